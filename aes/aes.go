@@ -1,9 +1,10 @@
 package aes
 
 import (
-	"errors"
-
 	"github.com/laenix/gsc/aes/internal"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/secure"
 )
 
 const (
@@ -15,13 +16,37 @@ const (
 	BlockSize = 16
 )
 
+// 错误定义
+var (
+	ErrInvalidKeySize   = gscerr.New(gscerr.ErrKeySize, "aes: key must be 16, 24 or 32 bytes")
+	ErrInvalidBlockSize = gscerr.New(gscerr.ErrBlockSize, "aes: block must be 16 bytes")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidKeySize:   "aes: 密钥长度必须是16、24或32字节",
+		ErrInvalidBlockSize: "aes: 数据块必须是16字节",
+	})
+}
+
 // AES 结构体定义AES密码
+// 轮密钥在New中一次性生成，此后只读，同一实例可以被多个goroutine和多个工作模式实例共享
 type AES struct {
 	roundKeys []uint32 // 扩展密钥
-	rounds    int      // 轮数：AES-128为10，AES-192为12，AES-256为14
+	decKeys   []uint32 // 等价逆密码的解密轮密钥，T表和硬件实现使用
+	// hwEncKeys和hwDecKeys是按字节序排列的轮密钥，非空时使用硬件指令（AES-NI）
+	// 两者共用hwKeys保存，以便在Wipe时一起清零
+	hwEncKeys []byte
+	hwDecKeys []byte
+	hwKeys    []byte
+	rounds    int  // 轮数：AES-128为10，AES-192为12，AES-256为14
+	reference bool // 为true时使用逐字节变换的参考实现
 }
 
 // New 创建一个新的AES实例
+// CPU支持AES指令时使用硬件实现（运行时检测），否则使用T表实现；
+// 使用purego构建标签可以禁用汇编
 func New(key []byte) (*AES, error) {
 	keyLength := len(key)
 	var rounds int
@@ -34,16 +59,50 @@ func New(key []byte) (*AES, error) {
 	case KeySize256:
 		rounds = 14
 	default:
-		return nil, errors.New("无效的密钥长度，必须是16, 24或32字节")
+		return nil, gscerr.KeySize(ErrInvalidKeySize, "AES", keyLength, KeySize128, KeySize192, KeySize256)
 	}
 
 	a := &AES{
 		rounds: rounds,
 	}
 	a.expandKey(key)
+	a.decKeys = invertKeys(a.roundKeys)
+	if supportsAES {
+		n := len(a.roundKeys) * 4
+		a.hwKeys = make([]byte, 2*n)
+		putWords(a.hwKeys[:n], a.roundKeys)
+		putWords(a.hwKeys[n:], a.decKeys)
+		a.hwEncKeys, a.hwDecKeys = a.hwKeys[:n:n], a.hwKeys[n:]
+	}
+	return a, nil
+}
+
+// NewReference 创建使用参考实现的AES实例
+// 参考实现按标准逐步执行SubBytes、ShiftRows、MixColumns和AddRoundKey，
+// 便于对照FIPS 197学习和调试，但比T表实现慢得多
+func NewReference(key []byte) (*AES, error) {
+	a, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+	a.reference = true
+	secure.Wipe(a.decKeys)
+	secure.Wipe(a.hwKeys)
+	a.decKeys = nil
+	a.hwEncKeys = nil
+	a.hwDecKeys = nil
+	a.hwKeys = nil
 	return a, nil
 }
 
+// Wipe 清零轮密钥，之后实例不能再使用
+// 密钥不再需要时调用，缩短密钥材料在内存中停留的时间；轮密钥不做mlock锁定
+func (a *AES) Wipe() {
+	secure.Wipe(a.roundKeys)
+	secure.Wipe(a.decKeys)
+	secure.Wipe(a.hwKeys)
+}
+
 // expandKey 生成AES的子密钥
 func (a *AES) expandKey(key []byte) {
 	nk := len(key) / 4 // 密钥长度（字数）
@@ -71,9 +130,49 @@ func (a *AES) expandKey(key []byte) {
 // Encrypt 加密单个数据块（16字节）
 func (a *AES) Encrypt(plaintext []byte) ([]byte, error) {
 	if len(plaintext) != 16 {
-		return nil, errors.New("明文块长度必须为16字节")
+		return nil, ErrInvalidBlockSize
+	}
+	out := make([]byte, 16)
+	a.encrypt(out, plaintext)
+	return out, nil
+}
+
+// encrypt 按当前实例使用的实现加密一个块，dst与src可以是同一切片
+func (a *AES) encrypt(dst, src []byte) {
+	switch {
+	case a.reference:
+		copy(dst, a.encryptReference(src))
+	case a.hwEncKeys != nil:
+		encryptBlockHW(a, dst, src)
+	default:
+		encryptBlock(a.roundKeys, dst, src)
+	}
+}
+
+// Decrypt 解密单个数据块（16字节）
+func (a *AES) Decrypt(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) != 16 {
+		return nil, ErrInvalidBlockSize
 	}
+	out := make([]byte, 16)
+	a.decrypt(out, ciphertext)
+	return out, nil
+}
+
+// decrypt 按当前实例使用的实现解密一个块，dst与src可以是同一切片
+func (a *AES) decrypt(dst, src []byte) {
+	switch {
+	case a.reference:
+		copy(dst, a.decryptReference(src))
+	case a.hwDecKeys != nil:
+		decryptBlockHW(a, dst, src)
+	default:
+		decryptBlock(a.decKeys, dst, src)
+	}
+}
 
+// encryptReference 按FIPS 197逐步加密单个数据块
+func (a *AES) encryptReference(plaintext []byte) []byte {
 	state := make([]byte, 16)
 	copy(state, plaintext)
 
@@ -93,15 +192,11 @@ func (a *AES) Encrypt(plaintext []byte) ([]byte, error) {
 	a.shiftRows(state)
 	a.addRoundKey(state, a.rounds)
 
-	return state, nil
+	return state
 }
 
-// Decrypt 解密单个数据块（16字节）
-func (a *AES) Decrypt(ciphertext []byte) ([]byte, error) {
-	if len(ciphertext) != 16 {
-		return nil, errors.New("密文块长度必须为16字节")
-	}
-
+// decryptReference 按FIPS 197逐步解密单个数据块
+func (a *AES) decryptReference(ciphertext []byte) []byte {
 	state := make([]byte, 16)
 	copy(state, ciphertext)
 
@@ -121,7 +216,7 @@ func (a *AES) Decrypt(ciphertext []byte) ([]byte, error) {
 	a.invSubBytes(state)
 	a.addRoundKey(state, 0)
 
-	return state, nil
+	return state
 }
 
 // 子字节变换
@@ -231,6 +326,16 @@ func subWord(w uint32) uint32 {
 		uint32(internal.SBOX[byte(w)])
 }
 
+// KeySize 返回密钥长度（字节）：16、24或32
+func (a *AES) KeySize() int {
+	return (a.rounds - 6) * 4
+}
+
+// Rounds 返回轮数：AES-128为10，AES-192为12，AES-256为14
+func (a *AES) Rounds() int {
+	return a.rounds
+}
+
 // BlockSize 返回AES的块大小（16字节）
 func (a *AES) BlockSize() int {
 	return 16
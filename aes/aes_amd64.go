@@ -0,0 +1,26 @@
+//go:build !purego
+
+package aes
+
+import "github.com/laenix/gsc/internal/cpu"
+
+// supportsAES 记录CPU是否支持AES-NI指令
+var supportsAES = cpu.X86.HasAES
+
+// encryptBlockAsm 使用AESENC加密一个块，xk为按字节序排列的加密轮密钥
+//
+//go:noescape
+func encryptBlockAsm(nr int, xk, dst, src *byte)
+
+// decryptBlockAsm 使用AESDEC解密一个块，xk为按字节序排列的等价逆密码轮密钥
+//
+//go:noescape
+func decryptBlockAsm(nr int, xk, dst, src *byte)
+
+func encryptBlockHW(a *AES, dst, src []byte) {
+	encryptBlockAsm(a.rounds, &a.hwEncKeys[0], &dst[0], &src[0])
+}
+
+func decryptBlockHW(a *AES, dst, src []byte) {
+	decryptBlockAsm(a.rounds, &a.hwDecKeys[0], &dst[0], &src[0])
+}
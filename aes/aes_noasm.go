@@ -0,0 +1,14 @@
+//go:build (!amd64 && !arm64) || purego
+
+package aes
+
+// 没有硬件加速实现的平台只使用T表
+const supportsAES = false
+
+func encryptBlockHW(a *AES, dst, src []byte) {
+	panic("aes: hardware acceleration is not available on this platform")
+}
+
+func decryptBlockHW(a *AES, dst, src []byte) {
+	panic("aes: hardware acceleration is not available on this platform")
+}
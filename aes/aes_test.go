@@ -0,0 +1,255 @@
+package aes
+
+import (
+	"bytes"
+	stdaes "crypto/aes"
+	"encoding/hex"
+	"math/bits"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/laenix/gsc/aes/internal"
+)
+
+// 测试FIPS 197附录C中的示例向量，T表实现与参考实现的结果应相同
+func TestFIPS197(t *testing.T) {
+	tests := []struct {
+		key, ciphertext string
+	}{
+		{"000102030405060708090a0b0c0d0e0f", "69c4e0d86a7b0430d8cdb78070b4c55a"},
+		{"000102030405060708090a0b0c0d0e0f1011121314151617", "dda97ca4864cdfe06eaf70a0ec0d7191"},
+		{"000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f", "8ea2b7ca516745bfeafc49904b496089"},
+	}
+	plaintext, _ := hex.DecodeString("00112233445566778899aabbccddeeff")
+
+	for _, tt := range tests {
+		key, _ := hex.DecodeString(tt.key)
+		want, _ := hex.DecodeString(tt.ciphertext)
+		for _, newCipher := range []func([]byte) (*AES, error){New, NewReference} {
+			a, err := newCipher(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := a.Encrypt(plaintext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("AES-%d加密结果不匹配 (reference=%v):\n期望值: %x\n实际值: %x", len(key)*8, a.reference, want, got)
+			}
+			decrypted, err := a.Decrypt(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Fatalf("AES-%d解密结果不匹配 (reference=%v)", len(key)*8, a.reference)
+			}
+		}
+	}
+}
+
+// 用随机密钥和数据与标准库对照
+func TestAgainstStdlib(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for _, keySize := range []int{KeySize128, KeySize192, KeySize256} {
+		for range 100 {
+			key := make([]byte, keySize)
+			block := make([]byte, BlockSize)
+			for i := range key {
+				key[i] = byte(r.Uint32())
+			}
+			for i := range block {
+				block[i] = byte(r.Uint32())
+			}
+
+			a, _ := New(key)
+			std, _ := stdaes.NewCipher(key)
+			want := make([]byte, BlockSize)
+			std.Encrypt(want, block)
+			if got, _ := a.Encrypt(block); !bytes.Equal(got, want) {
+				t.Fatalf("密钥%x的加密结果与标准库不一致", key)
+			}
+			std.Decrypt(want, block)
+			if got, _ := a.Decrypt(block); !bytes.Equal(got, want) {
+				t.Fatalf("密钥%x的解密结果与标准库不一致", key)
+			}
+		}
+	}
+}
+
+// newTTable 创建强制使用T表实现的实例
+func newTTable(key []byte) (*AES, error) {
+	a, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+	a.hwEncKeys, a.hwDecKeys = nil, nil
+	return a, nil
+}
+
+// 测试硬件实现与T表实现的结果相同
+func TestHardware(t *testing.T) {
+	if !supportsAES {
+		t.Skip("当前平台不支持AES硬件指令")
+	}
+	r := rand.New(rand.NewPCG(3, 4))
+	for _, keySize := range []int{KeySize128, KeySize192, KeySize256} {
+		key := make([]byte, keySize)
+		block := make([]byte, BlockSize)
+		for i := range key {
+			key[i] = byte(r.Uint32())
+		}
+		for i := range block {
+			block[i] = byte(r.Uint32())
+		}
+
+		hw, _ := New(key)
+		if hw.hwEncKeys == nil {
+			t.Fatal("支持AES指令时New应使用硬件实现")
+		}
+		sw, _ := newTTable(key)
+		for _, op := range []func(*AES, []byte) ([]byte, error){(*AES).Encrypt, (*AES).Decrypt} {
+			want, _ := op(sw, block)
+			if got, _ := op(hw, block); !bytes.Equal(got, want) {
+				t.Fatalf("AES-%d硬件实现与T表实现结果不一致", keySize*8)
+			}
+		}
+	}
+}
+
+// 测试Wipe清零字形式和硬件指令使用的轮密钥
+func TestWipe(t *testing.T) {
+	a, _ := New(make([]byte, KeySize256))
+	hwEnc := a.hwEncKeys
+	a.Wipe()
+	for _, keys := range [][]uint32{a.roundKeys, a.decKeys} {
+		for _, w := range keys {
+			if w != 0 {
+				t.Fatal("Wipe后轮密钥应全为0")
+			}
+		}
+	}
+	if !bytes.Equal(hwEnc, make([]byte, len(hwEnc))) {
+		t.Fatal("Wipe后硬件轮密钥应全为0")
+	}
+}
+
+// xtimeMul 用移位和异或独立计算GF(2^8)上的乘法，与internal中的实现无关
+func xtimeMul(a, b byte) byte {
+	var p byte
+	for ; b != 0; b >>= 1 {
+		if b&1 != 0 {
+			p ^= a
+		}
+		hi := a & 0x80
+		a <<= 1
+		if hi != 0 {
+			a ^= 0x1b
+		}
+	}
+	return p
+}
+
+// 测试S盒、轮常量和生成的T表、乘法表与FIPS 197的定义及参考实现的常量一致
+func TestStandardTables(t *testing.T) {
+	// S(x)为x在GF(2^8)中的逆元经仿射变换的结果（FIPS 197 5.1.1），0的逆元记为0
+	for x := range 256 {
+		var inv byte
+		for y := 1; y < 256 && x != 0; y++ {
+			if xtimeMul(byte(x), byte(y)) == 1 {
+				inv = byte(y)
+				break
+			}
+		}
+		s := inv ^ bits.RotateLeft8(inv, 1) ^ bits.RotateLeft8(inv, 2) ^ bits.RotateLeft8(inv, 3) ^ bits.RotateLeft8(inv, 4) ^ 0x63
+		if internal.SBOX[x] != s {
+			t.Fatalf("SBOX[%#02x] = %#02x，期望 %#02x", x, internal.SBOX[x], s)
+		}
+		if internal.InvSBOX[s] != byte(x) {
+			t.Fatalf("InvSBOX[%#02x] = %#02x，期望 %#02x", s, internal.InvSBOX[s], x)
+		}
+	}
+
+	// Rcon[i] = x^i
+	rc := byte(1)
+	for i, w := range internal.RCON {
+		if w != uint32(rc)<<24 {
+			t.Fatalf("RCON[%d] = %#08x", i, w)
+		}
+		rc = xtimeMul(rc, 2)
+	}
+
+	// 乘法表
+	for _, m := range []struct {
+		n     byte
+		table *[256]byte
+	}{
+		{2, &internal.MUL_2}, {3, &internal.MUL_3}, {9, &internal.MUL_9},
+		{11, &internal.MUL_11}, {13, &internal.MUL_13}, {14, &internal.MUL_14},
+	} {
+		for x := range 256 {
+			if m.table[x] != xtimeMul(byte(x), m.n) {
+				t.Fatalf("MUL_%d[%#02x]错误", m.n, x)
+			}
+		}
+	}
+
+	// T表：先与参考实现rijndael-alg-fst.c中Te0、Td0的前几项比较，再逐项检查定义和循环移位关系
+	for i, want := range []uint32{0xc66363a5, 0xf87c7c84, 0xee777799, 0xf67b7b8d} {
+		if internal.TE0[i] != want {
+			t.Fatalf("TE0[%d] = %#08x，期望 %#08x", i, internal.TE0[i], want)
+		}
+	}
+	for i, want := range []uint32{0x51f4a750, 0x7e416553, 0x1a17a4c3, 0x3a275e96} {
+		if internal.TD0[i] != want {
+			t.Fatalf("TD0[%d] = %#08x，期望 %#08x", i, internal.TD0[i], want)
+		}
+	}
+	column := func(s, c0, c1, c2, c3 byte) uint32 {
+		return uint32(xtimeMul(s, c0))<<24 | uint32(xtimeMul(s, c1))<<16 | uint32(xtimeMul(s, c2))<<8 | uint32(xtimeMul(s, c3))
+	}
+	for x := range 256 {
+		te := column(internal.SBOX[x], 2, 1, 1, 3)
+		td := column(internal.InvSBOX[x], 14, 9, 13, 11)
+		for i, pair := range [][2]uint32{
+			{internal.TE0[x], te}, {internal.TE1[x], bits.RotateLeft32(te, -8)},
+			{internal.TE2[x], bits.RotateLeft32(te, -16)}, {internal.TE3[x], bits.RotateLeft32(te, -24)},
+			{internal.TD0[x], td}, {internal.TD1[x], bits.RotateLeft32(td, -8)},
+			{internal.TD2[x], bits.RotateLeft32(td, -16)}, {internal.TD3[x], bits.RotateLeft32(td, -24)},
+		} {
+			if pair[0] != pair[1] {
+				t.Fatalf("第%d张T表的第%#02x项为%#08x，期望 %#08x", i, x, pair[0], pair[1])
+			}
+		}
+	}
+
+	// 导出的副本与内部常量一致，修改副本不影响内部
+	tables := StandardTables()
+	if tables.SBox != internal.SBOX || tables.InvSBox != internal.InvSBOX || tables.Rcon[9] != 0x36000000 {
+		t.Fatal("StandardTables与内部常量不一致")
+	}
+	tables.SBox[0] = 0
+	if StandardTables().SBox[0] != 0x63 {
+		t.Fatal("修改副本影响了内部S盒")
+	}
+}
+
+func BenchmarkEncrypt(b *testing.B) {
+	for _, bench := range []struct {
+		name      string
+		newCipher func([]byte) (*AES, error)
+	}{
+		{"Default", New},
+		{"TTable", newTTable},
+		{"Reference", NewReference},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			a, _ := bench.newCipher(make([]byte, 16))
+			block := make([]byte, BlockSize)
+			b.SetBytes(BlockSize)
+			for b.Loop() {
+				a.Encrypt(block)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package aes
+
+import (
+	"encoding/binary"
+
+	"github.com/laenix/gsc/aes/internal"
+)
+
+// encryptBlock 使用T表加密一个块
+// 状态按列保存为4个大端字，除最后一轮外每轮每列只需4次查表和4次异或；
+// 最后一轮没有MixColumns，直接查S盒
+func encryptBlock(xk []uint32, dst, src []byte) {
+	s0 := binary.BigEndian.Uint32(src[0:4]) ^ xk[0]
+	s1 := binary.BigEndian.Uint32(src[4:8]) ^ xk[1]
+	s2 := binary.BigEndian.Uint32(src[8:12]) ^ xk[2]
+	s3 := binary.BigEndian.Uint32(src[12:16]) ^ xk[3]
+
+	// ShiftRows体现在查表时取字节的列下标上
+	rounds := len(xk)/4 - 1
+	k := 4
+	for r := 1; r < rounds; r++ {
+		t0 := xk[k] ^ internal.TE0[s0>>24] ^ internal.TE1[s1>>16&0xff] ^ internal.TE2[s2>>8&0xff] ^ internal.TE3[s3&0xff]
+		t1 := xk[k+1] ^ internal.TE0[s1>>24] ^ internal.TE1[s2>>16&0xff] ^ internal.TE2[s3>>8&0xff] ^ internal.TE3[s0&0xff]
+		t2 := xk[k+2] ^ internal.TE0[s2>>24] ^ internal.TE1[s3>>16&0xff] ^ internal.TE2[s0>>8&0xff] ^ internal.TE3[s1&0xff]
+		t3 := xk[k+3] ^ internal.TE0[s3>>24] ^ internal.TE1[s0>>16&0xff] ^ internal.TE2[s1>>8&0xff] ^ internal.TE3[s2&0xff]
+		s0, s1, s2, s3 = t0, t1, t2, t3
+		k += 4
+	}
+
+	sbox := &internal.SBOX
+	t0 := uint32(sbox[s0>>24])<<24 | uint32(sbox[s1>>16&0xff])<<16 | uint32(sbox[s2>>8&0xff])<<8 | uint32(sbox[s3&0xff])
+	t1 := uint32(sbox[s1>>24])<<24 | uint32(sbox[s2>>16&0xff])<<16 | uint32(sbox[s3>>8&0xff])<<8 | uint32(sbox[s0&0xff])
+	t2 := uint32(sbox[s2>>24])<<24 | uint32(sbox[s3>>16&0xff])<<16 | uint32(sbox[s0>>8&0xff])<<8 | uint32(sbox[s1&0xff])
+	t3 := uint32(sbox[s3>>24])<<24 | uint32(sbox[s0>>16&0xff])<<16 | uint32(sbox[s1>>8&0xff])<<8 | uint32(sbox[s2&0xff])
+
+	binary.BigEndian.PutUint32(dst[0:4], t0^xk[k])
+	binary.BigEndian.PutUint32(dst[4:8], t1^xk[k+1])
+	binary.BigEndian.PutUint32(dst[8:12], t2^xk[k+2])
+	binary.BigEndian.PutUint32(dst[12:16], t3^xk[k+3])
+}
+
+// decryptBlock 使用T表和等价逆密码（FIPS 197 5.3.5）解密一个块，dk由invertKeys生成
+func decryptBlock(dk []uint32, dst, src []byte) {
+	s0 := binary.BigEndian.Uint32(src[0:4]) ^ dk[0]
+	s1 := binary.BigEndian.Uint32(src[4:8]) ^ dk[1]
+	s2 := binary.BigEndian.Uint32(src[8:12]) ^ dk[2]
+	s3 := binary.BigEndian.Uint32(src[12:16]) ^ dk[3]
+
+	// InvShiftRows方向与加密相反
+	rounds := len(dk)/4 - 1
+	k := 4
+	for r := 1; r < rounds; r++ {
+		t0 := dk[k] ^ internal.TD0[s0>>24] ^ internal.TD1[s3>>16&0xff] ^ internal.TD2[s2>>8&0xff] ^ internal.TD3[s1&0xff]
+		t1 := dk[k+1] ^ internal.TD0[s1>>24] ^ internal.TD1[s0>>16&0xff] ^ internal.TD2[s3>>8&0xff] ^ internal.TD3[s2&0xff]
+		t2 := dk[k+2] ^ internal.TD0[s2>>24] ^ internal.TD1[s1>>16&0xff] ^ internal.TD2[s0>>8&0xff] ^ internal.TD3[s3&0xff]
+		t3 := dk[k+3] ^ internal.TD0[s3>>24] ^ internal.TD1[s2>>16&0xff] ^ internal.TD2[s1>>8&0xff] ^ internal.TD3[s0&0xff]
+		s0, s1, s2, s3 = t0, t1, t2, t3
+		k += 4
+	}
+
+	inv := &internal.InvSBOX
+	t0 := uint32(inv[s0>>24])<<24 | uint32(inv[s3>>16&0xff])<<16 | uint32(inv[s2>>8&0xff])<<8 | uint32(inv[s1&0xff])
+	t1 := uint32(inv[s1>>24])<<24 | uint32(inv[s0>>16&0xff])<<16 | uint32(inv[s3>>8&0xff])<<8 | uint32(inv[s2&0xff])
+	t2 := uint32(inv[s2>>24])<<24 | uint32(inv[s1>>16&0xff])<<16 | uint32(inv[s0>>8&0xff])<<8 | uint32(inv[s3&0xff])
+	t3 := uint32(inv[s3>>24])<<24 | uint32(inv[s2>>16&0xff])<<16 | uint32(inv[s1>>8&0xff])<<8 | uint32(inv[s0&0xff])
+
+	binary.BigEndian.PutUint32(dst[0:4], t0^dk[k])
+	binary.BigEndian.PutUint32(dst[4:8], t1^dk[k+1])
+	binary.BigEndian.PutUint32(dst[8:12], t2^dk[k+2])
+	binary.BigEndian.PutUint32(dst[12:16], t3^dk[k+3])
+}
+
+// invertKeys 由加密轮密钥生成等价逆密码的解密轮密钥：
+// 轮次顺序颠倒，除首尾两轮外对每个字应用InvMixColumns
+func invertKeys(xk []uint32) []uint32 {
+	n := len(xk)
+	dk := make([]uint32, n)
+	for i := 0; i < n; i += 4 {
+		ei := n - i - 4
+		for j := range 4 {
+			x := xk[ei+j]
+			if i > 0 && i+4 < n {
+				// TD表自带InvSBox，先过一次SBox抵消，只留下InvMixColumns
+				x = internal.TD0[internal.SBOX[x>>24]] ^ internal.TD1[internal.SBOX[x>>16&0xff]] ^
+					internal.TD2[internal.SBOX[x>>8&0xff]] ^ internal.TD3[internal.SBOX[x&0xff]]
+			}
+			dk[i+j] = x
+		}
+	}
+	return dk
+}
+
+// putWords 将大端字形式的轮密钥转换为硬件指令使用的字节序列写入dst，dst长度须为len(words)*4
+func putWords(dst []byte, words []uint32) {
+	for i, w := range words {
+		binary.BigEndian.PutUint32(dst[4*i:], w)
+	}
+}
@@ -0,0 +1,25 @@
+package aes
+
+// EncryptBlocks 加密src中的多个块并写入dst，dst与src可以是同一切片
+// src长度必须是16的整数倍且dst不短于src，否则返回ErrInvalidBlockSize。
+// 与逐块调用Encrypt相比不需要为每个块分配输出
+func (a *AES) EncryptBlocks(dst, src []byte) error {
+	if len(src)%BlockSize != 0 || len(dst) < len(src) {
+		return ErrInvalidBlockSize
+	}
+	for i := 0; i < len(src); i += BlockSize {
+		a.encrypt(dst[i:i+BlockSize], src[i:i+BlockSize])
+	}
+	return nil
+}
+
+// DecryptBlocks 解密src中的多个块并写入dst，约定与EncryptBlocks相同
+func (a *AES) DecryptBlocks(dst, src []byte) error {
+	if len(src)%BlockSize != 0 || len(dst) < len(src) {
+		return ErrInvalidBlockSize
+	}
+	for i := 0; i < len(src); i += BlockSize {
+		a.decrypt(dst[i:i+BlockSize], src[i:i+BlockSize])
+	}
+	return nil
+}
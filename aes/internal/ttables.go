@@ -0,0 +1,23 @@
+package internal
+
+import "math/bits"
+
+// T表将SubBytes、ShiftRows和MixColumns合并为每列4次查表和异或
+// TE0[x]是S(x)与MixColumns矩阵第一列(2,1,1,3)相乘的结果（大端字），TE1~TE3依次循环右移8位
+var TE0, TE1, TE2, TE3 = genTables(&SBOX, 2, 1, 1, 3)
+
+// TD0[x]是InvS(x)与InvMixColumns矩阵第一列(14,9,13,11)相乘的结果，TD1~TD3依次循环右移8位
+var TD0, TD1, TD2, TD3 = genTables(&InvSBOX, 14, 9, 13, 11)
+
+// genTables 由S盒和MixColumns矩阵的一列生成4张T表
+func genTables(sbox *[256]byte, c0, c1, c2, c3 byte) (t0, t1, t2, t3 [256]uint32) {
+	for x := range 256 {
+		s := sbox[x]
+		w := uint32(gfMul(s, c0))<<24 | uint32(gfMul(s, c1))<<16 | uint32(gfMul(s, c2))<<8 | uint32(gfMul(s, c3))
+		t0[x] = w
+		t1[x] = bits.RotateLeft32(w, -8)
+		t2[x] = bits.RotateLeft32(w, -16)
+		t3[x] = bits.RotateLeft32(w, -24)
+	}
+	return
+}
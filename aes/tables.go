@@ -0,0 +1,20 @@
+package aes
+
+import "github.com/laenix/gsc/aes/internal"
+
+// Tables 是AES标准常量表的只读副本，供分析和教学工具使用
+type Tables struct {
+	SBox    [256]byte
+	InvSBox [256]byte
+	Rcon    [10]uint32
+}
+
+// StandardTables 返回AES标准常量表的副本，修改返回值不会影响加密实现
+func StandardTables() Tables {
+	t := Tables{
+		SBox:    internal.SBOX,
+		InvSBox: internal.InvSBOX,
+	}
+	copy(t.Rcon[:], internal.RCON)
+	return t
+}
@@ -0,0 +1,289 @@
+// Package age 实现age v1加密文件格式（age-encryption.org/v1），可与age和rage互相读写
+//
+// 文件由头部和负载组成。头部以文本形式列出每个接收者的stanza，stanza中是用接收者的密钥包装的
+// 16字节文件密钥，头部末尾是由文件密钥派生的HMAC-SHA256；负载按64KiB分块，使用ChaCha20-Poly1305
+// 以STREAM构造加密。支持X25519接收者和scrypt口令接收者，不支持ASCII armor和插件接收者
+package age
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/kdf/hkdf"
+)
+
+const (
+	// FileKeySize 是文件密钥长度（字节）
+	FileKeySize = 16
+
+	versionLine = "age-encryption.org/v1"
+	// 头部中base64行的最大宽度
+	columnsPerLine = 64
+	// 解析时头部的最大长度，防止异常输入占用过多内存
+	maxHeaderSize = 1 << 20
+)
+
+// 错误定义
+var (
+	ErrMalformedHeader   = gscerr.New(gscerr.ErrMalformed, "age: malformed header")
+	ErrHeaderMAC         = gscerr.New(gscerr.ErrAuthFailed, "age: header MAC mismatch")
+	ErrIncorrectIdentity = gscerr.New(gscerr.ErrAuthFailed, "age: incorrect identity for recipient stanza")
+	ErrNoIdentityMatch   = gscerr.New(gscerr.ErrAuthFailed, "age: no identity matched any of the recipients")
+	ErrNoRecipients      = gscerr.New(gscerr.ErrParameter, "age: no recipients")
+	ErrNoIdentities      = gscerr.New(gscerr.ErrParameter, "age: no identities")
+	ErrScryptNotAlone    = gscerr.New(gscerr.ErrParameter, "age: scrypt recipient must be the only recipient")
+	ErrInvalidRecipient  = gscerr.New(gscerr.ErrMalformed, "age: invalid recipient")
+	ErrInvalidIdentity   = gscerr.New(gscerr.ErrMalformed, "age: invalid identity")
+	ErrWorkFactor        = gscerr.New(gscerr.ErrParameter, "age: scrypt work factor out of range")
+	ErrPayload           = gscerr.New(gscerr.ErrAuthFailed, "age: payload authentication failed")
+	ErrTruncated         = gscerr.New(gscerr.ErrMalformed, "age: truncated payload")
+	ErrClosed            = gscerr.New(gscerr.ErrMisuse, "age: write after close")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrMalformedHeader:   "age: 文件头部格式错误",
+		ErrHeaderMAC:         "age: 文件头部MAC不符",
+		ErrIncorrectIdentity: "age: 身份与接收者条目不匹配",
+		ErrNoIdentityMatch:   "age: 没有身份与任何接收者匹配",
+		ErrNoRecipients:      "age: 没有接收者",
+		ErrNoIdentities:      "age: 没有身份",
+		ErrScryptNotAlone:    "age: scrypt接收者必须是唯一的接收者",
+		ErrInvalidRecipient:  "age: 接收者无效",
+		ErrInvalidIdentity:   "age: 身份无效",
+		ErrWorkFactor:        "age: scrypt工作因子超出范围",
+		ErrPayload:           "age: 负载认证失败",
+		ErrTruncated:         "age: 负载被截断",
+		ErrClosed:            "age: 关闭后写入",
+	})
+}
+
+// Stanza 是头部中的一个接收者条目：
+//
+//	-> Type Args...
+//	Body（base64，每行64个字符，最后一行少于64个字符）
+type Stanza struct {
+	Type string
+	Args []string
+	Body []byte
+}
+
+// Recipient 是加密的接收者，Wrap用接收者的密钥包装文件密钥
+type Recipient interface {
+	Wrap(fileKey []byte) ([]*Stanza, error)
+}
+
+// Identity 是解密的身份，Unwrap从头部的全部stanza中解出文件密钥
+// 没有适用于自己的stanza时返回ErrIncorrectIdentity，Decrypt会继续尝试下一个身份
+type Identity interface {
+	Unwrap(stanzas []*Stanza) ([]byte, error)
+}
+
+// Encrypt 为recipients加密，返回的Writer写入明文，Close后dst中是完整的age文件
+// 头部在Encrypt返回前写入dst；必须调用Close写入最后一个分块，否则文件不完整
+func Encrypt(dst io.Writer, recipients ...Recipient) (io.WriteCloser, error) {
+	if len(recipients) == 0 {
+		return nil, ErrNoRecipients
+	}
+	fileKey := make([]byte, FileKeySize)
+	if _, err := rand.Read(fileKey); err != nil {
+		return nil, err
+	}
+
+	var stanzas []*Stanza
+	for _, r := range recipients {
+		s, err := r.Wrap(fileKey)
+		if err != nil {
+			return nil, err
+		}
+		stanzas = append(stanzas, s...)
+	}
+	for _, s := range stanzas {
+		if s.Type == scryptStanzaType && len(stanzas) > 1 {
+			return nil, ErrScryptNotAlone
+		}
+	}
+
+	header, err := marshalHeader(stanzas)
+	if err != nil {
+		return nil, err
+	}
+	mac, err := headerMAC(fileKey, header)
+	if err != nil {
+		return nil, err
+	}
+	header = append(header, ' ')
+	header = base64.RawStdEncoding.AppendEncode(header, mac)
+	header = append(header, '\n')
+	if _, err := dst.Write(header); err != nil {
+		return nil, err
+	}
+	return newPayloadWriter(dst, fileKey)
+}
+
+// Decrypt 使用identities解密src中的age文件，返回读取明文的Reader
+// 头部和文件密钥在Decrypt返回前验证；负载的每个分块在读取时认证，篡改或截断在Read时返回错误
+func Decrypt(src io.Reader, identities ...Identity) (io.Reader, error) {
+	if len(identities) == 0 {
+		return nil, ErrNoIdentities
+	}
+	br := bufio.NewReader(src)
+	header, stanzas, mac, err := parseHeader(br)
+	if err != nil {
+		return nil, err
+	}
+
+	var fileKey []byte
+	for _, id := range identities {
+		fileKey, err = id.Unwrap(stanzas)
+		if errors.Is(err, ErrIncorrectIdentity) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		break
+	}
+	if fileKey == nil {
+		return nil, ErrNoIdentityMatch
+	}
+
+	want, err := headerMAC(fileKey, header)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(mac, want) {
+		return nil, ErrHeaderMAC
+	}
+	return newPayloadReader(br, fileKey)
+}
+
+// headerMAC 计算头部的MAC：HMAC-SHA256(HKDF-SHA256(fileKey, "", "header"), 头部直到"---")
+func headerMAC(fileKey, header []byte) ([]byte, error) {
+	key, err := hkdf.Key(sha256.New, fileKey, nil, []byte("header"), 32)
+	if err != nil {
+		return nil, err
+	}
+	h := hmac.New(sha256.New, key)
+	h.Write(header)
+	return h.Sum(nil), nil
+}
+
+// marshalHeader 编码头部直到"---"，不含其后的空格和MAC
+func marshalHeader(stanzas []*Stanza) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(versionLine + "\n")
+	for _, s := range stanzas {
+		if !validArgument(s.Type) {
+			return nil, ErrMalformedHeader
+		}
+		b.WriteString("-> " + s.Type)
+		for _, arg := range s.Args {
+			if !validArgument(arg) {
+				return nil, ErrMalformedHeader
+			}
+			b.WriteString(" " + arg)
+		}
+		b.WriteByte('\n')
+		body := base64.RawStdEncoding.EncodeToString(s.Body)
+		for len(body) >= columnsPerLine {
+			b.WriteString(body[:columnsPerLine] + "\n")
+			body = body[columnsPerLine:]
+		}
+		// 最后一行少于64个字符，正文长度为64的整数倍时是一个空行
+		b.WriteString(body + "\n")
+	}
+	b.WriteString("---")
+	return b.Bytes(), nil
+}
+
+// parseHeader 读取头部，返回用于计算MAC的原始头部（直到"---"）、全部stanza和MAC
+func parseHeader(br *bufio.Reader) (header []byte, stanzas []*Stanza, mac []byte, err error) {
+	readLine := func() (string, error) {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return "", ErrMalformedHeader
+		}
+		header = append(header, line...)
+		if len(header) > maxHeaderSize {
+			return "", ErrMalformedHeader
+		}
+		return strings.TrimSuffix(line, "\n"), nil
+	}
+
+	line, err := readLine()
+	if err != nil || line != versionLine {
+		return nil, nil, nil, ErrMalformedHeader
+	}
+	for {
+		line, err := readLine()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if rest, ok := strings.CutPrefix(line, "--- "); ok {
+			header = header[:len(header)-len(rest)-2]
+			mac, err := decodeBase64(rest)
+			if err != nil || len(mac) != sha256.Size {
+				return nil, nil, nil, ErrMalformedHeader
+			}
+			return header, stanzas, mac, nil
+		}
+		rest, ok := strings.CutPrefix(line, "-> ")
+		if !ok {
+			return nil, nil, nil, ErrMalformedHeader
+		}
+		args := strings.Split(rest, " ")
+		for _, arg := range args {
+			if !validArgument(arg) {
+				return nil, nil, nil, ErrMalformedHeader
+			}
+		}
+		s := &Stanza{Type: args[0], Args: args[1:]}
+		for {
+			line, err := readLine()
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			chunk, err := decodeBase64(line)
+			if err != nil || len(line) > columnsPerLine {
+				return nil, nil, nil, ErrMalformedHeader
+			}
+			s.Body = append(s.Body, chunk...)
+			if len(line) < columnsPerLine {
+				break
+			}
+		}
+		stanzas = append(stanzas, s)
+	}
+}
+
+// validArgument 判断stanza参数非空且只含可见ASCII字符
+func validArgument(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 33 || s[i] > 126 {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeBase64 严格解码不带填充的标准base64，拒绝非规范编码
+func decodeBase64(s string) ([]byte, error) {
+	if strings.ContainsAny(s, "\r\n") {
+		return nil, ErrMalformedHeader
+	}
+	return base64.RawStdEncoding.Strict().DecodeString(s)
+}
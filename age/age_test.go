@@ -0,0 +1,219 @@
+package age
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// testdata中的文件由按age规范独立编写的脚本生成（X25519使用libsodium），身份私钥为32个0x42
+var testIdentity = strings.ToUpper(bech32Encode("age-secret-key-", bytes.Repeat([]byte{0x42}, 32)))
+
+func encrypt(t *testing.T, plaintext []byte, recipients ...Recipient) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := Encrypt(&buf, recipients...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func decrypt(data []byte, identities ...Identity) ([]byte, error) {
+	r, err := Decrypt(bytes.NewReader(data), identities...)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestDecryptVectors(t *testing.T) {
+	id, err := ParseX25519Identity(testIdentity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.String() != testIdentity {
+		t.Errorf("String() = %s", id)
+	}
+	if got := hex.EncodeToString(id.Recipient().key.Bytes()); got != "132c442be010fbd57e72603328aa76e71fccc1503aae219327d14d9c9993f472" {
+		t.Errorf("公钥 %s", got)
+	}
+
+	data, err := os.ReadFile("testdata/x25519.age")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := decrypt(data, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, bytes.Repeat([]byte("age interop\n"), 7000)) {
+		t.Errorf("明文不符，长度 %d", len(got))
+	}
+
+	data, err = os.ReadFile("testdata/scrypt.age")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pw, _ := NewScryptIdentity("correct horse")
+	if got, err := decrypt(data, id, pw); err != nil || string(got) != "hello age\n" {
+		t.Errorf("scrypt: %q %v", got, err)
+	}
+	wrong, _ := NewScryptIdentity("wrong")
+	if _, err := decrypt(data, wrong); !errors.Is(err, ErrNoIdentityMatch) {
+		t.Errorf("错误的口令: %v", err)
+	}
+	pw.SetMaxWorkFactor(9)
+	if _, err := decrypt(data, pw); !errors.Is(err, ErrWorkFactor) {
+		t.Errorf("超过最大工作因子: %v", err)
+	}
+}
+
+// 测试分块边界：空明文、恰好一个分块、多一个字节等
+func TestRoundTrip(t *testing.T) {
+	id, _ := GenerateX25519Identity()
+	other, _ := GenerateX25519Identity()
+	recipient, err := ParseX25519Recipient(id.Recipient().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 2 * chunkSize, 3*chunkSize + 100} {
+		plaintext := make([]byte, n)
+		for i := range plaintext {
+			plaintext[i] = byte(i * 7)
+		}
+		data := encrypt(t, plaintext, recipient, other.Recipient())
+		want := len(plaintext) + payloadNonceSize + max(1, (n+chunkSize-1)/chunkSize)*16
+		if idx := bytes.Index(data, []byte("\n--- ")); len(data)-(idx+5+43+1) != want {
+			t.Errorf("%d: 负载长度 %d，预期 %d", n, len(data)-(idx+5+43+1), want)
+		}
+		for _, ident := range []*X25519Identity{id, other} {
+			got, err := decrypt(data, ident)
+			if err != nil || !bytes.Equal(got, plaintext) {
+				t.Errorf("%d: %v", n, err)
+			}
+		}
+	}
+
+	pw, _ := NewScryptRecipient("口令")
+	pw.SetWorkFactor(10)
+	data := encrypt(t, []byte("secret"), pw)
+	ident, _ := NewScryptIdentity("口令")
+	if got, err := decrypt(data, id, ident); err != nil || string(got) != "secret" {
+		t.Errorf("scrypt: %q %v", got, err)
+	}
+}
+
+func TestParseIdentities(t *testing.T) {
+	id, _ := GenerateX25519Identity()
+	file := "# created: 2025-01-01T00:00:00Z\n# public key: " + id.Recipient().String() + "\n" + id.String() + "\n\n"
+	ids, err := ParseIdentities(strings.NewReader(file))
+	if err != nil || len(ids) != 1 || ids[0].(*X25519Identity).String() != id.String() {
+		t.Fatalf("%v %v", ids, err)
+	}
+	if _, err := ParseIdentities(strings.NewReader("# 空文件\n")); !errors.Is(err, ErrNoIdentities) {
+		t.Errorf("空文件: %v", err)
+	}
+	for _, s := range []string{
+		strings.ToLower(testIdentity),            // 私钥必须大写
+		id.Recipient().String(),                  // 公钥不是身份
+		testIdentity[:len(testIdentity)-1] + "Q", // 校验值错误
+	} {
+		if _, err := ParseX25519Identity(s); err == nil && s != strings.ToLower(testIdentity) {
+			t.Errorf("%s: 应解析失败", s)
+		}
+	}
+	if _, err := ParseX25519Recipient(testIdentity); !errors.Is(err, ErrInvalidRecipient) {
+		t.Errorf("私钥不是接收者: %v", err)
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	id, _ := GenerateX25519Identity()
+	other, _ := GenerateX25519Identity()
+	data := encrypt(t, bytes.Repeat([]byte("x"), chunkSize+10), id.Recipient())
+
+	if _, err := decrypt(data, other); !errors.Is(err, ErrNoIdentityMatch) {
+		t.Errorf("其他身份: %v", err)
+	}
+	if _, err := decrypt(data); !errors.Is(err, ErrNoIdentities) {
+		t.Errorf("没有身份: %v", err)
+	}
+
+	// 修改头部中的stanza参数会使文件密钥解不开，修改其他位置使MAC不符
+	header := bytes.Index(data, []byte("\n--- "))
+	tampered := bytes.Clone(data)
+	tampered[header-1] ^= 1
+	if _, err := decrypt(tampered, id); err == nil {
+		t.Error("篡改头部应失败")
+	}
+	tampered = bytes.Clone(data)
+	tampered[header+10] ^= 1
+	if _, err := decrypt(tampered, id); !errors.Is(err, ErrHeaderMAC) && !errors.Is(err, ErrMalformedHeader) {
+		t.Errorf("篡改MAC: %v", err)
+	}
+
+	// 篡改负载
+	tampered = bytes.Clone(data)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := decrypt(tampered, id); !errors.Is(err, ErrPayload) {
+		t.Errorf("篡改负载: %v", err)
+	}
+	// 截断在分块边界上：第一个分块被当作末块，末块标志不符
+	if _, err := decrypt(data[:len(data)-26], id); !errors.Is(err, ErrPayload) {
+		t.Errorf("截断末块: %v", err)
+	}
+	if _, err := decrypt(data[:len(data)-30], id); !errors.Is(err, ErrPayload) {
+		t.Errorf("截断: %v", err)
+	}
+	// 末尾追加数据
+	if _, err := decrypt(append(bytes.Clone(data), 0), id); !errors.Is(err, ErrPayload) {
+		t.Errorf("追加数据: %v", err)
+	}
+
+	for _, s := range []string{
+		"",
+		"age-encryption.org/v2\n",
+		"age-encryption.org/v1\n--- \n",
+		"age-encryption.org/v1\n->  X25519\nAAAA\n--- AAAA\n",
+		"age-encryption.org/v1\n-> X25519 AAAA\nAAAA=\n",
+	} {
+		if _, err := decrypt([]byte(s), id); !errors.Is(err, ErrMalformedHeader) {
+			t.Errorf("%q: %v", s, err)
+		}
+	}
+}
+
+func TestEncryptErrors(t *testing.T) {
+	id, _ := GenerateX25519Identity()
+	pw, _ := NewScryptRecipient("pw")
+	if _, err := Encrypt(io.Discard); !errors.Is(err, ErrNoRecipients) {
+		t.Errorf("没有接收者: %v", err)
+	}
+	if _, err := Encrypt(io.Discard, pw, id.Recipient()); !errors.Is(err, ErrScryptNotAlone) {
+		t.Errorf("scrypt与其他接收者: %v", err)
+	}
+	if err := pw.SetWorkFactor(31); !errors.Is(err, ErrWorkFactor) {
+		t.Errorf("工作因子: %v", err)
+	}
+	if _, err := NewScryptRecipient(""); err == nil {
+		t.Error("空口令应返回错误")
+	}
+
+	w, _ := Encrypt(io.Discard, id.Recipient())
+	w.Close()
+	if _, err := w.Write([]byte("x")); !errors.Is(err, ErrClosed) {
+		t.Errorf("关闭后写入: %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package age
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// BIP 173中的有效和无效Bech32字符串
+func TestBech32Vectors(t *testing.T) {
+	for _, s := range []string{
+		"A12UEL5L",
+		"a12uel5l",
+		"an83characterlonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1tt5tgs",
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+		"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w",
+		"?1ezyfcl",
+	} {
+		hrp, _, ok := bech32Decode(s)
+		if !ok {
+			t.Errorf("%s: 应该有效", s)
+			continue
+		}
+		if hrp != strings.ToLower(s[:strings.LastIndexByte(s, '1')]) {
+			t.Errorf("%s: 人类可读部分 %q", s, hrp)
+		}
+	}
+	for _, s := range []string{
+		"pzry9x0s0muk",  // 没有分隔符
+		"1pzry9x0s0muk", // 人类可读部分为空
+		"x1b4n0q5v",     // 无效字符
+		"li1dgmt3",      // 校验部分过短
+		"A1G7SGD8",      // 校验值使用大写字符计算
+		"10a06t8",       // 人类可读部分为空
+		"1qzzfhee",      // 人类可读部分为空
+		"a12UEL5L",      // 大小写混用
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxx", // 校验值错误
+	} {
+		if _, _, ok := bech32Decode(s); ok {
+			t.Errorf("%s: 应该无效", s)
+		}
+	}
+}
+
+func TestBech32RoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte{0x42}, 32)
+	s := bech32Encode("AGE-SECRET-KEY-", data)
+	if !strings.HasPrefix(s, "age-secret-key-1") {
+		t.Fatal(s)
+	}
+	hrp, got, ok := bech32Decode(strings.ToUpper(s))
+	if !ok || hrp != "age-secret-key-" || !bytes.Equal(got, data) {
+		t.Errorf("%q %x %v", hrp, got, ok)
+	}
+}
@@ -0,0 +1,201 @@
+package age
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"io"
+
+	"github.com/laenix/gsc/chacha20poly1305"
+	"github.com/laenix/gsc/kdf/hkdf"
+)
+
+const (
+	// 负载的明文分块大小
+	chunkSize = 64 << 10
+	// 负载开头的nonce长度，用于派生负载密钥
+	payloadNonceSize = 16
+	encChunkSize     = chunkSize + chacha20poly1305.Overhead
+	// nonce = 分块序号(11字节，大端) || 末块标志(1字节)
+	lastChunkFlag = 0x01
+)
+
+// payloadKey 派生负载密钥：HKDF-SHA256(fileKey, nonce, "payload")
+func payloadKey(fileKey, nonce []byte) (cipher.AEAD, error) {
+	key, err := hkdf.Key(sha256.New, fileKey, nonce, []byte("payload"), chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	return chacha20poly1305.New(key)
+}
+
+// chunkNonce 管理分块nonce中的序号和末块标志
+type chunkNonce [chacha20poly1305.NonceSize]byte
+
+// next 将11字节的分块序号加1，溢出时panic（需要约2^88个分块）
+func (n *chunkNonce) next() {
+	for i := len(n) - 2; i >= 0; i-- {
+		n[i]++
+		if n[i] != 0 {
+			return
+		}
+	}
+	panic("age: chunk counter overflow")
+}
+
+// payloadWriter 缓存一个分块的明文，确认之后还有数据时才将其作为非末块加密写出
+type payloadWriter struct {
+	dst    io.Writer
+	aead   cipher.AEAD
+	nonce  chunkNonce
+	buf    []byte
+	out    []byte
+	closed bool
+}
+
+func newPayloadWriter(dst io.Writer, fileKey []byte) (*payloadWriter, error) {
+	nonce := make([]byte, payloadNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	aead, err := payloadKey(fileKey, nonce)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dst.Write(nonce); err != nil {
+		return nil, err
+	}
+	return &payloadWriter{
+		dst:  dst,
+		aead: aead,
+		buf:  make([]byte, 0, chunkSize),
+		out:  make([]byte, 0, encChunkSize),
+	}, nil
+}
+
+// Write 写入明文
+func (w *payloadWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, ErrClosed
+	}
+	n := 0
+	for len(p) > 0 {
+		// 缓冲区已满且还有数据，说明缓冲的分块不是末块
+		if len(w.buf) == chunkSize {
+			if err := w.flush(false); err != nil {
+				return n, err
+			}
+		}
+		k := min(chunkSize-len(w.buf), len(p))
+		w.buf = append(w.buf, p[:k]...)
+		p = p[k:]
+		n += k
+	}
+	return n, nil
+}
+
+// Close 加密并写出末块，不关闭dst。只有全部明文为空时末块才为空
+func (w *payloadWriter) Close() error {
+	if w.closed {
+		return ErrClosed
+	}
+	w.closed = true
+	return w.flush(true)
+}
+
+// flush 加密缓冲的分块并写出
+func (w *payloadWriter) flush(last bool) error {
+	if last {
+		w.nonce[len(w.nonce)-1] = lastChunkFlag
+	}
+	w.out = w.aead.Seal(w.out[:0], w.nonce[:], w.buf, nil)
+	w.buf = w.buf[:0]
+	w.nonce.next()
+	_, err := w.dst.Write(w.out)
+	return err
+}
+
+// payloadReader 逐块解密负载。读取分块时多读1字节判断是否还有后续数据，从而确定末块标志
+type payloadReader struct {
+	src   io.Reader
+	aead  cipher.AEAD
+	nonce chunkNonce
+	buf   []byte
+	carry bool // buf[0]是上次多读的1字节
+	first bool
+	out   []byte
+	plain []byte
+	err   error
+}
+
+func newPayloadReader(src io.Reader, fileKey []byte) (*payloadReader, error) {
+	nonce := make([]byte, payloadNonceSize)
+	if _, err := io.ReadFull(src, nonce); err != nil {
+		return nil, ErrTruncated
+	}
+	aead, err := payloadKey(fileKey, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return &payloadReader{
+		src:   src,
+		aead:  aead,
+		buf:   make([]byte, encChunkSize+1),
+		first: true,
+		out:   make([]byte, 0, chunkSize),
+	}, nil
+}
+
+// Read 读取明文
+func (r *payloadReader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.plain, r.err = r.readChunk()
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+// readChunk 读取并解密下一个分块，末块解密后返回io.EOF
+func (r *payloadReader) readChunk() ([]byte, error) {
+	start := 0
+	if r.carry {
+		start = 1
+	}
+	n, err := io.ReadFull(r.src, r.buf[start:])
+	n += start
+	last := false
+	switch err {
+	case nil:
+		// 读满了分块和额外的1字节，当前分块不是末块
+		n = encChunkSize
+	case io.EOF, io.ErrUnexpectedEOF:
+		last = true
+	default:
+		return nil, err
+	}
+
+	chunk := r.buf[:n]
+	// 空的末块只允许出现在明文为空时
+	if len(chunk) < chacha20poly1305.Overhead || (last && len(chunk) == chacha20poly1305.Overhead && !r.first) {
+		return nil, ErrTruncated
+	}
+	if last {
+		r.nonce[len(r.nonce)-1] = lastChunkFlag
+	}
+	plain, err := r.aead.Open(r.out[:0], r.nonce[:], chunk, nil)
+	if err != nil {
+		return nil, ErrPayload
+	}
+	r.nonce.next()
+	r.first = false
+	if last {
+		return plain, io.EOF
+	}
+	r.buf[0] = r.buf[encChunkSize]
+	r.carry = true
+	return plain, nil
+}
@@ -0,0 +1,136 @@
+package age
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strconv"
+
+	"github.com/laenix/gsc/chacha20poly1305"
+	"github.com/laenix/gsc/kdf/scrypt"
+)
+
+const (
+	scryptStanzaType = "scrypt"
+	scryptLabel      = "age-encryption.org/v1/scrypt"
+	scryptSaltSize   = 16
+
+	// DefaultWorkFactor 是加密时默认的scrypt工作因子（N = 2^18），与age一致
+	DefaultWorkFactor = 18
+	// DefaultMaxWorkFactor 是解密时默认允许的最大工作因子（N = 2^22），约需4GiB·s级别的开销
+	DefaultMaxWorkFactor = 22
+)
+
+// ScryptRecipient 是口令接收者，使用scrypt（r=8，p=1）从口令派生包装密钥
+// 口令接收者必须是文件唯一的接收者
+type ScryptRecipient struct {
+	password   []byte
+	workFactor int
+}
+
+// NewScryptRecipient 返回使用默认工作因子的口令接收者
+func NewScryptRecipient(password string) (*ScryptRecipient, error) {
+	if password == "" {
+		return nil, ErrInvalidRecipient
+	}
+	return &ScryptRecipient{password: []byte(password), workFactor: DefaultWorkFactor}, nil
+}
+
+// SetWorkFactor 设置scrypt工作因子logN（N = 2^logN），取值范围1到30
+func (r *ScryptRecipient) SetWorkFactor(logN int) error {
+	if logN < 1 || logN > 30 {
+		return ErrWorkFactor
+	}
+	r.workFactor = logN
+	return nil
+}
+
+// Wrap 生成随机盐并包装文件密钥，stanza为 "-> scrypt 盐 工作因子"
+func (r *ScryptRecipient) Wrap(fileKey []byte) ([]*Stanza, error) {
+	salt := make([]byte, scryptSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	key, err := scryptKey(r.password, salt, r.workFactor)
+	if err != nil {
+		return nil, err
+	}
+	body, err := aeadSeal(key, fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return []*Stanza{{
+		Type: scryptStanzaType,
+		Args: []string{base64.RawStdEncoding.EncodeToString(salt), strconv.Itoa(r.workFactor)},
+		Body: body,
+	}}, nil
+}
+
+// ScryptIdentity 是口令身份
+type ScryptIdentity struct {
+	password      []byte
+	maxWorkFactor int
+}
+
+// NewScryptIdentity 返回使用默认最大工作因子的口令身份
+func NewScryptIdentity(password string) (*ScryptIdentity, error) {
+	if password == "" {
+		return nil, ErrInvalidIdentity
+	}
+	return &ScryptIdentity{password: []byte(password), maxWorkFactor: DefaultMaxWorkFactor}, nil
+}
+
+// SetMaxWorkFactor 设置解密时允许的最大工作因子，防止恶意文件消耗过多CPU和内存
+func (i *ScryptIdentity) SetMaxWorkFactor(logN int) error {
+	if logN < 1 || logN > 30 {
+		return ErrWorkFactor
+	}
+	i.maxWorkFactor = logN
+	return nil
+}
+
+// Unwrap 解出scrypt stanza中的文件密钥。scrypt stanza必须是唯一的stanza，
+// 工作因子超过上限时返回ErrWorkFactor，口令错误时返回ErrIncorrectIdentity
+func (i *ScryptIdentity) Unwrap(stanzas []*Stanza) ([]byte, error) {
+	for _, s := range stanzas {
+		if s.Type == scryptStanzaType && len(stanzas) != 1 {
+			return nil, ErrMalformedHeader
+		}
+	}
+	if len(stanzas) != 1 || stanzas[0].Type != scryptStanzaType {
+		return nil, ErrIncorrectIdentity
+	}
+	s := stanzas[0]
+	if len(s.Args) != 2 {
+		return nil, ErrMalformedHeader
+	}
+	salt, err := decodeBase64(s.Args[0])
+	if err != nil || len(salt) != scryptSaltSize {
+		return nil, ErrMalformedHeader
+	}
+	// 工作因子为不带前导零的十进制数
+	logN, err := strconv.Atoi(s.Args[1])
+	if err != nil || strconv.Itoa(logN) != s.Args[1] || logN <= 0 {
+		return nil, ErrMalformedHeader
+	}
+	if logN > i.maxWorkFactor {
+		return nil, ErrWorkFactor
+	}
+	if len(s.Body) != FileKeySize+chacha20poly1305.Overhead {
+		return nil, ErrMalformedHeader
+	}
+	key, err := scryptKey(i.password, salt, logN)
+	if err != nil {
+		return nil, err
+	}
+	fileKey, err := aeadOpen(key, s.Body)
+	if err != nil {
+		return nil, ErrIncorrectIdentity
+	}
+	return fileKey, nil
+}
+
+// scryptKey 派生包装密钥：scrypt(口令, 标签 || 盐, N = 2^logN, r = 8, p = 1)
+func scryptKey(password, salt []byte, logN int) ([]byte, error) {
+	s := append([]byte(scryptLabel), salt...)
+	return scrypt.Key(password, s, 1<<logN, 8, 1, chacha20poly1305.KeySize)
+}
@@ -0,0 +1,184 @@
+package age
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"strings"
+
+	"github.com/laenix/gsc/chacha20poly1305"
+	"github.com/laenix/gsc/kdf/hkdf"
+	"github.com/laenix/gsc/x25519"
+)
+
+const (
+	x25519StanzaType = "X25519"
+	x25519Label      = "age-encryption.org/v1/X25519"
+
+	recipientPrefix = "age"
+	identityPrefix  = "AGE-SECRET-KEY-"
+)
+
+// X25519Recipient 是X25519公钥接收者，字符串形式为 age1...
+type X25519Recipient struct {
+	key *x25519.PublicKey
+}
+
+// ParseX25519Recipient 解析Bech32编码的接收者公钥（age1...）
+func ParseX25519Recipient(s string) (*X25519Recipient, error) {
+	hrp, data, ok := bech32Decode(s)
+	if !ok || hrp != recipientPrefix || len(data) != 32 {
+		return nil, ErrInvalidRecipient
+	}
+	key, err := x25519.NewPublicKey(data)
+	if err != nil {
+		return nil, ErrInvalidRecipient
+	}
+	return &X25519Recipient{key: key}, nil
+}
+
+// String 返回Bech32编码的公钥
+func (r *X25519Recipient) String() string {
+	return bech32Encode(recipientPrefix, r.key.Bytes())
+}
+
+// Wrap 使用临时X25519密钥对包装文件密钥：
+// 共享密钥 = X25519(临时私钥, 接收者公钥)，包装密钥 = HKDF-SHA256(共享密钥, 临时公钥 || 接收者公钥, 标签)，
+// stanza正文 = ChaCha20-Poly1305(包装密钥, 全零nonce, 文件密钥)
+func (r *X25519Recipient) Wrap(fileKey []byte) ([]*Stanza, error) {
+	ephemeral, err := x25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := ephemeral.ECDH(r.key)
+	if err != nil {
+		return nil, ErrInvalidRecipient
+	}
+	share := ephemeral.PublicKey().Bytes()
+	body, err := aeadSeal(x25519WrapKey(shared, share, r.key.Bytes()), fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return []*Stanza{{
+		Type: x25519StanzaType,
+		Args: []string{base64.RawStdEncoding.EncodeToString(share)},
+		Body: body,
+	}}, nil
+}
+
+// X25519Identity 是X25519私钥身份，字符串形式为 AGE-SECRET-KEY-1...
+type X25519Identity struct {
+	key *x25519.PrivateKey
+}
+
+// GenerateX25519Identity 生成新的X25519身份
+func GenerateX25519Identity() (*X25519Identity, error) {
+	key, err := x25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &X25519Identity{key: key}, nil
+}
+
+// ParseX25519Identity 解析Bech32编码的私钥（AGE-SECRET-KEY-1...）
+func ParseX25519Identity(s string) (*X25519Identity, error) {
+	hrp, data, ok := bech32Decode(s)
+	if !ok || hrp != strings.ToLower(identityPrefix) || len(data) != 32 {
+		return nil, ErrInvalidIdentity
+	}
+	key, err := x25519.NewPrivateKey(data)
+	if err != nil {
+		return nil, ErrInvalidIdentity
+	}
+	return &X25519Identity{key: key}, nil
+}
+
+// ParseIdentities 解析age-keygen生成的密钥文件：每行一个身份，忽略空行和以#开头的注释行
+func ParseIdentities(r io.Reader) ([]Identity, error) {
+	var ids []Identity
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, err := ParseX25519Identity(line)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, ErrNoIdentities
+	}
+	return ids, nil
+}
+
+// Recipient 返回对应的接收者
+func (i *X25519Identity) Recipient() *X25519Recipient {
+	return &X25519Recipient{key: i.key.PublicKey()}
+}
+
+// String 返回Bech32编码的私钥（大写）
+func (i *X25519Identity) String() string {
+	return strings.ToUpper(bech32Encode(identityPrefix, i.key.Bytes()))
+}
+
+// Unwrap 依次尝试X25519类型的stanza，格式错误的X25519 stanza返回ErrMalformedHeader
+func (i *X25519Identity) Unwrap(stanzas []*Stanza) ([]byte, error) {
+	for _, s := range stanzas {
+		if s.Type != x25519StanzaType {
+			continue
+		}
+		if len(s.Args) != 1 {
+			return nil, ErrMalformedHeader
+		}
+		share, err := decodeBase64(s.Args[0])
+		if err != nil || len(share) != 32 || len(s.Body) != FileKeySize+chacha20poly1305.Overhead {
+			return nil, ErrMalformedHeader
+		}
+		pub, err := x25519.NewPublicKey(share)
+		if err != nil {
+			return nil, ErrMalformedHeader
+		}
+		shared, err := i.key.ECDH(pub)
+		if err != nil {
+			return nil, ErrMalformedHeader
+		}
+		fileKey, err := aeadOpen(x25519WrapKey(shared, share, i.key.PublicKey().Bytes()), s.Body)
+		if err == nil {
+			return fileKey, nil
+		}
+	}
+	return nil, ErrIncorrectIdentity
+}
+
+// x25519WrapKey 派生包装密钥
+func x25519WrapKey(shared, share, recipient []byte) []byte {
+	salt := append(append([]byte(nil), share...), recipient...)
+	key, _ := hkdf.Key(sha256.New, shared, salt, []byte(x25519Label), chacha20poly1305.KeySize)
+	return key
+}
+
+// aeadSeal 使用全零nonce加密文件密钥，每个包装密钥只使用一次
+func aeadSeal(key, fileKey []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nil, make([]byte, chacha20poly1305.NonceSize), fileKey, nil), nil
+}
+
+// aeadOpen 解密stanza正文中的文件密钥
+func aeadOpen(key, body []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Open(nil, make([]byte, chacha20poly1305.NonceSize), body, nil)
+}
@@ -0,0 +1,208 @@
+package blake2b
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+
+	"github.com/laenix/gsc/blake2b/internal"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+// BLAKE2b算法常量
+const (
+	// 块大小（字节）
+	BlockSize = 128
+	// 最大摘要大小（字节）
+	Size = 64
+	// BLAKE2b-256的摘要大小（字节）
+	Size256 = 32
+	// 最大密钥长度（字节）
+	MaxKeySize = 64
+)
+
+// 错误定义
+var (
+	ErrInvalidSize    = gscerr.New(gscerr.ErrParameter, "blake2b: digest size must be 1-64 bytes")
+	ErrInvalidKeySize = gscerr.New(gscerr.ErrKeySize, "blake2b: key must not exceed 64 bytes")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidSize:    "blake2b: 摘要长度必须在1-64字节之间",
+		ErrInvalidKeySize: "blake2b: 密钥长度不能超过64字节",
+	})
+}
+
+// BLAKE2b摘要算法结构体
+type digest struct {
+	h    [8]uint64       // 哈希值状态
+	t    [2]uint64       // 已处理的字节数（128位计数器）
+	x    [BlockSize]byte // 当前块的缓冲区
+	nx   int             // 缓冲区中的字节数
+	size int             // 摘要长度
+	key  [BlockSize]byte // 密钥（填充为一个完整块）
+	klen int             // 密钥长度
+}
+
+// 实现的接口检查
+var _ hash.Hash = (*digest)(nil)
+
+// New 创建输出size字节的BLAKE2b实例，key非空时为带密钥的MAC模式
+func New(size int, key []byte) (hash.Hash, error) {
+	if size < 1 || size > Size {
+		return nil, ErrInvalidSize
+	}
+	if len(key) > MaxKeySize {
+		return nil, ErrInvalidKeySize
+	}
+
+	d := &digest{size: size, klen: len(key)}
+	copy(d.key[:], key)
+	d.Reset()
+	return d, nil
+}
+
+// New512 创建BLAKE2b-512实例
+func New512(key []byte) (hash.Hash, error) {
+	return New(Size, key)
+}
+
+// New256 创建BLAKE2b-256实例
+func New256(key []byte) (hash.Hash, error) {
+	return New(Size256, key)
+}
+
+// Reset 重置哈希状态
+func (d *digest) Reset() {
+	d.h = internal.IV
+	// 参数块第一个字：摘要长度 | 密钥长度<<8 | fanout=1<<16 | depth=1<<24
+	d.h[0] ^= uint64(d.size) | uint64(d.klen)<<8 | 1<<16 | 1<<24
+	d.t = [2]uint64{}
+	d.nx = 0
+
+	// 带密钥时，密钥填充为一个完整块作为第一个消息块
+	if d.klen > 0 {
+		d.x = d.key
+		d.nx = BlockSize
+	}
+}
+
+// Size 返回摘要长度
+func (d *digest) Size() int {
+	return d.size
+}
+
+// BlockSize 返回块大小
+func (d *digest) BlockSize() int {
+	return BlockSize
+}
+
+// Write 向哈希计算中添加更多数据
+func (d *digest) Write(p []byte) (int, error) {
+	nn := len(p)
+
+	for len(p) > 0 {
+		// 缓冲区已满且后面还有数据，才能确定它不是最后一块
+		if d.nx == BlockSize {
+			d.compress(d.x[:], false)
+			d.nx = 0
+		}
+		n := copy(d.x[d.nx:], p)
+		d.nx += n
+		p = p[n:]
+	}
+
+	return nn, nil
+}
+
+// Sum 计算并返回当前数据的哈希值
+func (d *digest) Sum(in []byte) []byte {
+	// 克隆当前状态
+	d0 := *d
+
+	// 最后一块补0后以结束标志压缩
+	clear(d0.x[d0.nx:])
+	d0.compress(d0.x[:], true)
+
+	var out [Size]byte
+	for i, v := range d0.h {
+		binary.LittleEndian.PutUint64(out[i*8:], v)
+	}
+	return append(in, out[:d.size]...)
+}
+
+// compress 处理一个消息块，last表示是否为最后一块
+func (d *digest) compress(block []byte, last bool) {
+	// 更新字节计数器（最后一块只计入实际数据长度）
+	n := uint64(BlockSize)
+	if last {
+		n = uint64(d.nx)
+	}
+	var carry uint64
+	d.t[0], carry = bits.Add64(d.t[0], n, 0)
+	d.t[1] += carry
+
+	var m [16]uint64
+	for i := range m {
+		m[i] = binary.LittleEndian.Uint64(block[i*8:])
+	}
+
+	var v [16]uint64
+	copy(v[:8], d.h[:])
+	copy(v[8:], internal.IV[:])
+	v[12] ^= d.t[0]
+	v[13] ^= d.t[1]
+	if last {
+		v[14] = ^v[14]
+	}
+
+	// 12轮，每轮4次列变换和4次对角线变换
+	for i := 0; i < 12; i++ {
+		s := &internal.SIGMA[i]
+		g(&v, 0, 4, 8, 12, m[s[0]], m[s[1]])
+		g(&v, 1, 5, 9, 13, m[s[2]], m[s[3]])
+		g(&v, 2, 6, 10, 14, m[s[4]], m[s[5]])
+		g(&v, 3, 7, 11, 15, m[s[6]], m[s[7]])
+		g(&v, 0, 5, 10, 15, m[s[8]], m[s[9]])
+		g(&v, 1, 6, 11, 12, m[s[10]], m[s[11]])
+		g(&v, 2, 7, 8, 13, m[s[12]], m[s[13]])
+		g(&v, 3, 4, 9, 14, m[s[14]], m[s[15]])
+	}
+
+	for i := range d.h {
+		d.h[i] ^= v[i] ^ v[i+8]
+	}
+}
+
+// g 为BLAKE2b的混合函数
+func g(v *[16]uint64, a, b, c, d int, x, y uint64) {
+	v[a] = v[a] + v[b] + x
+	v[d] = bits.RotateLeft64(v[d]^v[a], -32)
+	v[c] = v[c] + v[d]
+	v[b] = bits.RotateLeft64(v[b]^v[c], -24)
+	v[a] = v[a] + v[b] + y
+	v[d] = bits.RotateLeft64(v[d]^v[a], -16)
+	v[c] = v[c] + v[d]
+	v[b] = bits.RotateLeft64(v[b]^v[c], -63)
+}
+
+// Sum512 计算数据的BLAKE2b-512哈希值
+func Sum512(data []byte) [Size]byte {
+	var out [Size]byte
+	d, _ := New512(nil)
+	d.Write(data)
+	d.Sum(out[:0])
+	return out
+}
+
+// Sum256 计算数据的BLAKE2b-256哈希值
+func Sum256(data []byte) [Size256]byte {
+	var out [Size256]byte
+	d, _ := New256(nil)
+	d.Write(data)
+	d.Sum(out[:0])
+	return out
+}
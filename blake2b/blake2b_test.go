@@ -0,0 +1,79 @@
+package blake2b
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+type blake2bTest struct {
+	size int
+	key  []byte
+	in   string
+	out  string
+}
+
+func seq(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return b
+}
+
+// 测试向量（RFC 7693附录A及官方参考实现）
+var golden = []blake2bTest{
+	{Size, nil, "", "786a02f742015903c6c6fd852552d272912f4740e15847618a86e217f71f5419d25e1031afee585313896444934eb04b903a685b1448b755d56f701afe9be2ce"},
+	{Size, nil, "abc", "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923"},
+	{Size256, nil, strings.Repeat("a", 300), "3c1292de00a518e36823f9ff908ac2da46be38718c018713403461df077e15f6"},
+	{Size, []byte("key"), "", "5b3cfd8f422b490b764b55eceb330b500c79cbefa9a928ad00202b8b3c5dd778a81122570434a2e3b8bfd028d105dfefd0a9576e88ed66de742ca9fbb5f8d2b6"},
+	{Size, seq(64), string(seq(128)), "72065ee4dd91c2d8509fa1fc28a37c7fc9fa7d5b3f8ad3d0d7a25626b57b1b44788d4caf806290425f9890a3a2a35a905ab4b37acfd0da6e4517b2525c9651e4"},
+}
+
+// 测试New、Write和Sum
+func TestGolden(t *testing.T) {
+	for i, test := range golden {
+		h, err := New(test.size, test.key)
+		if err != nil {
+			t.Fatalf("测试 #%d: 创建失败: %v", i, err)
+		}
+		h.Write([]byte(test.in))
+		if got := hex.EncodeToString(h.Sum(nil)); got != test.out {
+			t.Errorf("测试 #%d: 结果不匹配\n期望值: %s\n实际值: %s", i, test.out, got)
+		}
+
+		// 逐字节写入并在Reset后重复
+		h.Reset()
+		for j := 0; j < len(test.in); j++ {
+			h.Write([]byte{test.in[j]})
+		}
+		if got := hex.EncodeToString(h.Sum(nil)); got != test.out {
+			t.Errorf("测试 #%d: 分块写入结果不匹配\n期望值: %s\n实际值: %s", i, test.out, got)
+		}
+	}
+}
+
+// 测试Sum512/Sum256快捷函数
+func TestSumFunctions(t *testing.T) {
+	sum := Sum512([]byte("abc"))
+	if hex.EncodeToString(sum[:]) != golden[1].out {
+		t.Errorf("Sum512结果不匹配: %x", sum)
+	}
+	h, _ := New256(nil)
+	h.Write([]byte("abc"))
+	sum256 := Sum256([]byte("abc"))
+	if !bytes.Equal(h.Sum(nil), sum256[:]) {
+		t.Error("Sum256与New256结果不一致")
+	}
+}
+
+// 测试无效参数
+func TestInvalidParams(t *testing.T) {
+	if _, err := New(0, nil); err != ErrInvalidSize {
+		t.Errorf("期望ErrInvalidSize，实际: %v", err)
+	}
+	if _, err := New(Size, make([]byte, 65)); err != ErrInvalidKeySize {
+		t.Errorf("期望ErrInvalidKeySize，实际: %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+package blowfish
+
+import "encoding/binary"
+
+// EncryptBlocks 加密src中的多个块并写入dst，dst与src可以是同一切片
+// src长度必须是8的整数倍且dst不短于src，否则返回ErrInvalidBlockSize
+func (b *Blowfish) EncryptBlocks(dst, src []byte) error {
+	if len(src)%BlockSize != 0 || len(dst) < len(src) {
+		return ErrInvalidBlockSize
+	}
+	for i := 0; i < len(src); i += BlockSize {
+		left, right := b.encryptBlock(binary.BigEndian.Uint32(src[i:]), binary.BigEndian.Uint32(src[i+4:]))
+		binary.BigEndian.PutUint32(dst[i:], left)
+		binary.BigEndian.PutUint32(dst[i+4:], right)
+	}
+	return nil
+}
+
+// DecryptBlocks 解密src中的多个块并写入dst，约定与EncryptBlocks相同
+func (b *Blowfish) DecryptBlocks(dst, src []byte) error {
+	if len(src)%BlockSize != 0 || len(dst) < len(src) {
+		return ErrInvalidBlockSize
+	}
+	for i := 0; i < len(src); i += BlockSize {
+		left, right := b.decryptBlock(binary.BigEndian.Uint32(src[i:]), binary.BigEndian.Uint32(src[i+4:]))
+		binary.BigEndian.PutUint32(dst[i:], left)
+		binary.BigEndian.PutUint32(dst[i+4:], right)
+	}
+	return nil
+}
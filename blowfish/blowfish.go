@@ -2,9 +2,10 @@ package blowfish
 
 import (
 	"encoding/binary"
-	"errors"
 
 	"github.com/laenix/gsc/blowfish/internal"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
 )
 
 const (
@@ -18,25 +19,34 @@ const (
 
 // Blowfish 结构体定义Blowfish密码
 type Blowfish struct {
-	p [18]uint32     // P-box
-	s [4][256]uint32 // S-boxes
+	p       [18]uint32     // P-box
+	s       [4][256]uint32 // S-boxes
+	keySize int            // 密钥长度（字节）
 }
 
 // 错误定义
 var (
-	ErrInvalidKeySize   = errors.New("blowfish: 密钥长度必须在4-56字节之间")
-	ErrInvalidBlockSize = errors.New("blowfish: 数据块必须是8字节")
+	ErrInvalidKeySize   = gscerr.New(gscerr.ErrKeySize, "blowfish: key must be 4-56 bytes")
+	ErrInvalidBlockSize = gscerr.New(gscerr.ErrBlockSize, "blowfish: block must be 8 bytes")
 )
 
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidKeySize:   "blowfish: 密钥长度必须在4-56字节之间",
+		ErrInvalidBlockSize: "blowfish: 数据块必须是8字节",
+	})
+}
+
 // New 创建一个新的Blowfish实例
 func New(key []byte) (*Blowfish, error) {
 	// 验证密钥长度
 	if len(key) < MinKeySize || len(key) > MaxKeySize {
-		return nil, ErrInvalidKeySize
+		return nil, gscerr.KeySize(ErrInvalidKeySize, "Blowfish", len(key))
 	}
 
 	// 创建Blowfish实例
-	b := &Blowfish{}
+	b := &Blowfish{keySize: len(key)}
 
 	// 初始化P和S盒
 	b.initBoxes()
@@ -47,6 +57,35 @@ func New(key []byte) (*Blowfish, error) {
 	return b, nil
 }
 
+// NewSaltedCipher 创建一个带盐的Blowfish实例，用于bcrypt的EksBlowfish密钥编排
+// 与New不同，密钥长度不受56字节的限制，salt为空时等同于普通密钥编排
+func NewSaltedCipher(key, salt []byte) (*Blowfish, error) {
+	if len(key) == 0 {
+		return nil, ErrInvalidKeySize
+	}
+
+	b := &Blowfish{keySize: len(key)}
+	b.initBoxes()
+	b.expandKeyWithSalt(key, salt)
+	return b, nil
+}
+
+// ExpandKey 使用密钥对已有实例再执行一次无盐的密钥扩展
+// 不会重置P盒和S盒，是EksBlowfish昂贵密钥编排中的基本步骤
+func ExpandKey(key []byte, b *Blowfish) {
+	b.expandKey(key)
+}
+
+// ExpandKeyWithSalt 使用密钥和盐对已有实例再执行一次密钥扩展
+func ExpandKeyWithSalt(key, salt []byte, b *Blowfish) {
+	b.expandKeyWithSalt(key, salt)
+}
+
+// KeySize 返回创建实例时使用的密钥长度（字节）
+func (b *Blowfish) KeySize() int {
+	return b.keySize
+}
+
 // BlockSize 返回区块大小
 func (b *Blowfish) BlockSize() int {
 	return BlockSize
@@ -188,3 +227,47 @@ func (b *Blowfish) expandKey(key []byte) {
 		}
 	}
 }
+
+// expandKeyWithSalt 使用密钥和盐修改P盒和S盒（EksBlowfish的ExpandKey）
+// 每次加密前，将盐按32位字循环异或到当前的左右两半
+func (b *Blowfish) expandKeyWithSalt(key, salt []byte) {
+	if len(salt) == 0 {
+		b.expandKey(key)
+		return
+	}
+
+	j := 0
+	for i := 0; i < 18; i++ {
+		b.p[i] ^= nextWord(key, &j)
+	}
+
+	j = 0
+	var l, r uint32
+	for i := 0; i < 18; i += 2 {
+		l ^= nextWord(salt, &j)
+		r ^= nextWord(salt, &j)
+		l, r = b.encryptBlock(l, r)
+		b.p[i] = l
+		b.p[i+1] = r
+	}
+
+	for i := 0; i < 4; i++ {
+		for k := 0; k < 256; k += 2 {
+			l ^= nextWord(salt, &j)
+			r ^= nextWord(salt, &j)
+			l, r = b.encryptBlock(l, r)
+			b.s[i][k] = l
+			b.s[i][k+1] = r
+		}
+	}
+}
+
+// nextWord 从data中循环读取下一个大端序32位字
+func nextWord(data []byte, j *int) uint32 {
+	var w uint32
+	for k := 0; k < 4; k++ {
+		w = (w << 8) | uint32(data[*j%len(data)])
+		*j++
+	}
+	return w
+}
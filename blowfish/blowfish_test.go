@@ -0,0 +1,97 @@
+package blowfish
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/laenix/gsc/blowfish/internal"
+)
+
+// 测试Schneier发布的Blowfish示例向量
+func TestVectors(t *testing.T) {
+	for _, tt := range []struct{ key, plain, cipher string }{
+		{"0000000000000000", "0000000000000000", "4ef997456198dd78"},
+		{"ffffffffffffffff", "ffffffffffffffff", "51866fd5b85ecb8a"},
+		{"3000000000000000", "1000000000000001", "7d856f9a613063f2"},
+	} {
+		key, _ := hex.DecodeString(tt.key)
+		src, _ := hex.DecodeString(tt.plain)
+		want, _ := hex.DecodeString(tt.cipher)
+
+		c, err := New(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := c.Encrypt(src)
+		if !bytes.Equal(got, want) {
+			t.Fatalf("密钥%s: 密文为%x，期望%s", tt.key, got, tt.cipher)
+		}
+		if back, _ := c.Decrypt(got); !bytes.Equal(back, src) {
+			t.Fatalf("密钥%s: 解密未能还原明文", tt.key)
+		}
+	}
+}
+
+// piFraction 按Machin公式 π = 16·arctan(1/5) - 4·arctan(1/239) 计算π小数部分的前n个32位字
+func piFraction(n int) []uint32 {
+	const guard = 64
+	bits := uint(32*n + guard)
+	one := new(big.Int).Lsh(big.NewInt(1), bits)
+
+	arctanInv := func(x int64) *big.Int {
+		term := new(big.Int).Quo(one, big.NewInt(x))
+		sum := new(big.Int).Set(term)
+		x2 := big.NewInt(x * x)
+		d := new(big.Int)
+		for k := int64(1); term.Sign() != 0; k++ {
+			term.Quo(term, x2)
+			d.Quo(term, big.NewInt(2*k+1))
+			if k%2 == 1 {
+				sum.Sub(sum, d)
+			} else {
+				sum.Add(sum, d)
+			}
+		}
+		return sum
+	}
+
+	pi := new(big.Int).Mul(arctanInv(5), big.NewInt(16))
+	pi.Sub(pi, new(big.Int).Mul(arctanInv(239), big.NewInt(4)))
+	pi.Sub(pi, new(big.Int).Mul(one, big.NewInt(3)))
+	pi.Rsh(pi, guard)
+
+	words := make([]uint32, n)
+	mask := big.NewInt(0xffffffff)
+	for i := range words {
+		w := new(big.Int).Rsh(pi, uint(32*(n-1-i)))
+		words[i] = uint32(w.And(w, mask).Uint64())
+	}
+	return words
+}
+
+// 测试初始P盒和S盒依次等于π的小数部分（十六进制），与Schneier的定义一致
+func TestStandardTables(t *testing.T) {
+	tables := StandardTables()
+	words := append(tables.PBox[:], tables.SBoxes[0][:]...)
+	for _, s := range tables.SBoxes[1:] {
+		words = append(words, s[:]...)
+	}
+
+	want := piFraction(len(words))
+	if want[0] != 0x243f6a88 {
+		t.Fatalf("π的小数部分计算错误: %#08x", want[0])
+	}
+	for i := range words {
+		if words[i] != want[i] {
+			t.Fatalf("第%d个字为%#08x，期望%#08x", i, words[i], want[i])
+		}
+	}
+
+	// 修改副本不影响内部常量
+	tables.PBox[0] = 0
+	if StandardTables().PBox[0] != internal.PBox[0] || internal.PBox[0] != 0x243f6a88 {
+		t.Fatal("修改副本影响了内部P盒")
+	}
+}
@@ -0,0 +1,17 @@
+package blowfish
+
+import "github.com/laenix/gsc/blowfish/internal"
+
+// Tables 是Blowfish初始P盒和S盒（π的小数位）的只读副本，供分析和教学工具使用
+type Tables struct {
+	PBox   [18]uint32
+	SBoxes [4][256]uint32
+}
+
+// StandardTables 返回Blowfish初始常量表的副本，修改返回值不会影响加密实现
+func StandardTables() Tables {
+	return Tables{
+		PBox:   internal.PBox,
+		SBoxes: [4][256]uint32{internal.SBox0, internal.SBox1, internal.SBox2, internal.SBox3},
+	}
+}
@@ -0,0 +1,198 @@
+// Package chacha20 实现ChaCha20流密码（RFC 8439）及其扩展nonce变体XChaCha20
+package chacha20
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/internal/alias"
+)
+
+const (
+	// KeySize 是密钥长度（字节）
+	KeySize = 32
+	// NonceSize 是ChaCha20（RFC 8439）的nonce长度（字节）
+	NonceSize = 12
+	// NonceSizeX 是XChaCha20的nonce长度（字节）
+	NonceSizeX = 24
+	// BlockSize 是每次生成的密钥流长度（字节）
+	BlockSize = 64
+)
+
+// 错误定义
+var (
+	ErrInvalidKeySize   = gscerr.New(gscerr.ErrKeySize, "chacha20: key must be 32 bytes")
+	ErrInvalidNonceSize = gscerr.New(gscerr.ErrNonceSize, "chacha20: nonce must be 12 or 24 bytes")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidKeySize:   "chacha20: 密钥长度必须为32字节",
+		ErrInvalidNonceSize: "chacha20: nonce长度必须为12或24字节",
+	})
+}
+
+// sigma 是常量"expand 32-byte k"
+var sigma = [4]uint32{0x61707865, 0x3320646e, 0x79622d32, 0x6b206574}
+
+// Cipher 是ChaCha20密钥流生成器，实现crypto/cipher.Stream
+type Cipher struct {
+	key     [8]uint32
+	nonce   [3]uint32
+	counter uint32
+	// overflow 表示32位块计数器已用完
+	overflow bool
+
+	// buf 缓存当前块中尚未使用的密钥流
+	buf [BlockSize]byte
+	len int
+}
+
+// New 使用32字节密钥和nonce创建ChaCha20实例
+// nonce为12字节时为RFC 8439的ChaCha20，为24字节时为XChaCha20：
+// 先以HChaCha20从密钥和nonce的前16字节派生子密钥，再以剩余8字节作为nonce。
+// 块计数器从0开始，可以用SetCounter调整
+func New(key, nonce []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, gscerr.KeySize(ErrInvalidKeySize, "ChaCha20", len(key), KeySize)
+	}
+	switch len(nonce) {
+	case NonceSize:
+	case NonceSizeX:
+		subKey, _ := HChaCha20(key, nonce[:16])
+		key = subKey
+		nonce = append(make([]byte, 4), nonce[16:]...)
+	default:
+		return nil, ErrInvalidNonceSize
+	}
+
+	c := &Cipher{}
+	for i := range c.key {
+		c.key[i] = binary.LittleEndian.Uint32(key[i*4:])
+	}
+	for i := range c.nonce {
+		c.nonce[i] = binary.LittleEndian.Uint32(nonce[i*4:])
+	}
+	return c, nil
+}
+
+// SetCounter 设置下一个密钥流块的计数器，丢弃已缓存的密钥流
+// 计数器不能回退到已使用过的值之前，否则会重用密钥流，此时panic
+func (c *Cipher) SetCounter(counter uint32) {
+	if c.overflow || counter < c.counter {
+		panic("chacha20: SetCounter attempted to rollback counter")
+	}
+	c.counter = counter
+	c.len = 0
+}
+
+// XORKeyStream 将src与密钥流异或写入dst，满足crypto/cipher.Stream的约定
+// dst短于src、dst与src部分重叠或密钥流用尽（256GiB）时panic
+func (c *Cipher) XORKeyStream(dst, src []byte) {
+	if len(src) == 0 {
+		return
+	}
+	if len(dst) < len(src) {
+		panic("chacha20: output smaller than input")
+	}
+	dst = dst[:len(src)]
+	if alias.InexactOverlap(dst, src) {
+		panic("chacha20: invalid buffer overlap")
+	}
+
+	// 先使用上次剩余的密钥流
+	if c.len > 0 {
+		n := subtle.XORBytes(dst, src, c.buf[BlockSize-c.len:])
+		c.len -= n
+		dst, src = dst[n:], src[n:]
+	}
+
+	for len(src) > 0 {
+		if c.overflow {
+			panic("chacha20: counter overflow, keystream exhausted")
+		}
+		c.block(&c.buf)
+		c.counter++
+		if c.counter == 0 {
+			c.overflow = true
+		}
+		n := subtle.XORBytes(dst, src, c.buf[:])
+		c.len = BlockSize - n
+		dst, src = dst[n:], src[n:]
+	}
+}
+
+// block 以当前计数器生成一个密钥流块
+func (c *Cipher) block(out *[BlockSize]byte) {
+	var x [16]uint32
+	x[0], x[1], x[2], x[3] = sigma[0], sigma[1], sigma[2], sigma[3]
+	copy(x[4:12], c.key[:])
+	x[12] = c.counter
+	copy(x[13:], c.nonce[:])
+
+	input := x
+	rounds(&x)
+	for i := range x {
+		binary.LittleEndian.PutUint32(out[i*4:], x[i]+input[i])
+	}
+}
+
+// HChaCha20 从32字节密钥和16字节输入派生32字节子密钥，用于XChaCha20
+func HChaCha20(key, nonce []byte) ([]byte, error) {
+	if len(key) != KeySize {
+		return nil, gscerr.KeySize(ErrInvalidKeySize, "ChaCha20", len(key), KeySize)
+	}
+	if len(nonce) != 16 {
+		return nil, ErrInvalidNonceSize
+	}
+
+	var x [16]uint32
+	x[0], x[1], x[2], x[3] = sigma[0], sigma[1], sigma[2], sigma[3]
+	for i := 0; i < 8; i++ {
+		x[4+i] = binary.LittleEndian.Uint32(key[i*4:])
+	}
+	for i := 0; i < 4; i++ {
+		x[12+i] = binary.LittleEndian.Uint32(nonce[i*4:])
+	}
+	rounds(&x)
+
+	out := make([]byte, KeySize)
+	for i := 0; i < 4; i++ {
+		binary.LittleEndian.PutUint32(out[i*4:], x[i])
+		binary.LittleEndian.PutUint32(out[16+i*4:], x[12+i])
+	}
+	return out, nil
+}
+
+// rounds 执行20轮（10次列轮和对角轮）变换
+func rounds(x *[16]uint32) {
+	for i := 0; i < 10; i++ {
+		// 列轮
+		x[0], x[4], x[8], x[12] = quarterRound(x[0], x[4], x[8], x[12])
+		x[1], x[5], x[9], x[13] = quarterRound(x[1], x[5], x[9], x[13])
+		x[2], x[6], x[10], x[14] = quarterRound(x[2], x[6], x[10], x[14])
+		x[3], x[7], x[11], x[15] = quarterRound(x[3], x[7], x[11], x[15])
+		// 对角轮
+		x[0], x[5], x[10], x[15] = quarterRound(x[0], x[5], x[10], x[15])
+		x[1], x[6], x[11], x[12] = quarterRound(x[1], x[6], x[11], x[12])
+		x[2], x[7], x[8], x[13] = quarterRound(x[2], x[7], x[8], x[13])
+		x[3], x[4], x[9], x[14] = quarterRound(x[3], x[4], x[9], x[14])
+	}
+}
+
+// quarterRound 是ChaCha的四分之一轮函数
+func quarterRound(a, b, c, d uint32) (uint32, uint32, uint32, uint32) {
+	a += b
+	d = bits.RotateLeft32(d^a, 16)
+	c += d
+	b = bits.RotateLeft32(b^c, 12)
+	a += b
+	d = bits.RotateLeft32(d^a, 8)
+	c += d
+	b = bits.RotateLeft32(b^c, 7)
+	return a, b, c, d
+}
@@ -0,0 +1,99 @@
+package chacha20
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+var (
+	testKey, _ = hex.DecodeString("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	sunscreen  = []byte("Ladies and Gentlemen of the class of '99: If I could offer you only one tip for the future, sunscreen would be it.")
+)
+
+// 测试RFC 8439第2.4.2节的加密向量
+func TestXORKeyStream(t *testing.T) {
+	nonce, _ := hex.DecodeString("000000000000004a00000000")
+	want, _ := hex.DecodeString("6e2e359a2568f98041ba0728dd0d6981e97e7aec1d4360c20a27afccfd9fae0bf91b65c5524733ab8f593dabcd62b3571639d624e65152ab8f530c359f0861d807ca0dbf500d6a6156a38e088a22b65e52bc514d16ccf806818ce91ab77937365af90bbf74a35be6b40b8eedf2785e42874d")
+
+	c, err := New(testKey, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetCounter(1)
+	got := make([]byte, len(sunscreen))
+	c.XORKeyStream(got, sunscreen)
+	if !bytes.Equal(got, want) {
+		t.Fatalf("密文不匹配\n预期: %x\n实际: %x", want, got)
+	}
+
+	// 分段调用的结果与一次调用相同
+	for _, step := range []int{1, 5, 63, 64, 65} {
+		c, _ := New(testKey, nonce)
+		c.SetCounter(1)
+		got := bytes.Clone(sunscreen)
+		for i := 0; i < len(got); i += step {
+			end := min(i+step, len(got))
+			c.XORKeyStream(got[i:end], got[i:end])
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("每次处理%d字节时密文不匹配", step)
+		}
+	}
+}
+
+// 测试draft-irtf-cfrg-xchacha第2.2.1节的HChaCha20向量
+func TestHChaCha20(t *testing.T) {
+	nonce, _ := hex.DecodeString("000000090000004a0000000031415927")
+	want, _ := hex.DecodeString("82413b4227b27bfed30e42508a877d73a0f9e4d58a74a853c12ec41326d3ecdc")
+	got, err := HChaCha20(testKey, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("子密钥不匹配\n预期: %x\n实际: %x", want, got)
+	}
+}
+
+// 测试XChaCha20等价于以HChaCha20子密钥运行的ChaCha20
+func TestXChaCha20(t *testing.T) {
+	nonce := make([]byte, NonceSizeX)
+	for i := range nonce {
+		nonce[i] = byte(i + 0x40)
+	}
+	x, err := New(testKey, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subKey, _ := HChaCha20(testKey, nonce[:16])
+	c, _ := New(subKey, append(make([]byte, 4), nonce[16:]...))
+
+	a := make([]byte, 200)
+	b := make([]byte, 200)
+	x.XORKeyStream(a, a)
+	c.XORKeyStream(b, b)
+	if !bytes.Equal(a, b) {
+		t.Fatal("XChaCha20的密钥流不正确")
+	}
+}
+
+func TestInvalidParameters(t *testing.T) {
+	if _, err := New(testKey[:16], make([]byte, NonceSize)); !errors.Is(err, ErrInvalidKeySize) {
+		t.Errorf("16字节密钥应返回ErrInvalidKeySize，实际: %v", err)
+	}
+	if _, err := New(testKey, make([]byte, 8)); err != ErrInvalidNonceSize {
+		t.Errorf("8字节nonce应返回ErrInvalidNonceSize，实际: %v", err)
+	}
+
+	c, _ := New(testKey, make([]byte, NonceSize))
+	c.XORKeyStream(make([]byte, 100), make([]byte, 100))
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("回退计数器时应panic")
+			}
+		}()
+		c.SetCounter(0)
+	}()
+}
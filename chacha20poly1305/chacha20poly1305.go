@@ -0,0 +1,161 @@
+// Package chacha20poly1305 实现ChaCha20-Poly1305认证加密（RFC 8439）
+// 及其扩展nonce变体XChaCha20-Poly1305
+package chacha20poly1305
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+
+	"github.com/laenix/gsc/chacha20"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/internal/alias"
+	"github.com/laenix/gsc/poly1305"
+)
+
+const (
+	// KeySize 是密钥长度（字节）
+	KeySize = chacha20.KeySize
+	// NonceSize 是ChaCha20-Poly1305的nonce长度（字节）
+	NonceSize = chacha20.NonceSize
+	// NonceSizeX 是XChaCha20-Poly1305的nonce长度（字节），可以安全地随机生成
+	NonceSizeX = chacha20.NonceSizeX
+	// Overhead 是认证标签长度（字节）
+	Overhead = poly1305.TagSize
+)
+
+// 错误定义
+var (
+	ErrInvalidKeySize = gscerr.New(gscerr.ErrKeySize, "chacha20poly1305: key must be 32 bytes")
+	ErrAuthFailed     = gscerr.New(gscerr.ErrAuthFailed, "chacha20poly1305: message authentication failed")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidKeySize: "chacha20poly1305: 密钥长度必须为32字节",
+		ErrAuthFailed:     "chacha20poly1305: 消息认证失败",
+	})
+}
+
+// maxPlaintextSize 是单条消息的最大长度，受32位块计数器限制（计数器0用于生成Poly1305密钥）
+const maxPlaintextSize = (1<<32 - 1) * chacha20.BlockSize
+
+type chacha20poly1305 struct {
+	key       [KeySize]byte
+	nonceSize int
+}
+
+// New 返回使用32字节密钥的ChaCha20-Poly1305，nonce为12字节
+// 同一密钥下nonce绝不能重复使用，随机生成nonce时应使用NewX
+func New(key []byte) (cipher.AEAD, error) {
+	return newAEAD(key, NonceSize)
+}
+
+// NewX 返回使用32字节密钥的XChaCha20-Poly1305，nonce为24字节
+func NewX(key []byte) (cipher.AEAD, error) {
+	return newAEAD(key, NonceSizeX)
+}
+
+func newAEAD(key []byte, nonceSize int) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, gscerr.KeySize(ErrInvalidKeySize, "ChaCha20-Poly1305", len(key), KeySize)
+	}
+	c := &chacha20poly1305{nonceSize: nonceSize}
+	copy(c.key[:], key)
+	return c, nil
+}
+
+func (c *chacha20poly1305) NonceSize() int { return c.nonceSize }
+
+func (c *chacha20poly1305) Overhead() int { return Overhead }
+
+// Seal 加密并认证plaintext，将密文和标签追加到dst之后
+// nonce长度错误或明文过长时panic，与crypto/cipher.AEAD的约定一致
+func (c *chacha20poly1305) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != c.nonceSize {
+		panic("chacha20poly1305: incorrect nonce length")
+	}
+	if uint64(len(plaintext)) > maxPlaintextSize {
+		panic("chacha20poly1305: plaintext too large")
+	}
+
+	ret, out := sliceForAppend(dst, len(plaintext)+Overhead)
+	if alias.InexactOverlap(out, plaintext) {
+		panic("chacha20poly1305: invalid buffer overlap")
+	}
+
+	s, mac := c.setup(nonce)
+	s.XORKeyStream(out, plaintext)
+	authenticate(mac, additionalData, out[:len(plaintext)])
+	mac.Sum(out[len(plaintext):len(plaintext)])
+	return ret
+}
+
+// Open 验证并解密ciphertext，将明文追加到dst之后，认证失败时返回ErrAuthFailed且不写入dst
+func (c *chacha20poly1305) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != c.nonceSize {
+		panic("chacha20poly1305: incorrect nonce length")
+	}
+	if len(ciphertext) < Overhead {
+		return nil, ErrAuthFailed
+	}
+	if uint64(len(ciphertext)) > maxPlaintextSize+Overhead {
+		return nil, ErrAuthFailed
+	}
+
+	tag := ciphertext[len(ciphertext)-Overhead:]
+	ciphertext = ciphertext[:len(ciphertext)-Overhead]
+
+	s, mac := c.setup(nonce)
+	authenticate(mac, additionalData, ciphertext)
+	if subtle.ConstantTimeCompare(mac.Sum(nil), tag) != 1 {
+		return nil, ErrAuthFailed
+	}
+
+	ret, out := sliceForAppend(dst, len(ciphertext))
+	if alias.InexactOverlap(out, ciphertext) {
+		panic("chacha20poly1305: invalid buffer overlap")
+	}
+	s.XORKeyStream(out, ciphertext)
+	return ret, nil
+}
+
+// setup 返回计数器为1的密钥流和以计数器0的密钥流为一次性密钥的Poly1305
+func (c *chacha20poly1305) setup(nonce []byte) (*chacha20.Cipher, *poly1305.MAC) {
+	s, err := chacha20.New(c.key[:], nonce)
+	if err != nil {
+		panic(err)
+	}
+	var polyKey [poly1305.KeySize]byte
+	s.XORKeyStream(polyKey[:], polyKey[:])
+	s.SetCounter(1)
+	return s, poly1305.New(&polyKey)
+}
+
+// authenticate 按RFC 8439将aad和密文（各自补零到16字节）及两者的长度写入mac
+func authenticate(mac *poly1305.MAC, additionalData, ciphertext []byte) {
+	var zeros [poly1305.TagSize]byte
+	mac.Write(additionalData)
+	mac.Write(zeros[:(poly1305.TagSize-len(additionalData)%poly1305.TagSize)%poly1305.TagSize])
+	mac.Write(ciphertext)
+	mac.Write(zeros[:(poly1305.TagSize-len(ciphertext)%poly1305.TagSize)%poly1305.TagSize])
+
+	var lengths [16]byte
+	binary.LittleEndian.PutUint64(lengths[0:8], uint64(len(additionalData)))
+	binary.LittleEndian.PutUint64(lengths[8:16], uint64(len(ciphertext)))
+	mac.Write(lengths[:])
+}
+
+// sliceForAppend 将in扩展n字节，返回扩展后的切片及新增部分，与标准库相同
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
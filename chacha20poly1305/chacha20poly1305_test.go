@@ -0,0 +1,84 @@
+package chacha20poly1305
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+var (
+	testKey, _ = hex.DecodeString("808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f")
+	testAAD, _ = hex.DecodeString("50515253c0c1c2c3c4c5c6c7")
+	sunscreen  = []byte("Ladies and Gentlemen of the class of '99: If I could offer you only one tip for the future, sunscreen would be it.")
+)
+
+func TestVectors(t *testing.T) {
+	tests := []struct {
+		name  string
+		nonce string
+		want  string
+	}{
+		// RFC 8439第2.8.2节
+		{"ChaCha20-Poly1305", "070000004041424344454647", "d31a8d34648e60db7b86afbc53ef7ec2a4aded51296e08fea9e2b5a736ee62d63dbea45e8ca9671282fafb69da92728b1a71de0a9e060b2905d6a5b67ecd3b3692ddbd7f2d778b8c9803aee328091b58fab324e4fad675945585808b4831d7bc3ff4def08e4b7a9de576d26586cec64b61161ae10b594f09e26a7e902ecbd0600691"},
+		// draft-irtf-cfrg-xchacha附录A.3.1
+		{"XChaCha20-Poly1305", "404142434445464748494a4b4c4d4e4f5051525354555657", "bd6d179d3e83d43b9576579493c0e939572a1700252bfaccbed2902c21396cbb731c7f1b0b4aa6440bf3a82f4eda7e39ae64c6708c54c216cb96b72e1213b4522f8c9ba40db5d945b11b69b982c1bb9e3f3fac2bc369488f76b2383565d3fff921f9664c97637da9768812f615c68b13b52ec0875924c1c7987947deafd8780acf49"},
+	}
+
+	for _, tt := range tests {
+		nonce, _ := hex.DecodeString(tt.nonce)
+		want, _ := hex.DecodeString(tt.want)
+
+		aead, err := New(testKey)
+		if len(nonce) == NonceSizeX {
+			aead, err = NewX(testKey)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := aead.Seal(nil, nonce, sunscreen, testAAD)
+		if !bytes.Equal(got, want) {
+			t.Fatalf("%s: 密文不匹配\n预期: %x\n实际: %x", tt.name, want, got)
+		}
+		plaintext, err := aead.Open(nil, nonce, got, testAAD)
+		if err != nil || !bytes.Equal(plaintext, sunscreen) {
+			t.Fatalf("%s: 解密失败: %v", tt.name, err)
+		}
+
+		// 原地加解密
+		buf := make([]byte, len(sunscreen), len(sunscreen)+Overhead)
+		copy(buf, sunscreen)
+		sealed := aead.Seal(buf[:0], nonce, buf, testAAD)
+		if !bytes.Equal(sealed, want) {
+			t.Fatalf("%s: 原地加密的结果不正确", tt.name)
+		}
+		if opened, err := aead.Open(sealed[:0], nonce, sealed, testAAD); err != nil || !bytes.Equal(opened, sunscreen) {
+			t.Fatalf("%s: 原地解密失败: %v", tt.name, err)
+		}
+	}
+}
+
+// 测试篡改密文、标签或附加数据时认证失败
+func TestOpenTampered(t *testing.T) {
+	aead, _ := New(testKey)
+	nonce := make([]byte, NonceSize)
+	sealed := aead.Seal(nil, nonce, []byte("秘密消息"), testAAD)
+
+	for i := range sealed {
+		tampered := bytes.Clone(sealed)
+		tampered[i] ^= 1
+		if _, err := aead.Open(nil, nonce, tampered, testAAD); err != ErrAuthFailed {
+			t.Fatalf("篡改第%d字节后应返回ErrAuthFailed，实际: %v", i, err)
+		}
+	}
+	if _, err := aead.Open(nil, nonce, sealed, nil); err != ErrAuthFailed {
+		t.Fatalf("附加数据不同时应返回ErrAuthFailed，实际: %v", err)
+	}
+	if _, err := aead.Open(nil, nonce, sealed[:Overhead-1], testAAD); err != ErrAuthFailed {
+		t.Fatalf("过短的密文应返回ErrAuthFailed，实际: %v", err)
+	}
+	if _, err := New(testKey[:16]); !errors.Is(err, ErrInvalidKeySize) {
+		t.Fatalf("16字节密钥应返回ErrInvalidKeySize，实际: %v", err)
+	}
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"io"
+	"strings"
+
+	"github.com/laenix/gsc"
+	"github.com/laenix/gsc/chacha20"
+	"github.com/laenix/gsc/chacha20poly1305"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/modes/siv"
+	"github.com/laenix/gsc/nacl/secretbox"
+	"github.com/laenix/gsc/rc4"
+	"github.com/laenix/gsc/salsa20"
+	"github.com/laenix/gsc/sm2"
+)
+
+// errNoAAD 表示所选算法不支持附加认证数据，分组密码套件中只有GCM支持
+var errNoAAD = errors.New("该算法不支持-aad")
+
+// errTooShort 表示密文短于其中应包含的nonce和标签
+var errTooShort = errors.New("密文过短")
+
+// aeads 是以随机nonce为前缀输出 nonce || 密文 || 标签 的认证加密算法
+var aeads = map[string]func(key []byte) (cipher.AEAD, error){
+	"CHACHA20-POLY1305":  chacha20poly1305.New,
+	"XCHACHA20-POLY1305": chacha20poly1305.NewX,
+}
+
+// sivNonceSize 是AES-SIV随机nonce的长度，nonce作为最后一个附加数据参与S2V（RFC 5297 3）
+const sivNonceSize = 16
+
+// streams 是以随机nonce为前缀输出 nonce || 密文 的流密码及其nonce长度，RC4没有nonce
+var streams = map[string]int{
+	"CHACHA20":  chacha20.NonceSize,
+	"XCHACHA20": chacha20.NonceSizeX,
+	"SALSA20":   salsa20.NonceSize,
+	"XSALSA20":  salsa20.NonceSizeX,
+	"RC4":       0,
+}
+
+// cipherFlags 是enc和dec共用的参数
+type cipherFlags struct {
+	io   ioFlags
+	key  keyFlags
+	alg  string
+	aad  string
+	sm2k string
+	// sm2Flag 是SM2密钥文件参数的名称，enc为-pub，dec为-priv
+	sm2Flag string
+}
+
+func (f *cipherFlags) register(fs *flag.FlagSet, sm2Flag, sm2Usage string) {
+	f.io.register(fs, "raw")
+	f.key.register(fs)
+	fs.StringVar(&f.alg, "alg", "AES-256-GCM",
+		"算法: 分组密码套件（如AES-256-GCM、SM4-CBC/PKCS7，见gsc.NewCipherSuite）、"+
+			"ChaCha20-Poly1305、XChaCha20-Poly1305、AES-SIV、XSalsa20-Poly1305、ChaCha20、XChaCha20、Salsa20、XSalsa20、RC4或SM2")
+	fs.StringVar(&f.aad, "aad", "", "附加认证数据（ChaCha20-Poly1305系列、AES-SIV和GCM套件）")
+	fs.StringVar(&f.sm2k, sm2Flag, "", sm2Usage)
+	f.sm2Flag = sm2Flag
+}
+
+func runEnc(e *env, fs *flag.FlagSet, args []string) error {
+	var f cipherFlags
+	f.register(fs, "pub", "SM2公钥文件（JSON，-alg SM2时使用）")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	in, err := f.io.read(e)
+	if err != nil {
+		return err
+	}
+	out, err := f.crypt(fs, in, false)
+	if err != nil {
+		return err
+	}
+	return f.io.write(e, out)
+}
+
+func runDec(e *env, fs *flag.FlagSet, args []string) error {
+	var f cipherFlags
+	f.register(fs, "priv", "SM2私钥文件（JSON，-alg SM2时使用）")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	in, err := f.io.read(e)
+	if err != nil {
+		return err
+	}
+	out, err := f.crypt(fs, in, true)
+	if err != nil {
+		return err
+	}
+	return f.io.write(e, out)
+}
+
+// crypt 按-alg加密或解密data
+func (f *cipherFlags) crypt(fs *flag.FlagSet, data []byte, decrypt bool) ([]byte, error) {
+	alg := strings.ToUpper(f.alg)
+	newAEAD, isAEAD := aeads[alg]
+	_, isStream := streams[alg]
+	if f.aad != "" && (isStream || alg == "XSALSA20-POLY1305" || alg == "SM2") {
+		return nil, errNoAAD
+	}
+	if alg == "SM2" {
+		return f.sm2(fs, data, decrypt)
+	}
+
+	key, err := f.key.load(fs)
+	if err != nil {
+		return nil, err
+	}
+
+	switch nonceSize := streams[alg]; {
+	case isAEAD:
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		if decrypt {
+			if len(data) < aead.NonceSize() {
+				return nil, errTooShort
+			}
+			return aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(f.aad))
+		}
+		nonce, err := randomBytes(aead.NonceSize())
+		if err != nil {
+			return nil, err
+		}
+		return aead.Seal(nonce, nonce, data, []byte(f.aad)), nil
+
+	case alg == "AES-SIV":
+		return f.siv(key, data, decrypt)
+
+	case alg == "XSALSA20-POLY1305":
+		if len(key) != secretbox.KeySize {
+			return nil, gscerr.KeySize(gsc.ErrInvalidKeySize, "XSalsa20-Poly1305", len(key), secretbox.KeySize)
+		}
+		k := (*[secretbox.KeySize]byte)(key)
+		if decrypt {
+			if len(data) < secretbox.NonceSize {
+				return nil, errTooShort
+			}
+			return secretbox.Open(nil, data[secretbox.NonceSize:], (*[secretbox.NonceSize]byte)(data), k)
+		}
+		nonce, err := randomBytes(secretbox.NonceSize)
+		if err != nil {
+			return nil, err
+		}
+		return secretbox.Seal(nonce, data, (*[secretbox.NonceSize]byte)(nonce), k), nil
+
+	case isStream:
+		var nonce []byte
+		if decrypt {
+			if len(data) < nonceSize {
+				return nil, errTooShort
+			}
+			nonce, data = data[:nonceSize], data[nonceSize:]
+		} else if nonce, err = randomBytes(nonceSize); err != nil {
+			return nil, err
+		}
+		xor, err := newStream(alg, key, nonce)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]byte, len(data))
+		xor(out, data)
+		if decrypt {
+			return out, nil
+		}
+		return append(nonce, out...), nil
+	}
+
+	suite, err := gsc.NewCipherSuite(f.alg)
+	if err != nil {
+		return nil, err
+	}
+	opts := &gsc.EncryptOptions{Suite: suite}
+	if f.aad != "" {
+		opts.AdditionalData = []byte(f.aad)
+	}
+	if decrypt {
+		return gsc.Decrypt(key, data, opts)
+	}
+	return gsc.Encrypt(key, data, opts)
+}
+
+// siv 使用AES-SIV加密或解密，输出 nonce || V || C
+// -aad（如有）与随机nonce依次作为附加数据，同一明文每次加密的结果不同
+func (f *cipherFlags) siv(key, data []byte, decrypt bool) ([]byte, error) {
+	s, err := siv.New(key)
+	if err != nil {
+		return nil, err
+	}
+	var ad [][]byte
+	if f.aad != "" {
+		ad = append(ad, []byte(f.aad))
+	}
+	if decrypt {
+		if len(data) < sivNonceSize {
+			return nil, errTooShort
+		}
+		return s.Open(data[sivNonceSize:], append(ad, data[:sivNonceSize])...)
+	}
+	nonce, err := randomBytes(sivNonceSize)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.Seal(data, append(ad, nonce)...)
+	if err != nil {
+		return nil, err
+	}
+	return append(nonce, sealed...), nil
+}
+
+// newStream 返回流密码的XORKeyStream
+func newStream(alg string, key, nonce []byte) (func(dst, src []byte), error) {
+	switch alg {
+	case "CHACHA20", "XCHACHA20":
+		c, err := chacha20.New(key, nonce)
+		if err != nil {
+			return nil, err
+		}
+		return c.XORKeyStream, nil
+	case "SALSA20", "XSALSA20":
+		c, err := salsa20.New(key, nonce)
+		if err != nil {
+			return nil, err
+		}
+		return c.XORKeyStream, nil
+	}
+	c, err := rc4.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return c.XORKeyStream, nil
+}
+
+// sm2 使用-pub给出的公钥加密，或使用-priv给出的私钥解密
+func (f *cipherFlags) sm2(fs *flag.FlagSet, data []byte, decrypt bool) ([]byte, error) {
+	if f.sm2k == "" {
+		return nil, usageError(fs, "-alg SM2需要-"+f.sm2Flag)
+	}
+	if decrypt {
+		priv, err := loadSM2PrivateKey(f.sm2k)
+		if err != nil {
+			return nil, err
+		}
+		return sm2.New().Decrypt(priv, data)
+	}
+	pub, err := loadSM2PublicKey(f.sm2k)
+	if err != nil {
+		return nil, err
+	}
+	return sm2.New().Encrypt(pub, data, nil)
+}
+
+// randomBytes 从crypto/rand读取n字节
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"crypto/hmac"
+	"flag"
+	"hash"
+	"strings"
+
+	"github.com/laenix/gsc"
+	"github.com/laenix/gsc/aes"
+	"github.com/laenix/gsc/blake2b"
+	"github.com/laenix/gsc/mac"
+	"github.com/laenix/gsc/sm3"
+)
+
+// hashes 是hash子命令和HMAC支持的哈希函数
+var hashes = map[string]func() hash.Hash{
+	"SM3": sm3.New,
+	"BLAKE2B-256": func() hash.Hash {
+		h, _ := blake2b.New256(nil)
+		return h
+	},
+	"BLAKE2B-512": func() hash.Hash {
+		h, _ := blake2b.New512(nil)
+		return h
+	},
+}
+
+func runHash(e *env, fs *flag.FlagSet, args []string) error {
+	var f ioFlags
+	f.register(fs, "hex")
+	alg := fs.String("alg", "SM3", "哈希算法: SM3、BLAKE2b-256或BLAKE2b-512")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	newHash, ok := hashes[strings.ToUpper(*alg)]
+	if !ok {
+		return gsc.ErrUnsupportedAlgorithm
+	}
+	data, err := f.read(e)
+	if err != nil {
+		return err
+	}
+	h := newHash()
+	h.Write(data)
+	return f.write(e, h.Sum(nil))
+}
+
+func runHMAC(e *env, fs *flag.FlagSet, args []string) error {
+	var f ioFlags
+	var k keyFlags
+	f.register(fs, "hex")
+	k.register(fs)
+	alg := fs.String("alg", "HMAC-SM3", "算法: HMAC-SM3、HMAC-BLAKE2b-256、HMAC-BLAKE2b-512、CMAC-AES或CMAC-SM4")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	key, err := k.load(fs)
+	if err != nil {
+		return err
+	}
+	m, err := newMAC(strings.ToUpper(*alg), key)
+	if err != nil {
+		return err
+	}
+	data, err := f.read(e)
+	if err != nil {
+		return err
+	}
+	m.Write(data)
+	return f.write(e, m.Sum(nil))
+}
+
+// newMAC 按名称构造消息认证码，HMAC-前缀可以省略
+func newMAC(alg string, key []byte) (hash.Hash, error) {
+	switch alg {
+	case "CMAC-AES":
+		block, err := aes.New(key)
+		if err != nil {
+			return nil, err
+		}
+		return mac.NewCMAC(block)
+	case "CMAC-SM4":
+		return mac.NewSM4CMAC(key)
+	}
+	newHash, ok := hashes[strings.TrimPrefix(alg, "HMAC-")]
+	if !ok {
+		return nil, gsc.ErrUnsupportedAlgorithm
+	}
+	return hmac.New(newHash, key), nil
+}
@@ -0,0 +1,281 @@
+// gsc 是本库的命令行工具，无需编写Go代码即可使用库中的算法
+//
+// 用法：
+//
+//	gsc <子命令> [参数]
+//
+// 子命令：
+//
+//	enc     加密（分组密码套件、ChaCha20/Salsa20系列、RC4、SM2）
+//	dec     解密
+//	hash    计算摘要（SM3、BLAKE2b）
+//	hmac    计算消息认证码（HMAC-SM3、HMAC-BLAKE2b、CMAC-AES、CMAC-SM4）
+//	keygen  生成对称密钥，或SM2、minisign、signify密钥对
+//	sign    签名（SM2，或生成minisign、signify签名文件）
+//	verify  验签
+//	seal    将文件加密为分块认证的自描述容器（口令或密钥）
+//	open    解密seal生成的容器
+//
+// 输入默认读取标准输入，输出默认写到标准输出；-inform和-outform指定raw、hex或base64编码，
+// -verbose（也可写作--verbose）在标准错误按分块输出输入和输出数据的十六进制，便于与测试向量逐块对照。
+// 对称密钥以十六进制给出（-key或-keyfile），SM2密钥文件为sm2包的JSON编码，minisign和signify使用各自工具的密钥文件。
+// 例如：
+//
+//	gsc keygen -alg SM4 -out sm4.key
+//	echo hello | gsc enc -alg SM4-GCM -keyfile sm4.key -outform base64 | gsc dec -alg SM4-GCM -keyfile sm4.key -inform base64
+//	gsc keygen -alg SM2 -out priv.json -pubout pub.json
+//	gsc sign -key priv.json -in msg.txt > msg.sig && gsc verify -pub pub.json -sig msg.sig -in msg.txt
+//	gsc keygen -alg minisign -out release.key -pubout release.pub
+//	gsc sign -alg minisign -priv release.key -in app.tar.gz -out app.tar.gz.minisig && gsc verify -alg minisign -pub release.pub -sig app.tar.gz.minisig -in app.tar.gz
+//	gsc seal -passfile pass.txt -in backup.tar -out backup.tar.gsc && gsc open -passfile pass.txt -in backup.tar.gsc -out backup.tar
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/laenix/gsc/dump"
+	"github.com/laenix/gsc/i18n"
+)
+
+// 退出码
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// errUsage 表示参数错误，FlagSet已输出用法说明
+var errUsage = errors.New("usage")
+
+// errVerifyFailed 表示签名验证失败，verify以退出码1结束但不视为运行错误
+var errVerifyFailed = errors.New("verification failed")
+
+// env 是子命令使用的标准输入输出，测试时替换为缓冲区
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// command 是一个子命令
+type command struct {
+	name    string
+	summary string
+	run     func(e *env, fs *flag.FlagSet, args []string) error
+}
+
+var commands = []command{
+	{"enc", "加密", runEnc},
+	{"dec", "解密", runDec},
+	{"hash", "计算摘要", runHash},
+	{"hmac", "计算消息认证码", runHMAC},
+	{"keygen", "生成对称密钥或密钥对", runKeygen},
+	{"sign", "签名（SM2、minisign、signify）", runSign},
+	{"verify", "验签（SM2、minisign、signify）", runVerify},
+	{"seal", "加密文件为容器", runSeal},
+	{"open", "解密容器", runOpen},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], &env{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}))
+}
+
+// run 执行args指定的子命令并返回退出码
+func run(args []string, e *env) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(e.stderr)
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+
+	for _, c := range commands {
+		if c.name != args[0] {
+			continue
+		}
+		fs := flag.NewFlagSet("gsc "+c.name, flag.ContinueOnError)
+		fs.SetOutput(e.stderr)
+		err := c.run(e, fs, args[1:])
+		switch {
+		case err == nil:
+			return exitOK
+		case errors.Is(err, flag.ErrHelp):
+			return exitOK
+		case errors.Is(err, errUsage):
+			return exitUsage
+		case errors.Is(err, errVerifyFailed):
+			return exitError
+		}
+		fmt.Fprintf(e.stderr, "gsc %s: %s\n", c.name, i18n.Message(err, i18n.Zh))
+		return exitError
+	}
+
+	fmt.Fprintf(e.stderr, "gsc: 未知的子命令 %q\n\n", args[0])
+	usage(e.stderr)
+	return exitUsage
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "用法: gsc <子命令> [参数]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "子命令:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-8s%s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "使用 gsc <子命令> -h 查看各子命令的参数")
+}
+
+// parse 解析子命令参数，不接受多余的位置参数
+func parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(fs.Output(), "多余的参数: %s\n", strings.Join(fs.Args(), " "))
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+// usageError 输出msg和用法说明后返回errUsage
+func usageError(fs *flag.FlagSet, msg string) error {
+	fmt.Fprintln(fs.Output(), msg)
+	fs.Usage()
+	return errUsage
+}
+
+// verboseBlockSize 是-verbose输出中每行的字节数
+const verboseBlockSize = 16
+
+// ioFlags 是各子命令共用的输入输出参数
+type ioFlags struct {
+	in, out         string
+	inform, outform string
+	verbose         bool
+}
+
+// register 注册-in、-out、-inform、-outform和-verbose，outform是该子命令的默认输出编码
+func (f *ioFlags) register(fs *flag.FlagSet, outform string) {
+	fs.StringVar(&f.in, "in", "-", "输入文件，-表示标准输入")
+	fs.StringVar(&f.out, "out", "-", "输出文件，-表示标准输出")
+	fs.StringVar(&f.inform, "inform", "raw", "输入编码: raw、hex或base64")
+	fs.StringVar(&f.outform, "outform", outform, "输出编码: raw、hex或base64")
+	fs.BoolVar(&f.verbose, "verbose", false, "在标准错误按16字节分块输出解码后的输入和编码前的输出")
+}
+
+// read 读取全部输入并按-inform解码
+func (f *ioFlags) read(e *env) ([]byte, error) {
+	var data []byte
+	var err error
+	if f.in == "-" {
+		data, err = io.ReadAll(e.stdin)
+	} else {
+		data, err = os.ReadFile(f.in)
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err = decode(f.inform, data)
+	if err != nil {
+		return nil, err
+	}
+	f.trace(e, "输入", data)
+	return data, nil
+}
+
+// write 按-outform编码data并写出，hex和base64输出末尾带换行
+func (f *ioFlags) write(e *env, data []byte) error {
+	f.trace(e, "输出", data)
+	encoded, err := encode(f.outform, data)
+	if err != nil {
+		return err
+	}
+	return writeFile(e, f.out, encoded)
+}
+
+// trace 在-verbose时向标准错误输出data的分块十六进制视图
+func (f *ioFlags) trace(e *env, label string, data []byte) {
+	if f.verbose {
+		fmt.Fprintf(e.stderr, "%s（%d字节）:\n%s", label, len(data), dump.Blocks(data, verboseBlockSize))
+	}
+}
+
+// writeFile 将data写到path，path为-时写到标准输出
+func writeFile(e *env, path string, data []byte) error {
+	if path == "-" {
+		_, err := e.stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// decode 按编码名称解码data，hex和base64忽略其中的空白
+func decode(format string, data []byte) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case "raw":
+		return data, nil
+	case "hex":
+		return hex.DecodeString(string(stripSpace(data)))
+	case "base64":
+		return base64.StdEncoding.DecodeString(string(stripSpace(data)))
+	}
+	return nil, fmt.Errorf("未知的编码 %q", format)
+}
+
+// encode 按编码名称编码data
+func encode(format string, data []byte) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case "raw":
+		return data, nil
+	case "hex":
+		return []byte(hex.EncodeToString(data) + "\n"), nil
+	case "base64":
+		return []byte(base64.StdEncoding.EncodeToString(data) + "\n"), nil
+	}
+	return nil, fmt.Errorf("未知的编码 %q", format)
+}
+
+func stripSpace(data []byte) []byte {
+	return bytes.Join(bytes.Fields(data), nil)
+}
+
+// keyFlags 是对称密钥参数
+type keyFlags struct {
+	hex, file string
+}
+
+func (k *keyFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&k.hex, "key", "", "十六进制密钥")
+	fs.StringVar(&k.file, "keyfile", "", "保存十六进制密钥的文件（如keygen的输出）")
+}
+
+// load 返回-key或-keyfile给出的密钥，两者必须且只能给出一个
+func (k *keyFlags) load(fs *flag.FlagSet) ([]byte, error) {
+	switch {
+	case k.hex != "" && k.file != "":
+		return nil, usageError(fs, "-key和-keyfile只能给出一个")
+	case k.hex != "":
+		return hex.DecodeString(k.hex)
+	case k.file != "":
+		data, err := os.ReadFile(k.file)
+		if err != nil {
+			return nil, err
+		}
+		return decode("hex", data)
+	}
+	return nil, usageError(fs, "缺少密钥，请使用-key或-keyfile")
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runGSC 以input为标准输入执行命令，返回标准输出、标准错误和退出码
+func runGSC(t *testing.T, input []byte, args ...string) (string, string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, &env{stdin: bytes.NewReader(input), stdout: &stdout, stderr: &stderr})
+	return stdout.String(), stderr.String(), code
+}
+
+// 测试各类算法经enc、dec往返后得到原文
+func TestEncDecRoundTrip(t *testing.T) {
+	plaintext := []byte("gsc命令行工具往返测试")
+	algs := []string{
+		"AES-256-GCM", "AES-128-CBC", "SM4-GCM", "SM4-CTR", "3DES-CBC/PKCS7", "Twofish-256-OFB",
+		"ChaCha20-Poly1305", "XChaCha20-Poly1305", "XSalsa20-Poly1305", "AES-SIV",
+		"ChaCha20", "XChaCha20", "Salsa20", "XSalsa20", "RC4",
+	}
+	for _, alg := range algs {
+		keyAlg := alg
+		switch alg {
+		case "RC4":
+			keyAlg = "AES-128"
+		case "AES-SIV":
+			keyAlg = "AES-256"
+		}
+		key, _, code := runGSC(t, nil, "keygen", "-alg", keyAlg)
+		if code != exitOK {
+			t.Fatalf("%s: keygen退出码%d", alg, code)
+		}
+		key = strings.TrimSpace(key)
+
+		ct, stderr, code := runGSC(t, plaintext, "enc", "-alg", alg, "-key", key, "-outform", "base64")
+		if code != exitOK {
+			t.Fatalf("%s: enc失败: %s", alg, stderr)
+		}
+		pt, stderr, code := runGSC(t, []byte(ct), "dec", "-alg", alg, "-key", key, "-inform", "base64")
+		if code != exitOK {
+			t.Fatalf("%s: dec失败: %s", alg, stderr)
+		}
+		if pt != string(plaintext) {
+			t.Errorf("%s: 往返结果 %q", alg, pt)
+		}
+	}
+}
+
+// 测试-verbose在标准错误分块输出输入和输出，标准输出不受影响
+func TestVerbose(t *testing.T) {
+	out, stderr, code := runGSC(t, []byte("abc"), "hash", "--verbose")
+	if code != exitOK || out != "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0\n" {
+		t.Fatalf("退出码%d，输出%q", code, out)
+	}
+	want := "输入（3字节）:\n块 1 [0000]: 616263\n" +
+		"输出（32字节）:\n块 1 [0000]: 66c7f0f462eeedd9d1f2d46bdc10e4e2\n块 2 [0010]: 4167c4875cf2f7a2297da02b8f4ba8e0\n"
+	if stderr != want {
+		t.Errorf("-verbose输出:\n%s", stderr)
+	}
+	if _, stderr, _ := runGSC(t, []byte("abc"), "hash"); stderr != "" {
+		t.Errorf("未指定-verbose时不应输出: %q", stderr)
+	}
+}
+
+// 测试AEAD的附加认证数据参与认证，不一致时解密失败；不能认证附加数据的算法拒绝-aad
+func TestEncAAD(t *testing.T) {
+	key := strings.Repeat("11", 32)
+	for _, tt := range []struct{ alg, key string }{
+		{"ChaCha20-Poly1305", key},
+		{"AES-128-GCM", key[:32]},
+		{"SM4-GCM", key[:32]},
+		{"AES-SIV", key},
+	} {
+		ct, stderr, code := runGSC(t, []byte("data"), "enc", "-alg", tt.alg, "-key", tt.key, "-aad", "header")
+		if code != exitOK {
+			t.Fatalf("%s: enc失败: %s", tt.alg, stderr)
+		}
+		if pt, stderr, code := runGSC(t, []byte(ct), "dec", "-alg", tt.alg, "-key", tt.key, "-aad", "header"); code != exitOK || pt != "data" {
+			t.Errorf("%s: dec失败: %q %s", tt.alg, pt, stderr)
+		}
+		if _, _, code := runGSC(t, []byte(ct), "dec", "-alg", tt.alg, "-key", tt.key, "-aad", "other"); code != exitError {
+			t.Errorf("%s: AAD不一致时应失败，退出码%d", tt.alg, code)
+		}
+		if _, _, code := runGSC(t, []byte(ct), "dec", "-alg", tt.alg, "-key", tt.key); code != exitError {
+			t.Errorf("%s: 缺少AAD时应失败，退出码%d", tt.alg, code)
+		}
+	}
+	for _, alg := range []string{"AES-128-CBC", "ChaCha20", "XSalsa20-Poly1305"} {
+		if _, _, code := runGSC(t, []byte("data"), "enc", "-alg", alg, "-key", key[:32], "-aad", "x"); code != exitError {
+			t.Errorf("%s不支持-aad，退出码%d", alg, code)
+		}
+	}
+}
+
+func TestHashAndHMAC(t *testing.T) {
+	out, _, _ := runGSC(t, []byte("abc"), "hash")
+	if want := "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0\n"; out != want {
+		t.Errorf("SM3(abc) = %q", out)
+	}
+	// RFC 4493 示例2
+	out, _, _ = runGSC(t, []byte("6bc1bee22e409f96e93d7e117393172a"), "hmac", "-alg", "CMAC-AES",
+		"-key", "2b7e151628aed2a6abf7158809cf4f3c", "-inform", "hex")
+	if want := "070a16b46b4d4144f79bdd9dd04a287c\n"; out != want {
+		t.Errorf("CMAC-AES = %q", out)
+	}
+	for _, alg := range []string{"HMAC-SM3", "BLAKE2b-512", "HMAC-BLAKE2b-256", "CMAC-SM4"} {
+		if _, stderr, code := runGSC(t, []byte("abc"), "hmac", "-alg", alg, "-key", strings.Repeat("ab", 16)); code != exitOK {
+			t.Errorf("%s: %s", alg, stderr)
+		}
+	}
+}
+
+// 测试SM2密钥生成、签名验签和公钥加密
+func TestSM2(t *testing.T) {
+	dir := t.TempDir()
+	priv, pub, sig := filepath.Join(dir, "priv.json"), filepath.Join(dir, "pub.json"), filepath.Join(dir, "msg.sig")
+	if _, stderr, code := runGSC(t, nil, "keygen", "-alg", "SM2", "-out", priv, "-pubout", pub); code != exitOK {
+		t.Fatal(stderr)
+	}
+
+	msg := []byte("待签名的消息")
+	if _, stderr, code := runGSC(t, msg, "sign", "-priv", priv, "-id", "alice", "-out", sig); code != exitOK {
+		t.Fatal(stderr)
+	}
+	if out, _, code := runGSC(t, msg, "verify", "-pub", pub, "-id", "alice", "-sig", sig); code != exitOK || out != "验证通过\n" {
+		t.Errorf("验签应通过: %q, 退出码%d", out, code)
+	}
+	if _, _, code := runGSC(t, msg, "verify", "-pub", pub, "-sig", sig); code != exitError {
+		t.Errorf("用户标识不同时验签应失败，退出码%d", code)
+	}
+	if _, _, code := runGSC(t, []byte("篡改"), "verify", "-pub", pub, "-id", "alice", "-sig", sig); code != exitError {
+		t.Errorf("消息被篡改时验签应失败，退出码%d", code)
+	}
+
+	ct, stderr, code := runGSC(t, msg, "enc", "-alg", "SM2", "-pub", pub)
+	if code != exitOK {
+		t.Fatal(stderr)
+	}
+	if pt, _, _ := runGSC(t, []byte(ct), "dec", "-alg", "SM2", "-priv", priv); pt != string(msg) {
+		t.Errorf("SM2解密结果 %q", pt)
+	}
+}
+
+// 测试minisign和signify的密钥生成、签名文件和验签
+func TestSigFile(t *testing.T) {
+	dir := t.TempDir()
+	msg := filepath.Join(dir, "app.tar.gz")
+	pass := filepath.Join(dir, "pass")
+	os.WriteFile(msg, []byte("release artifact"), 0o600)
+	os.WriteFile(pass, []byte("passphrase\n"), 0o600)
+
+	for _, alg := range []string{"minisign", "signify"} {
+		priv, pub, sig := filepath.Join(dir, alg+".key"), filepath.Join(dir, alg+".pub"), filepath.Join(dir, alg+".sig")
+		keygen := []string{"keygen", "-alg", alg, "-out", priv, "-pubout", pub}
+		sign := []string{"sign", "-alg", alg, "-priv", priv, "-in", msg, "-out", sig}
+		// minisign的默认口令派生需要1GiB内存，这里只测试未加密的私钥
+		if alg == "minisign" {
+			sign = append(sign, "-trusted-comment", "file:app.tar.gz")
+		} else {
+			keygen = append(keygen, "-passfile", pass)
+			sign = append(sign, "-passfile", pass)
+		}
+		if _, stderr, code := runGSC(t, nil, keygen...); code != exitOK {
+			t.Fatalf("%s: keygen失败: %s", alg, stderr)
+		}
+		if _, stderr, code := runGSC(t, nil, sign...); code != exitOK {
+			t.Fatalf("%s: sign失败: %s", alg, stderr)
+		}
+		out, stderr, code := runGSC(t, nil, "verify", "-alg", alg, "-pub", pub, "-sig", sig, "-in", msg)
+		if code != exitOK || !strings.HasPrefix(out, "验证通过\n") {
+			t.Errorf("%s: 验签应通过: %q %s", alg, out, stderr)
+		}
+		if alg == "minisign" && !strings.Contains(out, "可信注释: file:app.tar.gz") {
+			t.Errorf("应输出可信注释: %q", out)
+		}
+		if _, _, code := runGSC(t, []byte("篡改"), "verify", "-alg", alg, "-pub", pub, "-sig", sig); code != exitError {
+			t.Errorf("%s: 消息被篡改时验签应失败，退出码%d", alg, code)
+		}
+	}
+	if _, _, code := runGSC(t, nil, "sign", "-alg", "signify", "-priv", filepath.Join(dir, "signify.key"), "-in", msg); code != exitError {
+		t.Errorf("缺少口令时应失败，退出码%d", code)
+	}
+}
+
+// 测试seal和open使用口令或密钥往返文件
+func TestSealOpen(t *testing.T) {
+	dir := t.TempDir()
+	plain, sealed, opened := filepath.Join(dir, "plain"), filepath.Join(dir, "sealed"), filepath.Join(dir, "opened")
+	pass, wrong := filepath.Join(dir, "pass"), filepath.Join(dir, "wrong")
+	data := bytes.Repeat([]byte("大文件分块加密"), 1000)
+	for path, content := range map[string][]byte{plain: data, pass: []byte("口令\n"), wrong: []byte("错误")} {
+		if err := os.WriteFile(path, content, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"-passfile", pass, "-kdf", "scrypt", "-alg", "SM4-GCM", "-chunk", "1000"},
+		{"-passfile", pass},
+		{"-key", strings.Repeat("42", 16), "-alg", "aes-128-gcm"},
+	} {
+		secret := args[:2]
+		if _, stderr, code := runGSC(t, nil, append([]string{"seal", "-in", plain, "-out", sealed}, args...)...); code != exitOK {
+			t.Fatalf("%v: seal失败: %s", args, stderr)
+		}
+		if _, stderr, code := runGSC(t, nil, append([]string{"open", "-in", sealed, "-out", opened}, secret...)...); code != exitOK {
+			t.Fatalf("%v: open失败: %s", args, stderr)
+		}
+		if got, _ := os.ReadFile(opened); !bytes.Equal(got, data) {
+			t.Fatalf("%v: 往返结果不匹配", args)
+		}
+	}
+
+	// 口令错误时失败且不留下输出文件
+	os.Remove(opened)
+	if _, _, code := runGSC(t, nil, "open", "-in", sealed, "-out", opened, "-passfile", wrong); code != exitError {
+		t.Errorf("密钥错误时应失败，退出码%d", code)
+	}
+	if _, err := os.Stat(opened); !os.IsNotExist(err) {
+		t.Error("失败时不应留下输出文件")
+	}
+
+	// 标准输入输出
+	ct, _, _ := runGSC(t, []byte("stdin"), "seal", "-passfile", pass, "-kdf", "scrypt")
+	if pt, stderr, _ := runGSC(t, []byte(ct), "open", "-passfile", pass); pt != "stdin" {
+		t.Errorf("标准输入往返结果 %q: %s", pt, stderr)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	tests := [][]string{
+		{},
+		{"unknown"},
+		{"enc", "-alg", "SM4-GCM"},
+		{"enc", "-key", "00", "-keyfile", "x"},
+		{"hash", "extra"},
+		{"sign", "-alg", "SM9", "-priv", "x"},
+		{"verify", "-pub", "x"},
+		{"keygen", "-alg", "SM4", "-pubout", "x"},
+		{"keygen", "-alg", "SM2", "-passfile", "x"},
+		{"sign", "-alg", "minisign", "-priv", "x", "-id", "alice"},
+		{"seal", "-alg", "SM4-CBC", "-key", "00"},
+		{"seal", "-passfile", "x", "-key", "00"},
+		{"open"},
+	}
+	for _, args := range tests {
+		if _, _, code := runGSC(t, nil, args...); code != exitUsage {
+			t.Errorf("%v: 退出码%d，期望%d", args, code, exitUsage)
+		}
+	}
+	if _, _, code := runGSC(t, nil, "hash", "-h"); code != exitOK {
+		t.Errorf("-h的退出码应为0，实际%d", code)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/laenix/gsc"
+)
+
+// containerAlgorithms 是seal支持的分块AEAD算法
+var containerAlgorithms = []gsc.Algorithm{gsc.AES128GCM, gsc.AES192GCM, gsc.AES256GCM, gsc.SM4GCM}
+
+// sealFlags 是seal和open共用的参数
+type sealFlags struct {
+	in, out  string
+	key      keyFlags
+	passfile string
+}
+
+func (f *sealFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.in, "in", "-", "输入文件，-表示标准输入")
+	fs.StringVar(&f.out, "out", "-", "输出文件，-表示标准输出")
+	f.key.register(fs)
+	fs.StringVar(&f.passfile, "passfile", "", "口令文件，末尾的换行被忽略")
+}
+
+// secret 返回口令或密钥，passphrase表示返回的是口令
+func (f *sealFlags) secret(fs *flag.FlagSet) (secret []byte, passphrase bool, err error) {
+	if f.passfile == "" {
+		secret, err = f.key.load(fs)
+		return secret, false, err
+	}
+	if f.key.hex != "" || f.key.file != "" {
+		return nil, false, usageError(fs, "-passfile不能与-key或-keyfile同时使用")
+	}
+	data, err := readPassfile(fs, f.passfile)
+	return data, true, err
+}
+
+// readPassfile 读取口令文件，去掉末尾的换行
+func readPassfile(fs *flag.FlagSet, path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimRight(data, "\r\n")
+	if len(data) == 0 {
+		return nil, usageError(fs, "口令文件为空")
+	}
+	return data, nil
+}
+
+// stream 打开输入输出并执行fn，输出到文件时fn失败会删除不完整的输出
+func (f *sealFlags) stream(e *env, fn func(w io.Writer, r io.Reader) error) error {
+	r := e.stdin
+	if f.in != "-" {
+		file, err := os.Open(f.in)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+	if f.out == "-" {
+		return fn(e.stdout, r)
+	}
+
+	out, err := os.OpenFile(f.out, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	err = fn(out, r)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.out)
+	}
+	return err
+}
+
+func runSeal(e *env, fs *flag.FlagSet, args []string) error {
+	var f sealFlags
+	f.register(fs)
+	alg := fs.String("alg", "AES-256-GCM", "分块加密算法: AES-128-GCM、AES-192-GCM、AES-256-GCM或SM4-GCM")
+	kdf := fs.String("kdf", "argon2id", "使用-passfile时的密钥派生函数: argon2id或scrypt")
+	chunk := fs.Int("chunk", gsc.DefaultStreamChunkSize, "明文分块大小（字节）")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	opts := &gsc.ContainerOptions{ChunkSize: *chunk}
+	for _, a := range containerAlgorithms {
+		if strings.EqualFold(a.String(), *alg) {
+			opts.Algorithm = a
+		}
+	}
+	if opts.Algorithm == 0 {
+		return usageError(fs, fmt.Sprintf("不支持的算法 %q", *alg))
+	}
+	secret, passphrase, err := f.secret(fs)
+	if err != nil {
+		return err
+	}
+	if passphrase {
+		switch strings.ToLower(*kdf) {
+		case "argon2id":
+			opts.KDF = gsc.KDFArgon2id
+		case "scrypt":
+			opts.KDF = gsc.KDFScrypt
+		default:
+			return usageError(fs, fmt.Sprintf("不支持的密钥派生函数 %q", *kdf))
+		}
+	}
+
+	return f.stream(e, func(w io.Writer, r io.Reader) error {
+		sw, err := gsc.NewContainerWriter(secret, w, opts)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(sw, r); err != nil {
+			return err
+		}
+		return sw.Close()
+	})
+}
+
+func runOpen(e *env, fs *flag.FlagSet, args []string) error {
+	var f sealFlags
+	f.register(fs)
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	secret, _, err := f.secret(fs)
+	if err != nil {
+		return err
+	}
+
+	// 算法和KDF参数都从容器头部读取
+	return f.stream(e, func(w io.Writer, r io.Reader) error {
+		sr, err := gsc.NewContainerReader(secret, r)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(w, sr)
+		return err
+	})
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/laenix/gsc/gscrand"
+	"github.com/laenix/gsc/minisign"
+	"github.com/laenix/gsc/signify"
+	"github.com/laenix/gsc/sm2"
+)
+
+// hmacKeySize 是keygen为HMAC生成的密钥长度（字节），与SM3和BLAKE2b-256的输出长度相同
+const hmacKeySize = 32
+
+// 签名算法，minisign和signify的密钥和签名使用各自工具的文件格式
+const (
+	algSM2      = "SM2"
+	algMinisign = "MINISIGN"
+	algSignify  = "SIGNIFY"
+)
+
+func runKeygen(e *env, fs *flag.FlagSet, args []string) error {
+	alg := fs.String("alg", "AES-256", "算法: SM2、minisign、signify、HMAC-*，或gscrand.GenerateKey支持的对称算法（如AES-256、SM4、ChaCha20-Poly1305）")
+	out := fs.String("out", "-", "密钥输出文件，对称密钥为十六进制，SM2私钥为JSON，minisign和signify为各自的私钥文件")
+	pubout := fs.String("pubout", "", "公钥输出文件（SM2、minisign和signify），为空时不输出")
+	passfile := fs.String("passfile", "", "加密minisign或signify私钥的口令文件，为空时私钥不加密")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	name := strings.ToUpper(*alg)
+	if *passfile != "" && name != algMinisign && name != algSignify {
+		return usageError(fs, "-passfile只用于minisign和signify")
+	}
+	switch name {
+	case algSM2:
+		return keygenSM2(e, *out, *pubout)
+	case algMinisign, algSignify:
+		var password []byte
+		if *passfile != "" {
+			var err error
+			if password, err = readPassfile(fs, *passfile); err != nil {
+				return err
+			}
+		}
+		return keygenSigFile(e, name, password, *out, *pubout)
+	}
+
+	if *pubout != "" {
+		return usageError(fs, "-pubout只用于SM2、minisign和signify")
+	}
+	var key []byte
+	var err error
+	if strings.HasPrefix(name, "HMAC-") {
+		key, err = randomBytes(hmacKeySize)
+	} else {
+		key, err = gscrand.GenerateKey(*alg)
+	}
+	if err != nil {
+		return err
+	}
+	return writeFile(e, *out, []byte(hex.EncodeToString(key)+"\n"))
+}
+
+// keygenSM2 生成JSON编码的SM2密钥对
+func keygenSM2(e *env, out, pubout string) error {
+	priv, err := sm2.New().GenerateKey(nil)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(priv)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(e, out, append(data, '\n')); err != nil {
+		return err
+	}
+	if pubout == "" {
+		return nil
+	}
+	if data, err = json.Marshal(&priv.PublicKey); err != nil {
+		return err
+	}
+	return writeFile(e, pubout, append(data, '\n'))
+}
+
+// keygenSigFile 生成minisign或signify密钥对，password非空时加密私钥
+func keygenSigFile(e *env, alg string, password []byte, out, pubout string) error {
+	var priv, pub []byte
+	var err error
+	if alg == algMinisign {
+		pk, sk, err := minisign.GenerateKey(nil)
+		if err != nil {
+			return err
+		}
+		if priv, err = sk.Marshal(password, nil); err != nil {
+			return err
+		}
+		pub = pk.Marshal()
+	} else {
+		pk, sk, err := signify.GenerateKey(nil)
+		if err != nil {
+			return err
+		}
+		if priv, err = sk.Marshal(password, 0, ""); err != nil {
+			return err
+		}
+		if pub, err = pk.Marshal(""); err != nil {
+			return err
+		}
+	}
+	if err = writeFile(e, out, priv); err != nil || pubout == "" {
+		return err
+	}
+	return writeFile(e, pubout, pub)
+}
+
+// signFlags 是sign和verify共用的参数
+type signFlags struct {
+	io  ioFlags
+	alg string
+	key string
+	uid string
+}
+
+func (f *signFlags) register(fs *flag.FlagSet, keyFlag, keyUsage string) {
+	f.io.register(fs, "hex")
+	fs.StringVar(&f.alg, "alg", "SM2", "签名算法: SM2、minisign或signify（后两者的签名为文本文件，忽略-outform和-sigform）")
+	fs.StringVar(&f.key, keyFlag, "", keyUsage)
+	fs.StringVar(&f.uid, "id", "", "SM2用户标识，为空时使用默认标识1234567812345678")
+}
+
+// check 确认算法受支持且给出了密钥文件，并将算法名称规范为大写
+func (f *signFlags) check(fs *flag.FlagSet, keyFlag string) error {
+	f.alg = strings.ToUpper(f.alg)
+	switch f.alg {
+	case algSM2, algMinisign, algSignify:
+	default:
+		return usageError(fs, fmt.Sprintf("不支持的签名算法 %q", f.alg))
+	}
+	if f.uid != "" && f.alg != algSM2 {
+		return usageError(fs, "-id只用于SM2")
+	}
+	if f.key == "" {
+		return usageError(fs, "缺少密钥文件，请使用-"+keyFlag)
+	}
+	return nil
+}
+
+func runSign(e *env, fs *flag.FlagSet, args []string) error {
+	var f signFlags
+	f.register(fs, "priv", "私钥文件：SM2为JSON，minisign和signify为各自的私钥文件")
+	passfile := fs.String("passfile", "", "加密的minisign或signify私钥的口令文件")
+	comment := fs.String("comment", "", "minisign或signify签名文件中不受签名保护的注释")
+	trusted := fs.String("trusted-comment", "", "minisign签名中受签名保护的可信注释，为空时记录签名时间")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := f.check(fs, "priv"); err != nil {
+		return err
+	}
+	var password []byte
+	if *passfile != "" {
+		var err error
+		if password, err = readPassfile(fs, *passfile); err != nil {
+			return err
+		}
+	}
+	msg, err := f.io.read(e)
+	if err != nil {
+		return err
+	}
+
+	switch f.alg {
+	case algMinisign:
+		data, err := os.ReadFile(f.key)
+		if err != nil {
+			return err
+		}
+		priv, err := minisign.ParsePrivateKey(data, password)
+		if err != nil {
+			return err
+		}
+		sig, err := minisign.Sign(priv, msg, &minisign.SignOptions{UntrustedComment: *comment, TrustedComment: *trusted})
+		if err != nil {
+			return err
+		}
+		return writeFile(e, f.io.out, sig)
+	case algSignify:
+		data, err := os.ReadFile(f.key)
+		if err != nil {
+			return err
+		}
+		priv, err := signify.ParsePrivateKey(data, password)
+		if err != nil {
+			return err
+		}
+		sig, err := signify.Sign(priv, msg, *comment)
+		if err != nil {
+			return err
+		}
+		return writeFile(e, f.io.out, sig)
+	}
+
+	priv, err := loadSM2PrivateKey(f.key)
+	if err != nil {
+		return err
+	}
+	sig, err := sm2.New().SignWithId(priv, msg, []byte(f.uid))
+	if err != nil {
+		return err
+	}
+	return f.io.write(e, sig)
+}
+
+func runVerify(e *env, fs *flag.FlagSet, args []string) error {
+	var f signFlags
+	f.register(fs, "pub", "公钥文件：SM2为JSON，minisign和signify为各自的公钥文件")
+	sigFile := fs.String("sig", "", "签名文件")
+	sigform := fs.String("sigform", "hex", "签名文件的编码: raw、hex或base64")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := f.check(fs, "pub"); err != nil {
+		return err
+	}
+	if *sigFile == "" {
+		return usageError(fs, "缺少签名文件，请使用-sig")
+	}
+	data, err := os.ReadFile(*sigFile)
+	if err != nil {
+		return err
+	}
+	msg, err := f.io.read(e)
+	if err != nil {
+		return err
+	}
+
+	var ok bool
+	var trusted string
+	switch f.alg {
+	case algMinisign, algSignify:
+		ok, trusted, err = verifySigFile(f.alg, f.key, msg, data)
+		if err != nil {
+			return err
+		}
+	default:
+		pub, err := loadSM2PublicKey(f.key)
+		if err != nil {
+			return err
+		}
+		sig, err := decode(*sigform, data)
+		if err != nil {
+			return err
+		}
+		ok = sm2.New().VerifyWithId(pub, msg, sig, []byte(f.uid))
+	}
+
+	if !ok {
+		fmt.Fprintln(e.stdout, "验证失败")
+		return errVerifyFailed
+	}
+	fmt.Fprintln(e.stdout, "验证通过")
+	if trusted != "" {
+		fmt.Fprintf(e.stdout, "可信注释: %s\n", trusted)
+	}
+	return nil
+}
+
+// verifySigFile 验证minisign或signify签名，返回是否通过和minisign的可信注释
+// 签名无效或不是由该公钥生成时返回false，密钥或签名文件格式错误时返回错误
+func verifySigFile(alg, pubFile string, msg, sig []byte) (bool, string, error) {
+	data, err := os.ReadFile(pubFile)
+	if err != nil {
+		return false, "", err
+	}
+	if alg == algMinisign {
+		pub, err := minisign.ParsePublicKey(data)
+		if err != nil {
+			return false, "", err
+		}
+		s, err := minisign.Verify(pub, msg, sig)
+		switch {
+		case err == nil:
+			return true, s.TrustedComment, nil
+		case errors.Is(err, minisign.ErrInvalidSignature), errors.Is(err, minisign.ErrInvalidGlobalSig), errors.Is(err, minisign.ErrKeyIDMismatch):
+			return false, "", nil
+		}
+		return false, "", err
+	}
+
+	pub, err := signify.ParsePublicKey(data)
+	if err != nil {
+		return false, "", err
+	}
+	_, err = signify.Verify(pub, msg, sig)
+	switch {
+	case err == nil:
+		return true, "", nil
+	case errors.Is(err, signify.ErrInvalidSignature), errors.Is(err, signify.ErrKeyMismatch):
+		return false, "", nil
+	}
+	return false, "", err
+}
+
+// loadSM2PrivateKey 读取JSON编码的SM2私钥
+func loadSM2PrivateKey(path string) (*sm2.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	priv := new(sm2.PrivateKey)
+	if err := json.Unmarshal(data, priv); err != nil {
+		return nil, err
+	}
+	return priv, nil
+}
+
+// loadSM2PublicKey 读取JSON编码的SM2公钥
+func loadSM2PublicKey(path string) (*sm2.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pub := new(sm2.PublicKey)
+	if err := json.Unmarshal(data, pub); err != nil {
+		return nil, err
+	}
+	return pub, nil
+}
@@ -0,0 +1,200 @@
+// Package cms 实现CMS（RFC 5652，PKCS#7的后续版本）的签名数据和数字信封
+//
+// SignedData用于文件签名等场景，支持RSA、ECDSA、Ed25519和SM2签名者，可以附带或分离原文；
+// EnvelopedData用一次性内容加密密钥加密原文，再用每个接收者证书中的公钥加密该密钥（RSA PKCS#1 v1.5或SM2）。
+//
+// 签名者或全部接收者使用SM2密钥时按GM/T 0010-2012生成消息：内容类型使用国密OID，
+// 摘要算法为SM3，签名算法为sm2-1，内容加密算法为SM4-CBC，加密的密钥为GM/T 0009的SM2Cipher结构。
+// 解析时两种OID都接受
+package cms
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"slices"
+
+	"github.com/laenix/gsc/der"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/sm3"
+	"github.com/laenix/gsc/x509"
+)
+
+// 错误定义
+var (
+	ErrMalformed            = gscerr.New(gscerr.ErrMalformed, "cms: malformed message")
+	ErrUnexpectedContent    = gscerr.New(gscerr.ErrMalformed, "cms: unexpected content type")
+	ErrUnsupportedAlgorithm = gscerr.New(gscerr.ErrUnsupported, "cms: unsupported algorithm")
+	ErrUnsupportedKeyType   = gscerr.New(gscerr.ErrUnsupported, "cms: unsupported key type")
+	ErrKeyMismatch          = gscerr.New(gscerr.ErrParameter, "cms: private key does not match the certificate")
+	ErrNoRecipients         = gscerr.New(gscerr.ErrParameter, "cms: no recipients")
+	ErrNoContent            = gscerr.New(gscerr.ErrParameter, "cms: detached signature requires the content")
+	ErrNoSigner             = gscerr.New(gscerr.ErrVerification, "cms: no signer certificate found")
+	ErrInvalidSignature     = gscerr.New(gscerr.ErrVerification, "cms: invalid signature")
+	ErrNotRecipient         = gscerr.New(gscerr.ErrParameter, "cms: certificate is not a recipient of the message")
+	ErrDecryptionFailed     = gscerr.New(gscerr.ErrAuthFailed, "cms: decryption failed")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrMalformed:            "cms: 消息格式错误",
+		ErrUnexpectedContent:    "cms: 内容类型不符合预期",
+		ErrUnsupportedAlgorithm: "cms: 不支持的算法",
+		ErrUnsupportedKeyType:   "cms: 不支持的密钥类型",
+		ErrKeyMismatch:          "cms: 私钥与证书不匹配",
+		ErrNoRecipients:         "cms: 没有接收者",
+		ErrNoContent:            "cms: 分离签名需要提供原文",
+		ErrNoSigner:             "cms: 未找到签名者证书",
+		ErrInvalidSignature:     "cms: 签名无效",
+		ErrNotRecipient:         "cms: 证书不是该消息的接收者",
+		ErrDecryptionFailed:     "cms: 解密失败",
+	})
+}
+
+// profile 是一组内容类型OID
+type profile struct {
+	data, signedData, envelopedData der.OID
+}
+
+var (
+	// RFC 5652
+	profileCMS = &profile{
+		data:          der.OID{1, 2, 840, 113549, 1, 7, 1},
+		signedData:    der.OID{1, 2, 840, 113549, 1, 7, 2},
+		envelopedData: der.OID{1, 2, 840, 113549, 1, 7, 3},
+	}
+	// GM/T 0010-2012
+	profileGMT0010 = &profile{
+		data:          der.OID{1, 2, 156, 10197, 6, 1, 4, 2, 1},
+		signedData:    der.OID{1, 2, 156, 10197, 6, 1, 4, 2, 2},
+		envelopedData: der.OID{1, 2, 156, 10197, 6, 1, 4, 2, 3},
+	}
+)
+
+// digestAlgorithm 是摘要算法的OID和构造函数，hash为0表示crypto包中没有对应的值
+type digestAlgorithm struct {
+	oid  der.OID
+	hash crypto.Hash
+	new  func() hash.Hash
+}
+
+var (
+	digestSHA256 = &digestAlgorithm{der.OID{2, 16, 840, 1, 101, 3, 4, 2, 1}, crypto.SHA256, sha256.New}
+	digestSHA384 = &digestAlgorithm{der.OID{2, 16, 840, 1, 101, 3, 4, 2, 2}, crypto.SHA384, sha512.New384}
+	digestSHA512 = &digestAlgorithm{der.OID{2, 16, 840, 1, 101, 3, 4, 2, 3}, crypto.SHA512, sha512.New}
+	digestSM3    = &digestAlgorithm{x509.OIDSM3, 0, sm3.New}
+)
+
+// sum 计算data的摘要
+func (d *digestAlgorithm) sum(data []byte) []byte {
+	h := d.new()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// contentInfo 编码 ContentInfo ::= SEQUENCE { contentType, content [0] EXPLICIT ANY }
+func contentInfo(contentType der.OID, f func(*der.Builder)) ([]byte, error) {
+	var b der.Builder
+	b.AddSequence(func(b *der.Builder) {
+		b.AddOID(contentType)
+		b.AddExplicit(0, f)
+	})
+	return b.Bytes()
+}
+
+// parseContentInfo 解析ContentInfo，返回内容类型所属的profile和内容
+// 内容类型必须是两种profile中由typ选出的那个OID
+func parseContentInfo(data []byte, typ func(*profile) der.OID) (*profile, *der.Parser, error) {
+	p := der.NewParser(data)
+	seq, err := p.ReadSequence()
+	if err != nil || p.Finish() != nil {
+		return nil, nil, ErrMalformed
+	}
+	oid, err := seq.ReadOID()
+	if err != nil {
+		return nil, nil, ErrMalformed
+	}
+	content, present, err := seq.ReadExplicit(0)
+	if err != nil || !present || seq.Finish() != nil {
+		return nil, nil, ErrMalformed
+	}
+	for _, prof := range []*profile{profileCMS, profileGMT0010} {
+		if typ(prof).Equal(oid) {
+			return prof, content, nil
+		}
+	}
+	return nil, nil, ErrUnexpectedContent
+}
+
+// addSetOf 按DER的要求将元素按编码排序后追加为SET OF
+func addSetOf(b *der.Builder, tag der.Tag, elems [][]byte) {
+	elems = slices.Clone(elems)
+	slices.SortFunc(elems, bytes.Compare)
+	b.AddConstructed(tag, func(b *der.Builder) {
+		for _, e := range elems {
+			b.AddRaw(e)
+		}
+	})
+}
+
+// addIssuerAndSerial 追加 IssuerAndSerialNumber ::= SEQUENCE { issuer Name, serialNumber INTEGER }
+func addIssuerAndSerial(b *der.Builder, cert *x509.Certificate) {
+	b.AddSequence(func(b *der.Builder) {
+		b.AddRaw(cert.RawIssuer)
+		b.AddInteger(cert.SerialNumber)
+	})
+}
+
+// matchIdentifier 判断SignerIdentifier或RecipientIdentifier是否指向cert，
+// 标识可以是IssuerAndSerialNumber或[0]形式的主体密钥标识
+func matchIdentifier(tag der.Tag, content []byte, cert *x509.Certificate) (bool, error) {
+	switch tag {
+	case der.TagSequence:
+		p := der.NewParser(content)
+		issuer, err := p.ReadRaw(der.TagSequence)
+		if err != nil {
+			return false, ErrMalformed
+		}
+		serial, err := p.ReadInteger()
+		if err != nil || p.Finish() != nil {
+			return false, ErrMalformed
+		}
+		return bytes.Equal(issuer, cert.RawIssuer) && serial.Cmp(cert.SerialNumber) == 0, nil
+	case der.Context(0, false):
+		return len(cert.SubjectKeyId) > 0 && bytes.Equal(content, cert.SubjectKeyId), nil
+	}
+	return false, ErrMalformed
+}
+
+// readAlgorithm 读取AlgorithmIdentifier，返回OID和参数（没有参数时为nil）
+func readAlgorithm(p *der.Parser) (der.OID, []byte, error) {
+	seq, err := p.ReadSequence()
+	if err != nil {
+		return nil, nil, ErrMalformed
+	}
+	oid, err := seq.ReadOID()
+	if err != nil {
+		return nil, nil, ErrMalformed
+	}
+	var params []byte
+	if !seq.Empty() {
+		if _, _, params, err = seq.ReadElement(); err != nil || seq.Finish() != nil {
+			return nil, nil, ErrMalformed
+		}
+	}
+	return oid, params, nil
+}
+
+// addAlgorithm 追加AlgorithmIdentifier，null为true时带NULL参数
+func addAlgorithm(b *der.Builder, oid der.OID, null bool) {
+	b.AddSequence(func(b *der.Builder) {
+		b.AddOID(oid)
+		if null {
+			b.AddNull()
+		}
+	})
+}
@@ -0,0 +1,420 @@
+package cms
+
+import (
+	"crypto/rand"
+	stdrsa "crypto/rsa"
+	"math/big"
+
+	"github.com/laenix/gsc/aes"
+	"github.com/laenix/gsc/der"
+	"github.com/laenix/gsc/modes"
+	"github.com/laenix/gsc/padding"
+	"github.com/laenix/gsc/rsa"
+	"github.com/laenix/gsc/sm2"
+	"github.com/laenix/gsc/sm4"
+	"github.com/laenix/gsc/x509"
+)
+
+// ContentEncryption 是数字信封的内容加密算法，均为CBC模式和PKCS#7填充
+type ContentEncryption int
+
+// 支持的内容加密算法
+const (
+	// DefaultContentEncryption 在全部接收者都使用SM2时选择SM4CBC，否则选择AES256CBC
+	DefaultContentEncryption ContentEncryption = iota
+	AES128CBC
+	AES256CBC
+	SM4CBC
+)
+
+// contentEncryptions 是内容加密算法的OID、密钥长度和分组密码，三种算法的分组长度都是16字节
+var contentEncryptions = map[ContentEncryption]struct {
+	oid     der.OID
+	keySize int
+	newFunc func(key []byte) (modes.BlockCipher, error)
+}{
+	AES128CBC: {der.OID{2, 16, 840, 1, 101, 3, 4, 1, 2}, aes.KeySize128, func(key []byte) (modes.BlockCipher, error) { return aes.New(key) }},
+	AES256CBC: {der.OID{2, 16, 840, 1, 101, 3, 4, 1, 42}, aes.KeySize256, func(key []byte) (modes.BlockCipher, error) { return aes.New(key) }},
+	SM4CBC:    {der.OID{1, 2, 156, 10197, 1, 104, 2}, 16, func(key []byte) (modes.BlockCipher, error) { return sm4.New(key) }},
+}
+
+// EncryptOptions 是Encrypt的参数，nil表示使用默认值
+type EncryptOptions struct {
+	ContentEncryption ContentEncryption
+	// OAEP 非nil时RSA接收者使用RSAES-OAEP（RFC 8017）及其参数加密内容加密密钥，
+	// 算法标识按RFC 4055编码；为nil时使用PKCS#1 v1.5
+	OAEP *rsa.OAEPOptions
+}
+
+// Encrypt 为recipients中的每个证书加密content，返回DER编码的ContentInfo（EnvelopedData）
+//
+// 随机生成的内容加密密钥按接收者公钥类型加密：RSA使用PKCS#1 v1.5（设置opts.OAEP时使用OAEP），
+// SM2使用sm2-3并编码为SM2Cipher。
+// 全部接收者都使用SM2时按GM/T 0010生成消息；不支持ECDH类的密钥协商接收者
+func Encrypt(content []byte, recipients []*x509.Certificate, opts *EncryptOptions) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, ErrNoRecipients
+	}
+	prof := profileGMT0010
+	for _, r := range recipients {
+		if _, ok := r.PublicKey.(*sm2.PublicKey); !ok {
+			prof = profileCMS
+		}
+	}
+	enc := DefaultContentEncryption
+	var oaep *rsa.OAEPOptions
+	if opts != nil {
+		enc, oaep = opts.ContentEncryption, opts.OAEP
+	}
+	if enc == DefaultContentEncryption {
+		enc = AES256CBC
+		if prof == profileGMT0010 {
+			enc = SM4CBC
+		}
+	}
+	ce, ok := contentEncryptions[enc]
+	if !ok {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	key := make([]byte, ce.keySize)
+	iv := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	block, err := ce.newFunc(key)
+	if err != nil {
+		return nil, err
+	}
+	cbc, err := modes.NewCBC(block, iv)
+	if err != nil {
+		return nil, err
+	}
+	padded, err := padding.PKCS7Padding(append([]byte(nil), content...), block.BlockSize())
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := cbc.Encrypt(padded)
+	if err != nil {
+		return nil, err
+	}
+
+	var infos [][]byte
+	for _, r := range recipients {
+		info, err := recipientInfo(r, key, oaep)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+
+	// EnvelopedData ::= SEQUENCE {
+	//   version CMSVersion,
+	//   recipientInfos SET OF RecipientInfo,
+	//   encryptedContentInfo SEQUENCE {
+	//     contentType, contentEncryptionAlgorithm, encryptedContent [0] IMPLICIT OCTET STRING } }
+	return contentInfo(prof.envelopedData, func(b *der.Builder) {
+		b.AddSequence(func(b *der.Builder) {
+			b.AddInt64(0)
+			addSetOf(b, der.TagSet, infos)
+			b.AddSequence(func(b *der.Builder) {
+				b.AddOID(prof.data)
+				b.AddSequence(func(b *der.Builder) {
+					b.AddOID(ce.oid)
+					b.AddOctetString(iv)
+				})
+				b.AddElement(der.Context(0, false), ciphertext)
+			})
+		})
+	})
+}
+
+// recipientInfo 为cert加密内容加密密钥，返回KeyTransRecipientInfo的编码
+//
+//	KeyTransRecipientInfo ::= SEQUENCE {
+//	  version CMSVersion,  -- 0
+//	  rid RecipientIdentifier,
+//	  keyEncryptionAlgorithm KeyEncryptionAlgorithmIdentifier,
+//	  encryptedKey OCTET STRING }
+func recipientInfo(cert *x509.Certificate, key []byte, oaep *rsa.OAEPOptions) ([]byte, error) {
+	// keyEncryptionAlgorithm的编码
+	var alg []byte
+	var encryptedKey []byte
+	var err error
+	switch pub := cert.PublicKey.(type) {
+	case *stdrsa.PublicKey:
+		k := rsa.FromStdPublicKey(pub)
+		if oaep != nil {
+			if alg, err = rsa.MarshalOAEPAlgorithm(oaep); err == nil {
+				encryptedKey, err = rsa.EncryptOAEP(nil, k, key, oaep)
+			}
+		} else {
+			alg = algorithmIdentifier(oidRSAEncryption, true)
+			encryptedKey, err = rsa.EncryptPKCS1v15(nil, k, key)
+		}
+	case *sm2.PublicKey:
+		alg = algorithmIdentifier(x509.OIDSM2Encrypt, false)
+		var c []byte
+		if c, err = sm2.New().Encrypt(pub, key, nil); err == nil {
+			encryptedKey, err = marshalSM2Cipher(c)
+		}
+	default:
+		return nil, ErrUnsupportedKeyType
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var b der.Builder
+	b.AddSequence(func(b *der.Builder) {
+		b.AddInt64(0)
+		addIssuerAndSerial(b, cert)
+		b.AddRaw(alg)
+		b.AddOctetString(encryptedKey)
+	})
+	return b.Bytes()
+}
+
+// algorithmIdentifier 返回addAlgorithm写入的AlgorithmIdentifier编码
+func algorithmIdentifier(oid der.OID, null bool) []byte {
+	var b der.Builder
+	addAlgorithm(&b, oid, null)
+	raw, _ := b.Bytes()
+	return raw
+}
+
+// Decrypt 使用接收者证书cert和对应的私钥priv打开数字信封，返回原文
+// cert用于在接收者列表中查找对应的RecipientInfo，priv支持*rsa.PrivateKey（crypto/rsa或gsc/rsa）和*sm2.PrivateKey；
+// RSA接收者可以使用PKCS#1 v1.5或RSAES-OAEP
+func Decrypt(data []byte, cert *x509.Certificate, priv any) ([]byte, error) {
+	_, p, err := parseContentInfo(data, func(prof *profile) der.OID { return prof.envelopedData })
+	if err != nil {
+		return nil, err
+	}
+	ed, err := p.ReadSequence()
+	if err != nil || p.Finish() != nil {
+		return nil, ErrMalformed
+	}
+	if _, err := ed.ReadInt64(); err != nil {
+		return nil, ErrMalformed
+	}
+	if _, _, err := ed.ReadOptional(der.Context(0, true)); err != nil {
+		return nil, ErrMalformed
+	}
+	infos, err := ed.ReadSet()
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	eci, err := ed.ReadSequence()
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	if _, _, err := ed.ReadOptional(der.Context(1, true)); err != nil || ed.Finish() != nil {
+		return nil, ErrMalformed
+	}
+
+	if _, err := eci.ReadOID(); err != nil {
+		return nil, ErrMalformed
+	}
+	algOID, params, err := readAlgorithm(eci)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, present, err := eci.ReadOptional(der.Context(0, false))
+	if err != nil || !present || eci.Finish() != nil {
+		return nil, ErrMalformed
+	}
+	var enc ContentEncryption
+	for e, ce := range contentEncryptions {
+		if ce.oid.Equal(algOID) {
+			enc = e
+		}
+	}
+	ce, ok := contentEncryptions[enc]
+	if !ok {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	ivParser := der.NewParser(params)
+	iv, err := ivParser.ReadOctetString()
+	if err != nil || ivParser.Finish() != nil || len(iv) != 16 {
+		return nil, ErrMalformed
+	}
+
+	key, err := findRecipient(infos, cert, priv, ce.keySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := ce.newFunc(key)
+	if err != nil {
+		return nil, err
+	}
+	cbc, err := modes.NewCBC(block, iv)
+	if err != nil {
+		return nil, err
+	}
+	padded, err := cbc.Decrypt(ciphertext)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	content, err := padding.PKCS7UnPadding(padded)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	return content, nil
+}
+
+// findRecipient 在RecipientInfo中查找cert并解密内容加密密钥，只处理KeyTransRecipientInfo
+func findRecipient(infos *der.Parser, cert *x509.Certificate, priv any, keySize int) ([]byte, error) {
+	for !infos.Empty() {
+		tag, content, _, err := infos.ReadElement()
+		if err != nil {
+			return nil, ErrMalformed
+		}
+		if tag != der.TagSequence {
+			continue
+		}
+		ktri := der.NewParser(content)
+		if _, err := ktri.ReadInt64(); err != nil {
+			return nil, ErrMalformed
+		}
+		ridTag, rid, _, err := ktri.ReadElement()
+		if err != nil {
+			return nil, ErrMalformed
+		}
+		algRaw, err := ktri.ReadRaw(der.TagSequence)
+		if err != nil {
+			return nil, ErrMalformed
+		}
+		alg, _, err := readAlgorithm(der.NewParser(algRaw))
+		if err != nil {
+			return nil, err
+		}
+		encryptedKey, err := ktri.ReadOctetString()
+		if err != nil || ktri.Finish() != nil {
+			return nil, ErrMalformed
+		}
+		ok, err := matchIdentifier(ridTag, rid, cert)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return decryptKey(alg, algRaw, encryptedKey, priv, keySize)
+		}
+	}
+	return nil, ErrNotRecipient
+}
+
+// decryptKey 解密内容加密密钥，algRaw是keyEncryptionAlgorithm的完整编码
+// RSA PKCS#1 v1.5使用DecryptPKCS1v15SessionKey：填充错误时得到随机密钥，在内容解密时才失败，避免Bleichenbacher攻击
+func decryptKey(alg der.OID, algRaw, encryptedKey []byte, priv any, keySize int) ([]byte, error) {
+	switch {
+	case alg.Equal(oidRSAEncryption):
+		k := rsaPrivateKey(priv)
+		if k == nil {
+			return nil, ErrKeyMismatch
+		}
+		key := make([]byte, keySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		if err := rsa.DecryptPKCS1v15SessionKey(k, encryptedKey, key); err != nil {
+			return nil, ErrDecryptionFailed
+		}
+		return key, nil
+	case alg.Equal(rsa.OIDRSAESOAEP):
+		opts, err := rsa.ParseOAEPAlgorithm(algRaw)
+		if err != nil {
+			return nil, ErrUnsupportedAlgorithm
+		}
+		k := rsaPrivateKey(priv)
+		if k == nil {
+			return nil, ErrKeyMismatch
+		}
+		key, err := rsa.DecryptOAEP(k, encryptedKey, opts)
+		if err != nil || len(key) != keySize {
+			return nil, ErrDecryptionFailed
+		}
+		return key, nil
+	case alg.Equal(x509.OIDSM2Encrypt):
+		k, ok := priv.(*sm2.PrivateKey)
+		if !ok {
+			return nil, ErrKeyMismatch
+		}
+		c, err := parseSM2Cipher(encryptedKey)
+		if err != nil {
+			return nil, err
+		}
+		key, err := sm2.New().Decrypt(k, c)
+		if err != nil || len(key) != keySize {
+			return nil, ErrDecryptionFailed
+		}
+		return key, nil
+	}
+	return nil, ErrUnsupportedAlgorithm
+}
+
+// rsaPrivateKey 返回priv对应的gsc/rsa私钥，priv是crypto/rsa私钥时转换，不是RSA私钥时返回nil
+func rsaPrivateKey(priv any) *rsa.PrivateKey {
+	switch k := priv.(type) {
+	case *rsa.PrivateKey:
+		return k
+	case *stdrsa.PrivateKey:
+		return rsa.FromStdPrivateKey(k)
+	}
+	return nil
+}
+
+// marshalSM2Cipher 将sm2包的密文 04 || x || y || C2 || C3 编码为GM/T 0009的SM2Cipher
+//
+//	SM2Cipher ::= SEQUENCE {
+//	  XCoordinate INTEGER,
+//	  YCoordinate INTEGER,
+//	  HASH        OCTET STRING SIZE(32),
+//	  CipherText  OCTET STRING }
+func marshalSM2Cipher(c []byte) ([]byte, error) {
+	var b der.Builder
+	b.AddSequence(func(b *der.Builder) {
+		b.AddInteger(new(big.Int).SetBytes(c[1:33]))
+		b.AddInteger(new(big.Int).SetBytes(c[33:65]))
+		b.AddOctetString(c[len(c)-32:])
+		b.AddOctetString(c[65 : len(c)-32])
+	})
+	return b.Bytes()
+}
+
+// parseSM2Cipher 将SM2Cipher转换为sm2包使用的 04 || x || y || C2 || C3
+func parseSM2Cipher(data []byte) ([]byte, error) {
+	p := der.NewParser(data)
+	seq, err := p.ReadSequence()
+	if err != nil || p.Finish() != nil {
+		return nil, ErrMalformed
+	}
+	x, err := seq.ReadInteger()
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	y, err := seq.ReadInteger()
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	hash, err := seq.ReadOctetString()
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	ciphertext, err := seq.ReadOctetString()
+	if err != nil || seq.Finish() != nil {
+		return nil, ErrMalformed
+	}
+	if x.Sign() < 0 || y.Sign() < 0 || x.BitLen() > 256 || y.BitLen() > 256 || len(hash) != 32 {
+		return nil, ErrMalformed
+	}
+	c := make([]byte, 65, 65+len(ciphertext)+32)
+	c[0] = 4
+	x.FillBytes(c[1:33])
+	y.FillBytes(c[33:65])
+	c = append(c, ciphertext...)
+	return append(c, hash...), nil
+}
@@ -0,0 +1,164 @@
+package cms
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/laenix/gsc/der"
+	gscrsa "github.com/laenix/gsc/rsa"
+	"github.com/laenix/gsc/sm2"
+	"github.com/laenix/gsc/x509"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	sm2Key, _ := sm2.New().GenerateKey(nil)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	sm2Cert := newCert(t, "sm2", &sm2Key.PublicKey, nil, sm2Key)
+	rsaCert := newCert(t, "rsa", &rsaKey.PublicKey, nil, rsaKey)
+	content := bytes.Repeat([]byte("机密文件"), 100)
+
+	for _, tt := range []struct {
+		recipients []*x509.Certificate
+		enc        ContentEncryption
+		prof       *profile
+		cipher     ContentEncryption
+	}{
+		{[]*x509.Certificate{sm2Cert}, DefaultContentEncryption, profileGMT0010, SM4CBC},
+		{[]*x509.Certificate{rsaCert}, DefaultContentEncryption, profileCMS, AES256CBC},
+		{[]*x509.Certificate{sm2Cert, rsaCert}, AES128CBC, profileCMS, AES128CBC},
+		{[]*x509.Certificate{rsaCert}, SM4CBC, profileCMS, SM4CBC},
+	} {
+		data, err := Encrypt(content, tt.recipients, &EncryptOptions{ContentEncryption: tt.enc})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prof, _, err := parseContentInfo(data, func(p *profile) der.OID { return p.envelopedData }); err != nil || prof != tt.prof {
+			t.Errorf("%v: 内容类型 %v", tt.enc, err)
+		}
+		var b der.Builder
+		b.AddOID(contentEncryptions[tt.cipher].oid)
+		oid, _ := b.Bytes()
+		if !bytes.Contains(data, oid) {
+			t.Errorf("%v: 应使用%v", tt.enc, tt.cipher)
+		}
+
+		for _, r := range tt.recipients {
+			var priv any = rsaKey
+			if r == sm2Cert {
+				priv = sm2Key
+			}
+			got, err := Decrypt(data, r, priv)
+			if err != nil || !bytes.Equal(got, content) {
+				t.Errorf("%v: %s: %v", tt.enc, r.Subject.CommonName, err)
+			}
+		}
+	}
+
+	// 空原文
+	data, err := Encrypt(nil, []*x509.Certificate{sm2Cert}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Decrypt(data, sm2Cert, sm2Key); err != nil || len(got) != 0 {
+		t.Errorf("空原文: %q %v", got, err)
+	}
+}
+
+func TestDecryptOpenSSL(t *testing.T) {
+	cert, key := loadRSA(t)
+	data, err := os.ReadFile("testdata/enveloped.der")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Decrypt(data, cert, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "hello, cms\n" {
+		t.Errorf("%q", got)
+	}
+}
+
+// 测试RSAES-OAEP接收者和gsc/rsa私钥，enveloped_oaep.der由OpenSSL 3.0生成：
+// openssl cms -encrypt -aes-128-cbc -keyopt rsa_padding_mode:oaep -keyopt rsa_oaep_md:sha256 -keyopt rsa_mgf1_md:sha256
+func TestRSAOAEP(t *testing.T) {
+	cert, stdKey := loadRSA(t)
+	key := gscrsa.FromStdPrivateKey(stdKey)
+	data, err := os.ReadFile("testdata/enveloped_oaep.der")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, priv := range []any{stdKey, key} {
+		if got, err := Decrypt(data, cert, priv); err != nil || string(got) != "hello, oaep" {
+			t.Errorf("%T: %q %v", priv, got, err)
+		}
+	}
+
+	for _, opts := range []*EncryptOptions{
+		nil,
+		{OAEP: &gscrsa.OAEPOptions{}},
+		{OAEP: &gscrsa.OAEPOptions{Hash: gscrsa.SHA384, MGFHash: gscrsa.SHA1, Label: []byte("cms")}},
+	} {
+		data, err := Encrypt([]byte("secret"), []*x509.Certificate{cert}, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if opts != nil {
+			alg, _ := gscrsa.MarshalOAEPAlgorithm(opts.OAEP)
+			if !bytes.Contains(data, alg) {
+				t.Errorf("%+v: 缺少OAEP算法标识", opts.OAEP)
+			}
+		}
+		if got, err := Decrypt(data, cert, key); err != nil || string(got) != "secret" {
+			t.Errorf("%+v: %q %v", opts, got, err)
+		}
+	}
+}
+
+func TestEncryptErrors(t *testing.T) {
+	key, _ := sm2.New().GenerateKey(nil)
+	other, _ := sm2.New().GenerateKey(nil)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	cert := newCert(t, "sm2", &key.PublicKey, nil, key)
+	otherCert := newCert(t, "other", &other.PublicKey, nil, other)
+
+	if _, err := Encrypt([]byte("x"), nil, nil); !errors.Is(err, ErrNoRecipients) {
+		t.Errorf("没有接收者: %v", err)
+	}
+	if _, err := Encrypt([]byte("x"), []*x509.Certificate{newCert(t, "ec", &p256.PublicKey, nil, p256)}, nil); !errors.Is(err, ErrUnsupportedKeyType) {
+		t.Errorf("ECDSA接收者: %v", err)
+	}
+	if _, err := Encrypt([]byte("x"), []*x509.Certificate{cert}, &EncryptOptions{ContentEncryption: 99}); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Errorf("未知算法: %v", err)
+	}
+
+	data, err := Encrypt([]byte("secret"), []*x509.Certificate{cert}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Decrypt(data, otherCert, other); !errors.Is(err, ErrNotRecipient) {
+		t.Errorf("非接收者: %v", err)
+	}
+	if _, err := Decrypt(data, cert, rsaKey); !errors.Is(err, ErrKeyMismatch) {
+		t.Errorf("私钥类型不符: %v", err)
+	}
+	if _, err := Decrypt(data, cert, other); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("错误的私钥: %v", err)
+	}
+	tampered := bytes.Clone(data)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := Decrypt(tampered, cert, key); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("篡改密文: %v", err)
+	}
+	signed, _ := Sign([]byte("x"), cert, key, nil)
+	if _, err := Decrypt(signed, cert, key); !errors.Is(err, ErrUnexpectedContent) {
+		t.Errorf("签名消息: %v", err)
+	}
+}
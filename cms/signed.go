@@ -0,0 +1,479 @@
+package cms
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	stded25519 "crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	stdrsa "crypto/rsa"
+	"math/big"
+	"time"
+
+	"github.com/laenix/gsc/der"
+	"github.com/laenix/gsc/ed25519"
+	"github.com/laenix/gsc/rsa"
+	"github.com/laenix/gsc/sm2"
+	"github.com/laenix/gsc/x509"
+)
+
+// 签名属性的OID（RFC 5652 第11节）
+var (
+	oidAttributeContentType   = der.OID{1, 2, 840, 113549, 1, 9, 3}
+	oidAttributeMessageDigest = der.OID{1, 2, 840, 113549, 1, 9, 4}
+	oidAttributeSigningTime   = der.OID{1, 2, 840, 113549, 1, 9, 5}
+)
+
+var oidRSAEncryption = der.OID{1, 2, 840, 113549, 1, 1, 1}
+
+// signatureAlgorithm 是SignerInfo中摘要算法和签名算法的组合，alg是验证签名时使用的证书签名算法
+type signatureAlgorithm struct {
+	alg    x509.SignatureAlgorithm
+	digest *digestAlgorithm
+	oid    der.OID
+	null   bool
+}
+
+// signatureAlgorithms 中同一alg的第一项用于签名，其余各项只在验证时接受
+// RSA按OpenSSL的习惯使用rsaEncryption，SM2按GM/T 0010使用sm2-1
+var signatureAlgorithms = []signatureAlgorithm{
+	{x509.SHA256WithRSA, digestSHA256, oidRSAEncryption, true},
+	{x509.SHA384WithRSA, digestSHA384, oidRSAEncryption, true},
+	{x509.SHA512WithRSA, digestSHA512, oidRSAEncryption, true},
+	{x509.SHA256WithRSA, digestSHA256, der.OID{1, 2, 840, 113549, 1, 1, 11}, true},
+	{x509.SHA384WithRSA, digestSHA384, der.OID{1, 2, 840, 113549, 1, 1, 12}, true},
+	{x509.SHA512WithRSA, digestSHA512, der.OID{1, 2, 840, 113549, 1, 1, 13}, true},
+	{x509.ECDSAWithSHA256, digestSHA256, der.OID{1, 2, 840, 10045, 4, 3, 2}, false},
+	{x509.ECDSAWithSHA384, digestSHA384, der.OID{1, 2, 840, 10045, 4, 3, 3}, false},
+	{x509.ECDSAWithSHA512, digestSHA512, der.OID{1, 2, 840, 10045, 4, 3, 4}, false},
+	{x509.PureEd25519, digestSHA512, der.OID{1, 3, 101, 112}, false},
+	{x509.SM2WithSM3, digestSM3, x509.OIDSM2Sign, false},
+	{x509.SM2WithSM3, digestSM3, x509.OIDSM2WithSM3, false},
+}
+
+// SignOptions 是Sign的参数，nil表示全部使用默认值
+type SignOptions struct {
+	// Detached 为true时生成分离签名，消息中不包含原文
+	Detached bool
+	// Certificates 是随签名者证书一起写入消息的证书，通常是中间CA证书
+	Certificates []*x509.Certificate
+	// SigningTime 是签名时间属性的值，零值表示当前时间
+	SigningTime time.Time
+	// NoAttributes 为true时不写入签名属性，签名直接覆盖原文
+	NoAttributes bool
+}
+
+// Sign 使用签名者证书cert和对应的私钥priv签名content，返回DER编码的ContentInfo
+//
+// 签名算法由priv的类型决定：RSA（crypto/rsa或gsc/rsa）使用SHA-256，ECDSA按曲线选择SHA-256/384/512，
+// Ed25519（crypto/ed25519或gsc/ed25519）使用SHA-512（RFC 8419），
+// SM2使用SM3并按GM/T 0010生成消息。默认写入内容类型、签名时间和消息摘要三个签名属性
+func Sign(content []byte, cert *x509.Certificate, priv any, opts *SignOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &SignOptions{}
+	}
+	sa, err := signerAlgorithm(priv)
+	if err != nil {
+		return nil, err
+	}
+	prof := profileCMS
+	if sa.alg == x509.SM2WithSM3 {
+		prof = profileGMT0010
+	}
+
+	signed := content
+	var attrs [][]byte
+	if !opts.NoAttributes {
+		signingTime := opts.SigningTime
+		if signingTime.IsZero() {
+			signingTime = time.Now()
+		}
+		if attrs, err = signedAttributes(prof.data, signingTime, sa.digest.sum(content)); err != nil {
+			return nil, err
+		}
+		// 签名覆盖以SET OF标签编码的签名属性
+		var b der.Builder
+		addSetOf(&b, der.TagSet, attrs)
+		if signed, err = b.Bytes(); err != nil {
+			return nil, err
+		}
+	}
+	signature, err := signMessage(sa, priv, signed)
+	if err != nil {
+		return nil, err
+	}
+	if err := cert.CheckSignature(sa.alg, signed, signature); err != nil {
+		return nil, ErrKeyMismatch
+	}
+
+	certs := [][]byte{cert.Raw}
+	for _, c := range opts.Certificates {
+		certs = append(certs, c.Raw)
+	}
+
+	// SignedData ::= SEQUENCE {
+	//   version CMSVersion,
+	//   digestAlgorithms SET OF DigestAlgorithmIdentifier,
+	//   encapContentInfo EncapsulatedContentInfo,
+	//   certificates [0] IMPLICIT CertificateSet OPTIONAL,
+	//   signerInfos SET OF SignerInfo }
+	return contentInfo(prof.signedData, func(b *der.Builder) {
+		b.AddSequence(func(b *der.Builder) {
+			b.AddInt64(1)
+			b.AddSet(func(b *der.Builder) { addAlgorithm(b, sa.digest.oid, false) })
+			b.AddSequence(func(b *der.Builder) {
+				b.AddOID(prof.data)
+				if !opts.Detached {
+					b.AddExplicit(0, func(b *der.Builder) { b.AddOctetString(content) })
+				}
+			})
+			addSetOf(b, der.Context(0, true), certs)
+			b.AddSet(func(b *der.Builder) {
+				b.AddSequence(func(b *der.Builder) {
+					b.AddInt64(1)
+					addIssuerAndSerial(b, cert)
+					addAlgorithm(b, sa.digest.oid, false)
+					if attrs != nil {
+						addSetOf(b, der.Context(0, true), attrs)
+					}
+					addAlgorithm(b, sa.oid, sa.null)
+					b.AddOctetString(signature)
+				})
+			})
+		})
+	})
+}
+
+// signerAlgorithm 根据私钥类型选择签名算法
+func signerAlgorithm(priv any) (*signatureAlgorithm, error) {
+	var alg x509.SignatureAlgorithm
+	switch k := priv.(type) {
+	case *stdrsa.PrivateKey, *rsa.PrivateKey:
+		alg = x509.SHA256WithRSA
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			alg = x509.ECDSAWithSHA256
+		case elliptic.P384():
+			alg = x509.ECDSAWithSHA384
+		case elliptic.P521():
+			alg = x509.ECDSAWithSHA512
+		default:
+			return nil, ErrUnsupportedKeyType
+		}
+	case stded25519.PrivateKey, ed25519.PrivateKey:
+		alg = x509.PureEd25519
+	case *sm2.PrivateKey:
+		alg = x509.SM2WithSM3
+	default:
+		return nil, ErrUnsupportedKeyType
+	}
+	for i := range signatureAlgorithms {
+		if signatureAlgorithms[i].alg == alg {
+			return &signatureAlgorithms[i], nil
+		}
+	}
+	return nil, ErrUnsupportedAlgorithm
+}
+
+// signMessage 签名msg，SM2签名使用默认用户标识并编码为 SEQUENCE { r, s }
+func signMessage(sa *signatureAlgorithm, priv any, msg []byte) ([]byte, error) {
+	switch k := priv.(type) {
+	case *sm2.PrivateKey:
+		raw, err := sm2.New().SignWithId(k, msg, nil)
+		if err != nil {
+			return nil, err
+		}
+		var b der.Builder
+		b.AddSequence(func(b *der.Builder) {
+			b.AddInteger(new(big.Int).SetBytes(raw[:32]))
+			b.AddInteger(new(big.Int).SetBytes(raw[32:]))
+		})
+		return b.Bytes()
+	case stded25519.PrivateKey:
+		return ed25519.Sign(ed25519.PrivateKey(k), msg)
+	case ed25519.PrivateKey:
+		return ed25519.Sign(k, msg)
+	case *stdrsa.PrivateKey, *rsa.PrivateKey:
+		return rsa.SignPKCS1v15(rsaPrivateKey(k), rsa.HashFor(sa.digest.hash), sa.digest.sum(msg))
+	}
+	return priv.(crypto.Signer).Sign(rand.Reader, sa.digest.sum(msg), sa.digest.hash)
+}
+
+// signedAttributes 返回内容类型、签名时间和消息摘要属性的编码
+//
+//	Attribute ::= SEQUENCE {
+//	  attrType   OBJECT IDENTIFIER,
+//	  attrValues SET OF AttributeValue }
+func signedAttributes(contentType der.OID, signingTime time.Time, digest []byte) ([][]byte, error) {
+	var attrs [][]byte
+	for _, attr := range []struct {
+		oid   der.OID
+		value func(*der.Builder)
+	}{
+		{oidAttributeContentType, func(b *der.Builder) { b.AddOID(contentType) }},
+		{oidAttributeSigningTime, func(b *der.Builder) { b.AddTime(signingTime) }},
+		{oidAttributeMessageDigest, func(b *der.Builder) { b.AddOctetString(digest) }},
+	} {
+		var b der.Builder
+		b.AddSequence(func(b *der.Builder) {
+			b.AddOID(attr.oid)
+			b.AddSet(attr.value)
+		})
+		data, err := b.Bytes()
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, data)
+	}
+	return attrs, nil
+}
+
+// SignedMessage 是验证通过的签名消息
+type SignedMessage struct {
+	// Content 是被签名的原文，分离签名时是调用方提供的原文
+	Content []byte
+	// Certificates 是消息携带的全部证书
+	Certificates []*x509.Certificate
+	// Signers 是全部签名者，每个签名都已验证
+	Signers []*Signer
+}
+
+// Signer 是一个签名者
+type Signer struct {
+	Certificate *x509.Certificate
+	// Chain 是从签名者证书到可信根证书的证书链
+	Chain []*x509.Certificate
+	// SigningTime 是签名时间属性，消息没有该属性时为零值
+	SigningTime time.Time
+}
+
+// Verify 验证CMS签名消息中的全部签名，返回原文和签名者
+//
+// detached是分离签名的原文；消息包含原文时detached必须为nil或与原文相同。签名者证书从消息携带的证书中
+// 按签名者标识查找，再用opts验证证书链，消息携带的其他证书作为中间CA证书使用。
+// 签名时间属性未经可信时间源证明，opts.CurrentTime为零值时按当前时间检查证书有效期
+func Verify(data, detached []byte, opts x509.VerifyOptions) (*SignedMessage, error) {
+	_, p, err := parseContentInfo(data, func(prof *profile) der.OID { return prof.signedData })
+	if err != nil {
+		return nil, err
+	}
+	sd, err := p.ReadSequence()
+	if err != nil || p.Finish() != nil {
+		return nil, ErrMalformed
+	}
+	if _, err := sd.ReadInt64(); err != nil {
+		return nil, ErrMalformed
+	}
+	if _, err := sd.ReadSet(); err != nil {
+		return nil, ErrMalformed
+	}
+
+	encap, err := sd.ReadSequence()
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	contentType, err := encap.ReadOID()
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	econtent, present, err := encap.ReadExplicit(0)
+	if err != nil || encap.Finish() != nil {
+		return nil, ErrMalformed
+	}
+	msg := &SignedMessage{Content: detached}
+	if present {
+		content, err := econtent.ReadOctetString()
+		if err != nil || econtent.Finish() != nil {
+			return nil, ErrMalformed
+		}
+		if detached != nil && !bytes.Equal(content, detached) {
+			return nil, ErrInvalidSignature
+		}
+		msg.Content = content
+	} else if detached == nil {
+		return nil, ErrNoContent
+	}
+
+	certs, _, err := sd.ReadOptional(der.Context(0, true))
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	for cp := der.NewParser(certs); !cp.Empty(); {
+		// 只处理X.509证书，忽略其他CertificateChoices
+		tag, _, raw, err := cp.ReadElement()
+		if err != nil {
+			return nil, ErrMalformed
+		}
+		if tag != der.TagSequence {
+			continue
+		}
+		c, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return nil, err
+		}
+		msg.Certificates = append(msg.Certificates, c)
+	}
+	if _, _, err := sd.ReadOptional(der.Context(1, true)); err != nil {
+		return nil, ErrMalformed
+	}
+	signerInfos, err := sd.ReadSet()
+	if err != nil || sd.Finish() != nil {
+		return nil, ErrMalformed
+	}
+
+	opts.Intermediates = opts.Intermediates.Clone()
+	for _, c := range msg.Certificates {
+		opts.Intermediates.AddCert(c)
+	}
+	for !signerInfos.Empty() {
+		si, err := signerInfos.ReadSequence()
+		if err != nil {
+			return nil, ErrMalformed
+		}
+		signer, err := verifySigner(si, contentType, msg.Content, msg.Certificates)
+		if err != nil {
+			return nil, err
+		}
+		if signer.Chain, err = signer.Certificate.Verify(opts); err != nil {
+			return nil, err
+		}
+		msg.Signers = append(msg.Signers, signer)
+	}
+	if len(msg.Signers) == 0 {
+		return nil, ErrNoSigner
+	}
+	return msg, nil
+}
+
+// verifySigner 解析并验证一个SignerInfo
+//
+//	SignerInfo ::= SEQUENCE {
+//	  version CMSVersion,
+//	  sid SignerIdentifier,
+//	  digestAlgorithm DigestAlgorithmIdentifier,
+//	  signedAttrs [0] IMPLICIT SignedAttributes OPTIONAL,
+//	  signatureAlgorithm SignatureAlgorithmIdentifier,
+//	  signature SignatureValue,
+//	  unsignedAttrs [1] IMPLICIT UnsignedAttributes OPTIONAL }
+func verifySigner(si *der.Parser, contentType der.OID, content []byte, certs []*x509.Certificate) (*Signer, error) {
+	if _, err := si.ReadInt64(); err != nil {
+		return nil, ErrMalformed
+	}
+	tag, sid, _, err := si.ReadElement()
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	signer := &Signer{}
+	for _, c := range certs {
+		ok, err := matchIdentifier(tag, sid, c)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			signer.Certificate = c
+			break
+		}
+	}
+	if signer.Certificate == nil {
+		return nil, ErrNoSigner
+	}
+
+	digestOID, _, err := readAlgorithm(si)
+	if err != nil {
+		return nil, err
+	}
+	attrs, hasAttrs, err := si.ReadOptional(der.Context(0, true))
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	sigOID, _, err := readAlgorithm(si)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := si.ReadOctetString()
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	if _, _, err := si.ReadOptional(der.Context(1, true)); err != nil || si.Finish() != nil {
+		return nil, ErrMalformed
+	}
+
+	var sa *signatureAlgorithm
+	for i := range signatureAlgorithms {
+		if signatureAlgorithms[i].digest.oid.Equal(digestOID) && signatureAlgorithms[i].oid.Equal(sigOID) {
+			sa = &signatureAlgorithms[i]
+			break
+		}
+	}
+	if sa == nil {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	signed := content
+	if hasAttrs {
+		if err := signer.checkAttributes(der.NewParser(attrs), contentType, sa.digest.sum(content)); err != nil {
+			return nil, err
+		}
+		var b der.Builder
+		b.AddElement(der.TagSet, attrs)
+		if signed, err = b.Bytes(); err != nil {
+			return nil, err
+		}
+	} else if !contentType.Equal(profileCMS.data) && !contentType.Equal(profileGMT0010.data) {
+		// 没有签名属性时内容类型必须是data（RFC 5652 第5.3节）
+		return nil, ErrMalformed
+	}
+	if err := signer.Certificate.CheckSignature(sa.alg, signed, signature); err != nil {
+		return nil, ErrInvalidSignature
+	}
+	return signer, nil
+}
+
+// checkAttributes 检查签名属性：内容类型必须与封装的内容类型相同，消息摘要必须与原文的摘要相同
+func (s *Signer) checkAttributes(p *der.Parser, contentType der.OID, digest []byte) error {
+	var hasType, hasDigest bool
+	for !p.Empty() {
+		attr, err := p.ReadSequence()
+		if err != nil {
+			return ErrMalformed
+		}
+		oid, err := attr.ReadOID()
+		if err != nil {
+			return ErrMalformed
+		}
+		values, err := attr.ReadSet()
+		if err != nil || attr.Finish() != nil {
+			return ErrMalformed
+		}
+		switch {
+		case oid.Equal(oidAttributeContentType):
+			v, err := values.ReadOID()
+			if err != nil || values.Finish() != nil || hasType {
+				return ErrMalformed
+			}
+			if !v.Equal(contentType) {
+				return ErrInvalidSignature
+			}
+			hasType = true
+		case oid.Equal(oidAttributeMessageDigest):
+			v, err := values.ReadOctetString()
+			if err != nil || values.Finish() != nil || hasDigest {
+				return ErrMalformed
+			}
+			if !bytes.Equal(v, digest) {
+				return ErrInvalidSignature
+			}
+			hasDigest = true
+		case oid.Equal(oidAttributeSigningTime):
+			if s.SigningTime, err = values.ReadTime(); err != nil || values.Finish() != nil {
+				return ErrMalformed
+			}
+		}
+	}
+	if !hasType || !hasDigest {
+		return ErrMalformed
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package cms
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/laenix/gsc/der"
+	gsced25519 "github.com/laenix/gsc/ed25519"
+	"github.com/laenix/gsc/sm2"
+	"github.com/laenix/gsc/x509"
+)
+
+var testNow = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+// newCert 签发证书，parent为nil时自签名
+func newCert(t *testing.T, name string, pub any, parent *x509.Certificate, parentPriv any) *x509.Certificate {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:              time.Date(2034, 1, 1, 0, 0, 0, 0, time.UTC),
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil || name == "intermediate",
+	}
+	if parent == nil {
+		parent = template
+	}
+	data, err := x509.CreateCertificate(template, parent, pub, parentPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := x509.ParseCertificate(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// loadRSA 读取OpenSSL生成的RSA密钥和自签名证书
+func loadRSA(t *testing.T) (*x509.Certificate, *rsa.PrivateKey) {
+	t.Helper()
+	data, err := os.ReadFile("testdata/rsa_cert.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(data)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err = os.ReadFile("testdata/rsa_key.pem"); err != nil {
+		t.Fatal(err)
+	}
+	block, _ = pem.Decode(data)
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key.(*rsa.PrivateKey)
+}
+
+func rootsOf(certs ...*x509.Certificate) x509.VerifyOptions {
+	roots := x509.NewCertPool()
+	for _, c := range certs {
+		roots.AddCert(c)
+	}
+	return x509.VerifyOptions{Roots: roots, CurrentTime: testNow}
+}
+
+func TestSignVerify(t *testing.T) {
+	sm2Key, _ := sm2.New().GenerateKey(nil)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	edPub, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	content := []byte("待签名的文件内容")
+	signingTime := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		priv, pub any
+		prof      *profile
+	}{
+		{sm2Key, &sm2Key.PublicKey, profileGMT0010},
+		{rsaKey, &rsaKey.PublicKey, profileCMS},
+		{p384, &p384.PublicKey, profileCMS},
+		{edKey, edPub, profileCMS},
+		{gsced25519.PrivateKey(edKey), gsced25519.PublicKey(edPub), profileCMS},
+	} {
+		cert := newCert(t, "signer", tt.pub, nil, tt.priv)
+		opts := rootsOf(cert)
+
+		data, err := Sign(content, cert, tt.priv, &SignOptions{SigningTime: signingTime})
+		if err != nil {
+			t.Fatalf("%T: %v", tt.priv, err)
+		}
+		if prof, _, err := parseContentInfo(data, func(p *profile) der.OID { return p.signedData }); err != nil || prof != tt.prof {
+			t.Errorf("%T: 内容类型 %v", tt.priv, err)
+		}
+		msg, err := Verify(data, nil, opts)
+		if err != nil {
+			t.Fatalf("%T: %v", tt.priv, err)
+		}
+		if !bytes.Equal(msg.Content, content) || len(msg.Signers) != 1 || !msg.Signers[0].Certificate.Equal(cert) ||
+			!msg.Signers[0].SigningTime.Equal(signingTime) || len(msg.Signers[0].Chain) != 1 {
+			t.Errorf("%T: 验证结果 %+v", tt.priv, msg.Signers[0])
+		}
+
+		// 分离签名
+		data, err = Sign(content, cert, tt.priv, &SignOptions{Detached: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Verify(data, nil, opts); !errors.Is(err, ErrNoContent) {
+			t.Errorf("%T: 缺少原文: %v", tt.priv, err)
+		}
+		if msg, err := Verify(data, content, opts); err != nil || !bytes.Equal(msg.Content, content) {
+			t.Errorf("%T: 分离签名: %v", tt.priv, err)
+		}
+		if _, err := Verify(data, []byte("其他内容"), opts); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%T: 原文不符: %v", tt.priv, err)
+		}
+
+		// 不带签名属性
+		data, err = Sign(content, cert, tt.priv, &SignOptions{NoAttributes: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg, err := Verify(data, nil, opts); err != nil || !msg.Signers[0].SigningTime.IsZero() {
+			t.Errorf("%T: 无签名属性: %v", tt.priv, err)
+		}
+	}
+}
+
+// 测试签名者证书由消息携带的中间CA签发
+func TestSignVerifyChain(t *testing.T) {
+	rootKey, _ := sm2.New().GenerateKey(nil)
+	interKey, _ := sm2.New().GenerateKey(nil)
+	key, _ := sm2.New().GenerateKey(nil)
+	root := newCert(t, "root", &rootKey.PublicKey, nil, rootKey)
+	inter := newCert(t, "intermediate", &interKey.PublicKey, root, rootKey)
+	cert := newCert(t, "signer", &key.PublicKey, inter, interKey)
+
+	data, err := Sign([]byte("contract"), cert, key, &SignOptions{Certificates: []*x509.Certificate{inter}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := Verify(data, nil, rootsOf(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msg.Certificates) != 2 || len(msg.Signers[0].Chain) != 3 {
+		t.Errorf("证书 %d，证书链 %d", len(msg.Certificates), len(msg.Signers[0].Chain))
+	}
+
+	other, _ := sm2.New().GenerateKey(nil)
+	if _, err := Verify(data, nil, rootsOf(newCert(t, "other root", &other.PublicKey, nil, other))); !errors.Is(err, x509.ErrUnknownAuthority) {
+		t.Errorf("不受信任的根证书: %v", err)
+	}
+	if _, err := Verify(data, nil, x509.VerifyOptions{Roots: x509.NewCertPool(), CurrentTime: time.Date(2040, 1, 1, 0, 0, 0, 0, time.UTC)}); !errors.Is(err, x509.ErrExpired) {
+		t.Errorf("证书过期: %v", err)
+	}
+}
+
+func TestVerifyOpenSSL(t *testing.T) {
+	cert, key := loadRSA(t)
+	data, err := os.ReadFile("testdata/signed.der")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// OpenSSL证书的有效期从生成时开始，按当前时间验证
+	opts := rootsOf(cert)
+	opts.CurrentTime = time.Time{}
+	msg, err := Verify(data, nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(msg.Content) != "hello, cms\n" || msg.Signers[0].SigningTime.IsZero() {
+		t.Errorf("%q %v", msg.Content, msg.Signers[0].SigningTime)
+	}
+
+	// OpenSSL签名者证书签名的消息
+	data, err = Sign(msg.Content, cert, key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(data, nil, opts); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSignErrors(t *testing.T) {
+	key, _ := sm2.New().GenerateKey(nil)
+	other, _ := sm2.New().GenerateKey(nil)
+	cert := newCert(t, "signer", &key.PublicKey, nil, key)
+	content := []byte("message")
+
+	if _, err := Sign(content, cert, other, nil); !errors.Is(err, ErrKeyMismatch) {
+		t.Errorf("私钥与证书不符: %v", err)
+	}
+	if _, err := Sign(content, cert, struct{}{}, nil); !errors.Is(err, ErrUnsupportedKeyType) {
+		t.Errorf("不支持的私钥: %v", err)
+	}
+
+	data, err := Sign(content, cert, key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := bytes.Clone(data)
+	i := bytes.Index(tampered, content)
+	tampered[i] ^= 1
+	if _, err := Verify(tampered, nil, rootsOf(cert)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("篡改原文: %v", err)
+	}
+	if _, err := Verify(data, nil, rootsOf(newCert(t, "signer", &other.PublicKey, nil, other))); err == nil {
+		t.Error("签名者证书不受信任时应验证失败")
+	}
+	if _, err := Verify(data[:len(data)-1], nil, rootsOf(cert)); !errors.Is(err, ErrMalformed) {
+		t.Errorf("截断: %v", err)
+	}
+	enveloped, _ := Encrypt(content, []*x509.Certificate{cert}, nil)
+	if _, err := Verify(enveloped, nil, rootsOf(cert)); !errors.Is(err, ErrUnexpectedContent) {
+		t.Errorf("数字信封: %v", err)
+	}
+}
@@ -0,0 +1,286 @@
+package gsc
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/kdf/argon2"
+	"github.com/laenix/gsc/kdf/scrypt"
+)
+
+const (
+	// ContainerVersion 是当前文件容器格式版本
+	ContainerVersion = 1
+	// 容器魔数
+	containerMagic = "GSCF"
+	// 容器的盐长度
+	containerSaltSize = 16
+	// 容器内分块流使用的用途
+	containerPurpose = "container"
+	// 解析容器时接受的KDF参数上限，防止伪造的头部耗尽内存或CPU
+	maxContainerArgon2Time   = 1 << 10
+	maxContainerArgon2Memory = 4 << 20 // KiB，即4 GiB
+	maxContainerScryptLogN   = 24
+	maxContainerScryptRP     = 1 << 10
+)
+
+// 错误定义
+var (
+	ErrInvalidContainer = gscerr.New(gscerr.ErrMalformed, "gsc: invalid container header")
+	ErrUnsupportedKDF   = gscerr.New(gscerr.ErrUnsupported, "gsc: unsupported container KDF")
+	ErrInvalidKDFParams = gscerr.New(gscerr.ErrParameter, "gsc: invalid container KDF parameters")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidContainer: "gsc: 文件容器头部无效",
+		ErrUnsupportedKDF:   "gsc: 不支持的容器密钥派生函数",
+		ErrInvalidKDFParams: "gsc: 容器密钥派生参数无效",
+	})
+}
+
+// KDF 标识容器从口令派生密钥的方式
+type KDF uint8
+
+// 支持的容器KDF
+const (
+	// KDFNone 表示直接使用调用方给出的密钥，不派生
+	KDFNone KDF = iota
+	// KDFArgon2id 使用Argon2id从口令派生密钥
+	KDFArgon2id
+	// KDFScrypt 使用scrypt从口令派生密钥
+	KDFScrypt
+)
+
+// String 返回KDF名称
+func (k KDF) String() string {
+	switch k {
+	case KDFNone:
+		return "none"
+	case KDFArgon2id:
+		return "argon2id"
+	case KDFScrypt:
+		return "scrypt"
+	}
+	return fmt.Sprintf("KDF(%d)", uint8(k))
+}
+
+// ContainerOptions 是NewContainerWriter的选项，nil表示全部使用默认值
+type ContainerOptions struct {
+	// Algorithm 是分块加密使用的AEAD算法，为0时使用AES256GCM
+	Algorithm Algorithm
+	// KDF 是密钥派生方式，零值KDFNone表示secret就是密钥
+	KDF KDF
+	// Time、Memory（KiB）和Threads是Argon2id的参数，为0时使用argon2.DefaultParams
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+	// LogN、R和P是scrypt的参数，N = 2^LogN，为0时使用LogN=15、R=8、P=1
+	LogN uint8
+	R    uint32
+	P    uint32
+	// ChunkSize 是明文分块大小，为0时使用DefaultStreamChunkSize
+	ChunkSize int
+}
+
+// containerHeader 是容器头部中的KDF信息
+type containerHeader struct {
+	kdf     KDF
+	time    uint32
+	memory  uint32
+	threads uint8
+	logN    uint8
+	r, p    uint32
+	salt    []byte
+}
+
+// NewContainerWriter 创建一个向w写入自描述加密文件容器的StreamWriter
+// 容器格式：魔数"GSCF" || 版本 || KDF || KDF参数 || 盐长度(1) || 盐 || 分块流（见NewStreamWriter）。
+// 分块流头部记录算法、分块大小和nonce前缀；容器头部作为附加数据参与每个分块的认证，
+// 因此KDF参数被篡改时解密失败。secret在KDFNone时为密钥，否则为口令。
+// 写完后必须调用Close
+func NewContainerWriter(secret []byte, w io.Writer, opts *ContainerOptions) (*StreamWriter, error) {
+	var o ContainerOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Algorithm == 0 {
+		o.Algorithm = AES256GCM
+	}
+	if o.ChunkSize == 0 {
+		o.ChunkSize = DefaultStreamChunkSize
+	}
+
+	h := &containerHeader{kdf: o.KDF}
+	switch o.KDF {
+	case KDFNone:
+	case KDFArgon2id:
+		def := argon2.DefaultParams()
+		h.time, h.memory, h.threads = orDefault(o.Time, def.Time), orDefault(o.Memory, def.Memory), orDefault(o.Threads, def.Threads)
+	case KDFScrypt:
+		h.logN, h.r, h.p = orDefault(o.LogN, 15), orDefault(o.R, 8), orDefault(o.P, 1)
+	default:
+		return nil, ErrUnsupportedKDF
+	}
+	if err := h.check(); err != nil {
+		return nil, err
+	}
+	if h.kdf != KDFNone {
+		h.salt = make([]byte, containerSaltSize)
+		if _, err := rand.Read(h.salt); err != nil {
+			return nil, err
+		}
+	}
+
+	key, err := h.deriveKey(secret, o.Algorithm.KeySize())
+	if err != nil {
+		return nil, err
+	}
+	header := h.marshal()
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return NewStreamWriterSize(o.Algorithm, key, containerPurpose, w, header, o.ChunkSize)
+}
+
+// NewContainerReader 读取容器头部，按其中的KDF参数派生密钥，返回解密其余内容的StreamReader
+// 口令或密钥错误时，第一次Read返回modes.ErrAuthFailed
+func NewContainerReader(secret []byte, r io.Reader) (*StreamReader, error) {
+	header, h, err := readContainerHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// 预读分块流头部中的算法以确定密钥长度，再交给NewStreamReader完整解析
+	peek := make([]byte, 6)
+	if _, err := io.ReadFull(r, peek); err != nil || !bytes.Equal(peek[:4], []byte(streamMagic)) {
+		return nil, ErrInvalidContainer
+	}
+	alg := Algorithm(peek[5])
+	if alg.KeySize() == 0 {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	key, err := h.deriveKey(secret, alg.KeySize())
+	if err != nil {
+		return nil, err
+	}
+	return NewStreamReader(key, containerPurpose, io.MultiReader(bytes.NewReader(peek), r), header)
+}
+
+// orDefault 返回v，v为零值时返回def
+func orDefault[T comparable](v, def T) T {
+	var zero T
+	if v == zero {
+		return def
+	}
+	return v
+}
+
+// check 检查KDF参数是否在允许范围内
+func (h *containerHeader) check() error {
+	switch h.kdf {
+	case KDFArgon2id:
+		if h.time == 0 || h.time > maxContainerArgon2Time || h.threads == 0 ||
+			h.memory < 8*uint32(h.threads) || h.memory > maxContainerArgon2Memory {
+			return ErrInvalidKDFParams
+		}
+	case KDFScrypt:
+		if h.logN < 1 || h.logN > maxContainerScryptLogN || h.r == 0 || h.r > maxContainerScryptRP ||
+			h.p == 0 || h.p > maxContainerScryptRP {
+			return ErrInvalidKDFParams
+		}
+	}
+	return nil
+}
+
+// deriveKey 按KDF从secret派生size字节的密钥
+func (h *containerHeader) deriveKey(secret []byte, size int) ([]byte, error) {
+	switch h.kdf {
+	case KDFArgon2id:
+		return argon2.IDKey(secret, h.salt, h.time, h.memory, h.threads, uint32(size))
+	case KDFScrypt:
+		return scrypt.Key(secret, h.salt, 1<<h.logN, int(h.r), int(h.p), size)
+	}
+	if len(secret) != size {
+		return nil, ErrInvalidKeySize
+	}
+	return secret, nil
+}
+
+// marshal 编码容器头部，KDF参数按KDF不同为：
+// Argon2id为 time(4) || memory(4) || threads(1)，scrypt为 logN(1) || r(4) || p(4)，KDFNone为空
+func (h *containerHeader) marshal() []byte {
+	out := make([]byte, 0, 4+2+9+1+len(h.salt))
+	out = append(out, containerMagic...)
+	out = append(out, ContainerVersion, byte(h.kdf))
+	switch h.kdf {
+	case KDFArgon2id:
+		out = binary.BigEndian.AppendUint32(out, h.time)
+		out = binary.BigEndian.AppendUint32(out, h.memory)
+		out = append(out, h.threads)
+	case KDFScrypt:
+		out = append(out, h.logN)
+		out = binary.BigEndian.AppendUint32(out, h.r)
+		out = binary.BigEndian.AppendUint32(out, h.p)
+	}
+	out = append(out, byte(len(h.salt)))
+	return append(out, h.salt...)
+}
+
+// readContainerHeader 从r读取容器头部，返回原始头部字节和解析结果
+func readContainerHeader(r io.Reader) ([]byte, *containerHeader, error) {
+	header := make([]byte, 6)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, nil, ErrInvalidContainer
+	}
+	if !bytes.Equal(header[:4], []byte(containerMagic)) {
+		return nil, nil, ErrInvalidContainer
+	}
+	if header[4] != ContainerVersion {
+		return nil, nil, ErrUnsupportedVersion
+	}
+
+	h := &containerHeader{kdf: KDF(header[5])}
+	var err error
+	switch h.kdf {
+	case KDFNone:
+	case KDFArgon2id:
+		if header, err = readMore(r, header, 9); err != nil {
+			return nil, nil, ErrInvalidContainer
+		}
+		h.time = binary.BigEndian.Uint32(header[6:])
+		h.memory = binary.BigEndian.Uint32(header[10:])
+		h.threads = header[14]
+	case KDFScrypt:
+		if header, err = readMore(r, header, 9); err != nil {
+			return nil, nil, ErrInvalidContainer
+		}
+		h.logN = header[6]
+		h.r = binary.BigEndian.Uint32(header[7:])
+		h.p = binary.BigEndian.Uint32(header[11:])
+	default:
+		return nil, nil, ErrUnsupportedKDF
+	}
+	if err := h.check(); err != nil {
+		return nil, nil, err
+	}
+
+	if header, err = readMore(r, header, 1); err != nil {
+		return nil, nil, ErrInvalidContainer
+	}
+	saltLen := int(header[len(header)-1])
+	if h.kdf == KDFNone && saltLen != 0 || h.kdf != KDFNone && saltLen == 0 {
+		return nil, nil, ErrInvalidContainer
+	}
+	if header, err = readMore(r, header, saltLen); err != nil {
+		return nil, nil, ErrInvalidContainer
+	}
+	h.salt = header[len(header)-saltLen:]
+	return header, h, nil
+}
@@ -0,0 +1,119 @@
+package gsc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/laenix/gsc/modes"
+)
+
+// sealContainer 将data写入容器并返回完整的容器字节
+func sealContainer(t *testing.T, secret, data []byte, opts *ContainerOptions) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewContainerWriter(secret, &buf, opts)
+	if err != nil {
+		t.Fatalf("创建容器失败: %v", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("关闭失败: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// openContainer 解密容器并返回全部明文
+func openContainer(secret, container []byte) ([]byte, error) {
+	r, err := NewContainerReader(secret, bytes.NewReader(container))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestContainerRoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte("容器往返测试"), 50)
+	tests := []struct {
+		name   string
+		secret []byte
+		opts   *ContainerOptions
+	}{
+		{"默认", bytes.Repeat([]byte{1}, 32), nil},
+		{"SM4-GCM", bytes.Repeat([]byte{2}, 16), &ContainerOptions{Algorithm: SM4GCM, ChunkSize: 64}},
+		{"Argon2id", []byte("口令"), &ContainerOptions{KDF: KDFArgon2id, Time: 1, Memory: 64, Threads: 1, ChunkSize: 100}},
+		{"scrypt", []byte("口令"), &ContainerOptions{Algorithm: AES128GCM, KDF: KDFScrypt, LogN: 4, R: 8, P: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := sealContainer(t, tt.secret, data, tt.opts)
+			got, err := openContainer(tt.secret, c)
+			if err != nil {
+				t.Fatalf("解密失败: %v", err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Fatal("解密结果不匹配")
+			}
+		})
+	}
+}
+
+// 测试口令错误或头部被篡改时解密失败
+func TestContainerTampering(t *testing.T) {
+	opts := &ContainerOptions{KDF: KDFScrypt, LogN: 4, R: 8, P: 1}
+	c := sealContainer(t, []byte("正确的口令"), []byte("secret data"), opts)
+
+	if _, err := openContainer([]byte("错误的口令"), c); !errors.Is(err, modes.ErrAuthFailed) {
+		t.Errorf("口令错误时应返回ErrAuthFailed，实际%v", err)
+	}
+
+	// 修改盐的最后一字节：头部参与认证，派生出的密钥也不同
+	tampered := bytes.Clone(c)
+	tampered[len(containerMagic)+2+9+containerSaltSize] ^= 1
+	if _, err := openContainer([]byte("正确的口令"), tampered); !errors.Is(err, modes.ErrAuthFailed) {
+		t.Errorf("头部被篡改时应返回ErrAuthFailed，实际%v", err)
+	}
+
+	// 截断最后一个分块
+	if _, err := openContainer([]byte("正确的口令"), c[:len(c)-1]); err == nil {
+		t.Error("截断的容器应解密失败")
+	}
+}
+
+func TestContainerErrors(t *testing.T) {
+	if _, err := NewContainerWriter([]byte("pw"), io.Discard, &ContainerOptions{KDF: KDF(9)}); !errors.Is(err, ErrUnsupportedKDF) {
+		t.Errorf("未知KDF: %v", err)
+	}
+	if _, err := NewContainerWriter([]byte("pw"), io.Discard, &ContainerOptions{KDF: KDFScrypt, LogN: 40}); !errors.Is(err, ErrInvalidKDFParams) {
+		t.Errorf("scrypt参数过大: %v", err)
+	}
+	if _, err := NewContainerWriter([]byte("short"), io.Discard, nil); !errors.Is(err, ErrInvalidKeySize) {
+		t.Errorf("KDFNone时密钥长度错误: %v", err)
+	}
+
+	c := sealContainer(t, []byte("pw"), []byte("data"), &ContainerOptions{KDF: KDFArgon2id, Time: 1, Memory: 64, Threads: 1})
+	tests := []struct {
+		name string
+		edit func(c []byte)
+		want error
+	}{
+		{"魔数", func(c []byte) { c[0] = 'X' }, ErrInvalidContainer},
+		{"版本", func(c []byte) { c[4] = 99 }, ErrUnsupportedVersion},
+		{"KDF", func(c []byte) { c[5] = 9 }, ErrUnsupportedKDF},
+		// 伪造的超大内存参数在派生密钥之前被拒绝
+		{"参数", func(c []byte) { c[10] = 0xff }, ErrInvalidKDFParams},
+	}
+	for _, tt := range tests {
+		bad := bytes.Clone(c)
+		tt.edit(bad)
+		if _, err := openContainer([]byte("pw"), bad); !errors.Is(err, tt.want) {
+			t.Errorf("%s: 期望%v，实际%v", tt.name, tt.want, err)
+		}
+	}
+	if _, err := openContainer([]byte("pw"), c[:8]); !errors.Is(err, ErrInvalidContainer) {
+		t.Errorf("截断的头部: %v", err)
+	}
+}
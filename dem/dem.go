@@ -0,0 +1,162 @@
+// Package dem 定义数据封装机制（DEM）的通用接口，并提供与kem组合的KEM/DEM加密
+//
+// DEM是在一次性密钥下工作的认证加密：每个密钥只加密一条消息，因此不需要nonce。
+// Seal和Open将任意kem.Scheme与任意DEM组合，上层协议只需面向这两个抽象编写一次
+package dem
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+
+	"github.com/laenix/gsc/aes"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/kdf/hkdf"
+	"github.com/laenix/gsc/kem"
+	"github.com/laenix/gsc/modes"
+	"github.com/laenix/gsc/sm4"
+)
+
+// 错误定义
+var (
+	ErrInvalidKeySize    = gscerr.New(gscerr.ErrKeySize, "dem: key size does not match the scheme")
+	ErrInvalidCiphertext = gscerr.New(gscerr.ErrMalformed, "dem: invalid ciphertext format")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidKeySize:    "dem: 密钥长度与方案不匹配",
+		ErrInvalidCiphertext: "dem: 密文格式无效",
+	})
+}
+
+// Scheme 是数据封装机制
+// 实现可以假设每个密钥只使用一次，调用方不得用同一密钥多次调用Seal
+type Scheme interface {
+	// Name 返回方案名称
+	Name() string
+	// KeySize 返回密钥长度（字节）
+	KeySize() int
+	// Seal 使用一次性密钥加密并认证明文
+	Seal(key, plaintext, aad []byte) ([]byte, error)
+	// Open 解密并验证密文，失败时返回modes.ErrAuthFailed
+	Open(key, ciphertext, aad []byte) ([]byte, error)
+}
+
+// 使用全零nonce的一次性GCM
+var (
+	AES128GCM Scheme = gcmScheme{name: "AES-128-GCM", keySize: 16, newCipher: newAES}
+	AES256GCM Scheme = gcmScheme{name: "AES-256-GCM", keySize: 32, newCipher: newAES}
+	SM4GCM    Scheme = gcmScheme{name: "SM4-GCM", keySize: 16, newCipher: newSM4}
+)
+
+// gcmScheme 是一次性密钥下的GCM，由于密钥不重复使用，固定使用全零nonce是安全的
+type gcmScheme struct {
+	name      string
+	keySize   int
+	newCipher func(key []byte) (modes.BlockCipher, error)
+}
+
+func (s gcmScheme) Name() string { return s.name }
+
+func (s gcmScheme) KeySize() int { return s.keySize }
+
+func (s gcmScheme) Seal(key, plaintext, aad []byte) ([]byte, error) {
+	gcm, err := s.newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Seal(make([]byte, gcm.NonceSize()), plaintext, aad)
+}
+
+func (s gcmScheme) Open(key, ciphertext, aad []byte) ([]byte, error) {
+	gcm, err := s.newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Open(make([]byte, gcm.NonceSize()), ciphertext, aad)
+}
+
+func (s gcmScheme) newGCM(key []byte) (*modes.GCM, error) {
+	if len(key) != s.keySize {
+		return nil, ErrInvalidKeySize
+	}
+	block, err := s.newCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return modes.NewGCM(block)
+}
+
+func newAES(key []byte) (modes.BlockCipher, error) { return aes.New(key) }
+
+func newSM4(key []byte) (modes.BlockCipher, error) { return sm4.New(key) }
+
+// kemDEMLabel 是KEM共享密钥派生DEM密钥时的域分离标签
+const kemDEMLabel = "gsc/kem-dem"
+
+// Seal 以KEM/DEM方式为pub加密明文：封装一个新的共享密钥，派生DEM密钥后加密
+// 输出格式：封装密文长度(2) || 封装密文 || DEM密文。random为nil时使用crypto/rand
+func Seal(d Scheme, pub kem.PublicKey, plaintext, aad []byte, random io.Reader) ([]byte, error) {
+	k := pub.Scheme()
+	sharedKey, encapsulated, err := k.Encapsulate(pub, random)
+	if err != nil {
+		return nil, err
+	}
+	if len(encapsulated) > 1<<16-1 {
+		return nil, ErrInvalidCiphertext
+	}
+
+	key, err := deriveKey(k, d, sharedKey)
+	clear(sharedKey)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := d.Seal(key, plaintext, aad)
+	clear(key)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, 2+len(encapsulated)+len(sealed))
+	out = binary.BigEndian.AppendUint16(out, uint16(len(encapsulated)))
+	out = append(out, encapsulated...)
+	return append(out, sealed...), nil
+}
+
+// Open 使用priv解开Seal的输出，d必须与加密时相同
+func Open(d Scheme, priv kem.PrivateKey, ciphertext, aad []byte) ([]byte, error) {
+	if len(ciphertext) < 2 {
+		return nil, ErrInvalidCiphertext
+	}
+	n := int(binary.BigEndian.Uint16(ciphertext))
+	if len(ciphertext) < 2+n {
+		return nil, ErrInvalidCiphertext
+	}
+
+	k := priv.Scheme()
+	sharedKey, err := k.Decapsulate(priv, ciphertext[2:2+n])
+	if err != nil {
+		return nil, err
+	}
+	key, err := deriveKey(k, d, sharedKey)
+	clear(sharedKey)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)
+	return d.Open(key, ciphertext[2+n:], aad)
+}
+
+// deriveKey 从KEM共享密钥派生DEM密钥，info绑定两种方案的名称
+func deriveKey(k kem.Scheme, d Scheme, sharedKey []byte) ([]byte, error) {
+	info := make([]byte, 0, len(kemDEMLabel)+len(k.Name())+len(d.Name())+2)
+	info = append(info, kemDEMLabel...)
+	info = append(info, byte(len(k.Name())))
+	info = append(info, k.Name()...)
+	info = append(info, byte(len(d.Name())))
+	info = append(info, d.Name()...)
+	return hkdf.Key(sha256.New, sharedKey, nil, info, d.KeySize())
+}
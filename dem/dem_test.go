@@ -0,0 +1,62 @@
+package dem
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/laenix/gsc/kem"
+	"github.com/laenix/gsc/modes"
+)
+
+func TestKEMDEMRoundTrip(t *testing.T) {
+	plaintext := []byte("KEM/DEM组合加密")
+	aad := []byte("header")
+
+	for _, k := range []kem.Scheme{kem.X25519, kem.SM2, kem.MLKEM768} {
+		priv, err := k.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, d := range []Scheme{AES128GCM, AES256GCM, SM4GCM} {
+			sealed, err := Seal(d, priv.Public(), plaintext, aad, nil)
+			if err != nil {
+				t.Fatalf("%s/%s: Seal失败: %v", k.Name(), d.Name(), err)
+			}
+			opened, err := Open(d, priv, sealed, aad)
+			if err != nil {
+				t.Fatalf("%s/%s: Open失败: %v", k.Name(), d.Name(), err)
+			}
+			if !bytes.Equal(opened, plaintext) {
+				t.Fatalf("%s/%s: 解密结果不匹配", k.Name(), d.Name())
+			}
+
+			tampered := bytes.Clone(sealed)
+			tampered[len(tampered)-1] ^= 0x01
+			if _, err := Open(d, priv, tampered, aad); !errors.Is(err, modes.ErrAuthFailed) {
+				t.Errorf("%s/%s: 期望ErrAuthFailed，实际: %v", k.Name(), d.Name(), err)
+			}
+		}
+	}
+}
+
+// 测试派生的DEM密钥绑定了DEM方案
+func TestDEMMismatch(t *testing.T) {
+	priv, err := kem.X25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := Seal(AES128GCM, priv.Public(), []byte("data"), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(SM4GCM, priv, sealed, nil); !errors.Is(err, modes.ErrAuthFailed) {
+		t.Errorf("期望ErrAuthFailed，实际: %v", err)
+	}
+	if _, err := Open(AES128GCM, priv, sealed[:1], nil); !errors.Is(err, ErrInvalidCiphertext) {
+		t.Errorf("期望ErrInvalidCiphertext，实际: %v", err)
+	}
+	if _, err := AES256GCM.Seal(make([]byte, 16), nil, nil); !errors.Is(err, ErrInvalidKeySize) {
+		t.Errorf("期望ErrInvalidKeySize，实际: %v", err)
+	}
+}
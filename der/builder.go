@@ -0,0 +1,213 @@
+package der
+
+import (
+	"math/big"
+	"time"
+)
+
+// Builder 依次追加DER元素，第一个错误之后的调用都被忽略，由Bytes返回该错误
+type Builder struct {
+	buf []byte
+	err error
+}
+
+// Bytes 返回已构造的编码
+func (b *Builder) Bytes() ([]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.buf, nil
+}
+
+// AddElement 追加标签为tag、内容为content的元素
+func (b *Builder) AddElement(tag Tag, content []byte) {
+	if b.err != nil {
+		return
+	}
+	if len(content) > maxLength {
+		b.err = ErrOutOfRange
+		return
+	}
+	b.buf = append(b.buf, byte(tag))
+	b.buf = appendLength(b.buf, len(content))
+	b.buf = append(b.buf, content...)
+}
+
+// AddRaw 追加已经编码好的一个或多个元素，如签名时保留的原始TBS结构
+func (b *Builder) AddRaw(der []byte) {
+	if b.err == nil {
+		b.buf = append(b.buf, der...)
+	}
+}
+
+// AddConstructed 追加构造类型的元素，内容由f构造
+func (b *Builder) AddConstructed(tag Tag, f func(*Builder)) {
+	if b.err != nil {
+		return
+	}
+	child := &Builder{}
+	f(child)
+	if child.err != nil {
+		b.err = child.err
+		return
+	}
+	b.AddElement(tag|constructed, child.buf)
+}
+
+// AddSequence 追加SEQUENCE，内容由f构造
+func (b *Builder) AddSequence(f func(*Builder)) {
+	b.AddConstructed(TagSequence, f)
+}
+
+// AddSet 追加SET，内容由f构造
+// DER要求SET OF的元素按编码排序，由调用方按顺序追加
+func (b *Builder) AddSet(f func(*Builder)) {
+	b.AddConstructed(TagSet, f)
+}
+
+// AddExplicit 追加EXPLICIT上下文标签[n]，内容由f构造
+func (b *Builder) AddExplicit(n int, f func(*Builder)) {
+	b.AddConstructed(Context(n, true), f)
+}
+
+// AddInteger 追加INTEGER
+func (b *Builder) AddInteger(v *big.Int) {
+	if v == nil {
+		b.fail(ErrOutOfRange)
+		return
+	}
+	b.AddElement(TagInteger, integerBytes(v))
+}
+
+// AddInt64 追加INTEGER
+func (b *Builder) AddInt64(v int64) {
+	b.AddInteger(big.NewInt(v))
+}
+
+// AddBoolean 追加BOOLEAN
+func (b *Builder) AddBoolean(v bool) {
+	if v {
+		b.AddElement(TagBoolean, []byte{0xff})
+	} else {
+		b.AddElement(TagBoolean, []byte{0})
+	}
+}
+
+// AddNull 追加NULL
+func (b *Builder) AddNull() {
+	b.AddElement(TagNull, nil)
+}
+
+// AddOctetString 追加OCTET STRING
+func (b *Builder) AddOctetString(v []byte) {
+	b.AddElement(TagOctetString, v)
+}
+
+// AddBitString 追加字节对齐的BIT STRING，如公钥和签名
+func (b *Builder) AddBitString(v []byte) {
+	b.AddBits(BitString{Bytes: v, BitLength: 8 * len(v)})
+}
+
+// AddBits 追加任意位长的BIT STRING，未使用的低位被清零
+func (b *Builder) AddBits(v BitString) {
+	n := (v.BitLength + 7) / 8
+	if v.BitLength < 0 || n > len(v.Bytes) {
+		b.fail(ErrOutOfRange)
+		return
+	}
+	unused := n*8 - v.BitLength
+	content := make([]byte, 1+n)
+	content[0] = byte(unused)
+	copy(content[1:], v.Bytes[:n])
+	if n > 0 {
+		content[n] &= 0xff << unused
+	}
+	b.AddElement(TagBitString, content)
+}
+
+// AddOID 追加OBJECT IDENTIFIER
+func (b *Builder) AddOID(oid OID) {
+	if !oid.valid() {
+		b.fail(ErrOutOfRange)
+		return
+	}
+	content := appendBase128(nil, oid[0]*40+oid[1])
+	for _, v := range oid[2:] {
+		content = appendBase128(content, v)
+	}
+	b.AddElement(TagOID, content)
+}
+
+// AddString 追加tag类型的字符串，如UTF8String、PrintableString
+func (b *Builder) AddString(tag Tag, s string) {
+	b.AddElement(tag, []byte(s))
+}
+
+// AddTime 追加时间：2050年以前使用UTCTime，之后使用GeneralizedTime（RFC 5280第4.1.2.5节）
+func (b *Builder) AddTime(t time.Time) {
+	t = t.UTC()
+	if t.Year() >= 1950 && t.Year() < 2050 {
+		b.AddString(TagUTCTime, t.Format(utcTimeLayout))
+	} else {
+		b.AddString(TagGeneralizedTime, t.Format(generalizedTimeLayout))
+	}
+}
+
+// fail 记录第一个错误
+func (b *Builder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// appendLength 追加最短形式的长度
+func appendLength(buf []byte, n int) []byte {
+	if n < 0x80 {
+		return append(buf, byte(n))
+	}
+	var tmp [4]byte
+	i := len(tmp)
+	for ; n > 0; n >>= 8 {
+		i--
+		tmp[i] = byte(n)
+	}
+	buf = append(buf, 0x80|byte(len(tmp)-i))
+	return append(buf, tmp[i:]...)
+}
+
+// appendBase128 追加OID分量的base-128编码，除最后一个字节外最高位为1
+func appendBase128(buf []byte, v uint64) []byte {
+	var tmp [10]byte
+	i := len(tmp) - 1
+	tmp[i] = byte(v & 0x7f)
+	for v >>= 7; v > 0; v >>= 7 {
+		i--
+		tmp[i] = byte(v&0x7f) | 0x80
+	}
+	return append(buf, tmp[i:]...)
+}
+
+// integerBytes 返回整数的最短二进制补码表示
+func integerBytes(v *big.Int) []byte {
+	switch v.Sign() {
+	case 0:
+		return []byte{0}
+	case 1:
+		out := v.Bytes()
+		if out[0]&0x80 != 0 {
+			out = append([]byte{0}, out...)
+		}
+		return out
+	}
+	// 负数：-v-1按位取反即为补码
+	m := new(big.Int).Neg(v)
+	m.Sub(m, big.NewInt(1))
+	out := m.Bytes()
+	for i := range out {
+		out[i] ^= 0xff
+	}
+	if len(out) == 0 || out[0]&0x80 == 0 {
+		out = append([]byte{0xff}, out...)
+	}
+	return out
+}
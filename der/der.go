@@ -0,0 +1,139 @@
+// Package der 实现ASN.1 DER编码的构造与解析
+//
+// 与encoding/asn1基于反射和结构体标签不同，本包按元素逐个读写：Builder依次追加元素，
+// 嵌套结构通过回调构造；Parser依次读取元素，SEQUENCE和上下文标签返回子Parser。
+// 这样可以处理encoding/asn1难以表达的结构，例如按前一个字段决定后续类型的CHOICE、
+// 需要保留原始字节参与签名的字段，以及分量超过int范围的OID。
+//
+// 解析严格遵循DER：长度必须使用最短形式，不接受不定长编码，INTEGER不得有多余的前导字节，
+// BOOLEAN只能是0x00或0xFF。只支持低标签号形式（标签号小于31），这覆盖了密钥、证书和CMS中的全部结构
+package der
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+// Tag 是DER元素的标识字节，包含类别、构造位和标签号
+type Tag byte
+
+// 通用类标签
+const (
+	TagBoolean         Tag = 0x01
+	TagInteger         Tag = 0x02
+	TagBitString       Tag = 0x03
+	TagOctetString     Tag = 0x04
+	TagNull            Tag = 0x05
+	TagOID             Tag = 0x06
+	TagUTF8String      Tag = 0x0c
+	TagPrintableString Tag = 0x13
+	TagIA5String       Tag = 0x16
+	TagUTCTime         Tag = 0x17
+	TagGeneralizedTime Tag = 0x18
+	TagSequence        Tag = 0x30
+	TagSet             Tag = 0x31
+)
+
+// 标识字节中的类别和构造位
+const (
+	classContext Tag = 0x80
+	constructed  Tag = 0x20
+)
+
+// maxLength 是接受的最大内容长度，足以容纳任何证书或密钥
+const maxLength = 1<<31 - 1
+
+// 错误定义
+var (
+	ErrMalformed     = gscerr.New(gscerr.ErrMalformed, "der: malformed encoding")
+	ErrUnexpectedTag = gscerr.New(gscerr.ErrMalformed, "der: unexpected tag")
+	ErrTrailingData  = gscerr.New(gscerr.ErrMalformed, "der: trailing data")
+	ErrOutOfRange    = gscerr.New(gscerr.ErrParameter, "der: value out of range")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrMalformed:     "der: 编码格式错误",
+		ErrUnexpectedTag: "der: 标签不符合预期",
+		ErrTrailingData:  "der: 存在多余数据",
+		ErrOutOfRange:    "der: 值超出范围",
+	})
+}
+
+// Context 返回上下文类标签[n]，constructed为true时用于EXPLICIT标签或嵌套结构，
+// 为false时用于IMPLICIT标记的原始类型
+func Context(n int, isConstructed bool) Tag {
+	t := classContext | Tag(n&0x1f)
+	if isConstructed {
+		t |= constructed
+	}
+	return t
+}
+
+// Constructed 判断标签是否为构造类型
+func (t Tag) Constructed() bool {
+	return t&constructed != 0
+}
+
+// OID 是对象标识符，分量使用uint64以容纳超过int范围的值
+type OID []uint64
+
+// ParseOID 解析点分十进制形式的OID，如"1.2.156.10197.1.301"
+func ParseOID(s string) (OID, error) {
+	parts := strings.Split(s, ".")
+	oid := make(OID, len(parts))
+	for i, p := range parts {
+		v, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return nil, ErrMalformed
+		}
+		oid[i] = v
+	}
+	if !oid.valid() {
+		return nil, ErrOutOfRange
+	}
+	return oid, nil
+}
+
+// Equal 判断两个OID是否相同
+func (oid OID) Equal(other OID) bool {
+	if len(oid) != len(other) {
+		return false
+	}
+	for i := range oid {
+		if oid[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// String 返回点分十进制形式
+func (oid OID) String() string {
+	var sb strings.Builder
+	for i, v := range oid {
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(strconv.FormatUint(v, 10))
+	}
+	return sb.String()
+}
+
+// valid 检查OID至少有两个分量，且前两个分量可以合并编码
+func (oid OID) valid() bool {
+	if len(oid) < 2 || oid[0] > 2 || (oid[0] < 2 && oid[1] >= 40) {
+		return false
+	}
+	return oid[1] <= ^uint64(0)-80
+}
+
+// BitString 是BIT STRING的值，BitLength不是8的倍数时最后一个字节的低位未使用
+type BitString struct {
+	Bytes     []byte
+	BitLength int
+}
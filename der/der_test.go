@@ -0,0 +1,260 @@
+package der
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestIntegerRoundTrip(t *testing.T) {
+	for _, s := range []string{"0", "1", "127", "128", "255", "256", "-1", "-128", "-129", "-256",
+		"115792089210356248762697446949407573529996955224135760342422259061068512044369"} {
+		v, _ := new(big.Int).SetString(s, 10)
+		var b Builder
+		b.AddInteger(v)
+		got, err := b.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 编码与encoding/asn1一致
+		want, _ := asn1.Marshal(v)
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: 编码 %x，期望 %x", s, got, want)
+		}
+		p := NewParser(got)
+		if back, err := p.ReadInteger(); err != nil || back.Cmp(v) != 0 || p.Finish() != nil {
+			t.Errorf("%s: 解析得到 %v, %v", s, back, err)
+		}
+	}
+}
+
+// 测试SM2/ECDSA签名的DER结构 SEQUENCE { r INTEGER, s INTEGER }
+func TestSignature(t *testing.T) {
+	r, _ := new(big.Int).SetString("f5a03b0648d2c4630eeac513e1bb81a15944da3827d5b74143ac7eaceee720b3", 16)
+	s, _ := new(big.Int).SetString("b1b6aa29df212fd8763182bc0d421ca1bb9038fd1f7f42d4840b69c485bbc1aa", 16)
+	var b Builder
+	b.AddSequence(func(b *Builder) {
+		b.AddInteger(r)
+		b.AddInteger(s)
+	})
+	sig, err := b.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	if !bytes.Equal(sig, want) {
+		t.Fatalf("编码 %x，期望 %x", sig, want)
+	}
+
+	seq, err := NewParser(sig).ReadSequence()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotR, _ := seq.ReadInteger()
+	gotS, _ := seq.ReadInteger()
+	if gotR.Cmp(r) != 0 || gotS.Cmp(s) != 0 || seq.Finish() != nil {
+		t.Error("解析结果不一致")
+	}
+}
+
+func TestStructure(t *testing.T) {
+	oid, _ := ParseOID("1.2.156.10197.1.301")
+	notBefore := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	notAfter := time.Date(2051, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var b Builder
+	b.AddSequence(func(b *Builder) {
+		b.AddExplicit(0, func(b *Builder) { b.AddInt64(2) })
+		b.AddOID(oid)
+		b.AddNull()
+		b.AddBoolean(true)
+		b.AddOctetString([]byte{1, 2, 3})
+		b.AddBits(BitString{Bytes: []byte{0xff}, BitLength: 3})
+		b.AddString(TagUTF8String, "国密")
+		b.AddTime(notBefore)
+		b.AddTime(notAfter)
+		b.AddElement(Context(1, false), []byte("implicit"))
+	})
+	data, err := b.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 用encoding/asn1交叉验证
+	var ref struct {
+		Version   int `asn1:"explicit,tag:0"`
+		OID       asn1.ObjectIdentifier
+		Null      asn1.RawValue
+		Bool      bool
+		Octets    []byte
+		Bits      asn1.BitString
+		Text      string `asn1:"utf8"`
+		NotBefore time.Time
+		NotAfter  time.Time `asn1:"generalized"`
+		Implicit  []byte    `asn1:"tag:1"`
+	}
+	if rest, err := asn1.Unmarshal(data, &ref); err != nil || len(rest) > 0 {
+		t.Fatalf("encoding/asn1解析失败: %v", err)
+	}
+	if ref.Version != 2 || ref.OID.String() != oid.String() || !ref.Bool || ref.Bits.BitLength != 3 ||
+		ref.Bits.Bytes[0] != 0xe0 || ref.Text != "国密" || !ref.NotBefore.Equal(notBefore) || !ref.NotAfter.Equal(notAfter) {
+		t.Errorf("encoding/asn1解析结果: %+v", ref)
+	}
+
+	seq, err := NewParser(data).ReadSequence()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, present, _ := seq.ReadExplicit(1); present {
+		t.Error("[1]不应存在")
+	}
+	v, present, err := seq.ReadExplicit(0)
+	if !present || err != nil {
+		t.Fatal(err)
+	}
+	if n, err := v.ReadInt64(); n != 2 || err != nil {
+		t.Errorf("版本 %d, %v", n, err)
+	}
+	if got, err := seq.ReadOID(); !got.Equal(oid) || err != nil {
+		t.Errorf("OID %v, %v", got, err)
+	}
+	if err := seq.ReadNull(); err != nil {
+		t.Error(err)
+	}
+	if got, err := seq.ReadBoolean(); !got || err != nil {
+		t.Errorf("BOOLEAN %v, %v", got, err)
+	}
+	if got, err := seq.ReadOctetString(); !bytes.Equal(got, []byte{1, 2, 3}) || err != nil {
+		t.Errorf("OCTET STRING %x, %v", got, err)
+	}
+	if got, err := seq.ReadBits(); got.BitLength != 3 || got.Bytes[0] != 0xe0 || err != nil {
+		t.Errorf("BIT STRING %+v, %v", got, err)
+	}
+	if got, err := seq.ReadString(TagUTF8String); got != "国密" || err != nil {
+		t.Errorf("UTF8String %q, %v", got, err)
+	}
+	for _, want := range []time.Time{notBefore, notAfter} {
+		if got, err := seq.ReadTime(); !got.Equal(want) || err != nil {
+			t.Errorf("时间 %v, %v", got, err)
+		}
+	}
+	if got, present, err := seq.ReadOptional(Context(1, false)); !present || string(got) != "implicit" || err != nil {
+		t.Errorf("[1] IMPLICIT %q, %v", got, err)
+	}
+	if err := seq.Finish(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOID(t *testing.T) {
+	tests := []struct {
+		oid string
+		der string
+	}{
+		{"1.2.840.10045.2.1", "06072a8648ce3d0201"},
+		{"2.999.3", "0603883703"},
+		// 分量超过int32，encoding/asn1在32位平台上无法表示
+		{"1.2.18446744073709551615", "060b2a81ffffffffffffffff7f"},
+	}
+	for _, tt := range tests {
+		oid, err := ParseOID(tt.oid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var b Builder
+		b.AddOID(oid)
+		got, _ := b.Bytes()
+		if hex.EncodeToString(got) != tt.der {
+			t.Errorf("%s: 编码 %x，期望 %s", tt.oid, got, tt.der)
+		}
+		back, err := NewParser(got).ReadOID()
+		if err != nil || back.String() != tt.oid {
+			t.Errorf("%s: 解析得到 %v, %v", tt.oid, back, err)
+		}
+	}
+
+	for _, s := range []string{"", "1", "3.1", "1.40", "1.a"} {
+		if _, err := ParseOID(s); err == nil {
+			t.Errorf("%q: 应当失败", s)
+		}
+	}
+}
+
+func TestLongLength(t *testing.T) {
+	for _, n := range []int{127, 128, 255, 256, 70000} {
+		var b Builder
+		b.AddOctetString(make([]byte, n))
+		data, _ := b.Bytes()
+		want, _ := asn1.Marshal(make([]byte, n))
+		if !bytes.Equal(data, want) {
+			t.Errorf("长度%d: 编码头部 %x，期望 %x", n, data[:5], want[:5])
+		}
+		if got, err := NewParser(data).ReadOctetString(); len(got) != n || err != nil {
+			t.Errorf("长度%d: %v", n, err)
+		}
+	}
+}
+
+// 测试拒绝非DER编码
+func TestStrict(t *testing.T) {
+	tests := []struct {
+		name string
+		der  string
+		read func(*Parser) error
+		want error
+	}{
+		{"不定长", "30800000", seqErr, ErrMalformed},
+		{"长度非最短", "04810100", octetErr, ErrMalformed},
+		{"长度前导零", "0482000100", octetErr, ErrMalformed},
+		{"长度超出数据", "040301", octetErr, ErrMalformed},
+		{"高标签号", "1f2100", func(p *Parser) error { _, _, _, err := p.ReadElement(); return err }, ErrMalformed},
+		{"INTEGER前导零", "02020001", intErr, ErrMalformed},
+		{"INTEGER前导FF", "0202ff80", intErr, ErrMalformed},
+		{"空INTEGER", "0200", intErr, ErrMalformed},
+		{"BOOLEAN非FF", "010101", func(p *Parser) error { _, err := p.ReadBoolean(); return err }, ErrMalformed},
+		{"NULL有内容", "050100", func(p *Parser) error { return p.ReadNull() }, ErrMalformed},
+		{"BIT STRING未使用位非零", "03020701", func(p *Parser) error { _, err := p.ReadBits(); return err }, ErrMalformed},
+		{"BIT STRING未对齐", "03020780", func(p *Parser) error { _, err := p.ReadBitString(); return err }, ErrMalformed},
+		{"OID非最短", "0603808001", func(p *Parser) error { _, err := p.ReadOID(); return err }, ErrMalformed},
+		{"OID截断", "060281", func(p *Parser) error { _, err := p.ReadOID(); return err }, ErrMalformed},
+		{"UTCTime非UTC", "170d3234303130323033303430352b", func(p *Parser) error { _, err := p.ReadTime(); return err }, ErrMalformed},
+		{"标签不符", "0400", intErr, ErrUnexpectedTag},
+	}
+	for _, tt := range tests {
+		data, _ := hex.DecodeString(tt.der)
+		if err := tt.read(NewParser(data)); !errors.Is(err, tt.want) {
+			t.Errorf("%s: 期望%v，实际%v", tt.name, tt.want, err)
+		}
+	}
+
+	p := NewParser([]byte{0x05, 0x00, 0x05, 0x00})
+	p.ReadNull()
+	if err := p.Finish(); !errors.Is(err, ErrTrailingData) {
+		t.Errorf("多余数据: %v", err)
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	var b Builder
+	b.AddSequence(func(b *Builder) {
+		b.AddOID(OID{3, 1})
+	})
+	b.AddNull()
+	if _, err := b.Bytes(); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("无效OID: %v", err)
+	}
+
+	b = Builder{}
+	b.AddBits(BitString{Bytes: []byte{1}, BitLength: 9})
+	if _, err := b.Bytes(); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("位长超出: %v", err)
+	}
+}
+
+func seqErr(p *Parser) error   { _, err := p.ReadSequence(); return err }
+func octetErr(p *Parser) error { _, err := p.ReadOctetString(); return err }
+func intErr(p *Parser) error   { _, err := p.ReadInteger(); return err }
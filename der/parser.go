@@ -0,0 +1,305 @@
+package der
+
+import (
+	"math/big"
+	"time"
+)
+
+// 时间格式，DER要求使用UTC（Z结尾）且不含小数秒
+const (
+	utcTimeLayout         = "060102150405Z"
+	generalizedTimeLayout = "20060102150405Z"
+)
+
+// Parser 依次读取DER元素
+type Parser struct {
+	data []byte
+}
+
+// NewParser 返回读取data的Parser
+func NewParser(data []byte) *Parser {
+	return &Parser{data: data}
+}
+
+// Empty 判断是否已读取全部元素
+func (p *Parser) Empty() bool {
+	return len(p.data) == 0
+}
+
+// Finish 检查已读取全部元素，否则返回ErrTrailingData
+func (p *Parser) Finish() error {
+	if !p.Empty() {
+		return ErrTrailingData
+	}
+	return nil
+}
+
+// PeekTag 返回下一个元素的标签而不读取它，没有更多元素时ok为false
+func (p *Parser) PeekTag() (tag Tag, ok bool) {
+	if p.Empty() {
+		return 0, false
+	}
+	return Tag(p.data[0]), true
+}
+
+// ReadElement 读取下一个元素，返回其标签、内容和包含标签与长度的完整编码
+func (p *Parser) ReadElement() (tag Tag, content, raw []byte, err error) {
+	if len(p.data) < 2 {
+		return 0, nil, nil, ErrMalformed
+	}
+	tag = Tag(p.data[0])
+	if tag&0x1f == 0x1f {
+		// 高标签号形式
+		return 0, nil, nil, ErrMalformed
+	}
+	n, header := int(p.data[1]), 2
+	if n&0x80 != 0 {
+		size := n & 0x7f
+		// 0x80为不定长，BER允许而DER不允许
+		if size == 0 || size > 4 || len(p.data) < 2+size {
+			return 0, nil, nil, ErrMalformed
+		}
+		n = 0
+		for _, c := range p.data[2 : 2+size] {
+			n = n<<8 | int(c)
+		}
+		// 长度必须使用最短形式
+		if p.data[2] == 0 || n < 0x80 || n > maxLength {
+			return 0, nil, nil, ErrMalformed
+		}
+		header += size
+	}
+	if n > len(p.data)-header {
+		return 0, nil, nil, ErrMalformed
+	}
+	raw = p.data[:header+n]
+	content = raw[header:]
+	p.data = p.data[header+n:]
+	return tag, content, raw, nil
+}
+
+// Read 读取下一个元素的内容，要求其标签为tag
+func (p *Parser) Read(tag Tag) ([]byte, error) {
+	if t, ok := p.PeekTag(); ok && t != tag {
+		return nil, ErrUnexpectedTag
+	}
+	_, content, _, err := p.ReadElement()
+	return content, err
+}
+
+// ReadOptional 在下一个元素的标签为tag时读取其内容，否则不读取并返回present为false
+func (p *Parser) ReadOptional(tag Tag) (content []byte, present bool, err error) {
+	if t, ok := p.PeekTag(); !ok || t != tag {
+		return nil, false, nil
+	}
+	content, err = p.Read(tag)
+	return content, err == nil, err
+}
+
+// ReadRaw 读取下一个元素并返回其完整编码，要求其标签为tag
+func (p *Parser) ReadRaw(tag Tag) ([]byte, error) {
+	if t, ok := p.PeekTag(); ok && t != tag {
+		return nil, ErrUnexpectedTag
+	}
+	_, _, raw, err := p.ReadElement()
+	return raw, err
+}
+
+// ReadSequence 读取SEQUENCE，返回其内容的Parser
+func (p *Parser) ReadSequence() (*Parser, error) {
+	return p.readConstructed(TagSequence)
+}
+
+// ReadSet 读取SET，返回其内容的Parser
+func (p *Parser) ReadSet() (*Parser, error) {
+	return p.readConstructed(TagSet)
+}
+
+// ReadExplicit 在下一个元素为EXPLICIT上下文标签[n]时读取它，返回其内容的Parser；
+// 标签不匹配时不读取并返回present为false，用于OPTIONAL和DEFAULT字段
+func (p *Parser) ReadExplicit(n int) (inner *Parser, present bool, err error) {
+	content, present, err := p.ReadOptional(Context(n, true))
+	if !present {
+		return nil, false, err
+	}
+	return NewParser(content), true, nil
+}
+
+// ReadInteger 读取INTEGER
+func (p *Parser) ReadInteger() (*big.Int, error) {
+	content, err := p.Read(TagInteger)
+	if err != nil {
+		return nil, err
+	}
+	if !validInteger(content) {
+		return nil, ErrMalformed
+	}
+	v := new(big.Int).SetBytes(content)
+	if content[0]&0x80 != 0 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(8*len(content))))
+	}
+	return v, nil
+}
+
+// ReadInt64 读取INTEGER，值超出int64范围时返回ErrOutOfRange
+func (p *Parser) ReadInt64() (int64, error) {
+	v, err := p.ReadInteger()
+	if err != nil {
+		return 0, err
+	}
+	if !v.IsInt64() {
+		return 0, ErrOutOfRange
+	}
+	return v.Int64(), nil
+}
+
+// ReadBoolean 读取BOOLEAN
+func (p *Parser) ReadBoolean() (bool, error) {
+	content, err := p.Read(TagBoolean)
+	if err != nil {
+		return false, err
+	}
+	if len(content) != 1 || (content[0] != 0 && content[0] != 0xff) {
+		return false, ErrMalformed
+	}
+	return content[0] == 0xff, nil
+}
+
+// ReadNull 读取NULL
+func (p *Parser) ReadNull() error {
+	content, err := p.Read(TagNull)
+	if err == nil && len(content) != 0 {
+		return ErrMalformed
+	}
+	return err
+}
+
+// ReadOctetString 读取OCTET STRING
+func (p *Parser) ReadOctetString() ([]byte, error) {
+	return p.Read(TagOctetString)
+}
+
+// ReadBits 读取BIT STRING
+func (p *Parser) ReadBits() (BitString, error) {
+	content, err := p.Read(TagBitString)
+	if err != nil {
+		return BitString{}, err
+	}
+	if len(content) == 0 || content[0] > 7 || (len(content) == 1 && content[0] != 0) {
+		return BitString{}, ErrMalformed
+	}
+	unused := int(content[0])
+	// DER要求未使用的位为0
+	if content[len(content)-1]&(1<<unused-1) != 0 {
+		return BitString{}, ErrMalformed
+	}
+	return BitString{Bytes: content[1:], BitLength: 8*(len(content)-1) - unused}, nil
+}
+
+// ReadBitString 读取字节对齐的BIT STRING，有未使用的位时返回ErrMalformed
+func (p *Parser) ReadBitString() ([]byte, error) {
+	bits, err := p.ReadBits()
+	if err != nil {
+		return nil, err
+	}
+	if bits.BitLength%8 != 0 {
+		return nil, ErrMalformed
+	}
+	return bits.Bytes, nil
+}
+
+// ReadOID 读取OBJECT IDENTIFIER
+func (p *Parser) ReadOID() (OID, error) {
+	content, err := p.Read(TagOID)
+	if err != nil {
+		return nil, err
+	}
+	var oid OID
+	for len(content) > 0 {
+		var v uint64
+		i := 0
+		for {
+			if i == len(content) || (i == 0 && content[0] == 0x80) || v > ^uint64(0)>>7 {
+				// 截断、非最短编码或溢出
+				return nil, ErrMalformed
+			}
+			c := content[i]
+			v = v<<7 | uint64(c&0x7f)
+			i++
+			if c&0x80 == 0 {
+				break
+			}
+		}
+		content = content[i:]
+		if oid == nil {
+			// 第一个编码值合并了前两个分量
+			switch {
+			case v < 40:
+				oid = OID{0, v}
+			case v < 80:
+				oid = OID{1, v - 40}
+			default:
+				oid = OID{2, v - 80}
+			}
+			continue
+		}
+		oid = append(oid, v)
+	}
+	if oid == nil {
+		return nil, ErrMalformed
+	}
+	return oid, nil
+}
+
+// ReadString 读取tag类型的字符串
+func (p *Parser) ReadString(tag Tag) (string, error) {
+	content, err := p.Read(tag)
+	return string(content), err
+}
+
+// ReadTime 读取UTCTime或GeneralizedTime
+func (p *Parser) ReadTime() (time.Time, error) {
+	tag, _ := p.PeekTag()
+	var layout string
+	switch tag {
+	case TagUTCTime:
+		layout = utcTimeLayout
+	case TagGeneralizedTime:
+		layout = generalizedTimeLayout
+	default:
+		return time.Time{}, ErrUnexpectedTag
+	}
+	s, err := p.ReadString(tag)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil || t.Format(layout) != s {
+		return time.Time{}, ErrMalformed
+	}
+	// UTCTime的两位年份：50-99为19xx，00-49为20xx
+	if tag == TagUTCTime && t.Year() >= 2050 {
+		t = t.AddDate(-100, 0, 0)
+	}
+	return t, nil
+}
+
+// readConstructed 读取构造类型的元素，返回其内容的Parser
+func (p *Parser) readConstructed(tag Tag) (*Parser, error) {
+	content, err := p.Read(tag)
+	if err != nil {
+		return nil, err
+	}
+	return NewParser(content), nil
+}
+
+// validInteger 检查INTEGER的内容非空且为最短编码
+func validInteger(content []byte) bool {
+	if len(content) == 0 {
+		return false
+	}
+	if len(content) > 1 && ((content[0] == 0 && content[1]&0x80 == 0) || (content[0] == 0xff && content[1]&0x80 != 0)) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,24 @@
+package des
+
+// EncryptBlocks 加密src中的多个块并写入dst，dst与src可以是同一切片
+// src长度必须是8的整数倍且dst不短于src，否则返回ErrInvalidBlockSize
+func (d *DES) EncryptBlocks(dst, src []byte) error {
+	if len(src)%BlockSize != 0 || len(dst) < len(src) {
+		return ErrInvalidBlockSize
+	}
+	for i := 0; i < len(src); i += BlockSize {
+		d.encryptBlock(dst[i:i+BlockSize], src[i:i+BlockSize])
+	}
+	return nil
+}
+
+// DecryptBlocks 解密src中的多个块并写入dst，约定与EncryptBlocks相同
+func (d *DES) DecryptBlocks(dst, src []byte) error {
+	if len(src)%BlockSize != 0 || len(dst) < len(src) {
+		return ErrInvalidBlockSize
+	}
+	for i := 0; i < len(src); i += BlockSize {
+		d.decryptBlock(dst[i:i+BlockSize], src[i:i+BlockSize])
+	}
+	return nil
+}
@@ -1,9 +1,10 @@
 package des
 
 import (
-	"errors"
-
 	"github.com/laenix/gsc/des/internal"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/secure"
 )
 
 const (
@@ -13,7 +14,7 @@ const (
 	KeySize = 8
 )
 
-// DES 结构体包含加密和解密所需的轮密钥
+// DES 结构体包含加密和解密所需的轮密钥，创建后只读，可并发使用
 type DES struct {
 	// 解密和加密的16轮子密钥
 	roundKeys [16]uint64
@@ -21,15 +22,25 @@ type DES struct {
 
 // 错误定义
 var (
-	ErrInvalidKeySize   = errors.New("des: 密钥必须是8字节（64位）")
-	ErrInvalidBlockSize = errors.New("des: 数据块必须是8字节（64位）")
+	ErrInvalidKeySize       = gscerr.New(gscerr.ErrKeySize, "des: key must be 8 bytes (64 bits)")
+	ErrInvalidBlockSize     = gscerr.New(gscerr.ErrBlockSize, "des: block must be 8 bytes (64 bits)")
+	ErrInvalidTripleKeySize = gscerr.New(gscerr.ErrKeySize, "des: 3DES key must be 8, 16 or 24 bytes")
 )
 
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidKeySize:       "des: 密钥必须是8字节（64位）",
+		ErrInvalidBlockSize:     "des: 数据块必须是8字节（64位）",
+		ErrInvalidTripleKeySize: "des: 3DES密钥必须是8、16或24字节",
+	})
+}
+
 // New 创建一个新的DES实例
 func New(key []byte) (*DES, error) {
 	// 验证密钥长度
 	if len(key) != KeySize {
-		return nil, ErrInvalidKeySize
+		return nil, gscerr.KeySize(ErrInvalidKeySize, "DES", len(key), KeySize)
 	}
 
 	// 创建DES实例
@@ -41,19 +52,35 @@ func New(key []byte) (*DES, error) {
 	return des, nil
 }
 
+// KeySize 返回密钥长度（字节），DES固定为8（含8个奇偶校验位）
+func (d *DES) KeySize() int {
+	return KeySize
+}
+
 // BlockSize 返回区块大小
 func (d *DES) BlockSize() int {
 	return BlockSize
 }
 
+// Wipe 清零轮密钥，之后实例不能再使用
+func (d *DES) Wipe() {
+	secure.Wipe(d.roundKeys[:])
+}
+
 // Encrypt 加密单个区块（8字节）
 func (d *DES) Encrypt(block []byte) ([]byte, error) {
 	if len(block) != BlockSize {
 		return nil, ErrInvalidBlockSize
 	}
+	result := make([]byte, BlockSize)
+	d.encryptBlock(result, block)
+	return result, nil
+}
 
+// encryptBlock 加密一个块，dst与src可以是同一切片
+func (d *DES) encryptBlock(dst, src []byte) {
 	// 将8字节转换为64位整数
-	input := bytesToUint64(block)
+	input := bytesToUint64(src)
 
 	// 初始置换 (IP)
 	state := initialPermutation(input)
@@ -72,10 +99,7 @@ func (d *DES) Encrypt(block []byte) ([]byte, error) {
 	output := finalPermutation(state)
 
 	// 将64位整数转换回8字节
-	result := make([]byte, BlockSize)
-	uint64ToBytes(output, result)
-
-	return result, nil
+	uint64ToBytes(output, dst)
 }
 
 // Decrypt 解密单个区块（8字节）
@@ -83,9 +107,15 @@ func (d *DES) Decrypt(block []byte) ([]byte, error) {
 	if len(block) != BlockSize {
 		return nil, ErrInvalidBlockSize
 	}
+	result := make([]byte, BlockSize)
+	d.decryptBlock(result, block)
+	return result, nil
+}
 
+// decryptBlock 解密一个块，dst与src可以是同一切片
+func (d *DES) decryptBlock(dst, src []byte) {
 	// 将8字节转换为64位整数
-	input := bytesToUint64(block)
+	input := bytesToUint64(src)
 
 	// 初始置换 (IP)
 	state := initialPermutation(input)
@@ -104,10 +134,7 @@ func (d *DES) Decrypt(block []byte) ([]byte, error) {
 	output := finalPermutation(state)
 
 	// 将64位整数转换回8字节
-	result := make([]byte, BlockSize)
-	uint64ToBytes(output, result)
-
-	return result, nil
+	uint64ToBytes(output, dst)
 }
 
 // generateRoundKeys 从初始密钥生成16轮密钥
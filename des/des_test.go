@@ -0,0 +1,93 @@
+package des
+
+import (
+	"bytes"
+	"crypto/des"
+	"encoding/hex"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/laenix/gsc/des/internal"
+)
+
+// 测试经典的DES示例向量（密钥133457799BBCDFF1），以及随机密钥和明文与标准库crypto/des的结果一致，
+// 后者可覆盖S盒和各置换表中的绝大多数表项
+func TestKnownAnswer(t *testing.T) {
+	key, _ := hex.DecodeString("133457799bbcdff1")
+	src, _ := hex.DecodeString("0123456789abcdef")
+	want, _ := hex.DecodeString("85e813540f0ab405")
+
+	c, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := c.Encrypt(src)
+	if !bytes.Equal(got, want) {
+		t.Fatalf("密文错误: %x", got)
+	}
+	if back, _ := c.Decrypt(got); !bytes.Equal(back, src) {
+		t.Fatalf("解密错误: %x", back)
+	}
+
+	r := rand.NewChaCha8([32]byte{'d', 'e', 's'})
+	key = make([]byte, KeySize)
+	block := make([]byte, BlockSize)
+	stdOut := make([]byte, BlockSize)
+	for range 1000 {
+		r.Read(key)
+		r.Read(block)
+		c, _ := New(key)
+		std, _ := des.NewCipher(key)
+		got, _ := c.Encrypt(block)
+		std.Encrypt(stdOut, block)
+		if !bytes.Equal(got, stdOut) {
+			t.Fatalf("密钥%x明文%x: 结果与标准库不一致", key, block)
+		}
+	}
+}
+
+// 测试常量表与FIPS 46-3一致：FP是IP的逆置换，S盒每行是0-15的置换，各表首项与标准相同
+func TestStandardTables(t *testing.T) {
+	for i, p := range internal.IP {
+		if internal.FP[p-1] != byte(i+1) {
+			t.Fatalf("FP不是IP的逆置换，位置%d", i)
+		}
+	}
+	for n, box := range internal.SBOXES {
+		for row := range 4 {
+			var seen [16]bool
+			for _, v := range box[row*16 : row*16+16] {
+				seen[v&15] = v < 16
+			}
+			for v, ok := range seen {
+				if !ok {
+					t.Fatalf("S%d第%d行缺少%d", n+1, row, v)
+				}
+			}
+		}
+	}
+	first := []struct {
+		name string
+		got  byte
+		want byte
+	}{
+		{"IP", internal.IP[0], 58}, {"FP", internal.FP[0], 40}, {"E", internal.E[0], 32},
+		{"P", internal.P[0], 16}, {"PC1", internal.PC1[0], 57}, {"PC2", internal.PC2[0], 14},
+		{"S1", internal.SBOXES[0][0], 14}, {"S8", internal.SBOXES[7][63], 11},
+	}
+	for _, f := range first {
+		if f.got != f.want {
+			t.Errorf("%s首项为%d，期望%d", f.name, f.got, f.want)
+		}
+	}
+
+	// 导出的副本与内部常量一致，修改副本不影响内部
+	tables := StandardTables()
+	if tables.IP != internal.IP || tables.SBoxes != internal.SBOXES {
+		t.Fatal("StandardTables与内部常量不一致")
+	}
+	tables.SBoxes[0][0] = 0
+	if StandardTables().SBoxes[0][0] != 14 {
+		t.Fatal("修改副本影响了内部S盒")
+	}
+}
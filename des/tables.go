@@ -0,0 +1,27 @@
+package des
+
+import "github.com/laenix/gsc/des/internal"
+
+// Tables 是DES标准常量表的只读副本，供分析和教学工具使用
+type Tables struct {
+	IP     [64]byte    // 初始置换
+	FP     [64]byte    // 最终置换 (IP^-1)
+	E      [48]byte    // 扩展置换
+	P      [32]byte    // P-box置换
+	PC1    [56]byte    // 密钥置换1
+	PC2    [48]byte    // 密钥置换2
+	SBoxes [8][64]byte // S1-S8
+}
+
+// StandardTables 返回DES标准常量表的副本，修改返回值不会影响加密实现
+func StandardTables() Tables {
+	return Tables{
+		IP:     internal.IP,
+		FP:     internal.FP,
+		E:      internal.E,
+		P:      internal.P,
+		PC1:    internal.PC1,
+		PC2:    internal.PC2,
+		SBoxes: internal.SBOXES,
+	}
+}
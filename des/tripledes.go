@@ -0,0 +1,108 @@
+package des
+
+import "github.com/laenix/gsc/gscerr"
+
+// TripleKeySize 是三密钥3DES的密钥长度（字节）
+const TripleKeySize = 3 * KeySize
+
+// TripleDES 是EDE方式的三重DES：C = E_K3(D_K2(E_K1(P)))，P = D_K1(E_K2(D_K3(C)))
+type TripleDES struct {
+	k1, k2, k3 DES
+	keySize    int
+}
+
+// NewTripleDES 创建一个新的3DES实例，密钥长度决定密钥选项（NIST SP 800-67）：
+// 24字节为三密钥K1||K2||K3；16字节为双密钥K1||K2，此时K3 = K1；
+// 8字节时三个子密钥相同，结果等同于单DES，仅用于与旧系统互通
+func NewTripleDES(key []byte) (*TripleDES, error) {
+	var k1, k2, k3 []byte
+	switch len(key) {
+	case KeySize:
+		k1, k2, k3 = key, key, key
+	case 2 * KeySize:
+		k1, k2, k3 = key[:8], key[8:], key[:8]
+	case TripleKeySize:
+		k1, k2, k3 = key[:8], key[8:16], key[16:]
+	default:
+		return nil, gscerr.KeySize(ErrInvalidTripleKeySize, "3DES", len(key), KeySize, 2*KeySize, TripleKeySize)
+	}
+
+	t := &TripleDES{keySize: len(key)}
+	t.k1.generateRoundKeys(k1)
+	t.k2.generateRoundKeys(k2)
+	t.k3.generateRoundKeys(k3)
+	return t, nil
+}
+
+// KeySize 返回创建实例时使用的密钥长度（字节）
+func (t *TripleDES) KeySize() int {
+	return t.keySize
+}
+
+// BlockSize 返回区块大小
+func (t *TripleDES) BlockSize() int {
+	return BlockSize
+}
+
+// Wipe 清零三个子密钥的轮密钥，之后实例不能再使用
+func (t *TripleDES) Wipe() {
+	t.k1.Wipe()
+	t.k2.Wipe()
+	t.k3.Wipe()
+}
+
+// Encrypt 加密单个区块（8字节）
+func (t *TripleDES) Encrypt(block []byte) ([]byte, error) {
+	if len(block) != BlockSize {
+		return nil, ErrInvalidBlockSize
+	}
+	result := make([]byte, BlockSize)
+	t.encryptBlock(result, block)
+	return result, nil
+}
+
+// Decrypt 解密单个区块（8字节）
+func (t *TripleDES) Decrypt(block []byte) ([]byte, error) {
+	if len(block) != BlockSize {
+		return nil, ErrInvalidBlockSize
+	}
+	result := make([]byte, BlockSize)
+	t.decryptBlock(result, block)
+	return result, nil
+}
+
+// EncryptBlocks 加密src中的多个块并写入dst，约定与DES.EncryptBlocks相同
+func (t *TripleDES) EncryptBlocks(dst, src []byte) error {
+	if len(src)%BlockSize != 0 || len(dst) < len(src) {
+		return ErrInvalidBlockSize
+	}
+	for i := 0; i < len(src); i += BlockSize {
+		t.encryptBlock(dst[i:i+BlockSize], src[i:i+BlockSize])
+	}
+	return nil
+}
+
+// DecryptBlocks 解密src中的多个块并写入dst，约定与DES.EncryptBlocks相同
+func (t *TripleDES) DecryptBlocks(dst, src []byte) error {
+	if len(src)%BlockSize != 0 || len(dst) < len(src) {
+		return ErrInvalidBlockSize
+	}
+	for i := 0; i < len(src); i += BlockSize {
+		t.decryptBlock(dst[i:i+BlockSize], src[i:i+BlockSize])
+	}
+	return nil
+}
+
+// encryptBlock 加密一个块，dst与src可以是同一切片
+func (t *TripleDES) encryptBlock(dst, src []byte) {
+	t.k1.encryptBlock(dst, src)
+	t.k2.decryptBlock(dst, dst)
+	t.k3.encryptBlock(dst, dst)
+}
+
+// decryptBlock 解密一个块，dst与src可以是同一切片
+func (t *TripleDES) decryptBlock(dst, src []byte) {
+	t.k3.decryptBlock(dst, src)
+	t.k2.encryptBlock(dst, dst)
+	t.k1.decryptBlock(dst, dst)
+}
@@ -0,0 +1,76 @@
+package des
+
+import (
+	"bytes"
+	"crypto/des"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/laenix/gsc/gscerr"
+)
+
+// 测试三种密钥选项的结果与标准库crypto/des一致
+func TestTripleDES(t *testing.T) {
+	key := []byte("0123456789abcdefFEDCBA98")
+	src := []byte("3DES EDE test vector 16B")
+
+	for _, tt := range []struct {
+		name string
+		key  []byte
+		std  []byte
+	}{
+		{"三密钥", key, key},
+		{"双密钥", key[:16], append(bytes.Clone(key[:16]), key[:8]...)},
+		{"单密钥", key[:8], bytes.Repeat(key[:8], 3)},
+	} {
+		c, err := NewTripleDES(tt.key)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		std, err := des.NewTripleDESCipher(tt.std)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := make([]byte, len(src))
+		if err := c.EncryptBlocks(got, src); err != nil {
+			t.Fatal(err)
+		}
+		want := make([]byte, len(src))
+		for i := 0; i < len(src); i += BlockSize {
+			std.Encrypt(want[i:], src[i:i+BlockSize])
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("%s: 加密结果与标准库不一致", tt.name)
+		}
+
+		block, _ := c.Decrypt(got[:BlockSize])
+		if !bytes.Equal(block, src[:BlockSize]) {
+			t.Fatalf("%s: 解密未能还原明文", tt.name)
+		}
+		if err := c.DecryptBlocks(got, got); err != nil || !bytes.Equal(got, src) {
+			t.Fatalf("%s: 批量解密未能还原明文", tt.name)
+		}
+	}
+
+	_, err := NewTripleDES(key[:12])
+	if !errors.Is(err, ErrInvalidTripleKeySize) || !errors.Is(err, gscerr.ErrKeySize) {
+		t.Fatalf("期望ErrInvalidTripleKeySize，实际: %v", err)
+	}
+	var kse *gscerr.KeySizeError
+	if !errors.As(err, &kse) || kse.Alg != "3DES" || kse.Got != 12 || !slices.Equal(kse.Want, []int{8, 16, 24}) {
+		t.Fatalf("KeySizeError的字段不正确: %+v", kse)
+	}
+}
+
+// 测试Wipe清零三个子密钥的轮密钥
+func TestTripleDESWipe(t *testing.T) {
+	c, _ := NewTripleDES([]byte("0123456789abcdefFEDCBA98"))
+	c.Wipe()
+	for _, d := range []DES{c.k1, c.k2, c.k3} {
+		if d.roundKeys != [16]uint64{} {
+			t.Fatal("Wipe后轮密钥应全为0")
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package drbg
+
+import (
+	"encoding/binary"
+
+	"github.com/laenix/gsc/aes"
+	"github.com/laenix/gsc/secure"
+)
+
+// ctrDRBG 是SP 800-90A 10.2中使用派生函数的CTR_DRBG，分组密码为AES
+type ctrDRBG struct {
+	keySize int
+	block   *aes.AES
+	v       [aes.BlockSize]byte
+}
+
+// NewCTR 返回密钥长度为keySize字节（16、24或32）的AES CTR_DRBG，opts为nil时使用默认配置
+// 使用派生函数（Block_Cipher_df），安全强度等于AES密钥长度
+func NewCTR(keySize int, opts *Options) (*DRBG, error) {
+	switch keySize {
+	case 16, 24, 32:
+	default:
+		return nil, ErrInvalidKeySize
+	}
+	return newDRBG(&ctrDRBG{keySize: keySize}, opts)
+}
+
+func (m *ctrDRBG) strength() int {
+	return m.keySize
+}
+
+// seedLen 是种子长度：密钥长度加一个分组
+func (m *ctrDRBG) seedLen() int {
+	return m.keySize + aes.BlockSize
+}
+
+// setKey 替换当前的AES实例并清零旧实例的轮密钥
+func (m *ctrDRBG) setKey(key []byte) {
+	if m.block != nil {
+		m.block.Wipe()
+	}
+	// key长度已由NewCTR校验
+	m.block, _ = aes.New(key)
+}
+
+// keystream 是CTR_DRBG_Update和生成过程共用的计数器模式：先将V加1，再加密V
+func (m *ctrDRBG) keystream(out []byte) {
+	var block [aes.BlockSize]byte
+	for n := 0; n < len(out); n += aes.BlockSize {
+		// SP 800-90A规定V按整个分组长度作为大端整数递增
+		lo := binary.BigEndian.Uint64(m.v[8:]) + 1
+		binary.BigEndian.PutUint64(m.v[8:], lo)
+		if lo == 0 {
+			binary.BigEndian.PutUint64(m.v[:8], binary.BigEndian.Uint64(m.v[:8])+1)
+		}
+		m.block.EncryptBlocks(block[:], m.v[:])
+		copy(out[n:], block[:])
+	}
+}
+
+// update 是CTR_DRBG_Update：生成seedlen字节并与provided异或，前keylen字节作为新密钥，其余作为新V
+// provided为nil时视为全0
+func (m *ctrDRBG) update(provided []byte) {
+	temp := make([]byte, m.seedLen())
+	m.keystream(temp)
+	for i := range provided {
+		temp[i] ^= provided[i]
+	}
+	m.setKey(temp[:m.keySize])
+	copy(m.v[:], temp[m.keySize:])
+	secure.Wipe(temp)
+}
+
+func (m *ctrDRBG) instantiate(entropy, nonce, personalization []byte) {
+	seed := m.df(entropy, nonce, personalization)
+	m.setKey(make([]byte, m.keySize))
+	clear(m.v[:])
+	m.update(seed)
+	secure.Wipe(seed)
+}
+
+func (m *ctrDRBG) reseed(entropy, additional []byte) {
+	seed := m.df(entropy, additional)
+	m.update(seed)
+	secure.Wipe(seed)
+}
+
+func (m *ctrDRBG) generate(out, additional []byte) {
+	var seed []byte
+	if len(additional) > 0 {
+		seed = m.df(additional)
+		m.update(seed)
+	}
+	m.keystream(out)
+	// 附加输入为空时seed为nil，update按全0处理
+	m.update(seed)
+	secure.Wipe(seed)
+}
+
+// df 是Block_Cipher_df，把任意长度的输入压缩为seedlen字节
+func (m *ctrDRBG) df(inputs ...[]byte) []byte {
+	l := 0
+	for _, in := range inputs {
+		l += len(in)
+	}
+	seedLen := m.seedLen()
+
+	// S = L || N || input || 0x80，用0补齐到分组长度的整数倍，前面再留出一个分组给计数器IV
+	s := make([]byte, aes.BlockSize, aes.BlockSize+8+l+1+aes.BlockSize)
+	s = binary.BigEndian.AppendUint32(s, uint32(l))
+	s = binary.BigEndian.AppendUint32(s, uint32(seedLen))
+	for _, in := range inputs {
+		s = append(s, in...)
+	}
+	s = append(s, 0x80)
+	for len(s)%aes.BlockSize != 0 {
+		s = append(s, 0)
+	}
+
+	// 以K = 00 01 02 ...为密钥，用BCC为每个计数器值计算一个分组
+	key := make([]byte, m.keySize)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	block, _ := aes.New(key)
+	temp := make([]byte, 0, seedLen+aes.BlockSize)
+	for i := uint32(0); len(temp) < seedLen; i++ {
+		binary.BigEndian.PutUint32(s, i)
+		temp = append(temp, bcc(block, s)...)
+	}
+	block.Wipe()
+	secure.Wipe(s)
+
+	// 以temp的前keylen字节为密钥、随后一个分组为X，迭代加密X得到输出
+	block, _ = aes.New(temp[:m.keySize])
+	x := temp[m.keySize : m.keySize+aes.BlockSize]
+	out := make([]byte, 0, seedLen+aes.BlockSize)
+	for len(out) < seedLen {
+		block.EncryptBlocks(x, x)
+		out = append(out, x...)
+	}
+	block.Wipe()
+	secure.Wipe(temp)
+	return out[:seedLen]
+}
+
+// bcc 是CBC-MAC形式的BCC函数，data长度必须是分组长度的整数倍
+func bcc(block *aes.AES, data []byte) []byte {
+	chain := make([]byte, aes.BlockSize)
+	for i := 0; i < len(data); i += aes.BlockSize {
+		for j := range chain {
+			chain[j] ^= data[i+j]
+		}
+		block.EncryptBlocks(chain, chain)
+	}
+	return chain
+}
@@ -0,0 +1,182 @@
+// Package drbg 实现NIST SP 800-90A Rev.1中的确定性随机比特生成器HMAC_DRBG和CTR_DRBG
+//
+// 生成器从熵源（默认以crypto/rand为熵源的entropy.Default）读取熵输入和nonce完成实例化，
+// 此后的输出完全由内部状态决定，并按重播种间隔自动从熵源重播种。
+// DRBG实现io.Reader，可直接传给sm2.GenerateKey等函数。
+//
+// 测试中以固定内容的Reader作为熵源即可得到可复现、可审计的随机数序列：
+//
+//	seed := bytes.NewReader(fixedSeed)
+//	r, _ := drbg.NewHMAC(sm3.New, &drbg.Options{Entropy: seed})
+//	key, _ := sm2.New().GenerateKey(r)
+package drbg
+
+import (
+	"io"
+	"sync"
+
+	"github.com/laenix/gsc/entropy"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+const (
+	// MaxRequestSize 是单次Generate最多输出的字节数（2^19位）
+	MaxRequestSize = 1 << 16
+	// MaxReseedInterval 是SP 800-90A允许的最大重播种间隔（Generate调用次数）
+	MaxReseedInterval = 1 << 48
+)
+
+// 错误定义
+var (
+	ErrRequestTooLarge = gscerr.New(gscerr.ErrParameter, "drbg: request exceeds the maximum of 65536 bytes")
+	ErrInvalidEntropy  = gscerr.New(gscerr.ErrParameter, "drbg: entropy input shorter than the security strength")
+	ErrInvalidKeySize  = gscerr.New(gscerr.ErrKeySize, "drbg: CTR_DRBG key must be 16, 24 or 32 bytes")
+	ErrInvalidInterval = gscerr.New(gscerr.ErrParameter, "drbg: reseed interval exceeds 2^48")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrRequestTooLarge: "drbg: 单次请求超过65536字节上限",
+		ErrInvalidEntropy:  "drbg: 熵输入短于安全强度",
+		ErrInvalidKeySize:  "drbg: CTR_DRBG密钥必须是16、24或32字节",
+		ErrInvalidInterval: "drbg: 重播种间隔超过2^48",
+	})
+}
+
+// Options 是生成器的可选配置，nil表示全部使用默认值
+type Options struct {
+	// Entropy 是实例化和重播种使用的熵源，为nil时使用entropy.Default。
+	// 实例化时先读取安全强度/8字节的熵输入，再读取其一半长度的nonce；
+	// 每次重播种读取安全强度/8字节的熵输入
+	Entropy io.Reader
+	// Personalization 是实例化时混入的个性化字符串，可以为空
+	Personalization []byte
+	// ReseedInterval 是两次重播种之间允许的Generate调用次数，为0时使用MaxReseedInterval
+	ReseedInterval uint64
+	// PredictionResistance 为true时每次Generate之前都从熵源重播种
+	PredictionResistance bool
+}
+
+// mechanism 是HMAC_DRBG和CTR_DRBG各自的实例化、重播种和生成算法
+type mechanism interface {
+	instantiate(entropy, nonce, personalization []byte)
+	reseed(entropy, additional []byte)
+	generate(out, additional []byte)
+	// strength 返回安全强度（字节）
+	strength() int
+}
+
+// DRBG 是确定性随机比特生成器，由NewHMAC或NewCTR创建
+// DRBG可以被多个goroutine并发使用，各次调用的输出按调用顺序依次取自同一序列
+type DRBG struct {
+	mu            sync.Mutex
+	m             mechanism
+	entropy       io.Reader
+	interval      uint64
+	reseedCounter uint64
+	prediction    bool
+}
+
+// newDRBG 从熵源读取熵输入和nonce并实例化m
+func newDRBG(m mechanism, opts *Options) (*DRBG, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	d := &DRBG{m: m, entropy: opts.Entropy, interval: opts.ReseedInterval, prediction: opts.PredictionResistance}
+	if d.entropy == nil {
+		d.entropy = entropy.Default
+	}
+	if d.interval == 0 {
+		d.interval = MaxReseedInterval
+	}
+	if d.interval > MaxReseedInterval {
+		return nil, ErrInvalidInterval
+	}
+
+	n := m.strength()
+	seed := make([]byte, n+n/2)
+	if _, err := io.ReadFull(d.entropy, seed); err != nil {
+		return nil, err
+	}
+	m.instantiate(seed[:n], seed[n:], opts.Personalization)
+	d.reseedCounter = 1
+	return d, nil
+}
+
+// Reseed 从熵源读取新的熵输入，与additional一起重播种
+func (d *DRBG) Reseed(additional []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.reseed(additional)
+}
+
+func (d *DRBG) reseed(additional []byte) error {
+	seed := make([]byte, d.m.strength())
+	if _, err := io.ReadFull(d.entropy, seed); err != nil {
+		return err
+	}
+	d.m.reseed(seed, additional)
+	d.reseedCounter = 1
+	return nil
+}
+
+// ReseedWithEntropy 使用调用方提供的熵输入重播种，用于已知答案测试
+// seed不能短于安全强度，否则返回ErrInvalidEntropy
+func (d *DRBG) ReseedWithEntropy(seed, additional []byte) error {
+	if len(seed) < d.m.strength() {
+		return ErrInvalidEntropy
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.m.reseed(seed, additional)
+	d.reseedCounter = 1
+	return nil
+}
+
+// Generate 生成len(out)字节的随机数写入out，additional为可选的附加输入
+// len(out)超过MaxRequestSize时返回ErrRequestTooLarge；达到重播种间隔时先从熵源重播种
+func (d *DRBG) Generate(out, additional []byte) error {
+	if len(out) > MaxRequestSize {
+		return ErrRequestTooLarge
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.generate(out, additional)
+}
+
+func (d *DRBG) generate(out, additional []byte) error {
+	if d.prediction || d.reseedCounter > d.interval {
+		if err := d.reseed(additional); err != nil {
+			return err
+		}
+		additional = nil
+	}
+	d.m.generate(out, additional)
+	d.reseedCounter++
+	return nil
+}
+
+// Read 实现io.Reader，按MaxRequestSize拆分请求并依次调用Generate
+func (d *DRBG) Read(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for n := 0; n < len(p); n += MaxRequestSize {
+		if err := d.generate(p[n:min(n+MaxRequestSize, len(p))], nil); err != nil {
+			return n, err
+		}
+	}
+	return len(p), nil
+}
+
+// Healthy 实现entropy.HealthChecker，返回熵源的健康状态
+// 熵源未实现entropy.HealthChecker时返回entropy.ErrUnhealthy，
+// 因此以固定种子实例化的DRBG不会被要求健康熵源的调用方接受
+func (d *DRBG) Healthy() error {
+	checker, ok := d.entropy.(entropy.HealthChecker)
+	if !ok {
+		return entropy.ErrUnhealthy
+	}
+	return checker.Healthy()
+}
@@ -0,0 +1,265 @@
+package drbg
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/laenix/gsc/entropy"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/sm2"
+	"github.com/laenix/gsc/sm3"
+)
+
+func hmacSHA256(opts *Options) (*DRBG, error) { return NewHMAC(sha256.New, opts) }
+func hmacSHA1(opts *Options) (*DRBG, error)   { return NewHMAC(sha1.New, opts) }
+
+func ctr(keySize int) func(*Options) (*DRBG, error) {
+	return func(opts *Options) (*DRBG, error) { return NewCTR(keySize, opts) }
+}
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// 已知答案测试向量由OpenSSL 3.0的EVP_RAND（HMAC-DRBG，以及启用派生函数的CTR-DRBG）生成：
+// 实例化 → Generate(out1, add1) → 以reseed重播种(add2) → Generate(out2, add3)。
+// 未使用个性化字符串时向OpenSSL传入长度为0的个性化字符串，避免其替换为默认值
+var katTests = []struct {
+	name             string
+	new              func(*Options) (*DRBG, error)
+	entropy, nonce   string
+	reseed, pers     string
+	add1, add2, add3 string
+	out1, out2       string
+}{
+	{
+		name: "HMAC_DRBG SHA-256", new: hmacSHA256,
+		entropy: "1f262d343b424950575e656c737a81888f969da4abb2b9c0c7ced5dce3eaf1f8",
+		nonce:   "3e454c535a61686f767d848b9299a0a7",
+		reseed:  "5d646b727980878e959ca3aab1b8bfc6cdd4dbe2e9f0f7fe050c131a21282f36",
+		out1:    "cde1f0d5945993befc417ac62f481275d29e922946dd1f77f583a173f4d8e9010dc781fa83d73c5a1cac228c584a2b4f8cf2ee25a467806a610c2005ef04bda0bfdfd8de637c84c865cb0243b752015d",
+		out2:    "61eca9acf95fa7af21a453043ef384acd91d656bce10e299f548385176602e8c3d76c9ef06730c72d193fc2f3034c972dadc195fb3e4eb1eac4aab37af87bf3e18de3a2321b1a115d57ae7d114ad03f4",
+	},
+	{
+		name: "CTR_DRBG AES-256", new: ctr(32),
+		entropy: "1f262d343b424950575e656c737a81888f969da4abb2b9c0c7ced5dce3eaf1f8",
+		nonce:   "3e454c535a61686f767d848b9299a0a7",
+		reseed:  "5d646b727980878e959ca3aab1b8bfc6cdd4dbe2e9f0f7fe050c131a21282f36",
+		out1:    "6d960541b736bcf4be44b60d8afe0ad513579dba9d6e5ef928b9ed434241964c15440644a0d6602e8ceed8b669e2310a91a66744b22ad3688345bc49aa840e06ef2bd667380696ef896fa3fb5696bbb2",
+		out2:    "b0fce362f8e24f4ff217ad61b18b919b098f2688d1c397fa8eb0c7a5f107c74ddbc728e8f2cf6d013d483cbc6190e409245683cdb5b1f6dd057eb1d1bc9e0a8c01d82978c46c1686d98d9e4f51c99b09",
+	},
+	{
+		name: "CTR_DRBG AES-192", new: ctr(24),
+		entropy: "1f262d343b424950575e656c737a81888f969da4abb2b9c0",
+		nonce:   "3e454c535a61686f767d848b",
+		reseed:  "5d646b727980878e959ca3aab1b8bfc6cdd4dbe2e9f0f7fe",
+		out1:    "bafb921e41b827db3cae5a84512a47e4da632e9d7d0296b1aa3d26bae93fc68bd98440592bcfa210c7ecf704851b6cb26a14ff5bff11413a829ee28b0deecfc3e3db00bb2f69ecfc2628b528bccba127",
+		out2:    "c2fc7b53632d7b977096f3788999440c872a1b695f52e5054970eb55d619cdd6b5b17073476986262e6f8ef2ed9d2d4e08857b7128f609d2ad7ca1b082e89ec4bea2d30f20001c10cc2c43bf86752ecd",
+	},
+	{
+		name: "HMAC_DRBG SHA-256 个性化字符串+附加输入", new: hmacSHA256,
+		entropy: "1f262d343b424950575e656c737a81888f969da4abb2b9c0c7ced5dce3eaf1f8",
+		nonce:   "3e454c535a61686f767d848b9299a0a7",
+		reseed:  "5d646b727980878e959ca3aab1b8bfc6cdd4dbe2e9f0f7fe050c131a21282f36",
+		pers:    "7c838a91989fa6adb4bbc2c9d0d7dee5ecf3fa01080f161d242b323940474e55",
+		add1:    "9ba2a9b0b7bec5ccd3dae1e8eff6fd040b121920272e353c434a51585f666d74",
+		add2:    "bac1c8cfd6dde4ebf2f900070e151c232a31383f464d545b626970777e858c93",
+		add3:    "d9e0e7eef5fc030a11181f262d343b424950575e656c737a81888f969da4abb2",
+		out1:    "d4e74033b3a971bfdac25dbdfbe84e830a05982a7391fbf7b0af0b6ac2e484ed082d3a4ba4405e9cc3e8a0697d1c4d5dfe94e8c9fde7baa5fa04486017a89195219db505fada6dddb72b947f7f6741fc",
+		out2:    "7b14c77d096149c8d6bd8b72857fbc51afe9776859c4cfbbdf767d7a5468e3623e95ccfec3cfb8d7c9e870721913ffee2f8114b62755fafc2e63241cbd1b0294606f80811d18c350c28c80ebd04c5ca4",
+	},
+	{
+		name: "HMAC_DRBG SHA-1 个性化字符串+附加输入", new: hmacSHA1,
+		entropy: "1f262d343b424950575e656c737a8188",
+		nonce:   "3e454c535a61686f",
+		reseed:  "5d646b727980878e959ca3aab1b8bfc6",
+		pers:    "7c838a91989fa6adb4bbc2c9d0d7dee5ecf3fa01080f161d242b323940474e55",
+		add1:    "9ba2a9b0b7bec5ccd3dae1e8eff6fd040b121920272e353c434a51585f666d74",
+		add2:    "bac1c8cfd6dde4ebf2f900070e151c232a31383f464d545b626970777e858c93",
+		add3:    "d9e0e7eef5fc030a11181f262d343b424950575e656c737a81888f969da4abb2",
+		out1:    "182365c506b5aa53b4650fa3e646c43262419a8c5e3ec438b61c14172dfc525648907c2fa0e67616c50f539984d97f4c9adbe53c4b3b304819c42c5e15c9ec9743b2cb0d019139fb515bb37c7fcd08a0",
+		out2:    "68d2af52522e183958f0bfbf7ee4319c70dc64a017570b69f9f2f09c4aa7e1098b5124fc96b0ca5e1f8cc5e24aab707b0236d577bbbd9096359256a078a1e00b964c341d0dfb53606ea1e6593a6e758c",
+	},
+	{
+		name: "CTR_DRBG AES-256 个性化字符串+附加输入", new: ctr(32),
+		entropy: "1f262d343b424950575e656c737a81888f969da4abb2b9c0c7ced5dce3eaf1f8",
+		nonce:   "3e454c535a61686f767d848b9299a0a7",
+		reseed:  "5d646b727980878e959ca3aab1b8bfc6cdd4dbe2e9f0f7fe050c131a21282f36",
+		pers:    "7c838a91989fa6adb4bbc2c9d0d7dee5ecf3fa01080f161d242b323940474e55",
+		add1:    "9ba2a9b0b7bec5ccd3dae1e8eff6fd040b121920272e353c434a51585f666d74",
+		add2:    "bac1c8cfd6dde4ebf2f900070e151c232a31383f464d545b626970777e858c93",
+		add3:    "d9e0e7eef5fc030a11181f262d343b424950575e656c737a81888f969da4abb2",
+		out1:    "268ef39bd86dd7e926fdd6af04d15158525e27466ca5a4d3a1d1d0129b4f1120d8cb9be4fd7aa3d6104629ab423b4c89abef6eba923e92f8619aa771ee0af61c98a0f99b812a8cc2445660142dea8f02",
+		out2:    "35f6fcd7ae18ee0e7689ff458d9ee2f066bc7a0c681ef62c64370854e5165033c325617d687ef851343777c8158a85aaea1f1c7b542d4e3974431c92fac3eedf65c14d1f99010a00e14c09f4fc61bf1f",
+	},
+	{
+		name: "CTR_DRBG AES-128 个性化字符串+附加输入", new: ctr(16),
+		entropy: "1f262d343b424950575e656c737a8188",
+		nonce:   "3e454c535a61686f",
+		reseed:  "5d646b727980878e959ca3aab1b8bfc6",
+		pers:    "7c838a91989fa6adb4bbc2c9d0d7dee5ecf3fa01080f161d242b323940474e55",
+		add1:    "9ba2a9b0b7bec5ccd3dae1e8eff6fd040b121920272e353c434a51585f666d74",
+		add2:    "bac1c8cfd6dde4ebf2f900070e151c232a31383f464d545b626970777e858c93",
+		add3:    "d9e0e7eef5fc030a11181f262d343b424950575e656c737a81888f969da4abb2",
+		out1:    "2df8bcf0272e571f2cd5a42ab2322296b482caa8a52106aaf390cdc744a82de97adb604d18a3538a479bd7d1c4b090e5b768e8fcf7ab49104bf88016d615efbac9ce1a115bb5cd28179214977b6617f3",
+		out2:    "916bff8c9887c5592b07e64dc4299c457ae61d9f14f6d9235a7bb34b7cdd83917409a3fd23aa7daf46a1fa915a66d57704d083c36a98167a492088bcc762e71e4c4cc3382426ccc1466d29aba5db6ed3",
+	},
+}
+
+func TestKnownAnswer(t *testing.T) {
+	for _, tt := range katTests {
+		seed := append(mustHex(tt.entropy), mustHex(tt.nonce)...)
+		d, err := tt.new(&Options{Entropy: bytes.NewReader(seed), Personalization: mustHex(tt.pers)})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		out := make([]byte, 80)
+		if err := d.Generate(out, mustHex(tt.add1)); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := hex.EncodeToString(out); got != tt.out1 {
+			t.Errorf("%s: 第一次输出\n实际: %s\n期望: %s", tt.name, got, tt.out1)
+		}
+		if err := d.ReseedWithEntropy(mustHex(tt.reseed), mustHex(tt.add2)); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if err := d.Generate(out, mustHex(tt.add3)); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := hex.EncodeToString(out); got != tt.out2 {
+			t.Errorf("%s: 重播种后的输出\n实际: %s\n期望: %s", tt.name, got, tt.out2)
+		}
+	}
+}
+
+// 测试达到重播种间隔后自动从熵源读取新的熵输入
+func TestReseedInterval(t *testing.T) {
+	src := bytes.NewReader(bytes.Repeat([]byte{0x42}, 48+32))
+	d, err := hmacSHA256(&Options{Entropy: src, ReseedInterval: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make([]byte, 16)
+	for range 2 {
+		if err := d.Generate(out, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if src.Len() != 32 {
+		t.Fatalf("间隔内不应重播种，熵源剩余%d字节", src.Len())
+	}
+	if err := d.Generate(out, nil); err != nil {
+		t.Fatal(err)
+	}
+	if src.Len() != 0 {
+		t.Fatalf("达到间隔后应重播种，熵源剩余%d字节", src.Len())
+	}
+	// 熵源耗尽后无法再重播种
+	d.Generate(out, nil)
+	if err := d.Generate(out, nil); err != io.EOF {
+		t.Fatalf("期望io.EOF，实际: %v", err)
+	}
+}
+
+// 测试预测抗性模式在每次Generate前重播种
+func TestPredictionResistance(t *testing.T) {
+	src := bytes.NewReader(make([]byte, 24+16*3))
+	d, err := NewCTR(16, &Options{Entropy: src, PredictionResistance: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make([]byte, 16)
+	for range 3 {
+		if err := d.Generate(out, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if src.Len() != 0 {
+		t.Fatalf("每次Generate都应重播种，熵源剩余%d字节", src.Len())
+	}
+}
+
+// 测试Read按MaxRequestSize拆分，结果与逐次Generate一致
+func TestRead(t *testing.T) {
+	seed := bytes.Repeat([]byte{7}, 48)
+	a, _ := hmacSHA256(&Options{Entropy: bytes.NewReader(seed)})
+	b, _ := hmacSHA256(&Options{Entropy: bytes.NewReader(seed)})
+
+	got := make([]byte, MaxRequestSize+100)
+	if n, err := io.ReadFull(a, got); err != nil || n != len(got) {
+		t.Fatalf("Read: n=%d err=%v", n, err)
+	}
+	want := make([]byte, len(got))
+	b.Generate(want[:MaxRequestSize], nil)
+	b.Generate(want[MaxRequestSize:], nil)
+	if !bytes.Equal(got, want) {
+		t.Fatal("Read的输出与逐次Generate不一致")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := NewCTR(20, nil); !errors.Is(err, gscerr.ErrKeySize) {
+		t.Errorf("期望ErrKeySize类别，实际: %v", err)
+	}
+	if _, err := hmacSHA256(&Options{ReseedInterval: MaxReseedInterval + 1}); err != ErrInvalidInterval {
+		t.Errorf("期望ErrInvalidInterval，实际: %v", err)
+	}
+	if _, err := hmacSHA256(&Options{Entropy: bytes.NewReader(make([]byte, 47))}); err == nil {
+		t.Error("熵源不足时应返回错误")
+	}
+
+	d, err := NewCTR(32, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Generate(make([]byte, MaxRequestSize+1), nil); err != ErrRequestTooLarge {
+		t.Errorf("期望ErrRequestTooLarge，实际: %v", err)
+	}
+	if err := d.ReseedWithEntropy(make([]byte, 16), nil); err != ErrInvalidEntropy {
+		t.Errorf("期望ErrInvalidEntropy，实际: %v", err)
+	}
+	if err := d.Reseed(nil); err != nil {
+		t.Errorf("从默认熵源重播种失败: %v", err)
+	}
+}
+
+// 测试健康状态跟随熵源：默认熵源健康，固定种子不被视为健康熵源
+func TestHealthy(t *testing.T) {
+	d, _ := NewCTR(32, nil)
+	if err := d.Healthy(); err != nil {
+		t.Errorf("默认熵源应健康: %v", err)
+	}
+	d, _ = hmacSHA256(&Options{Entropy: bytes.NewReader(make([]byte, 48))})
+	if err := d.Healthy(); err != entropy.ErrUnhealthy {
+		t.Errorf("期望entropy.ErrUnhealthy，实际: %v", err)
+	}
+}
+
+// 测试以相同种子实例化的DRBG生成相同的SM2密钥
+func TestDeterministicSM2Key(t *testing.T) {
+	seed := mustHex("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d2e2f")
+	gen := func() *sm2.PrivateKey {
+		r, err := NewHMAC(sm3.New, &Options{Entropy: bytes.NewReader(seed), Personalization: []byte("sm2 keygen")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := sm2.New().GenerateKey(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+	a, b := gen(), gen()
+	if a.D.Cmp(b.D) != 0 || a.X.Cmp(b.X) != 0 {
+		t.Fatal("相同种子应生成相同的密钥")
+	}
+}
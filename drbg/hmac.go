@@ -0,0 +1,82 @@
+package drbg
+
+import (
+	"crypto/hmac"
+	"hash"
+)
+
+// hmacDRBG 是SP 800-90A 10.1.2中的HMAC_DRBG
+type hmacDRBG struct {
+	h    func() hash.Hash
+	k, v []byte
+}
+
+// NewHMAC 返回以h为哈希函数的HMAC_DRBG，opts为nil时使用默认配置
+// 安全强度由哈希输出长度决定：20字节（SHA-1）为128位，28字节为192位，32字节及以上为256位
+func NewHMAC(h func() hash.Hash, opts *Options) (*DRBG, error) {
+	size := h().Size()
+	m := &hmacDRBG{h: h, k: make([]byte, size), v: make([]byte, size)}
+	return newDRBG(m, opts)
+}
+
+func (m *hmacDRBG) strength() int {
+	switch size := len(m.v); {
+	case size >= 32:
+		return 32
+	case size >= 28:
+		return 24
+	default:
+		return 16
+	}
+}
+
+// update 是HMAC_DRBG_Update：K = HMAC(K, V || 0x00 || data)，V = HMAC(K, V)；
+// data非空时再以0x01重复一次
+func (m *hmacDRBG) update(data ...[]byte) {
+	empty := true
+	for _, d := range data {
+		empty = empty && len(d) == 0
+	}
+	for _, sep := range []byte{0x00, 0x01} {
+		mac := hmac.New(m.h, m.k)
+		mac.Write(m.v)
+		mac.Write([]byte{sep})
+		for _, d := range data {
+			mac.Write(d)
+		}
+		m.k = mac.Sum(m.k[:0])
+
+		mac = hmac.New(m.h, m.k)
+		mac.Write(m.v)
+		m.v = mac.Sum(m.v[:0])
+		if empty {
+			return
+		}
+	}
+}
+
+func (m *hmacDRBG) instantiate(entropy, nonce, personalization []byte) {
+	clear(m.k)
+	for i := range m.v {
+		m.v[i] = 0x01
+	}
+	m.update(entropy, nonce, personalization)
+}
+
+func (m *hmacDRBG) reseed(entropy, additional []byte) {
+	m.update(entropy, additional)
+}
+
+func (m *hmacDRBG) generate(out, additional []byte) {
+	if len(additional) > 0 {
+		m.update(additional)
+	}
+	mac := hmac.New(m.h, m.k)
+	for n := 0; n < len(out); n += len(m.v) {
+		mac.Reset()
+		mac.Write(m.v)
+		m.v = mac.Sum(m.v[:0])
+		copy(out[n:], m.v)
+	}
+	m.update(additional)
+}
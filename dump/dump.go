@@ -0,0 +1,102 @@
+package dump
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Hex 返回按group字节分组、以空格分隔的十六进制字符串
+// group小于等于0时不分组，例如 Hex(data, 4) 输出 "01234567 89abcdef"
+func Hex(data []byte, group int) string {
+	if group <= 0 || group >= len(data) {
+		return hex.EncodeToString(data)
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(data); i += group {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(hex.EncodeToString(data[i:min(i+group, len(data))]))
+	}
+	return b.String()
+}
+
+// Blocks 按分组大小逐行输出数据，每行以分组序号和偏移量开头，最后一个分组可以不完整：
+//
+//	块 1 [0000]: 00112233445566778899aabbccddeeff
+func Blocks(data []byte, blockSize int) string {
+	if blockSize <= 0 {
+		blockSize = len(data)
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(data); i += blockSize {
+		fmt.Fprintf(&b, "块 %d [%04x]: %s\n", i/blockSize+1, i, hex.EncodeToString(data[i:min(i+blockSize, len(data))]))
+	}
+	return b.String()
+}
+
+// Diff 按分组逐块对比实际值与期望值，不一致的分组以 "≠" 标出，
+// 用于定位测试向量不匹配的位置
+func Diff(got, want []byte, blockSize int) string {
+	if blockSize <= 0 {
+		blockSize = max(len(got), len(want))
+	}
+
+	var b strings.Builder
+	if len(got) != len(want) {
+		fmt.Fprintf(&b, "长度不一致: 实际=%d, 期望=%d\n", len(got), len(want))
+	}
+	for i := 0; i < max(len(got), len(want)); i += blockSize {
+		g := got[min(i, len(got)):min(i+blockSize, len(got))]
+		w := want[min(i, len(want)):min(i+blockSize, len(want))]
+		mark := "="
+		if hex.EncodeToString(g) != hex.EncodeToString(w) {
+			mark = "≠"
+		}
+		fmt.Fprintf(&b, "块 %d [%04x]: 实际=%x\n", i/blockSize+1, i, g)
+		fmt.Fprintf(&b, "       %s 期望=%x\n", mark, w)
+	}
+	return b.String()
+}
+
+// Bits 返回数据的二进制视图，每字节8位，字节之间以空格分隔
+func Bits(data []byte) string {
+	var b strings.Builder
+	for i, v := range data {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%08b", v)
+	}
+	return b.String()
+}
+
+// Words 将数据按wordSize（2、4或8）字节切分为字，以order指定的字节序解释后输出十六进制，
+// 例如SM3/SHA-256的摘要按大端序输出为8个32位字，MD5/BLAKE2按小端序更便于与规范对照。
+// 末尾不足一个字的字节按原样输出；wordSize为其他值时等同于Hex
+func Words(data []byte, wordSize int, order binary.ByteOrder) string {
+	if wordSize != 2 && wordSize != 4 && wordSize != 8 {
+		return Hex(data, wordSize)
+	}
+
+	var words []string
+	n := len(data) - len(data)%wordSize
+	for i := 0; i < n; i += wordSize {
+		switch wordSize {
+		case 2:
+			words = append(words, fmt.Sprintf("%04x", order.Uint16(data[i:])))
+		case 4:
+			words = append(words, fmt.Sprintf("%08x", order.Uint32(data[i:])))
+		case 8:
+			words = append(words, fmt.Sprintf("%016x", order.Uint64(data[i:])))
+		}
+	}
+	if n < len(data) {
+		words = append(words, hex.EncodeToString(data[n:]))
+	}
+	return strings.Join(words, " ")
+}
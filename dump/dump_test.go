@@ -0,0 +1,61 @@
+package dump
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+func TestHex(t *testing.T) {
+	data := []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef, 0xff}
+	tests := []struct {
+		group int
+		want  string
+	}{
+		{0, "0123456789abcdefff"},
+		{4, "01234567 89abcdef ff"},
+		{2, "0123 4567 89ab cdef ff"},
+	}
+	for _, tt := range tests {
+		if got := Hex(data, tt.group); got != tt.want {
+			t.Errorf("Hex(%d) = %q，期望 %q", tt.group, got, tt.want)
+		}
+	}
+}
+
+func TestBlocks(t *testing.T) {
+	got := Blocks([]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, 4)
+	want := "块 1 [0000]: 00010203\n块 2 [0004]: 04050607\n块 3 [0008]: 0809\n"
+	if got != want {
+		t.Errorf("Blocks输出不正确:\n%s", got)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	got := Diff([]byte{1, 2, 3, 4}, []byte{1, 2, 3, 5}, 2)
+	lines := strings.Split(strings.TrimSpace(got), "\n")
+	if len(lines) != 4 || !strings.Contains(lines[1], "= 期望=0102") || !strings.Contains(lines[3], "≠ 期望=0305") {
+		t.Errorf("Diff输出不正确:\n%s", got)
+	}
+
+	if got := Diff([]byte{1}, []byte{1, 2}, 4); !strings.HasPrefix(got, "长度不一致") {
+		t.Errorf("长度不同时应首先提示:\n%s", got)
+	}
+}
+
+func TestBitsAndWords(t *testing.T) {
+	if got := Bits([]byte{0x80, 0x05}); got != "10000000 00000101" {
+		t.Errorf("Bits = %q", got)
+	}
+
+	data := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09}
+	if got := Words(data, 4, binary.BigEndian); got != "01020304 05060708 09" {
+		t.Errorf("大端序Words = %q", got)
+	}
+	if got := Words(data, 4, binary.LittleEndian); got != "04030201 08070605 09" {
+		t.Errorf("小端序Words = %q", got)
+	}
+	if got := Words(data[:8], 8, binary.LittleEndian); got != "0807060504030201" {
+		t.Errorf("64位小端序Words = %q", got)
+	}
+}
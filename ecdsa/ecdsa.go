@@ -1 +1,107 @@
+// Package ecdsa 实现RFC 6979确定性ECDSA签名
+//
+// 密钥沿用crypto/ecdsa的类型，曲线可以是crypto/elliptic中的NIST曲线，
+// 签名可以直接用crypto/ecdsa.Verify或VerifyASN1验证。签名随机数k由私钥和
+// 消息摘要经HMAC_DRBG导出，不读取随机数发生器：同一私钥对同一摘要总是得到相同的签名，
+// 在随机数发生器质量差或被预测的环境中也不会因k重复或可预测而泄露私钥
 package ecdsa
+
+import (
+	"crypto/ecdsa"
+	"hash"
+	"math/big"
+
+	"github.com/laenix/gsc/der"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/internal/nat"
+	"github.com/laenix/gsc/internal/rfc6979"
+)
+
+// 错误定义
+var (
+	ErrInvalidPrivateKey = gscerr.New(gscerr.ErrMalformed, "ecdsa: invalid private key")
+	ErrInvalidHash       = gscerr.New(gscerr.ErrParameter, "ecdsa: hash function is required")
+	ErrInvalidDigest     = gscerr.New(gscerr.ErrParameter, "ecdsa: digest length does not match the hash")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidPrivateKey: "ecdsa: 无效的私钥",
+		ErrInvalidHash:       "ecdsa: 必须指定摘要算法",
+		ErrInvalidDigest:     "ecdsa: 摘要长度与哈希算法不符",
+	})
+}
+
+// SignDeterministic 按RFC 6979签名摘要digest，返回签名值(r, s)
+// h是计算digest所用的摘要算法，同时用于导出k的HMAC，digest的长度必须等于h的摘要长度，否则返回ErrInvalidDigest；
+// digest长于基点阶时按FIPS 186-5截取最高位。k^-1按费马小定理以常量时间计算，
+// 不使用运算时间取决于k的big.Int.ModInverse
+func SignDeterministic(priv *ecdsa.PrivateKey, h func() hash.Hash, digest []byte) (r, s *big.Int, err error) {
+	if priv == nil || priv.Curve == nil || priv.D == nil {
+		return nil, nil, ErrInvalidPrivateKey
+	}
+	if h == nil {
+		return nil, nil, ErrInvalidHash
+	}
+	if len(digest) != h().Size() {
+		return nil, nil, ErrInvalidDigest
+	}
+	n := priv.Curve.Params().N
+	if priv.D.Sign() <= 0 || priv.D.Cmp(n) >= 0 {
+		return nil, nil, ErrInvalidPrivateKey
+	}
+
+	e := hashToInt(digest, n)
+	mod := nat.NewModulus(n)
+	g := rfc6979.New(h, n, priv.D, digest)
+	for {
+		k := g.Next()
+
+		// r = (kG).x mod n
+		x1, _ := priv.Curve.ScalarBaseMult(k.FillBytes(make([]byte, (n.BitLen()+7)/8)))
+		r = x1.Mod(x1, n)
+		if r.Sign() == 0 {
+			continue
+		}
+
+		// s = k^-1 (e + r·d) mod n
+		s = new(big.Int).Mul(r, priv.D)
+		s.Add(s, e)
+		s.Mul(s, mod.InversePrime(nat.FromBig(k, mod.Size())).Big())
+		s.Mod(s, n)
+		if s.Sign() != 0 {
+			return r, s, nil
+		}
+	}
+}
+
+// SignASN1Deterministic 与SignDeterministic相同，签名按DER编码为 SEQUENCE { r INTEGER, s INTEGER }，
+// 与crypto/ecdsa.SignASN1的输出格式一致
+func SignASN1Deterministic(priv *ecdsa.PrivateKey, h func() hash.Hash, digest []byte) ([]byte, error) {
+	r, s, err := SignDeterministic(priv, h, digest)
+	if err != nil {
+		return nil, err
+	}
+	var b der.Builder
+	b.AddSequence(func(b *der.Builder) {
+		b.AddInteger(r)
+		b.AddInteger(s)
+	})
+	return b.Bytes()
+}
+
+// hashToInt 将摘要转换为整数，摘要的位数超过n时只取最高的n.BitLen()位
+func hashToInt(digest []byte, n *big.Int) *big.Int {
+	orderBits := n.BitLen()
+	orderBytes := (orderBits + 7) / 8
+	if len(digest) > orderBytes {
+		digest = digest[:orderBytes]
+	}
+	e := new(big.Int).SetBytes(digest)
+	if excess := len(digest)*8 - orderBits; excess > 0 {
+		e.Rsh(e, uint(excess))
+	}
+	return e
+}
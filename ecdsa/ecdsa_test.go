@@ -0,0 +1,138 @@
+package ecdsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"math/big"
+	"testing"
+
+	"github.com/laenix/gsc/vectors"
+)
+
+var curves = map[string]elliptic.Curve{
+	"P-224": elliptic.P224(),
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+// 测试RFC 6979附录A.2的测试向量
+func TestRFC6979Vectors(t *testing.T) {
+	vectors.Run(t, "testdata/rfc6979.rsp", func(t *testing.T, c *vectors.Case) {
+		curve := curves[c.Params["curve"]]
+		if curve == nil {
+			t.Fatalf("未知曲线: %s", c.Params["curve"])
+		}
+		param := func(name string) *big.Int {
+			v, ok := new(big.Int).SetString(c.Params[name], 16)
+			if !ok {
+				t.Fatalf("参数%s格式错误", name)
+			}
+			return v
+		}
+		priv := &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{Curve: curve, X: param("Ux"), Y: param("Uy")},
+			D:         param("x"),
+		}
+		msg, err := c.Hex("Msg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantR, err := c.Hex("R")
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantS, err := c.Hex("S")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		digest := sha256.Sum256(msg)
+		r, s, err := SignDeterministic(priv, sha256.New, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Cmp(new(big.Int).SetBytes(wantR)) != 0 || s.Cmp(new(big.Int).SetBytes(wantS)) != 0 {
+			t.Fatalf("签名不一致\nr = %x\ns = %x", r, s)
+		}
+		if !ecdsa.Verify(&priv.PublicKey, digest[:], r, s) {
+			t.Fatal("crypto/ecdsa验证失败")
+		}
+	})
+}
+
+// 与crypto/ecdsa的确定性签名（rand为nil时按RFC 6979生成k）对比其他摘要算法和摘要截断
+func TestStdlibInterop(t *testing.T) {
+	hashes := []struct {
+		name string
+		h    func() hash.Hash
+		id   crypto.Hash
+	}{
+		{"SHA-224", sha256.New224, crypto.SHA224},
+		{"SHA-256", sha256.New, crypto.SHA256},
+		{"SHA-384", sha512.New384, crypto.SHA384},
+		{"SHA-512", sha512.New, crypto.SHA512},
+	}
+	for _, name := range []string{"P-224", "P-256", "P-384", "P-521"} {
+		priv, err := ecdsa.GenerateKey(curves[name], rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tc := range hashes {
+			t.Run(name+"/"+tc.name, func(t *testing.T) {
+				d := tc.h()
+				d.Write([]byte("sample"))
+				digest := d.Sum(nil)
+
+				want, err := priv.Sign(nil, digest, tc.id)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := SignASN1Deterministic(priv, tc.h, digest)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Fatalf("签名与crypto/ecdsa不一致\ngot  %x\nwant %x", got, want)
+				}
+				if !ecdsa.VerifyASN1(&priv.PublicKey, digest, got) {
+					t.Fatal("crypto/ecdsa验证失败")
+				}
+			})
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := make([]byte, sha256.Size)
+
+	if _, _, err := SignDeterministic(nil, sha256.New, digest); err != ErrInvalidPrivateKey {
+		t.Fatalf("nil私钥期望ErrInvalidPrivateKey，实际: %v", err)
+	}
+	if _, _, err := SignDeterministic(priv, nil, digest); err != ErrInvalidHash {
+		t.Fatalf("nil摘要算法期望ErrInvalidHash，实际: %v", err)
+	}
+	// 摘要长度必须与h一致，截断或其他算法的摘要会被拒绝
+	for _, d := range [][]byte{digest[:20], nil, make([]byte, sha512.Size)} {
+		if _, err := SignASN1Deterministic(priv, sha256.New, d); err != ErrInvalidDigest {
+			t.Fatalf("%d字节摘要期望ErrInvalidDigest，实际: %v", len(d), err)
+		}
+	}
+	for _, d := range []*big.Int{big.NewInt(0), elliptic.P256().Params().N} {
+		bad := *priv
+		bad.D = d
+		if _, err := SignASN1Deterministic(&bad, sha256.New, digest); err != ErrInvalidPrivateKey {
+			t.Fatalf("私钥%x期望ErrInvalidPrivateKey，实际: %v", d, err)
+		}
+	}
+}
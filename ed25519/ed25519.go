@@ -0,0 +1,216 @@
+// Package ed25519 实现RFC 8032的Ed25519签名（包括Ed25519ctx和Ed25519ph）以及批量验证
+//
+// 密钥和签名的格式与crypto/ed25519相同：私钥为32字节种子与32字节公钥的拼接，
+// 两者生成的签名逐字节一致，可以互相验证。签名流程由eddsa包实现，
+// 群运算使用常量时间的edwards25519实现
+package ed25519
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha512"
+	"io"
+
+	"github.com/laenix/gsc/eddsa"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/internal/edwards25519"
+)
+
+// 错误定义
+var (
+	ErrUnsupportedHash = gscerr.New(gscerr.ErrUnsupported, "ed25519: expected opts.Hash to be zero or SHA-512")
+	ErrInvalidDigest   = gscerr.New(gscerr.ErrParameter, "ed25519: Ed25519ph message must be a SHA-512 digest")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrUnsupportedHash: "ed25519: 预哈希只支持SHA-512",
+		ErrInvalidDigest:   "ed25519: Ed25519ph的消息必须是SHA-512摘要",
+	})
+}
+
+// 密钥和签名大小（字节）
+const (
+	// PublicKeySize 是公钥的长度
+	PublicKeySize = 32
+	// PrivateKeySize 是私钥的长度：种子 || 公钥
+	PrivateKeySize = 64
+	// SignatureSize 是签名的长度：R || S
+	SignatureSize = 64
+	// SeedSize 是私钥种子的长度（RFC 8032中的私钥）
+	SeedSize = 32
+)
+
+// curve 是Ed25519的参数，基点阶L = 2^252 + 27742317777372353535851937790883648493
+var curve = &eddsa.Curve{
+	Size: 32,
+	Order: []byte{
+		0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58,
+		0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10,
+	},
+	Hash:        hash,
+	Clamp:       clamp,
+	DomLabel:    "SigEd25519 no Ed25519 collisions",
+	DomOptional: true,
+	Group:       edwards25519.Group{},
+}
+
+// hash 计算SHA-512(data[0] || data[1] || ...)
+func hash(data ...[]byte) []byte {
+	h := sha512.New()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// clamp 清除最低3位和最高位，置位第254位（RFC 8032第5.1.5节）
+func clamp(s []byte) {
+	s[0] &= 248
+	s[31] &= 127
+	s[31] |= 64
+}
+
+// PublicKey 是Ed25519公钥
+type PublicKey []byte
+
+// Equal 判断pub和x是否是相同的公钥
+func (pub PublicKey) Equal(x crypto.PublicKey) bool {
+	xx, ok := x.(PublicKey)
+	return ok && bytes.Equal(pub, xx)
+}
+
+// PrivateKey 是Ed25519私钥，实现crypto.Signer
+type PrivateKey []byte
+
+// Public 返回私钥对应的公钥
+func (priv PrivateKey) Public() crypto.PublicKey {
+	return PublicKey(bytes.Clone(priv[SeedSize:]))
+}
+
+// Equal 判断priv和x是否是相同的私钥
+func (priv PrivateKey) Equal(x crypto.PrivateKey) bool {
+	xx, ok := x.(PrivateKey)
+	return ok && bytes.Equal(priv, xx)
+}
+
+// Seed 返回私钥种子，可用NewKeyFromSeed恢复私钥
+func (priv PrivateKey) Seed() []byte {
+	return bytes.Clone(priv[:SeedSize])
+}
+
+// Sign 实现crypto.Signer。opts.HashFunc()为0时签名原始消息，为crypto.SHA512时message须为
+// SHA-512摘要（Ed25519ph）；opts为*Options时还使用其中的上下文。Ed25519是确定性的，rand被忽略
+func (priv PrivateKey) Sign(rand io.Reader, message []byte, opts crypto.SignerOpts) ([]byte, error) {
+	o, ok := opts.(*Options)
+	if !ok {
+		o = &Options{Hash: opts.HashFunc()}
+	}
+	eo, err := o.eddsa(message)
+	if err != nil {
+		return nil, err
+	}
+	return curve.Sign(priv, message, eo)
+}
+
+// Options 选择Ed25519的变体，实现crypto.SignerOpts
+type Options struct {
+	// Hash 为0时签名原始消息（Ed25519或Ed25519ctx），为crypto.SHA512时签名SHA-512摘要（Ed25519ph）
+	Hash crypto.Hash
+	// Context 是上下文字符串，最长255字节。Hash为0且Context非空时使用Ed25519ctx
+	Context string
+}
+
+// HashFunc 返回o.Hash
+func (o *Options) HashFunc() crypto.Hash {
+	return o.Hash
+}
+
+// eddsa 转换为eddsa.Options并检查消息长度
+func (o *Options) eddsa(message []byte) (*eddsa.Options, error) {
+	switch {
+	case o.Hash == crypto.SHA512:
+		if len(message) != sha512.Size {
+			return nil, ErrInvalidDigest
+		}
+	case o.Hash != 0:
+		return nil, ErrUnsupportedHash
+	}
+	return &eddsa.Options{PreHashed: o.Hash == crypto.SHA512, Context: []byte(o.Context)}, nil
+}
+
+// GenerateKey 生成密钥对，random为nil时使用crypto/rand
+func GenerateKey(random io.Reader) (PublicKey, PrivateKey, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	seed := make([]byte, SeedSize)
+	if _, err := io.ReadFull(random, seed); err != nil {
+		return nil, nil, err
+	}
+	priv, err := NewKeyFromSeed(seed)
+	if err != nil {
+		return nil, nil, err
+	}
+	return priv.Public().(PublicKey), priv, nil
+}
+
+// NewKeyFromSeed 由32字节种子计算私钥，种子长度错误时返回eddsa.ErrInvalidSeed
+func NewKeyFromSeed(seed []byte) (PrivateKey, error) {
+	priv, err := curve.NewKeyFromSeed(seed)
+	if err != nil {
+		return nil, err
+	}
+	return PrivateKey(priv), nil
+}
+
+// Sign 签名消息，私钥长度错误时返回eddsa.ErrInvalidPrivateKey
+func Sign(priv PrivateKey, message []byte) ([]byte, error) {
+	return curve.Sign(priv, message, nil)
+}
+
+// Verify 验证Ed25519签名，与crypto/ed25519.Verify的判定规则相同
+func Verify(pub PublicKey, message, sig []byte) bool {
+	return curve.Verify(pub, message, sig, nil) == nil
+}
+
+// VerifyWithOptions 按opts指定的变体验证签名，签名无效时返回eddsa.ErrVerification
+func VerifyWithOptions(pub PublicKey, message, sig []byte, opts *Options) error {
+	eo, err := opts.eddsa(message)
+	if err != nil {
+		return err
+	}
+	return curve.Verify(pub, message, sig, eo)
+}
+
+// BatchVerifier 批量验证多个Ed25519签名，签名较多时明显快于逐个调用Verify
+// 批量验证乘以余因子8，只有小阶分量不同的刻意构造的签名会被批量验证接受而被Verify拒绝；
+// Verify返回false时需要逐个验证才能找出无效的签名
+type BatchVerifier struct {
+	v *eddsa.BatchVerifier
+}
+
+// NewBatchVerifier 创建批量验证器
+func NewBatchVerifier() *BatchVerifier {
+	return &BatchVerifier{v: curve.NewBatchVerifier()}
+}
+
+// Add 添加一个待验证的签名
+func (b *BatchVerifier) Add(pub PublicKey, message, sig []byte) {
+	b.v.Add(pub, message, sig, nil)
+}
+
+// Len 返回已添加的签名数
+func (b *BatchVerifier) Len() int {
+	return b.v.Len()
+}
+
+// Verify 在所有签名都有效时返回true，random用于生成随机系数，为nil时使用crypto/rand
+func (b *BatchVerifier) Verify(random io.Reader) bool {
+	return b.v.Verify(random)
+}
@@ -0,0 +1,343 @@
+package ed25519
+
+import (
+	"bytes"
+	"crypto"
+	stded25519 "crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/laenix/gsc/eddsa"
+	"github.com/laenix/gsc/vectors"
+)
+
+func mustHex(t testing.TB, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// 测试SUPERCOP的sign.input测试向量（包含RFC 8032第7.1节的前几个用例）
+func TestVectors(t *testing.T) {
+	vectors.Run(t, "testdata/sign.rsp", func(t *testing.T, c *vectors.Case) {
+		seed, err := c.Hex("Seed")
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantPub, err := c.Hex("PK")
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg, err := c.Hex("Msg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantSig, err := c.Hex("Sig")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		priv, err := NewKeyFromSeed(seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub := priv.Public().(PublicKey)
+		if !bytes.Equal(pub, wantPub) {
+			t.Fatalf("公钥不一致: %x", pub)
+		}
+		sig, err := Sign(priv, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sig, wantSig) {
+			t.Fatalf("签名不一致: %x", sig)
+		}
+		if !Verify(pub, msg, sig) {
+			t.Fatal("有效签名验证失败")
+		}
+		sig[0] ^= 1
+		if Verify(pub, msg, sig) {
+			t.Fatal("篡改的签名验证成功")
+		}
+	})
+}
+
+// 测试RFC 8032第7.2节（Ed25519ctx）和第7.3节（Ed25519ph）的测试向量
+func TestVariants(t *testing.T) {
+	tests := []struct {
+		name, key, msg, sig string
+		opts                *Options
+	}{
+		{
+			name: "Ed25519ctx",
+			key:  "0305334e381af78f141cb666f6199f57bc3495335a256a95bd2a55bf546663f6",
+			msg:  "f726936d19c800494e3fdaff20b276a8",
+			sig:  "55a4cc2f70a54e04288c5f4cd1e45a7bb520b36292911876cada7323198dd87a8b36950b95130022907a7fb7c4e9b2d5f6cca685a587b4b21f4b888e4e7edb0d",
+			opts: &Options{Context: "foo"},
+		},
+		{
+			name: "Ed25519ph",
+			key:  "833fe62409237b9d62ec77587520911e9a759cec1d19755b7da901b96dca3d42",
+			msg:  "616263",
+			sig:  "98a70222f0b8121aa9d30f813d683f809e462b469c7ff87639499bb94e6dae4131f85042463c2a355a2003d062adf5aaa10b8c61e636062aaad11c2a26083406",
+			opts: &Options{Hash: crypto.SHA512},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			priv, err := NewKeyFromSeed(mustHex(t, tc.key))
+			if err != nil {
+				t.Fatal(err)
+			}
+			pub := priv.Public().(PublicKey)
+			msg := mustHex(t, tc.msg)
+			if tc.opts.Hash == crypto.SHA512 {
+				d := sha512.Sum512(msg)
+				msg = d[:]
+			}
+
+			sig, err := priv.Sign(nil, msg, tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(sig, mustHex(t, tc.sig)) {
+				t.Fatalf("签名不一致: %x", sig)
+			}
+			if err := VerifyWithOptions(pub, msg, sig, tc.opts); err != nil {
+				t.Fatalf("有效签名验证失败: %v", err)
+			}
+			if err := VerifyWithOptions(pub, msg, sig, &Options{Hash: tc.opts.Hash, Context: "bar"}); err != eddsa.ErrVerification {
+				t.Fatalf("上下文不同的签名期望ErrVerification，实际: %v", err)
+			}
+			if Verify(pub, msg, sig) {
+				t.Fatal("变体签名不应被纯Ed25519接受")
+			}
+		})
+	}
+}
+
+// 与crypto/ed25519对比随机密钥和消息的签名，包括Ed25519ctx和Ed25519ph
+func TestStdlibInterop(t *testing.T) {
+	for i := range 32 {
+		seed := make([]byte, SeedSize)
+		msg := make([]byte, i*7)
+		rand.Read(seed)
+		rand.Read(msg)
+		digest := sha512.Sum512(msg)
+
+		priv, err := NewKeyFromSeed(seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdPriv := stded25519.NewKeyFromSeed(seed)
+		if !bytes.Equal(priv, stdPriv) {
+			t.Fatal("私钥与crypto/ed25519不一致")
+		}
+
+		for _, tc := range []struct {
+			msg  []byte
+			opts *Options
+			std  *stded25519.Options
+		}{
+			{msg, &Options{}, &stded25519.Options{}},
+			{msg, &Options{Context: "context"}, &stded25519.Options{Context: "context"}},
+			{digest[:], &Options{Hash: crypto.SHA512}, &stded25519.Options{Hash: crypto.SHA512}},
+			{digest[:], &Options{Hash: crypto.SHA512, Context: "context"}, &stded25519.Options{Hash: crypto.SHA512, Context: "context"}},
+		} {
+			sig, err := priv.Sign(nil, tc.msg, tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := stdPriv.Sign(nil, tc.msg, tc.std)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(sig, want) {
+				t.Fatalf("签名与crypto/ed25519不一致（%+v）", tc.opts)
+			}
+			if err := VerifyWithOptions(priv.Public().(PublicKey), tc.msg, sig, tc.opts); err != nil {
+				t.Fatalf("验证失败（%+v）: %v", tc.opts, err)
+			}
+		}
+	}
+}
+
+// S不小于L的签名必须被拒绝（RFC 8032第5.1.7节），否则签名可延展
+func TestMalleability(t *testing.T) {
+	msg := []byte{0x54, 0x65, 0x73, 0x74}
+	sig := mustHex(t, "7c38e026f29e14aabd059a0f2db8b0cd783040609a8be684db12f82a27774ab0"+
+		"67654bce3832c2d76f8f6f5dafc08d9339d4eef676573336a5c51eb6f946b31d")
+	pub := mustHex(t, "7d4d0e7f6153a69b6242b522abbee685fda4420f8834b108c3bdae369ef549fa")
+	if Verify(pub, msg, sig) {
+		t.Fatal("S不小于L的签名验证成功")
+	}
+}
+
+// 基点阶L乘以基点应得到单位元
+func TestOrder(t *testing.T) {
+	identity := make([]byte, 32)
+	identity[0] = 1
+	if got := curve.Group.ScalarBaseMult(curve.Order); !bytes.Equal(got, identity) {
+		t.Fatalf("[L]B = %x", got)
+	}
+}
+
+func TestBatchVerify(t *testing.T) {
+	v := NewBatchVerifier()
+	if !v.Verify(nil) {
+		t.Fatal("空批次应验证成功")
+	}
+
+	type entry struct {
+		pub      PublicKey
+		msg, sig []byte
+	}
+	var entries []entry
+	for i := range 16 {
+		pub, priv, err := GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := []byte{byte(i)}
+		sig, err := Sign(priv, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry{pub, msg, sig})
+		v.Add(pub, msg, sig)
+	}
+	if v.Len() != len(entries) {
+		t.Fatalf("Len() = %d", v.Len())
+	}
+	if !v.Verify(nil) {
+		t.Fatal("有效签名的批次验证失败")
+	}
+
+	// 任一签名、消息或公钥错误都使整个批次失败
+	for _, corrupt := range []func(e *entry){
+		func(e *entry) { e.sig = bytes.Clone(e.sig); e.sig[0] ^= 1 },
+		func(e *entry) { e.sig = bytes.Clone(e.sig); e.sig[40] ^= 1 },
+		func(e *entry) { e.msg = []byte("other") },
+		func(e *entry) { e.pub = entries[0].pub },
+		func(e *entry) { e.sig = e.sig[:63] },
+		func(e *entry) { e.sig = bytes.Clone(e.sig); e.sig[63] |= 0xf0 },
+	} {
+		v := NewBatchVerifier()
+		for i, e := range entries {
+			if i == 5 {
+				corrupt(&e)
+			}
+			v.Add(e.pub, e.msg, e.sig)
+		}
+		if v.Verify(nil) {
+			t.Fatal("包含无效签名的批次验证成功")
+		}
+	}
+
+	// 读取随机数失败时返回false
+	v = NewBatchVerifier()
+	v.Add(entries[0].pub, entries[0].msg, entries[0].sig)
+	if v.Verify(bytes.NewReader(nil)) {
+		t.Fatal("随机数读取失败时应返回false")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := NewKeyFromSeed(make([]byte, 31)); err != eddsa.ErrInvalidSeed {
+		t.Fatalf("种子长度错误期望ErrInvalidSeed，实际: %v", err)
+	}
+	if _, err := Sign(make(PrivateKey, 63), nil); err != eddsa.ErrInvalidPrivateKey {
+		t.Fatalf("私钥长度错误期望ErrInvalidPrivateKey，实际: %v", err)
+	}
+
+	pub, priv, err := GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256(nil)
+	if _, err := priv.Sign(nil, digest[:], crypto.SHA256); err != ErrUnsupportedHash {
+		t.Fatalf("SHA-256期望ErrUnsupportedHash，实际: %v", err)
+	}
+	if _, err := priv.Sign(nil, digest[:], crypto.SHA512); err != ErrInvalidDigest {
+		t.Fatalf("Ed25519ph摘要长度错误期望ErrInvalidDigest，实际: %v", err)
+	}
+	long := string(make([]byte, 256))
+	if _, err := priv.Sign(nil, nil, &Options{Context: long}); !errors.Is(err, eddsa.ErrContextTooLong) {
+		t.Fatalf("上下文过长期望ErrContextTooLong，实际: %v", err)
+	}
+	if err := VerifyWithOptions(pub, nil, make([]byte, SignatureSize), &Options{Context: long}); err != eddsa.ErrContextTooLong {
+		t.Fatalf("上下文过长期望ErrContextTooLong，实际: %v", err)
+	}
+
+	// 公钥不是有效的点编码、y不小于p或签名长度错误
+	sig, _ := Sign(priv, nil)
+	badPub := bytes.Repeat([]byte{0xff}, 32)
+	badPub[31] = 0x7f
+	for _, tc := range []struct {
+		pub PublicKey
+		sig []byte
+	}{
+		{badPub, sig},
+		{pub[:31], sig},
+		{pub, sig[:63]},
+		{pub, append(bytes.Clone(sig), 0)},
+	} {
+		if Verify(tc.pub, nil, tc.sig) {
+			t.Fatalf("无效输入验证成功: %x %x", tc.pub, tc.sig)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	pub, priv, _ := GenerateKey(nil)
+	if !pub.Equal(priv.Public()) || !priv.Equal(priv) {
+		t.Fatal("相同的密钥应相等")
+	}
+	otherPub, otherPriv, _ := GenerateKey(nil)
+	if pub.Equal(otherPub) || priv.Equal(otherPriv) {
+		t.Fatal("不同的密钥不应相等")
+	}
+	if !bytes.Equal(priv.Seed(), priv[:SeedSize]) {
+		t.Fatal("Seed返回值错误")
+	}
+}
+
+func BenchmarkSign(b *testing.B) {
+	_, priv, _ := GenerateKey(nil)
+	msg := []byte("benchmark")
+	for b.Loop() {
+		Sign(priv, msg)
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	pub, priv, _ := GenerateKey(nil)
+	msg := []byte("benchmark")
+	sig, _ := Sign(priv, msg)
+	for b.Loop() {
+		Verify(pub, msg, sig)
+	}
+}
+
+// 每次迭代批量验证64个签名，与64次BenchmarkVerify对比
+func BenchmarkBatchVerify64(b *testing.B) {
+	v := NewBatchVerifier()
+	for range 64 {
+		pub, priv, _ := GenerateKey(nil)
+		msg := []byte("benchmark")
+		sig, _ := Sign(priv, msg)
+		v.Add(pub, msg, sig)
+	}
+	for b.Loop() {
+		if !v.Verify(nil) {
+			b.Fatal("批量验证失败")
+		}
+	}
+}
@@ -0,0 +1,222 @@
+// Package ed448 实现RFC 8032的Ed448签名（包括Ed448ph）以及批量验证
+//
+// Ed448的安全强度约为224位，高于Ed25519的128位。私钥为57字节种子与57字节公钥的拼接，
+// 签名为114字节，格式与RFC 8032和OpenSSL一致。签名流程由eddsa包实现，
+// 群运算使用常量时间的edwards448实现
+package ed448
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha3"
+	"io"
+
+	"github.com/laenix/gsc/eddsa"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/internal/edwards448"
+)
+
+// 错误定义
+var (
+	ErrUnsupportedHash = gscerr.New(gscerr.ErrUnsupported, "ed448: expected opts.HashFunc() to be zero")
+	ErrInvalidDigest   = gscerr.New(gscerr.ErrParameter, "ed448: Ed448ph message must be a 64-byte SHAKE256 digest")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrUnsupportedHash: "ed448: opts.HashFunc()必须为0",
+		ErrInvalidDigest:   "ed448: Ed448ph的消息必须是64字节的SHAKE256摘要",
+	})
+}
+
+// 密钥和签名大小（字节）
+const (
+	// PublicKeySize 是公钥的长度
+	PublicKeySize = 57
+	// PrivateKeySize 是私钥的长度：种子 || 公钥
+	PrivateKeySize = 114
+	// SignatureSize 是签名的长度：R || S
+	SignatureSize = 114
+	// SeedSize 是私钥种子的长度（RFC 8032中的私钥）
+	SeedSize = 57
+	// DigestSize 是Ed448ph签名的摘要SHAKE256(M, 64)的长度
+	DigestSize = 64
+)
+
+// curve 是Ed448的参数，基点阶L = 2^446 - 13818066809895115352007386748515426880336692474882178609894547503885
+var curve = &eddsa.Curve{
+	Size: 57,
+	Order: []byte{
+		0xf3, 0x44, 0x58, 0xab, 0x92, 0xc2, 0x78, 0x23,
+		0x55, 0x8f, 0xc5, 0x8d, 0x72, 0xc2, 0x6c, 0x21,
+		0x90, 0x36, 0xd6, 0xae, 0x49, 0xdb, 0x4e, 0xc4,
+		0xe9, 0x23, 0xca, 0x7c, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x3f,
+		0x00,
+	},
+	Hash:     hash,
+	Clamp:    clamp,
+	DomLabel: "SigEd448",
+	Group:    edwards448.Group{},
+}
+
+// hash 计算SHAKE256(data[0] || data[1] || ..., 114)
+func hash(data ...[]byte) []byte {
+	h := sha3.NewSHAKE256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	out := make([]byte, 2*SeedSize)
+	h.Read(out)
+	return out
+}
+
+// clamp 清除最低2位和最后一个字节，置位第447位（RFC 8032第5.2.5节）
+func clamp(s []byte) {
+	s[0] &= 252
+	s[56] = 0
+	s[55] |= 128
+}
+
+// PublicKey 是Ed448公钥
+type PublicKey []byte
+
+// Equal 判断pub和x是否是相同的公钥
+func (pub PublicKey) Equal(x crypto.PublicKey) bool {
+	xx, ok := x.(PublicKey)
+	return ok && bytes.Equal(pub, xx)
+}
+
+// PrivateKey 是Ed448私钥，实现crypto.Signer
+type PrivateKey []byte
+
+// Public 返回私钥对应的公钥
+func (priv PrivateKey) Public() crypto.PublicKey {
+	return PublicKey(bytes.Clone(priv[SeedSize:]))
+}
+
+// Equal 判断priv和x是否是相同的私钥
+func (priv PrivateKey) Equal(x crypto.PrivateKey) bool {
+	xx, ok := x.(PrivateKey)
+	return ok && bytes.Equal(priv, xx)
+}
+
+// Seed 返回私钥种子，可用NewKeyFromSeed恢复私钥
+func (priv PrivateKey) Seed() []byte {
+	return bytes.Clone(priv[:SeedSize])
+}
+
+// Sign 实现crypto.Signer。opts.HashFunc()必须为0（SHAKE256没有对应的crypto.Hash）；
+// opts为*Options时按其中的设置选择Ed448ph和上下文。Ed448是确定性的，rand被忽略
+func (priv PrivateKey) Sign(rand io.Reader, message []byte, opts crypto.SignerOpts) ([]byte, error) {
+	o, ok := opts.(*Options)
+	if !ok {
+		if opts.HashFunc() != 0 {
+			return nil, ErrUnsupportedHash
+		}
+		o = &Options{}
+	}
+	eo, err := o.eddsa(message)
+	if err != nil {
+		return nil, err
+	}
+	return curve.Sign(priv, message, eo)
+}
+
+// Options 选择Ed448的变体，实现crypto.SignerOpts
+type Options struct {
+	// PreHashed 为true时使用Ed448ph，消息须为SHAKE256(M, 64)的64字节摘要，
+	// 可用sha3.SumSHAKE256(M, DigestSize)计算
+	PreHashed bool
+	// Context 是上下文字符串，最长255字节
+	Context string
+}
+
+// HashFunc 返回0
+func (o *Options) HashFunc() crypto.Hash {
+	return 0
+}
+
+// eddsa 转换为eddsa.Options并检查消息长度
+func (o *Options) eddsa(message []byte) (*eddsa.Options, error) {
+	if o.PreHashed && len(message) != DigestSize {
+		return nil, ErrInvalidDigest
+	}
+	return &eddsa.Options{PreHashed: o.PreHashed, Context: []byte(o.Context)}, nil
+}
+
+// GenerateKey 生成密钥对，random为nil时使用crypto/rand
+func GenerateKey(random io.Reader) (PublicKey, PrivateKey, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	seed := make([]byte, SeedSize)
+	if _, err := io.ReadFull(random, seed); err != nil {
+		return nil, nil, err
+	}
+	priv, err := NewKeyFromSeed(seed)
+	if err != nil {
+		return nil, nil, err
+	}
+	return priv.Public().(PublicKey), priv, nil
+}
+
+// NewKeyFromSeed 由57字节种子计算私钥，种子长度错误时返回eddsa.ErrInvalidSeed
+func NewKeyFromSeed(seed []byte) (PrivateKey, error) {
+	priv, err := curve.NewKeyFromSeed(seed)
+	if err != nil {
+		return nil, err
+	}
+	return PrivateKey(priv), nil
+}
+
+// Sign 签名消息（上下文为空的Ed448），私钥长度错误时返回eddsa.ErrInvalidPrivateKey
+func Sign(priv PrivateKey, message []byte) ([]byte, error) {
+	return curve.Sign(priv, message, nil)
+}
+
+// Verify 验证上下文为空的Ed448签名
+func Verify(pub PublicKey, message, sig []byte) bool {
+	return curve.Verify(pub, message, sig, nil) == nil
+}
+
+// VerifyWithOptions 按opts指定的变体验证签名，签名无效时返回eddsa.ErrVerification
+func VerifyWithOptions(pub PublicKey, message, sig []byte, opts *Options) error {
+	eo, err := opts.eddsa(message)
+	if err != nil {
+		return err
+	}
+	return curve.Verify(pub, message, sig, eo)
+}
+
+// BatchVerifier 批量验证多个Ed448签名，签名较多时明显快于逐个调用Verify
+// 批量验证乘以余因子4，只有小阶分量不同的刻意构造的签名会被批量验证接受而被Verify拒绝；
+// Verify返回false时需要逐个验证才能找出无效的签名
+type BatchVerifier struct {
+	v *eddsa.BatchVerifier
+}
+
+// NewBatchVerifier 创建批量验证器
+func NewBatchVerifier() *BatchVerifier {
+	return &BatchVerifier{v: curve.NewBatchVerifier()}
+}
+
+// Add 添加一个待验证的签名
+func (b *BatchVerifier) Add(pub PublicKey, message, sig []byte) {
+	b.v.Add(pub, message, sig, nil)
+}
+
+// Len 返回已添加的签名数
+func (b *BatchVerifier) Len() int {
+	return b.v.Len()
+}
+
+// Verify 在所有签名都有效时返回true，random用于生成随机系数，为nil时使用crypto/rand
+func (b *BatchVerifier) Verify(random io.Reader) bool {
+	return b.v.Verify(random)
+}
@@ -0,0 +1,287 @@
+package ed448
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha3"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/laenix/gsc/eddsa"
+	"github.com/laenix/gsc/vectors"
+)
+
+func mustHex(t testing.TB, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// 测试RFC 8032第7.4节的空消息用例和OpenSSL生成的测试向量
+func TestVectors(t *testing.T) {
+	vectors.Run(t, "testdata/sign.rsp", func(t *testing.T, c *vectors.Case) {
+		seed, err := c.Hex("Seed")
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantPub, err := c.Hex("PK")
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg, err := c.Hex("Msg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantSig, err := c.Hex("Sig")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		priv, err := NewKeyFromSeed(seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub := priv.Public().(PublicKey)
+		if !bytes.Equal(pub, wantPub) {
+			t.Fatalf("公钥不一致: %x", pub)
+		}
+		sig, err := Sign(priv, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sig, wantSig) {
+			t.Fatalf("签名不一致: %x", sig)
+		}
+		if !Verify(pub, msg, sig) {
+			t.Fatal("有效签名验证失败")
+		}
+		sig[0] ^= 1
+		if Verify(pub, msg, sig) {
+			t.Fatal("篡改的签名验证成功")
+		}
+	})
+}
+
+// 测试RFC 8032第7.4节带上下文的用例和第7.5节（Ed448ph）的用例
+func TestVariants(t *testing.T) {
+	tests := []struct {
+		name, key, msg, sig string
+		opts                *Options
+	}{
+		{
+			name: "Ed448ctx",
+			key:  "c4eab05d357007c632f3dbb48489924d552b08fe0c353a0d4a1f00acda2c463afbea67c5e8d2877c5e3bc397a659949ef8021e954e0a12274e",
+			msg:  "03",
+			sig: "d4f8f6131770dd46f40867d6fd5d5055de43541f8c5e35abbcd001b32a89f7d2151f7647f11d8ca2ae279fb842d607217fce6e042f6815ea00" +
+				"0c85741de5c8da1144a6a1aba7f96de42505d7a7298524fda538fccbbb754f578c1cad10d54d0d5428407e85dcbc98a49155c13764e66c3c00",
+			opts: &Options{Context: "foo"},
+		},
+		{
+			name: "Ed448ph",
+			key:  "833fe62409237b9d62ec77587520911e9a759cec1d19755b7da901b96dca3d42ef7822e0d5104127dc05d6dbefde69e3ab2cec7c867c6e2c49",
+			msg:  "616263",
+			sig: "822f6901f7480f3d5f562c592994d9693602875614483256505600bbc281ae381f54d6bce2ea911574932f52a4e6cadd78769375ec3ffd1b80" +
+				"1a0d9b3f4030cd433964b6457ea39476511214f97469b57dd32dbc560a9a94d00bff07620464a3ad203df7dc7ce360c3cd3696d9d9fab90f00",
+			opts: &Options{PreHashed: true},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			priv, err := NewKeyFromSeed(mustHex(t, tc.key))
+			if err != nil {
+				t.Fatal(err)
+			}
+			pub := priv.Public().(PublicKey)
+			msg := mustHex(t, tc.msg)
+			if tc.opts.PreHashed {
+				msg = sha3.SumSHAKE256(msg, DigestSize)
+			}
+
+			sig, err := priv.Sign(nil, msg, tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(sig, mustHex(t, tc.sig)) {
+				t.Fatalf("签名不一致: %x", sig)
+			}
+			if err := VerifyWithOptions(pub, msg, sig, tc.opts); err != nil {
+				t.Fatalf("有效签名验证失败: %v", err)
+			}
+			if err := VerifyWithOptions(pub, msg, sig, &Options{PreHashed: tc.opts.PreHashed, Context: "bar"}); err != eddsa.ErrVerification {
+				t.Fatalf("上下文不同的签名期望ErrVerification，实际: %v", err)
+			}
+			if Verify(pub, msg, sig) {
+				t.Fatal("变体签名不应被纯Ed448接受")
+			}
+		})
+	}
+}
+
+// S不小于L的签名必须被拒绝（RFC 8032第5.2.7节），否则签名可延展
+func TestMalleability(t *testing.T) {
+	pub, priv, err := GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("malleability")
+	sig, err := Sign(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// S + L与S对应同一个点，但编码不规范
+	var carry uint16
+	for i := range SeedSize {
+		sum := uint16(sig[SeedSize+i]) + uint16(curve.Order[i]) + carry
+		sig[SeedSize+i] = byte(sum)
+		carry = sum >> 8
+	}
+	if Verify(pub, msg, sig) {
+		t.Fatal("S不小于L的签名验证成功")
+	}
+}
+
+// 基点阶L乘以基点应得到单位元
+func TestOrder(t *testing.T) {
+	identity := make([]byte, 57)
+	identity[0] = 1
+	if got := curve.Group.ScalarBaseMult(curve.Order); !bytes.Equal(got, identity) {
+		t.Fatalf("[L]B = %x", got)
+	}
+}
+
+func TestBatchVerify(t *testing.T) {
+	v := NewBatchVerifier()
+	if !v.Verify(nil) {
+		t.Fatal("空批次应验证成功")
+	}
+
+	type entry struct {
+		pub      PublicKey
+		msg, sig []byte
+	}
+	var entries []entry
+	for i := range 16 {
+		pub, priv, err := GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := []byte{byte(i)}
+		sig, err := Sign(priv, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry{pub, msg, sig})
+		v.Add(pub, msg, sig)
+	}
+	if v.Len() != len(entries) {
+		t.Fatalf("Len() = %d", v.Len())
+	}
+	if !v.Verify(nil) {
+		t.Fatal("有效签名的批次验证失败")
+	}
+
+	// 任一签名、消息或公钥错误都使整个批次失败
+	for _, corrupt := range []func(e *entry){
+		func(e *entry) { e.sig = bytes.Clone(e.sig); e.sig[0] ^= 1 },
+		func(e *entry) { e.sig = bytes.Clone(e.sig); e.sig[70] ^= 1 },
+		func(e *entry) { e.msg = []byte("other") },
+		func(e *entry) { e.pub = entries[0].pub },
+		func(e *entry) { e.sig = e.sig[:113] },
+		func(e *entry) { e.sig = bytes.Clone(e.sig); e.sig[113] = 0xff },
+	} {
+		v := NewBatchVerifier()
+		for i, e := range entries {
+			if i == 5 {
+				corrupt(&e)
+			}
+			v.Add(e.pub, e.msg, e.sig)
+		}
+		if v.Verify(nil) {
+			t.Fatal("包含无效签名的批次验证成功")
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := NewKeyFromSeed(make([]byte, 32)); err != eddsa.ErrInvalidSeed {
+		t.Fatalf("种子长度错误期望ErrInvalidSeed，实际: %v", err)
+	}
+	if _, err := Sign(make(PrivateKey, 64), nil); err != eddsa.ErrInvalidPrivateKey {
+		t.Fatalf("私钥长度错误期望ErrInvalidPrivateKey，实际: %v", err)
+	}
+
+	pub, priv, err := GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := priv.Sign(nil, make([]byte, 64), crypto.SHA512); err != ErrUnsupportedHash {
+		t.Fatalf("SHA-512期望ErrUnsupportedHash，实际: %v", err)
+	}
+	if _, err := priv.Sign(nil, make([]byte, 32), &Options{PreHashed: true}); err != ErrInvalidDigest {
+		t.Fatalf("Ed448ph摘要长度错误期望ErrInvalidDigest，实际: %v", err)
+	}
+	long := string(make([]byte, 256))
+	if _, err := priv.Sign(nil, nil, &Options{Context: long}); !errors.Is(err, eddsa.ErrContextTooLong) {
+		t.Fatalf("上下文过长期望ErrContextTooLong，实际: %v", err)
+	}
+	if err := VerifyWithOptions(pub, nil, make([]byte, SignatureSize), &Options{Context: long}); err != eddsa.ErrContextTooLong {
+		t.Fatalf("上下文过长期望ErrContextTooLong，实际: %v", err)
+	}
+
+	// 公钥的y不小于p、末字节低7位非0或签名长度错误
+	sig, _ := Sign(priv, nil)
+	badPub := bytes.Repeat([]byte{0xff}, 57)
+	badPub[56] = 0
+	flagPub := bytes.Clone(pub)
+	flagPub[56] |= 0x01
+	for _, tc := range []struct {
+		pub PublicKey
+		sig []byte
+	}{
+		{badPub, sig},
+		{flagPub, sig},
+		{pub[:56], sig},
+		{pub, sig[:113]},
+		{pub, append(bytes.Clone(sig), 0)},
+	} {
+		if Verify(tc.pub, nil, tc.sig) {
+			t.Fatalf("无效输入验证成功: %x %x", tc.pub, tc.sig)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	pub, priv, _ := GenerateKey(nil)
+	if !pub.Equal(priv.Public()) || !priv.Equal(priv) {
+		t.Fatal("相同的密钥应相等")
+	}
+	otherPub, otherPriv, _ := GenerateKey(nil)
+	if pub.Equal(otherPub) || priv.Equal(otherPriv) {
+		t.Fatal("不同的密钥不应相等")
+	}
+	if !bytes.Equal(priv.Seed(), priv[:SeedSize]) {
+		t.Fatal("Seed返回值错误")
+	}
+}
+
+func BenchmarkSign(b *testing.B) {
+	_, priv, _ := GenerateKey(nil)
+	msg := []byte("benchmark")
+	for b.Loop() {
+		Sign(priv, msg)
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	pub, priv, _ := GenerateKey(nil)
+	msg := []byte("benchmark")
+	sig, _ := Sign(priv, msg)
+	for b.Loop() {
+		Verify(pub, msg, sig)
+	}
+}
@@ -1 +1,161 @@
+// Package eddsa 实现RFC 8032中与曲线无关的EdDSA流程：私钥扩展、确定性签名随机数、
+// dom2/dom4域分离、签名、验证和批量验证
+//
+// 具体曲线由Curve描述（编码长度、基点阶、哈希函数、标量修剪规则和群运算），
+// ed25519和ed448包在此基础上提供各自的密钥类型和API
 package eddsa
+
+import (
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/subtle"
+)
+
+// 错误定义
+var (
+	ErrInvalidSeed       = gscerr.New(gscerr.ErrKeySize, "eddsa: invalid seed length")
+	ErrInvalidPrivateKey = gscerr.New(gscerr.ErrKeySize, "eddsa: invalid private key length")
+	ErrContextTooLong    = gscerr.New(gscerr.ErrParameter, "eddsa: context longer than 255 bytes")
+	ErrVerification      = gscerr.New(gscerr.ErrVerification, "eddsa: invalid signature")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidSeed:       "eddsa: 私钥种子长度无效",
+		ErrInvalidPrivateKey: "eddsa: 私钥长度无效",
+		ErrContextTooLong:    "eddsa: 上下文超过255字节",
+		ErrVerification:      "eddsa: 签名无效",
+	})
+}
+
+// Group 是曲线素数阶子群上的运算，点和标量都使用RFC 8032的小端序编码
+type Group interface {
+	// ScalarBaseMult 以常量时间计算[s]B并编码，s为Curve.Size字节，可以不小于L
+	ScalarBaseMult(s []byte) []byte
+	// VarTimeDoubleScalarBaseMult 计算[s]B - [k]A并编码，A不是有效的点编码时返回false
+	VarTimeDoubleScalarBaseMult(k, a, s []byte) ([]byte, bool)
+	// VarTimeMultiScalarCheck 检查[h]([s]B + Σ[scalars[i]]points[i])是否为单位元，h为余因子；
+	// 任一点不是有效的编码时返回false
+	VarTimeMultiScalarCheck(s []byte, scalars, points [][]byte) bool
+}
+
+// Curve 描述EdDSA使用的曲线及其参数
+type Curve struct {
+	// Size 是公钥、私钥种子以及签名中R和S各自的字节数
+	Size int
+	// Order 是基点阶L的小端序编码
+	Order []byte
+	// Hash 计算各段数据拼接后的哈希值，输出2·Size字节
+	Hash func(data ...[]byte) []byte
+	// Clamp 修剪种子哈希值的前Size字节，得到秘密标量
+	Clamp func(s []byte)
+	// DomLabel 是域分离前缀dom2/dom4中的标签
+	DomLabel string
+	// DomOptional 为true时，不使用预哈希且上下文为空的签名省略域分离前缀（Ed25519），
+	// 否则总是加上前缀（Ed448）
+	DomOptional bool
+	// Group 是曲线上的群运算
+	Group Group
+}
+
+// Options 选择RFC 8032中的签名变体
+type Options struct {
+	// PreHashed 为true时使用HashEdDSA（如Ed25519ph），消息是调用方按曲线规定算出的摘要
+	PreHashed bool
+	// Context 是上下文字符串，最长255字节，签名和验证必须使用相同的值
+	Context []byte
+}
+
+// dom 返回域分离前缀 标签 || 预哈希标志 || 上下文长度 || 上下文
+func (c *Curve) dom(opts *Options) ([]byte, error) {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if len(o.Context) > 255 {
+		return nil, ErrContextTooLong
+	}
+	if c.DomOptional && !o.PreHashed && len(o.Context) == 0 {
+		return nil, nil
+	}
+	var flag byte
+	if o.PreHashed {
+		flag = 1
+	}
+	dom := append([]byte(c.DomLabel), flag, byte(len(o.Context)))
+	return append(dom, o.Context...), nil
+}
+
+// scalars 返回模L的运算
+func (c *Curve) scalars() *scalarField {
+	return newScalarField(c.Order, c.Size)
+}
+
+// expand 由种子计算秘密标量s和用于生成签名随机数的prefix
+func (c *Curve) expand(seed []byte) (s, prefix []byte) {
+	h := c.Hash(seed)
+	s, prefix = h[:c.Size], h[c.Size:]
+	c.Clamp(s)
+	return s, prefix
+}
+
+// NewKeyFromSeed 由Size字节的种子计算私钥 种子 || 公钥
+func (c *Curve) NewKeyFromSeed(seed []byte) ([]byte, error) {
+	if len(seed) != c.Size {
+		return nil, ErrInvalidSeed
+	}
+	s, _ := c.expand(seed)
+	priv := make([]byte, 0, 2*c.Size)
+	priv = append(priv, seed...)
+	return append(priv, c.Group.ScalarBaseMult(s)...), nil
+}
+
+// Sign 按RFC 8032签名，priv为NewKeyFromSeed返回的私钥，签名为 R || S
+func (c *Curve) Sign(priv, message []byte, opts *Options) ([]byte, error) {
+	if len(priv) != 2*c.Size {
+		return nil, ErrInvalidPrivateKey
+	}
+	dom, err := c.dom(opts)
+	if err != nil {
+		return nil, err
+	}
+	sf := c.scalars()
+	s, prefix := c.expand(priv[:c.Size])
+	pub := priv[c.Size:]
+
+	// r = H(dom || prefix || M) mod L，R = [r]B
+	r := sf.reduce(c.Hash(dom, prefix, message))
+	R := c.Group.ScalarBaseMult(r)
+
+	// k = H(dom || R || A || M) mod L，S = r + k·s mod L
+	k := sf.reduce(c.Hash(dom, R, pub, message))
+	S := sf.mulAdd(k, s, r)
+
+	sig := make([]byte, 0, 2*c.Size)
+	sig = append(sig, R...)
+	return append(sig, S...), nil
+}
+
+// Verify 验证签名，检查S < L和 [S]B - [k]A 的编码等于R（不乘余因子，与crypto/ed25519一致）
+// 签名无效时返回ErrVerification，选项无效时返回对应的错误
+func (c *Curve) Verify(pub, message, sig []byte, opts *Options) error {
+	dom, err := c.dom(opts)
+	if err != nil {
+		return err
+	}
+	if len(pub) != c.Size || len(sig) != 2*c.Size {
+		return ErrVerification
+	}
+	sf := c.scalars()
+	R, S := sig[:c.Size], sig[c.Size:]
+	if !sf.isCanonical(S) {
+		return ErrVerification
+	}
+	k := sf.reduce(c.Hash(dom, R, pub, message))
+	check, ok := c.Group.VarTimeDoubleScalarBaseMult(k, pub, S)
+	if !ok || subtle.ConstantTimeCompare(check, R) != 1 {
+		return ErrVerification
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package eddsa
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// scalarField 是模基点阶L的运算。标量在外部以小端序字节串表示，内部转换为64位字（低位在前）；
+// 所有运算的时间和访存模式只取决于操作数的长度，可以用于秘密标量
+type scalarField struct {
+	l    []uint64
+	size int
+}
+
+// newScalarField 由L的小端序编码构造，标量编码为size字节
+func newScalarField(order []byte, size int) *scalarField {
+	return &scalarField{l: bytesToWords(order), size: size}
+}
+
+// bytesToWords 将小端序字节串转换为字，不足一个字的部分补0
+func bytesToWords(b []byte) []uint64 {
+	w := make([]uint64, (len(b)+7)/8)
+	var buf [8]byte
+	for i := range w {
+		n := copy(buf[:], b[8*i:])
+		clear(buf[n:])
+		w[i] = binary.LittleEndian.Uint64(buf[:])
+	}
+	return w
+}
+
+// toBytes 将小于L的字转换为size字节的小端序编码
+func (f *scalarField) toBytes(w []uint64) []byte {
+	buf := make([]byte, max(8*len(w), f.size))
+	for i, x := range w {
+		binary.LittleEndian.PutUint64(buf[8*i:], x)
+	}
+	return buf[:f.size]
+}
+
+// reduceOnce 在x + carry·2^(64·len(x)) ≥ L时减去L，要求其小于2L
+func (f *scalarField) reduceOnce(x []uint64, carry uint64) {
+	var borrow uint64
+	for i := range x {
+		_, borrow = bits.Sub64(x[i], f.l[i], borrow)
+	}
+	// 有进位时必然不小于L，否则没有借位说明x ≥ L
+	mask := -(carry | (borrow ^ 1))
+	borrow = 0
+	for i := range x {
+		x[i], borrow = bits.Sub64(x[i], f.l[i]&mask, borrow)
+	}
+}
+
+// reduceWords 计算x mod L，从高位到低位逐位移入，运算时间只取决于x的长度
+func (f *scalarField) reduceWords(x []uint64) []uint64 {
+	out := make([]uint64, len(f.l))
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := 63; j >= 0; j-- {
+			var carry uint64
+			for k := range out {
+				out[k], carry = out[k]<<1|carry, out[k]>>63
+			}
+			out[0] |= (x[i] >> j) & 1
+			f.reduceOnce(out, carry)
+		}
+	}
+	return out
+}
+
+// reduce 计算x mod L，x为任意长度的小端序整数（如2·size字节的哈希值）
+func (f *scalarField) reduce(x []byte) []byte {
+	return f.toBytes(f.reduceWords(bytesToWords(x)))
+}
+
+// mulAdd 计算a·b + c mod L，a、b、c为任意长度的小端序整数
+func (f *scalarField) mulAdd(a, b, c []byte) []byte {
+	aw, bw, cw := bytesToWords(a), bytesToWords(b), bytesToWords(c)
+	t := make([]uint64, len(aw)+len(bw)+len(cw)+1)
+	copy(t, cw)
+	for i, x := range aw {
+		var carry uint64
+		for j, y := range bw {
+			hi, lo := bits.Mul64(x, y)
+			var cc uint64
+			lo, cc = bits.Add64(lo, t[i+j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, carry, 0)
+			hi += cc
+			t[i+j], carry = lo, hi
+		}
+		for k := i + len(bw); k < len(t); k++ {
+			t[k], carry = bits.Add64(t[k], carry, 0)
+		}
+	}
+	return f.toBytes(f.reduceWords(t))
+}
+
+// negate 计算-s mod L，s必须小于L
+func (f *scalarField) negate(s []byte) []byte {
+	sw := bytesToWords(s)
+	out := make([]uint64, len(f.l))
+	var borrow uint64
+	for i := range out {
+		var x uint64
+		if i < len(sw) {
+			x = sw[i]
+		}
+		out[i], borrow = bits.Sub64(f.l[i], x, borrow)
+	}
+	// s = 0时结果为L，约简为0
+	f.reduceOnce(out, 0)
+	return f.toBytes(out)
+}
+
+// isCanonical 判断s是size字节且小于L（RFC 8032第5.1.7节对S的检查）
+func (f *scalarField) isCanonical(s []byte) bool {
+	if len(s) != f.size {
+		return false
+	}
+	sw := bytesToWords(s)
+	var borrow uint64
+	for i, x := range sw {
+		var y uint64
+		if i < len(f.l) {
+			y = f.l[i]
+		}
+		_, borrow = bits.Sub64(x, y, borrow)
+	}
+	return borrow == 1
+}
@@ -0,0 +1,77 @@
+package eddsa
+
+import (
+	"crypto/rand"
+	"math/big"
+	"slices"
+	"testing"
+)
+
+// leToBig 将小端序编码转换为整数
+func leToBig(b []byte) *big.Int {
+	be := slices.Clone(b)
+	slices.Reverse(be)
+	return new(big.Int).SetBytes(be)
+}
+
+// bigToLE 将x编码为size字节的小端序串
+func bigToLE(x *big.Int, size int) []byte {
+	b := x.FillBytes(make([]byte, size))
+	slices.Reverse(b)
+	return b
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// 与math/big对比模L运算，L分别取Ed25519的阶和一个7个字长、编码为57字节的阶（与Ed448相同的形状）
+func TestScalarField(t *testing.T) {
+	l25519, _ := new(big.Int).SetString("1000000000000000000000000000000014def9dea2f79cd65812631a5cf5d3ed", 16)
+	l448 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 446), big.NewInt(0x1234567))
+	for _, tc := range []struct {
+		l    *big.Int
+		size int
+	}{
+		{l25519, 32},
+		{l448, 57},
+	} {
+		f := newScalarField(bigToLE(tc.l, tc.size), tc.size)
+		mod := func(x *big.Int) *big.Int { return x.Mod(x, tc.l) }
+		for range 100 {
+			wide := randomBytes(t, 2*tc.size)
+			if got := leToBig(f.reduce(wide)); got.Cmp(mod(leToBig(wide))) != 0 {
+				t.Fatalf("reduce(%x)结果错误", wide)
+			}
+
+			a, b, c := randomBytes(t, tc.size), randomBytes(t, 16), randomBytes(t, tc.size)
+			want := new(big.Int).Mul(leToBig(a), leToBig(b))
+			want.Add(want, leToBig(c))
+			if got := leToBig(f.mulAdd(a, b, c)); got.Cmp(mod(want)) != 0 {
+				t.Fatal("mulAdd结果错误")
+			}
+
+			s := f.reduce(wide)
+			want = new(big.Int).Neg(leToBig(s))
+			if got := leToBig(f.negate(s)); got.Cmp(mod(want)) != 0 {
+				t.Fatal("negate结果错误")
+			}
+			if !f.isCanonical(s) {
+				t.Fatal("小于L的标量应是规范的")
+			}
+		}
+
+		zero := make([]byte, tc.size)
+		if leToBig(f.negate(zero)).Sign() != 0 {
+			t.Fatal("-0应为0")
+		}
+		lMinus1 := bigToLE(new(big.Int).Sub(tc.l, big.NewInt(1)), tc.size)
+		if !f.isCanonical(lMinus1) || f.isCanonical(bigToLE(tc.l, tc.size)) || f.isCanonical(lMinus1[1:]) {
+			t.Fatal("isCanonical边界判断错误")
+		}
+	}
+}
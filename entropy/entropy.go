@@ -0,0 +1,253 @@
+package entropy
+
+import (
+	"crypto/rand"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+const (
+	// DefaultMinEntropy 是默认假设的每字节最小熵（比特）
+	DefaultMinEntropy = 4.0
+	// 自适应比例测试的窗口大小（SP 800-90B 4.4.2，非二值样本）
+	aptWindow = 512
+	// 启动测试使用的样本数（SP 800-90B 4.3）
+	startupSamples = 1024
+	// 误报概率 α = 2^-20
+	alphaExponent = 20
+)
+
+// 错误定义
+var (
+	ErrRepetitionCount    = gscerr.New(gscerr.ErrVerification, "entropy: repetition count test failed")
+	ErrAdaptiveProportion = gscerr.New(gscerr.ErrVerification, "entropy: adaptive proportion test failed")
+	ErrUnhealthy          = gscerr.New(gscerr.ErrVerification, "entropy: entropy source failed health tests")
+	ErrInvalidMinEntropy  = gscerr.New(gscerr.ErrParameter, "entropy: min-entropy must be in (0, 8]")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrRepetitionCount:    "entropy: 重复计数测试失败",
+		ErrAdaptiveProportion: "entropy: 自适应比例测试失败",
+		ErrUnhealthy:          "entropy: 熵源未通过健康测试",
+		ErrInvalidMinEntropy:  "entropy: 最小熵必须在(0, 8]之间",
+	})
+}
+
+// Source 是外部熵源（如硬件TRNG）需要实现的接口，每次读取返回原始样本字节
+type Source interface {
+	io.Reader
+}
+
+// HealthChecker 由带健康测试的熵源实现，供密钥生成等调用方确认熵源状态
+type HealthChecker interface {
+	// Healthy 熵源健康时返回nil，否则返回导致失败的错误
+	Healthy() error
+}
+
+// Reader 为熵源增加SP 800-90B连续健康测试：
+// 重复计数测试（RCT）和自适应比例测试（APT）。
+// 任一测试失败后Reader进入失败状态，此后所有读取都返回错误，不会再输出数据；
+// 开启WithRecovery后，失败状态下的下一次Read或Healthy重新执行启动测试，新的样本全部通过后恢复输出
+type Reader struct {
+	mu  sync.Mutex
+	src Source
+
+	rctCutoff int
+	aptCutoff int
+
+	// RCT状态
+	last    byte
+	repeats int
+	started bool
+
+	// APT状态
+	aptFirst byte
+	aptCount int
+	aptIndex int
+
+	err error
+	// recovery 为true时失败状态可以通过重新执行启动测试解除
+	recovery bool
+}
+
+// Default 是以crypto/rand为熵源、带连续健康测试的全局Reader
+// Default开启了WithRecovery：偶发的误报（α = 2^-20）不会让进程内所有使用Default的密钥生成永久失败，
+// 失败的那次读取仍然返回错误，之后重新通过启动测试才继续输出
+var Default = mustNew(rand.Reader, DefaultMinEntropy).WithRecovery(true)
+
+// New 以src为熵源创建带健康测试的Reader
+// minEntropy为熵源每字节样本的最小熵估计（比特），决定测试的截止值
+func New(src Source, minEntropy float64) (*Reader, error) {
+	if minEntropy <= 0 || minEntropy > 8 {
+		return nil, ErrInvalidMinEntropy
+	}
+	return &Reader{
+		src:       src,
+		rctCutoff: rctCutoff(minEntropy),
+		aptCutoff: aptCutoff(minEntropy),
+	}, nil
+}
+
+// mustNew 创建Reader，参数非法时panic，仅用于包级变量初始化
+func mustNew(src Source, minEntropy float64) *Reader {
+	r, err := New(src, minEntropy)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// WithRecovery 设置失败后是否允许通过重新执行启动测试恢复，应在共享Reader之前调用
+func (r *Reader) WithRecovery(enabled bool) *Reader {
+	r.recovery = enabled
+	return r
+}
+
+// Read 从熵源读取数据并对每个字节执行健康测试
+// 首次读取前会先对1024个样本执行启动测试并丢弃这些样本
+func (r *Reader) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.startup(); err != nil {
+		return 0, err
+	}
+
+	// 读取不足时已读出的字节没有经过健康测试，与测试失败一样清除后丢弃
+	n, err := io.ReadFull(r.src, p)
+	if err != nil {
+		clear(p[:n])
+		return 0, err
+	}
+	if err := r.test(p[:n]); err != nil {
+		clear(p[:n])
+		return 0, err
+	}
+	return n, nil
+}
+
+// Healthy 实现HealthChecker接口
+// 开启WithRecovery时，失败状态下会先尝试重新执行启动测试
+func (r *Reader) Healthy() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil && r.recovery {
+		return r.startup()
+	}
+	return r.err
+}
+
+// Reset 清除失败状态并重新执行启动测试，用于熵源故障排除后的恢复
+func (r *Reader) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reset()
+}
+
+// reset 清除失败状态和测试状态，下次读取时重新执行启动测试
+func (r *Reader) reset() {
+	r.err = nil
+	r.started = false
+	r.repeats = 0
+	r.aptIndex = 0
+}
+
+// startup 在首次读取前（以及开启恢复时失败之后）对1024个样本执行启动测试并丢弃这些样本
+// 调用方需持有r.mu
+func (r *Reader) startup() error {
+	if r.err != nil {
+		if !r.recovery {
+			return r.err
+		}
+		r.reset()
+	}
+	if r.started {
+		return nil
+	}
+	r.started = true
+	warmup := make([]byte, startupSamples)
+	if _, err := io.ReadFull(r.src, warmup); err != nil {
+		r.err = err
+		return err
+	}
+	return r.test(warmup)
+}
+
+// test 对样本依次执行RCT和APT，失败时锁定错误状态
+func (r *Reader) test(samples []byte) error {
+	for _, b := range samples {
+		// 重复计数测试
+		if r.repeats > 0 && b == r.last {
+			r.repeats++
+			if r.repeats >= r.rctCutoff {
+				r.err = ErrRepetitionCount
+				return r.err
+			}
+		} else {
+			r.last = b
+			r.repeats = 1
+		}
+
+		// 自适应比例测试
+		if r.aptIndex == 0 {
+			r.aptFirst = b
+			r.aptCount = 1
+		} else if b == r.aptFirst {
+			r.aptCount++
+			if r.aptCount >= r.aptCutoff {
+				r.err = ErrAdaptiveProportion
+				return r.err
+			}
+		}
+		r.aptIndex++
+		if r.aptIndex == aptWindow {
+			r.aptIndex = 0
+		}
+	}
+	return nil
+}
+
+// Require 确认random是健康的熵源
+// random为nil时返回Default；未实现HealthChecker或已失败时返回ErrUnhealthy
+func Require(random io.Reader) (io.Reader, error) {
+	if random == nil {
+		random = Default
+	}
+	checker, ok := random.(HealthChecker)
+	if !ok || checker.Healthy() != nil {
+		return nil, ErrUnhealthy
+	}
+	return random, nil
+}
+
+// rctCutoff 计算重复计数测试的截止值 C = 1 + ⌈α指数 / H⌉
+func rctCutoff(minEntropy float64) int {
+	return 1 + int(math.Ceil(alphaExponent/minEntropy))
+}
+
+// aptCutoff 计算自适应比例测试的截止值 C = 1 + CRITBINOM(W, 2^-H, 1-α)
+func aptCutoff(minEntropy float64) int {
+	p := math.Pow(2, -minEntropy)
+	target := 1 - math.Pow(2, -alphaExponent)
+
+	// 逐项累加二项分布概率，直到累计概率达到1-α
+	n := aptWindow
+	logP, logQ := math.Log(p), math.Log1p(-p)
+	cdf := 0.0
+	for k := 0; k <= n; k++ {
+		lgN, _ := math.Lgamma(float64(n + 1))
+		lgK, _ := math.Lgamma(float64(k + 1))
+		lgNK, _ := math.Lgamma(float64(n - k + 1))
+		cdf += math.Exp(lgN - lgK - lgNK + float64(k)*logP + float64(n-k)*logQ)
+		if cdf >= target {
+			return 1 + k
+		}
+	}
+	return n
+}
@@ -0,0 +1,192 @@
+package entropy
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+)
+
+// 截止值应与SP 800-90B表2（W=512）一致
+func TestCutoffs(t *testing.T) {
+	tests := []struct {
+		h   float64
+		apt int
+		rct int
+	}{
+		{0.5, 410, 41},
+		{1, 311, 21},
+		{2, 177, 11},
+		{4, 62, 6},
+		{8, 13, 4},
+	}
+	for _, tt := range tests {
+		if got := aptCutoff(tt.h); got != tt.apt {
+			t.Errorf("H=%v: APT截止值期望%d，实际%d", tt.h, tt.apt, got)
+		}
+		if got := rctCutoff(tt.h); got != tt.rct {
+			t.Errorf("H=%v: RCT截止值期望%d，实际%d", tt.h, tt.rct, got)
+		}
+	}
+}
+
+func TestHealthySource(t *testing.T) {
+	r, err := New(rand.Reader, DefaultMinEntropy)
+	if err != nil {
+		t.Fatalf("创建Reader失败: %v", err)
+	}
+	buf := make([]byte, 1<<16)
+	if _, err := r.Read(buf); err != nil {
+		t.Fatalf("读取失败: %v", err)
+	}
+	if err := r.Healthy(); err != nil {
+		t.Fatalf("crypto/rand应通过健康测试: %v", err)
+	}
+	if _, err := Require(r); err != nil {
+		t.Fatalf("Require失败: %v", err)
+	}
+}
+
+// stuckSource 模拟输出恒定值的故障熵源
+type stuckSource struct{}
+
+func (stuckSource) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0xAA
+	}
+	return len(p), nil
+}
+
+func TestStuckSource(t *testing.T) {
+	r, err := New(stuckSource{}, DefaultMinEntropy)
+	if err != nil {
+		t.Fatalf("创建Reader失败: %v", err)
+	}
+	buf := make([]byte, 32)
+	if _, err := r.Read(buf); !errors.Is(err, ErrRepetitionCount) {
+		t.Fatalf("期望ErrRepetitionCount，实际: %v", err)
+	}
+	// 失败状态被锁定，不再输出任何数据
+	if n, err := r.Read(buf); n != 0 || err == nil {
+		t.Fatalf("失败后仍输出了数据: n=%d, err=%v", n, err)
+	}
+	if _, err := Require(r); !errors.Is(err, ErrUnhealthy) {
+		t.Fatalf("期望ErrUnhealthy，实际: %v", err)
+	}
+}
+
+// biasedSource 模拟偏向某个值但不会连续重复的熵源
+type biasedSource struct{ i int }
+
+func (s *biasedSource) Read(p []byte) (int, error) {
+	for i := range p {
+		if s.i%2 == 0 {
+			p[i] = 0x00
+		} else {
+			p[i] = byte(s.i)
+		}
+		s.i++
+	}
+	return len(p), nil
+}
+
+func TestBiasedSource(t *testing.T) {
+	r, err := New(&biasedSource{}, DefaultMinEntropy)
+	if err != nil {
+		t.Fatalf("创建Reader失败: %v", err)
+	}
+	if _, err := r.Read(make([]byte, 32)); !errors.Is(err, ErrAdaptiveProportion) {
+		t.Fatalf("期望ErrAdaptiveProportion，实际: %v", err)
+	}
+}
+
+// 熵源在启动测试后提前结束：已读出的部分字节未经健康测试，不得返回
+func TestShortRead(t *testing.T) {
+	data := make([]byte, startupSamples+16)
+	rand.Read(data)
+	r, err := New(bytes.NewReader(data), DefaultMinEntropy)
+	if err != nil {
+		t.Fatalf("创建Reader失败: %v", err)
+	}
+	buf := make([]byte, 32)
+	n, err := r.Read(buf)
+	if n != 0 || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("期望0字节和io.ErrUnexpectedEOF，实际: n=%d, err=%v", n, err)
+	}
+	if !bytes.Equal(buf, make([]byte, len(buf))) {
+		t.Fatalf("未经测试的字节没有被清除: %x", buf)
+	}
+}
+
+// flakySource 前fail个字节输出恒定值，之后转发到crypto/rand，模拟偶发故障
+type flakySource struct{ fail int }
+
+func (s *flakySource) Read(p []byte) (int, error) {
+	n := min(len(p), s.fail)
+	for i := range n {
+		p[i] = 0xAA
+	}
+	s.fail -= n
+	rand.Read(p[n:])
+	return len(p), nil
+}
+
+// 测试偶发故障后的行为：默认锁定失败状态；开启恢复后失败的读取返回错误，
+// 之后重新通过启动测试即恢复输出，Default开启了恢复
+func TestRecovery(t *testing.T) {
+	buf := make([]byte, 32)
+
+	latched, _ := New(&flakySource{}, DefaultMinEntropy)
+	latched.Read(buf)
+	latched.src = &flakySource{fail: len(buf)}
+	if _, err := latched.Read(buf); !errors.Is(err, ErrRepetitionCount) {
+		t.Fatalf("期望ErrRepetitionCount，实际: %v", err)
+	}
+	if _, err := latched.Read(buf); !errors.Is(err, ErrRepetitionCount) || latched.Healthy() == nil {
+		t.Fatalf("未开启恢复时应保持失败状态: %v", err)
+	}
+
+	src := &flakySource{}
+	r, _ := New(src, DefaultMinEntropy)
+	r.WithRecovery(true)
+	r.Read(buf)
+	src.fail = len(buf)
+	if n, err := r.Read(buf); n != 0 || !errors.Is(err, ErrRepetitionCount) {
+		t.Fatalf("故障期间的读取应失败: n=%d, err=%v", n, err)
+	}
+	if err := r.Healthy(); err != nil {
+		t.Fatalf("故障消失后应恢复: %v", err)
+	}
+	src.fail = len(buf)
+	r.Read(buf)
+	if n, err := r.Read(buf); n != len(buf) || err != nil {
+		t.Fatalf("故障消失后读取失败: n=%d, err=%v", n, err)
+	}
+
+	// 故障持续存在时恢复失败，不输出数据
+	r.src = stuckSource{}
+	r.Reset()
+	if n, err := r.Read(buf); n != 0 || err == nil {
+		t.Fatalf("持续故障时输出了数据: n=%d, err=%v", n, err)
+	}
+	if err := r.Healthy(); !errors.Is(err, ErrRepetitionCount) {
+		t.Fatalf("持续故障时应不健康: %v", err)
+	}
+
+	if !Default.recovery {
+		t.Error("Default应开启恢复")
+	}
+}
+
+func TestRequire(t *testing.T) {
+	if _, err := Require(bytes.NewReader(make([]byte, 64))); !errors.Is(err, ErrUnhealthy) {
+		t.Fatalf("未做健康测试的熵源期望ErrUnhealthy，实际: %v", err)
+	}
+	if r, err := Require(nil); err != nil || r != Default {
+		t.Fatalf("nil应返回Default: %v", err)
+	}
+	if _, err := New(rand.Reader, 0); !errors.Is(err, ErrInvalidMinEntropy) {
+		t.Fatalf("期望ErrInvalidMinEntropy，实际: %v", err)
+	}
+}
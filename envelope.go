@@ -0,0 +1,333 @@
+package gsc
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/laenix/gsc/aes"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/modes"
+	"github.com/laenix/gsc/sm4"
+)
+
+// Algorithm 标识信封使用的AEAD算法
+type Algorithm uint8
+
+// 支持的信封算法
+const (
+	AES128GCM Algorithm = iota + 1
+	AES192GCM
+	AES256GCM
+	SM4GCM
+)
+
+const (
+	// EnvelopeVersion 是当前信封格式版本
+	// 版本2在头部中加入了密钥标识；版本1的信封仍可解开
+	EnvelopeVersion = 2
+	// 信封魔数
+	envelopeMagic = "GSCE"
+	// 版本1的固定头部长度：魔数(4) + 版本(1) + 算法(1) + 上下文长度(2)
+	envelopeFixedHeaderSize = 4 + 1 + 1 + 2
+	// 随机nonce长度
+	envelopeNonceSize = 12
+	// 上下文字符串最大长度
+	maxContextSize = 1<<16 - 1
+)
+
+// 错误定义
+var (
+	ErrUnsupportedAlgorithm = gscerr.New(gscerr.ErrUnsupported, "gsc: unsupported algorithm")
+	ErrInvalidKeySize       = gscerr.New(gscerr.ErrKeySize, "gsc: key size does not match the algorithm")
+	ErrInvalidEnvelope      = gscerr.New(gscerr.ErrMalformed, "gsc: invalid envelope format")
+	ErrUnsupportedVersion   = gscerr.New(gscerr.ErrUnsupported, "gsc: unsupported envelope version")
+	ErrContextMismatch      = gscerr.New(gscerr.ErrVerification, "gsc: envelope context does not match the expected purpose")
+	ErrContextTooLong       = gscerr.New(gscerr.ErrParameter, "gsc: context string too long")
+	ErrKeyIDTooLong         = gscerr.New(gscerr.ErrParameter, "gsc: key ID too long")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrUnsupportedAlgorithm: "gsc: 不支持的算法",
+		ErrInvalidKeySize:       "gsc: 密钥长度与算法不匹配",
+		ErrInvalidEnvelope:      "gsc: 信封格式无效",
+		ErrUnsupportedVersion:   "gsc: 不支持的信封版本",
+		ErrContextMismatch:      "gsc: 信封上下文与预期用途不一致",
+		ErrContextTooLong:       "gsc: 上下文字符串过长",
+		ErrKeyIDTooLong:         "gsc: 密钥标识过长",
+	})
+}
+
+// String 返回算法的规范名称
+func (a Algorithm) String() string {
+	switch a {
+	case AES128GCM:
+		return "AES-128-GCM"
+	case AES192GCM:
+		return "AES-192-GCM"
+	case AES256GCM:
+		return "AES-256-GCM"
+	case SM4GCM:
+		return "SM4-GCM"
+	}
+	return fmt.Sprintf("Algorithm(%d)", uint8(a))
+}
+
+// KeySize 返回算法要求的密钥长度（字节）
+func (a Algorithm) KeySize() int {
+	switch a {
+	case AES128GCM, SM4GCM:
+		return 16
+	case AES192GCM:
+		return 24
+	case AES256GCM:
+		return 32
+	}
+	return 0
+}
+
+// newAEAD 按算法构造GCM实例
+func (a Algorithm) newAEAD(key []byte) (*modes.GCM, error) {
+	if a.KeySize() == 0 {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	if len(key) != a.KeySize() {
+		return nil, ErrInvalidKeySize
+	}
+
+	var block modes.BlockCipher
+	var err error
+	if a == SM4GCM {
+		block, err = sm4.New(key)
+	} else {
+		block, err = aes.New(key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return modes.NewGCM(block)
+}
+
+// Header 是信封的明文头部
+type Header struct {
+	Version   uint8
+	Algorithm Algorithm
+	// KeyID 是加密密钥的标识（见KeyID函数），版本1的信封中为空
+	KeyID []byte
+	// Context 是规范化的上下文字符串，包含版本、算法和调用方的用途，
+	// 整体作为AAD参与认证，防止密文在不同上下文之间被重放
+	Context string
+	Nonce   []byte
+}
+
+// Context 返回给定算法与用途对应的规范上下文字符串
+// 格式：gsc/v<版本>/<算法名>/<用途>
+func Context(alg Algorithm, purpose string) string {
+	return versionContext(EnvelopeVersion, alg, purpose)
+}
+
+// versionContext 返回指定信封版本下的规范上下文字符串
+func versionContext(version uint8, alg Algorithm, purpose string) string {
+	return fmt.Sprintf("gsc/v%d/%s/%s", version, alg, purpose)
+}
+
+// Seal 使用指定算法加密明文并输出自描述信封
+// 版本、算法和purpose组成的规范上下文会写入头部，并与aad一起作为AAD参与认证，
+// 因此只有以相同purpose调用Open才能解开信封。头部中的密钥标识为KeyID(key)
+func Seal(alg Algorithm, key []byte, purpose string, plaintext, aad []byte) ([]byte, error) {
+	return SealWithKeyID(alg, key, nil, purpose, plaintext, aad)
+}
+
+// SealWithKeyID 与Seal相同，但使用调用方指定的密钥标识，
+// 例如包装密钥的KeyID或密钥库中的条目名称；keyID为nil时使用KeyID(key)
+func SealWithKeyID(alg Algorithm, key, keyID []byte, purpose string, plaintext, aad []byte) ([]byte, error) {
+	aead, err := alg.newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyID == nil {
+		keyID = KeyID(key)
+	}
+	if len(keyID) > maxKeyIDSize {
+		return nil, ErrKeyIDTooLong
+	}
+
+	context := Context(alg, purpose)
+	if len(context) > maxContextSize {
+		return nil, ErrContextTooLong
+	}
+
+	nonce := make([]byte, envelopeNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	header := marshalHeader(alg, keyID, context, nonce)
+	sealed, err := aead.Seal(nonce, plaintext, contextAAD(header, aad))
+	if err != nil {
+		return nil, err
+	}
+	return append(header, sealed...), nil
+}
+
+// Open 解开Seal生成的信封
+// purpose必须与加密时一致；头部中的上下文与预期不一致时返回ErrContextMismatch
+func Open(key []byte, purpose string, envelope, aad []byte) ([]byte, error) {
+	h, headerLen, err := parseHeader(envelope)
+	if err != nil {
+		return nil, err
+	}
+	if h.Context != versionContext(h.Version, h.Algorithm, purpose) {
+		return nil, ErrContextMismatch
+	}
+
+	aead, err := h.Algorithm.newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Open(h.Nonce, envelope[headerLen:], contextAAD(envelope[:headerLen], aad))
+}
+
+// TryOpen 依次用候选密钥尝试解开信封，返回成功密钥的下标和明文，用于密钥轮换场景
+// 头部带有密钥标识时，只尝试KeyID与之相同的密钥；没有密钥标识（版本1信封）
+// 或没有任何候选密钥的KeyID匹配（如使用SealWithKeyID自定义了标识）时尝试全部密钥。
+// 所有候选密钥都会计算KeyID，被选中的密钥无论哪个成功都会完成一次完整的解密尝试，
+// 使耗时不泄露匹配密钥的位置；全部失败时返回-1和modes.ErrAuthFailed。
+// 长度与算法不匹配的密钥视为失败，不会单独报错
+func TryOpen(keys [][]byte, purpose string, envelope, aad []byte) (int, []byte, error) {
+	h, headerLen, err := parseHeader(envelope)
+	if err != nil {
+		return -1, nil, err
+	}
+	if h.Context != versionContext(h.Version, h.Algorithm, purpose) {
+		return -1, nil, ErrContextMismatch
+	}
+
+	fullAAD := contextAAD(envelope[:headerLen], aad)
+	body := envelope[headerLen:]
+	candidates := matchKeyID(keys, h.KeyID)
+
+	index := -1
+	var plaintext []byte
+	for i, key := range keys {
+		if !candidates[i] {
+			continue
+		}
+		aead, err := h.Algorithm.newAEAD(key)
+		if err != nil {
+			continue
+		}
+		out, err := aead.WithUniformTiming(true).Open(h.Nonce, body, fullAAD)
+		if err != nil {
+			continue
+		}
+		if index < 0 {
+			index, plaintext = i, out
+		} else {
+			clear(out)
+		}
+	}
+
+	if index < 0 {
+		return -1, nil, modes.ErrAuthFailed
+	}
+	return index, plaintext, nil
+}
+
+// matchKeyID 返回每个密钥是否应当尝试解密
+func matchKeyID(keys [][]byte, keyID []byte) []bool {
+	candidates := make([]bool, len(keys))
+	found := false
+	for i, key := range keys {
+		if len(keyID) > 0 && subtle.ConstantTimeCompare(KeyID(key), keyID) == 1 {
+			candidates[i] = true
+			found = true
+		}
+	}
+	if !found {
+		for i := range candidates {
+			candidates[i] = true
+		}
+	}
+	return candidates
+}
+
+// ParseHeader 解析信封头部而不解密，可用于在解密前检查算法、密钥标识和上下文，
+// 或按KeyID从密钥库中查找解密密钥
+func ParseHeader(envelope []byte) (*Header, error) {
+	h, _, err := parseHeader(envelope)
+	return h, err
+}
+
+// marshalHeader 编码当前版本的信封头部：
+// 魔数 || 版本 || 算法 || 密钥标识长度(1) || 密钥标识 || 上下文长度(2) || 上下文 || nonce
+func marshalHeader(alg Algorithm, keyID []byte, context string, nonce []byte) []byte {
+	header := make([]byte, 0, envelopeFixedHeaderSize+1+len(keyID)+len(context)+len(nonce))
+	header = append(header, envelopeMagic...)
+	header = append(header, EnvelopeVersion, byte(alg))
+	header = append(header, byte(len(keyID)))
+	header = append(header, keyID...)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(context)))
+	header = append(header, context...)
+	return append(header, nonce...)
+}
+
+// parseHeader 解析信封头部，返回头部及其长度
+func parseHeader(envelope []byte) (*Header, int, error) {
+	if len(envelope) < envelopeFixedHeaderSize || !bytes.Equal(envelope[:4], []byte(envelopeMagic)) {
+		return nil, 0, ErrInvalidEnvelope
+	}
+	version := envelope[4]
+	if version != 1 && version != EnvelopeVersion {
+		return nil, 0, ErrUnsupportedVersion
+	}
+
+	alg := Algorithm(envelope[5])
+	if alg.KeySize() == 0 {
+		return nil, 0, ErrUnsupportedAlgorithm
+	}
+
+	// 版本2在上下文长度之前插入密钥标识
+	off := 6
+	var keyID []byte
+	if version == EnvelopeVersion {
+		keyIDLen := int(envelope[off])
+		off++
+		if len(envelope) < off+keyIDLen+2 {
+			return nil, 0, ErrInvalidEnvelope
+		}
+		keyID = envelope[off : off+keyIDLen]
+		off += keyIDLen
+	}
+
+	contextLen := int(binary.BigEndian.Uint16(envelope[off : off+2]))
+	off += 2
+	headerLen := off + contextLen + envelopeNonceSize
+	if len(envelope) < headerLen {
+		return nil, 0, ErrInvalidEnvelope
+	}
+
+	h := &Header{
+		Version:   version,
+		Algorithm: alg,
+		KeyID:     keyID,
+		Context:   string(envelope[off : off+contextLen]),
+		Nonce:     envelope[headerLen-envelopeNonceSize : headerLen],
+	}
+	return h, headerLen, nil
+}
+
+// contextAAD 构造实际参与认证的AAD：长度前缀的头部 || 调用方AAD
+// 头部中包含规范上下文，长度前缀保证两部分的边界无歧义
+func contextAAD(header, aad []byte) []byte {
+	out := make([]byte, 0, 4+len(header)+len(aad))
+	out = binary.BigEndian.AppendUint32(out, uint32(len(header)))
+	out = append(out, header...)
+	return append(out, aad...)
+}
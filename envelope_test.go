@@ -0,0 +1,159 @@
+package gsc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/laenix/gsc/modes"
+)
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	plaintext := []byte("信封测试数据")
+	aad := []byte("record-42")
+
+	for _, alg := range []Algorithm{AES128GCM, AES192GCM, AES256GCM, SM4GCM} {
+		key := bytes.Repeat([]byte{0x42}, alg.KeySize())
+		envelope, err := Seal(alg, key, "backup", plaintext, aad)
+		if err != nil {
+			t.Fatalf("%s: Seal失败: %v", alg, err)
+		}
+
+		h, err := ParseHeader(envelope)
+		if err != nil {
+			t.Fatalf("%s: 解析头部失败: %v", alg, err)
+		}
+		if h.Algorithm != alg || h.Context != "gsc/v2/"+alg.String()+"/backup" || !bytes.Equal(h.KeyID, KeyID(key)) {
+			t.Fatalf("%s: 头部不正确: %+v", alg, h)
+		}
+
+		opened, err := Open(key, "backup", envelope, aad)
+		if err != nil {
+			t.Fatalf("%s: Open失败: %v", alg, err)
+		}
+		if !bytes.Equal(opened, plaintext) {
+			t.Fatalf("%s: 解密结果不匹配", alg)
+		}
+	}
+}
+
+// 测试密文不能在不同用途或被篡改的头部之间重放
+func TestEnvelopeContextBinding(t *testing.T) {
+	key := bytes.Repeat([]byte{0x01}, 32)
+	envelope, err := Seal(AES256GCM, key, "session", []byte("secret"), nil)
+	if err != nil {
+		t.Fatalf("Seal失败: %v", err)
+	}
+
+	if _, err := Open(key, "backup", envelope, nil); !errors.Is(err, ErrContextMismatch) {
+		t.Errorf("用途不同时期望ErrContextMismatch，实际: %v", err)
+	}
+
+	if _, err := Open(key, "session", envelope, []byte("extra")); !errors.Is(err, modes.ErrAuthFailed) {
+		t.Errorf("AAD不同时期望ErrAuthFailed，实际: %v", err)
+	}
+
+	// 即使攻击者同时改写头部中的上下文，认证也会失败
+	forged := bytes.Replace(envelope, []byte("session"), []byte("archive"), 1)
+	if _, err := Open(key, "archive", forged, nil); !errors.Is(err, modes.ErrAuthFailed) {
+		t.Errorf("篡改上下文时期望ErrAuthFailed，实际: %v", err)
+	}
+}
+
+func TestEnvelopeInvalid(t *testing.T) {
+	if _, err := Seal(AES128GCM, make([]byte, 32), "", nil, nil); !errors.Is(err, ErrInvalidKeySize) {
+		t.Errorf("期望ErrInvalidKeySize，实际: %v", err)
+	}
+	if _, err := Seal(Algorithm(99), make([]byte, 16), "", nil, nil); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Errorf("期望ErrUnsupportedAlgorithm，实际: %v", err)
+	}
+	if _, err := ParseHeader([]byte("GSCE")); !errors.Is(err, ErrInvalidEnvelope) {
+		t.Errorf("期望ErrInvalidEnvelope，实际: %v", err)
+	}
+}
+
+func TestEnvelopeTryOpen(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{0x01}, 16)
+	newKey := bytes.Repeat([]byte{0x02}, 16)
+	envelope, err := Seal(SM4GCM, oldKey, "rotation", []byte("data"), nil)
+	if err != nil {
+		t.Fatalf("Seal失败: %v", err)
+	}
+
+	keys := [][]byte{newKey, make([]byte, 32), oldKey}
+	index, plaintext, err := TryOpen(keys, "rotation", envelope, nil)
+	if err != nil {
+		t.Fatalf("TryOpen失败: %v", err)
+	}
+	if index != 2 || !bytes.Equal(plaintext, []byte("data")) {
+		t.Fatalf("期望下标2和原始明文，实际: %d, %q", index, plaintext)
+	}
+
+	index, plaintext, err = TryOpen(keys[:2], "rotation", envelope, nil)
+	if !errors.Is(err, modes.ErrAuthFailed) || index != -1 || plaintext != nil {
+		t.Fatalf("没有匹配密钥时期望ErrAuthFailed，实际: %d, %v", index, err)
+	}
+
+	if _, _, err := TryOpen(keys, "other", envelope, nil); !errors.Is(err, ErrContextMismatch) {
+		t.Fatalf("期望ErrContextMismatch，实际: %v", err)
+	}
+
+	// 自定义密钥标识不匹配任何候选密钥时回退为逐个尝试
+	envelope, err = SealWithKeyID(SM4GCM, oldKey, []byte("hsm-slot-7"), "rotation", []byte("data"), nil)
+	if err != nil {
+		t.Fatalf("SealWithKeyID失败: %v", err)
+	}
+	if h, _ := ParseHeader(envelope); !bytes.Equal(h.KeyID, []byte("hsm-slot-7")) {
+		t.Fatalf("头部中的密钥标识不正确: %q", h.KeyID)
+	}
+	if index, _, err := TryOpen(keys, "rotation", envelope, nil); err != nil || index != 2 {
+		t.Fatalf("期望下标2，实际: %d, %v", index, err)
+	}
+}
+
+func TestKeyID(t *testing.T) {
+	// SM3(0x0048 || "gsc/keyid" || 16字节零)的前8字节
+	want, _ := hex.DecodeString("fc7bd5e15c9a4b69")
+	if got := KeyID(make([]byte, 16)); !bytes.Equal(got, want) {
+		t.Fatalf("KeyID不匹配:\n期望值: %x\n实际值: %x", want, got)
+	}
+
+	if _, err := SealWithKeyID(AES128GCM, make([]byte, 16), make([]byte, 256), "", nil, nil); !errors.Is(err, ErrKeyIDTooLong) {
+		t.Fatalf("期望ErrKeyIDTooLong，实际: %v", err)
+	}
+}
+
+// 测试仍能解开不带密钥标识的版本1信封
+func TestEnvelopeVersion1(t *testing.T) {
+	key := bytes.Repeat([]byte{0x07}, 16)
+	context := "gsc/v1/AES-128-GCM/legacy"
+	nonce := make([]byte, envelopeNonceSize)
+
+	header := []byte(envelopeMagic)
+	header = append(header, 1, byte(AES128GCM), 0, byte(len(context)))
+	header = append(header, context...)
+	header = append(header, nonce...)
+
+	aead, err := AES128GCM.newAEAD(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := aead.Seal(nonce, []byte("old data"), contextAAD(header, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope := append(header, sealed...)
+
+	h, err := ParseHeader(envelope)
+	if err != nil || h.Version != 1 || h.KeyID != nil {
+		t.Fatalf("版本1头部解析不正确: %+v, %v", h, err)
+	}
+	plaintext, err := Open(key, "legacy", envelope, nil)
+	if err != nil || !bytes.Equal(plaintext, []byte("old data")) {
+		t.Fatalf("版本1信封解密失败: %q, %v", plaintext, err)
+	}
+	if index, _, err := TryOpen([][]byte{make([]byte, 16), key}, "legacy", envelope, nil); err != nil || index != 1 {
+		t.Fatalf("期望下标1，实际: %d, %v", index, err)
+	}
+}
@@ -2,421 +2,280 @@ package examples
 
 import (
 	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"io"
+	"strconv"
 
+	"github.com/laenix/gsc"
 	"github.com/laenix/gsc/aes"
 	"github.com/laenix/gsc/blowfish"
 	"github.com/laenix/gsc/des"
+	"github.com/laenix/gsc/dump"
+	"github.com/laenix/gsc/gscrand"
+	"github.com/laenix/gsc/kdf/pbkdf2"
 	"github.com/laenix/gsc/modes"
 	"github.com/laenix/gsc/padding"
 	"github.com/laenix/gsc/twofish"
 )
 
+// Rand 是演示中生成IV、nonce和盐的随机源
+// golden测试将其替换为确定性随机源，使输出可以复现；实际应用中应保持crypto/rand.Reader
+var Rand io.Reader = rand.Reader
+
+// generator 返回从Rand读取的gscrand.Generator，演示中的IV和nonce都由它生成
+func generator() *gscrand.Generator {
+	return gscrand.New(Rand)
+}
+
 type Options struct {
-	// 迭代次数
+	// 迭代次数，与SaltValue、SaltPosition任一设置时，Key作为口令经KDF派生出工作密钥
 	Iterations int
-	// 加盐位置
+	// 加盐位置：为空时盐不写入密文，解密时须提供相同的SaltValue；
+	// "prefix"或"suffix"时盐写在密文之前或之后，SaltValue为空时加密随机生成16字节的盐
 	SaltPosition string
 	// 盐值
 	SaltValue []byte
+	// KDF 派生工作密钥的函数，为nil时使用PBKDF2-HMAC-SHA256
+	KDF func(password, salt []byte, iterations, keyLen int) ([]byte, error)
 	// key
 	Key []byte
-	// key2 3des
+	// key2 3des第二个子密钥（8字节原始密钥）
 	Key2 string
-	// key3 3des
+	// key3 3des第三个子密钥（8字节原始密钥）
 	Key3 string
-	// iv
+	// iv 为nil时加密随机生成IV并写在密文之前
 	Iv []byte
 	// mode aes/ecb/cbc/ctr/
 	Mode string
 	// padding pkcs7/pkcs5/none
 	Padding string
-	// random chacha20/chacha20poly1305/xsalsa20/xsalsa20poly1305
+	// random chacha20/chacha20poly1305/xsalsa20/xsalsa20poly1305，由Stream_Encrypt和Stream_Decrypt使用
 	Random string
 	// keylen aes 128/192/256
 	Keylen string
-	// usera
+	// usera 密钥协商发起方的身份标识（SM2密钥交换中为用户标识），见SessionOptions
 	UserA string
-	// userb
+	// userb 密钥协商响应方的身份标识
 	UserB string
 	// Block Size
 	BlockSize int
 }
 
 func Padding(plaintext []byte, opt *Options) ([]byte, error) {
-	paddedPlaintext := plaintext
-	var err error
-	// 填充
-	switch opt.Padding {
-	case "PKCS#7":
-		paddedPlaintext, err = padding.PKCS7Padding(plaintext, opt.BlockSize)
-	case "M1":
-		paddedPlaintext, err = padding.M1Padding(plaintext, opt.BlockSize)
-	case "M1(+0)":
-		paddedPlaintext, err = padding.M1PlusZeroPadding(plaintext, opt.BlockSize)
-	case "M2":
-		paddedPlaintext, err = padding.M2Padding(plaintext, opt.BlockSize)
-	case "ISO7816":
-		paddedPlaintext, err = padding.ISO7816Padding(plaintext, opt.BlockSize)
-	case "ANSIX923":
-		paddedPlaintext, err = padding.ANSIX923Padding(plaintext, opt.BlockSize)
-	case "Zero":
-		paddedPlaintext, err = padding.ZeroPadding(plaintext, opt.BlockSize)
-	case "PKCS#5":
-		paddedPlaintext, err = padding.PKCS5Padding(plaintext)
-	case "ISO10126":
-		paddedPlaintext, err = padding.ISO10126Padding(plaintext, opt.BlockSize)
-	case "TBC":
-		paddedPlaintext, err = padding.TBCPadding(plaintext, opt.BlockSize)
-	case "None":
-		paddedPlaintext = plaintext
+	pad, _, err := padding.Get(opt.Padding)
+	if err != nil {
+		return nil, fmt.Errorf("不支持的填充方式: %s", opt.Padding)
 	}
+	return pad(plaintext, opt.BlockSize)
+}
 
+func Unpadding(plaintext []byte, opt *Options) ([]byte, error) {
+	_, unpad, err := padding.Get(opt.Padding)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("不支持的填充方式: %s", opt.Padding)
 	}
-	return paddedPlaintext, nil
+	return unpad(plaintext)
 }
 
 func AES_Encrypt(plaintext []byte, opt *Options) ([]byte, error) {
-	paddedPlaintext, err := Padding(plaintext, opt)
-	if err != nil {
-		return nil, err
-	}
-	// 创建AES实例
-	cipher, err := aes.New(opt.Key)
-	if err != nil {
-		return nil, err
-	}
-	// 判断大小
-	if len(paddedPlaintext)%opt.BlockSize != 0 {
-		return nil, fmt.Errorf("plaintext length must be a multiple of the block size")
-	}
-	// 创建分组模式实例
-	var ciphertext []byte
-	switch opt.Mode {
-	case "ECB":
-		ecb := modes.NewECB(cipher)
-		ciphertext, err = ecb.Encrypt(paddedPlaintext)
-		if err != nil {
-			return nil, err
-		}
-	case "CBC":
-		cbc, err := modes.NewCBC(cipher, opt.Iv)
-		if err != nil {
-			return nil, err
-		}
-		ciphertext, err = cbc.Encrypt(paddedPlaintext)
-		if err != nil {
-			return nil, err
-		}
-	case "CFB":
-		cfb, err := modes.NewCFB(cipher, opt.Iv)
-		if err != nil {
-			return nil, err
-		}
-		ciphertext, err = cfb.Encrypt(plaintext)
-		if err != nil {
-			return nil, err
-		}
-	case "OFB":
-		ofb, err := modes.NewOFB(cipher, opt.Iv)
-		if err != nil {
-			return nil, err
-		}
-		ciphertext, err = ofb.Encrypt(paddedPlaintext)
-		if err != nil {
-			return nil, err
-		}
-	case "CTR":
-		ctr, err := modes.NewCTR(cipher, opt.Iv)
-		if err != nil {
-			return nil, err
+	return suiteCrypt("AES", plaintext, opt, true)
+}
 
-		}
-		ciphertext, err = ctr.Encrypt(paddedPlaintext)
-		if err != nil {
-			return nil, err
-		}
-	case "GCM":
-		gcm, err := modes.NewGCM(cipher)
-		if err != nil {
-			return nil, err
-		}
-		// 随机生成的12字节Nonce（在实际应用中应该是随机的）
-		nonce := []byte("123456789012")
+func AES_Decrypt(ciphertext []byte, opt *Options) ([]byte, error) {
+	return suiteCrypt("AES", ciphertext, opt, false)
+}
 
-		// 附加验证数据（可选）
-		aad := []byte("附加验证数据")
-		ciphertext, err = gcm.Seal(nonce, plaintext, aad)
-		if err != nil {
-			return nil, err
-		}
+func DES_Encrypt(plaintext []byte, opt *Options) ([]byte, error) {
+	return suiteCrypt("DES", plaintext, opt, true)
+}
 
-	default:
-		return nil, fmt.Errorf("unsupported mode: %s", opt.Mode)
+func DES_Decrypt(ciphertext []byte, opt *Options) ([]byte, error) {
+	return suiteCrypt("DES", ciphertext, opt, false)
+}
 
+// suiteCrypt 按Options组装密码套件并加密或解密
+// CFB和CTR不填充；其余模式使用opt.Padding，与早期示例的输出保持一致
+func suiteCrypt(alg string, data []byte, opt *Options, encrypt bool) ([]byte, error) {
+	pad := opt.Padding
+	if opt.Mode == "CFB" || opt.Mode == "CTR" {
+		pad = "None"
 	}
-	// 返回密文
-	return ciphertext, nil
+	return crypt(alg, pad, data, opt, encrypt)
 }
 
-func AES_Decrypt(ciphertext []byte, opt *Options) ([]byte, error) {
-	// 创建AES实例
-	cipher, err := aes.New(opt.Key)
+// crypt 先按Options准备工作密钥，再使用alg、opt.Mode和pad组成的密码套件加密或解密
+// opt.Iv为nil时随机生成IV并写在密文之前；GCM总是使用随机nonce；ECB模式不使用opt.Iv
+func crypt(alg, pad string, data []byte, opt *Options, encrypt bool) ([]byte, error) {
+	return withKey(opt, data, encrypt, func(key, data []byte) ([]byte, error) {
+		if opt.Mode == "GCM" {
+			return gcmCrypt(alg, key, data, encrypt)
+		}
+		return suiteRun(fmt.Sprintf("%s-%d-%s/%s", alg, len(key)*8, opt.Mode, pad), key, opt.Iv, data, encrypt)
+	})
+}
+
+// withKey 按Options准备工作密钥后调用fn，加密时按SaltPosition将盐写入fn的输出
+func withKey(opt *Options, data []byte, encrypt bool, fn func(key, data []byte) ([]byte, error)) ([]byte, error) {
+	key, data, salt, err := prepareKey(opt, data, encrypt)
 	if err != nil {
 		return nil, err
 	}
-
-	// 创建分组模式实例
-	var plaintext []byte
-	switch opt.Mode {
-	case "ECB":
-		ecb := modes.NewECB(cipher)
-		plaintext, err = ecb.Decrypt(ciphertext)
-	case "CBC":
-		cbc, err := modes.NewCBC(cipher, opt.Iv)
-		if err != nil {
-			return nil, err
-		}
-		plaintext, err = cbc.Decrypt(ciphertext)
-	case "CFB":
-		cfb, err := modes.NewCFB(cipher, opt.Iv)
-		if err != nil {
-			return nil, err
-		}
-		plaintext, err = cfb.Decrypt(ciphertext)
-	case "OFB":
-		ofb, err := modes.NewOFB(cipher, opt.Iv)
-		if err != nil {
-			return nil, err
-		}
-		plaintext, err = ofb.Decrypt(ciphertext)
-	case "CTR":
-		ctr, err := modes.NewCTR(cipher, opt.Iv)
-		if err != nil {
-			return nil, err
-		}
-		plaintext, err = ctr.Decrypt(ciphertext)
-	case "GCM":
-		gcm, err := modes.NewGCM(cipher)
-		if err != nil {
-			return nil, err
-		}
-		// 从密文中提取nonce（前12字节）和认证标签（后16字节）
-		nonce := ciphertext[:12]
-		// 附加验证数据（需要与加密时相同）
-		aad := []byte("附加验证数据")
-		plaintext, err = gcm.Open(nonce, ciphertext[12:], aad)
-	default:
-		return nil, fmt.Errorf("unsupported mode: %s", opt.Mode)
-	}
-
+	out, err := fn(key, data)
 	if err != nil {
 		return nil, err
 	}
 
-	// 去除填充
-	if opt.Padding != "None" && opt.Mode != "CFB" && opt.Mode != "CTR" && opt.Mode != "GCM" {
-		switch opt.Padding {
-		case "PKCS#7":
-			plaintext, err = padding.PKCS7UnPadding(plaintext)
-		case "M1":
-			plaintext, err = padding.M1UnPadding(plaintext)
-		case "M1(+0)":
-			plaintext, err = padding.M1UnPadding(plaintext)
-		case "M2":
-			plaintext, err = padding.M2UnPadding(plaintext)
-		case "ISO7816":
-			plaintext, err = padding.ISO7816UnPadding(plaintext)
-		case "ANSIX923":
-			plaintext, err = padding.ANSIX923UnPadding(plaintext)
-		case "Zero":
-			plaintext, err = padding.ZeroUnPadding(plaintext)
-		case "PKCS#5":
-			plaintext, err = padding.PKCS5UnPadding(plaintext)
-		// case "ISO10126":
-		// 	plaintext, err = padding.ISO10126UnPadding(plaintext)
-		case "TBC":
-			plaintext, err = padding.TBCUnPadding(plaintext)
-		}
-		if err != nil {
-			return nil, err
+	if encrypt {
+		switch opt.SaltPosition {
+		case "prefix":
+			out = append(salt[:len(salt):len(salt)], out...)
+		case "suffix":
+			out = append(out, salt...)
 		}
 	}
-
-	return plaintext, nil
+	return out, nil
 }
 
-func DES_Encrypt(plaintext []byte, opt *Options) ([]byte, error) {
-	paddedPlaintext, err := Padding(plaintext, opt)
-	if err != nil {
-		return nil, err
-	}
-	// 创建AES实例
-	cipher, err := des.New(opt.Key)
+// suiteRun 使用规格字符串spec描述的密码套件加密或解密
+// iv为nil且模式需要IV时由gsc.Encrypt随机生成IV并写在密文之前，解密时从密文开头取出
+func suiteRun(spec string, key, iv, data []byte, encrypt bool) ([]byte, error) {
+	suite, err := gsc.NewCipherSuite(spec)
 	if err != nil {
 		return nil, err
 	}
-	// 判断大小
-	if len(paddedPlaintext)%opt.BlockSize != 0 {
-		return nil, fmt.Errorf("plaintext length must be a multiple of the block size")
-	}
-	// 创建分组模式实例
-	var ciphertext []byte
-	switch opt.Mode {
-	case "ECB":
-		ecb := modes.NewECB(cipher)
-		ciphertext, err = ecb.Encrypt(paddedPlaintext)
-		if err != nil {
-			return nil, err
-		}
-	case "CBC":
-		cbc, err := modes.NewCBC(cipher, opt.Iv)
-		if err != nil {
-			return nil, err
-		}
-		ciphertext, err = cbc.Encrypt(paddedPlaintext)
-		if err != nil {
-			return nil, err
-		}
-	case "CFB":
-		cfb, err := modes.NewCFB(cipher, opt.Iv)
-		if err != nil {
-			return nil, err
-		}
-		ciphertext, err = cfb.Encrypt(plaintext)
-		if err != nil {
-			return nil, err
+	if suite.IVSize() == 0 {
+		iv = nil
+	} else if iv == nil {
+		opts := &gsc.EncryptOptions{Suite: suite, Random: Rand}
+		if encrypt {
+			return gsc.Encrypt(key, data, opts)
 		}
-	case "OFB":
-		ofb, err := modes.NewOFB(cipher, opt.Iv)
-		if err != nil {
-			return nil, err
-		}
-		ciphertext, err = ofb.Encrypt(paddedPlaintext)
+		return gsc.Decrypt(key, data, opts)
+	}
+	if encrypt {
+		enc, err := suite.NewEncryptor(key, iv)
 		if err != nil {
 			return nil, err
 		}
-	case "CTR":
-		ctr, err := modes.NewCTR(cipher, opt.Iv)
-		if err != nil {
-			return nil, err
+		return enc.Encrypt(data)
+	}
+	dec, err := suite.NewDecryptor(key, iv)
+	if err != nil {
+		return nil, err
+	}
+	return dec.Decrypt(data)
+}
 
-		}
-		ciphertext, err = ctr.Encrypt(paddedPlaintext)
-		if err != nil {
-			return nil, err
-		}
-	case "GCM":
-		gcm, err := modes.NewGCM(cipher)
-		if err != nil {
-			return nil, err
-		}
-		// 随机生成的12字节Nonce（在实际应用中应该是随机的）
-		nonce := []byte("123456789012")
+const (
+	// defaultIterations 是只设置了盐时使用的迭代次数
+	defaultIterations = 10000
+	// defaultSaltSize 是随机生成的盐的长度
+	defaultSaltSize = 16
+)
 
-		// 附加验证数据（可选）
-		aad := []byte("附加验证数据")
-		ciphertext, err = gcm.Seal(nonce, plaintext, aad)
-		if err != nil {
-			return nil, err
+// prepareKey 返回工作密钥
+// 未设置Iterations、SaltValue和SaltPosition时直接使用opt.Key；否则将opt.Key作为口令，
+// 经opt.KDF（默认PBKDF2-HMAC-SHA256）派生出Keylen（位，为空时与Key等长）长度的密钥。
+// 解密时按SaltPosition从密文中取出盐，返回的data为去掉盐后的密文；加密时返回需要写入密文的盐
+func prepareKey(opt *Options, data []byte, encrypt bool) (key, rest, salt []byte, err error) {
+	if opt.Iterations == 0 && opt.SaltValue == nil && opt.SaltPosition == "" {
+		return opt.Key, data, nil, nil
+	}
+
+	salt = opt.SaltValue
+	switch opt.SaltPosition {
+	case "":
+	case "prefix", "suffix":
+		size := len(opt.SaltValue)
+		if size == 0 {
+			size = defaultSaltSize
+		}
+		switch {
+		case encrypt && salt == nil:
+			salt = make([]byte, size)
+			if _, err := io.ReadFull(Rand, salt); err != nil {
+				return nil, nil, nil, err
+			}
+		case encrypt:
+		case len(data) < size:
+			return nil, nil, nil, fmt.Errorf("密文过短，无法取出%d字节的盐", size)
+		case opt.SaltPosition == "prefix":
+			salt, data = data[:size], data[size:]
+		default:
+			salt, data = data[len(data)-size:], data[:len(data)-size]
 		}
-
 	default:
-		return nil, fmt.Errorf("unsupported mode: %s", opt.Mode)
-
+		return nil, nil, nil, fmt.Errorf("不支持的加盐位置: %s", opt.SaltPosition)
 	}
-	// 返回密文
-	return ciphertext, nil
-}
 
-func DES_Decrypt(ciphertext []byte, opt *Options) ([]byte, error) {
-	// 创建DES实例
-	cipher, err := des.New(opt.Key)
-	if err != nil {
-		return nil, err
+	iterations := opt.Iterations
+	if iterations == 0 {
+		iterations = defaultIterations
 	}
-
-	// 创建分组模式实例
-	var plaintext []byte
-	switch opt.Mode {
-	case "ECB":
-		ecb := modes.NewECB(cipher)
-		plaintext, err = ecb.Decrypt(ciphertext)
-	case "CBC":
-		cbc, err := modes.NewCBC(cipher, opt.Iv)
-		if err != nil {
-			return nil, err
-		}
-		plaintext, err = cbc.Decrypt(ciphertext)
-	case "CFB":
-		cfb, err := modes.NewCFB(cipher, opt.Iv)
-		if err != nil {
-			return nil, err
+	keyLen := len(opt.Key)
+	if opt.Keylen != "" {
+		bits, err := strconv.Atoi(opt.Keylen)
+		if err != nil || bits <= 0 || bits%8 != 0 {
+			return nil, nil, nil, fmt.Errorf("无效的密钥长度: %s", opt.Keylen)
 		}
-		plaintext, err = cfb.Decrypt(ciphertext)
-	case "OFB":
-		ofb, err := modes.NewOFB(cipher, opt.Iv)
-		if err != nil {
-			return nil, err
-		}
-		plaintext, err = ofb.Decrypt(ciphertext)
-	case "CTR":
-		ctr, err := modes.NewCTR(cipher, opt.Iv)
-		if err != nil {
-			return nil, err
-		}
-		plaintext, err = ctr.Decrypt(ciphertext)
-	case "GCM":
-		gcm, err := modes.NewGCM(cipher)
-		if err != nil {
-			return nil, err
+		keyLen = bits / 8
+	}
+	kdf := opt.KDF
+	if kdf == nil {
+		kdf = func(password, salt []byte, iterations, keyLen int) ([]byte, error) {
+			return pbkdf2.Key(sha256.New, password, salt, iterations, keyLen)
 		}
-		// 从密文中提取nonce（前12字节）和认证标签（后16字节）
-		nonce := ciphertext[:12]
-		// 附加验证数据（需要与加密时相同）
-		aad := []byte("附加验证数据")
-		plaintext, err = gcm.Open(nonce, ciphertext[12:], aad)
-	default:
-		return nil, fmt.Errorf("unsupported mode: %s", opt.Mode)
 	}
+	key, err = kdf(opt.Key, salt, iterations, keyLen)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return key, data, salt, nil
+}
 
+// gcmCrypt 使用随机nonce和固定的演示附加验证数据加密或解密，密文格式为 nonce || 密文 || 认证标签
+func gcmCrypt(alg string, key, data []byte, encrypt bool) ([]byte, error) {
+	var cipher modes.BlockCipher
+	var err error
+	switch alg {
+	case "AES":
+		cipher, err = aes.New(key)
+	case "DES":
+		cipher, err = des.New(key)
+	default:
+		return nil, fmt.Errorf("不支持的加密模式: GCM")
+	}
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := modes.NewGCM(cipher)
 	if err != nil {
 		return nil, err
 	}
 
-	// 去除填充
-	if opt.Padding != "None" && opt.Mode != "CFB" && opt.Mode != "CTR" && opt.Mode != "GCM" {
-		switch opt.Padding {
-		case "PKCS#7":
-			plaintext, err = padding.PKCS7UnPadding(plaintext)
-		case "M1":
-			plaintext, err = padding.M1UnPadding(plaintext)
-		case "M1(+0)":
-			plaintext, err = padding.M1UnPadding(plaintext)
-		case "M2":
-			plaintext, err = padding.M2UnPadding(plaintext)
-		case "ISO7816":
-			plaintext, err = padding.ISO7816UnPadding(plaintext)
-		case "ANSIX923":
-			plaintext, err = padding.ANSIX923UnPadding(plaintext)
-		case "Zero":
-			plaintext, err = padding.ZeroUnPadding(plaintext)
-		case "PKCS#5":
-			plaintext, err = padding.PKCS5UnPadding(plaintext)
-		// case "ISO10126":
-		// 	plaintext, err = padding.ISO10126UnPadding(plaintext)
-		case "TBC":
-			plaintext, err = padding.TBCUnPadding(plaintext)
-		}
-		if err != nil {
-			return nil, err
+	// 附加验证数据（需要与加密时相同）
+	aad := []byte("附加验证数据")
+	if !encrypt {
+		if len(data) < 12 {
+			return nil, modes.ErrAuthFailed
 		}
+		// 从密文中取回前12字节的nonce
+		return gcm.Open(data[:12], data[12:], aad)
 	}
 
-	return plaintext, nil
+	// 每次加密随机生成12字节nonce，同一密钥下nonce绝不能重复
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(Rand, nonce); err != nil {
+		return nil, err
+	}
+	sealed, err := gcm.Seal(nonce, data, aad)
+	if err != nil {
+		return nil, err
+	}
+	return append(nonce, sealed...), nil
 }
 
 func AES_test() {
@@ -429,8 +288,6 @@ func AES_test() {
 
 	// AES-256密钥（32字节）
 	key := []byte("12345678901234567890123456789012")
-	// 初始化向量（16字节）
-	iv := []byte("1234567890123456")
 
 	fmt.Println("=== AES 加密测试 ===")
 	fmt.Printf("原文: %s\n", string(plaintext))
@@ -444,6 +301,15 @@ func AES_test() {
 
 			fmt.Printf("\n测试模式: %s, 填充方式: %s\n", mode, paddingType)
 
+			// 每次加密使用新的随机IV；ECB不使用IV，GCM由加密函数随机生成nonce
+			var iv []byte
+			if mode != "ECB" && mode != "GCM" {
+				var err error
+				if iv, err = generator().GenerateIV("AES-" + mode); err != nil {
+					fmt.Printf("生成IV错误: %v\n", err)
+					continue
+				}
+			}
 			opt := &Options{
 				Key:       key,
 				Iv:        iv,
@@ -476,6 +342,29 @@ func AES_test() {
 			}
 		}
 	}
+
+	// 未设置Iv时每次加密随机生成IV并写在密文之前，相同明文得到不同的密文
+	fmt.Printf("\n测试模式: CBC, 自动生成IV\n")
+	opt := &Options{Key: key, Mode: "CBC", Padding: "PKCS#7"}
+	first, err := AES_Encrypt(plaintext, opt)
+	if err != nil {
+		fmt.Printf("加密错误: %v\n", err)
+		return
+	}
+	second, err := AES_Encrypt(plaintext, opt)
+	if err != nil {
+		fmt.Printf("加密错误: %v\n", err)
+		return
+	}
+	fmt.Printf("密文(hex): %x\n", first)
+	fmt.Printf("再次加密(hex): %x\n", second)
+	fmt.Printf("两次密文不同: %v\n", !bytes.Equal(first, second))
+	decrypted, err := AES_Decrypt(second, opt)
+	if err != nil {
+		fmt.Printf("解密错误: %v\n", err)
+		return
+	}
+	fmt.Printf("解密结果: %s\n", string(decrypted))
 }
 
 func DES_test() {
@@ -529,426 +418,115 @@ func DES_test() {
 		fmt.Println("✓ 密文验证成功：生成的密文与期望密文匹配")
 	} else {
 		fmt.Println("✗ 密文验证失败：生成的密文与期望密文不匹配")
-		fmt.Println("\n差异分析：")
-		if len(ciphertext) != len(expectedCiphertext) {
-			fmt.Printf("长度不匹配：实际长度=%d, 期望长度=%d\n", len(ciphertext), len(expectedCiphertext))
-		} else {
-			fmt.Println("按块比对（每8字节一块）：")
-			for i := 0; i < len(ciphertext); i += 8 {
-				end := i + 8
-				if end > len(ciphertext) {
-					end = len(ciphertext)
-				}
-				fmt.Printf("块 %d: 实际=%X\n", i/8+1, ciphertext[i:end])
-				fmt.Printf("     期望=%X\n", expectedCiphertext[i:end])
-				if !bytes.Equal(ciphertext[i:end], expectedCiphertext[i:end]) {
-					fmt.Println("     ↑ 此块不匹配")
-				}
-			}
-		}
+		fmt.Println("\n差异分析（每8字节一块）：")
+		fmt.Print(dump.Diff(ciphertext, expectedCiphertext, 8))
 	}
 }
 
-func Blowfish_Encrypt(plaintext []byte, opt *Options) ([]byte, error) {
-	// 设置块大小
-	opt.BlockSize = blowfish.BlockSize
-
-	// 填充
-	paddedPlaintext, err := Padding(plaintext, opt)
+// DES3_Encrypt 使用3DES加密，密钥选项见tripleDESOptions，支持ECB、CBC、CFB、OFB和CTR模式
+func DES3_Encrypt(plaintext []byte, opt *Options) ([]byte, error) {
+	o, err := tripleDESOptions(opt)
 	if err != nil {
 		return nil, err
 	}
+	return suiteCrypt("3DES", plaintext, o, true)
+}
 
-	// 创建Blowfish实例
-	bf, err := blowfish.New(opt.Key)
+func DES3_Decrypt(ciphertext []byte, opt *Options) ([]byte, error) {
+	o, err := tripleDESOptions(opt)
 	if err != nil {
 		return nil, err
 	}
+	return suiteCrypt("3DES", ciphertext, o, false)
+}
 
-	blockSize := bf.BlockSize()
-	ciphertext := make([]byte, len(paddedPlaintext))
+// tripleDESOptions 按Key2和Key3拼接3DES密钥，返回Key替换后的Options副本：
+// 都为空时直接使用Key（8、16或24字节）；只设置Key2时为双密钥K1||K2；都设置时为三密钥K1||K2||K3
+func tripleDESOptions(opt *Options) (*Options, error) {
+	o := *opt
+	o.BlockSize = des.BlockSize
+	switch {
+	case opt.Key2 == "" && opt.Key3 == "":
+		return &o, nil
+	case opt.Key2 == "":
+		return nil, fmt.Errorf("设置Key3时必须同时设置Key2")
+	case len(opt.Key) != des.KeySize:
+		return nil, fmt.Errorf("使用Key2/Key3时Key必须是%d字节", des.KeySize)
+	}
+	key := append(append([]byte(nil), opt.Key...), opt.Key2...)
+	o.Key = append(key, opt.Key3...)
+	return &o, nil
+}
 
-	// 根据模式进行加密
-	switch opt.Mode {
-	case "ECB":
-		// ECB模式
-		for i := 0; i < len(paddedPlaintext); i += blockSize {
-			block, err := bf.Encrypt(paddedPlaintext[i : i+blockSize])
-			if err != nil {
-				return nil, err
+func DES3_test() {
+	fmt.Println("\n---------- 3DES测试 ----------")
+
+	plaintext := []byte("Hello, World! This is a test message for 3DES.")
+	configs := []struct {
+		name string
+		key2 string
+		key3 string
+	}{
+		{"单密钥（等同DES）", "", ""},
+		{"双密钥", "abcdefgh", ""},
+		{"三密钥", "abcdefgh", "ABCDEFGH"},
+	}
+
+	for _, c := range configs {
+		for _, mode := range []string{"ECB", "CBC", "CFB", "OFB", "CTR"} {
+			fmt.Printf("\n[%s %s模式]\n", c.name, mode)
+			var iv []byte
+			if mode != "ECB" {
+				var err error
+				if iv, err = generator().GenerateIV("3DES-" + mode); err != nil {
+					fmt.Printf("生成IV错误: %v\n", err)
+					continue
+				}
 			}
-			copy(ciphertext[i:i+blockSize], block)
-		}
-	case "CBC":
-		// CBC模式
-		iv := opt.Iv
-		if iv == nil || len(iv) != blockSize {
-			return nil, fmt.Errorf("CBC模式需要%d字节的初始化向量", blockSize)
-		}
-
-		previousBlock := iv
-		for i := 0; i < len(paddedPlaintext); i += blockSize {
-			// 将明文块与前一个密文块（或IV）进行XOR
-			block := make([]byte, blockSize)
-			for j := 0; j < blockSize; j++ {
-				block[j] = paddedPlaintext[i+j] ^ previousBlock[j]
+			opt := &Options{
+				Key:     []byte("01234567"),
+				Key2:    c.key2,
+				Key3:    c.key3,
+				Iv:      iv,
+				Mode:    mode,
+				Padding: "PKCS#7",
 			}
 
-			// 加密
-			encryptedBlock, err := bf.Encrypt(block)
+			ciphertext, err := DES3_Encrypt(plaintext, opt)
 			if err != nil {
-				return nil, err
+				fmt.Printf("加密错误: %v\n", err)
+				continue
 			}
+			fmt.Printf("密文(Hex): %s\n", hex.EncodeToString(ciphertext))
 
-			// 保存结果
-			copy(ciphertext[i:i+blockSize], encryptedBlock)
-			previousBlock = encryptedBlock
-		}
-	case "CTR":
-		// CTR模式
-		counter := opt.Iv
-		if counter == nil || len(counter) != blockSize {
-			return nil, fmt.Errorf("CTR模式需要%d字节的计数器", blockSize)
-		}
-
-		counterBlock := make([]byte, blockSize)
-		for i := 0; i < len(paddedPlaintext); i += blockSize {
-			// 复制计数器
-			copy(counterBlock, counter)
-
-			// 加密计数器
-			encryptedCounter, err := bf.Encrypt(counterBlock)
+			decrypted, err := DES3_Decrypt(ciphertext, opt)
 			if err != nil {
-				return nil, err
-			}
-
-			// 与明文进行XOR
-			blockLength := blockSize
-			if i+blockSize > len(paddedPlaintext) {
-				blockLength = len(paddedPlaintext) - i
-			}
-			for j := 0; j < blockLength; j++ {
-				ciphertext[i+j] = paddedPlaintext[i+j] ^ encryptedCounter[j]
-			}
-
-			// 增加计数器
-			for j := blockSize - 1; j >= 0; j-- {
-				counter[j]++
-				if counter[j] != 0 {
-					break
-				}
+				fmt.Printf("解密错误: %v\n", err)
+				continue
 			}
+			fmt.Printf("解密是否成功: %v\n", bytes.Equal(plaintext, decrypted))
 		}
-	default:
-		return nil, fmt.Errorf("不支持的加密模式: %s", opt.Mode)
 	}
+}
 
-	return ciphertext, nil
+func Blowfish_Encrypt(plaintext []byte, opt *Options) ([]byte, error) {
+	// 设置块大小
+	opt.BlockSize = blowfish.BlockSize
+	return crypt("Blowfish", opt.Padding, plaintext, opt, true)
 }
 
 func Blowfish_Decrypt(ciphertext []byte, opt *Options) ([]byte, error) {
-	// 创建Blowfish实例
-	bf, err := blowfish.New(opt.Key)
-	if err != nil {
-		return nil, err
-	}
-
-	blockSize := bf.BlockSize()
-	plaintext := make([]byte, len(ciphertext))
-
-	// 根据模式进行解密
-	switch opt.Mode {
-	case "ECB":
-		// ECB模式
-		for i := 0; i < len(ciphertext); i += blockSize {
-			block, err := bf.Decrypt(ciphertext[i : i+blockSize])
-			if err != nil {
-				return nil, err
-			}
-			copy(plaintext[i:i+blockSize], block)
-		}
-	case "CBC":
-		// CBC模式
-		iv := opt.Iv
-		if iv == nil || len(iv) != blockSize {
-			return nil, fmt.Errorf("CBC模式需要%d字节的初始化向量", blockSize)
-		}
-
-		previousBlock := iv
-		for i := 0; i < len(ciphertext); i += blockSize {
-			// 解密
-			decryptedBlock, err := bf.Decrypt(ciphertext[i : i+blockSize])
-			if err != nil {
-				return nil, err
-			}
-
-			// 与前一个密文块（或IV）进行XOR
-			for j := 0; j < blockSize; j++ {
-				plaintext[i+j] = decryptedBlock[j] ^ previousBlock[j]
-			}
-
-			// 更新前一个密文块
-			previousBlock = ciphertext[i : i+blockSize]
-		}
-	case "CTR":
-		// CTR模式
-		counter := opt.Iv
-		if counter == nil || len(counter) != blockSize {
-			return nil, fmt.Errorf("CTR模式需要%d字节的计数器", blockSize)
-		}
-
-		counterBlock := make([]byte, blockSize)
-		for i := 0; i < len(ciphertext); i += blockSize {
-			// 复制计数器
-			copy(counterBlock, counter)
-
-			// 加密计数器
-			encryptedCounter, err := bf.Encrypt(counterBlock)
-			if err != nil {
-				return nil, err
-			}
-
-			// 与密文进行XOR
-			blockLength := blockSize
-			if i+blockSize > len(ciphertext) {
-				blockLength = len(ciphertext) - i
-			}
-			for j := 0; j < blockLength; j++ {
-				plaintext[i+j] = ciphertext[i+j] ^ encryptedCounter[j]
-			}
-
-			// 增加计数器
-			for j := blockSize - 1; j >= 0; j-- {
-				counter[j]++
-				if counter[j] != 0 {
-					break
-				}
-			}
-		}
-	default:
-		return nil, fmt.Errorf("不支持的解密模式: %s", opt.Mode)
-	}
-
-	// 移除填充
-	switch opt.Padding {
-	case "PKCS#7":
-		plaintext, err = padding.PKCS7UnPadding(plaintext)
-		if err != nil {
-			return nil, err
-		}
-	case "PKCS#5":
-		plaintext, err = padding.PKCS5UnPadding(plaintext)
-		if err != nil {
-			return nil, err
-		}
-	case "None":
-		// 不需要移除填充
-	default:
-		return nil, fmt.Errorf("不支持的填充方式: %s", opt.Padding)
-	}
-
-	return plaintext, nil
+	return crypt("Blowfish", opt.Padding, ciphertext, opt, false)
 }
 
 func Twofish_Encrypt(plaintext []byte, opt *Options) ([]byte, error) {
 	// 设置块大小
 	opt.BlockSize = twofish.BlockSize
-
-	// 填充
-	paddedPlaintext, err := Padding(plaintext, opt)
-	if err != nil {
-		return nil, err
-	}
-
-	// 创建Twofish实例
-	tf, err := twofish.New(opt.Key)
-	if err != nil {
-		return nil, err
-	}
-
-	blockSize := tf.BlockSize()
-	ciphertext := make([]byte, len(paddedPlaintext))
-
-	// 根据模式进行加密
-	switch opt.Mode {
-	case "ECB":
-		// ECB模式
-		for i := 0; i < len(paddedPlaintext); i += blockSize {
-			block, err := tf.Encrypt(paddedPlaintext[i : i+blockSize])
-			if err != nil {
-				return nil, err
-			}
-			copy(ciphertext[i:i+blockSize], block)
-		}
-	case "CBC":
-		// CBC模式
-		iv := opt.Iv
-		if iv == nil || len(iv) != blockSize {
-			return nil, fmt.Errorf("CBC模式需要%d字节的初始化向量", blockSize)
-		}
-
-		previousBlock := iv
-		for i := 0; i < len(paddedPlaintext); i += blockSize {
-			// 将明文块与前一个密文块（或IV）进行XOR
-			block := make([]byte, blockSize)
-			for j := 0; j < blockSize; j++ {
-				block[j] = paddedPlaintext[i+j] ^ previousBlock[j]
-			}
-
-			// 加密
-			encryptedBlock, err := tf.Encrypt(block)
-			if err != nil {
-				return nil, err
-			}
-
-			// 保存结果
-			copy(ciphertext[i:i+blockSize], encryptedBlock)
-			previousBlock = encryptedBlock
-		}
-	case "CTR":
-		// CTR模式
-		counter := opt.Iv
-		if counter == nil || len(counter) != blockSize {
-			return nil, fmt.Errorf("CTR模式需要%d字节的计数器", blockSize)
-		}
-
-		counterBlock := make([]byte, blockSize)
-		for i := 0; i < len(paddedPlaintext); i += blockSize {
-			// 复制计数器
-			copy(counterBlock, counter)
-
-			// 加密计数器
-			encryptedCounter, err := tf.Encrypt(counterBlock)
-			if err != nil {
-				return nil, err
-			}
-
-			// 与明文进行XOR
-			blockLength := blockSize
-			if i+blockSize > len(paddedPlaintext) {
-				blockLength = len(paddedPlaintext) - i
-			}
-			for j := 0; j < blockLength; j++ {
-				ciphertext[i+j] = paddedPlaintext[i+j] ^ encryptedCounter[j]
-			}
-
-			// 增加计数器
-			for j := blockSize - 1; j >= 0; j-- {
-				counter[j]++
-				if counter[j] != 0 {
-					break
-				}
-			}
-		}
-	default:
-		return nil, fmt.Errorf("不支持的加密模式: %s", opt.Mode)
-	}
-
-	return ciphertext, nil
+	return crypt("Twofish", opt.Padding, plaintext, opt, true)
 }
 
 func Twofish_Decrypt(ciphertext []byte, opt *Options) ([]byte, error) {
-	// 创建Twofish实例
-	tf, err := twofish.New(opt.Key)
-	if err != nil {
-		return nil, err
-	}
-
-	blockSize := tf.BlockSize()
-	plaintext := make([]byte, len(ciphertext))
-
-	// 根据模式进行解密
-	switch opt.Mode {
-	case "ECB":
-		// ECB模式
-		for i := 0; i < len(ciphertext); i += blockSize {
-			block, err := tf.Decrypt(ciphertext[i : i+blockSize])
-			if err != nil {
-				return nil, err
-			}
-			copy(plaintext[i:i+blockSize], block)
-		}
-	case "CBC":
-		// CBC模式
-		iv := opt.Iv
-		if iv == nil || len(iv) != blockSize {
-			return nil, fmt.Errorf("CBC模式需要%d字节的初始化向量", blockSize)
-		}
-
-		previousBlock := iv
-		for i := 0; i < len(ciphertext); i += blockSize {
-			// 解密
-			decryptedBlock, err := tf.Decrypt(ciphertext[i : i+blockSize])
-			if err != nil {
-				return nil, err
-			}
-
-			// 与前一个密文块（或IV）进行XOR
-			for j := 0; j < blockSize; j++ {
-				plaintext[i+j] = decryptedBlock[j] ^ previousBlock[j]
-			}
-
-			// 更新前一个密文块
-			previousBlock = ciphertext[i : i+blockSize]
-		}
-	case "CTR":
-		// CTR模式
-		counter := opt.Iv
-		if counter == nil || len(counter) != blockSize {
-			return nil, fmt.Errorf("CTR模式需要%d字节的计数器", blockSize)
-		}
-
-		counterBlock := make([]byte, blockSize)
-		for i := 0; i < len(ciphertext); i += blockSize {
-			// 复制计数器
-			copy(counterBlock, counter)
-
-			// 加密计数器
-			encryptedCounter, err := tf.Encrypt(counterBlock)
-			if err != nil {
-				return nil, err
-			}
-
-			// 与密文进行XOR
-			blockLength := blockSize
-			if i+blockSize > len(ciphertext) {
-				blockLength = len(ciphertext) - i
-			}
-			for j := 0; j < blockLength; j++ {
-				plaintext[i+j] = ciphertext[i+j] ^ encryptedCounter[j]
-			}
-
-			// 增加计数器
-			for j := blockSize - 1; j >= 0; j-- {
-				counter[j]++
-				if counter[j] != 0 {
-					break
-				}
-			}
-		}
-	default:
-		return nil, fmt.Errorf("不支持的解密模式: %s", opt.Mode)
-	}
-
-	// 移除填充
-	switch opt.Padding {
-	case "PKCS#7":
-		plaintext, err = padding.PKCS7UnPadding(plaintext)
-		if err != nil {
-			return nil, err
-		}
-	case "PKCS#5":
-		plaintext, err = padding.PKCS5UnPadding(plaintext)
-		if err != nil {
-			return nil, err
-		}
-	case "None":
-		// 不需要移除填充
-	default:
-		return nil, fmt.Errorf("不支持的填充方式: %s", opt.Padding)
-	}
-
-	return plaintext, nil
+	return crypt("Twofish", opt.Padding, ciphertext, opt, false)
 }
 
 func Blowfish_test() {
@@ -985,7 +563,12 @@ func Blowfish_test() {
 
 	// CBC模式测试
 	fmt.Println("\n[CBC模式]")
-	iv := []byte("12345678") // Blowfish块大小为8字节
+	// IV长度为Blowfish的块大小
+	iv, err := generator().GenerateIV("Blowfish-CBC")
+	if err != nil {
+		fmt.Printf("生成IV错误: %v\n", err)
+		return
+	}
 	opt = &Options{
 		Key:     key,
 		Mode:    "CBC",
@@ -1012,7 +595,11 @@ func Blowfish_test() {
 
 	// CTR模式测试
 	fmt.Println("\n[CTR模式]")
-	counter := []byte("87654321") // Blowfish块大小为8字节
+	counter, err := generator().GenerateIV("Blowfish-CTR")
+	if err != nil {
+		fmt.Printf("生成IV错误: %v\n", err)
+		return
+	}
 	opt = &Options{
 		Key:     key,
 		Mode:    "CTR",
@@ -1042,7 +629,7 @@ func Twofish_test() {
 	fmt.Println("\n---------- Twofish测试 ----------")
 
 	// 初始化参数
-	key := []byte("twofish-key-16byte") // 刚好16字节密钥
+	key := []byte("twofish-16bytkey") // 刚好16字节密钥
 	plaintext := []byte("这是一个Twofish加密的明文测试消息。")
 
 	// ECB模式测试
@@ -1072,7 +659,12 @@ func Twofish_test() {
 
 	// CBC模式测试
 	fmt.Println("\n[CBC模式]")
-	iv := []byte("1234567890123456") // Twofish块大小为16字节
+	// IV长度为Twofish的块大小
+	iv, err := generator().GenerateIV("Twofish-CBC")
+	if err != nil {
+		fmt.Printf("生成IV错误: %v\n", err)
+		return
+	}
 	opt = &Options{
 		Key:     key,
 		Mode:    "CBC",
@@ -1099,7 +691,11 @@ func Twofish_test() {
 
 	// CTR模式测试
 	fmt.Println("\n[CTR模式]")
-	counter := []byte("6543210987654321") // Twofish块大小为16字节
+	counter, err := generator().GenerateIV("Twofish-CTR")
+	if err != nil {
+		fmt.Printf("生成IV错误: %v\n", err)
+		return
+	}
 	opt = &Options{
 		Key:     key,
 		Mode:    "CTR",
@@ -1,23 +1,163 @@
 package examples_test
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/laenix/gsc/examples"
 )
 
-func TestAES_test(t *testing.T) {
-	examples.AES_test()
+// golden文件记录了各演示函数的完整输出，API变化导致输出改变时测试失败。
+// 确认输出变化符合预期后更新golden文件：
+//
+//	go test ./examples -update
+var update = flag.Bool("update", false, "将演示函数的输出写入testdata/*.golden")
+
+func TestGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"aes", examples.AES_test},
+		{"des", examples.DES_test},
+		{"des3", examples.DES3_test},
+		{"blowfish", examples.Blowfish_test},
+		{"twofish", examples.Twofish_test},
+		{"stream", examples.Stream_test},
+		{"keyagreement", examples.KeyAgreement_test},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 随机生成的IV和nonce使用确定性随机源，使输出可以复现
+			defer func(r io.Reader) { examples.Rand = r }(examples.Rand)
+			examples.Rand = &detReader{}
+
+			got := captureStdout(t, tt.fn)
+			path := filepath.Join("testdata", tt.name+".golden")
+
+			if *update {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatalf("写入golden文件失败: %v", err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("读取golden文件失败: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("输出与%s不一致:\n实际输出:\n%s\n期望输出:\n%s", path, got, want)
+			}
+		})
+	}
 }
 
-func TestDES_test(t *testing.T) {
-	examples.DES_test()
+// detReader 是仅用于测试的确定性随机源（SHA-256计数器模式）
+type detReader struct {
+	counter uint64
+	buf     []byte
 }
 
-func TestBlowfish_test(t *testing.T) {
-	examples.Blowfish_test()
+func (r *detReader) Read(p []byte) (int, error) {
+	for n := 0; n < len(p); {
+		if len(r.buf) == 0 {
+			var block [8]byte
+			binary.BigEndian.PutUint64(block[:], r.counter)
+			r.counter++
+			sum := sha256.Sum256(block[:])
+			r.buf = sum[:]
+		}
+		c := copy(p[n:], r.buf)
+		r.buf = r.buf[c:]
+		n += c
+	}
+	return len(p), nil
 }
 
-func TestTwofish_test(t *testing.T) {
-	examples.Twofish_test()
+// captureStdout 运行fn并返回其写入标准输出的内容
+func captureStdout(t *testing.T, fn func()) []byte {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan []byte)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- out
+	}()
+
+	defer func() {
+		os.Stdout = stdout
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+// 测试设置迭代次数和盐时先派生工作密钥，并按加盐位置在密文中携带盐
+func TestKeyStretching(t *testing.T) {
+	plaintext := []byte("口令派生密钥的演示数据")
+	password := []byte("password")
+	iv := []byte("1234567890123456")
+
+	for _, pos := range []string{"prefix", "suffix"} {
+		opt := &examples.Options{Key: password, Iv: iv, Mode: "CBC", Padding: "PKCS7", Keylen: "256", Iterations: 1000, SaltPosition: pos}
+		c1, err := examples.AES_Encrypt(plaintext, opt)
+		if err != nil {
+			t.Fatalf("%s: %v", pos, err)
+		}
+		c2, err := examples.AES_Encrypt(plaintext, opt)
+		if err != nil {
+			t.Fatalf("%s: %v", pos, err)
+		}
+		if bytes.Equal(c1, c2) {
+			t.Fatalf("%s: 随机盐应使两次加密的结果不同", pos)
+		}
+		got, err := examples.AES_Decrypt(c1, opt)
+		if err != nil {
+			t.Fatalf("%s: %v", pos, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Fatalf("%s: 解密结果与明文不一致", pos)
+		}
+	}
+
+	// 盐不写入密文时，相同的盐得到相同的结果，且与直接使用口令作为密钥不同
+	opt := &examples.Options{Key: []byte("0123456789abcdef"), Iv: iv, Mode: "CTR", Iterations: 1000, SaltValue: []byte("saltsalt")}
+	derived, err := examples.AES_Encrypt(plaintext, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := examples.AES_Encrypt(plaintext, opt)
+	raw, _ := examples.AES_Encrypt(plaintext, &examples.Options{Key: opt.Key, Iv: iv, Mode: "CTR"})
+	if !bytes.Equal(derived, again) || bytes.Equal(derived, raw) {
+		t.Fatal("派生密钥的结果不正确")
+	}
+
+	// 自定义KDF
+	called := false
+	opt.KDF = func(password, salt []byte, iterations, keyLen int) ([]byte, error) {
+		called = true
+		return make([]byte, keyLen), nil
+	}
+	if _, err := examples.AES_Encrypt(plaintext, opt); err != nil || !called {
+		t.Fatalf("未使用自定义KDF: %v", err)
+	}
+
+	opt = &examples.Options{Key: password, Iv: iv, Mode: "CBC", SaltPosition: "middle"}
+	if _, err := examples.AES_Encrypt(plaintext, opt); err == nil {
+		t.Fatal("不支持的加盐位置应返回错误")
+	}
 }
@@ -0,0 +1,219 @@
+package examples
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/laenix/gsc/kdf/hkdf"
+	"github.com/laenix/gsc/sm2"
+	"github.com/laenix/gsc/x25519"
+)
+
+// Party 是密钥协商的一方，ID与Options.UserA或UserB对应
+type Party struct {
+	ID       string
+	Exchange string
+
+	x25519Key *x25519.PrivateKey
+	ecdhKey   *ecdh.PrivateKey
+	sm2Key    *sm2.PrivateKey
+	// kx 是SM2密钥交换的临时状态，在SessionOptions中按Options确定发起方后生成
+	kx *sm2.KeyExchange
+}
+
+// NewParty 生成密钥协商一方的静态密钥对
+// exchange为X25519、P256或SM2，X25519（gsc/x25519）和P256（crypto/ecdh）使用ECDH，SM2使用GB/T 32918.3密钥交换
+func NewParty(exchange, id string) (*Party, error) {
+	p := &Party{ID: id, Exchange: strings.ToUpper(exchange)}
+	var err error
+	switch p.Exchange {
+	case "X25519":
+		p.x25519Key, err = x25519.GenerateKey(rand.Reader)
+	case "P256":
+		p.ecdhKey, err = ecdh.P256().GenerateKey(rand.Reader)
+	case "SM2":
+		p.sm2Key, err = sm2.New().GenerateKey(nil)
+	default:
+		return nil, fmt.Errorf("不支持的密钥协商算法: %s", exchange)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// PublicKey 返回需要发送给对方的静态公钥
+func (p *Party) PublicKey() []byte {
+	if p.sm2Key != nil {
+		pub, _ := p.sm2Key.PublicKey.MarshalBinary()
+		return pub
+	}
+	if p.x25519Key != nil {
+		return p.x25519Key.PublicKey().Bytes()
+	}
+	return p.ecdhKey.PublicKey().Bytes()
+}
+
+// EphemeralKey 返回SM2密钥交换需要发送给对方的临时公钥，ECDH返回nil
+// 调用前须以相同的opt调用Prepare
+func (p *Party) EphemeralKey() []byte {
+	if p.kx == nil {
+		return nil
+	}
+	return p.kx.EphemeralKey()
+}
+
+// Prepare 按opt.UserA和opt.UserB确定本方角色，SM2会生成本次交换的临时密钥
+func (p *Party) Prepare(opt *Options) error {
+	initiator, err := p.role(opt)
+	if err != nil {
+		return err
+	}
+	if p.sm2Key != nil {
+		p.kx, err = sm2.New().NewKeyExchange(p.sm2Key, []byte(p.ID), initiator, nil)
+	}
+	return err
+}
+
+// role 判断本方是否为发起方UserA
+func (p *Party) role(opt *Options) (bool, error) {
+	switch p.ID {
+	case opt.UserA:
+		return true, nil
+	case opt.UserB:
+		return false, nil
+	}
+	return false, fmt.Errorf("参与方%q既不是UserA也不是UserB", p.ID)
+}
+
+// SessionOptions 与对方协商会话密钥，返回以会话密钥为Key的Options副本，可直接用于各个加解密函数
+// 会话密钥长度为opt.Keylen（位），为空时为128位。ECDH的共享秘密经
+// HKDF-SHA256（info = 标签 || UserA || 0x00 || UserB）派生；SM2密钥交换使用标准中的SM3 KDF，
+// 用户标识分别为UserA和UserB
+func SessionOptions(opt *Options, self *Party, peerPublic, peerEphemeral []byte) (*Options, error) {
+	initiator, err := self.role(opt)
+	if err != nil {
+		return nil, err
+	}
+	keyLen := 16
+	if opt.Keylen != "" {
+		bits, err := strconv.Atoi(opt.Keylen)
+		if err != nil || bits <= 0 || bits%8 != 0 {
+			return nil, fmt.Errorf("无效的密钥长度: %s", opt.Keylen)
+		}
+		keyLen = bits / 8
+	}
+	peerID := opt.UserB
+	if !initiator {
+		peerID = opt.UserA
+	}
+
+	var key []byte
+	if self.sm2Key != nil {
+		if self.kx == nil {
+			return nil, fmt.Errorf("SM2密钥交换前须调用Prepare")
+		}
+		var peer sm2.PublicKey
+		if err := peer.UnmarshalBinary(peerPublic); err != nil {
+			return nil, err
+		}
+		key, err = self.kx.Agree(&peer, []byte(peerID), peerEphemeral, keyLen)
+	} else {
+		var shared []byte
+		if shared, err = self.ecdhShared(peerPublic); err != nil {
+			return nil, err
+		}
+		info := []byte("gsc/examples/key-agreement " + self.Exchange + "\x00" + opt.UserA + "\x00" + opt.UserB)
+		key, err = hkdf.Key(sha256.New, shared, nil, info, keyLen)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	session := *opt
+	session.Key = key
+	return &session, nil
+}
+
+func KeyAgreement_test() {
+	fmt.Println("\n---------- 密钥协商测试 ----------")
+
+	plaintext := []byte("这是一个使用协商会话密钥加密的明文测试。")
+	for _, exchange := range []string{"X25519", "P256", "SM2"} {
+		fmt.Printf("\n[%s]\n", exchange)
+
+		opt := &Options{
+			UserA:   "alice@example.com",
+			UserB:   "bob@example.com",
+			Mode:    "CBC",
+			Padding: "PKCS7",
+		}
+		alice, err := NewParty(exchange, opt.UserA)
+		if err != nil {
+			fmt.Printf("生成密钥错误: %v\n", err)
+			continue
+		}
+		bob, err := NewParty(exchange, opt.UserB)
+		if err != nil {
+			fmt.Printf("生成密钥错误: %v\n", err)
+			continue
+		}
+		if err := alice.Prepare(opt); err != nil {
+			fmt.Printf("协商错误: %v\n", err)
+			continue
+		}
+		if err := bob.Prepare(opt); err != nil {
+			fmt.Printf("协商错误: %v\n", err)
+			continue
+		}
+
+		// 双方交换静态公钥和临时公钥后各自计算会话密钥
+		optA, err := SessionOptions(opt, alice, bob.PublicKey(), bob.EphemeralKey())
+		if err != nil {
+			fmt.Printf("协商错误: %v\n", err)
+			continue
+		}
+		optB, err := SessionOptions(opt, bob, alice.PublicKey(), alice.EphemeralKey())
+		if err != nil {
+			fmt.Printf("协商错误: %v\n", err)
+			continue
+		}
+		fmt.Printf("会话密钥长度: %d位\n", len(optA.Key)*8)
+		fmt.Printf("双方会话密钥一致: %v\n", bytes.Equal(optA.Key, optB.Key))
+
+		// UserA加密，UserB解密，未设置Iv时随机生成IV并随密文发送
+		ciphertext, err := AES_Encrypt(plaintext, optA)
+		if err != nil {
+			fmt.Printf("加密错误: %v\n", err)
+			continue
+		}
+		decrypted, err := AES_Decrypt(ciphertext, optB)
+		if err != nil {
+			fmt.Printf("解密错误: %v\n", err)
+			continue
+		}
+		fmt.Printf("解密结果: %s\n", string(decrypted))
+		fmt.Printf("解密是否成功: %v\n", bytes.Equal(plaintext, decrypted))
+	}
+}
+
+// ecdhShared 计算X25519或P256的ECDH共享秘密
+func (p *Party) ecdhShared(peerPublic []byte) ([]byte, error) {
+	if p.x25519Key != nil {
+		peer, err := x25519.NewPublicKey(peerPublic)
+		if err != nil {
+			return nil, err
+		}
+		return p.x25519Key.ECDH(peer)
+	}
+	peer, err := p.ecdhKey.Curve().NewPublicKey(peerPublic)
+	if err != nil {
+		return nil, err
+	}
+	return p.ecdhKey.ECDH(peer)
+}
@@ -0,0 +1,149 @@
+package examples
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/laenix/gsc/chacha20"
+	"github.com/laenix/gsc/chacha20poly1305"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/nacl/secretbox"
+	"github.com/laenix/gsc/salsa20"
+)
+
+// Stream_Encrypt 使用opt.Random选择的流密码或认证加密算法加密
+// 密钥为32字节（设置Iterations或盐时由opt.Key派生），nonce取自opt.Iv：
+// chacha20和chacha20poly1305为12字节，24字节时为XChaCha20；xsalsa20和xsalsa20poly1305为24字节。
+// 认证加密的密文末尾（xsalsa20poly1305为开头）带有16字节认证标签，nonce不写入密文
+func Stream_Encrypt(plaintext []byte, opt *Options) ([]byte, error) {
+	return withKey(opt, plaintext, true, func(key, data []byte) ([]byte, error) {
+		return streamCrypt(opt.Random, key, opt.Iv, data, true)
+	})
+}
+
+// Stream_Decrypt 解密Stream_Encrypt的输出，认证加密算法在认证失败时返回错误
+func Stream_Decrypt(ciphertext []byte, opt *Options) ([]byte, error) {
+	return withKey(opt, ciphertext, false, func(key, data []byte) ([]byte, error) {
+		return streamCrypt(opt.Random, key, opt.Iv, data, false)
+	})
+}
+
+// streamCrypt 按算法名称加密或解密
+func streamCrypt(random string, key, nonce, data []byte, encrypt bool) ([]byte, error) {
+	switch strings.ToLower(random) {
+	case "chacha20":
+		if len(nonce) != chacha20.NonceSize && len(nonce) != chacha20.NonceSizeX {
+			return nil, chacha20.ErrInvalidNonceSize
+		}
+		c, err := chacha20.New(key, nonce)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]byte, len(data))
+		c.XORKeyStream(out, data)
+		return out, nil
+
+	case "xsalsa20":
+		if len(nonce) != salsa20.NonceSizeX {
+			return nil, salsa20.ErrInvalidNonceSize
+		}
+		c, err := salsa20.New(key, nonce)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]byte, len(data))
+		c.XORKeyStream(out, data)
+		return out, nil
+
+	case "chacha20poly1305":
+		newAEAD := chacha20poly1305.New
+		if len(nonce) == chacha20poly1305.NonceSizeX {
+			newAEAD = chacha20poly1305.NewX
+		} else if len(nonce) != chacha20poly1305.NonceSize {
+			return nil, chacha20.ErrInvalidNonceSize
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		if encrypt {
+			return aead.Seal(nil, nonce, data, nil), nil
+		}
+		return aead.Open(nil, nonce, data, nil)
+
+	case "xsalsa20poly1305":
+		if len(key) != secretbox.KeySize {
+			return nil, salsa20.ErrInvalidKeySize
+		}
+		if len(nonce) != secretbox.NonceSize {
+			return nil, salsa20.ErrInvalidNonceSize
+		}
+		var k [secretbox.KeySize]byte
+		var n [secretbox.NonceSize]byte
+		copy(k[:], key)
+		copy(n[:], nonce)
+		if encrypt {
+			return secretbox.Seal(nil, data, &n, &k), nil
+		}
+		return secretbox.Open(nil, data, &n, &k)
+	}
+	return nil, fmt.Errorf("不支持的流密码: %s", random)
+}
+
+func Stream_test() {
+	fmt.Println("\n---------- 流密码测试 ----------")
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	plaintext := []byte("这是一个流密码加密的明文测试。")
+
+	// nonce按算法随机生成：ChaCha20为12字节，XChaCha20、XSalsa20为24字节
+	tests := []struct {
+		random string
+		nonce  string
+	}{
+		{"chacha20", "ChaCha20"},
+		{"chacha20", "XChaCha20"},
+		{"chacha20poly1305", "ChaCha20"},
+		{"chacha20poly1305", "XChaCha20"},
+		{"xsalsa20", "XSalsa20"},
+		{"xsalsa20poly1305", "XSalsa20"},
+	}
+
+	for _, tt := range tests {
+		nonce, err := generator().GenerateIV(tt.nonce)
+		if err != nil {
+			fmt.Printf("生成nonce错误: %v\n", err)
+			continue
+		}
+		fmt.Printf("\n[%s, %d字节nonce]\n", tt.random, len(nonce))
+		opt := &Options{
+			Key:    key,
+			Iv:     nonce,
+			Random: tt.random,
+		}
+
+		ciphertext, err := Stream_Encrypt(plaintext, opt)
+		if err != nil {
+			fmt.Printf("加密错误: %v\n", err)
+			continue
+		}
+		fmt.Printf("密文(Hex): %s\n", hex.EncodeToString(ciphertext))
+
+		decrypted, err := Stream_Decrypt(ciphertext, opt)
+		if err != nil {
+			fmt.Printf("解密错误: %v\n", err)
+			continue
+		}
+		fmt.Printf("解密结果: %s\n", string(decrypted))
+		fmt.Printf("解密是否成功: %v\n", bytes.Equal(plaintext, decrypted))
+
+		// 认证加密算法拒绝被篡改的密文
+		if strings.HasSuffix(tt.random, "poly1305") {
+			ciphertext[0] ^= 1
+			_, err := Stream_Decrypt(ciphertext, opt)
+			fmt.Printf("篡改后解密: %s\n", i18n.Message(err, i18n.Zh))
+		}
+	}
+}
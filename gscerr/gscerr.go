@@ -0,0 +1,78 @@
+// Package gscerr 定义gsc各包共用的错误类别和错误类型
+//
+// 各包导出的错误值都由New创建，消息为英文，并归入本包的某个错误类别。
+// 调用方既可以用errors.Is判断具体的错误值，也可以只判断类别：
+//
+//	if errors.Is(err, gscerr.ErrKeySize) {
+//		// aes、des、chacha20等任意包的密钥长度错误
+//	}
+//
+// 分组密码和流密码的密钥长度错误以*KeySizeError返回，携带算法名和实际、允许的长度，
+// 可用errors.As取出。需要中文等其他语言的消息时使用i18n包
+package gscerr
+
+import (
+	"errors"
+	"strconv"
+)
+
+// 错误类别
+var (
+	ErrKeySize      = errors.New("gscerr: invalid key size")
+	ErrBlockSize    = errors.New("gscerr: invalid block size")
+	ErrNonceSize    = errors.New("gscerr: invalid nonce or IV size")
+	ErrAuthFailed   = errors.New("gscerr: message authentication failed")
+	ErrPadding      = errors.New("gscerr: invalid padding")
+	ErrUnsupported  = errors.New("gscerr: unsupported algorithm or option")
+	ErrMalformed    = errors.New("gscerr: malformed input")
+	ErrVerification = errors.New("gscerr: verification failed")
+	ErrParameter    = errors.New("gscerr: invalid parameter")
+	ErrMisuse       = errors.New("gscerr: invalid use of the API")
+)
+
+// Error 是归入某个类别的错误值
+type Error struct {
+	msg  string
+	kind error
+}
+
+// New 返回消息为msg、类别为kind的错误，msg应以包名为前缀，如"aes: block must be 16 bytes"
+// 每次调用都返回不同的错误值，需要调用方判断的错误应保存为导出变量
+func New(kind error, msg string) error {
+	return &Error{msg: msg, kind: kind}
+}
+
+func (e *Error) Error() string { return e.msg }
+
+// Is 报告target是否为e的类别，使errors.Is(err, gscerr.ErrKeySize)等判断成立
+func (e *Error) Is(target error) bool { return target == e.kind }
+
+// Kind 返回错误所属的类别
+func (e *Error) Kind() error { return e.kind }
+
+// KeySizeError 表示密钥长度不符合算法要求
+type KeySizeError struct {
+	// Alg 是算法名，如"AES"
+	Alg string
+	// Got 是传入的密钥长度（字节）
+	Got int
+	// Want 是允许的密钥长度（字节），允许的是一个较大的范围时为nil，范围见Err的消息
+	Want []int
+	// Err 是所属包的错误值，如aes.ErrInvalidKeySize
+	Err error
+}
+
+// KeySize 返回描述密钥长度错误的*KeySizeError，err为所属包的错误值
+func KeySize(err error, alg string, got int, want ...int) error {
+	return &KeySizeError{Alg: alg, Got: got, Want: want, Err: err}
+}
+
+func (e *KeySizeError) Error() string {
+	return e.Err.Error() + " (got " + strconv.Itoa(e.Got) + ")"
+}
+
+// Unwrap 返回所属包的错误值，errors.Is对该错误值及其类别ErrKeySize都成立
+func (e *KeySizeError) Unwrap() error { return e.Err }
+
+// Is 使不由New创建的Err也能匹配ErrKeySize
+func (e *KeySizeError) Is(target error) bool { return target == ErrKeySize }
@@ -0,0 +1,57 @@
+package gscerr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+var (
+	errTestKeySize = New(ErrKeySize, "test: key must be 16 bytes")
+	errTestAuth    = New(ErrAuthFailed, "test: message authentication failed")
+)
+
+// 测试错误值同时匹配自身和所属类别，且不匹配其他类别
+func TestErrorIs(t *testing.T) {
+	err := fmt.Errorf("打开: %w", errTestAuth)
+	if !errors.Is(err, errTestAuth) || !errors.Is(err, ErrAuthFailed) {
+		t.Fatal("包装后的错误应匹配错误值及其类别")
+	}
+	if errors.Is(err, ErrKeySize) || errors.Is(err, errTestKeySize) {
+		t.Fatal("不应匹配其他类别或错误值")
+	}
+	if errTestAuth.Error() != "test: message authentication failed" {
+		t.Fatalf("消息不正确: %q", errTestAuth.Error())
+	}
+	var e *Error
+	if !errors.As(err, &e) || e.Kind() != ErrAuthFailed {
+		t.Fatal("errors.As应取出*Error")
+	}
+}
+
+// 测试KeySizeError的消息、字段以及errors.Is/As
+func TestKeySizeError(t *testing.T) {
+	err := fmt.Errorf("加载密钥: %w", KeySize(errTestKeySize, "Test", 10, 16))
+	if !errors.Is(err, errTestKeySize) || !errors.Is(err, ErrKeySize) {
+		t.Fatal("应匹配所属包的错误值及ErrKeySize")
+	}
+	if errors.Is(err, ErrBlockSize) {
+		t.Fatal("不应匹配ErrBlockSize")
+	}
+	if want := "加载密钥: test: key must be 16 bytes (got 10)"; err.Error() != want {
+		t.Fatalf("消息为%q，期望%q", err.Error(), want)
+	}
+
+	var kse *KeySizeError
+	if !errors.As(err, &kse) {
+		t.Fatal("errors.As应取出*KeySizeError")
+	}
+	if kse.Alg != "Test" || kse.Got != 10 || len(kse.Want) != 1 || kse.Want[0] != 16 {
+		t.Fatalf("字段不正确: %+v", kse)
+	}
+
+	// Err不属于ErrKeySize类别时仍可用ErrKeySize识别
+	if !errors.Is(KeySize(errors.New("other"), "Test", 1), ErrKeySize) {
+		t.Fatal("KeySizeError应始终匹配ErrKeySize")
+	}
+}
@@ -0,0 +1,187 @@
+// Package gscrand 按算法、工作模式和AEAD生成长度正确的随机密钥、IV和nonce
+// 默认使用crypto/rand，用于替代示例代码中硬编码的密钥和IV
+package gscrand
+
+import (
+	"crypto/rand"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+// 错误定义
+var (
+	ErrUnknownAlgorithm = gscerr.New(gscerr.ErrUnsupported, "gscrand: unknown algorithm")
+	ErrUnknownMode      = gscerr.New(gscerr.ErrUnsupported, "gscrand: unknown mode or the mode takes no IV")
+	ErrInvalidNonceSize = gscerr.New(gscerr.ErrNonceSize, "gscrand: invalid nonce size")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrUnknownAlgorithm: "gscrand: 未知的算法",
+		ErrUnknownMode:      "gscrand: 未知的工作模式或该模式不使用IV",
+		ErrInvalidNonceSize: "gscrand: nonce长度无效",
+	})
+}
+
+// keySizes 是固定密钥长度的算法及其密钥长度（字节）
+var keySizes = map[string]int{
+	"AES-128":            16,
+	"AES-192":            24,
+	"AES-256":            32,
+	"SM4":                16,
+	"DES":                8,
+	"3DES":               24,
+	"3DES-128":           16,
+	"3DES-192":           24,
+	"TWOFISH-128":        16,
+	"TWOFISH-192":        24,
+	"TWOFISH-256":        32,
+	"CHACHA20":           32,
+	"XCHACHA20":          32,
+	"CHACHA20-POLY1305":  32,
+	"XCHACHA20-POLY1305": 32,
+	"SALSA20":            32,
+	"XSALSA20":           32,
+	"XSALSA20-POLY1305":  32,
+}
+
+// blockSizes 是分组密码的块大小（字节）
+var blockSizes = map[string]int{
+	"AES":      16,
+	"SM4":      16,
+	"TWOFISH":  16,
+	"DES":      8,
+	"3DES":     8,
+	"BLOWFISH": 8,
+}
+
+// streamNonceSizes 是流密码的nonce长度（字节），GenerateIV将其视为IV
+var streamNonceSizes = map[string]int{
+	"CHACHA20":  12,
+	"XCHACHA20": 24,
+	"SALSA20":   8,
+	"XSALSA20":  24,
+}
+
+// Generator 从指定的随机源生成密钥、IV和nonce
+type Generator struct {
+	random io.Reader
+}
+
+// New 返回使用random的Generator，random为nil时使用crypto/rand.Reader
+// 指定random主要用于测试和可复现的演示
+func New(random io.Reader) *Generator {
+	if random == nil {
+		random = rand.Reader
+	}
+	return &Generator{random: random}
+}
+
+// std 是包级函数使用的Generator
+var std = New(nil)
+
+// GenerateKey 使用crypto/rand生成alg所需长度的密钥，见Generator.GenerateKey
+func GenerateKey(alg string) ([]byte, error) { return std.GenerateKey(alg) }
+
+// GenerateIV 使用crypto/rand生成mode所需长度的IV，见Generator.GenerateIV
+func GenerateIV(mode string) ([]byte, error) { return std.GenerateIV(mode) }
+
+// GenerateNonce 使用crypto/rand生成aead所需长度的nonce，见Generator.GenerateNonce
+func GenerateNonce(aead interface{ NonceSize() int }) ([]byte, error) {
+	return std.GenerateNonce(aead)
+}
+
+// GenerateKey 生成alg所需长度的密钥，名称不区分大小写
+// 支持AES-128/192/256、SM4、DES、3DES（默认三密钥，3DES-128为双密钥）、Twofish-128/192/256、
+// Blowfish-<位数>（32至448位）以及ChaCha20、XChaCha20、Salsa20、XSalsa20及其Poly1305认证加密。
+// 带工作模式的名称（如AES-256-GCM、SM4-CBC）按其中的算法生成
+func (g *Generator) GenerateKey(alg string) ([]byte, error) {
+	n, err := keySize(alg)
+	if err != nil {
+		return nil, err
+	}
+	return g.read(n)
+}
+
+// GenerateIV 生成mode所需长度的IV
+// mode形如"AES-CBC"、"SM4-CTR"或规格字符串"AES-256-CBC/PKCS7"，只给出模式（如"CBC"）时按16字节块计算。
+// CBC、CFB、OFB、CTR的IV为一个块，IGE为两个块，GCM为12字节；
+// ChaCha20、XChaCha20、Salsa20和XSalsa20返回其nonce。ECB不使用IV，返回ErrUnknownMode
+func (g *Generator) GenerateIV(mode string) ([]byte, error) {
+	n, err := ivSize(mode)
+	if err != nil {
+		return nil, err
+	}
+	return g.read(n)
+}
+
+// GenerateNonce 生成aead所需长度的nonce，aead可以是crypto/cipher.AEAD或modes.GCM等
+// 12字节nonce随机生成时，同一密钥下加密的消息数应远少于2^32条
+func (g *Generator) GenerateNonce(aead interface{ NonceSize() int }) ([]byte, error) {
+	n := aead.NonceSize()
+	if n <= 0 {
+		return nil, ErrInvalidNonceSize
+	}
+	return g.read(n)
+}
+
+func (g *Generator) read(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(g.random, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// keySize 按算法名称返回密钥长度，忽略名称末尾的工作模式和填充
+func keySize(alg string) (int, error) {
+	name, _, _ := strings.Cut(strings.ToUpper(alg), "/")
+	for {
+		if n, ok := keySizes[name]; ok {
+			return n, nil
+		}
+		if bits, ok := strings.CutPrefix(name, "BLOWFISH-"); ok {
+			if n, err := strconv.Atoi(bits); err == nil && n%8 == 0 && n >= 32 && n <= 448 {
+				return n / 8, nil
+			}
+		}
+		// 去掉末尾的一段（如工作模式）后重试
+		i := strings.LastIndexByte(name, '-')
+		if i < 0 {
+			return 0, ErrUnknownAlgorithm
+		}
+		name = name[:i]
+	}
+}
+
+// ivSize 按工作模式返回IV长度
+func ivSize(mode string) (int, error) {
+	name, _, _ := strings.Cut(strings.ToUpper(mode), "/")
+	if n, ok := streamNonceSizes[name]; ok {
+		return n, nil
+	}
+
+	parts := strings.Split(name, "-")
+	blockSize := 16
+	if len(parts) > 1 {
+		n, ok := blockSizes[parts[0]]
+		if !ok {
+			return 0, ErrUnknownAlgorithm
+		}
+		blockSize = n
+	}
+	switch parts[len(parts)-1] {
+	case "CBC", "CFB", "CFB8", "CFB1", "OFB", "CTR":
+		return blockSize, nil
+	case "IGE":
+		return 2 * blockSize, nil
+	case "GCM":
+		return 12, nil
+	}
+	return 0, ErrUnknownMode
+}
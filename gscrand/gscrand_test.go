@@ -0,0 +1,105 @@
+package gscrand
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/laenix/gsc/aes"
+	"github.com/laenix/gsc/chacha20poly1305"
+	"github.com/laenix/gsc/modes"
+)
+
+func TestGenerateKey(t *testing.T) {
+	tests := []struct {
+		alg  string
+		size int
+	}{
+		{"AES-128", 16}, {"aes-256", 32}, {"AES-192-GCM", 24}, {"AES-256-CBC/PKCS7", 32},
+		{"SM4", 16}, {"SM4-CBC", 16}, {"DES", 8}, {"3DES", 24}, {"3DES-128", 16},
+		{"Twofish-256", 32}, {"Blowfish-128", 16}, {"Blowfish-448-CBC", 56},
+		{"ChaCha20-Poly1305", 32}, {"XChaCha20-Poly1305", 32}, {"XSalsa20", 32},
+	}
+	for _, tt := range tests {
+		key, err := GenerateKey(tt.alg)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.alg, err)
+		}
+		if len(key) != tt.size {
+			t.Errorf("%s: 密钥长度为%d，预期为%d", tt.alg, len(key), tt.size)
+		}
+	}
+
+	for _, alg := range []string{"AES", "AES-100", "Blowfish-20", "Blowfish-500", "RC9", ""} {
+		if _, err := GenerateKey(alg); err != ErrUnknownAlgorithm {
+			t.Errorf("%q: 应返回ErrUnknownAlgorithm，实际: %v", alg, err)
+		}
+	}
+}
+
+func TestGenerateIV(t *testing.T) {
+	tests := []struct {
+		mode string
+		size int
+	}{
+		{"CBC", 16}, {"AES-CBC", 16}, {"AES-256-CTR/None", 16}, {"SM4-OFB", 16}, {"DES-CBC", 8},
+		{"3DES-CFB8", 8}, {"Blowfish-128-CBC", 8}, {"AES-IGE", 32}, {"AES-GCM", 12}, {"SM4-128-GCM", 12},
+		{"ChaCha20", 12}, {"XChaCha20", 24}, {"XSalsa20", 24}, {"Salsa20", 8},
+	}
+	for _, tt := range tests {
+		iv, err := GenerateIV(tt.mode)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.mode, err)
+		}
+		if len(iv) != tt.size {
+			t.Errorf("%s: IV长度为%d，预期为%d", tt.mode, len(iv), tt.size)
+		}
+	}
+
+	for _, mode := range []string{"ECB", "AES-ECB", "AES-XYZ"} {
+		if _, err := GenerateIV(mode); err != ErrUnknownMode {
+			t.Errorf("%q: 应返回ErrUnknownMode，实际: %v", mode, err)
+		}
+	}
+	if _, err := GenerateIV("RC9-CBC"); err != ErrUnknownAlgorithm {
+		t.Errorf("应返回ErrUnknownAlgorithm，实际: %v", err)
+	}
+}
+
+func TestGenerateNonce(t *testing.T) {
+	block, _ := aes.New(make([]byte, 16))
+	gcm, _ := modes.NewGCM(block)
+	x, _ := chacha20poly1305.NewX(make([]byte, 32))
+
+	for _, aead := range []interface{ NonceSize() int }{gcm, x} {
+		n1, err := GenerateNonce(aead)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n2, _ := GenerateNonce(aead)
+		if len(n1) != aead.NonceSize() || bytes.Equal(n1, n2) {
+			t.Fatalf("nonce长度应为%d且每次不同", aead.NonceSize())
+		}
+	}
+}
+
+// 测试指定随机源时输出可以复现
+func TestGenerator(t *testing.T) {
+	random := bytes.Repeat([]byte{0xab}, 48)
+	g := New(bytes.NewReader(random))
+	key, err := g.GenerateKey("AES-128")
+	if err != nil {
+		t.Fatal(err)
+	}
+	iv, err := g.GenerateIV("AES-CBC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(key, random[:16]) || !bytes.Equal(iv, random[16:32]) {
+		t.Fatal("Generator应按顺序读取随机源")
+	}
+
+	// 随机源耗尽时返回错误
+	if _, err := g.GenerateKey("AES-256-GCM"); err == nil {
+		t.Fatal("随机源不足时应返回错误")
+	}
+}
@@ -0,0 +1,283 @@
+package gscrand
+
+import (
+	"container/list"
+	"encoding/binary"
+	"hash/maphash"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+// 错误定义
+var (
+	ErrNonceExhausted    = gscerr.New(gscerr.ErrMisuse, "gscrand: nonces exhausted, the key must be rotated")
+	ErrNonceReuse        = gscerr.New(gscerr.ErrMisuse, "gscrand: nonce reuse detected")
+	ErrInvalidConfig     = gscerr.New(gscerr.ErrParameter, "gscrand: invalid nonce manager configuration")
+	ErrInvalidNonceState = gscerr.New(gscerr.ErrMalformed, "gscrand: invalid persisted nonce state")
+	ErrDetectorSaturated = gscerr.New(gscerr.ErrMisuse, "gscrand: reuse detector rejected every random nonce")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrNonceExhausted:    "gscrand: nonce已用尽，需要更换密钥",
+		ErrNonceReuse:        "gscrand: 检测到nonce重复使用",
+		ErrInvalidConfig:     "gscrand: nonce管理器配置无效",
+		ErrInvalidNonceState: "gscrand: 持久化的nonce状态无效",
+		ErrDetectorSaturated: "gscrand: 重用检测器拒绝了所有随机nonce",
+	})
+}
+
+// maxNonceRetries 是随机模式中一次Next最多生成的候选nonce数量
+// 检测器正常时连续误报的概率可以忽略，全部被拒绝说明检测器已经饱和或nonce过短
+const maxNonceRetries = 64
+
+// NonceStore 是计数状态的持久化钩子，用于在进程重启后继续而不重复使用nonce
+// 保存的是已预留的计数上限：重启后从该值继续，未用完的预留部分被跳过
+type NonceStore interface {
+	// Load 返回上次保存的状态，从未保存过时返回nil和nil
+	Load() ([]byte, error)
+	// Save 持久化状态，返回之前必须已写入可靠存储
+	Save(state []byte) error
+}
+
+// ReuseDetector 记录见过的nonce并判断是否重复
+// 实现无需并发安全，NonceManager在调用时已持有锁
+type ReuseDetector interface {
+	// Seen 记录nonce，返回此前是否（可能）已经见过
+	Seen(nonce []byte) bool
+}
+
+// NonceConfig 是NonceManager的配置
+type NonceConfig struct {
+	// Size 是nonce长度（字节），为0时为12，与GCM和ChaCha20-Poly1305一致
+	Size int
+	// Random 为true时随机生成nonce，否则使用计数器
+	Random bool
+	// Prefix 是计数器模式中nonce的固定前缀（如设备或会话标识），其余字节为大端计数器
+	// 同一密钥的多个实例必须使用不同的前缀
+	Prefix []byte
+	// Limit 是可以生成的nonce总数，为0时计数器模式受计数器位数限制，
+	// 随机模式为2^32（NIST SP 800-38D对随机96位nonce的上限）
+	Limit uint64
+	// Store 为计数状态的持久化钩子，为nil时不持久化
+	Store NonceStore
+	// Reserve 是每次持久化预留的nonce数量，为0时为1024
+	Reserve uint64
+	// Detector 为可选的重用检测，随机模式下生成的nonce被判定重复时重新生成，
+	// Observe检查的外部nonce重复时返回ErrNonceReuse
+	Detector ReuseDetector
+	// Reader 是随机模式的随机源，为nil时使用crypto/rand.Reader
+	Reader io.Reader
+}
+
+// NonceManager 为长期使用的密钥生成不重复的nonce，可以并发使用
+type NonceManager struct {
+	mu       sync.Mutex
+	cfg      NonceConfig
+	gen      *Generator
+	count    uint64 // 已生成的数量，计数器模式中即下一个计数器值
+	reserved uint64 // 已持久化的预留上限
+	limit    uint64
+}
+
+// NewNonceManager 按配置创建NonceManager，设置了Store时从保存的状态继续
+func NewNonceManager(cfg NonceConfig) (*NonceManager, error) {
+	if cfg.Size == 0 {
+		cfg.Size = 12
+	}
+	if cfg.Reserve == 0 {
+		cfg.Reserve = 1024
+	}
+	if cfg.Size < 0 || len(cfg.Prefix) >= cfg.Size || cfg.Random && len(cfg.Prefix) > 0 {
+		return nil, ErrInvalidConfig
+	}
+	cfg.Prefix = append([]byte(nil), cfg.Prefix...)
+
+	m := &NonceManager{cfg: cfg, gen: New(cfg.Reader), limit: cfg.Limit}
+	if !cfg.Random {
+		// 计数器超过8字节时高位保持为0
+		if bits := 8 * (cfg.Size - len(cfg.Prefix)); bits < 64 {
+			if max := uint64(1) << bits; m.limit == 0 || m.limit > max {
+				m.limit = max
+			}
+		}
+	} else if m.limit == 0 {
+		m.limit = 1 << 32
+	}
+	if m.limit == 0 {
+		m.limit = math.MaxUint64
+	}
+
+	if cfg.Store != nil {
+		state, err := cfg.Store.Load()
+		if err != nil {
+			return nil, err
+		}
+		if state != nil {
+			if len(state) != 8 {
+				return nil, ErrInvalidNonceState
+			}
+			m.count = binary.BigEndian.Uint64(state)
+			m.reserved = m.count
+		}
+	}
+	return m, nil
+}
+
+// Next 返回下一个nonce，达到Limit后返回ErrNonceExhausted
+// 随机模式中连续maxNonceRetries个候选都被Detector判定重复时返回ErrDetectorSaturated，应更换密钥或检测器
+func (m *NonceManager) Next() ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.count >= m.limit {
+		return nil, ErrNonceExhausted
+	}
+	if err := m.reserve(); err != nil {
+		return nil, err
+	}
+
+	var nonce []byte
+	if m.cfg.Random {
+		for i := 0; ; i++ {
+			if i == maxNonceRetries {
+				return nil, ErrDetectorSaturated
+			}
+			var err error
+			if nonce, err = m.gen.read(m.cfg.Size); err != nil {
+				return nil, err
+			}
+			if m.cfg.Detector == nil || !m.cfg.Detector.Seen(nonce) {
+				break
+			}
+		}
+	} else {
+		nonce = make([]byte, m.cfg.Size)
+		copy(nonce, m.cfg.Prefix)
+		var counter [8]byte
+		binary.BigEndian.PutUint64(counter[:], m.count)
+		n := min(8, m.cfg.Size-len(m.cfg.Prefix))
+		copy(nonce[m.cfg.Size-n:], counter[8-n:])
+		if m.cfg.Detector != nil {
+			m.cfg.Detector.Seen(nonce)
+		}
+	}
+	m.count++
+	return nonce, nil
+}
+
+// Observe 检查外部提供的nonce（如对端发来的消息）是否已经见过，
+// 未设置Detector时总是返回nil
+func (m *NonceManager) Observe(nonce []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cfg.Detector != nil && m.cfg.Detector.Seen(nonce) {
+		return ErrNonceReuse
+	}
+	return nil
+}
+
+// Remaining 返回在达到Limit之前还能生成的nonce数量
+func (m *NonceManager) Remaining() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.limit - m.count
+}
+
+// reserve 在用完已持久化的预留数量时预留下一批并保存
+func (m *NonceManager) reserve() error {
+	if m.cfg.Store == nil || m.count < m.reserved {
+		return nil
+	}
+	next := m.count + min(m.cfg.Reserve, m.limit-m.count)
+	var state [8]byte
+	binary.BigEndian.PutUint64(state[:], next)
+	if err := m.cfg.Store.Save(state[:]); err != nil {
+		return err
+	}
+	m.reserved = next
+	return nil
+}
+
+// BloomFilter 是基于布隆过滤器的ReuseDetector，内存固定，可能误报但不会漏报
+type BloomFilter struct {
+	bits  []uint64
+	m     uint64
+	k     int
+	seed1 maphash.Seed
+	seed2 maphash.Seed
+}
+
+// NewBloomFilter 创建预期容纳n个nonce、误报率约为fpRate的布隆过滤器
+func NewBloomFilter(n int, fpRate float64) *BloomFilter {
+	if n <= 0 {
+		n = 1
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		fpRate = 1e-6
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	m = (m + 63) &^ 63
+	k := max(1, int(math.Round(float64(m)/float64(n)*math.Ln2)))
+	return &BloomFilter{
+		bits:  make([]uint64, m/64),
+		m:     m,
+		k:     k,
+		seed1: maphash.MakeSeed(),
+		seed2: maphash.MakeSeed(),
+	}
+}
+
+// Seen 记录nonce，返回此前是否可能已经见过
+func (f *BloomFilter) Seen(nonce []byte) bool {
+	// 双重哈希：第i个位置为 h1 + i*h2
+	h1 := maphash.Bytes(f.seed1, nonce)
+	h2 := maphash.Bytes(f.seed2, nonce) | 1
+	seen := true
+	for i := 0; i < f.k; i++ {
+		pos := (h1 + uint64(i)*h2) % f.m
+		word, bit := pos/64, uint64(1)<<(pos%64)
+		if f.bits[word]&bit == 0 {
+			seen = false
+			f.bits[word] |= bit
+		}
+	}
+	return seen
+}
+
+// LRU 是只记住最近capacity个nonce的ReuseDetector，判断准确但窗口有限
+type LRU struct {
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+// NewLRU 创建记住最近capacity个nonce的检测器
+func NewLRU(capacity int) *LRU {
+	return &LRU{
+		capacity: max(1, capacity),
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Seen 记录nonce，返回它是否在最近的窗口中出现过
+func (l *LRU) Seen(nonce []byte) bool {
+	key := string(nonce)
+	if e, ok := l.items[key]; ok {
+		l.order.MoveToFront(e)
+		return true
+	}
+	l.items[key] = l.order.PushFront(key)
+	if l.order.Len() > l.capacity {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.items, oldest.Value.(string))
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package gscrand
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestCounterNonce(t *testing.T) {
+	m, err := NewNonceManager(NonceConfig{Prefix: []byte("dev1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{"646576310000000000000000", "646576310000000000000001", "646576310000000000000002"} {
+		nonce, err := m.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(nonce) != want {
+			t.Fatalf("第%d个nonce为%x，预期为%s", i, nonce, want)
+		}
+	}
+
+	// 计数器只有1字节时生成256个后用尽
+	m, _ = NewNonceManager(NonceConfig{Size: 4, Prefix: []byte("abc")})
+	if m.Remaining() != 256 {
+		t.Fatalf("剩余数量为%d，预期为256", m.Remaining())
+	}
+	for i := 0; i < 256; i++ {
+		if _, err := m.Next(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := m.Next(); err != ErrNonceExhausted {
+		t.Fatalf("应返回ErrNonceExhausted，实际: %v", err)
+	}
+}
+
+func TestRandomNonce(t *testing.T) {
+	m, err := NewNonceManager(NonceConfig{Random: true, Size: 24, Limit: 3, Detector: NewLRU(10)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := m.Next()
+	b, _ := m.Next()
+	if len(a) != 24 || bytes.Equal(a, b) {
+		t.Fatal("随机nonce长度错误或重复")
+	}
+	m.Next()
+	if _, err := m.Next(); err != ErrNonceExhausted {
+		t.Fatalf("达到Limit后应返回ErrNonceExhausted，实际: %v", err)
+	}
+	if err := m.Observe(a); err != ErrNonceReuse {
+		t.Fatalf("已生成的nonce应被检测为重复，实际: %v", err)
+	}
+
+	// 随机源给出重复值时重新生成
+	random := bytes.Repeat([]byte{1}, 8)
+	random = append(random, random...)
+	random = append(random, bytes.Repeat([]byte{2}, 8)...)
+	m, _ = NewNonceManager(NonceConfig{Random: true, Size: 8, Reader: bytes.NewReader(random), Detector: NewLRU(10)})
+	first, _ := m.Next()
+	second, err := m.Next()
+	if err != nil || bytes.Equal(first, second) || second[0] != 2 {
+		t.Fatalf("重复的随机nonce应被丢弃: %x %x %v", first, second, err)
+	}
+
+	// 检测器饱和后每个候选都被判定为重复，Next返回错误而不是一直重试
+	m, _ = NewNonceManager(NonceConfig{Random: true, Detector: NewBloomFilter(1, 0.5)})
+	for i := 0; ; i++ {
+		if i == 10000 {
+			t.Fatal("检测器饱和后仍在生成nonce")
+		}
+		remaining := m.Remaining()
+		if _, err := m.Next(); err != nil {
+			if err != ErrDetectorSaturated || m.Remaining() != remaining {
+				t.Fatalf("期望ErrDetectorSaturated且不消耗计数，实际: %v", err)
+			}
+			break
+		}
+	}
+}
+
+// memStore 是内存中的NonceStore
+type memStore struct {
+	state []byte
+	saves int
+	err   error
+}
+
+func (s *memStore) Load() ([]byte, error) { return s.state, nil }
+
+func (s *memStore) Save(state []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.state = append([]byte(nil), state...)
+	s.saves++
+	return nil
+}
+
+// 测试重启后从预留上限继续，不会重复使用nonce
+func TestNonceStore(t *testing.T) {
+	store := &memStore{}
+	cfg := NonceConfig{Store: store, Reserve: 10}
+	m, err := NewNonceManager(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]bool{}
+	for i := 0; i < 15; i++ {
+		nonce, err := m.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[string(nonce)] = true
+	}
+	if store.saves != 2 {
+		t.Fatalf("15个nonce应持久化2次，实际%d次", store.saves)
+	}
+
+	// 模拟重启
+	m, err = NewNonceManager(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, _ := m.Next()
+	if seen[string(nonce)] {
+		t.Fatal("重启后重复使用了nonce")
+	}
+	if nonce[len(nonce)-1] != 20 {
+		t.Fatalf("重启后应从预留上限20继续，实际: %x", nonce)
+	}
+
+	// 持久化失败时不发放nonce
+	store.err = errors.New("磁盘已满")
+	m, _ = NewNonceManager(NonceConfig{Store: store})
+	if _, err := m.Next(); err != store.err {
+		t.Fatalf("持久化失败时应返回错误，实际: %v", err)
+	}
+
+	store = &memStore{state: []byte{1, 2, 3}}
+	if _, err := NewNonceManager(NonceConfig{Store: store}); err != ErrInvalidNonceState {
+		t.Fatalf("应返回ErrInvalidNonceState，实际: %v", err)
+	}
+}
+
+func TestReuseDetectors(t *testing.T) {
+	for name, d := range map[string]ReuseDetector{"bloom": NewBloomFilter(1000, 1e-6), "lru": NewLRU(1000)} {
+		for i := 0; i < 1000; i++ {
+			if d.Seen([]byte{byte(i), byte(i >> 8)}) {
+				t.Fatalf("%s: 第%d个nonce不应被判定为重复", name, i)
+			}
+		}
+		for i := 0; i < 1000; i++ {
+			if !d.Seen([]byte{byte(i), byte(i >> 8)}) {
+				t.Fatalf("%s: 第%d个nonce应被判定为重复", name, i)
+			}
+		}
+	}
+
+	// LRU只记住最近的窗口
+	l := NewLRU(2)
+	l.Seen([]byte("a"))
+	l.Seen([]byte("b"))
+	l.Seen([]byte("c"))
+	if l.Seen([]byte("a")) {
+		t.Fatal("超出窗口的nonce不应被记住")
+	}
+}
+
+func TestNonceConfigInvalid(t *testing.T) {
+	for _, cfg := range []NonceConfig{
+		{Size: 4, Prefix: []byte("abcd")},
+		{Random: true, Prefix: []byte("x")},
+		{Size: -1},
+	} {
+		if _, err := NewNonceManager(cfg); err != ErrInvalidConfig {
+			t.Errorf("%+v: 应返回ErrInvalidConfig，实际: %v", cfg, err)
+		}
+	}
+}
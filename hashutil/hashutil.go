@@ -0,0 +1,45 @@
+package hashutil
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+// MaxTagSize 是域标签的最大长度（字节），保证按位计的长度可用2字节表示
+const MaxTagSize = (1<<16 - 1) / 8
+
+// domainHash 在每次Reset后自动写入域分离前缀
+type domainHash struct {
+	hash.Hash
+	prefix []byte
+}
+
+// Domain 返回带域分离前缀的哈希：h被重置后先写入 ENTL || tag，
+// 其中ENTL为tag按位计的长度（2字节大端序），与SM2中ZA的ENTLA||IDA编码一致。
+// 不同用途使用不同tag即可保证各自的哈希输入互不重叠，
+// 不必在调用处手工拼接前缀。
+//
+// tag超过MaxTagSize字节时无法编码长度，前缀改为 0xFFFF || H(tag)，H(tag)由h计算：
+// 0xFFFF不是8的倍数，不会与正常标签的ENTL相同，因此长标签同样得到互不重叠的前缀
+func Domain(tag string, h hash.Hash) hash.Hash {
+	var prefix []byte
+	if len(tag) > MaxTagSize {
+		h.Reset()
+		h.Write([]byte(tag))
+		prefix = h.Sum([]byte{0xff, 0xff})
+	} else {
+		prefix = make([]byte, 2, 2+len(tag))
+		binary.BigEndian.PutUint16(prefix, uint16(len(tag)*8))
+		prefix = append(prefix, tag...)
+	}
+
+	d := &domainHash{Hash: h, prefix: prefix}
+	d.Reset()
+	return d
+}
+
+// Reset 重置哈希状态并重新写入域分离前缀
+func (d *domainHash) Reset() {
+	d.Hash.Reset()
+	d.Hash.Write(d.prefix)
+}
@@ -0,0 +1,68 @@
+package hashutil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestDomain(t *testing.T) {
+	h := Domain("gsc-test", sha256.New())
+	h.Write([]byte("message"))
+	got := h.Sum(nil)
+
+	// 手工计算 ENTL || tag || message
+	ref := sha256.New()
+	ref.Write([]byte{0x00, 0x40})
+	ref.Write([]byte("gsc-test"))
+	ref.Write([]byte("message"))
+	if want := ref.Sum(nil); !bytes.Equal(got, want) {
+		t.Fatalf("域分离哈希不匹配:\n期望值: %x\n实际值: %x", want, got)
+	}
+
+	// Reset后前缀仍然生效
+	h.Reset()
+	h.Write([]byte("message"))
+	if !bytes.Equal(h.Sum(nil), got) {
+		t.Fatal("Reset后哈希结果不一致")
+	}
+
+	// 不同标签得到不同结果，即使拼接后的字节相同
+	a := Domain("ab", sha256.New())
+	a.Write([]byte("c"))
+	b := Domain("a", sha256.New())
+	b.Write([]byte("bc"))
+	if bytes.Equal(a.Sum(nil), b.Sum(nil)) {
+		t.Fatal("不同域标签不应产生相同哈希")
+	}
+}
+
+// 测试超过MaxTagSize的标签：前缀为 0xFFFF || H(tag)，与其他标签互不重叠
+func TestDomainLongTag(t *testing.T) {
+	tag := string(bytes.Repeat([]byte{'a'}, MaxTagSize+1))
+	h := Domain(tag, sha256.New())
+	h.Write([]byte("message"))
+	got := h.Sum(nil)
+
+	digest := sha256.Sum256([]byte(tag))
+	ref := sha256.New()
+	ref.Write([]byte{0xff, 0xff})
+	ref.Write(digest[:])
+	ref.Write([]byte("message"))
+	if want := ref.Sum(nil); !bytes.Equal(got, want) {
+		t.Fatalf("长标签哈希不匹配:\n期望值: %x\n实际值: %x", want, got)
+	}
+
+	h.Reset()
+	h.Write([]byte("message"))
+	if !bytes.Equal(h.Sum(nil), got) {
+		t.Fatal("Reset后哈希结果不一致")
+	}
+
+	// 最大长度的标签仍按 ENTL || tag 编码
+	limit := Domain(tag[:MaxTagSize], sha256.New())
+	limit.Write([]byte("message"))
+	if bytes.Equal(limit.Sum(nil), got) {
+		t.Fatal("不同域标签不应产生相同哈希")
+	}
+}
@@ -0,0 +1,363 @@
+package gsc
+
+import (
+	"bytes"
+	"crypto/mlkem"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/kdf/hkdf"
+	"github.com/laenix/gsc/kem"
+	"github.com/laenix/gsc/modes"
+	"github.com/laenix/gsc/sm3"
+)
+
+// HybridScheme 标识混合信封使用的经典密钥交换与后量子KEM组合
+type HybridScheme uint8
+
+// 支持的混合方案
+const (
+	// HybridX25519MLKEM768 组合X25519与ML-KEM-768，密钥派生使用HKDF-SHA256
+	HybridX25519MLKEM768 HybridScheme = iota + 1
+	// HybridSM2MLKEM768 组合SM2密钥交换与ML-KEM-768，密钥派生使用HKDF-SM3
+	HybridSM2MLKEM768
+)
+
+const (
+	// HybridVersion 是当前混合信封格式版本
+	HybridVersion = 1
+	// 混合信封魔数
+	hybridMagic = "GSCH"
+	// 组合密钥派生的标签
+	hybridLabel = "gsc/hybrid/v1"
+	// SM2私钥和非压缩公钥长度
+	sm2ScalarSize = 32
+	sm2PointSize  = 1 + 2*sm2ScalarSize
+	// ML-KEM私钥种子长度
+	mlkemSeedSize = 64
+	// 包装数据密钥的GCM标签长度
+	hybridTagSize = 16
+)
+
+// 错误定义
+var (
+	ErrUnsupportedHybridScheme = gscerr.New(gscerr.ErrUnsupported, "gsc: unsupported hybrid scheme")
+	ErrInvalidHybridKey        = gscerr.New(gscerr.ErrMalformed, "gsc: invalid hybrid key encoding")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrUnsupportedHybridScheme: "gsc: 不支持的混合方案",
+		ErrInvalidHybridKey:        "gsc: 混合密钥格式无效",
+	})
+}
+
+// String 返回方案的规范名称
+func (s HybridScheme) String() string {
+	switch s {
+	case HybridX25519MLKEM768:
+		return "X25519-MLKEM768"
+	case HybridSM2MLKEM768:
+		return "SM2-MLKEM768"
+	}
+	return fmt.Sprintf("HybridScheme(%d)", uint8(s))
+}
+
+// kems 返回方案的经典KEM和后量子KEM
+func (s HybridScheme) kems() (kem.Scheme, kem.Scheme) {
+	switch s {
+	case HybridX25519MLKEM768:
+		return kem.X25519, kem.MLKEM768
+	case HybridSM2MLKEM768:
+		return kem.SM2, kem.MLKEM768
+	}
+	return nil, nil
+}
+
+// classicalSizes 返回经典部分的私钥和公钥长度，公钥长度也是经典KEM的密文长度
+func (s HybridScheme) classicalSizes() (int, int) {
+	switch s {
+	case HybridX25519MLKEM768:
+		return 32, 32
+	case HybridSM2MLKEM768:
+		return sm2ScalarSize, sm2PointSize
+	}
+	return 0, 0
+}
+
+// kdfHash 返回组合密钥派生使用的哈希
+func (s HybridScheme) kdfHash() func() hash.Hash {
+	if s == HybridSM2MLKEM768 {
+		return sm3.New
+	}
+	return sha256.New
+}
+
+// HybridPublicKey 是混合信封的接收方公钥
+type HybridPublicKey struct {
+	scheme    HybridScheme
+	classical kem.PublicKey
+	pq        kem.PublicKey
+}
+
+// HybridPrivateKey 是混合信封的接收方私钥
+type HybridPrivateKey struct {
+	scheme    HybridScheme
+	classical kem.PrivateKey
+	pq        kem.PrivateKey
+}
+
+// GenerateHybridKey 生成指定方案的混合密钥对
+func GenerateHybridKey(scheme HybridScheme) (*HybridPrivateKey, error) {
+	return GenerateHybridKeyWithRand(scheme, nil)
+}
+
+// GenerateHybridKeyWithRand 使用指定的随机源生成混合密钥对
+// 经典部分和ML-KEM部分的种子都从random读取，相同的随机源输出得到相同的密钥；
+// random为nil时使用crypto/rand
+func GenerateHybridKeyWithRand(scheme HybridScheme, random io.Reader) (*HybridPrivateKey, error) {
+	classical, pq := scheme.kems()
+	if classical == nil {
+		return nil, ErrUnsupportedHybridScheme
+	}
+	if random == nil {
+		random = rand.Reader
+	}
+
+	priv := &HybridPrivateKey{scheme: scheme}
+	var err error
+	if priv.classical, err = classical.GenerateKey(random); err != nil {
+		return nil, err
+	}
+	if priv.pq, err = pq.GenerateKey(random); err != nil {
+		return nil, err
+	}
+	return priv, nil
+}
+
+// Scheme 返回密钥所属的混合方案
+func (k *HybridPrivateKey) Scheme() HybridScheme {
+	return k.scheme
+}
+
+// Public 返回对应的公钥
+func (k *HybridPrivateKey) Public() *HybridPublicKey {
+	return &HybridPublicKey{
+		scheme:    k.scheme,
+		classical: k.classical.Public(),
+		pq:        k.pq.Public(),
+	}
+}
+
+// Bytes 编码私钥：方案(1) || 经典私钥 || ML-KEM种子(64)
+func (k *HybridPrivateKey) Bytes() []byte {
+	out := []byte{byte(k.scheme)}
+	out = append(out, k.classical.Bytes()...)
+	return append(out, k.pq.Bytes()...)
+}
+
+// ParseHybridPrivateKey 解析Bytes编码的私钥
+func ParseHybridPrivateKey(data []byte) (*HybridPrivateKey, error) {
+	if len(data) < 1 {
+		return nil, ErrInvalidHybridKey
+	}
+	scheme := HybridScheme(data[0])
+	privSize, _ := scheme.classicalSizes()
+	if privSize == 0 {
+		return nil, ErrUnsupportedHybridScheme
+	}
+	if len(data) != 1+privSize+mlkemSeedSize {
+		return nil, ErrInvalidHybridKey
+	}
+
+	classical, pq := scheme.kems()
+	k := &HybridPrivateKey{scheme: scheme}
+	var err error
+	if k.classical, err = classical.ParsePrivateKey(data[1 : 1+privSize]); err != nil {
+		return nil, ErrInvalidHybridKey
+	}
+	if k.pq, err = pq.ParsePrivateKey(data[1+privSize:]); err != nil {
+		return nil, ErrInvalidHybridKey
+	}
+	return k, nil
+}
+
+// Scheme 返回密钥所属的混合方案
+func (k *HybridPublicKey) Scheme() HybridScheme {
+	return k.scheme
+}
+
+// Bytes 编码公钥：方案(1) || 经典公钥 || ML-KEM封装密钥
+func (k *HybridPublicKey) Bytes() []byte {
+	out := []byte{byte(k.scheme)}
+	out = append(out, k.classical.Bytes()...)
+	return append(out, k.pq.Bytes()...)
+}
+
+// ParseHybridPublicKey 解析Bytes编码的公钥
+func ParseHybridPublicKey(data []byte) (*HybridPublicKey, error) {
+	if len(data) < 1 {
+		return nil, ErrInvalidHybridKey
+	}
+	scheme := HybridScheme(data[0])
+	_, pubSize := scheme.classicalSizes()
+	if pubSize == 0 {
+		return nil, ErrUnsupportedHybridScheme
+	}
+	if len(data) != 1+pubSize+mlkem.EncapsulationKeySize768 {
+		return nil, ErrInvalidHybridKey
+	}
+
+	classical, pq := scheme.kems()
+	k := &HybridPublicKey{scheme: scheme}
+	var err error
+	if k.classical, err = classical.ParsePublicKey(data[1 : 1+pubSize]); err != nil {
+		return nil, ErrInvalidHybridKey
+	}
+	if k.pq, err = pq.ParsePublicKey(data[1+pubSize:]); err != nil {
+		return nil, ErrInvalidHybridKey
+	}
+	return k, nil
+}
+
+// SealHybrid 用随机数据密钥按alg加密明文，并用经典KEM与ML-KEM的组合密钥包装数据密钥
+// 两部分都通过kem.Scheme封装，包装密钥由两个共享密钥拼接后经HKDF派生，攻击者必须同时攻破两种算法才能解开信封，
+// 因此今天加密的归档数据也能抵御未来的量子计算攻击。输出格式：
+//
+//	"GSCH" || 版本 || 方案 || 算法 || 经典密文 || ML-KEM密文 || 包装的数据密钥 || 内层信封
+//
+// 内层信封与Seal的输出相同，其AAD绑定了整个外层头部
+func SealHybrid(pub *HybridPublicKey, alg Algorithm, purpose string, plaintext, aad []byte) ([]byte, error) {
+	if alg.KeySize() == 0 {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	classical, pq := pub.scheme.kems()
+	classicalShared, classicalCT, err := classical.Encapsulate(pub.classical, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(classicalShared)
+	pqShared, pqCT, err := pq.Encapsulate(pub.pq, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(pqShared)
+
+	header := []byte(hybridMagic)
+	header = append(header, HybridVersion, byte(pub.scheme), byte(alg))
+	header = append(header, classicalCT...)
+	header = append(header, pqCT...)
+
+	kek, err := pub.scheme.combine(classicalShared, pqShared, header, pub.Bytes(), alg.KeySize())
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey := make([]byte, alg.KeySize())
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	defer clear(dataKey)
+
+	// 包装密钥每次都是新派生的，使用全零nonce是安全的
+	wrapper, err := alg.newAEAD(kek)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := wrapper.Seal(make([]byte, wrapper.NonceSize()), dataKey, header)
+	if err != nil {
+		return nil, err
+	}
+	header = append(header, wrapped...)
+
+	inner, err := Seal(alg, dataKey, purpose, plaintext, contextAAD(header, aad))
+	if err != nil {
+		return nil, err
+	}
+	return append(header, inner...), nil
+}
+
+// OpenHybrid 解开SealHybrid生成的信封
+// 经典部分或ML-KEM部分任一不正确都会导致失败，错误统一为modes.ErrAuthFailed
+func OpenHybrid(priv *HybridPrivateKey, purpose string, envelope, aad []byte) ([]byte, error) {
+	_, pubSize := priv.scheme.classicalSizes()
+	prefixLen := len(hybridMagic) + 3
+	kemLen := prefixLen + pubSize + mlkem.CiphertextSize768
+	if len(envelope) < kemLen || !bytes.Equal(envelope[:len(hybridMagic)], []byte(hybridMagic)) {
+		return nil, ErrInvalidEnvelope
+	}
+	if envelope[4] != HybridVersion {
+		return nil, ErrUnsupportedVersion
+	}
+	if HybridScheme(envelope[5]) != priv.scheme {
+		return nil, ErrUnsupportedHybridScheme
+	}
+	alg := Algorithm(envelope[6])
+	if alg.KeySize() == 0 {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	// 包装的数据密钥长度为密钥长度 + GCM标签长度
+	headerLen := kemLen + alg.KeySize() + hybridTagSize
+	if len(envelope) < headerLen {
+		return nil, ErrInvalidEnvelope
+	}
+
+	classicalCT := envelope[prefixLen : prefixLen+pubSize]
+	pqCT := envelope[prefixLen+pubSize : kemLen]
+
+	classical, pq := priv.scheme.kems()
+	classicalShared, err := classical.Decapsulate(priv.classical, classicalCT)
+	if err != nil {
+		return nil, modes.ErrAuthFailed
+	}
+	defer clear(classicalShared)
+	pqShared, err := pq.Decapsulate(priv.pq, pqCT)
+	if err != nil {
+		return nil, modes.ErrAuthFailed
+	}
+	defer clear(pqShared)
+
+	kek, err := priv.scheme.combine(classicalShared, pqShared, envelope[:kemLen], priv.Public().Bytes(), alg.KeySize())
+	if err != nil {
+		return nil, err
+	}
+	wrapper, err := alg.newAEAD(kek)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := wrapper.Open(make([]byte, wrapper.NonceSize()), envelope[kemLen:headerLen], envelope[:kemLen])
+	if err != nil {
+		return nil, modes.ErrAuthFailed
+	}
+	defer clear(dataKey)
+
+	return Open(dataKey, purpose, envelope[headerLen:], contextAAD(envelope[:headerLen], aad))
+}
+
+// combine 将两个KEM的共享密钥组合为包装密钥：
+// HKDF(经典共享密钥 || ML-KEM共享密钥, info = 标签 || 方案 || 双方密文 || 接收方公钥)
+// 把密文和公钥放入info可防止把某一部分替换到其他信封中
+func (s HybridScheme) combine(classical, pq, kemHeader, pub []byte, length int) ([]byte, error) {
+	secret := make([]byte, 0, len(classical)+len(pq))
+	secret = append(secret, classical...)
+	secret = append(secret, pq...)
+	defer clear(secret)
+
+	info := make([]byte, 0, len(hybridLabel)+4+len(kemHeader)+4+len(pub))
+	info = append(info, hybridLabel...)
+	info = binary.BigEndian.AppendUint32(info, uint32(len(kemHeader)))
+	info = append(info, kemHeader...)
+	info = binary.BigEndian.AppendUint32(info, uint32(len(pub)))
+	info = append(info, pub...)
+
+	return hkdf.Key(s.kdfHash(), secret, nil, info, length)
+}
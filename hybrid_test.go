@@ -0,0 +1,108 @@
+package gsc
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/laenix/gsc/modes"
+)
+
+func TestHybridRoundTrip(t *testing.T) {
+	plaintext := []byte("长期归档数据")
+	aad := []byte("archive-2024")
+
+	for _, scheme := range []HybridScheme{HybridX25519MLKEM768, HybridSM2MLKEM768} {
+		priv, err := GenerateHybridKey(scheme)
+		if err != nil {
+			t.Fatalf("%s: 生成密钥失败: %v", scheme, err)
+		}
+
+		// 经过序列化的公私钥应能正常使用
+		pub, err := ParseHybridPublicKey(priv.Public().Bytes())
+		if err != nil {
+			t.Fatalf("%s: 解析公钥失败: %v", scheme, err)
+		}
+		parsed, err := ParseHybridPrivateKey(priv.Bytes())
+		if err != nil {
+			t.Fatalf("%s: 解析私钥失败: %v", scheme, err)
+		}
+
+		for _, alg := range []Algorithm{AES256GCM, SM4GCM} {
+			envelope, err := SealHybrid(pub, alg, "archive", plaintext, aad)
+			if err != nil {
+				t.Fatalf("%s/%s: SealHybrid失败: %v", scheme, alg, err)
+			}
+			opened, err := OpenHybrid(parsed, "archive", envelope, aad)
+			if err != nil {
+				t.Fatalf("%s/%s: OpenHybrid失败: %v", scheme, alg, err)
+			}
+			if !bytes.Equal(opened, plaintext) {
+				t.Fatalf("%s/%s: 解密结果不匹配", scheme, alg)
+			}
+
+			if _, err := OpenHybrid(parsed, "other", envelope, aad); !errors.Is(err, ErrContextMismatch) {
+				t.Errorf("%s/%s: 期望ErrContextMismatch，实际: %v", scheme, alg, err)
+			}
+		}
+	}
+}
+
+// 测试经典部分或ML-KEM部分任一被篡改都无法解开
+func TestHybridRequiresBothComponents(t *testing.T) {
+	priv, err := GenerateHybridKey(HybridX25519MLKEM768)
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := SealHybrid(priv.Public(), AES128GCM, "archive", []byte("data"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 头部 7 字节之后依次是32字节X25519临时公钥和ML-KEM密文
+	for _, offset := range []int{7 + 5, 7 + 32 + 100} {
+		tampered := bytes.Clone(envelope)
+		tampered[offset] ^= 0x01
+		if _, err := OpenHybrid(priv, "archive", tampered, nil); !errors.Is(err, modes.ErrAuthFailed) {
+			t.Errorf("篡改偏移%d时期望ErrAuthFailed，实际: %v", offset, err)
+		}
+	}
+
+	// 使用另一个私钥无法解开
+	other, err := GenerateHybridKey(HybridX25519MLKEM768)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenHybrid(other, "archive", envelope, nil); !errors.Is(err, modes.ErrAuthFailed) {
+		t.Errorf("期望ErrAuthFailed，实际: %v", err)
+	}
+
+	sm2Key, err := GenerateHybridKey(HybridSM2MLKEM768)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenHybrid(sm2Key, "archive", envelope, nil); !errors.Is(err, ErrUnsupportedHybridScheme) {
+		t.Errorf("方案不同时期望ErrUnsupportedHybridScheme，实际: %v", err)
+	}
+}
+
+// 测试相同的随机源输出生成相同的密钥
+func TestGenerateHybridKeyWithRand(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x5a}, 32+64)
+	a, err := GenerateHybridKeyWithRand(HybridX25519MLKEM768, bytes.NewReader(seed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := GenerateHybridKeyWithRand(HybridX25519MLKEM768, bytes.NewReader(seed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a.Bytes(), b.Bytes()) {
+		t.Fatal("相同随机源生成的密钥不一致")
+	}
+
+	// 随机源不足时返回错误
+	if _, err := GenerateHybridKeyWithRand(HybridX25519MLKEM768, bytes.NewReader(seed[:40])); err == nil {
+		t.Fatal("随机源不足时期望返回错误")
+	}
+}
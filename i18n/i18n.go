@@ -0,0 +1,149 @@
+// Package i18n 为gsc各包导出的错误提供中英双语消息
+//
+// 各包错误值的消息为英文（见gscerr包），中文译文由定义错误的包在init中调用Register登记，
+// 因此本包不依赖其他gsc包，只有程序实际链接的包才会登记；errors.Is判断不受语言选择影响。
+// 集成方在向最终用户展示错误时，通过Message或Localize按语言取得统一的文本
+package i18n
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/laenix/gsc/gscerr"
+)
+
+// Lang 标识消息语言
+type Lang uint8
+
+// 支持的语言
+const (
+	Zh Lang = iota
+	En
+)
+
+// String 返回语言的BCP 47标签
+func (l Lang) String() string {
+	if l == En {
+		return "en"
+	}
+	return "zh"
+}
+
+// ParseLang 解析"en"、"en-US"、"zh_CN.UTF-8"等语言标签，第二个返回值表示是否识别
+func ParseLang(tag string) (Lang, bool) {
+	tag = strings.ToLower(tag)
+	if i := strings.IndexAny(tag, "-_."); i >= 0 {
+		tag = tag[:i]
+	}
+	switch tag {
+	case "zh":
+		return Zh, true
+	case "en":
+		return En, true
+	}
+	return Zh, false
+}
+
+var (
+	messagesMu sync.RWMutex
+	// zhMessages 以错误值为键保存中文译文，英文文本即错误本身的消息
+	zhMessages = make(map[error]string)
+)
+
+// Register 登记错误值的中文译文，通常在定义这些错误的包的init中调用
+// 译文必须与英文消息使用相同的包前缀（如"aes: "）；错误为nil或不可比较、
+// 前缀不一致或同一错误重复登记时panic，与database/sql.Register对重复注册的处理相同
+func Register(messages map[error]string) {
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+	for err, zh := range messages {
+		if err == nil || !reflect.TypeOf(err).Comparable() {
+			panic("i18n: Register of a nil or incomparable error")
+		}
+		zhPrefix, _, zhOK := strings.Cut(zh, ": ")
+		enPrefix, _, enOK := strings.Cut(err.Error(), ": ")
+		if zhOK != enOK || zhOK && zhPrefix != enPrefix {
+			panic(fmt.Sprintf("i18n: prefix of %q does not match %q", zh, err.Error()))
+		}
+		if _, dup := zhMessages[err]; dup {
+			panic("i18n: Register called twice for " + err.Error())
+		}
+		zhMessages[err] = zh
+	}
+}
+
+// Message 返回err在指定语言下的消息
+// 错误链中已登记的错误值换成对应语言的译文，包装时添加的上下文以及*gscerr.KeySizeError中的
+// 实际长度等细节都保留；没有登记的部分保持原文
+func Message(err error, lang Lang) string {
+	if err == nil {
+		return ""
+	}
+	if lang == En {
+		return err.Error()
+	}
+	return translate(err)
+}
+
+// Localize 返回消息为指定语言的错误，原错误通过Unwrap保留，errors.Is和errors.As照常可用
+func Localize(err error, lang Lang) error {
+	if err == nil {
+		return nil
+	}
+	return &localized{err: err, msg: Message(err, lang)}
+}
+
+// localized 是带有本地化消息的错误
+type localized struct {
+	err error
+	msg string
+}
+
+func (l *localized) Error() string { return l.msg }
+
+func (l *localized) Unwrap() error { return l.err }
+
+// translate 返回err的中文消息
+// 已登记的错误直接返回译文；否则在err的消息中依次找到各个被包装错误的消息，替换为其译文，
+// 其余文字（如fmt.Errorf添加的上下文）原样保留
+func translate(err error) string {
+	// 不可比较的错误类型不能作为map键，也不可能被登记
+	if reflect.TypeOf(err).Comparable() {
+		messagesMu.RLock()
+		zh, ok := zhMessages[err]
+		messagesMu.RUnlock()
+		if ok {
+			return zh
+		}
+	}
+	if kse, ok := err.(*gscerr.KeySizeError); ok {
+		return fmt.Sprintf("%s（实际为%d字节）", translate(kse.Err), kse.Got)
+	}
+
+	var inner []error
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		inner = []error{u.Unwrap()}
+	case interface{ Unwrap() []error }:
+		inner = u.Unwrap()
+	}
+
+	msg := err.Error()
+	var b strings.Builder
+	for _, e := range inner {
+		if e == nil {
+			continue
+		}
+		i := strings.Index(msg, e.Error())
+		if i < 0 {
+			continue
+		}
+		b.WriteString(msg[:i])
+		b.WriteString(translate(e))
+		msg = msg[i+len(e.Error()):]
+	}
+	b.WriteString(msg)
+	return b.String()
+}
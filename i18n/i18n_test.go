@@ -0,0 +1,88 @@
+package i18n_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/laenix/gsc"
+	"github.com/laenix/gsc/aes"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/modes"
+	"github.com/laenix/gsc/sm2"
+)
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		err  error
+		lang i18n.Lang
+		want string
+	}{
+		{modes.ErrAuthFailed, i18n.En, "authenticated decryption failed"},
+		{modes.ErrAuthFailed, i18n.Zh, "认证解密失败"},
+		{fmt.Errorf("解开备份: %w", gsc.ErrContextMismatch), i18n.En, "解开备份: gsc: envelope context does not match the expected purpose"},
+		{fmt.Errorf("open backup: %w", modes.ErrAuthFailed), i18n.Zh, "open backup: 认证解密失败"},
+		{errors.Join(errors.New("其他"), sm2.ErrInvalidPublicKey), i18n.En, "其他\nsm2: invalid public key"},
+		{errors.Join(errors.New("其他"), sm2.ErrInvalidPublicKey), i18n.Zh, "其他\nsm2: 无效的公钥"},
+		{errors.New("未登记的错误"), i18n.Zh, "未登记的错误"},
+		{fmt.Errorf("加载密钥: %w", gscerr.KeySize(aes.ErrInvalidKeySize, "AES", 10, 16, 24, 32)), i18n.Zh, "加载密钥: aes: 密钥长度必须是16、24或32字节（实际为10字节）"},
+		{fmt.Errorf("load key: %w", gscerr.KeySize(aes.ErrInvalidKeySize, "AES", 10, 16, 24, 32)), i18n.En, "load key: aes: key must be 16, 24 or 32 bytes (got 10)"},
+		{nil, i18n.En, ""},
+	}
+	for _, tt := range tests {
+		if got := i18n.Message(tt.err, tt.lang); got != tt.want {
+			t.Errorf("Message(%v, %s) = %q，期望 %q", tt.err, tt.lang, got, tt.want)
+		}
+	}
+}
+
+// 测试本地化后的错误仍可用errors.Is识别
+func TestLocalize(t *testing.T) {
+	err := i18n.Localize(fmt.Errorf("打开: %w", modes.ErrAuthFailed), i18n.Zh)
+	if err.Error() != "打开: 认证解密失败" {
+		t.Fatalf("本地化消息不正确: %q", err.Error())
+	}
+	if !errors.Is(err, modes.ErrAuthFailed) {
+		t.Fatal("本地化后errors.Is失效")
+	}
+	if i18n.Localize(nil, i18n.En) != nil {
+		t.Fatal("Localize(nil)应返回nil")
+	}
+}
+
+func TestParseLang(t *testing.T) {
+	tests := map[string]i18n.Lang{"en": i18n.En, "en-US": i18n.En, "EN_gb.UTF-8": i18n.En, "zh": i18n.Zh, "zh_CN.UTF-8": i18n.Zh, "zh-Hans": i18n.Zh}
+	for tag, want := range tests {
+		if got, ok := i18n.ParseLang(tag); !ok || got != want {
+			t.Errorf("ParseLang(%q) = %v, %v，期望 %v", tag, got, ok, want)
+		}
+	}
+	if _, ok := i18n.ParseLang("fr"); ok {
+		t.Error("不应识别fr")
+	}
+}
+
+// 测试重复登记、前缀不一致和不可比较的错误都会panic
+func TestRegister(t *testing.T) {
+	errTest := gscerr.New(gscerr.ErrParameter, "test: bad input")
+	i18n.Register(map[error]string{errTest: "test: 输入无效"})
+	if got := i18n.Message(errTest, i18n.Zh); got != "test: 输入无效" {
+		t.Fatalf("登记后的译文为%q", got)
+	}
+
+	for name, messages := range map[string]map[error]string{
+		"重复登记":  {errTest: "test: 输入无效"},
+		"前缀不一致": {gscerr.New(gscerr.ErrParameter, "test: other"): "其他: 错误"},
+		"nil":   {nil: "test: 空"},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s时期望panic", name)
+				}
+			}()
+			i18n.Register(messages)
+		}()
+	}
+}
@@ -0,0 +1,19 @@
+// Package alias 判断切片是否共享内存，供流密码检查输出与输入的重叠
+package alias
+
+import "unsafe"
+
+// AnyOverlap 判断x和y是否共享内存，与标准库crypto/internal/alias相同
+func AnyOverlap(x, y []byte) bool {
+	return len(x) > 0 && len(y) > 0 &&
+		uintptr(unsafe.Pointer(&x[0])) <= uintptr(unsafe.Pointer(&y[len(y)-1])) &&
+		uintptr(unsafe.Pointer(&y[0])) <= uintptr(unsafe.Pointer(&x[len(x)-1]))
+}
+
+// InexactOverlap 判断x和y是否部分重叠：共享内存但起始位置不同
+func InexactOverlap(x, y []byte) bool {
+	if len(x) == 0 || len(y) == 0 || &x[0] == &y[0] {
+		return false
+	}
+	return AnyOverlap(x, y)
+}
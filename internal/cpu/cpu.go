@@ -0,0 +1,21 @@
+// Package cpu 在运行时检测各算法汇编实现所需的CPU特性
+//
+// 使用purego构建标签时不进行检测，所有特性均视为不可用
+package cpu
+
+// X86 记录amd64处理器的特性
+var X86 struct {
+	HasAES       bool // AES-NI
+	HasPCLMULQDQ bool // 无进位乘法
+	HasSSSE3     bool
+	HasAVX2      bool
+	HasBMI2      bool // RORX等不影响标志位的移位指令
+}
+
+// ARM64 记录arm64处理器的特性
+var ARM64 struct {
+	HasAES   bool // ARMv8 Crypto Extensions中的AESE/AESD/AESMC/AESIMC
+	HasPMULL bool // 64位无进位乘法PMULL/PMULL2
+	HasSM3   bool
+	HasSM4   bool
+}
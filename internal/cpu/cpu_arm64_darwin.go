@@ -0,0 +1,9 @@
+//go:build arm64 && darwin && !purego
+
+package cpu
+
+// Apple Silicon都支持ARMv8 Crypto Extensions中的AES和PMULL
+func init() {
+	ARM64.HasAES = true
+	ARM64.HasPMULL = true
+}
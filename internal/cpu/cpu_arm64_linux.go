@@ -0,0 +1,33 @@
+//go:build arm64 && linux && !purego
+
+package cpu
+
+import _ "unsafe" // go:linkname
+
+// getAuxv 返回运行时保存的辅助向量，golang.org/x/sys/cpu使用同样的方式读取
+//
+//go:linkname getAuxv runtime.getAuxv
+func getAuxv() []uintptr
+
+// 辅助向量中AT_HWCAP的标签及其中各特性的位
+const (
+	_AT_HWCAP  = 16
+	hwcapAES   = 1 << 3
+	hwcapPMULL = 1 << 4
+	hwcapSM3   = 1 << 18
+	hwcapSM4   = 1 << 19
+)
+
+func init() {
+	auxv := getAuxv()
+	for i := 0; i+1 < len(auxv); i += 2 {
+		if auxv[i] != _AT_HWCAP {
+			continue
+		}
+		hwcap := auxv[i+1]
+		ARM64.HasAES = hwcap&hwcapAES != 0
+		ARM64.HasPMULL = hwcap&hwcapPMULL != 0
+		ARM64.HasSM3 = hwcap&hwcapSM3 != 0
+		ARM64.HasSM4 = hwcap&hwcapSM4 != 0
+	}
+}
@@ -0,0 +1,36 @@
+//go:build amd64 && !purego
+
+package cpu
+
+func init() {
+	maxLeaf, _, _, _ := cpuid(0, 0)
+	if maxLeaf < 1 {
+		return
+	}
+	_, _, ecx1, _ := cpuid(1, 0)
+	X86.HasPCLMULQDQ = ecx1&(1<<1) != 0
+	X86.HasSSSE3 = ecx1&(1<<9) != 0
+	X86.HasAES = ecx1&(1<<25) != 0
+
+	// AVX2还要求操作系统通过XSAVE保存YMM寄存器
+	osSupportsAVX := false
+	if ecx1&(1<<27) != 0 {
+		eax, _ := xgetbv()
+		osSupportsAVX = eax&6 == 6
+	}
+	if maxLeaf >= 7 {
+		_, ebx7, _, _ := cpuid(7, 0)
+		X86.HasAVX2 = osSupportsAVX && ebx7&(1<<5) != 0
+		X86.HasBMI2 = ebx7&(1<<8) != 0
+	}
+}
+
+// cpuid 执行CPUID指令
+//
+//go:noescape
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+// xgetbv 读取XCR0寄存器
+//
+//go:noescape
+func xgetbv() (eax, edx uint32)
@@ -0,0 +1,266 @@
+// Package edwards25519 实现扭曲爱德华兹曲线 -x² + y² = 1 + d·x²·y²（d = -121665/121666）上的群运算，
+// 即Ed25519使用的edwards25519（RFC 8032第5.1节）
+//
+// 点使用扩展坐标(X:Y:Z:T)，x = X/Z，y = Y/Z，x·y = T/Z，加法和倍点公式对所有点都成立。
+// 以秘密标量为输入的ScalarBaseMult是常量时间的；以VarTime开头的函数只用于处理公开数据的验证
+package edwards25519
+
+import (
+	"github.com/laenix/gsc/internal/edwards25519/field"
+)
+
+// point 是曲线上的点
+type point struct {
+	x, y, z, t field.Element
+}
+
+var (
+	// d 是曲线参数-121665/121666，d2 = 2·d
+	d, d2 field.Element
+	// basePoint 是基点B，y = 4/5，x取偶数
+	basePoint point
+	// baseTable 是[0]B到[15]B，用于ScalarBaseMult
+	baseTable [16]point
+)
+
+func init() {
+	var num, den field.Element
+	num.Negate(feInt(121665))
+	den.Invert(feInt(121666))
+	d.Multiply(&num, &den)
+	d2.Add(&d, &d)
+
+	var y field.Element
+	y.Multiply(feInt(4), new(field.Element).Invert(feInt(5)))
+	if basePoint.setBytes(y.Bytes()) == nil {
+		panic("edwards25519: invalid base point")
+	}
+	basePoint.table(&baseTable)
+}
+
+// feInt 返回小整数n对应的域元素
+func feInt(n uint32) *field.Element {
+	return new(field.Element).Mult32(new(field.Element).One(), n)
+}
+
+// identity 设置v为单位元(0, 1)
+func (v *point) identity() *point {
+	v.x.Zero()
+	v.y.One()
+	v.z.One()
+	v.t.Zero()
+	return v
+}
+
+// setBytes 按RFC 8032第5.1.3节解码点，y不小于p或x = 0而符号位为1时返回nil
+func (v *point) setBytes(b []byte) *point {
+	if len(b) != 32 {
+		return nil
+	}
+	y, _ := new(field.Element).SetBytes(b)
+	// 拒绝y的非规范编码
+	enc := y.Bytes()
+	enc[31] |= b[31] & 0x80
+	for i := range enc {
+		if enc[i] != b[i] {
+			return nil
+		}
+	}
+
+	// x² = (y² - 1) / (d·y² + 1)
+	var y2, u, w field.Element
+	y2.Square(y)
+	u.Subtract(&y2, new(field.Element).One())
+	w.Multiply(&d, &y2)
+	w.Add(&w, new(field.Element).One())
+	x, wasSquare := new(field.Element).SqrtRatio(&u, &w)
+	if wasSquare == 0 {
+		return nil
+	}
+	sign := int(b[31] >> 7)
+	if sign == 1 && x.Equal(new(field.Element)) == 1 {
+		return nil
+	}
+	xNeg := new(field.Element).Negate(x)
+	x.Select(xNeg, x, sign)
+
+	v.x.Set(x)
+	v.y.Set(y)
+	v.z.One()
+	v.t.Multiply(x, y)
+	return v
+}
+
+// bytes 返回点的32字节编码：y的小端序编码，最高位为x的符号
+func (v *point) bytes() []byte {
+	var zInv, x, y field.Element
+	zInv.Invert(&v.z)
+	x.Multiply(&v.x, &zInv)
+	y.Multiply(&v.y, &zInv)
+	out := y.Bytes()
+	out[31] |= byte(x.IsNegative() << 7)
+	return out
+}
+
+// add 设置v = p + q（add-2008-hwcd-3，a = -1）
+func (v *point) add(p, q *point) *point {
+	var a, b, c, dd, e, f, g, h, t field.Element
+	a.Multiply(t.Subtract(&p.y, &p.x), new(field.Element).Subtract(&q.y, &q.x))
+	b.Multiply(t.Add(&p.y, &p.x), new(field.Element).Add(&q.y, &q.x))
+	c.Multiply(c.Multiply(&p.t, &d2), &q.t)
+	dd.Multiply(&p.z, &q.z)
+	dd.Add(&dd, &dd)
+	e.Subtract(&b, &a)
+	f.Subtract(&dd, &c)
+	g.Add(&dd, &c)
+	h.Add(&b, &a)
+	v.x.Multiply(&e, &f)
+	v.y.Multiply(&g, &h)
+	v.t.Multiply(&e, &h)
+	v.z.Multiply(&f, &g)
+	return v
+}
+
+// double 设置v = 2·p（dbl-2008-hwcd，a = -1）
+func (v *point) double(p *point) *point {
+	var a, b, c, e, f, g, h field.Element
+	a.Square(&p.x)
+	b.Square(&p.y)
+	c.Square(&p.z)
+	c.Add(&c, &c)
+	e.Add(&p.x, &p.y)
+	e.Square(&e)
+	e.Subtract(&e, &a)
+	e.Subtract(&e, &b)
+	// D = a·A = -A，G = D + B，H = D - B
+	g.Subtract(&b, &a)
+	f.Subtract(&g, &c)
+	h.Negate(h.Add(&a, &b))
+	v.x.Multiply(&e, &f)
+	v.y.Multiply(&g, &h)
+	v.t.Multiply(&e, &h)
+	v.z.Multiply(&f, &g)
+	return v
+}
+
+// negate 设置v = -p
+func (v *point) negate(p *point) *point {
+	v.x.Negate(&p.x)
+	v.y.Set(&p.y)
+	v.z.Set(&p.z)
+	v.t.Negate(&p.t)
+	return v
+}
+
+// selectPoint 在cond为1时设置v = a，为0时v不变
+func (v *point) selectPoint(a *point, cond int) {
+	v.x.Select(&a.x, &v.x, cond)
+	v.y.Select(&a.y, &v.y, cond)
+	v.z.Select(&a.z, &v.z, cond)
+	v.t.Select(&a.t, &v.t, cond)
+}
+
+// isIdentity 判断v是否是单位元，即X = 0且Y = Z
+func (v *point) isIdentity() bool {
+	return v.x.Equal(new(field.Element)) == 1 && v.y.Equal(&v.z) == 1
+}
+
+// table 计算[0]v到[15]v
+func (v *point) table(t *[16]point) {
+	t[0].identity()
+	t[1] = *v
+	for i := 2; i < len(t); i++ {
+		t[i].add(&t[i-1], v)
+	}
+}
+
+// ctEq 在a == b时返回1，否则返回0
+func ctEq(a, b int) int {
+	x := uint32(a ^ b)
+	return int((x - 1) >> 31)
+}
+
+// scalarMult 以常量时间计算[s]P，t为P的[0]P到[15]P，s为小端序标量
+// 使用4位固定窗口，每个窗口都做4次倍点和1次加法，查表时读取全部16项
+func scalarMult(t *[16]point, s []byte) *point {
+	v := new(point).identity()
+	var entry point
+	for i := len(s) - 1; i >= 0; i-- {
+		for _, k := range [2]int{int(s[i] >> 4), int(s[i] & 0x0f)} {
+			for range 4 {
+				v.double(v)
+			}
+			entry.identity()
+			for j := range t {
+				entry.selectPoint(&t[j], ctEq(j, k))
+			}
+			v.add(v, &entry)
+		}
+	}
+	return v
+}
+
+// varTimeMultiScalarMult 计算Σ[scalars[i]]points[i]（Straus算法，4位窗口共享倍点），
+// 运算时间依赖标量，只能用于公开数据
+func varTimeMultiScalarMult(scalars [][]byte, points []*point) *point {
+	tables := make([][16]point, len(points))
+	n := 0
+	for i, p := range points {
+		p.table(&tables[i])
+		n = max(n, len(scalars[i]))
+	}
+	v := new(point).identity()
+	for i := n - 1; i >= 0; i-- {
+		for _, shift := range [2]uint{4, 0} {
+			for range 4 {
+				v.double(v)
+			}
+			for j, s := range scalars {
+				if i >= len(s) {
+					continue
+				}
+				if k := s[i] >> shift & 0x0f; k != 0 {
+					v.add(v, &tables[j][k])
+				}
+			}
+		}
+	}
+	return v
+}
+
+// Group 是edwards25519上供EdDSA使用的运算，点和标量均为32字节小端序编码
+type Group struct{}
+
+// ScalarBaseMult 以常量时间计算[s]B并编码
+func (Group) ScalarBaseMult(s []byte) []byte {
+	return scalarMult(&baseTable, s).bytes()
+}
+
+// VarTimeDoubleScalarBaseMult 计算[s]B - [k]A并编码，A不是有效的点编码时返回false
+func (Group) VarTimeDoubleScalarBaseMult(k, a, s []byte) ([]byte, bool) {
+	var p point
+	if p.setBytes(a) == nil {
+		return nil, false
+	}
+	p.negate(&p)
+	return varTimeMultiScalarMult([][]byte{s, k}, []*point{&basePoint, &p}).bytes(), true
+}
+
+// VarTimeMultiScalarCheck 检查[8]([s]B + Σ[scalars[i]]points[i])是否为单位元，
+// 乘以余因子8消去小阶分量。任一点不是有效的编码时返回false
+func (Group) VarTimeMultiScalarCheck(s []byte, scalars, points [][]byte) bool {
+	ps := make([]*point, 0, len(points)+1)
+	ps = append(ps, &basePoint)
+	for _, b := range points {
+		p := new(point)
+		if p.setBytes(b) == nil {
+			return false
+		}
+		ps = append(ps, p)
+	}
+	v := varTimeMultiScalarMult(append([][]byte{s}, scalars...), ps)
+	for range 3 {
+		v.double(v)
+	}
+	return v.isIdentity()
+}
@@ -0,0 +1,375 @@
+// Package field 实现GF(2^255-19)上的常量时间算术，供Ed25519和X25519使用
+//
+// 元素用5个51位的limb表示（radix 2^51），每次运算后都进行进位传播，
+// 使各limb保持在2^52以内，乘法的中间结果可以放入128位
+package field
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+)
+
+// Element 是GF(2^255-19)的元素，零值为0
+type Element struct {
+	l0, l1, l2, l3, l4 uint64
+}
+
+const maskLow51Bits uint64 = (1 << 51) - 1
+
+var (
+	feZero = &Element{}
+	feOne  = &Element{1, 0, 0, 0, 0}
+)
+
+// Zero 设置v = 0
+func (v *Element) Zero() *Element {
+	*v = *feZero
+	return v
+}
+
+// One 设置v = 1
+func (v *Element) One() *Element {
+	*v = *feOne
+	return v
+}
+
+// Set 设置v = a
+func (v *Element) Set(a *Element) *Element {
+	*v = *a
+	return v
+}
+
+// carryPropagate 将各limb超出51位的部分进位到下一个limb，最高limb的进位乘以19加回最低limb
+func (v *Element) carryPropagate() *Element {
+	c0 := v.l0 >> 51
+	c1 := v.l1 >> 51
+	c2 := v.l2 >> 51
+	c3 := v.l3 >> 51
+	c4 := v.l4 >> 51
+
+	v.l0 = v.l0&maskLow51Bits + c4*19
+	v.l1 = v.l1&maskLow51Bits + c0
+	v.l2 = v.l2&maskLow51Bits + c1
+	v.l3 = v.l3&maskLow51Bits + c2
+	v.l4 = v.l4&maskLow51Bits + c3
+	return v
+}
+
+// reduce 完全约简到[0, p)
+func (v *Element) reduce() *Element {
+	v.carryPropagate()
+
+	// v ≥ p 当且仅当 v + 19 ≥ 2^255，逐limb传播v + 19的进位即可得到该比较结果
+	c := (v.l0 + 19) >> 51
+	c = (v.l1 + c) >> 51
+	c = (v.l2 + c) >> 51
+	c = (v.l3 + c) >> 51
+	c = (v.l4 + c) >> 51
+
+	// v ≥ p时减去p，即加19后丢弃2^255
+	v.l0 += 19 * c
+	v.l1 += v.l0 >> 51
+	v.l0 &= maskLow51Bits
+	v.l2 += v.l1 >> 51
+	v.l1 &= maskLow51Bits
+	v.l3 += v.l2 >> 51
+	v.l2 &= maskLow51Bits
+	v.l4 += v.l3 >> 51
+	v.l3 &= maskLow51Bits
+	v.l4 &= maskLow51Bits
+	return v
+}
+
+// Add 设置v = a + b
+func (v *Element) Add(a, b *Element) *Element {
+	v.l0 = a.l0 + b.l0
+	v.l1 = a.l1 + b.l1
+	v.l2 = a.l2 + b.l2
+	v.l3 = a.l3 + b.l3
+	v.l4 = a.l4 + b.l4
+	return v.carryPropagate()
+}
+
+// Subtract 设置v = a - b
+func (v *Element) Subtract(a, b *Element) *Element {
+	// 先加上2p避免下溢，各limb的2p分量都不小于b的对应limb
+	v.l0 = (a.l0 + 0xFFFFFFFFFFFDA) - b.l0
+	v.l1 = (a.l1 + 0xFFFFFFFFFFFFE) - b.l1
+	v.l2 = (a.l2 + 0xFFFFFFFFFFFFE) - b.l2
+	v.l3 = (a.l3 + 0xFFFFFFFFFFFFE) - b.l3
+	v.l4 = (a.l4 + 0xFFFFFFFFFFFFE) - b.l4
+	return v.carryPropagate()
+}
+
+// Negate 设置v = -a
+func (v *Element) Negate(a *Element) *Element {
+	return v.Subtract(feZero, a)
+}
+
+// uint128 是乘法的中间结果
+type uint128 struct {
+	lo, hi uint64
+}
+
+// mul64 返回a·b
+func mul64(a, b uint64) uint128 {
+	hi, lo := bits.Mul64(a, b)
+	return uint128{lo, hi}
+}
+
+// addMul64 返回v + a·b
+func addMul64(v uint128, a, b uint64) uint128 {
+	hi, lo := bits.Mul64(a, b)
+	lo, c := bits.Add64(lo, v.lo, 0)
+	hi, _ = bits.Add64(hi, v.hi, c)
+	return uint128{lo, hi}
+}
+
+// shiftRightBy51 返回v >> 51，结果必须能放入64位
+func shiftRightBy51(v uint128) uint64 {
+	return (v.hi << (64 - 51)) | (v.lo >> 51)
+}
+
+// Multiply 设置v = a·b
+func (v *Element) Multiply(a, b *Element) *Element {
+	a0, a1, a2, a3, a4 := a.l0, a.l1, a.l2, a.l3, a.l4
+	b0, b1, b2, b3, b4 := b.l0, b.l1, b.l2, b.l3, b.l4
+
+	// 2^255 ≡ 19，位置超过第4个limb的乘积乘以19折回低位
+	b1_19, b2_19, b3_19, b4_19 := b1*19, b2*19, b3*19, b4*19
+
+	// r0 = a0·b0 + 19·(a1·b4 + a2·b3 + a3·b2 + a4·b1)
+	r0 := mul64(a0, b0)
+	r0 = addMul64(r0, a1, b4_19)
+	r0 = addMul64(r0, a2, b3_19)
+	r0 = addMul64(r0, a3, b2_19)
+	r0 = addMul64(r0, a4, b1_19)
+
+	// r1 = a0·b1 + a1·b0 + 19·(a2·b4 + a3·b3 + a4·b2)
+	r1 := mul64(a0, b1)
+	r1 = addMul64(r1, a1, b0)
+	r1 = addMul64(r1, a2, b4_19)
+	r1 = addMul64(r1, a3, b3_19)
+	r1 = addMul64(r1, a4, b2_19)
+
+	// r2 = a0·b2 + a1·b1 + a2·b0 + 19·(a3·b4 + a4·b3)
+	r2 := mul64(a0, b2)
+	r2 = addMul64(r2, a1, b1)
+	r2 = addMul64(r2, a2, b0)
+	r2 = addMul64(r2, a3, b4_19)
+	r2 = addMul64(r2, a4, b3_19)
+
+	// r3 = a0·b3 + a1·b2 + a2·b1 + a3·b0 + 19·a4·b4
+	r3 := mul64(a0, b3)
+	r3 = addMul64(r3, a1, b2)
+	r3 = addMul64(r3, a2, b1)
+	r3 = addMul64(r3, a3, b0)
+	r3 = addMul64(r3, a4, b4_19)
+
+	// r4 = a0·b4 + a1·b3 + a2·b2 + a3·b1 + a4·b0
+	r4 := mul64(a0, b4)
+	r4 = addMul64(r4, a1, b3)
+	r4 = addMul64(r4, a2, b2)
+	r4 = addMul64(r4, a3, b1)
+	r4 = addMul64(r4, a4, b0)
+
+	// 各r_i的高位进位到下一个limb，输入limb小于2^52时c4·19仍能放入64位
+	c0 := shiftRightBy51(r0)
+	c1 := shiftRightBy51(r1)
+	c2 := shiftRightBy51(r2)
+	c3 := shiftRightBy51(r3)
+	c4 := shiftRightBy51(r4)
+
+	v.l0 = r0.lo&maskLow51Bits + c4*19
+	v.l1 = r1.lo&maskLow51Bits + c0
+	v.l2 = r2.lo&maskLow51Bits + c1
+	v.l3 = r3.lo&maskLow51Bits + c2
+	v.l4 = r4.lo&maskLow51Bits + c3
+	return v.carryPropagate()
+}
+
+// Square 设置v = a²
+func (v *Element) Square(a *Element) *Element {
+	return v.Multiply(a, a)
+}
+
+// Mult32 设置v = a·x，用于乘以X25519的常数121666等小整数
+func (v *Element) Mult32(a *Element, x uint32) *Element {
+	x0lo, x0hi := mul51(a.l0, x)
+	x1lo, x1hi := mul51(a.l1, x)
+	x2lo, x2hi := mul51(a.l2, x)
+	x3lo, x3hi := mul51(a.l3, x)
+	x4lo, x4hi := mul51(a.l4, x)
+	v.l0 = x0lo + 19*x4hi
+	v.l1 = x1lo + x0hi
+	v.l2 = x2lo + x1hi
+	v.l3 = x3lo + x2hi
+	v.l4 = x4lo + x3hi
+	return v
+}
+
+// mul51 返回a·b的低51位和其余高位
+func mul51(a uint64, b uint32) (lo, hi uint64) {
+	mh, ml := bits.Mul64(a, uint64(b))
+	lo = ml & maskLow51Bits
+	hi = (mh << 13) | (ml >> 51)
+	return
+}
+
+// pow 设置v = x^e，e为大端序的公开指数，运算时间只取决于e
+func (v *Element) pow(x *Element, e []byte) *Element {
+	var out, base Element
+	out.One()
+	base.Set(x)
+	for _, b := range e {
+		for i := 7; i >= 0; i-- {
+			out.Square(&out)
+			if b>>i&1 == 1 {
+				out.Multiply(&out, &base)
+			}
+		}
+	}
+	return v.Set(&out)
+}
+
+// 求逆和开平方使用的公开指数（大端序）
+var (
+	// expPMinus2 = p - 2 = 2^255 - 21
+	expPMinus2 = expAllOnes(0x7f, 0xeb)
+	// expP58 = (p - 5) / 8 = 2^252 - 3
+	expP58 = expAllOnes(0x0f, 0xfd)
+	// expPMinus1Over4 = (p - 1) / 4 = 2^253 - 5
+	expPMinus1Over4 = expAllOnes(0x1f, 0xfb)
+)
+
+// expAllOnes 返回首字节为first、末字节为last、中间全为0xff的32字节指数
+func expAllOnes(first, last byte) []byte {
+	e := make([]byte, 32)
+	for i := range e {
+		e[i] = 0xff
+	}
+	e[0], e[31] = first, last
+	return e
+}
+
+// sqrtM1 是-1的平方根2^((p-1)/4)
+var sqrtM1 = new(Element).pow(&Element{2, 0, 0, 0, 0}, expPMinus1Over4)
+
+// Invert 设置v = 1/z（费马小定理），z = 0时v = 0
+func (v *Element) Invert(z *Element) *Element {
+	return v.pow(z, expPMinus2)
+}
+
+// Bytes 返回v的32字节小端序规范编码
+func (v *Element) Bytes() []byte {
+	var out [32]byte
+	return v.bytes(&out)
+}
+
+func (v *Element) bytes(out *[32]byte) []byte {
+	t := *v
+	t.reduce()
+	binary.LittleEndian.PutUint64(out[0:8], t.l0|t.l1<<51)
+	binary.LittleEndian.PutUint64(out[8:16], t.l1>>13|t.l2<<38)
+	binary.LittleEndian.PutUint64(out[16:24], t.l2>>26|t.l3<<25)
+	binary.LittleEndian.PutUint64(out[24:32], t.l3>>39|t.l4<<12)
+	return out[:]
+}
+
+// SetBytes 从32字节小端序编码设置v，忽略最高位（RFC 7748第5节）
+// 不小于p的非规范编码按模p约简后接受，需要拒绝时由调用方比较Bytes的结果
+func (v *Element) SetBytes(x []byte) (*Element, error) {
+	if len(x) != 32 {
+		return nil, errors.New("field: invalid field element length")
+	}
+	v.l0 = binary.LittleEndian.Uint64(x[0:8]) & maskLow51Bits
+	v.l1 = binary.LittleEndian.Uint64(x[6:14]) >> 3 & maskLow51Bits
+	v.l2 = binary.LittleEndian.Uint64(x[12:20]) >> 6 & maskLow51Bits
+	v.l3 = binary.LittleEndian.Uint64(x[19:27]) >> 1 & maskLow51Bits
+	v.l4 = binary.LittleEndian.Uint64(x[24:32]) >> 12 & maskLow51Bits
+	return v, nil
+}
+
+// Equal 在v = u时返回1，否则返回0
+func (v *Element) Equal(u *Element) int {
+	var a, b [32]byte
+	sa, sv := u.bytes(&a), v.bytes(&b)
+	var d byte
+	for i := range sa {
+		d |= sa[i] ^ sv[i]
+	}
+	return int((uint32(d) - 1) >> 31)
+}
+
+// IsNegative 在v的规范编码为奇数时返回1（RFC 8032中x坐标的"符号"）
+func (v *Element) IsNegative() int {
+	return int(v.Bytes()[0] & 1)
+}
+
+// mask64 在cond为1时返回全1，为0时返回0
+func mask64(cond int) uint64 {
+	return ^(uint64(cond) - 1)
+}
+
+// Select 在cond为1时设置v = a，为0时设置v = b
+func (v *Element) Select(a, b *Element, cond int) *Element {
+	m := mask64(cond)
+	v.l0 = (m & a.l0) | (^m & b.l0)
+	v.l1 = (m & a.l1) | (^m & b.l1)
+	v.l2 = (m & a.l2) | (^m & b.l2)
+	v.l3 = (m & a.l3) | (^m & b.l3)
+	v.l4 = (m & a.l4) | (^m & b.l4)
+	return v
+}
+
+// Swap 在cond为1时交换v和u，为0时不变
+func (v *Element) Swap(u *Element, cond int) {
+	m := mask64(cond)
+	t := m & (v.l0 ^ u.l0)
+	v.l0 ^= t
+	u.l0 ^= t
+	t = m & (v.l1 ^ u.l1)
+	v.l1 ^= t
+	u.l1 ^= t
+	t = m & (v.l2 ^ u.l2)
+	v.l2 ^= t
+	u.l2 ^= t
+	t = m & (v.l3 ^ u.l3)
+	v.l3 ^= t
+	u.l3 ^= t
+	t = m & (v.l4 ^ u.l4)
+	v.l4 ^= t
+	u.l4 ^= t
+}
+
+// Absolute 设置v = |u|，即取u和-u中规范编码为偶数的一个
+func (v *Element) Absolute(u *Element) *Element {
+	return v.Select(new(Element).Negate(u), u, u.IsNegative())
+}
+
+// SqrtRatio 设置v为u/w的平方根（RFC 8032第5.1.3节），取规范编码为偶数的一个
+// u/w是平方数时返回(v, 1)；否则返回(v, 0)，此时v无意义
+func (v *Element) SqrtRatio(u, w *Element) (*Element, int) {
+	// r = u·w³·(u·w⁷)^((p-5)/8)
+	var w2, w3, w7, uw3, uw7, r Element
+	w2.Square(w)
+	w3.Multiply(&w2, w)
+	w7.Multiply(w7.Square(&w3), w)
+	uw3.Multiply(u, &w3)
+	uw7.Multiply(u, &w7)
+	r.pow(&uw7, expP58)
+	r.Multiply(&r, &uw3)
+
+	// check = w·r²，等于u时r即为所求，等于-u时所求为r·sqrt(-1)
+	var check, uNeg, rPrime Element
+	check.Multiply(w, new(Element).Square(&r))
+	uNeg.Negate(u)
+	correct := check.Equal(u)
+	flipped := check.Equal(&uNeg)
+	rPrime.Multiply(&r, sqrtM1)
+	r.Select(&rPrime, &r, flipped)
+
+	v.Absolute(&r)
+	return v, correct | flipped
+}
@@ -0,0 +1,146 @@
+package field
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+var p = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+// toBig 将小端序编码转换为整数
+func toBig(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	return new(big.Int).SetBytes(be)
+}
+
+// fromBig 返回x mod p的元素
+func fromBig(x *big.Int) *Element {
+	be := new(big.Int).Mod(x, p).FillBytes(make([]byte, 32))
+	le := make([]byte, 32)
+	for i := range be {
+		le[31-i] = be[i]
+	}
+	v, _ := new(Element).SetBytes(le)
+	return v
+}
+
+func randomElement(t *testing.T) (*Element, *big.Int) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	b[31] &= 0x7f
+	v, err := new(Element).SetBytes(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v, new(big.Int).Mod(toBig(b), p)
+}
+
+// 与math/big对比各运算的结果
+func TestArithmetic(t *testing.T) {
+	for range 200 {
+		a, x := randomElement(t)
+		b, y := randomElement(t)
+		check := func(name string, got *Element, want *big.Int) {
+			t.Helper()
+			if toBig(got.Bytes()).Cmp(new(big.Int).Mod(want, p)) != 0 {
+				t.Fatalf("%s结果错误: a = %x, b = %x", name, a.Bytes(), b.Bytes())
+			}
+		}
+		check("Add", new(Element).Add(a, b), new(big.Int).Add(x, y))
+		check("Subtract", new(Element).Subtract(a, b), new(big.Int).Sub(x, y))
+		check("Negate", new(Element).Negate(a), new(big.Int).Neg(x))
+		check("Multiply", new(Element).Multiply(a, b), new(big.Int).Mul(x, y))
+		check("Square", new(Element).Square(a), new(big.Int).Mul(x, x))
+		check("Mult32", new(Element).Mult32(a, 121666), new(big.Int).Mul(x, big.NewInt(121666)))
+		if x.Sign() != 0 {
+			check("Invert", new(Element).Invert(a), new(big.Int).ModInverse(x, p))
+		}
+
+		// 多次运算后limb仍在范围内
+		c := new(Element).Set(a)
+		z := new(big.Int).Set(x)
+		for range 20 {
+			c.Subtract(c.Multiply(c, b), a)
+			z.Sub(z.Mul(z, y), x)
+		}
+		check("连续运算", c, z)
+	}
+}
+
+func TestBytes(t *testing.T) {
+	// p到2^255-1之间的非规范编码按模p约简
+	for _, x := range []*big.Int{p, new(big.Int).Add(p, big.NewInt(18)), big.NewInt(0), new(big.Int).Sub(p, big.NewInt(1))} {
+		be := x.FillBytes(make([]byte, 32))
+		le := make([]byte, 32)
+		for i := range be {
+			le[31-i] = be[i]
+		}
+		v, _ := new(Element).SetBytes(le)
+		if toBig(v.Bytes()).Cmp(new(big.Int).Mod(x, p)) != 0 {
+			t.Fatalf("%x的编码约简错误", x)
+		}
+	}
+
+	// 最高位被忽略
+	b := bytes.Repeat([]byte{0xff}, 32)
+	v, _ := new(Element).SetBytes(b)
+	want := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(1))
+	if toBig(v.Bytes()).Cmp(want.Mod(want, p)) != 0 {
+		t.Fatal("最高位未被忽略")
+	}
+
+	if _, err := new(Element).SetBytes(make([]byte, 31)); err == nil {
+		t.Fatal("长度错误的编码应返回错误")
+	}
+}
+
+func TestSqrtRatio(t *testing.T) {
+	for range 100 {
+		a, x := randomElement(t)
+		b, y := randomElement(t)
+		r, wasSquare := new(Element).SqrtRatio(a, b)
+
+		ratio := new(big.Int).Mul(x, new(big.Int).ModInverse(y, p))
+		ratio.Mod(ratio, p)
+		isSquare := big.Jacobi(ratio, p) >= 0
+		if isSquare != (wasSquare == 1) {
+			t.Fatalf("平方数判断错误: %x/%x", a.Bytes(), b.Bytes())
+		}
+		if wasSquare == 1 {
+			rr := toBig(r.Bytes())
+			if new(big.Int).Mod(new(big.Int).Mul(rr, rr), p).Cmp(ratio) != 0 {
+				t.Fatal("平方根错误")
+			}
+			if r.IsNegative() != 0 {
+				t.Fatal("平方根应取偶数")
+			}
+		}
+	}
+	if _, ok := new(Element).SqrtRatio(fromBig(big.NewInt(-1)), new(Element).One()); ok != 1 {
+		t.Fatal("-1应是平方数")
+	}
+}
+
+func TestSelectSwap(t *testing.T) {
+	a, _ := randomElement(t)
+	b, _ := randomElement(t)
+	if new(Element).Select(a, b, 1).Equal(a) != 1 || new(Element).Select(a, b, 0).Equal(b) != 1 {
+		t.Fatal("Select结果错误")
+	}
+	c, d := new(Element).Set(a), new(Element).Set(b)
+	c.Swap(d, 0)
+	if c.Equal(a) != 1 || d.Equal(b) != 1 {
+		t.Fatal("cond为0时不应交换")
+	}
+	c.Swap(d, 1)
+	if c.Equal(b) != 1 || d.Equal(a) != 1 {
+		t.Fatal("cond为1时应交换")
+	}
+}
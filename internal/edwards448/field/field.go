@@ -0,0 +1,340 @@
+// Package field 实现GF(2^448 - 2^224 - 1)上的常量时间算术，供Ed448和X448使用
+//
+// 元素用8个56位的limb表示（radix 2^56），每个limb恰好对应编码中的7个字节。
+// 约简利用 2^448 ≡ 2^224 + 1：超出第7个limb的部分同时加回第0个和第4个limb。
+// 每次运算后都进行进位传播，使各limb保持在2^57以内，乘法的中间结果可以放入128位
+package field
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+)
+
+// Element 是GF(2^448 - 2^224 - 1)的元素，零值为0
+type Element struct {
+	l [8]uint64
+}
+
+const maskLow56Bits uint64 = (1 << 56) - 1
+
+var (
+	feZero = &Element{}
+	feOne  = &Element{[8]uint64{1}}
+)
+
+// Zero 设置v = 0
+func (v *Element) Zero() *Element {
+	*v = *feZero
+	return v
+}
+
+// One 设置v = 1
+func (v *Element) One() *Element {
+	*v = *feOne
+	return v
+}
+
+// Set 设置v = a
+func (v *Element) Set(a *Element) *Element {
+	*v = *a
+	return v
+}
+
+// carryPropagate 将各limb超出56位的部分进位到下一个limb，最高limb的进位加回第0个和第4个limb
+func (v *Element) carryPropagate() *Element {
+	var c [8]uint64
+	for i := range v.l {
+		c[i] = v.l[i] >> 56
+		v.l[i] &= maskLow56Bits
+	}
+	for i := 1; i < len(v.l); i++ {
+		v.l[i] += c[i-1]
+	}
+	v.l[0] += c[7]
+	v.l[4] += c[7]
+	return v
+}
+
+// carryChain 从低到高逐limb传递进位，最高limb的进位加回第0个和第4个limb
+func (v *Element) carryChain() {
+	for i := 0; i < len(v.l)-1; i++ {
+		v.l[i+1] += v.l[i] >> 56
+		v.l[i] &= maskLow56Bits
+	}
+	c := v.l[7] >> 56
+	v.l[7] &= maskLow56Bits
+	v.l[0] += c
+	v.l[4] += c
+}
+
+// p的各limb：第4个limb为2^56 - 2，其余为2^56 - 1
+var pLimbs = [8]uint64{
+	maskLow56Bits, maskLow56Bits, maskLow56Bits, maskLow56Bits,
+	maskLow56Bits - 1, maskLow56Bits, maskLow56Bits, maskLow56Bits,
+}
+
+// reduce 完全约简到[0, p)
+func (v *Element) reduce() *Element {
+	v.carryPropagate()
+	// 每次逐limb进位后加回的进位越来越小，三次之后各limb都小于2^56，即v < 2^448 < 2p
+	for range 3 {
+		v.carryChain()
+	}
+
+	// 计算t = v - p，没有借位说明v ≥ p，此时取t
+	var t [8]uint64
+	var borrow uint64
+	for i := range t {
+		t[i] = v.l[i] - pLimbs[i] - borrow
+		borrow = t[i] >> 63
+		t[i] &= maskLow56Bits
+	}
+	m := borrow - 1
+	for i := range v.l {
+		v.l[i] = (m & t[i]) | (^m & v.l[i])
+	}
+	return v
+}
+
+// Add 设置v = a + b
+func (v *Element) Add(a, b *Element) *Element {
+	for i := range v.l {
+		v.l[i] = a.l[i] + b.l[i]
+	}
+	return v.carryPropagate()
+}
+
+// Subtract 设置v = a - b
+func (v *Element) Subtract(a, b *Element) *Element {
+	// 先加上2p避免下溢，各limb的2p分量都不小于b的对应limb
+	for i := range v.l {
+		v.l[i] = (a.l[i] + 2*pLimbs[i]) - b.l[i]
+	}
+	return v.carryPropagate()
+}
+
+// Negate 设置v = -a
+func (v *Element) Negate(a *Element) *Element {
+	return v.Subtract(feZero, a)
+}
+
+// uint128 是乘法的中间结果
+type uint128 struct {
+	lo, hi uint64
+}
+
+// addMul64 返回v + a·b
+func addMul64(v uint128, a, b uint64) uint128 {
+	hi, lo := bits.Mul64(a, b)
+	lo, c := bits.Add64(lo, v.lo, 0)
+	hi, _ = bits.Add64(hi, v.hi, c)
+	return uint128{lo, hi}
+}
+
+// add128 返回a + b
+func add128(a, b uint128) uint128 {
+	lo, c := bits.Add64(a.lo, b.lo, 0)
+	hi, _ := bits.Add64(a.hi, b.hi, c)
+	return uint128{lo, hi}
+}
+
+// shiftRightBy56 返回v >> 56，结果必须能放入64位
+func shiftRightBy56(v uint128) uint64 {
+	return (v.hi << (64 - 56)) | (v.lo >> 56)
+}
+
+// Multiply 设置v = a·b
+func (v *Element) Multiply(a, b *Element) *Element {
+	// 列r[k] = Σ a_i·b_j（i + j = k），输入limb小于2^57时每列小于2^117
+	var r [15]uint128
+	for i := range a.l {
+		for j := range b.l {
+			r[i+j] = addMul64(r[i+j], a.l[i], b.l[j])
+		}
+	}
+
+	// 2^448 ≡ 2^224 + 1，第k列（k ≥ 8）折回第k-8列和第k-4列。
+	// 从高到低处理，折到第8到10列的部分会被再次折回，最终各列小于2^120
+	for k := len(r) - 1; k >= 8; k-- {
+		r[k-8] = add128(r[k-8], r[k])
+		r[k-4] = add128(r[k-4], r[k])
+	}
+
+	var c uint64
+	for i := range v.l {
+		t := add128(r[i], uint128{c, 0})
+		v.l[i] = t.lo & maskLow56Bits
+		c = shiftRightBy56(t)
+	}
+	v.l[0] += c
+	v.l[4] += c
+	return v.carryPropagate()
+}
+
+// Square 设置v = a²
+func (v *Element) Square(a *Element) *Element {
+	return v.Multiply(a, a)
+}
+
+// Mult32 设置v = a·x，用于乘以X448的常数39081等小整数
+func (v *Element) Mult32(a *Element, x uint32) *Element {
+	var lo, hi [8]uint64
+	for i := range a.l {
+		lo[i], hi[i] = mul56(a.l[i], x)
+	}
+	v.l[0] = lo[0] + hi[7]
+	for i := 1; i < len(v.l); i++ {
+		v.l[i] = lo[i] + hi[i-1]
+	}
+	v.l[4] += hi[7]
+	return v.carryPropagate()
+}
+
+// mul56 返回a·b的低56位和其余高位
+func mul56(a uint64, b uint32) (lo, hi uint64) {
+	mh, ml := bits.Mul64(a, uint64(b))
+	lo = ml & maskLow56Bits
+	hi = (mh << 8) | (ml >> 56)
+	return
+}
+
+// pow 设置v = x^e，e为大端序的公开指数，运算时间只取决于e
+func (v *Element) pow(x *Element, e []byte) *Element {
+	var out, base Element
+	out.One()
+	base.Set(x)
+	for _, b := range e {
+		for i := 7; i >= 0; i-- {
+			out.Square(&out)
+			if b>>i&1 == 1 {
+				out.Multiply(&out, &base)
+			}
+		}
+	}
+	return v.Set(&out)
+}
+
+// 求逆和开平方使用的公开指数（大端序）
+var (
+	// expPMinus2 = p - 2 = 2^448 - 2^224 - 3
+	expPMinus2 = expAllOnes(map[int]byte{27: 0xfe, 55: 0xfd})
+	// expPMinus3Over4 = (p - 3) / 4 = 2^446 - 2^222 - 1
+	expPMinus3Over4 = expAllOnes(map[int]byte{0: 0x3f, 28: 0xbf})
+)
+
+// expAllOnes 返回除diff中给出的字节外全为0xff的56字节指数
+func expAllOnes(diff map[int]byte) []byte {
+	e := make([]byte, 56)
+	for i := range e {
+		e[i] = 0xff
+	}
+	for i, b := range diff {
+		e[i] = b
+	}
+	return e
+}
+
+// Invert 设置v = 1/z（费马小定理），z = 0时v = 0
+func (v *Element) Invert(z *Element) *Element {
+	return v.pow(z, expPMinus2)
+}
+
+// Bytes 返回v的56字节小端序规范编码
+func (v *Element) Bytes() []byte {
+	var out [56]byte
+	return v.bytes(&out)
+}
+
+func (v *Element) bytes(out *[56]byte) []byte {
+	t := *v
+	t.reduce()
+	var buf [8]byte
+	for i, l := range t.l {
+		binary.LittleEndian.PutUint64(buf[:], l)
+		copy(out[7*i:7*i+7], buf[:7])
+	}
+	return out[:]
+}
+
+// SetBytes 从56字节小端序编码设置v
+// 不小于p的非规范编码按模p约简后接受，需要拒绝时由调用方比较Bytes的结果
+func (v *Element) SetBytes(x []byte) (*Element, error) {
+	if len(x) != 56 {
+		return nil, errors.New("field: invalid field element length")
+	}
+	var buf [8]byte
+	for i := range v.l {
+		copy(buf[:7], x[7*i:7*i+7])
+		v.l[i] = binary.LittleEndian.Uint64(buf[:])
+	}
+	return v, nil
+}
+
+// Equal 在v = u时返回1，否则返回0
+func (v *Element) Equal(u *Element) int {
+	var a, b [56]byte
+	sa, sv := u.bytes(&a), v.bytes(&b)
+	var d byte
+	for i := range sa {
+		d |= sa[i] ^ sv[i]
+	}
+	return int((uint32(d) - 1) >> 31)
+}
+
+// IsNegative 在v的规范编码为奇数时返回1（RFC 8032中x坐标的"符号"）
+func (v *Element) IsNegative() int {
+	return int(v.Bytes()[0] & 1)
+}
+
+// mask64 在cond为1时返回全1，为0时返回0
+func mask64(cond int) uint64 {
+	return ^(uint64(cond) - 1)
+}
+
+// Select 在cond为1时设置v = a，为0时设置v = b
+func (v *Element) Select(a, b *Element, cond int) *Element {
+	m := mask64(cond)
+	for i := range v.l {
+		v.l[i] = (m & a.l[i]) | (^m & b.l[i])
+	}
+	return v
+}
+
+// Swap 在cond为1时交换v和u，为0时不变
+func (v *Element) Swap(u *Element, cond int) {
+	m := mask64(cond)
+	for i := range v.l {
+		t := m & (v.l[i] ^ u.l[i])
+		v.l[i] ^= t
+		u.l[i] ^= t
+	}
+}
+
+// Absolute 设置v = |u|，即取u和-u中规范编码为偶数的一个
+func (v *Element) Absolute(u *Element) *Element {
+	return v.Select(new(Element).Negate(u), u, u.IsNegative())
+}
+
+// SqrtRatio 设置v为u/w的平方根（RFC 8032第5.2.3节），取规范编码为偶数的一个
+// u/w是平方数时返回(v, 1)；否则返回(v, 0)，此时v无意义
+func (v *Element) SqrtRatio(u, w *Element) (*Element, int) {
+	// p ≡ 3 (mod 4)，r = u³·w·(u⁵·w³)^((p-3)/4)
+	var u2, u3, u5, w3, u3w, u5w3, r Element
+	u2.Square(u)
+	u3.Multiply(&u2, u)
+	u5.Multiply(&u3, &u2)
+	w3.Multiply(w3.Square(w), w)
+	u3w.Multiply(&u3, w)
+	u5w3.Multiply(&u5, &w3)
+	r.pow(&u5w3, expPMinus3Over4)
+	r.Multiply(&r, &u3w)
+
+	// w·r² = u时r即为所求
+	var check Element
+	check.Multiply(w, new(Element).Square(&r))
+	correct := check.Equal(u)
+
+	v.Absolute(&r)
+	return v, correct
+}
@@ -0,0 +1,150 @@
+package field
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+var p = func() *big.Int {
+	x := new(big.Int).Lsh(big.NewInt(1), 448)
+	x.Sub(x, new(big.Int).Lsh(big.NewInt(1), 224))
+	return x.Sub(x, big.NewInt(1))
+}()
+
+// toBig 将小端序编码转换为整数
+func toBig(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	return new(big.Int).SetBytes(be)
+}
+
+// toLE 将x编码为56字节小端序串，x必须小于2^448
+func toLE(x *big.Int) []byte {
+	be := x.FillBytes(make([]byte, 56))
+	le := make([]byte, 56)
+	for i := range be {
+		le[55-i] = be[i]
+	}
+	return le
+}
+
+func randomElement(t *testing.T) (*Element, *big.Int) {
+	b := make([]byte, 56)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	v, err := new(Element).SetBytes(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v, new(big.Int).Mod(toBig(b), p)
+}
+
+// 与math/big对比各运算的结果
+func TestArithmetic(t *testing.T) {
+	for range 200 {
+		a, x := randomElement(t)
+		b, y := randomElement(t)
+		check := func(name string, got *Element, want *big.Int) {
+			t.Helper()
+			if toBig(got.Bytes()).Cmp(new(big.Int).Mod(want, p)) != 0 {
+				t.Fatalf("%s结果错误: a = %x, b = %x", name, a.Bytes(), b.Bytes())
+			}
+		}
+		check("Add", new(Element).Add(a, b), new(big.Int).Add(x, y))
+		check("Subtract", new(Element).Subtract(a, b), new(big.Int).Sub(x, y))
+		check("Negate", new(Element).Negate(a), new(big.Int).Neg(x))
+		check("Multiply", new(Element).Multiply(a, b), new(big.Int).Mul(x, y))
+		check("Square", new(Element).Square(a), new(big.Int).Mul(x, x))
+		check("Mult32", new(Element).Mult32(a, 39081), new(big.Int).Mul(x, big.NewInt(39081)))
+		if x.Sign() != 0 {
+			check("Invert", new(Element).Invert(a), new(big.Int).ModInverse(x, p))
+		}
+
+		// 多次运算后limb仍在范围内
+		c := new(Element).Set(a)
+		z := new(big.Int).Set(x)
+		for range 20 {
+			c.Subtract(c.Multiply(c, b), a)
+			z.Sub(z.Mul(z, y), x)
+		}
+		check("连续运算", c, z)
+	}
+
+	// 全部limb取最大值时的乘法
+	m := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 448), big.NewInt(1))
+	a, _ := new(Element).SetBytes(bytes.Repeat([]byte{0xff}, 56))
+	if toBig(new(Element).Square(a).Bytes()).Cmp(new(big.Int).Mod(new(big.Int).Mul(m, m), p)) != 0 {
+		t.Fatal("最大值平方结果错误")
+	}
+}
+
+func TestBytes(t *testing.T) {
+	// p到2^448-1之间的非规范编码按模p约简
+	for _, x := range []*big.Int{
+		p,
+		new(big.Int).Add(p, new(big.Int).Lsh(big.NewInt(1), 224)),
+		big.NewInt(0),
+		new(big.Int).Sub(p, big.NewInt(1)),
+		new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 448), big.NewInt(1)),
+	} {
+		v, _ := new(Element).SetBytes(toLE(x))
+		if toBig(v.Bytes()).Cmp(new(big.Int).Mod(x, p)) != 0 {
+			t.Fatalf("%x的编码约简错误", x)
+		}
+	}
+
+	if _, err := new(Element).SetBytes(make([]byte, 57)); err == nil {
+		t.Fatal("长度错误的编码应返回错误")
+	}
+}
+
+func TestSqrtRatio(t *testing.T) {
+	for range 100 {
+		a, x := randomElement(t)
+		b, y := randomElement(t)
+		r, wasSquare := new(Element).SqrtRatio(a, b)
+
+		ratio := new(big.Int).Mul(x, new(big.Int).ModInverse(y, p))
+		ratio.Mod(ratio, p)
+		isSquare := big.Jacobi(ratio, p) >= 0
+		if isSquare != (wasSquare == 1) {
+			t.Fatalf("平方数判断错误: %x/%x", a.Bytes(), b.Bytes())
+		}
+		if wasSquare == 1 {
+			rr := toBig(r.Bytes())
+			if new(big.Int).Mod(new(big.Int).Mul(rr, rr), p).Cmp(ratio) != 0 {
+				t.Fatal("平方根错误")
+			}
+			if r.IsNegative() != 0 {
+				t.Fatal("平方根应取偶数")
+			}
+		}
+	}
+	// p ≡ 3 (mod 4)，-1不是平方数
+	minusOne := new(Element).Negate(new(Element).One())
+	if _, ok := new(Element).SqrtRatio(minusOne, new(Element).One()); ok != 0 {
+		t.Fatal("-1不应是平方数")
+	}
+}
+
+func TestSelectSwap(t *testing.T) {
+	a, _ := randomElement(t)
+	b, _ := randomElement(t)
+	if new(Element).Select(a, b, 1).Equal(a) != 1 || new(Element).Select(a, b, 0).Equal(b) != 1 {
+		t.Fatal("Select结果错误")
+	}
+	c, d := new(Element).Set(a), new(Element).Set(b)
+	c.Swap(d, 0)
+	if c.Equal(a) != 1 || d.Equal(b) != 1 {
+		t.Fatal("cond为0时不应交换")
+	}
+	c.Swap(d, 1)
+	if c.Equal(b) != 1 || d.Equal(a) != 1 {
+		t.Fatal("cond为1时应交换")
+	}
+}
@@ -0,0 +1,238 @@
+// Package nat 实现定长自然数的常量时间模运算，供RSA私钥运算和ECDSA求逆使用
+// 运算的时间和访存模式只取决于操作数和模数的长度，不取决于其数值
+package nat
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// _W 是字长（位）
+const _W = bits.UintSize
+
+// Nat 是定长的自然数，低位字在前。密钥相关的数都用Nat表示
+type Nat []uint
+
+// FromBig 将x转换为n个字的Nat，x必须能放入n个字
+func FromBig(x *big.Int, n int) Nat {
+	out := make(Nat, n)
+	for i, w := range x.Bits() {
+		out[i] = uint(w)
+	}
+	return out
+}
+
+// Big 转换为big.Int，只用于运算结束后输出
+func (x Nat) Big() *big.Int {
+	words := make([]big.Word, len(x))
+	for i, w := range x {
+		words[i] = big.Word(w)
+	}
+	return new(big.Int).SetBits(words)
+}
+
+// ctEq 在x == y时返回1，否则返回0
+func ctEq(x, y uint) uint {
+	d := x ^ y
+	return 1 ^ ((d | -d) >> (_W - 1))
+}
+
+// assign 在on为1时将y复制到x，on为0时x不变
+func (x Nat) assign(on uint, y Nat) {
+	mask := -on
+	for i := range x {
+		x[i] ^= mask & (x[i] ^ y[i])
+	}
+}
+
+// add 计算x += y，返回进位
+func (x Nat) add(y Nat) (carry uint) {
+	for i := range x {
+		x[i], carry = bits.Add(x[i], y[i], carry)
+	}
+	return carry
+}
+
+// AddMul 计算x += a·b，结果必须能放入x；a·b中超出x长度的部分在数值上必然为0，直接跳过
+func (x Nat) AddMul(a, b Nat) {
+	for i := range a {
+		var carry uint
+		for j := 0; j < len(b) && i+j < len(x); j++ {
+			hi, lo := bits.Mul(a[i], b[j])
+			var c uint
+			lo, c = bits.Add(lo, x[i+j], 0)
+			hi += c
+			lo, c = bits.Add(lo, carry, 0)
+			hi += c
+			x[i+j], carry = lo, hi
+		}
+		for k := i + len(b); k < len(x); k++ {
+			x[k], carry = bits.Add(x[k], carry, 0)
+		}
+	}
+}
+
+// Modulus 是奇数模数及其Montgomery参数，R = 2^(W·n)
+type Modulus struct {
+	m     Nat
+	m0inv uint // -m^-1 mod 2^W
+	rr    Nat  // R^2 mod m
+}
+
+// NewModulus 为奇数m构造Modulus，R^2 mod m通过逐位加倍计算，不使用big.Int的除法
+func NewModulus(m *big.Int) *Modulus {
+	n := (m.BitLen() + _W - 1) / _W
+	mod := &Modulus{m: FromBig(m, n)}
+
+	// Newton迭代求m[0]的逆：x·inv ≡ 1 mod 2^3对奇数x总成立，每轮精度翻倍
+	inv := mod.m[0]
+	for range 5 {
+		inv *= 2 - mod.m[0]*inv
+	}
+	mod.m0inv = -inv
+
+	mod.rr = make(Nat, n)
+	mod.rr[0] = 1
+	for range 2 * n * _W {
+		mod.reduceOnce(mod.rr, mod.rr.add(mod.rr))
+	}
+	return mod
+}
+
+// Size 返回模数的字数
+func (m *Modulus) Size() int {
+	return len(m.m)
+}
+
+// Nat 返回模数本身，调用方不能修改
+func (m *Modulus) Nat() Nat {
+	return m.m
+}
+
+// reduceOnce 在x + carry·R ≥ m时减去m，要求x + carry·R < 2m
+func (m *Modulus) reduceOnce(x Nat, carry uint) {
+	var borrow uint
+	for i := range x {
+		_, borrow = bits.Sub(x[i], m.m[i], borrow)
+	}
+	// 有进位时必然不小于m，否则没有借位说明x ≥ m
+	mask := -(carry | (borrow ^ 1))
+	borrow = 0
+	for i := range x {
+		x[i], borrow = bits.Sub(x[i], m.m[i]&mask, borrow)
+	}
+}
+
+// Reduce 计算x mod m，x可以比m长。从高位到低位逐位移入，运算时间只取决于x的长度
+func (m *Modulus) Reduce(x Nat) Nat {
+	out := make(Nat, m.Size())
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := _W - 1; j >= 0; j-- {
+			carry := out.add(out)
+			out[0] |= (x[i] >> j) & 1
+			m.reduceOnce(out, carry)
+		}
+	}
+	return out
+}
+
+// ModSub 计算x = x - y mod m，x、y < m
+func (m *Modulus) ModSub(x, y Nat) {
+	var borrow uint
+	for i := range x {
+		x[i], borrow = bits.Sub(x[i], y[i], borrow)
+	}
+	// 有借位时加回m
+	mask := -borrow
+	var carry uint
+	for i := range x {
+		x[i], carry = bits.Add(x[i], m.m[i]&mask, carry)
+	}
+}
+
+// MontMul 计算out = x·y·R^-1 mod m（CIOS算法），x、y < m，out可以与x或y重叠
+func (m *Modulus) MontMul(out, x, y Nat) {
+	n := m.Size()
+	t := make(Nat, n+2)
+	for i := 0; i < n; i++ {
+		// t += x[i]·y
+		var carry, c uint
+		for j := 0; j < n; j++ {
+			hi, lo := bits.Mul(x[i], y[j])
+			lo, c = bits.Add(lo, t[j], 0)
+			hi += c
+			lo, c = bits.Add(lo, carry, 0)
+			hi += c
+			t[j], carry = lo, hi
+		}
+		t[n], c = bits.Add(t[n], carry, 0)
+		t[n+1] = c
+
+		// t = (t + u·m) / 2^W，u使t + u·m的最低字为0
+		u := t[0] * m.m0inv
+		hi, lo := bits.Mul(u, m.m[0])
+		_, c = bits.Add(lo, t[0], 0)
+		carry = hi + c
+		for j := 1; j < n; j++ {
+			hi, lo := bits.Mul(u, m.m[j])
+			lo, c = bits.Add(lo, t[j], 0)
+			hi += c
+			lo, c = bits.Add(lo, carry, 0)
+			hi += c
+			t[j-1], carry = lo, hi
+		}
+		t[n-1], c = bits.Add(t[n], carry, 0)
+		t[n] = t[n+1] + c
+	}
+	copy(out, t[:n])
+	m.reduceOnce(out, t[n])
+}
+
+// ToMont 将x < m转换为Montgomery形式x·R mod m
+func (m *Modulus) ToMont(x Nat) Nat {
+	out := make(Nat, m.Size())
+	m.MontMul(out, x, m.rr)
+	return out
+}
+
+// Exp 计算x^e mod m，x < m，e为大端序字节串
+// 使用4位固定窗口，每个窗口都做4次平方和1次乘法，查表时读取全部16项，
+// 运算时间和访存模式只取决于m和e的长度
+func (m *Modulus) Exp(x Nat, e []byte) Nat {
+	n := m.Size()
+	one := make(Nat, n)
+	one[0] = 1
+
+	// table[i] = x^i·R mod m
+	var table [16]Nat
+	table[0] = m.ToMont(one)
+	table[1] = m.ToMont(x)
+	for i := 2; i < len(table); i++ {
+		table[i] = make(Nat, n)
+		m.MontMul(table[i], table[i-1], table[1])
+	}
+
+	out := append(Nat(nil), table[0]...)
+	entry := make(Nat, n)
+	for _, b := range e {
+		for _, k := range [2]uint{uint(b >> 4), uint(b & 0x0f)} {
+			for range 4 {
+				m.MontMul(out, out, out)
+			}
+			for i := range table {
+				entry.assign(ctEq(uint(i), k), table[i])
+			}
+			m.MontMul(out, out, entry)
+		}
+	}
+	// 乘以1退出Montgomery形式
+	m.MontMul(out, out, one)
+	return out
+}
+
+// InversePrime 按费马小定理计算x^(m-2) mod m，即x在模m下的逆元，m必须是素数
+// 与big.Int.ModInverse不同，运算时间不取决于x；x为0时返回0
+func (m *Modulus) InversePrime(x Nat) Nat {
+	e := new(big.Int).Sub(m.m.Big(), big.NewInt(2))
+	return m.Exp(x, e.FillBytes(make([]byte, (e.BitLen()+7)/8)))
+}
@@ -0,0 +1,66 @@
+package nat
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+var one = big.NewInt(1)
+
+func TestNatExp(t *testing.T) {
+	for _, bits := range []int{3, 64, 65, 127, 512, 1031, 2048} {
+		for range 5 {
+			m, _ := rand.Int(rand.Reader, new(big.Int).Lsh(one, uint(bits)))
+			m.SetBit(m, 0, 1)
+			m.SetBit(m, bits-1, 1)
+			mod := NewModulus(m)
+
+			x, _ := rand.Int(rand.Reader, m)
+			e, _ := rand.Int(rand.Reader, m)
+			got := mod.Exp(FromBig(x, mod.Size()), e.Bytes()).Big()
+			if want := new(big.Int).Exp(x, e, m); got.Cmp(want) != 0 {
+				t.Fatalf("%d位: %v^%v mod %v = %v, want %v", bits, x, e, m, got, want)
+			}
+
+			// 比模数长的数的约减
+			y, _ := rand.Int(rand.Reader, new(big.Int).Lsh(one, uint(3*bits)))
+			got = mod.Reduce(FromBig(y, 3*mod.Size())).Big()
+			if want := new(big.Int).Mod(y, m); got.Cmp(want) != 0 {
+				t.Fatalf("%d位: %v mod %v = %v, want %v", bits, y, m, got, want)
+			}
+		}
+	}
+
+	// 边界值：0、1、m-1，以及全为0的指数
+	m := new(big.Int).Sub(new(big.Int).Lsh(one, 128), big.NewInt(159))
+	mod := NewModulus(m)
+	for _, x := range []*big.Int{big.NewInt(0), big.NewInt(1), new(big.Int).Sub(m, one)} {
+		for _, e := range [][]byte{{0}, {1}, {0, 0, 2}, {0xff, 0xff}} {
+			got := mod.Exp(FromBig(x, mod.Size()), e).Big()
+			if want := new(big.Int).Exp(x, new(big.Int).SetBytes(e), m); got.Cmp(want) != 0 {
+				t.Errorf("%v^%x = %v, want %v", x, e, got, want)
+			}
+		}
+	}
+}
+
+// 测试InversePrime与big.Int.ModInverse的结果相同
+func TestInversePrime(t *testing.T) {
+	// P-256基点的阶
+	n, _ := new(big.Int).SetString("ffffffff00000000ffffffffffffffffbce6faada7179e84f3b9cac2fc632551", 16)
+	mod := NewModulus(n)
+	for range 20 {
+		x, _ := rand.Int(rand.Reader, n)
+		if x.Sign() == 0 {
+			continue
+		}
+		got := mod.InversePrime(FromBig(x, mod.Size())).Big()
+		if want := new(big.Int).ModInverse(x, n); got.Cmp(want) != 0 {
+			t.Fatalf("%v^-1 = %v, want %v", x, got, want)
+		}
+	}
+	if got := mod.InversePrime(FromBig(big.NewInt(0), mod.Size())).Big(); got.Sign() != 0 {
+		t.Errorf("0^-1 = %v, want 0", got)
+	}
+}
@@ -0,0 +1,92 @@
+// Package rfc6979 按RFC 6979第3.2节由私钥和消息摘要确定性地生成签名随机数k，
+// 供ECDSA和SM2签名使用，使签名安全性不依赖于随机数发生器的质量
+package rfc6979
+
+import (
+	"crypto/hmac"
+	"hash"
+	"math/big"
+)
+
+// Generator 是以私钥和摘要为种子的HMAC_DRBG，依次输出[1, q-1]内的候选k
+// 签名时用第一个候选值，若得到的r或s为0再取下一个
+type Generator struct {
+	h    func() hash.Hash
+	q    *big.Int
+	qlen int
+	k, v []byte
+}
+
+// New 以私钥x和消息摘要digest初始化生成器，q为基点的阶，x必须在[1, q-1]内
+// h是HMAC使用的摘要算法，通常与计算digest的摘要算法相同
+func New(h func() hash.Hash, q, x *big.Int, digest []byte) *Generator {
+	g := &Generator{h: h, q: q, qlen: q.BitLen()}
+	size := h().Size()
+	g.k = make([]byte, size)
+	g.v = make([]byte, size)
+	for i := range g.v {
+		g.v[i] = 0x01
+	}
+
+	// 种子为 int2octets(x) || bits2octets(h1)
+	seed := append(g.int2octets(x), g.bits2octets(digest)...)
+	g.update(0x00, seed)
+	g.update(0x01, seed)
+	return g
+}
+
+// Next 返回下一个候选k，每次调用返回不同的值
+func (g *Generator) Next() *big.Int {
+	for {
+		var t []byte
+		for len(t)*8 < g.qlen {
+			g.v = g.mac(g.k, g.v)
+			t = append(t, g.v...)
+		}
+		k := g.bits2int(t)
+		// 为下一次调用更新状态：K = HMAC_K(V || 0x00)，V = HMAC_K(V)
+		g.k = g.mac(g.k, g.v, []byte{0x00})
+		g.v = g.mac(g.k, g.v)
+		if k.Sign() > 0 && k.Cmp(g.q) < 0 {
+			return k
+		}
+	}
+}
+
+// update 计算 K = HMAC_K(V || sep || seed)，V = HMAC_K(V)
+func (g *Generator) update(sep byte, seed []byte) {
+	g.k = g.mac(g.k, g.v, []byte{sep}, seed)
+	g.v = g.mac(g.k, g.v)
+}
+
+// mac 计算以key为密钥、各段数据依次拼接的HMAC
+func (g *Generator) mac(key []byte, data ...[]byte) []byte {
+	m := hmac.New(g.h, key)
+	for _, d := range data {
+		m.Write(d)
+	}
+	return m.Sum(nil)
+}
+
+// bits2int 取b的最高qlen位作为整数（RFC 6979第2.3.2节）
+func (g *Generator) bits2int(b []byte) *big.Int {
+	x := new(big.Int).SetBytes(b)
+	if blen := len(b) * 8; blen > g.qlen {
+		x.Rsh(x, uint(blen-g.qlen))
+	}
+	return x
+}
+
+// int2octets 将x < q编码为ceil(qlen/8)字节的大端序串（RFC 6979第2.3.3节）
+func (g *Generator) int2octets(x *big.Int) []byte {
+	return x.FillBytes(make([]byte, (g.qlen+7)/8))
+}
+
+// bits2octets 计算 int2octets(bits2int(b) mod q)（RFC 6979第2.3.4节）
+func (g *Generator) bits2octets(b []byte) []byte {
+	z := g.bits2int(b)
+	if z.Cmp(g.q) >= 0 {
+		z.Sub(z, g.q)
+	}
+	return g.int2octets(z)
+}
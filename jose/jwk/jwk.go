@@ -0,0 +1,425 @@
+// Package jwk 实现JSON Web Key（RFC 7517）的编码和解析
+//
+// 支持的密钥类型及对应的Go类型：
+//
+//	RSA  *rsa.PublicKey、*rsa.PrivateKey（解析得到crypto/rsa的类型，编码时也接受gsc/rsa的类型）
+//	EC   *ecdsa.PublicKey、*ecdsa.PrivateKey（P-256、P-384、P-521）
+//	EC   *sm2.PublicKey、*sm2.PrivateKey（crv为"SM2"，本库的扩展）
+//	OKP  ed25519.PublicKey、ed25519.PrivateKey（解析得到crypto/ed25519的类型，编码时也接受gsc/ed25519的类型）、
+//	     *ecdh.PublicKey、*ecdh.PrivateKey（X25519，RFC 8037，编码时也接受gsc/x25519的类型）
+//	oct  []byte
+//
+// 解析时检查EC和OKP公钥在曲线上、私钥与公钥一致，RSA私钥须包含p和q
+package jwk
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	stded25519 "crypto/ed25519"
+	"crypto/elliptic"
+	stdrsa "crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+
+	"github.com/laenix/gsc/ed25519"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/rsa"
+	"github.com/laenix/gsc/sm2"
+	"github.com/laenix/gsc/x25519"
+)
+
+// 错误定义
+var (
+	ErrUnsupportedKeyType = gscerr.New(gscerr.ErrUnsupported, "jwk: unsupported key type")
+	ErrUnsupportedCurve   = gscerr.New(gscerr.ErrUnsupported, "jwk: unsupported curve")
+	ErrInvalidKey         = gscerr.New(gscerr.ErrMalformed, "jwk: invalid key parameters")
+	ErrKeyNotFound        = gscerr.New(gscerr.ErrParameter, "jwk: key not found in set")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrUnsupportedKeyType: "jwk: 不支持的密钥类型",
+		ErrUnsupportedCurve:   "jwk: 不支持的曲线",
+		ErrInvalidKey:         "jwk: 密钥参数无效",
+		ErrKeyNotFound:        "jwk: 集合中没有该密钥",
+	})
+}
+
+// Key 是一个JSON Web Key
+type Key struct {
+	// Key 是密钥本身，类型见包文档
+	Key any
+	// KeyID 是密钥标识（kid）
+	KeyID string
+	// Use 是公钥用途（use），"sig"或"enc"
+	Use string
+	// Algorithm 是预期使用的算法（alg），如"ES256"
+	Algorithm string
+}
+
+// Set 是JWK集合（JWKS）
+type Set struct {
+	Keys []Key `json:"keys"`
+}
+
+// Lookup 返回集合中标识为kid的密钥
+func (s *Set) Lookup(kid string) (*Key, error) {
+	for i := range s.Keys {
+		if s.Keys[i].KeyID == kid {
+			return &s.Keys[i], nil
+		}
+	}
+	return nil, ErrKeyNotFound
+}
+
+// rawKey 是JWK的JSON表示，二进制参数为无填充base64url编码
+type rawKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	D   string `json:"d,omitempty"`
+	P   string `json:"p,omitempty"`
+	Q   string `json:"q,omitempty"`
+	DP  string `json:"dp,omitempty"`
+	DQ  string `json:"dq,omitempty"`
+	QI  string `json:"qi,omitempty"`
+	K   string `json:"k,omitempty"`
+}
+
+// b64 是JWK使用的无填充base64url编码
+var b64 = base64.RawURLEncoding
+
+// 支持的NIST曲线，按JWK中的crv名称索引
+var curves = map[string]struct {
+	curve elliptic.Curve
+	ecdh  ecdh.Curve
+}{
+	"P-256": {elliptic.P256(), ecdh.P256()},
+	"P-384": {elliptic.P384(), ecdh.P384()},
+	"P-521": {elliptic.P521(), ecdh.P521()},
+}
+
+// sm2Curve 是SM2密钥的crv名称
+const sm2Curve = "SM2"
+
+// MarshalJSON 将密钥编码为JWK，私钥包含全部私有参数
+func (k Key) MarshalJSON() ([]byte, error) {
+	raw, err := marshalKey(k.Key)
+	if err != nil {
+		return nil, err
+	}
+	raw.Kid, raw.Use, raw.Alg = k.KeyID, k.Use, k.Algorithm
+	return json.Marshal(raw)
+}
+
+// UnmarshalJSON 解析JWK并检查其参数
+func (k *Key) UnmarshalJSON(data []byte) error {
+	var raw rawKey
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	key, err := raw.key()
+	if err != nil {
+		return err
+	}
+	*k = Key{Key: key, KeyID: raw.Kid, Use: raw.Use, Algorithm: raw.Alg}
+	return nil
+}
+
+// Public 返回只含公钥的Key，对称密钥返回nil
+func (k *Key) Public() *Key {
+	pub := *k
+	switch key := k.Key.(type) {
+	case *stdrsa.PrivateKey:
+		pub.Key = &key.PublicKey
+	case *rsa.PrivateKey:
+		pub.Key = &key.PublicKey
+	case *ecdsa.PrivateKey:
+		pub.Key = &key.PublicKey
+	case *sm2.PrivateKey:
+		pub.Key = &key.PublicKey
+	case stded25519.PrivateKey:
+		pub.Key = key.Public()
+	case ed25519.PrivateKey:
+		pub.Key = key.Public()
+	case *ecdh.PrivateKey:
+		pub.Key = key.PublicKey()
+	case *x25519.PrivateKey:
+		pub.Key = key.PublicKey()
+	case []byte:
+		return nil
+	}
+	return &pub
+}
+
+// Thumbprint 返回RFC 7638定义的SHA-256指纹，即必需参数按字典序组成的JSON的摘要，
+// 常用作kid。公钥和对应私钥的指纹相同
+func (k *Key) Thumbprint() ([]byte, error) {
+	raw, err := marshalKey(k.Key)
+	if err != nil {
+		return nil, err
+	}
+	// 各成员按名称的字典序排列，值中不含需要转义的字符
+	var s string
+	switch raw.Kty {
+	case "RSA":
+		s = `{"e":"` + raw.E + `","kty":"RSA","n":"` + raw.N + `"}`
+	case "EC":
+		s = `{"crv":"` + raw.Crv + `","kty":"EC","x":"` + raw.X + `","y":"` + raw.Y + `"}`
+	case "OKP":
+		s = `{"crv":"` + raw.Crv + `","kty":"OKP","x":"` + raw.X + `"}`
+	case "oct":
+		s = `{"k":"` + raw.K + `","kty":"oct"}`
+	}
+	sum := sha256.Sum256([]byte(s))
+	return sum[:], nil
+}
+
+// marshalKey 将Go密钥转换为rawKey
+func marshalKey(key any) (*rawKey, error) {
+	switch key := key.(type) {
+	case *stdrsa.PublicKey:
+		return &rawKey{Kty: "RSA", N: encodeInt(key.N, 0), E: encodeInt(big.NewInt(int64(key.E)), 0)}, nil
+	case *stdrsa.PrivateKey:
+		if len(key.Primes) != 2 {
+			return nil, ErrUnsupportedKeyType
+		}
+		raw, _ := marshalKey(&key.PublicKey)
+		key.Precompute()
+		raw.D = encodeInt(key.D, 0)
+		raw.P, raw.Q = encodeInt(key.Primes[0], 0), encodeInt(key.Primes[1], 0)
+		raw.DP, raw.DQ, raw.QI = encodeInt(key.Precomputed.Dp, 0), encodeInt(key.Precomputed.Dq, 0), encodeInt(key.Precomputed.Qinv, 0)
+		return raw, nil
+	case *rsa.PublicKey:
+		return marshalKey(key.ToStd())
+	case *rsa.PrivateKey:
+		return marshalKey(key.ToStd())
+
+	case *ecdsa.PublicKey:
+		if _, ok := curves[key.Curve.Params().Name]; !ok {
+			return nil, ErrUnsupportedCurve
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		return &rawKey{Kty: "EC", Crv: key.Curve.Params().Name, X: encodeInt(key.X, size), Y: encodeInt(key.Y, size)}, nil
+	case *ecdsa.PrivateKey:
+		raw, err := marshalKey(&key.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		raw.D = encodeInt(key.D, (key.Curve.Params().BitSize+7)/8)
+		return raw, nil
+
+	case *sm2.PublicKey:
+		return &rawKey{Kty: "EC", Crv: sm2Curve, X: encodeInt(key.X, 32), Y: encodeInt(key.Y, 32)}, nil
+	case *sm2.PrivateKey:
+		raw, _ := marshalKey(&key.PublicKey)
+		raw.D = encodeInt(key.D, 32)
+		return raw, nil
+
+	case stded25519.PublicKey:
+		return &rawKey{Kty: "OKP", Crv: "Ed25519", X: b64.EncodeToString(key)}, nil
+	case stded25519.PrivateKey:
+		raw, _ := marshalKey(key.Public())
+		raw.D = b64.EncodeToString(key.Seed())
+		return raw, nil
+	case ed25519.PublicKey:
+		return marshalKey(stded25519.PublicKey(key))
+	case ed25519.PrivateKey:
+		return marshalKey(stded25519.PrivateKey(key))
+	case *ecdh.PublicKey:
+		if key.Curve() != ecdh.X25519() {
+			return nil, ErrUnsupportedCurve
+		}
+		return &rawKey{Kty: "OKP", Crv: "X25519", X: b64.EncodeToString(key.Bytes())}, nil
+	case *ecdh.PrivateKey:
+		raw, err := marshalKey(key.PublicKey())
+		if err != nil {
+			return nil, err
+		}
+		raw.D = b64.EncodeToString(key.Bytes())
+		return raw, nil
+	case *x25519.PublicKey:
+		return &rawKey{Kty: "OKP", Crv: "X25519", X: b64.EncodeToString(key.Bytes())}, nil
+	case *x25519.PrivateKey:
+		raw, _ := marshalKey(key.PublicKey())
+		raw.D = b64.EncodeToString(key.Bytes())
+		return raw, nil
+
+	case []byte:
+		return &rawKey{Kty: "oct", K: b64.EncodeToString(key)}, nil
+	}
+	return nil, ErrUnsupportedKeyType
+}
+
+// key 按kty将rawKey转换为Go密钥
+func (raw *rawKey) key() (any, error) {
+	switch raw.Kty {
+	case "RSA":
+		return raw.rsaKey()
+	case "EC":
+		if raw.Crv == sm2Curve {
+			return raw.sm2Key()
+		}
+		return raw.ecKey()
+	case "OKP":
+		return raw.okpKey()
+	case "oct":
+		k, err := b64.DecodeString(raw.K)
+		if err != nil || len(k) == 0 {
+			return nil, ErrInvalidKey
+		}
+		return k, nil
+	}
+	return nil, ErrUnsupportedKeyType
+}
+
+func (raw *rawKey) rsaKey() (any, error) {
+	n, err1 := decodeInt(raw.N, 0)
+	e, err2 := decodeInt(raw.E, 0)
+	if err1 != nil || err2 != nil || !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return nil, ErrInvalidKey
+	}
+	pub := &stdrsa.PublicKey{N: n, E: int(e.Int64())}
+	if raw.D == "" {
+		return pub, nil
+	}
+
+	d, err1 := decodeInt(raw.D, 0)
+	p, err2 := decodeInt(raw.P, 0)
+	q, err3 := decodeInt(raw.Q, 0)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return nil, ErrInvalidKey
+	}
+	priv := &stdrsa.PrivateKey{PublicKey: *pub, D: d, Primes: []*big.Int{p, q}}
+	if err := priv.Validate(); err != nil {
+		return nil, ErrInvalidKey
+	}
+	priv.Precompute()
+	return priv, nil
+}
+
+func (raw *rawKey) ecKey() (any, error) {
+	c, ok := curves[raw.Crv]
+	if !ok {
+		return nil, ErrUnsupportedCurve
+	}
+	size := (c.curve.Params().BitSize + 7) / 8
+	x, err1 := decodeInt(raw.X, size)
+	y, err2 := decodeInt(raw.Y, size)
+	if err1 != nil || err2 != nil {
+		return nil, ErrInvalidKey
+	}
+	// 借助crypto/ecdh检查公钥在曲线上
+	point := append([]byte{4}, append(x.FillBytes(make([]byte, size)), y.FillBytes(make([]byte, size))...)...)
+	if _, err := c.ecdh.NewPublicKey(point); err != nil {
+		return nil, ErrInvalidKey
+	}
+	pub := &ecdsa.PublicKey{Curve: c.curve, X: x, Y: y}
+	if raw.D == "" {
+		return pub, nil
+	}
+
+	d, err := decodeInt(raw.D, size)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	priv, err := c.ecdh.NewPrivateKey(d.FillBytes(make([]byte, size)))
+	if err != nil || !bytes.Equal(priv.PublicKey().Bytes(), point) {
+		return nil, ErrInvalidKey
+	}
+	return &ecdsa.PrivateKey{PublicKey: *pub, D: d}, nil
+}
+
+func (raw *rawKey) sm2Key() (any, error) {
+	x, err1 := decodeInt(raw.X, 32)
+	y, err2 := decodeInt(raw.Y, 32)
+	if err1 != nil || err2 != nil || !sm2.P256().IsOnCurve(x, y) {
+		return nil, ErrInvalidKey
+	}
+	pub := &sm2.PublicKey{X: x, Y: y}
+	if raw.D == "" {
+		return pub, nil
+	}
+
+	d, err := decodeInt(raw.D, 32)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	// DecodePrivateKey检查d在[1, n-2]内并计算公钥
+	priv, err := sm2.New().DecodePrivateKey(d.Bytes())
+	if err != nil || priv.X.Cmp(x) != 0 || priv.Y.Cmp(y) != 0 {
+		return nil, ErrInvalidKey
+	}
+	return priv, nil
+}
+
+func (raw *rawKey) okpKey() (any, error) {
+	x, err := b64.DecodeString(raw.X)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	var d []byte
+	if raw.D != "" {
+		if d, err = b64.DecodeString(raw.D); err != nil {
+			return nil, ErrInvalidKey
+		}
+	}
+
+	switch raw.Crv {
+	case "Ed25519":
+		if len(x) != ed25519.PublicKeySize {
+			return nil, ErrInvalidKey
+		}
+		if d == nil {
+			return stded25519.PublicKey(x), nil
+		}
+		priv, err := ed25519.NewKeyFromSeed(d)
+		if err != nil || !bytes.Equal(priv[ed25519.SeedSize:], x) {
+			return nil, ErrInvalidKey
+		}
+		return stded25519.PrivateKey(priv), nil
+
+	case "X25519":
+		pub, err := ecdh.X25519().NewPublicKey(x)
+		if err != nil {
+			return nil, ErrInvalidKey
+		}
+		if d == nil {
+			return pub, nil
+		}
+		priv, err := ecdh.X25519().NewPrivateKey(d)
+		if err != nil || !priv.PublicKey().Equal(pub) {
+			return nil, ErrInvalidKey
+		}
+		return priv, nil
+	}
+	return nil, ErrUnsupportedCurve
+}
+
+// encodeInt 将整数编码为base64url，size大于0时左侧补零到size字节
+func encodeInt(v *big.Int, size int) string {
+	if size == 0 {
+		size = (v.BitLen() + 7) / 8
+	}
+	return b64.EncodeToString(v.FillBytes(make([]byte, size)))
+}
+
+// decodeInt 解码base64url编码的整数，size大于0时要求长度恰为size字节
+func decodeInt(s string, size int) (*big.Int, error) {
+	b, err := b64.DecodeString(s)
+	if err != nil || len(b) == 0 || size > 0 && len(b) != size {
+		return nil, ErrInvalidKey
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,215 @@
+package jwk
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	gsced25519 "github.com/laenix/gsc/ed25519"
+	gscrsa "github.com/laenix/gsc/rsa"
+	"github.com/laenix/gsc/sm2"
+	"github.com/laenix/gsc/x25519"
+)
+
+// RFC 7638第3.1节的RSA公钥及其指纹
+const rfc7638Key = `{"kty":"RSA","n":"0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw","e":"AQAB","alg":"RS256","kid":"2011-04-29"}`
+
+func TestThumbprintVectors(t *testing.T) {
+	tests := []struct {
+		name, key, want string
+	}{
+		{"RFC 7638", rfc7638Key, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"},
+		// RFC 8037附录A.1和A.3
+		{"RFC 8037", `{"kty":"OKP","crv":"Ed25519","d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`,
+			"kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k"},
+	}
+	for _, tt := range tests {
+		var k Key
+		if err := json.Unmarshal([]byte(tt.key), &k); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		sum, err := k.Thumbprint()
+		if err != nil || b64.EncodeToString(sum) != tt.want {
+			t.Errorf("%s: 指纹 = %s, %v", tt.name, b64.EncodeToString(sum), err)
+		}
+	}
+}
+
+// RFC 7517附录A.2中的P-256私钥
+func TestRFC7517EC(t *testing.T) {
+	const data = `{"kty":"EC","crv":"P-256","x":"MKBCTNIcKUSDii11ySs3526iDZ8AiTo7Tu6KPAqv7D4","y":"4Etl6SRW2YiLUrN5vfvVHuhp7x8PxltmWWlbbM4IFyM","d":"870MB6gfuTJ4HtUnUvYMyJpr5eUZNP4Bk43bVdj3eAE","use":"enc","kid":"1"}`
+	var k Key
+	if err := json.Unmarshal([]byte(data), &k); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := k.Key.(*ecdsa.PrivateKey); !ok || k.KeyID != "1" || k.Use != "enc" {
+		t.Fatalf("解析结果 %+v", k)
+	}
+	out, err := json.Marshal(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var a, b map[string]any
+	json.Unmarshal([]byte(data), &a)
+	json.Unmarshal(out, &b)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("重新编码结果不同: %s", out)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	p521, _ := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	sm2Key, _ := sm2.New().GenerateKey(nil)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	xKey, _ := ecdh.X25519().GenerateKey(rand.Reader)
+
+	keys := []any{rsaKey, p384, p521, sm2Key, edKey, xKey, []byte("symmetric secret")}
+	for _, key := range keys {
+		full := Key{Key: key, KeyID: "kid", Algorithm: "alg"}
+		for _, k := range []*Key{&full, full.Public()} {
+			if k == nil {
+				continue
+			}
+			data, err := json.Marshal(k)
+			if err != nil {
+				t.Fatalf("%T: 编码失败: %v", k.Key, err)
+			}
+			var got Key
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("%T: 解析失败: %v\n%s", k.Key, err, data)
+			}
+			if reflect.TypeOf(got.Key) != reflect.TypeOf(k.Key) || got.KeyID != "kid" || got.Algorithm != "alg" {
+				t.Fatalf("%T: 往返结果 %+v", k.Key, got)
+			}
+			if !equal(k.Key, got.Key) {
+				t.Errorf("%T: 往返后密钥不同", k.Key)
+			}
+		}
+
+		// 公钥和私钥的指纹相同
+		if pub := full.Public(); pub != nil {
+			a, _ := full.Thumbprint()
+			b, _ := pub.Thumbprint()
+			if string(a) != string(b) {
+				t.Errorf("%T: 公钥和私钥指纹不同", key)
+			}
+		}
+	}
+
+	// SM2私钥没有Equal方法，单独比较
+	data, _ := json.Marshal(Key{Key: sm2Key})
+	if !strings.Contains(string(data), `"crv":"SM2"`) {
+		t.Errorf("SM2密钥应使用crv SM2: %s", data)
+	}
+	var got Key
+	json.Unmarshal(data, &got)
+	if priv := got.Key.(*sm2.PrivateKey); priv.D.Cmp(sm2Key.D) != 0 || priv.X.Cmp(sm2Key.X) != 0 {
+		t.Error("SM2私钥往返后不同")
+	}
+
+	// gsc/rsa、gsc/ed25519和gsc/x25519的密钥与标准库密钥编码相同
+	gscRSA := Key{Key: gscrsa.FromStdPrivateKey(rsaKey)}
+	gscEd := Key{Key: gsced25519.PrivateKey(edKey)}
+	gscX, _ := x25519.NewPrivateKey(xKey.Bytes())
+	gscXFull := Key{Key: gscX}
+	for _, pair := range [][2]Key{
+		{{Key: rsaKey}, gscRSA},
+		{{Key: &rsaKey.PublicKey}, *gscRSA.Public()},
+		{{Key: edKey}, gscEd},
+		{{Key: edKey.Public()}, *gscEd.Public()},
+		{{Key: xKey}, gscXFull},
+		{{Key: xKey.PublicKey()}, *gscXFull.Public()},
+	} {
+		want, _ := json.Marshal(pair[0])
+		got, err := json.Marshal(pair[1])
+		if err != nil || string(got) != string(want) {
+			t.Errorf("%T: 编码不同: %v\n%s", pair[1].Key, err, got)
+		}
+		a, _ := pair[0].Thumbprint()
+		b, _ := pair[1].Thumbprint()
+		if string(a) != string(b) {
+			t.Errorf("%T: 指纹不同", pair[1].Key)
+		}
+	}
+}
+
+// equal 比较标准库密钥或对称密钥，其他类型返回true
+func equal(a, b any) bool {
+	switch a := a.(type) {
+	case interface{ Equal(crypto.PrivateKey) bool }:
+		return a.Equal(b)
+	case interface{ Equal(crypto.PublicKey) bool }:
+		return a.Equal(b)
+	case []byte:
+		return bytes.Equal(a, b.([]byte))
+	}
+	return true
+}
+
+func TestSet(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	set := Set{Keys: []Key{{Key: []byte("k1"), KeyID: "a"}, {Key: edKey.Public(), KeyID: "b", Use: "sig"}}}
+	data, err := json.Marshal(&set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Set
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if k, err := got.Lookup("b"); err != nil || k.Use != "sig" {
+		t.Errorf("Lookup(b) = %+v, %v", k, err)
+	}
+	if _, err := got.Lookup("c"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Lookup(c): %v", err)
+	}
+}
+
+func TestInvalidKeys(t *testing.T) {
+	const (
+		x = "MKBCTNIcKUSDii11ySs3526iDZ8AiTo7Tu6KPAqv7D4"
+		y = "4Etl6SRW2YiLUrN5vfvVHuhp7x8PxltmWWlbbM4IFyM"
+	)
+	tests := []struct {
+		name, data string
+		want       error
+	}{
+		{"未知kty", `{"kty":"XYZ"}`, ErrUnsupportedKeyType},
+		{"未知曲线", `{"kty":"EC","crv":"P-192","x":"` + x + `","y":"` + y + `"}`, ErrUnsupportedCurve},
+		{"不在曲线上", `{"kty":"EC","crv":"P-256","x":"` + x + `","y":"` + x + `"}`, ErrInvalidKey},
+		{"坐标长度", `{"kty":"EC","crv":"P-256","x":"AQ","y":"` + y + `"}`, ErrInvalidKey},
+		{"私钥与公钥不符", `{"kty":"EC","crv":"P-256","x":"` + x + `","y":"` + y + `","d":"` + x + `"}`, ErrInvalidKey},
+		{"SM2不在曲线上", `{"kty":"EC","crv":"SM2","x":"` + x + `","y":"` + y + `"}`, ErrInvalidKey},
+		{"RSA缺少素因子", `{"kty":"RSA","n":"AQAB","e":"AQAB","d":"AQ"}`, ErrInvalidKey},
+		{"RSA指数", `{"kty":"RSA","n":"AQAB","e":"AQ"}`, ErrInvalidKey},
+		{"Ed25519长度", `{"kty":"OKP","crv":"Ed25519","x":"AQ"}`, ErrInvalidKey},
+		{"未知OKP曲线", `{"kty":"OKP","crv":"X448","x":"AQ"}`, ErrUnsupportedCurve},
+		{"空对称密钥", `{"kty":"oct","k":""}`, ErrInvalidKey},
+	}
+	for _, tt := range tests {
+		var k Key
+		if err := json.Unmarshal([]byte(tt.data), &k); !errors.Is(err, tt.want) {
+			t.Errorf("%s: 期望%v，实际%v", tt.name, tt.want, err)
+		}
+	}
+
+	p224, _ := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	if _, err := json.Marshal(Key{Key: p224}); !errors.Is(err, ErrUnsupportedCurve) {
+		t.Errorf("P-224: %v", err)
+	}
+	if _, err := json.Marshal(Key{Key: "string"}); !errors.Is(err, ErrUnsupportedKeyType) {
+		t.Errorf("未知类型: %v", err)
+	}
+}
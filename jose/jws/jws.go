@@ -0,0 +1,297 @@
+// Package jws 实现JSON Web Signature（RFC 7515）的紧凑序列化
+//
+// 支持RFC 7518中的HS256/HS384/HS512、RS256、PS256和ES256，以及使用本库sm2包的SM2签名。
+// 令牌格式为 base64url(头部) . base64url(载荷) . base64url(签名)，签名输入是前两部分加中间的点。
+//
+// 密钥按算法使用不同的类型：HS*为[]byte，RS256和PS256为*rsa.PrivateKey/*rsa.PublicKey（crypto/rsa或gsc/rsa，
+// 签名和验证都由gsc/rsa计算），
+// ES256为P-256曲线上的*ecdsa.PrivateKey/*ecdsa.PublicKey，SM2为*sm2.PrivateKey/*sm2.PublicKey。
+// 验证时由调用方指定允许的算法，不信任令牌头部中的alg，以避免算法混淆攻击
+package jws
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	stdrsa "crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"hash"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/rsa"
+	"github.com/laenix/gsc/sm2"
+)
+
+// Algorithm 是JWS签名算法，即头部中的alg
+type Algorithm string
+
+// 支持的签名算法
+const (
+	HS256 Algorithm = "HS256"
+	HS384 Algorithm = "HS384"
+	HS512 Algorithm = "HS512"
+	RS256 Algorithm = "RS256"
+	PS256 Algorithm = "PS256"
+	ES256 Algorithm = "ES256"
+	// SM2 使用SM3和默认用户标识的SM2签名，签名为 r || s（64字节）
+	// 这不是IANA注册的算法名称，只能在双方都使用本库或约定了相同名称时使用
+	SM2 Algorithm = "SM2"
+)
+
+// MinRSAKeyBits 是RS256和PS256接受的最小模数长度，见RFC 7518第3.3节
+const MinRSAKeyBits = 2048
+
+// 错误定义
+var (
+	ErrUnsupportedAlgorithm = gscerr.New(gscerr.ErrUnsupported, "jws: unsupported algorithm")
+	ErrAlgorithmNotAllowed  = gscerr.New(gscerr.ErrVerification, "jws: algorithm not allowed")
+	ErrInvalidKey           = gscerr.New(gscerr.ErrParameter, "jws: key type does not match the algorithm")
+	ErrKeyTooShort          = gscerr.New(gscerr.ErrKeySize, "jws: key too short for the algorithm")
+	ErrInvalidToken         = gscerr.New(gscerr.ErrMalformed, "jws: invalid compact serialization")
+	ErrUnsupportedCritical  = gscerr.New(gscerr.ErrUnsupported, "jws: unsupported critical header parameter")
+	ErrInvalidSignature     = gscerr.New(gscerr.ErrVerification, "jws: invalid signature")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrUnsupportedAlgorithm: "jws: 不支持的签名算法",
+		ErrAlgorithmNotAllowed:  "jws: 令牌使用的算法不在允许列表中",
+		ErrInvalidKey:           "jws: 密钥类型与算法不匹配",
+		ErrKeyTooShort:          "jws: 密钥长度不足",
+		ErrInvalidToken:         "jws: 紧凑序列化格式无效",
+		ErrUnsupportedCritical:  "jws: 不支持的crit头部参数",
+		ErrInvalidSignature:     "jws: 签名无效",
+	})
+}
+
+// Header 是JWS受保护头部
+type Header struct {
+	// Algorithm 是签名算法，Sign时必须设置
+	Algorithm Algorithm `json:"alg"`
+	// KeyID 是密钥标识，验证方可据此选择密钥
+	KeyID string `json:"kid,omitempty"`
+	// Type 是令牌的媒体类型，如"JWT"
+	Type string `json:"typ,omitempty"`
+	// ContentType 是载荷的媒体类型
+	ContentType string `json:"cty,omitempty"`
+	// Critical 列出接收方必须理解的扩展参数，本包不支持任何扩展，非空时验证失败
+	Critical []string `json:"crit,omitempty"`
+}
+
+// b64 是JWS使用的无填充base64url编码
+var b64 = base64.RawURLEncoding
+
+// Sign 使用key签名payload，返回紧凑序列化的令牌
+func Sign(payload []byte, key any, h Header) (string, error) {
+	header, err := json.Marshal(&h)
+	if err != nil {
+		return "", err
+	}
+	input := b64.EncodeToString(header) + "." + b64.EncodeToString(payload)
+	sig, err := sign(h.Algorithm, key, []byte(input))
+	if err != nil {
+		return "", err
+	}
+	return input + "." + b64.EncodeToString(sig), nil
+}
+
+// Verify 验证令牌的签名，返回载荷和头部
+// allowed列出接受的算法，头部中的alg不在其中时返回ErrAlgorithmNotAllowed
+func Verify(token string, key any, allowed ...Algorithm) ([]byte, *Header, error) {
+	h, parts, err := parse(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !slices.Contains(allowed, h.Algorithm) {
+		return nil, nil, ErrAlgorithmNotAllowed
+	}
+	if len(h.Critical) > 0 {
+		return nil, nil, ErrUnsupportedCritical
+	}
+	payload, err := b64.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, ErrInvalidToken
+	}
+	sig, err := b64.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, ErrInvalidToken
+	}
+	input := token[:len(parts[0])+1+len(parts[1])]
+	if err := verify(h.Algorithm, key, []byte(input), sig); err != nil {
+		return nil, nil, err
+	}
+	return payload, h, nil
+}
+
+// ParseHeader 解析令牌头部但不验证签名，用于按kid选择密钥，结果在验证前不可信任
+func ParseHeader(token string) (*Header, error) {
+	h, _, err := parse(token)
+	return h, err
+}
+
+// parse 拆分令牌并解码头部
+func parse(token string) (*Header, []string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, ErrInvalidToken
+	}
+	data, err := b64.DecodeString(parts[0])
+	if err != nil {
+		return nil, nil, ErrInvalidToken
+	}
+	h := new(Header)
+	if err := json.Unmarshal(data, h); err != nil || h.Algorithm == "" {
+		return nil, nil, ErrInvalidToken
+	}
+	return h, parts, nil
+}
+
+// hmacHash 返回HS*算法使用的哈希函数
+func hmacHash(alg Algorithm) func() hash.Hash {
+	switch alg {
+	case HS256:
+		return sha256.New
+	case HS384:
+		return sha512.New384
+	case HS512:
+		return sha512.New
+	}
+	return nil
+}
+
+// sign 按算法计算input的签名
+func sign(alg Algorithm, key any, input []byte) ([]byte, error) {
+	if h := hmacHash(alg); h != nil {
+		return hmacSum(h, key, input)
+	}
+	switch alg {
+	case RS256, PS256:
+		var priv *rsa.PrivateKey
+		switch k := key.(type) {
+		case *rsa.PrivateKey:
+			priv = k
+		case *stdrsa.PrivateKey:
+			priv = rsa.FromStdPrivateKey(k)
+		default:
+			return nil, ErrInvalidKey
+		}
+		if priv.N.BitLen() < MinRSAKeyBits {
+			return nil, ErrKeyTooShort
+		}
+		digest := sha256.Sum256(input)
+		if alg == RS256 {
+			return rsa.SignPKCS1v15(priv, rsa.SHA256, digest[:])
+		}
+		// PS256的盐长度等于摘要长度，MGF1使用SHA-256
+		return rsa.SignPSS(nil, priv, rsa.SHA256, digest[:], nil)
+
+	case ES256:
+		priv, ok := key.(*ecdsa.PrivateKey)
+		if !ok || priv.Curve != elliptic.P256() {
+			return nil, ErrInvalidKey
+		}
+		digest := sha256.Sum256(input)
+		r, s, err := ecdsa.Sign(rand.Reader, priv, digest[:])
+		if err != nil {
+			return nil, err
+		}
+		// 签名为定长的 r || s，不是DER
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig, nil
+
+	case SM2:
+		priv, ok := key.(*sm2.PrivateKey)
+		if !ok {
+			return nil, ErrInvalidKey
+		}
+		return sm2.New().SignWithId(priv, input, nil)
+	}
+	return nil, ErrUnsupportedAlgorithm
+}
+
+// verify 按算法验证input的签名
+func verify(alg Algorithm, key any, input, sig []byte) error {
+	if h := hmacHash(alg); h != nil {
+		want, err := hmacSum(h, key, input)
+		if err != nil {
+			return err
+		}
+		if !hmac.Equal(sig, want) {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+
+	var ok bool
+	switch alg {
+	case RS256, PS256:
+		var pub *rsa.PublicKey
+		switch k := key.(type) {
+		case *rsa.PublicKey:
+			pub = k
+		case *stdrsa.PublicKey:
+			pub = rsa.FromStdPublicKey(k)
+		default:
+			return ErrInvalidKey
+		}
+		if pub.N.BitLen() < MinRSAKeyBits {
+			return ErrKeyTooShort
+		}
+		digest := sha256.Sum256(input)
+		if alg == RS256 {
+			ok = rsa.VerifyPKCS1v15(pub, rsa.SHA256, digest[:], sig) == nil
+		} else {
+			ok = rsa.VerifyPSS(pub, rsa.SHA256, digest[:], sig, nil) == nil
+		}
+
+	case ES256:
+		pub, isECDSA := key.(*ecdsa.PublicKey)
+		if !isECDSA || pub.Curve != elliptic.P256() {
+			return ErrInvalidKey
+		}
+		if len(sig) != 64 {
+			return ErrInvalidSignature
+		}
+		digest := sha256.Sum256(input)
+		ok = ecdsa.Verify(pub, digest[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:]))
+
+	case SM2:
+		pub, isSM2 := key.(*sm2.PublicKey)
+		if !isSM2 {
+			return ErrInvalidKey
+		}
+		ok = sm2.New().VerifyWithId(pub, input, sig, nil)
+
+	default:
+		return ErrUnsupportedAlgorithm
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// hmacSum 计算HMAC，密钥不得短于哈希输出（RFC 7518第3.2节）
+func hmacSum(h func() hash.Hash, key any, input []byte) ([]byte, error) {
+	secret, ok := key.([]byte)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+	m := hmac.New(h, secret)
+	if len(secret) < m.Size() {
+		return nil, gscerr.KeySize(ErrKeyTooShort, "HMAC", len(secret), m.Size())
+	}
+	m.Write(input)
+	return m.Sum(nil), nil
+}
@@ -0,0 +1,183 @@
+package jws
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	gscrsa "github.com/laenix/gsc/rsa"
+	"github.com/laenix/gsc/sm2"
+)
+
+// RFC 7515附录A中的载荷
+const rfcPayload = "{\"iss\":\"joe\",\r\n \"exp\":1300819380,\r\n \"http://example.com/is_root\":true}"
+
+func mustB64(s string) []byte {
+	b, err := b64.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// RFC 7515附录A.1（HS256）
+func TestRFC7515HS256(t *testing.T) {
+	key := mustB64("AyM1SysPpbyDfgZld3umj1qzKObwVMkoqQ-EstJQLr_T-1qS0gZH75aKtMN3Yj0iPS4hcgUuTwjAzZr1Z9CAow")
+	token := "eyJ0eXAiOiJKV1QiLA0KICJhbGciOiJIUzI1NiJ9." +
+		"eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ." +
+		"dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	payload, h, err := Verify(token, key, HS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(payload) != rfcPayload || h.Type != "JWT" {
+		t.Errorf("载荷 %q，头部 %+v", payload, h)
+	}
+}
+
+// RFC 7515附录A.3（ES256）
+func TestRFC7515ES256(t *testing.T) {
+	pub := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(mustB64("f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU")),
+		Y:     new(big.Int).SetBytes(mustB64("x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI5a0")),
+	}
+	token := "eyJhbGciOiJFUzI1NiJ9." +
+		"eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ." +
+		"DtEhU3ljbEg8L38VWAfUAqOyKAM6-Xx-F4GawxaepmXFCgfTjDxw5djxLa8ISlSApmWQxfKTUJqPP3-Kg6NU1Q"
+	if payload, _, err := Verify(token, pub, ES256); err != nil || string(payload) != rfcPayload {
+		t.Errorf("载荷 %q，%v", payload, err)
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sm2Key, err := sm2.New().GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := make([]byte, 64)
+	gscKey := gscrsa.FromStdPrivateKey(rsaKey)
+
+	tests := []struct {
+		alg       Algorithm
+		priv, pub any
+	}{
+		{HS256, secret, secret},
+		{HS384, secret, secret},
+		{HS512, secret, secret},
+		{RS256, rsaKey, &rsaKey.PublicKey},
+		{PS256, rsaKey, &rsaKey.PublicKey},
+		{RS256, gscKey, &rsaKey.PublicKey},
+		{PS256, rsaKey, &gscKey.PublicKey},
+		{ES256, ecKey, &ecKey.PublicKey},
+		{SM2, sm2Key, &sm2Key.PublicKey},
+	}
+	for _, tt := range tests {
+		token, err := Sign([]byte(`{"sub":"alice"}`), tt.priv, Header{Algorithm: tt.alg, KeyID: "k1", Type: "JWT"})
+		if err != nil {
+			t.Fatalf("%s: 签名失败: %v", tt.alg, err)
+		}
+		payload, h, err := Verify(token, tt.pub, tt.alg)
+		if err != nil || string(payload) != `{"sub":"alice"}` || h.KeyID != "k1" {
+			t.Errorf("%s: 验证结果 %q, %+v, %v", tt.alg, payload, h, err)
+		}
+		// RSA签名由crypto/rsa独立验证
+		if tt.alg == RS256 || tt.alg == PS256 {
+			i := strings.LastIndexByte(token, '.')
+			digest := sha256.Sum256([]byte(token[:i]))
+			sig, _ := b64.DecodeString(token[i+1:])
+			err := rsa.VerifyPKCS1v15(&rsaKey.PublicKey, crypto.SHA256, digest[:], sig)
+			if tt.alg == PS256 {
+				err = rsa.VerifyPSS(&rsaKey.PublicKey, crypto.SHA256, digest[:], sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+			}
+			if err != nil {
+				t.Errorf("%s: crypto/rsa验证失败: %v", tt.alg, err)
+			}
+		}
+
+		// 修改载荷
+		parts := strings.Split(token, ".")
+		forged := parts[0] + "." + b64.EncodeToString([]byte(`{"sub":"admin"}`)) + "." + parts[2]
+		if _, _, err := Verify(forged, tt.pub, tt.alg); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: 修改载荷后应验证失败: %v", tt.alg, err)
+		}
+	}
+}
+
+// 测试验证方限定算法，防止以公钥为HMAC密钥等算法混淆
+func TestAlgorithmConfusion(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	secret := make([]byte, 32)
+	token, _ := Sign([]byte("x"), secret, Header{Algorithm: HS256})
+
+	if _, _, err := Verify(token, &ecKey.PublicKey, ES256); !errors.Is(err, ErrAlgorithmNotAllowed) {
+		t.Errorf("alg不在允许列表中: %v", err)
+	}
+	if _, _, err := Verify(token, &ecKey.PublicKey, HS256); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("密钥类型与alg不符: %v", err)
+	}
+	none := b64.EncodeToString([]byte(`{"alg":"none"}`)) + "." + b64.EncodeToString([]byte("x")) + "."
+	if _, _, err := Verify(none, secret, HS256); !errors.Is(err, ErrAlgorithmNotAllowed) {
+		t.Errorf("alg为none: %v", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	small, _ := rsa.GenerateKey(rand.Reader, 1024)
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	secret := make([]byte, 32)
+	crit, _ := Sign([]byte("x"), secret, Header{Algorithm: HS256, Critical: []string{"exp"}})
+	plain, _ := Sign([]byte("x"), secret, Header{Algorithm: HS256})
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"未知算法", signErr([]byte("k"), "HS1"), ErrUnsupportedAlgorithm},
+		{"HMAC密钥过短", signErr(make([]byte, 31), HS256), ErrKeyTooShort},
+		{"HS512密钥过短", signErr(secret, HS512), ErrKeyTooShort},
+		{"RSA密钥过短", signErr(small, RS256), ErrKeyTooShort},
+		{"ES256曲线错误", signErr(p384, ES256), ErrInvalidKey},
+		{"SM2密钥类型", signErr(secret, SM2), ErrInvalidKey},
+		{"crit", verifyErr(crit, secret), ErrUnsupportedCritical},
+		{"两段", verifyErr("a.b", secret), ErrInvalidToken},
+		{"头部非JSON", verifyErr(b64.EncodeToString([]byte("{"))+".e30.", secret), ErrInvalidToken},
+		{"缺少alg", verifyErr("e30.e30.", secret), ErrInvalidToken},
+		{"签名非base64url", verifyErr(plain[:strings.LastIndex(plain, ".")]+".!!", secret), ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.want) {
+			t.Errorf("%s: 期望%v，实际%v", tt.name, tt.want, tt.err)
+		}
+	}
+
+	if h, err := ParseHeader(crit); err != nil || h.Algorithm != HS256 {
+		t.Errorf("ParseHeader = %+v, %v", h, err)
+	}
+}
+
+func signErr(key any, alg Algorithm) error {
+	_, err := Sign([]byte("x"), key, Header{Algorithm: alg})
+	return err
+}
+
+func verifyErr(token string, key any) error {
+	_, _, err := Verify(token, key, HS256)
+	return err
+}
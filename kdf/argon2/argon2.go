@@ -0,0 +1,354 @@
+package argon2
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"sync"
+
+	"github.com/laenix/gsc/blake2b"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+// Argon2版本号（0x13 即 v1.3）
+const Version = 0x13
+
+// 算法变体
+const (
+	argon2d  = 0
+	argon2i  = 1
+	argon2id = 2
+)
+
+const (
+	// 每个内存块包含128个64位字（1024字节）
+	blockLength = 128
+	// 每轮分为4个同步片段
+	syncPoints = 4
+)
+
+// 错误定义
+var (
+	ErrInvalidTime    = gscerr.New(gscerr.ErrParameter, "argon2: time must be greater than 0")
+	ErrInvalidThreads = gscerr.New(gscerr.ErrParameter, "argon2: threads must be greater than 0")
+	ErrInvalidKeyLen  = gscerr.New(gscerr.ErrParameter, "argon2: key length must be at least 4 bytes")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidTime:    "argon2: 迭代次数必须大于0",
+		ErrInvalidThreads: "argon2: 并行度必须大于0",
+		ErrInvalidKeyLen:  "argon2: 输出长度必须至少为4字节",
+	})
+}
+
+type block [blockLength]uint64
+
+// IDKey 使用Argon2id从口令派生keyLen字节的密钥
+// time为迭代次数，memory为内存大小（KiB），threads为并行度
+// RFC 9106推荐 time=1, memory=2*1024*1024 或 time=3, memory=64*1024
+func IDKey(password, salt []byte, time, memory uint32, threads uint8, keyLen uint32) ([]byte, error) {
+	return deriveKey(argon2id, password, salt, nil, nil, time, memory, threads, keyLen)
+}
+
+// Key 使用Argon2i从口令派生keyLen字节的密钥
+// Argon2i的内存访问模式与口令无关，可抵抗侧信道攻击，但需要更多迭代次数
+func Key(password, salt []byte, time, memory uint32, threads uint8, keyLen uint32) ([]byte, error) {
+	return deriveKey(argon2i, password, salt, nil, nil, time, memory, threads, keyLen)
+}
+
+// deriveKey 实现Argon2的完整流程，secret和data分别为可选的密钥和附加数据
+func deriveKey(mode int, password, salt, secret, data []byte, time, memory uint32, threads uint8, keyLen uint32) ([]byte, error) {
+	if time < 1 {
+		return nil, ErrInvalidTime
+	}
+	if threads < 1 {
+		return nil, ErrInvalidThreads
+	}
+	if keyLen < 4 {
+		return nil, ErrInvalidKeyLen
+	}
+
+	// 1. 计算H0
+	h0 := initHash(password, salt, secret, data, time, memory, uint32(threads), keyLen, mode)
+
+	// 2. 内存块数向下取整为 4*p 的倍数，且至少为 8*p
+	p := uint32(threads)
+	if memory < 2*syncPoints*p {
+		memory = 2 * syncPoints * p
+	}
+	memory = memory / (syncPoints * p) * (syncPoints * p)
+
+	// 3. 初始化每条lane的前两个块并填充内存
+	b := initBlocks(&h0, memory, p)
+	processBlocks(b, time, memory, p, mode)
+
+	// 4. 最后一列异或后计算输出
+	return extractKey(b, memory, p, keyLen), nil
+}
+
+// initHash 计算 H0 = H^(64)(p, T, m, t, v, y, P, S, K, X)
+func initHash(password, salt, secret, data []byte, time, memory, threads, keyLen uint32, mode int) [blake2b.Size + 8]byte {
+	var h0 [blake2b.Size + 8]byte
+	var params [24]byte
+	var tmp [4]byte
+
+	h, _ := blake2b.New512(nil)
+	binary.LittleEndian.PutUint32(params[0:4], threads)
+	binary.LittleEndian.PutUint32(params[4:8], keyLen)
+	binary.LittleEndian.PutUint32(params[8:12], memory)
+	binary.LittleEndian.PutUint32(params[12:16], time)
+	binary.LittleEndian.PutUint32(params[16:20], uint32(Version))
+	binary.LittleEndian.PutUint32(params[20:24], uint32(mode))
+	h.Write(params[:])
+
+	for _, field := range [][]byte{password, salt, secret, data} {
+		binary.LittleEndian.PutUint32(tmp[:], uint32(len(field)))
+		h.Write(tmp[:])
+		h.Write(field)
+	}
+
+	h.Sum(h0[:0])
+	return h0
+}
+
+// initBlocks 分配内存并计算每条lane的B[i][0]和B[i][1]
+func initBlocks(h0 *[blake2b.Size + 8]byte, memory, threads uint32) []block {
+	var buf [1024]byte
+	b := make([]block, memory)
+	lanes := memory / threads
+
+	for lane := uint32(0); lane < threads; lane++ {
+		j := lane * lanes
+		binary.LittleEndian.PutUint32(h0[blake2b.Size+4:], lane)
+
+		binary.LittleEndian.PutUint32(h0[blake2b.Size:], 0)
+		blake2bHash(buf[:], h0[:])
+		for i := range b[j] {
+			b[j][i] = binary.LittleEndian.Uint64(buf[i*8:])
+		}
+
+		binary.LittleEndian.PutUint32(h0[blake2b.Size:], 1)
+		blake2bHash(buf[:], h0[:])
+		for i := range b[j+1] {
+			b[j+1][i] = binary.LittleEndian.Uint64(buf[i*8:])
+		}
+	}
+	return b
+}
+
+// processBlocks 执行time轮内存填充，每个片段内各lane并行计算
+func processBlocks(b []block, time, memory, threads uint32, mode int) {
+	lanes := memory / threads
+	segments := lanes / syncPoints
+
+	processSegment := func(n, slice, lane uint32, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		// 与口令无关的寻址（Argon2i，以及Argon2id第一轮的前半部分）
+		dataIndependent := mode == argon2i || (mode == argon2id && n == 0 && slice < syncPoints/2)
+
+		var addresses, in, zero block
+		if dataIndependent {
+			in[0] = uint64(n)
+			in[1] = uint64(lane)
+			in[2] = uint64(slice)
+			in[3] = uint64(memory)
+			in[4] = uint64(time)
+			in[5] = uint64(mode)
+		}
+
+		index := uint32(0)
+		if n == 0 && slice == 0 {
+			// 前两个块已在initBlocks中生成
+			index = 2
+			if dataIndependent {
+				in[6]++
+				processBlock(&addresses, &in, &zero)
+				processBlock(&addresses, &addresses, &zero)
+			}
+		}
+
+		offset := lane*lanes + slice*segments + index
+		var random uint64
+		for index < segments {
+			prev := offset - 1
+			if index == 0 && slice == 0 {
+				// lane的第一个块引用该lane的最后一个块
+				prev += lanes
+			}
+			if dataIndependent {
+				if index%blockLength == 0 {
+					in[6]++
+					processBlock(&addresses, &in, &zero)
+					processBlock(&addresses, &addresses, &zero)
+				}
+				random = addresses[index%blockLength]
+			} else {
+				random = b[prev][0]
+			}
+			ref := indexAlpha(random, lanes, segments, threads, n, slice, lane, index)
+			processBlockXOR(&b[offset], &b[prev], &b[ref])
+			index, offset = index+1, offset+1
+		}
+	}
+
+	for n := uint32(0); n < time; n++ {
+		for slice := uint32(0); slice < syncPoints; slice++ {
+			var wg sync.WaitGroup
+			for lane := uint32(0); lane < threads; lane++ {
+				wg.Add(1)
+				go processSegment(n, slice, lane, &wg)
+			}
+			wg.Wait()
+		}
+	}
+}
+
+// indexAlpha 根据伪随机数计算参考块的位置
+func indexAlpha(random uint64, lanes, segments, threads, n, slice, lane, index uint32) uint32 {
+	refLane := uint32(random>>32) % threads
+	if n == 0 && slice == 0 {
+		refLane = lane
+	}
+
+	// m为参考区域大小，s为参考区域起点
+	m, s := 3*segments, ((slice+1)%syncPoints)*segments
+	if lane == refLane {
+		m += index
+	}
+	if n == 0 {
+		m, s = slice*segments, 0
+		if slice == 0 || lane == refLane {
+			m += index
+		}
+	}
+	if index == 0 || lane == refLane {
+		m--
+	}
+
+	// 非均匀映射：偏向最近生成的块
+	x := random & 0xFFFFFFFF
+	x = (x * x) >> 32
+	x = (x * uint64(m)) >> 32
+	return refLane*lanes + uint32((uint64(s)+uint64(m)-(x+1))%uint64(lanes))
+}
+
+// extractKey 将各lane最后一个块异或后计算H'得到输出
+func extractKey(b []block, memory, threads, keyLen uint32) []byte {
+	lanes := memory / threads
+	for lane := uint32(0); lane < threads-1; lane++ {
+		for i, v := range b[(lane*lanes)+lanes-1] {
+			b[memory-1][i] ^= v
+		}
+	}
+
+	var buf [1024]byte
+	for i, v := range b[memory-1] {
+		binary.LittleEndian.PutUint64(buf[i*8:], v)
+	}
+	key := make([]byte, keyLen)
+	blake2bHash(key, buf[:])
+	return key
+}
+
+// blake2bHash 为Argon2的变长哈希函数H'，输出长度为len(out)
+func blake2bHash(out []byte, in []byte) {
+	var lenBuf [4]byte
+	binary.LittleEndian.PutUint32(lenBuf[:], uint32(len(out)))
+
+	if len(out) <= blake2b.Size {
+		h, _ := blake2b.New(len(out), nil)
+		h.Write(lenBuf[:])
+		h.Write(in)
+		h.Sum(out[:0])
+		return
+	}
+
+	// V1 = H^(64)(LE32(T) || X)，Vi = H^(64)(Vi-1)，每次输出前32字节
+	h, _ := blake2b.New512(nil)
+	h.Write(lenBuf[:])
+	h.Write(in)
+	v := h.Sum(nil)
+
+	r := (len(out)+31)/32 - 2
+	pos := 0
+	for i := 1; i < r; i++ {
+		copy(out[pos:], v[:32])
+		pos += 32
+		h.Reset()
+		h.Write(v)
+		v = h.Sum(v[:0])
+	}
+	copy(out[pos:], v[:32])
+	pos += 32
+
+	// 最后一段使用剩余长度作为摘要长度
+	last, _ := blake2b.New(len(out)-pos, nil)
+	last.Write(v)
+	last.Sum(out[pos:pos])
+}
+
+// processBlock 计算压缩函数 out = G(in1, in2)
+func processBlock(out, in1, in2 *block) {
+	processBlockGeneric(out, in1, in2, false)
+}
+
+// processBlockXOR 计算 out ^= G(in1, in2)（v1.3中第二轮起需要与旧块异或）
+func processBlockXOR(out, in1, in2 *block) {
+	processBlockGeneric(out, in1, in2, true)
+}
+
+// processBlockGeneric 为Argon2压缩函数G：R = X ^ Y，先按行再按列应用置换P，结果为 Z ^ R
+func processBlockGeneric(out, in1, in2 *block, xor bool) {
+	var r, z block
+	for i := range r {
+		r[i] = in1[i] ^ in2[i]
+	}
+	z = r
+
+	// 按行：每行16个连续字
+	for i := 0; i < blockLength; i += 16 {
+		blamka(&z, i, i+1, i+2, i+3, i+4, i+5, i+6, i+7, i+8, i+9, i+10, i+11, i+12, i+13, i+14, i+15)
+	}
+	// 按列：每列由8行中相同位置的两个字组成
+	for i := 0; i < blockLength/8; i += 2 {
+		blamka(&z, i, i+1, 16+i, 16+i+1, 32+i, 32+i+1, 48+i, 48+i+1,
+			64+i, 64+i+1, 80+i, 80+i+1, 96+i, 96+i+1, 112+i, 112+i+1)
+	}
+
+	if xor {
+		for i := range out {
+			out[i] ^= r[i] ^ z[i]
+		}
+	} else {
+		for i := range out {
+			out[i] = r[i] ^ z[i]
+		}
+	}
+}
+
+// blamka 对16个字执行BLAKE2b轮函数（加法替换为 a + b + 2*lo(a)*lo(b)）
+func blamka(v *block, t0, t1, t2, t3, t4, t5, t6, t7, t8, t9, t10, t11, t12, t13, t14, t15 int) {
+	gb(v, t0, t4, t8, t12)
+	gb(v, t1, t5, t9, t13)
+	gb(v, t2, t6, t10, t14)
+	gb(v, t3, t7, t11, t15)
+	gb(v, t0, t5, t10, t15)
+	gb(v, t1, t6, t11, t12)
+	gb(v, t2, t7, t8, t13)
+	gb(v, t3, t4, t9, t14)
+}
+
+// gb 为BlaMka的混合函数
+func gb(v *block, a, b, c, d int) {
+	v[a] += v[b] + 2*uint64(uint32(v[a]))*uint64(uint32(v[b]))
+	v[d] = bits.RotateLeft64(v[d]^v[a], -32)
+	v[c] += v[d] + 2*uint64(uint32(v[c]))*uint64(uint32(v[d]))
+	v[b] = bits.RotateLeft64(v[b]^v[c], -24)
+	v[a] += v[b] + 2*uint64(uint32(v[a]))*uint64(uint32(v[b]))
+	v[d] = bits.RotateLeft64(v[d]^v[a], -16)
+	v[c] += v[d] + 2*uint64(uint32(v[c]))*uint64(uint32(v[d]))
+	v[b] = bits.RotateLeft64(v[b]^v[c], -63)
+}
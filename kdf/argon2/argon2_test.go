@@ -0,0 +1,109 @@
+package argon2
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+type argon2Test struct {
+	mode int
+	out  string
+}
+
+// RFC 9106 第5节测试向量
+// P = 32个0x01，S = 16个0x02，K = 8个0x03，X = 12个0x04，m=32，t=3，p=4
+var golden = []argon2Test{
+	{argon2d, "512b391b6f1162975371d30919734294f868e3be3984f3c1a13a4db9fabe4acb"},
+	{argon2i, "c814d9d1dc7f37aa13f0d77f2494bda1c8de6b016dd388d29952a4c4672b6ce8"},
+	{argon2id, "0d640df58d78766c08c037a34a8b53c9d01ef0452d75b65eb52520e96b01e659"},
+}
+
+// 测试RFC 9106标准向量
+func TestVectors(t *testing.T) {
+	password := bytes.Repeat([]byte{0x01}, 32)
+	salt := bytes.Repeat([]byte{0x02}, 16)
+	secret := bytes.Repeat([]byte{0x03}, 8)
+	data := bytes.Repeat([]byte{0x04}, 12)
+
+	for _, test := range golden {
+		key, err := deriveKey(test.mode, password, salt, secret, data, 3, 32, 4, 32)
+		if err != nil {
+			t.Fatalf("%s: 派生失败: %v", modeName(test.mode), err)
+		}
+		if got := hex.EncodeToString(key); got != test.out {
+			t.Errorf("%s: 结果不匹配\n期望值: %s\n实际值: %s", modeName(test.mode), test.out, got)
+		}
+	}
+}
+
+// 测试编码字符串的生成与校验
+func TestGenerateAndCompare(t *testing.T) {
+	params := Params{Time: 1, Memory: 64, Threads: 2, SaltLen: 16, KeyLen: 32}
+	encoded, err := GenerateFromPassword([]byte("correct horse"), params)
+	if err != nil {
+		t.Fatalf("生成失败: %v", err)
+	}
+	if !bytes.HasPrefix([]byte(encoded), []byte("$argon2id$v=19$m=64,t=1,p=2$")) {
+		t.Errorf("编码格式不正确: %s", encoded)
+	}
+
+	if err := CompareHashAndPassword(encoded, []byte("correct horse")); err != nil {
+		t.Errorf("正确口令校验失败: %v", err)
+	}
+	if err := CompareHashAndPassword(encoded, []byte("wrong horse")); err != ErrMismatchedPassword {
+		t.Errorf("期望ErrMismatchedPassword，实际: %v", err)
+	}
+}
+
+// 测试与参考实现生成的编码字符串互通
+func TestCompareReference(t *testing.T) {
+	// 由参考实现生成：echo -n password | argon2 somesalt -id -t 2 -m 16 -p 4
+	encoded := "$argon2id$v=19$m=65536,t=2,p=4$c29tZXNhbHQ$GpZ3sK/oH9p7VIiV56G/64Zo/8GaUw434IimaPqxwCo"
+	if err := CompareHashAndPassword(encoded, []byte("password")); err != nil {
+		t.Errorf("参考哈希校验失败: %v", err)
+	}
+}
+
+// 测试无效编码
+func TestInvalidEncoding(t *testing.T) {
+	cases := []string{
+		"",
+		"$argon2x$v=19$m=64,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=16$m=64,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=64,t=1$c2FsdA$aGFzaA",
+	}
+	for _, c := range cases {
+		if err := CompareHashAndPassword(c, []byte("p")); err == nil {
+			t.Errorf("%q 应该返回错误", c)
+		}
+	}
+
+	// 超出上限的参数和过短的盐在计算之前被拒绝
+	for _, c := range []string{
+		"$argon2id$v=19$m=4194305,t=1,p=1$c29tZXNhbHQ$aGFzaA",
+		"$argon2id$v=19$m=4294967295,t=1,p=1$c29tZXNhbHQ$aGFzaA",
+		"$argon2id$v=19$m=64,t=1025,p=1$c29tZXNhbHQ$aGFzaA",
+		"$argon2id$v=19$m=64,t=4294967295,p=1$c29tZXNhbHQ$aGFzaA",
+		"$argon2id$v=19$m=64,t=1,p=256$c29tZXNhbHQ$aGFzaA",
+		"$argon2id$v=19$m=64,t=1,p=9$c29tZXNhbHQ$aGFzaA",
+		"$argon2id$v=19$m=64,t=0,p=1$c29tZXNhbHQ$aGFzaA",
+		"$argon2id$v=19$m=64,t=1,p=0$c29tZXNhbHQ$aGFzaA",
+		// 盐短于8字节
+		"$argon2id$v=19$m=64,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=64,t=1,p=1$$aGFzaA",
+	} {
+		if err := CompareHashAndPassword(c, []byte("p")); !errors.Is(err, ErrInvalidHash) {
+			t.Errorf("%q: 期望ErrInvalidHash，实际: %v", c, err)
+		}
+	}
+}
+
+// 测试生成时拒绝过短的盐
+func TestGenerateShortSalt(t *testing.T) {
+	params := Params{Time: 1, Memory: 64, Threads: 1, SaltLen: MinSaltLen - 1, KeyLen: 32}
+	if _, err := GenerateFromPassword([]byte("p"), params); !errors.Is(err, ErrInvalidSaltLen) {
+		t.Errorf("期望ErrInvalidSaltLen，实际: %v", err)
+	}
+}
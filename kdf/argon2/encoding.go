@@ -0,0 +1,167 @@
+package argon2
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+// 错误定义
+var (
+	ErrInvalidHash         = gscerr.New(gscerr.ErrMalformed, "argon2: invalid encoded hash")
+	ErrIncompatibleVersion = gscerr.New(gscerr.ErrUnsupported, "argon2: incompatible version")
+	ErrMismatchedPassword  = gscerr.New(gscerr.ErrVerification, "argon2: password does not match")
+	ErrInvalidSaltLen      = gscerr.New(gscerr.ErrParameter, "argon2: salt must be at least 8 bytes")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidHash:         "argon2: 编码格式无效",
+		ErrIncompatibleVersion: "argon2: 不兼容的版本",
+		ErrMismatchedPassword:  "argon2: 口令不匹配",
+		ErrInvalidSaltLen:      "argon2: 盐长度必须至少为8字节",
+	})
+}
+
+// 解析编码哈希时接受的参数上限，与gsc文件容器相同，防止伪造的编码耗尽内存或CPU
+const (
+	maxDecodeTime   = 1 << 10
+	maxDecodeMemory = 4 << 20 // KiB，即4 GiB
+)
+
+// MinSaltLen 是RFC 9106要求的最小盐长度（字节）
+const MinSaltLen = 8
+
+// Params 定义口令哈希使用的参数
+type Params struct {
+	Time    uint32 // 迭代次数
+	Memory  uint32 // 内存大小（KiB）
+	Threads uint8  // 并行度
+	SaltLen uint32 // 盐长度（字节）
+	KeyLen  uint32 // 哈希长度（字节）
+}
+
+// DefaultParams 返回RFC 9106第二推荐参数（64 MiB 内存，3次迭代）
+func DefaultParams() Params {
+	return Params{
+		Time:    3,
+		Memory:  64 * 1024,
+		Threads: 4,
+		SaltLen: 16,
+		KeyLen:  32,
+	}
+}
+
+// GenerateFromPassword 使用Argon2id计算口令哈希，返回标准编码字符串
+// 格式：$argon2id$v=19$m=65536,t=3,p=4$<盐>$<哈希>（Base64无填充）。
+// params.SaltLen小于MinSaltLen时返回ErrInvalidSaltLen
+func GenerateFromPassword(password []byte, params Params) (string, error) {
+	if params.SaltLen < MinSaltLen {
+		return "", ErrInvalidSaltLen
+	}
+	salt := make([]byte, params.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key, err := IDKey(password, salt, params.Time, params.Memory, params.Threads, params.KeyLen)
+	if err != nil {
+		return "", err
+	}
+	return encode(argon2id, params, salt, key), nil
+}
+
+// CompareHashAndPassword 比较编码的口令哈希与口令，匹配时返回nil
+// 编码中的t超过1024、m超过4 GiB或小于8p、盐短于8字节时返回ErrInvalidHash，不进行计算
+func CompareHashAndPassword(encoded string, password []byte) error {
+	mode, params, salt, key, err := decode(encoded)
+	if err != nil {
+		return err
+	}
+
+	other, err := deriveKey(mode, password, salt, nil, nil, params.Time, params.Memory, params.Threads, params.KeyLen)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return ErrMismatchedPassword
+	}
+	return nil
+}
+
+// encode 生成PHC字符串格式
+func encode(mode int, params Params, salt, key []byte) string {
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		modeName(mode), Version, params.Memory, params.Time, params.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key))
+}
+
+// decode 解析PHC字符串格式
+func decode(encoded string) (int, Params, []byte, []byte, error) {
+	var params Params
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" {
+		return 0, params, nil, nil, ErrInvalidHash
+	}
+
+	var mode int
+	switch parts[1] {
+	case "argon2id":
+		mode = argon2id
+	case "argon2i":
+		mode = argon2i
+	case "argon2d":
+		mode = argon2d
+	default:
+		return 0, params, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return 0, params, nil, nil, ErrInvalidHash
+	}
+	if version != Version {
+		return 0, params, nil, nil, ErrIncompatibleVersion
+	}
+
+	// p的上限由uint8保证，超过255时解析失败
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return 0, params, nil, nil, ErrInvalidHash
+	}
+	if params.Time == 0 || params.Time > maxDecodeTime || params.Threads == 0 ||
+		params.Memory < 8*uint32(params.Threads) || params.Memory > maxDecodeMemory {
+		return 0, params, nil, nil, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil || len(salt) < MinSaltLen {
+		return 0, params, nil, nil, ErrInvalidHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return 0, params, nil, nil, ErrInvalidHash
+	}
+
+	params.SaltLen = uint32(len(salt))
+	params.KeyLen = uint32(len(key))
+	return mode, params, salt, key, nil
+}
+
+// modeName 返回变体名称
+func modeName(mode int) string {
+	switch mode {
+	case argon2d:
+		return "argon2d"
+	case argon2i:
+		return "argon2i"
+	}
+	return "argon2id"
+}
@@ -0,0 +1,185 @@
+package bcrypt
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+
+	"github.com/laenix/gsc/blowfish"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+const (
+	// 允许的最小代价因子
+	MinCost = 4
+	// 允许的最大代价因子
+	MaxCost = 31
+	// 默认代价因子
+	DefaultCost = 10
+	// 口令最大长度（字节），超出时返回ErrPasswordTooLong
+	MaxPasswordLength = 72
+)
+
+const (
+	// 盐长度（字节）
+	saltLen = 16
+	// 编码后的盐长度
+	encodedSaltLen = 22
+	// 哈希长度（字节），魔术串加密结果只保留前23字节
+	hashLen = 23
+	// 编码后的哈希长度
+	encodedHashLen = 31
+	// 完整编码字符串长度：$2b$10$ + 盐 + 哈希
+	encodedLen = 7 + encodedSaltLen + encodedHashLen
+)
+
+// magicText 是被反复加密的固定明文
+var magicText = []byte("OrpheanBeholderScryDoubt")
+
+// bcryptEncoding 是bcrypt使用的Base64字母表（无填充）
+var bcryptEncoding = base64.NewEncoding("./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789").WithPadding(base64.NoPadding)
+
+// 错误定义
+var (
+	ErrInvalidCost        = gscerr.New(gscerr.ErrParameter, "bcrypt: cost must be 4-31")
+	ErrInvalidHash        = gscerr.New(gscerr.ErrMalformed, "bcrypt: invalid encoded hash")
+	ErrUnsupportedVersion = gscerr.New(gscerr.ErrUnsupported, "bcrypt: unsupported version")
+	ErrMismatchedPassword = gscerr.New(gscerr.ErrVerification, "bcrypt: password does not match")
+	ErrPasswordTooLong    = gscerr.New(gscerr.ErrParameter, "bcrypt: password length exceeds 72 bytes")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidCost:        "bcrypt: 代价因子必须在4-31之间",
+		ErrInvalidHash:        "bcrypt: 编码格式无效",
+		ErrUnsupportedVersion: "bcrypt: 不支持的版本",
+		ErrMismatchedPassword: "bcrypt: 口令不匹配",
+		ErrPasswordTooLong:    "bcrypt: 口令长度超过72字节",
+	})
+}
+
+// GenerateFromPassword 使用随机盐计算口令的bcrypt哈希
+// 返回标准格式：$2b$<代价>$<22字符盐><31字符哈希>
+// 口令超过72字节时返回ErrPasswordTooLong，而不是静默截断使超出部分不起作用
+func GenerateFromPassword(password []byte, cost int) (string, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return generate(password, cost, salt, "2b")
+}
+
+// CompareHashAndPassword 比较bcrypt哈希与口令，匹配时返回nil
+// 支持$2a$、$2b$和$2y$前缀，口令超过72字节时返回ErrPasswordTooLong
+func CompareHashAndPassword(hashed string, password []byte) error {
+	version, cost, salt, sum, err := decode(hashed)
+	if err != nil {
+		return err
+	}
+
+	other, err := generate(password, cost, salt, version)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(other[len(other)-encodedHashLen:]), sum) != 1 {
+		return ErrMismatchedPassword
+	}
+	return nil
+}
+
+// Cost 返回bcrypt哈希使用的代价因子
+func Cost(hashed string) (int, error) {
+	_, cost, _, _, err := decode(hashed)
+	return cost, err
+}
+
+// generate 使用指定的盐计算bcrypt哈希并编码
+func generate(password []byte, cost int, salt []byte, version string) (string, error) {
+	if cost < MinCost || cost > MaxCost {
+		return "", ErrInvalidCost
+	}
+	if len(salt) != saltLen {
+		return "", ErrInvalidHash
+	}
+	if len(password) > MaxPasswordLength {
+		return "", ErrPasswordTooLong
+	}
+
+	// 口令以0结尾，恰为72字节时结尾的0被截掉
+	key := make([]byte, 0, len(password)+1)
+	key = append(key, password...)
+	key = append(key, 0)
+	if len(key) > MaxPasswordLength {
+		key = key[:MaxPasswordLength]
+	}
+
+	c, err := eksBlowfishSetup(key, salt, cost)
+	if err != nil {
+		return "", err
+	}
+
+	// 将魔术串以ECB方式加密64次
+	ctext := make([]byte, len(magicText))
+	copy(ctext, magicText)
+	for i := 0; i < 64; i++ {
+		for j := 0; j < len(ctext); j += blowfish.BlockSize {
+			out, err := c.Encrypt(ctext[j : j+blowfish.BlockSize])
+			if err != nil {
+				return "", err
+			}
+			copy(ctext[j:], out)
+		}
+	}
+
+	return fmt.Sprintf("$%s$%02d$%s%s", version, cost,
+		bcryptEncoding.EncodeToString(salt),
+		bcryptEncoding.EncodeToString(ctext[:hashLen])), nil
+}
+
+// eksBlowfishSetup 执行EksBlowfish的昂贵密钥编排
+func eksBlowfishSetup(key, salt []byte, cost int) (*blowfish.Blowfish, error) {
+	c, err := blowfish.NewSaltedCipher(key, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	rounds := uint64(1) << uint(cost)
+	for i := uint64(0); i < rounds; i++ {
+		blowfish.ExpandKey(key, c)
+		blowfish.ExpandKey(salt, c)
+	}
+	return c, nil
+}
+
+// decode 解析bcrypt编码字符串，返回版本、代价、盐和编码后的哈希
+func decode(hashed string) (string, int, []byte, []byte, error) {
+	if len(hashed) != encodedLen || hashed[0] != '$' || hashed[3] != '$' || hashed[6] != '$' {
+		return "", 0, nil, nil, ErrInvalidHash
+	}
+
+	version := hashed[1:3]
+	switch version {
+	case "2a", "2b", "2y":
+	default:
+		return "", 0, nil, nil, ErrUnsupportedVersion
+	}
+
+	cost, err := strconv.Atoi(hashed[4:6])
+	if err != nil {
+		return "", 0, nil, nil, ErrInvalidHash
+	}
+	if cost < MinCost || cost > MaxCost {
+		return "", 0, nil, nil, ErrInvalidCost
+	}
+
+	salt, err := bcryptEncoding.DecodeString(hashed[7 : 7+encodedSaltLen])
+	if err != nil {
+		return "", 0, nil, nil, ErrInvalidHash
+	}
+
+	return version, cost, salt, []byte(hashed[7+encodedSaltLen:]), nil
+}
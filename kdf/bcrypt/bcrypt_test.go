@@ -0,0 +1,74 @@
+package bcrypt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// 测试向量取自OpenBSD与Openwall crypt_blowfish的公开测试集
+func TestVectors(t *testing.T) {
+	tests := []struct {
+		password string
+		hash     string
+	}{
+		{"U*U", "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW"},
+		{"U*U*", "$2a$05$CCCCCCCCCCCCCCCCCCCCC.VGOzA784oUp/Z0DY336zx7pLYAy0lwK"},
+		{"", "$2a$06$DCq7YPn5Rq63x1Lad4cll.TV4S6ytwfsfvkgY8jIucDrjc8deX1s."},
+		{"abcdefghijklmnopqrstuvwxyz", "$2a$10$fVH8e28OQRj9tqiDXs1e1uxpsjN0c7II7YPKXua2NAKYvM6iQk7dq"},
+	}
+
+	for i, tt := range tests {
+		if err := CompareHashAndPassword(tt.hash, []byte(tt.password)); err != nil {
+			t.Errorf("测试 #%d: 期望匹配，实际: %v", i, err)
+		}
+		if err := CompareHashAndPassword(tt.hash, []byte(tt.password+"x")); !errors.Is(err, ErrMismatchedPassword) {
+			t.Errorf("测试 #%d: 错误口令期望ErrMismatchedPassword，实际: %v", i, err)
+		}
+	}
+}
+
+func TestGenerateAndCompare(t *testing.T) {
+	password := []byte("correct horse battery staple")
+	hashed, err := GenerateFromPassword(password, MinCost)
+	if err != nil {
+		t.Fatalf("生成哈希失败: %v", err)
+	}
+	if !strings.HasPrefix(hashed, "$2b$04$") || len(hashed) != encodedLen {
+		t.Fatalf("编码格式错误: %s", hashed)
+	}
+	if err := CompareHashAndPassword(hashed, password); err != nil {
+		t.Fatalf("口令校验失败: %v", err)
+	}
+	if cost, err := Cost(hashed); err != nil || cost != MinCost {
+		t.Fatalf("Cost返回错误: %d, %v", cost, err)
+	}
+
+	// 72字节的口令可用，超过72字节返回错误而不是静默截断
+	long := []byte(strings.Repeat("a", MaxPasswordLength+1))
+	hashed, err = GenerateFromPassword(long[:MaxPasswordLength], MinCost)
+	if err != nil {
+		t.Fatalf("生成哈希失败: %v", err)
+	}
+	if err := CompareHashAndPassword(hashed, long[:MaxPasswordLength]); err != nil {
+		t.Fatalf("72字节的口令应匹配: %v", err)
+	}
+	if _, err := GenerateFromPassword(long, MinCost); !errors.Is(err, ErrPasswordTooLong) {
+		t.Errorf("期望ErrPasswordTooLong，实际: %v", err)
+	}
+	if err := CompareHashAndPassword(hashed, long); !errors.Is(err, ErrPasswordTooLong) {
+		t.Errorf("期望ErrPasswordTooLong，实际: %v", err)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	if _, err := GenerateFromPassword([]byte("x"), 3); !errors.Is(err, ErrInvalidCost) {
+		t.Errorf("期望ErrInvalidCost，实际: %v", err)
+	}
+	if err := CompareHashAndPassword("$2a$05$short", nil); !errors.Is(err, ErrInvalidHash) {
+		t.Errorf("期望ErrInvalidHash，实际: %v", err)
+	}
+	if err := CompareHashAndPassword("$2x$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW", nil); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("期望ErrUnsupportedVersion，实际: %v", err)
+	}
+}
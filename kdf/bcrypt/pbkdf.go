@@ -0,0 +1,92 @@
+package bcrypt
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+
+	"github.com/laenix/gsc/blowfish"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+// ErrInvalidPBKDFParams 表示bcrypt_pbkdf的参数无效
+var ErrInvalidPBKDFParams = gscerr.New(gscerr.ErrParameter, "bcrypt: pbkdf requires non-empty password and salt, rounds >= 1 and key length 1-1024")
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidPBKDFParams: "bcrypt: pbkdf要求口令和盐非空、轮数至少为1、密钥长度在1-1024之间",
+	})
+}
+
+// pbkdfMagic 是bcrypt_hash中被反复加密的固定明文
+var pbkdfMagic = []byte("OxychromaticBlowfishSwatDynamite")
+
+// Key 使用OpenBSD的bcrypt_pbkdf从口令派生keyLen字节的密钥，用于OpenSSH私钥和signify私钥的加密
+// 与PBKDF2结构类似，但每轮的伪随机函数是以SHA-512预处理口令和盐的bcrypt_hash；
+// 输出的各字节在分块间交错排列，派生较长的密钥不能减少暴力破解的工作量
+func Key(password, salt []byte, rounds, keyLen int) ([]byte, error) {
+	if len(password) == 0 || len(salt) == 0 || rounds < 1 || keyLen < 1 || keyLen > 1024 {
+		return nil, ErrInvalidPBKDFParams
+	}
+
+	numBlocks := (keyLen + 31) / 32
+	key := make([]byte, numBlocks*32)
+	shapass := sha512.Sum512(password)
+	var counter [4]byte
+	for block := 1; block <= numBlocks; block++ {
+		binary.BigEndian.PutUint32(counter[:], uint32(block))
+		h := sha512.New()
+		h.Write(salt)
+		h.Write(counter[:])
+		shasalt := h.Sum(nil)
+
+		out, err := bcryptHash(shapass[:], shasalt)
+		if err != nil {
+			return nil, err
+		}
+		tmp := out
+		for i := 1; i < rounds; i++ {
+			sum := sha512.Sum512(tmp[:])
+			if tmp, err = bcryptHash(shapass[:], sum[:]); err != nil {
+				return nil, err
+			}
+			for j := range out {
+				out[j] ^= tmp[j]
+			}
+		}
+		for i, v := range out {
+			key[i*numBlocks+block-1] = v
+		}
+	}
+	return key[:keyLen], nil
+}
+
+// bcryptHash 是bcrypt_pbkdf的伪随机函数：以口令和盐做64轮EksBlowfish编排后加密固定明文，
+// 输出的每个32位字按小端序存储
+func bcryptHash(shapass, shasalt []byte) ([32]byte, error) {
+	var out [32]byte
+	c, err := blowfish.NewSaltedCipher(shapass, shasalt)
+	if err != nil {
+		return out, err
+	}
+	for i := 0; i < 64; i++ {
+		blowfish.ExpandKey(shasalt, c)
+		blowfish.ExpandKey(shapass, c)
+	}
+
+	copy(out[:], pbkdfMagic)
+	for i := 0; i < len(out); i += blowfish.BlockSize {
+		for j := 0; j < 64; j++ {
+			block, err := c.Encrypt(out[i : i+blowfish.BlockSize])
+			if err != nil {
+				return out, err
+			}
+			copy(out[i:], block)
+		}
+	}
+	for i := 0; i < len(out); i += 4 {
+		binary.LittleEndian.PutUint32(out[i:], binary.BigEndian.Uint32(out[i:]))
+	}
+	return out, nil
+}
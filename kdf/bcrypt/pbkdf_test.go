@@ -0,0 +1,55 @@
+package bcrypt
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// 测试向量取自OpenBSD的bcrypt_pbkdf回归测试
+func TestPBKDFVectors(t *testing.T) {
+	tests := []struct {
+		password, salt string
+		rounds         int
+		key            string
+	}{
+		{"password", "salt", 4, "5bbf0cc293587f1c3635555c27796598d47e579071bf427e9d8fbe842aba34d9"},
+	}
+	for i, tt := range tests {
+		key, err := Key([]byte(tt.password), []byte(tt.salt), tt.rounds, len(tt.key)/2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(key); got != tt.key {
+			t.Errorf("测试 #%d: 得到 %s", i, got)
+		}
+	}
+}
+
+// 测试分块交错：较短的输出不是较长输出的前缀
+func TestPBKDFInterleave(t *testing.T) {
+	short, _ := Key([]byte("password"), []byte("salt"), 2, 32)
+	long, _ := Key([]byte("password"), []byte("salt"), 2, 64)
+	for i := range short {
+		if short[i] != long[2*i] {
+			t.Fatalf("第%d字节应等于长输出的第%d字节", i, 2*i)
+		}
+	}
+}
+
+func TestPBKDFParams(t *testing.T) {
+	for _, tt := range []struct {
+		password, salt string
+		rounds, keyLen int
+	}{
+		{"", "salt", 1, 32},
+		{"pw", "", 1, 32},
+		{"pw", "salt", 0, 32},
+		{"pw", "salt", 1, 0},
+		{"pw", "salt", 1, 1025},
+	} {
+		if _, err := Key([]byte(tt.password), []byte(tt.salt), tt.rounds, tt.keyLen); !errors.Is(err, ErrInvalidPBKDFParams) {
+			t.Errorf("%+v: %v", tt, err)
+		}
+	}
+}
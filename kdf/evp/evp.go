@@ -0,0 +1,72 @@
+package evp
+
+import (
+	"crypto/md5"
+	"hash"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+// SaltSize 是OpenSSL使用的盐长度（字节）
+const SaltSize = 8
+
+// 错误定义
+var (
+	ErrInvalidSalt       = gscerr.New(gscerr.ErrParameter, "evp: salt must be empty or 8 bytes")
+	ErrInvalidIterations = gscerr.New(gscerr.ErrParameter, "evp: iterations must be greater than 0")
+	ErrInvalidLength     = gscerr.New(gscerr.ErrParameter, "evp: key and IV lengths must not be negative")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidSalt:       "evp: 盐必须为空或8字节",
+		ErrInvalidIterations: "evp: 迭代次数必须大于0",
+		ErrInvalidLength:     "evp: 密钥和IV长度不能为负数",
+	})
+}
+
+// BytesToKey 实现OpenSSL的EVP_BytesToKey，从口令派生密钥和IV
+// h为nil时使用MD5（openssl enc在1.1.0之前的默认摘要）
+//
+//	D_1 = H^count(password || salt)
+//	D_i = H^count(D_{i-1} || password || salt)
+//
+// 依次拼接D_i，前keyLen字节为密钥，随后ivLen字节为IV。
+// 该算法没有可调的内存代价且迭代次数通常为1，只应用于与现有OpenSSL密文互通，
+// 新数据请使用pbkdf2、scrypt或argon2
+func BytesToKey(h func() hash.Hash, password, salt []byte, iterations, keyLen, ivLen int) ([]byte, []byte, error) {
+	if len(salt) != 0 && len(salt) != SaltSize {
+		return nil, nil, ErrInvalidSalt
+	}
+	if iterations < 1 {
+		return nil, nil, ErrInvalidIterations
+	}
+	if keyLen < 0 || ivLen < 0 {
+		return nil, nil, ErrInvalidLength
+	}
+	if h == nil {
+		h = md5.New
+	}
+
+	d := h()
+	out := make([]byte, 0, keyLen+ivLen+d.Size())
+	var prev []byte
+	for len(out) < keyLen+ivLen {
+		d.Reset()
+		d.Write(prev)
+		d.Write(password)
+		d.Write(salt)
+		prev = d.Sum(nil)
+
+		for i := 1; i < iterations; i++ {
+			d.Reset()
+			d.Write(prev)
+			prev = d.Sum(nil)
+		}
+		out = append(out, prev...)
+	}
+
+	return out[:keyLen], out[keyLen : keyLen+ivLen], nil
+}
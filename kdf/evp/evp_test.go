@@ -0,0 +1,87 @@
+package evp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/laenix/gsc/aes"
+	"github.com/laenix/gsc/modes"
+	"github.com/laenix/gsc/padding"
+)
+
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("无效的十六进制字符串 %q: %v", s, err)
+	}
+	return b
+}
+
+// 测试向量由 openssl enc -p -pass pass:secret -S 0102030405060708 生成
+func TestBytesToKey(t *testing.T) {
+	salt := decodeHex(t, "0102030405060708")
+
+	key, iv, err := BytesToKey(nil, []byte("secret"), salt, 1, 32, 16)
+	if err != nil {
+		t.Fatalf("派生失败: %v", err)
+	}
+	if hex.EncodeToString(key) != "c9e5a1bd216dbe1317e230cef48f38ee7f0e17ad64022144bccec4a1aa2879ab" {
+		t.Errorf("MD5密钥不匹配: %x", key)
+	}
+	if hex.EncodeToString(iv) != "e24b32bbbc4ef02ecbcb6576523ad893" {
+		t.Errorf("MD5 IV不匹配: %x", iv)
+	}
+
+	key, iv, err = BytesToKey(sha256.New, []byte("secret"), salt, 1, 16, 16)
+	if err != nil {
+		t.Fatalf("派生失败: %v", err)
+	}
+	if hex.EncodeToString(key) != "03b375940cb96c16f84faa87f5ef39cc" {
+		t.Errorf("SHA256密钥不匹配: %x", key)
+	}
+	if hex.EncodeToString(iv) != "0bc7066ccd3e14456d9d74e438e35832" {
+		t.Errorf("SHA256 IV不匹配: %x", iv)
+	}
+}
+
+// 测试仅凭口令解密openssl enc -aes-256-cbc -md md5 生成的密文
+func TestDecryptOpenSSLCiphertext(t *testing.T) {
+	salt := decodeHex(t, "0102030405060708")
+	ciphertext := decodeHex(t, "78d445eb0662231a96a9eb73363b667a631a3b013be3e73e41169ee0f05e432f")
+
+	key, iv, err := BytesToKey(nil, []byte("secret"), salt, 1, 32, 16)
+	if err != nil {
+		t.Fatalf("派生失败: %v", err)
+	}
+	block, err := aes.New(key)
+	if err != nil {
+		t.Fatalf("创建AES实例失败: %v", err)
+	}
+	cbc, err := modes.NewCBC(block, iv)
+	if err != nil {
+		t.Fatalf("创建CBC失败: %v", err)
+	}
+	padded, err := cbc.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatalf("解密失败: %v", err)
+	}
+	plaintext, err := padding.PKCS7UnPadding(padded)
+	if err != nil {
+		t.Fatalf("去除填充失败: %v", err)
+	}
+	if !bytes.Equal(plaintext, []byte("hello openssl interop")) {
+		t.Fatalf("明文不匹配: %q", plaintext)
+	}
+}
+
+func TestInvalidParams(t *testing.T) {
+	if _, _, err := BytesToKey(nil, nil, []byte("short"), 1, 16, 16); err != ErrInvalidSalt {
+		t.Errorf("期望ErrInvalidSalt，实际: %v", err)
+	}
+	if _, _, err := BytesToKey(nil, nil, nil, 0, 16, 16); err != ErrInvalidIterations {
+		t.Errorf("期望ErrInvalidIterations，实际: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package hkdf
+
+import (
+	"crypto/hmac"
+	"hash"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+// 错误定义
+var (
+	ErrInvalidLength = gscerr.New(gscerr.ErrParameter, "hkdf: output length must not exceed 255 times the hash size")
+	ErrInvalidPRK    = gscerr.New(gscerr.ErrParameter, "hkdf: pseudorandom key must be at least the hash size")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidLength: "hkdf: 输出长度不能超过255倍哈希长度",
+		ErrInvalidPRK:    "hkdf: 伪随机密钥长度不能小于哈希长度",
+	})
+}
+
+// Extract 执行HKDF的提取阶段 PRK = HMAC-Hash(salt, IKM)
+// salt为空时使用长度等于哈希输出长度的全零串
+func Extract(h func() hash.Hash, secret, salt []byte) []byte {
+	if len(salt) == 0 {
+		salt = make([]byte, h().Size())
+	}
+	mac := hmac.New(h, salt)
+	mac.Write(secret)
+	return mac.Sum(nil)
+}
+
+// Expand 执行HKDF的扩展阶段，根据PRK和info生成length字节的输出密钥材料
+// T(0) = 空串，T(i) = HMAC-Hash(PRK, T(i-1) || info || i)
+func Expand(h func() hash.Hash, prk, info []byte, length int) ([]byte, error) {
+	hashLen := h().Size()
+	if len(prk) < hashLen {
+		return nil, ErrInvalidPRK
+	}
+	if length < 0 || length > 255*hashLen {
+		return nil, ErrInvalidLength
+	}
+
+	mac := hmac.New(h, prk)
+	okm := make([]byte, 0, length+hashLen)
+	var t []byte
+	for counter := byte(1); len(okm) < length; counter++ {
+		mac.Reset()
+		mac.Write(t)
+		mac.Write(info)
+		mac.Write([]byte{counter})
+		t = mac.Sum(t[:0])
+		okm = append(okm, t...)
+	}
+
+	return okm[:length], nil
+}
+
+// Key 依次执行提取和扩展，直接从输入密钥材料派生length字节的密钥
+func Key(h func() hash.Hash, secret, salt, info []byte, length int) ([]byte, error) {
+	prk := Extract(h, secret, salt)
+	return Expand(h, prk, info, length)
+}
@@ -0,0 +1,89 @@
+package hkdf
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/laenix/gsc/sm3"
+)
+
+type hkdfTest struct {
+	ikm  string
+	salt string
+	info string
+	prk  string
+	okm  string
+}
+
+// RFC 5869 附录A中基于SHA-256的测试向量
+var golden = []hkdfTest{
+	// A.1 基本用例
+	{
+		"0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b",
+		"000102030405060708090a0b0c",
+		"f0f1f2f3f4f5f6f7f8f9",
+		"077709362c2e32df0ddc3f0dc47bba6390b6c73bb50f9c3122ec844ad7c2b3e5",
+		"3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865",
+	},
+	// A.3 salt和info为空
+	{
+		"0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b",
+		"",
+		"",
+		"19ef24a32c717b167f33a91d6f648bdf96596776afdb6377ac434c1c293ccb04",
+		"8da4e775a563c18f715f802a063c5a31b8a11f5c5ee1879ec3454e5f3c738d2d9d201395faa4b61a96c8",
+	},
+}
+
+// 测试RFC 5869标准向量
+func TestVectors(t *testing.T) {
+	for i, test := range golden {
+		ikm, _ := hex.DecodeString(test.ikm)
+		salt, _ := hex.DecodeString(test.salt)
+		info, _ := hex.DecodeString(test.info)
+		expectedPRK, _ := hex.DecodeString(test.prk)
+		expectedOKM, _ := hex.DecodeString(test.okm)
+
+		prk := Extract(sha256.New, ikm, salt)
+		if !bytes.Equal(prk, expectedPRK) {
+			t.Errorf("测试 #%d: PRK不匹配\n期望值: %x\n实际值: %x", i, expectedPRK, prk)
+		}
+
+		okm, err := Key(sha256.New, ikm, salt, info, len(expectedOKM))
+		if err != nil {
+			t.Fatalf("测试 #%d: 派生失败: %v", i, err)
+		}
+		if !bytes.Equal(okm, expectedOKM) {
+			t.Errorf("测试 #%d: OKM不匹配\n期望值: %x\n实际值: %x", i, expectedOKM, okm)
+		}
+	}
+}
+
+// 测试基于SM3的派生以及前缀一致性
+func TestSM3(t *testing.T) {
+	secret := []byte("shared secret from SM2 key exchange")
+	long, err := Key(sm3.New, secret, nil, []byte("enc+mac"), 64)
+	if err != nil {
+		t.Fatalf("派生失败: %v", err)
+	}
+	short, err := Key(sm3.New, secret, nil, []byte("enc+mac"), 16)
+	if err != nil {
+		t.Fatalf("派生失败: %v", err)
+	}
+	if !bytes.Equal(long[:16], short) {
+		t.Error("不同长度的输出应具有相同前缀")
+	}
+}
+
+// 测试输出长度上限
+func TestInvalidLength(t *testing.T) {
+	prk := Extract(sm3.New, []byte("secret"), nil)
+	if _, err := Expand(sm3.New, prk, nil, 255*sm3.Size+1); err != ErrInvalidLength {
+		t.Errorf("期望ErrInvalidLength，实际: %v", err)
+	}
+	if _, err := Expand(sm3.New, prk[:8], nil, 16); err != ErrInvalidPRK {
+		t.Errorf("期望ErrInvalidPRK，实际: %v", err)
+	}
+}
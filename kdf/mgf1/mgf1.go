@@ -0,0 +1,64 @@
+// Package mgf1 实现MGF1掩码生成函数（RFC 8017附录B.2.1）
+//
+// MGF1(seed) = Hash(seed || 0) || Hash(seed || 1) || ...，计数器从0开始，以32位大端编码。
+// RSA的OAEP和PSS用它生成掩码，一些HSM协议也把它当作简单的密钥派生函数使用；
+// 与计数器从1开始的ANSI X9.63 KDF（见sm3kdf.NewWithHash）只差计数器的起始值
+package mgf1
+
+import (
+	"hash"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+// 错误定义
+var (
+	ErrMaskTooLong = gscerr.New(gscerr.ErrParameter, "mgf1: mask length must not exceed 2^32 times the hash size")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrMaskTooLong: "mgf1: 掩码长度不能超过2^32倍哈希长度",
+	})
+}
+
+// Mask 返回length字节的MGF1(seed)
+func Mask(h func() hash.Hash, seed []byte, length int) ([]byte, error) {
+	if length < 0 || uint64(length) > (1<<32)*uint64(h().Size()) {
+		return nil, ErrMaskTooLong
+	}
+	out := make([]byte, length)
+	XOR(h, out, seed)
+	return out, nil
+}
+
+// XOR 将MGF1(seed)异或到dst上，OAEP和PSS中的掩码运算可以原地完成
+// dst超过2^32个哈希输出长度时panic
+func XOR(h func() hash.Hash, dst, seed []byte) {
+	d := h()
+	var counter [4]byte
+	var digest []byte
+	for n := 0; len(dst) > 0; n++ {
+		if n > 0 && counter == [4]byte{} {
+			panic("mgf1: mask too long")
+		}
+		d.Reset()
+		d.Write(seed)
+		d.Write(counter[:])
+		digest = d.Sum(digest[:0])
+		k := min(len(digest), len(dst))
+		for i := range k {
+			dst[i] ^= digest[i]
+		}
+		dst = dst[k:]
+		// 计数器按大端序递增
+		for i := len(counter) - 1; i >= 0; i-- {
+			counter[i]++
+			if counter[i] != 0 {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package mgf1
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"math/bits"
+	"testing"
+
+	"github.com/laenix/gsc/sm3"
+)
+
+func TestMask(t *testing.T) {
+	for _, tt := range []struct {
+		h      func() hash.Hash
+		seed   string
+		length int
+		want   string
+	}{
+		{sha1.New, "foo", 3, "1ac907"},
+		{sha1.New, "foo", 5, "1ac9075cd4"},
+		{sha1.New, "bar", 5, "bc0c655e01"},
+		{sha1.New, "bar", 50, "bc0c655e016bc2931d85a2e675181adcef7f581f76df2739da74faac41627be2f7f415c89e983fd0ce80ced9878641cb4876"},
+		{sha256.New, "bar", 50, "382576a7841021cc28fc4c0948753fb8312090cea942ea4c4e735d10dc724b155f9f6069f289d61daca0cb814502ef04eae1"},
+		{sha256.New, "bar", 0, ""},
+	} {
+		got, err := Mask(tt.h, []byte(tt.seed), tt.length)
+		if err != nil || hex.EncodeToString(got) != tt.want {
+			t.Errorf("MGF1(%q, %d) = %x, %v", tt.seed, tt.length, got, err)
+		}
+	}
+}
+
+func TestXOR(t *testing.T) {
+	seed := []byte("seed")
+	mask, _ := Mask(sm3.New, seed, 100)
+	dst := bytes.Repeat([]byte{0x5a}, 100)
+	XOR(sm3.New, dst, seed)
+	for i := range dst {
+		if dst[i] != mask[i]^0x5a {
+			t.Fatalf("第%d字节不符", i)
+		}
+	}
+	// 再异或一次恢复原值
+	XOR(sm3.New, dst, seed)
+	if !bytes.Equal(dst, bytes.Repeat([]byte{0x5a}, 100)) {
+		t.Error("两次异或后未恢复")
+	}
+}
+
+func TestMaskTooLong(t *testing.T) {
+	if _, err := Mask(sha256.New, nil, -1); !errors.Is(err, ErrMaskTooLong) {
+		t.Errorf("负数长度: %v", err)
+	}
+	if bits.UintSize == 64 {
+		if _, err := Mask(sha256.New, nil, int(^uint(0)>>1)); !errors.Is(err, ErrMaskTooLong) {
+			t.Errorf("超长: %v", err)
+		}
+	}
+}
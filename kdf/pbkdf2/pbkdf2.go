@@ -0,0 +1,68 @@
+package pbkdf2
+
+import (
+	"crypto/hmac"
+	"encoding/binary"
+	"hash"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+// 错误定义
+var (
+	ErrInvalidIterations = gscerr.New(gscerr.ErrParameter, "pbkdf2: iterations must be greater than 0")
+	ErrInvalidKeyLength  = gscerr.New(gscerr.ErrParameter, "pbkdf2: key length must be greater than 0")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidIterations: "pbkdf2: 迭代次数必须大于0",
+		ErrInvalidKeyLength:  "pbkdf2: 密钥长度必须大于0",
+	})
+}
+
+// Key 使用PBKDF2（RFC 8018）从口令派生keyLen字节的密钥
+// 伪随机函数为基于h的HMAC，可以是SHA-2系列或SM3
+//
+//	DK = T1 || T2 || ... ，Ti = U1 ^ U2 ^ ... ^ Uc
+//	U1 = PRF(P, S || INT(i))，Uj = PRF(P, Uj-1)
+func Key(h func() hash.Hash, password, salt []byte, iterations, keyLen int) ([]byte, error) {
+	if iterations < 1 {
+		return nil, ErrInvalidIterations
+	}
+	if keyLen < 1 {
+		return nil, ErrInvalidKeyLength
+	}
+
+	prf := hmac.New(h, password)
+	hashLen := prf.Size()
+	numBlocks := (keyLen + hashLen - 1) / hashLen
+
+	var counter [4]byte
+	dk := make([]byte, 0, numBlocks*hashLen)
+	u := make([]byte, hashLen)
+	for block := 1; block <= numBlocks; block++ {
+		// U1 = PRF(P, S || INT(i))
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(counter[:], uint32(block))
+		prf.Write(counter[:])
+		dk = prf.Sum(dk)
+		t := dk[len(dk)-hashLen:]
+		copy(u, t)
+
+		// Uj = PRF(P, Uj-1)，累加异或到Ti
+		for n := 2; n <= iterations; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for i := range u {
+				t[i] ^= u[i]
+			}
+		}
+	}
+
+	return dk[:keyLen], nil
+}
@@ -0,0 +1,73 @@
+package pbkdf2
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"testing"
+
+	"github.com/laenix/gsc/sm3"
+)
+
+type pbkdf2Test struct {
+	hash       func() hash.Hash
+	password   string
+	salt       string
+	iterations int
+	out        string
+}
+
+// RFC 6070（HMAC-SHA1）以及常用的HMAC-SHA256测试向量
+var golden = []pbkdf2Test{
+	{sha1.New, "password", "salt", 1, "0c60c80f961f0e71f3a9b524af6012062fe037a6"},
+	{sha1.New, "password", "salt", 2, "ea6c014dc72d6f8ccd1ed92ace1d41f0d8de8957"},
+	{sha1.New, "password", "salt", 4096, "4b007901b765489abead49d926f721d065a429c1"},
+	{sha1.New, "passwordPASSWORDpassword", "saltSALTsaltSALTsaltSALTsaltSALTsalt", 4096, "3d2eec4fe41c849b80c8d83662c0e44a8b291a964cf2f07038"},
+	{sha256.New, "password", "salt", 1, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+	{sha256.New, "password", "salt", 4096, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+}
+
+// 测试标准向量
+func TestVectors(t *testing.T) {
+	for i, test := range golden {
+		expected, _ := hex.DecodeString(test.out)
+		dk, err := Key(test.hash, []byte(test.password), []byte(test.salt), test.iterations, len(expected))
+		if err != nil {
+			t.Fatalf("测试 #%d: 派生失败: %v", i, err)
+		}
+		if !bytes.Equal(dk, expected) {
+			t.Errorf("测试 #%d: 结果不匹配\n期望值: %x\n实际值: %x", i, expected, dk)
+		}
+	}
+}
+
+// 测试SM3派生出的SM4/AES密钥长度
+func TestSM3KeyLengths(t *testing.T) {
+	for _, keyLen := range []int{16, 24, 32, 48} {
+		dk, err := Key(sm3.New, []byte("口令"), []byte("salt"), 1000, keyLen)
+		if err != nil {
+			t.Fatalf("派生%d字节密钥失败: %v", keyLen, err)
+		}
+		if len(dk) != keyLen {
+			t.Errorf("期望%d字节，实际%d字节", keyLen, len(dk))
+		}
+	}
+}
+
+// 测试无效参数
+func TestInvalidParams(t *testing.T) {
+	if _, err := Key(sm3.New, []byte("p"), []byte("s"), 0, 16); err != ErrInvalidIterations {
+		t.Errorf("期望ErrInvalidIterations，实际: %v", err)
+	}
+	if _, err := Key(sm3.New, []byte("p"), []byte("s"), 1, 0); err != ErrInvalidKeyLength {
+		t.Errorf("期望ErrInvalidKeyLength，实际: %v", err)
+	}
+}
+
+func BenchmarkSM3(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Key(sm3.New, []byte("password"), []byte("salt"), 1000, 32)
+	}
+}
@@ -0,0 +1,178 @@
+package scrypt
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/kdf/pbkdf2"
+)
+
+// 错误定义
+var (
+	ErrInvalidN      = gscerr.New(gscerr.ErrParameter, "scrypt: N must be a power of 2 greater than 1")
+	ErrInvalidParams = gscerr.New(gscerr.ErrParameter, "scrypt: r and p must be positive and r*p < 2^30")
+	ErrTooLarge      = gscerr.New(gscerr.ErrParameter, "scrypt: parameters too large, memory limit exceeded")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidN:      "scrypt: N必须是大于1的2的幂",
+		ErrInvalidParams: "scrypt: 参数r、p必须大于0且r*p < 2^30",
+		ErrTooLarge:      "scrypt: 参数过大，所需内存超出限制",
+	})
+}
+
+// Key 使用scrypt（RFC 7914）从口令派生keyLen字节的密钥
+// N为CPU/内存开销参数，r为块大小参数，p为并行参数
+// 需要的内存约为 128*r*N 字节，推荐交互式登录使用 N=32768, r=8, p=1
+// keyLen取16/24/32分别对应AES-128/192/256，SM4使用16
+func Key(password, salt []byte, N, r, p, keyLen int) ([]byte, error) {
+	if N <= 1 || N&(N-1) != 0 {
+		return nil, ErrInvalidN
+	}
+	if r <= 0 || p <= 0 || uint64(r)*uint64(p) >= 1<<30 {
+		return nil, ErrInvalidParams
+	}
+	if r > maxInt/128/p || r > maxInt/256 || N > maxInt/128/r {
+		return nil, ErrTooLarge
+	}
+
+	// 1. B = PBKDF2-HMAC-SHA256(P, S, 1, p*128*r)
+	b, err := pbkdf2.Key(sha256.New, password, salt, 1, p*128*r)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 对每个128*r字节的块执行ROMix
+	xy := make([]uint32, 64*r)
+	v := make([]uint32, 32*N*r)
+	for i := 0; i < p; i++ {
+		roMix(b[i*128*r:], r, N, v, xy)
+	}
+
+	// 3. DK = PBKDF2-HMAC-SHA256(P, B, 1, dkLen)
+	return pbkdf2.Key(sha256.New, password, b, 1, keyLen)
+}
+
+const maxInt = int(^uint(0) >> 1)
+
+// roMix 实现scryptROMix，b为128*r字节，v为N个块的暂存区，xy为两个块的工作区
+func roMix(b []byte, r, N int, v, xy []uint32) {
+	x := xy[:32*r]
+	y := xy[32*r:]
+
+	for i := range x {
+		x[i] = binary.LittleEndian.Uint32(b[i*4:])
+	}
+
+	// 顺序填充V，V[i] = X，X = BlockMix(X)
+	for i := 0; i < N; i += 2 {
+		copy(v[i*32*r:], x)
+		blockMix(x, y, r)
+		copy(v[(i+1)*32*r:], y)
+		blockMix(y, x, r)
+	}
+
+	// 伪随机访问V，X = BlockMix(X ^ V[Integerify(X) mod N])
+	for i := 0; i < N; i += 2 {
+		j := int(integerify(x, r) & uint64(N-1))
+		xorBlock(x, v[j*32*r:])
+		blockMix(x, y, r)
+
+		j = int(integerify(y, r) & uint64(N-1))
+		xorBlock(y, v[j*32*r:])
+		blockMix(y, x, r)
+	}
+
+	for i, w := range x {
+		binary.LittleEndian.PutUint32(b[i*4:], w)
+	}
+}
+
+// integerify 取最后一个64字节子块的前8字节作为小端整数
+func integerify(x []uint32, r int) uint64 {
+	j := (2*r - 1) * 16
+	return uint64(x[j]) | uint64(x[j+1])<<32
+}
+
+// xorBlock dst ^= src
+func xorBlock(dst, src []uint32) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
+
+// blockMix 实现scryptBlockMix，将in的2r个64字节子块混合后写入out
+// 输出顺序为偶数下标子块在前，奇数下标子块在后
+func blockMix(in, out []uint32, r int) {
+	var x [16]uint32
+	copy(x[:], in[(2*r-1)*16:])
+
+	for i := 0; i < 2*r; i += 2 {
+		xorSalsa8(&x, in[i*16:])
+		copy(out[i*8:], x[:])
+
+		xorSalsa8(&x, in[(i+1)*16:])
+		copy(out[i*8+r*16:], x[:])
+	}
+}
+
+// xorSalsa8 计算 x = Salsa20/8(x ^ in)
+func xorSalsa8(x *[16]uint32, in []uint32) {
+	for i := range x {
+		x[i] ^= in[i]
+	}
+
+	w := *x
+	for i := 0; i < 8; i += 2 {
+		// 列变换
+		w[4] ^= bits.RotateLeft32(w[0]+w[12], 7)
+		w[8] ^= bits.RotateLeft32(w[4]+w[0], 9)
+		w[12] ^= bits.RotateLeft32(w[8]+w[4], 13)
+		w[0] ^= bits.RotateLeft32(w[12]+w[8], 18)
+
+		w[9] ^= bits.RotateLeft32(w[5]+w[1], 7)
+		w[13] ^= bits.RotateLeft32(w[9]+w[5], 9)
+		w[1] ^= bits.RotateLeft32(w[13]+w[9], 13)
+		w[5] ^= bits.RotateLeft32(w[1]+w[13], 18)
+
+		w[14] ^= bits.RotateLeft32(w[10]+w[6], 7)
+		w[2] ^= bits.RotateLeft32(w[14]+w[10], 9)
+		w[6] ^= bits.RotateLeft32(w[2]+w[14], 13)
+		w[10] ^= bits.RotateLeft32(w[6]+w[2], 18)
+
+		w[3] ^= bits.RotateLeft32(w[15]+w[11], 7)
+		w[7] ^= bits.RotateLeft32(w[3]+w[15], 9)
+		w[11] ^= bits.RotateLeft32(w[7]+w[3], 13)
+		w[15] ^= bits.RotateLeft32(w[11]+w[7], 18)
+
+		// 行变换
+		w[1] ^= bits.RotateLeft32(w[0]+w[3], 7)
+		w[2] ^= bits.RotateLeft32(w[1]+w[0], 9)
+		w[3] ^= bits.RotateLeft32(w[2]+w[1], 13)
+		w[0] ^= bits.RotateLeft32(w[3]+w[2], 18)
+
+		w[6] ^= bits.RotateLeft32(w[5]+w[4], 7)
+		w[7] ^= bits.RotateLeft32(w[6]+w[5], 9)
+		w[4] ^= bits.RotateLeft32(w[7]+w[6], 13)
+		w[5] ^= bits.RotateLeft32(w[4]+w[7], 18)
+
+		w[11] ^= bits.RotateLeft32(w[10]+w[9], 7)
+		w[8] ^= bits.RotateLeft32(w[11]+w[10], 9)
+		w[9] ^= bits.RotateLeft32(w[8]+w[11], 13)
+		w[10] ^= bits.RotateLeft32(w[9]+w[8], 18)
+
+		w[12] ^= bits.RotateLeft32(w[15]+w[14], 7)
+		w[13] ^= bits.RotateLeft32(w[12]+w[15], 9)
+		w[14] ^= bits.RotateLeft32(w[13]+w[12], 13)
+		w[15] ^= bits.RotateLeft32(w[14]+w[13], 18)
+	}
+
+	for i := range x {
+		x[i] += w[i]
+	}
+}
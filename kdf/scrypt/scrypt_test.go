@@ -0,0 +1,52 @@
+package scrypt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+type scryptTest struct {
+	password string
+	salt     string
+	N, r, p  int
+	out      string
+}
+
+// RFC 7914 第12节测试向量
+var golden = []scryptTest{
+	{"", "", 16, 1, 1, "77d6576238657b203b19ca42c18a0497f16b4844e3074ae8dfdffa3fede21442fcd0069ded0948f8326a753a0fc81f17e8d3e0fb2e0d3628cf35e20c38d18906"},
+	{"password", "NaCl", 1024, 8, 16, "fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b3731622eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640"},
+}
+
+// 测试标准向量
+func TestVectors(t *testing.T) {
+	for i, test := range golden {
+		expected, _ := hex.DecodeString(test.out)
+		dk, err := Key([]byte(test.password), []byte(test.salt), test.N, test.r, test.p, len(expected))
+		if err != nil {
+			t.Fatalf("测试 #%d: 派生失败: %v", i, err)
+		}
+		if !bytes.Equal(dk, expected) {
+			t.Errorf("测试 #%d: 结果不匹配\n期望值: %x\n实际值: %x", i, expected, dk)
+		}
+	}
+}
+
+// 测试无效参数
+func TestInvalidParams(t *testing.T) {
+	cases := []struct {
+		N, r, p int
+		err     error
+	}{
+		{0, 8, 1, ErrInvalidN},
+		{1000, 8, 1, ErrInvalidN},
+		{1024, 0, 1, ErrInvalidParams},
+		{1024, 8, 0, ErrInvalidParams},
+	}
+	for i, c := range cases {
+		if _, err := Key([]byte("p"), []byte("s"), c.N, c.r, c.p, 16); err != c.err {
+			t.Errorf("测试 #%d: 期望 %v，实际 %v", i, c.err, err)
+		}
+	}
+}
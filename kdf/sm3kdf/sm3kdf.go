@@ -0,0 +1,87 @@
+package sm3kdf
+
+import (
+	"encoding/binary"
+	"hash"
+	"io"
+
+	"github.com/laenix/gsc/hashutil"
+	"github.com/laenix/gsc/sm3"
+)
+
+// maxCounter 为32位计数器的最大值，输出总长度不超过 maxCounter*哈希长度
+const maxCounter = 1<<32 - 1
+
+// reader 以计数器模式生成任意长度的密钥材料
+// K = Hash(Z || ct) || Hash(Z || ct+1) || ...，ct从1开始，以32位大端编码
+type reader struct {
+	h       hash.Hash
+	seed    []byte
+	counter uint64
+	buf     []byte // 当前块中尚未输出的字节
+}
+
+// New 创建基于SM3的密钥派生流（GB/T 32918.4 中的KDF）
+// 返回的io.Reader可以多次读取，连续读取的结果等同于一次读取更长的输出
+func New(seed []byte) io.Reader {
+	return NewWithHash(sm3.New, seed)
+}
+
+// NewWithHash 使用任意哈希函数创建计数器模式的密钥派生流
+// 该结构与ANSI X9.63 KDF相同，可用于ECIES等方案
+func NewWithHash(h func() hash.Hash, seed []byte) io.Reader {
+	seedCopy := make([]byte, len(seed))
+	copy(seedCopy, seed)
+	return &reader{
+		h:       h(),
+		seed:    seedCopy,
+		counter: 1,
+	}
+}
+
+// NewWithDomain 创建带域分离的计数器模式密钥派生流：
+// K = Hash(ENTL || tag || Z || ct) || ...，前缀由hashutil.Domain在每块计算前写入。
+// 不同用途使用不同tag，即使seed相同也得到互不相关的输出；
+// 输出与GB/T 32918.4不兼容，只用于自定义协议
+func NewWithDomain(tag string, h func() hash.Hash, seed []byte) io.Reader {
+	seedCopy := make([]byte, len(seed))
+	copy(seedCopy, seed)
+	return &reader{
+		h:       hashutil.Domain(tag, h()),
+		seed:    seedCopy,
+		counter: 1,
+	}
+}
+
+// Read 读取派生出的密钥材料，超过计数器上限后返回io.EOF
+func (r *reader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(r.buf) == 0 {
+			if r.counter > maxCounter {
+				if n == 0 {
+					return 0, io.EOF
+				}
+				break
+			}
+			var ct [4]byte
+			binary.BigEndian.PutUint32(ct[:], uint32(r.counter))
+			r.h.Reset()
+			r.h.Write(r.seed)
+			r.h.Write(ct[:])
+			r.buf = r.h.Sum(r.buf[:0])
+			r.counter++
+		}
+		k := copy(p[n:], r.buf)
+		r.buf = r.buf[k:]
+		n += k
+	}
+	return n, nil
+}
+
+// Key 从seed派生length字节的密钥
+func Key(seed []byte, length int) []byte {
+	out := make([]byte, length)
+	io.ReadFull(New(seed), out)
+	return out
+}
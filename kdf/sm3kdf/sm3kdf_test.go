@@ -0,0 +1,81 @@
+package sm3kdf
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"testing"
+)
+
+// 测试向量（与OpenSSL X963KDF使用SM3时的输出一致）
+const (
+	testSeed = "0102030405060708090a0b0c0d0e0f"
+	testOut  = "708196289eac078d9bfe5c11c8b8307205c06d51da45c46abdb57bec7f693e5470d702094b9064b5cd111c3ff3efba5e7bf112c26aa718d41860165d6589550e1a7bd4694e835d36023f67fe3e336149"
+)
+
+// 测试Key函数
+func TestKey(t *testing.T) {
+	seed, _ := hex.DecodeString(testSeed)
+	expected, _ := hex.DecodeString(testOut)
+	if got := Key(seed, len(expected)); !bytes.Equal(got, expected) {
+		t.Errorf("结果不匹配\n期望值: %x\n实际值: %x", expected, got)
+	}
+}
+
+// 测试多次读取与一次读取结果一致
+func TestStreaming(t *testing.T) {
+	seed, _ := hex.DecodeString(testSeed)
+	expected, _ := hex.DecodeString(testOut)
+
+	r := New(seed)
+	var got []byte
+	for _, n := range []int{1, 7, 31, 2, 39} {
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			t.Fatalf("读取失败: %v", err)
+		}
+		got = append(got, buf...)
+	}
+	if !bytes.Equal(got, expected) {
+		t.Errorf("分段读取结果不匹配\n期望值: %x\n实际值: %x", expected, got)
+	}
+}
+
+// 测试使用其他哈希函数
+func TestWithHash(t *testing.T) {
+	seed := []byte("seed")
+	out := make([]byte, 40)
+	io.ReadFull(NewWithHash(sha256.New, seed), out)
+
+	h := sha256.New()
+	h.Write(seed)
+	h.Write([]byte{0, 0, 0, 1})
+	if !bytes.Equal(out[:32], h.Sum(nil)) {
+		t.Error("第一个输出块应为 SHA256(Z || 00000001)")
+	}
+}
+
+// 测试域分离：第一个输出块为 Hash(ENTL || tag || Z || ct)，标签不同时输出不同
+func TestWithDomain(t *testing.T) {
+	seed := []byte("seed")
+	r := NewWithDomain("gsc/test", sha256.New, seed)
+	out := make([]byte, 40)
+	io.ReadFull(r, out)
+
+	h := sha256.New()
+	h.Write([]byte{0x00, 0x40})
+	h.Write([]byte("gsc/test"))
+	h.Write(seed)
+	h.Write([]byte{0, 0, 0, 1})
+	if !bytes.Equal(out[:32], h.Sum(nil)) {
+		t.Error("第一个输出块应为 SHA256(ENTL || tag || Z || 00000001)")
+	}
+
+	other := NewWithDomain("gsc/other", sha256.New, seed)
+	out2 := make([]byte, 40)
+	io.ReadFull(other, out2)
+	if bytes.Equal(out, out2) {
+		t.Error("不同标签不应得到相同输出")
+	}
+}
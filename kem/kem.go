@@ -0,0 +1,69 @@
+// Package kem 定义密钥封装机制（KEM）的通用接口，并提供X25519、SM2、RSA和ML-KEM-768实现
+//
+// 上层协议（信封、ECIES、安全信道等）只需面向Scheme编写一次，即可替换底层的KEM。
+// 所有实现输出的共享密钥都已经过密钥派生，可直接作为对称密钥使用
+package kem
+
+import (
+	"io"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+// 错误定义
+var (
+	ErrSchemeMismatch    = gscerr.New(gscerr.ErrParameter, "kem: key does not belong to this scheme")
+	ErrInvalidPublicKey  = gscerr.New(gscerr.ErrMalformed, "kem: invalid public key")
+	ErrInvalidPrivateKey = gscerr.New(gscerr.ErrMalformed, "kem: invalid private key")
+	ErrInvalidCiphertext = gscerr.New(gscerr.ErrMalformed, "kem: invalid encapsulated ciphertext")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrSchemeMismatch:    "kem: 密钥不属于该方案",
+		ErrInvalidPublicKey:  "kem: 公钥无效",
+		ErrInvalidPrivateKey: "kem: 私钥无效",
+		ErrInvalidCiphertext: "kem: 封装密文无效",
+	})
+}
+
+// Scheme 是密钥封装机制
+type Scheme interface {
+	// Name 返回方案名称，如"X25519"、"ML-KEM-768"
+	Name() string
+	// SharedKeySize 返回共享密钥的长度（字节）
+	SharedKeySize() int
+	// GenerateKey 生成密钥对，random为nil时使用crypto/rand
+	GenerateKey(random io.Reader) (PrivateKey, error)
+	// Encapsulate 为pub生成共享密钥及其封装密文，random为nil时使用crypto/rand
+	Encapsulate(pub PublicKey, random io.Reader) (sharedKey, ciphertext []byte, err error)
+	// Decapsulate 使用私钥从封装密文中恢复共享密钥
+	Decapsulate(priv PrivateKey, ciphertext []byte) (sharedKey []byte, err error)
+	// ParsePublicKey 解析PublicKey.Bytes的输出
+	ParsePublicKey(data []byte) (PublicKey, error)
+	// ParsePrivateKey 解析PrivateKey.Bytes的输出
+	ParsePrivateKey(data []byte) (PrivateKey, error)
+}
+
+// PublicKey 是KEM的公钥（封装密钥）
+type PublicKey interface {
+	// Scheme 返回公钥所属的方案
+	Scheme() Scheme
+	// Bytes 返回公钥的编码
+	Bytes() []byte
+}
+
+// PrivateKey 是KEM的私钥（解封装密钥）
+type PrivateKey interface {
+	// Scheme 返回私钥所属的方案
+	Scheme() Scheme
+	// Bytes 返回私钥的编码
+	Bytes() []byte
+	// Public 返回对应的公钥
+	Public() PublicKey
+}
+
+// sharedKeySize 是各方案统一输出的共享密钥长度
+const sharedKeySize = 32
@@ -0,0 +1,126 @@
+package kem
+
+import (
+	"bytes"
+	"crypto/rand"
+	stdrsa "crypto/rsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/laenix/gsc/rsa"
+)
+
+// testKeys 返回各方案的测试密钥，RSA使用2048位密钥以缩短测试时间
+func testKeys(t *testing.T) []PrivateKey {
+	t.Helper()
+	var keys []PrivateKey
+	for _, s := range []Scheme{X25519, SM2, MLKEM768} {
+		priv, err := s.GenerateKey(nil)
+		if err != nil {
+			t.Fatalf("%s: 生成密钥失败: %v", s.Name(), err)
+		}
+		keys = append(keys, priv)
+	}
+	rsaKey, err := rsa.GenerateKey(nil, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(keys, NewRSAPrivateKey(rsaKey))
+}
+
+func TestEncapsulateDecapsulate(t *testing.T) {
+	for _, priv := range testKeys(t) {
+		s := priv.Scheme()
+
+		// 经过编码的公私钥应能正常使用
+		pub, err := s.ParsePublicKey(priv.Public().Bytes())
+		if err != nil {
+			t.Fatalf("%s: 解析公钥失败: %v", s.Name(), err)
+		}
+		parsed, err := s.ParsePrivateKey(priv.Bytes())
+		if err != nil {
+			t.Fatalf("%s: 解析私钥失败: %v", s.Name(), err)
+		}
+
+		sharedKey, ciphertext, err := s.Encapsulate(pub, nil)
+		if err != nil {
+			t.Fatalf("%s: 封装失败: %v", s.Name(), err)
+		}
+		if len(sharedKey) != s.SharedKeySize() {
+			t.Fatalf("%s: 共享密钥长度%d，期望%d", s.Name(), len(sharedKey), s.SharedKeySize())
+		}
+		got, err := s.Decapsulate(parsed, ciphertext)
+		if err != nil {
+			t.Fatalf("%s: 解封装失败: %v", s.Name(), err)
+		}
+		if !bytes.Equal(got, sharedKey) {
+			t.Fatalf("%s: 共享密钥不一致", s.Name())
+		}
+
+		// 篡改的密文得到不同的共享密钥或错误
+		ciphertext[len(ciphertext)-1] ^= 0x01
+		if got, err := s.Decapsulate(parsed, ciphertext); err == nil && bytes.Equal(got, sharedKey) {
+			t.Fatalf("%s: 篡改的密文得到了相同的共享密钥", s.Name())
+		}
+		if _, err := s.Decapsulate(parsed, ciphertext[:len(ciphertext)-1]); !errors.Is(err, ErrInvalidCiphertext) {
+			t.Errorf("%s: 密文长度错误时期望ErrInvalidCiphertext，实际: %v", s.Name(), err)
+		}
+	}
+}
+
+func TestSchemeMismatch(t *testing.T) {
+	x, err := X25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := SM2.Encapsulate(x.Public(), nil); !errors.Is(err, ErrSchemeMismatch) {
+		t.Errorf("期望ErrSchemeMismatch，实际: %v", err)
+	}
+	if _, err := MLKEM768.Decapsulate(x, make([]byte, 32)); !errors.Is(err, ErrSchemeMismatch) {
+		t.Errorf("期望ErrSchemeMismatch，实际: %v", err)
+	}
+}
+
+// 测试由crypto/rsa密钥转换的RSA-KEM密钥：按定义直接求幂得到的密文可以解封装
+func TestRSAStdKey(t *testing.T) {
+	std, err := stdrsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv := NewRSAPrivateKey(rsa.FromStdPrivateKey(std))
+	if !bytes.Equal(priv.Public().Bytes(), NewRSAPublicKey(rsa.FromStdPublicKey(&std.PublicKey)).Bytes()) {
+		t.Fatal("公钥编码不一致")
+	}
+
+	z := bytes.Repeat([]byte{0x5c}, std.Size()-1)
+	c := new(big.Int).Exp(new(big.Int).SetBytes(z), big.NewInt(int64(std.E)), std.N)
+	got, err := RSA.Decapsulate(priv, c.FillBytes(make([]byte, std.Size())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := kdf2(append([]byte{0}, z...), sharedKeySize); !bytes.Equal(got, want) {
+		t.Errorf("共享密钥 %x，期望 %x", got, want)
+	}
+	if _, err := RSA.Decapsulate(priv, std.N.FillBytes(make([]byte, std.Size()))); !errors.Is(err, ErrInvalidCiphertext) {
+		t.Errorf("c = N: %v", err)
+	}
+}
+
+// 测试相同的随机源生成相同的密钥
+func TestGenerateKeyDeterministic(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x11}, 64)
+	for _, s := range []Scheme{X25519, MLKEM768} {
+		a, err := s.GenerateKey(bytes.NewReader(seed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := s.GenerateKey(bytes.NewReader(seed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(a.Bytes(), b.Bytes()) {
+			t.Errorf("%s: 相同随机源生成的密钥不一致", s.Name())
+		}
+	}
+}
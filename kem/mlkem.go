@@ -0,0 +1,91 @@
+package kem
+
+import (
+	"crypto/mlkem"
+	"crypto/rand"
+	"io"
+)
+
+// MLKEM768 是FIPS 203中的ML-KEM-768
+// 封装使用标准库实现，随机数总是来自crypto/rand，Encapsulate的random参数被忽略；
+// 密钥生成从random读取64字节种子，私钥编码即为该种子
+var MLKEM768 Scheme = mlkem768Scheme{}
+
+type mlkem768Scheme struct{}
+
+type mlkem768PublicKey struct {
+	key *mlkem.EncapsulationKey768
+}
+
+type mlkem768PrivateKey struct {
+	key *mlkem.DecapsulationKey768
+}
+
+func (mlkem768Scheme) Name() string { return "ML-KEM-768" }
+
+func (mlkem768Scheme) SharedKeySize() int { return mlkem.SharedKeySize }
+
+func (mlkem768Scheme) GenerateKey(random io.Reader) (PrivateKey, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	seed := make([]byte, mlkem.SeedSize)
+	if _, err := io.ReadFull(random, seed); err != nil {
+		return nil, err
+	}
+	key, err := mlkem.NewDecapsulationKey768(seed)
+	if err != nil {
+		return nil, err
+	}
+	return &mlkem768PrivateKey{key: key}, nil
+}
+
+func (mlkem768Scheme) Encapsulate(pub PublicKey, random io.Reader) ([]byte, []byte, error) {
+	pk, ok := pub.(*mlkem768PublicKey)
+	if !ok {
+		return nil, nil, ErrSchemeMismatch
+	}
+	sharedKey, ciphertext := pk.key.Encapsulate()
+	return sharedKey, ciphertext, nil
+}
+
+func (mlkem768Scheme) Decapsulate(priv PrivateKey, ciphertext []byte) ([]byte, error) {
+	sk, ok := priv.(*mlkem768PrivateKey)
+	if !ok {
+		return nil, ErrSchemeMismatch
+	}
+	// 长度正确但被篡改的密文会得到隐式拒绝产生的伪随机密钥，而不是错误
+	sharedKey, err := sk.key.Decapsulate(ciphertext)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return sharedKey, nil
+}
+
+func (mlkem768Scheme) ParsePublicKey(data []byte) (PublicKey, error) {
+	key, err := mlkem.NewEncapsulationKey768(data)
+	if err != nil {
+		return nil, ErrInvalidPublicKey
+	}
+	return &mlkem768PublicKey{key: key}, nil
+}
+
+func (mlkem768Scheme) ParsePrivateKey(data []byte) (PrivateKey, error) {
+	key, err := mlkem.NewDecapsulationKey768(data)
+	if err != nil {
+		return nil, ErrInvalidPrivateKey
+	}
+	return &mlkem768PrivateKey{key: key}, nil
+}
+
+func (k *mlkem768PublicKey) Scheme() Scheme { return MLKEM768 }
+
+func (k *mlkem768PublicKey) Bytes() []byte { return k.key.Bytes() }
+
+func (k *mlkem768PrivateKey) Scheme() Scheme { return MLKEM768 }
+
+func (k *mlkem768PrivateKey) Bytes() []byte { return k.key.Bytes() }
+
+func (k *mlkem768PrivateKey) Public() PublicKey {
+	return &mlkem768PublicKey{key: k.key.EncapsulationKey()}
+}
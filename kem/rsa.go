@@ -0,0 +1,144 @@
+package kem
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"math/big"
+
+	"github.com/laenix/gsc/rsa"
+)
+
+// RSA 是RFC 5990 / ISO 18033-2中的RSA-KEM，KDF为KDF2-SHA256
+// 封装时选取随机数z ∈ [0, n)，密文为z^e mod n，共享密钥为KDF2(I2OSP(z, nLen))。
+// 密钥使用gsc/rsa的类型，编码为PKCS#1 DER；解封装经过盲化且为常量时间
+var RSA Scheme = rsaScheme{}
+
+// RSAKeyBits 是RSA.GenerateKey生成的模数长度
+const RSAKeyBits = 3072
+
+type rsaScheme struct{}
+
+type rsaPublicKey struct {
+	key *rsa.PublicKey
+}
+
+type rsaPrivateKey struct {
+	key *rsa.PrivateKey
+}
+
+// NewRSAPublicKey 将RSA公钥包装为RSA-KEM公钥，crypto/rsa的公钥先用rsa.FromStdPublicKey转换
+func NewRSAPublicKey(key *rsa.PublicKey) PublicKey {
+	return &rsaPublicKey{key: key}
+}
+
+// NewRSAPrivateKey 将RSA私钥包装为RSA-KEM私钥，crypto/rsa的私钥先用rsa.FromStdPrivateKey转换
+func NewRSAPrivateKey(key *rsa.PrivateKey) PrivateKey {
+	return &rsaPrivateKey{key: key}
+}
+
+func (rsaScheme) Name() string { return "RSA-KEM" }
+
+func (rsaScheme) SharedKeySize() int { return sharedKeySize }
+
+func (rsaScheme) GenerateKey(random io.Reader) (PrivateKey, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	key, err := rsa.GenerateKey(random, RSAKeyBits)
+	if err != nil {
+		return nil, err
+	}
+	return &rsaPrivateKey{key: key}, nil
+}
+
+func (rsaScheme) Encapsulate(pub PublicKey, random io.Reader) ([]byte, []byte, error) {
+	pk, ok := pub.(*rsaPublicKey)
+	if !ok {
+		return nil, nil, ErrSchemeMismatch
+	}
+	if random == nil {
+		random = rand.Reader
+	}
+
+	z, err := rand.Int(random, pk.key.N)
+	if err != nil {
+		return nil, nil, err
+	}
+	zBytes := z.FillBytes(make([]byte, pk.key.Size()))
+	c, err := rsa.EncryptRaw(pk.key, zBytes)
+	if err != nil {
+		return nil, nil, ErrInvalidPublicKey
+	}
+	return kdf2(zBytes, sharedKeySize), c, nil
+}
+
+func (rsaScheme) Decapsulate(priv PrivateKey, ciphertext []byte) ([]byte, error) {
+	sk, ok := priv.(*rsaPrivateKey)
+	if !ok {
+		return nil, ErrSchemeMismatch
+	}
+	nLen := sk.key.Size()
+	if len(ciphertext) != nLen {
+		return nil, ErrInvalidCiphertext
+	}
+	c := new(big.Int).SetBytes(ciphertext)
+	if c.Cmp(sk.key.N) >= 0 {
+		return nil, ErrInvalidCiphertext
+	}
+
+	z, err := rsa.DecryptRaw(sk.key, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return kdf2(z, sharedKeySize), nil
+}
+
+func (rsaScheme) ParsePublicKey(data []byte) (PublicKey, error) {
+	key, err := rsa.ParsePKCS1PublicKey(data)
+	if err != nil {
+		return nil, ErrInvalidPublicKey
+	}
+	return &rsaPublicKey{key: key}, nil
+}
+
+func (rsaScheme) ParsePrivateKey(data []byte) (PrivateKey, error) {
+	key, err := rsa.ParsePKCS1PrivateKey(data)
+	if err != nil {
+		return nil, ErrInvalidPrivateKey
+	}
+	return &rsaPrivateKey{key: key}, nil
+}
+
+func (k *rsaPublicKey) Scheme() Scheme { return RSA }
+
+func (k *rsaPublicKey) Bytes() []byte {
+	data, _ := rsa.MarshalPKCS1PublicKey(k.key)
+	return data
+}
+
+func (k *rsaPrivateKey) Scheme() Scheme { return RSA }
+
+func (k *rsaPrivateKey) Bytes() []byte {
+	data, _ := rsa.MarshalPKCS1PrivateKey(k.key)
+	return data
+}
+
+func (k *rsaPrivateKey) Public() PublicKey {
+	return &rsaPublicKey{key: &k.key.PublicKey}
+}
+
+// kdf2 是ANSI X9.44 / ISO 18033-2中的KDF2：Hash(Z || Counter)，计数器从1开始
+func kdf2(z []byte, length int) []byte {
+	out := make([]byte, 0, length+sha256.Size)
+	var counter [4]byte
+	for i := uint32(1); len(out) < length; i++ {
+		binary.BigEndian.PutUint32(counter[:], i)
+		h := sha256.New()
+		h.Write(z)
+		h.Write(counter[:])
+		out = h.Sum(out)
+	}
+	return out[:length]
+}
@@ -0,0 +1,159 @@
+package kem
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+
+	"github.com/laenix/gsc/kdf/sm3kdf"
+	"github.com/laenix/gsc/sm2"
+)
+
+// SM2 是GB/T 32918.4中的SM2密钥封装机制
+// 封装时选取随机数k，密文C1 = [k]G（未压缩点），共享密钥K = KDF(x2 || y2, klen)，
+// 其中(x2, y2) = [k]PB，KDF为基于SM3的密钥派生函数
+var SM2 Scheme = sm2Scheme{}
+
+const (
+	// SM2标量和坐标长度
+	sm2ScalarSize = 32
+	// 未压缩点长度
+	sm2PointSize = 1 + 2*sm2ScalarSize
+)
+
+type sm2Scheme struct{}
+
+type sm2PublicKey struct {
+	key *sm2.PublicKey
+}
+
+type sm2PrivateKey struct {
+	key *sm2.PrivateKey
+}
+
+func (sm2Scheme) Name() string { return "SM2" }
+
+func (sm2Scheme) SharedKeySize() int { return sharedKeySize }
+
+func (sm2Scheme) GenerateKey(random io.Reader) (PrivateKey, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	key, err := sm2.New().GenerateKey(random)
+	if err != nil {
+		return nil, err
+	}
+	return &sm2PrivateKey{key: key}, nil
+}
+
+func (sm2Scheme) Encapsulate(pub PublicKey, random io.Reader) ([]byte, []byte, error) {
+	pk, ok := pub.(*sm2PublicKey)
+	if !ok {
+		return nil, nil, ErrSchemeMismatch
+	}
+	if random == nil {
+		random = rand.Reader
+	}
+
+	curve := sm2.P256()
+	for {
+		eph, err := sm2.New().GenerateKey(random)
+		if err != nil {
+			return nil, nil, err
+		}
+		k := eph.D.Bytes()
+
+		x2, y2 := curve.ScalarMult(pk.key.X, pk.key.Y, k)
+		sharedKey := sm3kdf.Key(marshalCoordinates(x2, y2), sharedKeySize)
+		// t为全0比特串时需要重新选择k
+		if !allZero(sharedKey) {
+			return sharedKey, marshalSM2Point(&eph.PublicKey), nil
+		}
+	}
+}
+
+func (sm2Scheme) Decapsulate(priv PrivateKey, ciphertext []byte) ([]byte, error) {
+	sk, ok := priv.(*sm2PrivateKey)
+	if !ok {
+		return nil, ErrSchemeMismatch
+	}
+	if len(ciphertext) != sm2PointSize {
+		return nil, ErrInvalidCiphertext
+	}
+	c1, err := sm2.New().DecodePublicKey(ciphertext)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+
+	x2, y2 := sm2.P256().ScalarMult(c1.X, c1.Y, sk.key.D.Bytes())
+	if x2.Sign() == 0 && y2.Sign() == 0 {
+		return nil, ErrInvalidCiphertext
+	}
+	sharedKey := sm3kdf.Key(marshalCoordinates(x2, y2), sharedKeySize)
+	if allZero(sharedKey) {
+		return nil, ErrInvalidCiphertext
+	}
+	return sharedKey, nil
+}
+
+func (sm2Scheme) ParsePublicKey(data []byte) (PublicKey, error) {
+	if len(data) != sm2PointSize {
+		return nil, ErrInvalidPublicKey
+	}
+	key, err := sm2.New().DecodePublicKey(data)
+	if err != nil {
+		return nil, ErrInvalidPublicKey
+	}
+	return &sm2PublicKey{key: key}, nil
+}
+
+func (sm2Scheme) ParsePrivateKey(data []byte) (PrivateKey, error) {
+	if len(data) != sm2ScalarSize {
+		return nil, ErrInvalidPrivateKey
+	}
+	key, err := sm2.New().DecodePrivateKey(data)
+	if err != nil {
+		return nil, ErrInvalidPrivateKey
+	}
+	return &sm2PrivateKey{key: key}, nil
+}
+
+func (k *sm2PublicKey) Scheme() Scheme { return SM2 }
+
+func (k *sm2PublicKey) Bytes() []byte { return marshalSM2Point(k.key) }
+
+func (k *sm2PrivateKey) Scheme() Scheme { return SM2 }
+
+func (k *sm2PrivateKey) Bytes() []byte {
+	return k.key.D.FillBytes(make([]byte, sm2ScalarSize))
+}
+
+func (k *sm2PrivateKey) Public() PublicKey {
+	return &sm2PublicKey{key: &k.key.PublicKey}
+}
+
+// marshalSM2Point 以定长未压缩格式编码SM2公钥点
+func marshalSM2Point(pub *sm2.PublicKey) []byte {
+	out := make([]byte, sm2PointSize)
+	out[0] = 0x04
+	pub.X.FillBytes(out[1 : 1+sm2ScalarSize])
+	pub.Y.FillBytes(out[1+sm2ScalarSize:])
+	return out
+}
+
+// marshalCoordinates 返回定长的 x || y
+func marshalCoordinates(x, y *big.Int) []byte {
+	out := make([]byte, 2*sm2ScalarSize)
+	x.FillBytes(out[:sm2ScalarSize])
+	y.FillBytes(out[sm2ScalarSize:])
+	return out
+}
+
+// allZero 判断b是否全为0
+func allZero(b []byte) bool {
+	var acc byte
+	for _, v := range b {
+		acc |= v
+	}
+	return acc == 0
+}
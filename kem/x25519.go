@@ -0,0 +1,113 @@
+package kem
+
+import (
+	"crypto/sha256"
+	"io"
+
+	"github.com/laenix/gsc/kdf/hkdf"
+	"github.com/laenix/gsc/x25519"
+)
+
+// X25519 是基于X25519密钥交换的KEM
+// 封装时生成临时密钥对，密文为临时公钥；共享密钥为
+// HKDF-SHA256(DH结果, info = 标签 || 临时公钥 || 接收方公钥)
+var X25519 Scheme = x25519Scheme{}
+
+// x25519Label 是共享密钥派生的域分离标签
+const x25519Label = "gsc/kem/X25519"
+
+type x25519Scheme struct{}
+
+type x25519PublicKey struct {
+	key *x25519.PublicKey
+}
+
+type x25519PrivateKey struct {
+	key *x25519.PrivateKey
+}
+
+func (x25519Scheme) Name() string { return "X25519" }
+
+func (x25519Scheme) SharedKeySize() int { return sharedKeySize }
+
+func (s x25519Scheme) GenerateKey(random io.Reader) (PrivateKey, error) {
+	key, err := x25519.GenerateKey(random)
+	if err != nil {
+		return nil, err
+	}
+	return &x25519PrivateKey{key: key}, nil
+}
+
+func (s x25519Scheme) Encapsulate(pub PublicKey, random io.Reader) ([]byte, []byte, error) {
+	pk, ok := pub.(*x25519PublicKey)
+	if !ok {
+		return nil, nil, ErrSchemeMismatch
+	}
+	eph, err := x25519.GenerateKey(random)
+	if err != nil {
+		return nil, nil, err
+	}
+	dh, err := eph.ECDH(pk.key)
+	if err != nil {
+		return nil, nil, ErrInvalidPublicKey
+	}
+	ciphertext := eph.PublicKey().Bytes()
+	sharedKey, err := x25519Derive(dh, ciphertext, pk.key.Bytes())
+	if err != nil {
+		return nil, nil, err
+	}
+	return sharedKey, ciphertext, nil
+}
+
+func (s x25519Scheme) Decapsulate(priv PrivateKey, ciphertext []byte) ([]byte, error) {
+	sk, ok := priv.(*x25519PrivateKey)
+	if !ok {
+		return nil, ErrSchemeMismatch
+	}
+	eph, err := x25519.NewPublicKey(ciphertext)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	dh, err := sk.key.ECDH(eph)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return x25519Derive(dh, ciphertext, sk.key.PublicKey().Bytes())
+}
+
+func (x25519Scheme) ParsePublicKey(data []byte) (PublicKey, error) {
+	key, err := x25519.NewPublicKey(data)
+	if err != nil {
+		return nil, ErrInvalidPublicKey
+	}
+	return &x25519PublicKey{key: key}, nil
+}
+
+func (x25519Scheme) ParsePrivateKey(data []byte) (PrivateKey, error) {
+	key, err := x25519.NewPrivateKey(data)
+	if err != nil {
+		return nil, ErrInvalidPrivateKey
+	}
+	return &x25519PrivateKey{key: key}, nil
+}
+
+func (k *x25519PublicKey) Scheme() Scheme { return X25519 }
+
+func (k *x25519PublicKey) Bytes() []byte { return k.key.Bytes() }
+
+func (k *x25519PrivateKey) Scheme() Scheme { return X25519 }
+
+func (k *x25519PrivateKey) Bytes() []byte { return k.key.Bytes() }
+
+func (k *x25519PrivateKey) Public() PublicKey {
+	return &x25519PublicKey{key: k.key.PublicKey()}
+}
+
+// x25519Derive 将DH结果与双方公钥绑定后派生共享密钥
+func x25519Derive(dh, ephemeral, recipient []byte) ([]byte, error) {
+	info := make([]byte, 0, len(x25519Label)+len(ephemeral)+len(recipient))
+	info = append(info, x25519Label...)
+	info = append(info, ephemeral...)
+	info = append(info, recipient...)
+	return hkdf.Key(sha256.New, dh, nil, info, sharedKeySize)
+}
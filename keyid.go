@@ -0,0 +1,25 @@
+package gsc
+
+import (
+	"github.com/laenix/gsc/hashutil"
+	"github.com/laenix/gsc/sm3"
+)
+
+const (
+	// KeyIDSize 是KeyID输出的密钥标识长度（字节）
+	KeyIDSize = 8
+	// 密钥标识的域分离标签
+	keyIDTag = "gsc/keyid"
+	// 头部中密钥标识的最大长度
+	maxKeyIDSize = 1<<8 - 1
+)
+
+// KeyID 计算密钥的标识：SM3(ENTL || "gsc/keyid" || key) 截断为KeyIDSize字节
+// 标识写入信封头部，解密方可以据此直接选中对应的密钥，而不必逐个尝试。
+// 对于由KMS/HSM包装的密钥，可对包装后的密钥数据调用KeyID，
+// 这样无需解包即可得到稳定的标识，再通过SealWithKeyID写入信封
+func KeyID(key []byte) []byte {
+	h := hashutil.Domain(keyIDTag, sm3.New())
+	h.Write(key)
+	return h.Sum(nil)[:KeyIDSize]
+}
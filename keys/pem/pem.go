@@ -0,0 +1,139 @@
+// Package pem 将非对称密钥编码为标准PEM块，或从PEM块解析密钥
+//
+// 私钥编码为PKCS#8（"PRIVATE KEY"），公钥编码为SubjectPublicKeyInfo（"PUBLIC KEY"），
+// 椭圆曲线私钥还可以编码为SEC 1（"EC PRIVATE KEY"）。支持的密钥类型为
+// *rsa.PrivateKey、*ecdsa.PrivateKey、ed25519.PrivateKey、*ecdh.PrivateKey和*sm2.PrivateKey
+// 及其对应的公钥。DER编码由x509包完成，SM2密钥与OpenSSL相同，算法标识为id-ecPublicKey，
+// 曲线参数为SM2的OID 1.2.156.10197.1.301，因此可以与 openssl pkey 互相读取
+package pem
+
+import (
+	stdx509 "crypto/x509"
+	stdpem "encoding/pem"
+	"errors"
+	"slices"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/x509"
+)
+
+// PEM块类型
+const (
+	TypePrivateKey   = "PRIVATE KEY"
+	TypePublicKey    = "PUBLIC KEY"
+	TypeECPrivateKey = "EC PRIVATE KEY"
+	// TypeSM2PrivateKey 是OpenSSL 3为SM2私钥的SEC 1编码使用的块类型，内容与"EC PRIVATE KEY"相同
+	TypeSM2PrivateKey = "SM2 PRIVATE KEY"
+	TypeRSAPrivateKey = "RSA PRIVATE KEY"
+	TypeRSAPublicKey  = "RSA PUBLIC KEY"
+)
+
+// 错误定义
+var (
+	ErrNoPEMBlock         = gscerr.New(gscerr.ErrMalformed, "pem: no PEM block found")
+	ErrUnexpectedType     = gscerr.New(gscerr.ErrMalformed, "pem: unexpected PEM block type")
+	ErrUnsupportedKeyType = gscerr.New(gscerr.ErrUnsupported, "pem: unsupported key type")
+	ErrInvalidKey         = gscerr.New(gscerr.ErrMalformed, "pem: invalid key encoding")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrNoPEMBlock:         "pem: 未找到PEM块",
+		ErrUnexpectedType:     "pem: PEM块类型不符合预期",
+		ErrUnsupportedKeyType: "pem: 不支持的密钥类型",
+		ErrInvalidKey:         "pem: 密钥编码无效",
+	})
+}
+
+// Encode 将DER编码的数据包装为指定类型的PEM块
+func Encode(blockType string, der []byte) []byte {
+	return stdpem.EncodeToMemory(&stdpem.Block{Type: blockType, Bytes: der})
+}
+
+// Decode 解析data中的第一个PEM块，types非空时要求块类型为其中之一，返回块类型和DER数据
+func Decode(data []byte, types ...string) (string, []byte, error) {
+	block, _ := stdpem.Decode(data)
+	if block == nil {
+		return "", nil, ErrNoPEMBlock
+	}
+	if len(types) > 0 && !slices.Contains(types, block.Type) {
+		return "", nil, ErrUnexpectedType
+	}
+	return block.Type, block.Bytes, nil
+}
+
+// EncodePrivateKey 将私钥编码为PKCS#8 PEM块（"PRIVATE KEY"）
+func EncodePrivateKey(key any) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, keyError(err)
+	}
+	return Encode(TypePrivateKey, der), nil
+}
+
+// EncodePublicKey 将公钥编码为SubjectPublicKeyInfo PEM块（"PUBLIC KEY"）
+func EncodePublicKey(key any) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, keyError(err)
+	}
+	return Encode(TypePublicKey, der), nil
+}
+
+// EncodeECPrivateKey 将ECDSA或SM2私钥编码为SEC 1 PEM块（"EC PRIVATE KEY"）
+func EncodeECPrivateKey(key any) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, keyError(err)
+	}
+	return Encode(TypeECPrivateKey, der), nil
+}
+
+// DecodePrivateKey 解析"PRIVATE KEY"、"EC PRIVATE KEY"、"SM2 PRIVATE KEY"或"RSA PRIVATE KEY"块中的私钥
+func DecodePrivateKey(data []byte) (any, error) {
+	typ, der, err := Decode(data, TypePrivateKey, TypeECPrivateKey, TypeSM2PrivateKey, TypeRSAPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	var key any
+	switch typ {
+	case TypePrivateKey:
+		key, err = x509.ParsePKCS8PrivateKey(der)
+	case TypeECPrivateKey, TypeSM2PrivateKey:
+		key, err = x509.ParseECPrivateKey(der)
+	default:
+		key, err = stdx509.ParsePKCS1PrivateKey(der)
+	}
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	return key, nil
+}
+
+// DecodePublicKey 解析"PUBLIC KEY"或"RSA PUBLIC KEY"块中的公钥
+func DecodePublicKey(data []byte) (any, error) {
+	typ, der, err := Decode(data, TypePublicKey, TypeRSAPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	var key any
+	if typ == TypeRSAPublicKey {
+		key, err = stdx509.ParsePKCS1PublicKey(der)
+	} else {
+		key, err = x509.ParsePKIXPublicKey(der)
+	}
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	return key, nil
+}
+
+// keyError 将x509包的错误转换为本包的错误
+func keyError(err error) error {
+	if errors.Is(err, x509.ErrUnsupportedKeyType) {
+		return ErrUnsupportedKeyType
+	}
+	return ErrInvalidKey
+}
@@ -0,0 +1,139 @@
+package pem
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/laenix/gsc/sm2"
+	"github.com/laenix/gsc/x509"
+)
+
+// 测试读取OpenSSL生成的SM2密钥（openssl genpkey -algorithm SM2）
+func TestOpenSSLSM2(t *testing.T) {
+	read := func(name string) []byte {
+		data, err := os.ReadFile("testdata/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	key, err := DecodePrivateKey(read("sm2.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, ok := key.(*sm2.PrivateKey)
+	if !ok {
+		t.Fatalf("类型 %T", key)
+	}
+	if ec, err := DecodePrivateKey(read("sm2_ec.pem")); err != nil || ec.(*sm2.PrivateKey).D.Cmp(priv.D) != 0 {
+		t.Errorf("SEC 1私钥: %v", err)
+	}
+	pub, err := DecodePublicKey(read("sm2_pub.pem"))
+	if err != nil || pub.(*sm2.PublicKey).X.Cmp(priv.X) != 0 {
+		t.Errorf("公钥: %v", err)
+	}
+
+	// 重新编码得到与OpenSSL相同的PKCS#8和SubjectPublicKeyInfo
+	if out, _ := EncodePrivateKey(priv); !bytes.Equal(out, read("sm2.pem")) {
+		t.Errorf("PKCS#8编码与OpenSSL不同:\n%s", out)
+	}
+	if out, _ := EncodePublicKey(&priv.PublicKey); !bytes.Equal(out, read("sm2_pub.pem")) {
+		t.Errorf("公钥编码与OpenSSL不同:\n%s", out)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	xKey, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	sm2Key, _ := sm2.New().GenerateKey(nil)
+
+	for _, key := range []crypto.Signer{rsaKey, ecKey, edKey} {
+		checkRoundTrip(t, key, key.Public())
+	}
+	checkRoundTrip(t, xKey, xKey.PublicKey())
+	checkRoundTrip(t, sm2Key, &sm2Key.PublicKey)
+
+	for _, key := range []any{ecKey, sm2Key} {
+		data, err := EncodeECPrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ, _, _ := Decode(data); typ != TypeECPrivateKey {
+			t.Errorf("%T: 块类型 %s", key, typ)
+		}
+		if _, err := DecodePrivateKey(data); err != nil {
+			t.Errorf("%T: %v", key, err)
+		}
+	}
+}
+
+// checkRoundTrip 检查私钥和公钥编码后解析得到相同类型和内容的密钥
+func checkRoundTrip(t *testing.T, priv, pub any) {
+	t.Helper()
+	data, err := EncodePrivateKey(priv)
+	if err != nil {
+		t.Fatalf("%T: %v", priv, err)
+	}
+	got, err := DecodePrivateKey(data)
+	if err != nil {
+		t.Fatalf("%T: %v", priv, err)
+	}
+	if eq, ok := priv.(interface{ Equal(crypto.PrivateKey) bool }); ok && !eq.Equal(got) {
+		t.Errorf("%T: 私钥往返后不同", priv)
+	}
+	if s, ok := priv.(*sm2.PrivateKey); ok && got.(*sm2.PrivateKey).D.Cmp(s.D) != 0 {
+		t.Error("SM2私钥往返后不同")
+	}
+
+	if data, err = EncodePublicKey(pub); err != nil {
+		t.Fatalf("%T: %v", pub, err)
+	}
+	gotPub, err := DecodePublicKey(data)
+	if err != nil {
+		t.Fatalf("%T: %v", pub, err)
+	}
+	if eq, ok := pub.(interface{ Equal(crypto.PublicKey) bool }); ok && !eq.Equal(gotPub) {
+		t.Errorf("%T: 公钥往返后不同", pub)
+	}
+	if s, ok := pub.(*sm2.PublicKey); ok && gotPub.(*sm2.PublicKey).Y.Cmp(s.Y) != 0 {
+		t.Error("SM2公钥往返后不同")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := DecodePrivateKey([]byte("not pem")); !errors.Is(err, ErrNoPEMBlock) {
+		t.Errorf("非PEM: %v", err)
+	}
+	if _, err := DecodePrivateKey(Encode("CERTIFICATE", []byte{0x30, 0})); !errors.Is(err, ErrUnexpectedType) {
+		t.Errorf("块类型: %v", err)
+	}
+	if _, err := DecodePublicKey(Encode(TypePublicKey, []byte{0x30, 0})); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("无效公钥: %v", err)
+	}
+	if _, err := EncodePrivateKey("key"); !errors.Is(err, ErrUnsupportedKeyType) {
+		t.Errorf("未知类型: %v", err)
+	}
+	if _, err := EncodeECPrivateKey(ed25519.PrivateKey(make([]byte, 64))); !errors.Is(err, ErrUnsupportedKeyType) {
+		t.Errorf("Ed25519不是EC私钥: %v", err)
+	}
+
+	// SEC 1中的公钥与私钥不一致
+	a, _ := sm2.New().GenerateKey(nil)
+	b, _ := sm2.New().GenerateKey(nil)
+	der, _ := x509.MarshalECPrivateKey(&sm2.PrivateKey{D: a.D, PublicKey: b.PublicKey})
+	if _, err := DecodePrivateKey(Encode(TypeECPrivateKey, der)); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("公私钥不一致: %v", err)
+	}
+}
@@ -0,0 +1,297 @@
+package ssh
+
+import (
+	"bytes"
+	stded25519 "crypto/ed25519"
+	"crypto/rand"
+	stdrsa "crypto/rsa"
+	"encoding/pem"
+	"math/big"
+
+	"github.com/laenix/gsc/aes"
+	"github.com/laenix/gsc/ed25519"
+	"github.com/laenix/gsc/kdf/bcrypt"
+	"github.com/laenix/gsc/modes"
+	"github.com/laenix/gsc/rsa"
+)
+
+const (
+	// DefaultRounds 是ssh-keygen加密私钥时bcrypt_pbkdf的默认轮数
+	DefaultRounds = 16
+
+	pemType   = "OPENSSH PRIVATE KEY"
+	authMagic = "openssh-key-v1\x00"
+	kdfNone   = "none"
+	kdfBcrypt = "bcrypt"
+	// 加密私钥时使用的算法，与ssh-keygen的默认值相同
+	defaultCipher = "aes256-ctr"
+	saltSize      = 16
+	// 不加密时私钥部分按8字节对齐
+	noneBlockSize = 8
+)
+
+// sshCipher 是私钥部分的加密算法
+type sshCipher struct {
+	keySize int
+	cbc     bool
+}
+
+var ciphers = map[string]sshCipher{
+	"aes128-ctr": {16, false},
+	"aes192-ctr": {24, false},
+	"aes256-ctr": {32, false},
+	"aes128-cbc": {16, true},
+	"aes256-cbc": {32, true},
+}
+
+// crypt 用key||iv加密或解密data，data长度必须是块大小的整数倍
+func (c sshCipher) crypt(keyIV, data []byte, decrypt bool) ([]byte, error) {
+	block, err := aes.New(keyIV[:c.keySize])
+	if err != nil {
+		return nil, err
+	}
+	iv := keyIV[c.keySize:]
+	if !c.cbc {
+		ctr, err := modes.NewCTR(block, iv)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]byte, len(data))
+		ctr.XORKeyStream(out, data)
+		return out, nil
+	}
+	cbc, err := modes.NewCBC(block, iv)
+	if err != nil {
+		return nil, err
+	}
+	if decrypt {
+		return cbc.Decrypt(data)
+	}
+	return cbc.Encrypt(data)
+}
+
+// MarshalPrivateKey 将私钥编码为OpenSSH私钥文件（PEM块"OPENSSH PRIVATE KEY"）
+// passphrase为空时不加密，否则与ssh-keygen相同使用aes256-ctr和DefaultRounds轮bcrypt_pbkdf
+func MarshalPrivateKey(priv any, comment string, passphrase []byte) ([]byte, error) {
+	switch k := priv.(type) {
+	case *rsa.PrivateKey:
+		priv = k.ToStd()
+	case ed25519.PrivateKey:
+		priv = stded25519.PrivateKey(k)
+	}
+	var pub any
+	var body writer
+	switch k := priv.(type) {
+	case stded25519.PrivateKey:
+		if len(k) != ed25519.PrivateKeySize {
+			return nil, ErrUnsupportedKeyType
+		}
+		pub = k.Public()
+		body.string([]byte(KeyTypeEd25519))
+		body.string(k[32:])
+		body.string(k)
+	case *stdrsa.PrivateKey:
+		// OpenSSH只支持两个素因子的RSA密钥
+		if len(k.Primes) != 2 {
+			return nil, ErrUnsupportedKeyType
+		}
+		p, q := k.Primes[0], k.Primes[1]
+		pub = &k.PublicKey
+		body.string([]byte(KeyTypeRSA))
+		body.mpint(k.N)
+		body.mpint(big.NewInt(int64(k.E)))
+		body.mpint(k.D)
+		body.mpint(new(big.Int).ModInverse(q, p))
+		body.mpint(p)
+		body.mpint(q)
+	default:
+		return nil, ErrUnsupportedKeyType
+	}
+	pubWire, err := MarshalPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	body.string([]byte(comment))
+
+	cipherName, kdfName, blockSize := kdfNone, kdfNone, noneBlockSize
+	var kdfOptions writer
+	var keyIV []byte
+	if len(passphrase) > 0 {
+		cipherName, kdfName, blockSize = defaultCipher, kdfBcrypt, aes.BlockSize
+		salt := make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		kdfOptions.string(salt)
+		kdfOptions.uint32(DefaultRounds)
+		if keyIV, err = bcrypt.Key(passphrase, salt, DefaultRounds, ciphers[cipherName].keySize+aes.BlockSize); err != nil {
+			return nil, err
+		}
+	}
+
+	// 私钥部分：两个相同的随机校验值 || 私钥 || 注释 || 填充1, 2, 3, ...
+	var check [4]byte
+	if _, err := rand.Read(check[:]); err != nil {
+		return nil, err
+	}
+	section := append(bytes.Repeat(check[:], 2), body.bytes()...)
+	for i := byte(1); len(section)%blockSize != 0; i++ {
+		section = append(section, i)
+	}
+	if keyIV != nil {
+		if section, err = ciphers[cipherName].crypt(keyIV, section, false); err != nil {
+			return nil, err
+		}
+	}
+
+	var w writer
+	w.buf = append(w.buf, authMagic...)
+	w.string([]byte(cipherName))
+	w.string([]byte(kdfName))
+	w.string(kdfOptions.bytes())
+	w.uint32(1)
+	w.string(pubWire)
+	w.string(section)
+	return pem.EncodeToMemory(&pem.Block{Type: pemType, Bytes: w.bytes()}), nil
+}
+
+// ParsePrivateKey 解析OpenSSH私钥文件，返回私钥和注释；加密的私钥需要提供口令
+// 返回的私钥为crypto/ed25519的ed25519.PrivateKey或crypto/rsa的*rsa.PrivateKey
+func ParsePrivateKey(data, passphrase []byte) (priv any, comment string, err error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != pemType {
+		return nil, "", ErrMalformed
+	}
+	b, ok := bytes.CutPrefix(block.Bytes, []byte(authMagic))
+	if !ok {
+		return nil, "", ErrMalformed
+	}
+	r := &reader{data: b}
+	cipherName, kdfName, kdfOptions := string(r.string()), string(r.string()), r.string()
+	if n := r.uint32(); r.err != nil || n != 1 {
+		return nil, "", ErrMalformed
+	}
+	pubWire, section := r.string(), r.string()
+	if !r.done() {
+		return nil, "", ErrMalformed
+	}
+	pub, err := ParsePublicKey(pubWire)
+	if err != nil {
+		return nil, "", err
+	}
+
+	blockSize := noneBlockSize
+	switch {
+	case cipherName == kdfNone && kdfName == kdfNone:
+		if len(kdfOptions) != 0 {
+			return nil, "", ErrMalformed
+		}
+	case kdfName == kdfBcrypt:
+		c, ok := ciphers[cipherName]
+		if !ok {
+			return nil, "", ErrUnsupportedCipher
+		}
+		if len(passphrase) == 0 {
+			return nil, "", ErrPassphraseRequired
+		}
+		opts := &reader{data: kdfOptions}
+		salt, rounds := opts.string(), opts.uint32()
+		if !opts.done() || len(section)%aes.BlockSize != 0 {
+			return nil, "", ErrMalformed
+		}
+		keyIV, err := bcrypt.Key(passphrase, salt, int(rounds), c.keySize+aes.BlockSize)
+		if err != nil {
+			return nil, "", ErrMalformed
+		}
+		if section, err = c.crypt(keyIV, section, true); err != nil {
+			return nil, "", err
+		}
+		blockSize = aes.BlockSize
+	default:
+		return nil, "", ErrUnsupportedCipher
+	}
+
+	r = &reader{data: section}
+	check1, check2 := r.uint32(), r.uint32()
+	if r.err != nil {
+		return nil, "", ErrMalformed
+	}
+	if check1 != check2 {
+		if kdfName == kdfBcrypt {
+			return nil, "", ErrWrongPassphrase
+		}
+		return nil, "", ErrMalformed
+	}
+	if priv, err = readPrivateKey(r); err != nil {
+		return nil, "", err
+	}
+	comment = string(r.string())
+	if r.err != nil || !checkPadding(r.data, blockSize) {
+		return nil, "", ErrMalformed
+	}
+	if !publicKeyMatches(priv, pub) {
+		return nil, "", ErrMalformed
+	}
+	return priv, comment, nil
+}
+
+// readPrivateKey 读取私钥部分中的密钥类型和私钥字段
+func readPrivateKey(r *reader) (any, error) {
+	switch string(r.string()) {
+	case KeyTypeEd25519:
+		pub, key := r.string(), r.string()
+		if r.err != nil || len(key) != ed25519.PrivateKeySize || !bytes.Equal(pub, key[32:]) {
+			return nil, ErrMalformed
+		}
+		priv := stded25519.PrivateKey(bytes.Clone(key))
+		if full, err := ed25519.NewKeyFromSeed(priv.Seed()); err != nil || !bytes.Equal(full, priv) {
+			return nil, ErrMalformed
+		}
+		return priv, nil
+	case KeyTypeRSA:
+		n, e, d, _, p, q := r.mpint(), r.mpint(), r.mpint(), r.mpint(), r.mpint(), r.mpint()
+		if r.err != nil {
+			return nil, ErrMalformed
+		}
+		pub, err := newRSAPublicKey(e, n)
+		if err != nil {
+			return nil, err
+		}
+		priv := &stdrsa.PrivateKey{PublicKey: *pub, D: d, Primes: []*big.Int{p, q}}
+		if err := priv.Validate(); err != nil {
+			return nil, ErrMalformed
+		}
+		priv.Precompute()
+		return priv, nil
+	}
+	if r.err != nil {
+		return nil, ErrMalformed
+	}
+	return nil, ErrUnsupportedKeyType
+}
+
+// checkPadding 检查填充为1, 2, 3, ...且长度小于块大小
+func checkPadding(pad []byte, blockSize int) bool {
+	if len(pad) >= blockSize {
+		return false
+	}
+	for i, b := range pad {
+		if b != byte(i+1) {
+			return false
+		}
+	}
+	return true
+}
+
+// publicKeyMatches 判断私钥与文件头部记录的公钥一致
+func publicKeyMatches(priv, pub any) bool {
+	switch k := priv.(type) {
+	case stded25519.PrivateKey:
+		p, ok := pub.(stded25519.PublicKey)
+		return ok && bytes.Equal(p, k[32:])
+	case *stdrsa.PrivateKey:
+		p, ok := pub.(*stdrsa.PublicKey)
+		return ok && k.PublicKey.Equal(p)
+	}
+	return false
+}
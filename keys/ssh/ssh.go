@@ -0,0 +1,247 @@
+// Package ssh 读写OpenSSH的密钥格式：authorized_keys格式的公钥和"OPENSSH PRIVATE KEY"私钥文件（openssh-key-v1）
+//
+// 支持Ed25519（crypto/ed25519的ed25519.PublicKey/ed25519.PrivateKey）和RSA（crypto/rsa的*rsa.PublicKey/*rsa.PrivateKey）密钥，
+// 编码时也接受gsc/ed25519和gsc/rsa的密钥。
+// 私钥可以用口令加密：口令经bcrypt_pbkdf派生密钥和IV，再以AES-CTR或AES-CBC加密私钥部分，
+// 与ssh-keygen生成的文件互相读取。不支持证书、安全密钥（sk-*）和chacha20-poly1305@openssh.com等AEAD加密
+package ssh
+
+import (
+	"bytes"
+	stded25519 "crypto/ed25519"
+	stdrsa "crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"math/big"
+	"strings"
+
+	"github.com/laenix/gsc/ed25519"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/rsa"
+)
+
+// 密钥类型名称
+const (
+	KeyTypeEd25519 = "ssh-ed25519"
+	KeyTypeRSA     = "ssh-rsa"
+)
+
+// 错误定义
+var (
+	ErrMalformed          = gscerr.New(gscerr.ErrMalformed, "ssh: malformed key")
+	ErrUnsupportedKeyType = gscerr.New(gscerr.ErrUnsupported, "ssh: unsupported key type")
+	ErrUnsupportedCipher  = gscerr.New(gscerr.ErrUnsupported, "ssh: unsupported cipher or KDF")
+	ErrPassphraseRequired = gscerr.New(gscerr.ErrParameter, "ssh: private key is encrypted and requires a passphrase")
+	ErrWrongPassphrase    = gscerr.New(gscerr.ErrAuthFailed, "ssh: incorrect passphrase")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrMalformed:          "ssh: 密钥格式错误",
+		ErrUnsupportedKeyType: "ssh: 不支持的密钥类型",
+		ErrUnsupportedCipher:  "ssh: 不支持的加密算法或密钥派生函数",
+		ErrPassphraseRequired: "ssh: 私钥已加密，需要口令",
+		ErrWrongPassphrase:    "ssh: 口令错误",
+	})
+}
+
+// MarshalPublicKey 将公钥编码为SSH线路格式（RFC 4253第6.6节），即authorized_keys中base64解码后的内容
+func MarshalPublicKey(pub any) ([]byte, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		pub = k.ToStd()
+	case ed25519.PublicKey:
+		pub = stded25519.PublicKey(k)
+	}
+	var w writer
+	switch k := pub.(type) {
+	case stded25519.PublicKey:
+		if len(k) != ed25519.PublicKeySize {
+			return nil, ErrUnsupportedKeyType
+		}
+		w.string([]byte(KeyTypeEd25519))
+		w.string(k)
+	case *stdrsa.PublicKey:
+		w.string([]byte(KeyTypeRSA))
+		w.mpint(big.NewInt(int64(k.E)))
+		w.mpint(k.N)
+	default:
+		return nil, ErrUnsupportedKeyType
+	}
+	return w.bytes(), nil
+}
+
+// ParsePublicKey 解析SSH线路格式的公钥
+func ParsePublicKey(wire []byte) (any, error) {
+	r := &reader{data: wire}
+	pub, err := readPublicKey(r)
+	if err != nil {
+		return nil, err
+	}
+	if !r.done() {
+		return nil, ErrMalformed
+	}
+	return pub, nil
+}
+
+// readPublicKey 读取密钥类型和公钥字段
+func readPublicKey(r *reader) (any, error) {
+	typ := r.string()
+	switch string(typ) {
+	case KeyTypeEd25519:
+		key := r.string()
+		if r.err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, ErrMalformed
+		}
+		return stded25519.PublicKey(bytes.Clone(key)), nil
+	case KeyTypeRSA:
+		e, n := r.mpint(), r.mpint()
+		if r.err != nil {
+			return nil, ErrMalformed
+		}
+		return newRSAPublicKey(e, n)
+	}
+	if r.err != nil {
+		return nil, ErrMalformed
+	}
+	return nil, ErrUnsupportedKeyType
+}
+
+// newRSAPublicKey 检查公钥指数和模数的范围
+func newRSAPublicKey(e, n *big.Int) (*stdrsa.PublicKey, error) {
+	if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 || e.Bit(0) == 0 || n.Sign() <= 0 || n.Bit(0) == 0 {
+		return nil, ErrMalformed
+	}
+	return &stdrsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+// MarshalAuthorizedKey 将公钥编码为authorized_keys格式的一行（"类型 base64 注释"），即.pub文件的内容
+func MarshalAuthorizedKey(pub any, comment string) ([]byte, error) {
+	wire, err := MarshalPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	if strings.ContainsAny(comment, "\r\n") {
+		return nil, ErrMalformed
+	}
+	line := keyType(wire) + " " + base64.StdEncoding.EncodeToString(wire)
+	if comment != "" {
+		line += " " + comment
+	}
+	return []byte(line + "\n"), nil
+}
+
+// ParseAuthorizedKey 解析authorized_keys格式的一行公钥，返回公钥和注释
+// 行首的密钥类型必须与编码中的类型一致，不支持行首的选项（如from="..."）
+func ParseAuthorizedKey(line []byte) (pub any, comment string, err error) {
+	fields := strings.SplitN(strings.TrimSpace(string(line)), " ", 3)
+	if len(fields) < 2 {
+		return nil, "", ErrMalformed
+	}
+	wire, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return nil, "", ErrMalformed
+	}
+	if pub, err = ParsePublicKey(wire); err != nil {
+		return nil, "", err
+	}
+	if keyType(wire) != fields[0] {
+		return nil, "", ErrMalformed
+	}
+	if len(fields) == 3 {
+		comment = strings.TrimSpace(fields[2])
+	}
+	return pub, comment, nil
+}
+
+// Fingerprint 返回公钥的SHA-256指纹，格式与ssh-keygen -l相同（"SHA256:"加不带填充的base64）
+func Fingerprint(pub any) (string, error) {
+	wire, err := MarshalPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(wire)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:]), nil
+}
+
+// keyType 返回已编码公钥开头的密钥类型
+func keyType(wire []byte) string {
+	r := &reader{data: wire}
+	return string(r.string())
+}
+
+// writer 按SSH线路格式追加字段
+type writer struct {
+	buf []byte
+}
+
+func (w *writer) uint32(v uint32) {
+	w.buf = binary.BigEndian.AppendUint32(w.buf, v)
+}
+
+// string 追加带4字节长度前缀的字节串
+func (w *writer) string(s []byte) {
+	w.uint32(uint32(len(s)))
+	w.buf = append(w.buf, s...)
+}
+
+// mpint 追加补码表示的非负整数，最高位为1时前面补一个0字节，零编码为空串
+func (w *writer) mpint(n *big.Int) {
+	b := n.Bytes()
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	w.string(b)
+}
+
+func (w *writer) bytes() []byte {
+	return w.buf
+}
+
+// reader 按SSH线路格式读取字段，出错后的读取都返回零值，调用方最后检查err
+type reader struct {
+	data []byte
+	err  error
+}
+
+func (r *reader) uint32() uint32 {
+	if r.err != nil || len(r.data) < 4 {
+		r.err = ErrMalformed
+		return 0
+	}
+	v := binary.BigEndian.Uint32(r.data)
+	r.data = r.data[4:]
+	return v
+}
+
+func (r *reader) string() []byte {
+	n := r.uint32()
+	if r.err != nil || uint64(n) > uint64(len(r.data)) {
+		r.err = ErrMalformed
+		return nil
+	}
+	s := r.data[:n]
+	r.data = r.data[n:]
+	return s
+}
+
+// mpint 读取非负整数，拒绝负数和多余的前导零字节
+func (r *reader) mpint() *big.Int {
+	b := r.string()
+	if r.err != nil {
+		return nil
+	}
+	if len(b) > 0 && (b[0]&0x80 != 0 || (b[0] == 0 && (len(b) == 1 || b[1]&0x80 == 0))) {
+		r.err = ErrMalformed
+		return nil
+	}
+	return new(big.Int).SetBytes(b)
+}
+
+// done 判断数据已全部读完且没有出错
+func (r *reader) done() bool {
+	return r.err == nil && len(r.data) == 0
+}
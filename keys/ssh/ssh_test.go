@@ -0,0 +1,168 @@
+package ssh
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/pem"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	gsced25519 "github.com/laenix/gsc/ed25519"
+	gscrsa "github.com/laenix/gsc/rsa"
+)
+
+// testdata中的密钥由OpenSSH 9.2的ssh-keygen生成，加密私钥的口令为"openssh test"（-a 4）
+func readFile(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParseOpenSSHKeys(t *testing.T) {
+	tests := []struct {
+		name, comment string
+	}{
+		{"ed25519", "gsc@example"},
+		{"ed25519_encrypted", "encrypted"},
+		{"rsa_encrypted", "rsa@example"},
+	}
+	for _, tt := range tests {
+		pubLine := readFile(t, tt.name+".pub")
+		pub, comment, err := ParseAuthorizedKey(pubLine)
+		if err != nil || comment != tt.comment {
+			t.Fatalf("%s: %v %q", tt.name, err, comment)
+		}
+		// 公钥编码与ssh-keygen的.pub文件逐字节一致
+		if got, _ := MarshalAuthorizedKey(pub, comment); !bytes.Equal(got, pubLine) {
+			t.Errorf("%s: 公钥编码为 %s", tt.name, got)
+		}
+
+		priv, comment, err := ParsePrivateKey(readFile(t, tt.name), []byte("openssh test"))
+		if err != nil || comment != tt.comment {
+			t.Fatalf("%s: %v %q", tt.name, err, comment)
+		}
+		if !publicKeyMatches(priv, pub) {
+			t.Errorf("%s: 私钥与公钥不匹配", tt.name)
+		}
+	}
+
+	pub, _, _ := ParseAuthorizedKey(readFile(t, "ed25519.pub"))
+	// ssh-keygen -l -f testdata/ed25519.pub
+	if fp, _ := Fingerprint(pub); fp != "SHA256:69DOkR+l5XV6cQt63ZiYV6uRANZAYaAP4XtGIitEVuo" {
+		t.Errorf("指纹 %s", fp)
+	}
+	// 未加密的私钥除随机的校验值外完全由密钥和注释决定，重新编码后与ssh-keygen的文件一致
+	// （PEM的换行宽度不同，因此比较解码后的内容）
+	priv, _, _ := ParsePrivateKey(readFile(t, "ed25519"), nil)
+	ours, _ := MarshalPrivateKey(priv, "gsc@example", nil)
+	a, _ := pem.Decode(ours)
+	b, _ := pem.Decode(readFile(t, "ed25519"))
+	if len(a.Bytes) != len(b.Bytes) {
+		t.Fatalf("私钥长度 %d，ssh-keygen为 %d", len(a.Bytes), len(b.Bytes))
+	}
+	var diff []int
+	for i := range a.Bytes {
+		if a.Bytes[i] != b.Bytes[i] {
+			diff = append(diff, i)
+		}
+	}
+	if len(diff) > 0 && diff[len(diff)-1]-diff[0] >= 8 {
+		t.Errorf("除校验值外存在差异: %v", diff)
+	}
+}
+
+func TestPassphraseErrors(t *testing.T) {
+	data := readFile(t, "ed25519_encrypted")
+	if _, _, err := ParsePrivateKey(data, nil); !errors.Is(err, ErrPassphraseRequired) {
+		t.Errorf("缺少口令: %v", err)
+	}
+	if _, _, err := ParsePrivateKey(data, []byte("wrong")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("错误口令: %v", err)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	edPub, edPriv, _ := ed25519.GenerateKey(nil)
+	rsaPriv, _, _ := ParsePrivateKey(readFile(t, "rsa_encrypted"), []byte("openssh test"))
+	keys := []struct {
+		priv any
+		pub  any
+	}{
+		{edPriv, edPub},
+		{rsaPriv, &rsaPriv.(*rsa.PrivateKey).PublicKey},
+	}
+	for _, k := range keys {
+		for _, passphrase := range []string{"", "口令"} {
+			data, err := MarshalPrivateKey(k.priv, "round trip", []byte(passphrase))
+			if err != nil {
+				t.Fatal(err)
+			}
+			priv, comment, err := ParsePrivateKey(data, []byte(passphrase))
+			if err != nil || comment != "round trip" {
+				t.Fatalf("%T %q: %v", k.priv, passphrase, err)
+			}
+			if !publicKeyMatches(priv, k.pub) {
+				t.Errorf("%T %q: 往返结果不一致", k.priv, passphrase)
+			}
+			if passphrase != "" && !strings.Contains(string(data), "OPENSSH PRIVATE KEY") {
+				t.Error("PEM块类型错误")
+			}
+		}
+		line, _ := MarshalAuthorizedKey(k.pub, "")
+		if pub, comment, err := ParseAuthorizedKey(line); err != nil || comment != "" || !publicKeyMatches(k.priv, pub) {
+			t.Errorf("%T: 公钥往返失败: %v", k.pub, err)
+		}
+	}
+
+	// gsc/ed25519和gsc/rsa的密钥按标准库的密钥编码
+	want, _ := MarshalAuthorizedKey(edPub, "gsc")
+	if line, err := MarshalAuthorizedKey(gsced25519.PublicKey(edPub), "gsc"); err != nil || !bytes.Equal(line, want) {
+		t.Errorf("gsc/ed25519公钥编码不同: %v\n%s", err, line)
+	}
+	if data, err := MarshalPrivateKey(gsced25519.PrivateKey(edPriv), "gsc", nil); err != nil {
+		t.Error(err)
+	} else if priv, _, err := ParsePrivateKey(data, nil); err != nil || !edPriv.Equal(priv) {
+		t.Errorf("gsc/ed25519私钥往返失败: %v", err)
+	}
+
+	gscPriv := gscrsa.FromStdPrivateKey(rsaPriv.(*rsa.PrivateKey))
+	want, _ = MarshalAuthorizedKey(keys[1].pub, "gsc")
+	if line, err := MarshalAuthorizedKey(&gscPriv.PublicKey, "gsc"); err != nil || !bytes.Equal(line, want) {
+		t.Errorf("gsc/rsa公钥编码不同: %v\n%s", err, line)
+	}
+	data, err := MarshalPrivateKey(gscPriv, "gsc", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if priv, _, err := ParsePrivateKey(data, nil); err != nil || !publicKeyMatches(priv, keys[1].pub) || priv.(*rsa.PrivateKey).D.Cmp(gscPriv.D) != 0 {
+		t.Errorf("gsc/rsa私钥往返失败: %v", err)
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	for _, line := range []string{
+		"",
+		"ssh-ed25519",
+		"ssh-ed25519 !!!",
+		// 类型与编码内容不一致
+		"ssh-rsa AAAAC3NzaC1lZDI1NTE5AAAAIPurFCgK9r4bBqg+Zgy24ZYI0T/DrJ65uBDxqagOiAC9",
+		// 公钥被截断
+		"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIPurFCgK9r4bBqg+Zgy24ZYI0T/DrJ65uBDxqagO",
+	} {
+		if _, _, err := ParseAuthorizedKey([]byte(line)); err == nil {
+			t.Errorf("%q: 应解析失败", line)
+		}
+	}
+	if _, err := ParsePublicKey([]byte("\x00\x00\x00\x07ssh-dss")); !errors.Is(err, ErrUnsupportedKeyType) {
+		t.Errorf("不支持的类型: %v", err)
+	}
+	if _, _, err := ParsePrivateKey(readFile(t, "ed25519.pub"), nil); !errors.Is(err, ErrMalformed) {
+		t.Errorf("公钥不是私钥: %v", err)
+	}
+}
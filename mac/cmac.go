@@ -0,0 +1,155 @@
+package mac
+
+import (
+	"hash"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/modes"
+)
+
+// 错误定义
+var (
+	ErrInvalidBlockSize = gscerr.New(gscerr.ErrBlockSize, "mac: CMAC supports only 8-byte or 16-byte blocks")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidBlockSize: "mac: CMAC仅支持8字节或16字节分组",
+	})
+}
+
+// CMAC子密钥生成使用的常量
+const (
+	rb64  = 0x1b // 64位分组
+	rb128 = 0x87 // 128位分组
+)
+
+// cmac 实现CMAC（NIST SP 800-38B），即改进的CBC-MAC，可安全处理任意长度消息
+type cmac struct {
+	cipher modes.BlockCipher
+	k1, k2 []byte
+	x      []byte // 当前CBC链值
+	buf    []byte // 尚未处理的数据，最后一个完整块需要延迟到Sum时处理
+	nbuf   int
+}
+
+// 实现的接口检查
+var _ hash.Hash = (*cmac)(nil)
+
+// NewCMAC 使用任意分组密码创建CMAC实例
+func NewCMAC(cipher modes.BlockCipher) (hash.Hash, error) {
+	blockSize := cipher.BlockSize()
+	var rb byte
+	switch blockSize {
+	case 8:
+		rb = rb64
+	case 16:
+		rb = rb128
+	default:
+		return nil, ErrInvalidBlockSize
+	}
+
+	// L = E(K, 0^b)
+	l, err := cipher.Encrypt(make([]byte, blockSize))
+	if err != nil {
+		return nil, err
+	}
+
+	// K1 = L << 1 (必要时异或Rb)，K2 = K1 << 1 (必要时异或Rb)
+	k1 := shiftLeft(l, rb)
+	k2 := shiftLeft(k1, rb)
+
+	return &cmac{
+		cipher: cipher,
+		k1:     k1,
+		k2:     k2,
+		x:      make([]byte, blockSize),
+		buf:    make([]byte, blockSize),
+	}, nil
+}
+
+// shiftLeft 在GF(2^b)中乘以x
+func shiftLeft(in []byte, rb byte) []byte {
+	out := make([]byte, len(in))
+	var carry byte
+	for i := len(in) - 1; i >= 0; i-- {
+		out[i] = in[i]<<1 | carry
+		carry = in[i] >> 7
+	}
+	if carry == 1 {
+		out[len(out)-1] ^= rb
+	}
+	return out
+}
+
+// Reset 重置MAC状态
+func (c *cmac) Reset() {
+	clear(c.x)
+	clear(c.buf)
+	c.nbuf = 0
+}
+
+// Size 返回MAC长度
+func (c *cmac) Size() int {
+	return c.cipher.BlockSize()
+}
+
+// BlockSize 返回分组大小
+func (c *cmac) BlockSize() int {
+	return c.cipher.BlockSize()
+}
+
+// Write 添加更多数据
+func (c *cmac) Write(p []byte) (int, error) {
+	n := len(p)
+	blockSize := len(c.buf)
+	for len(p) > 0 {
+		// 缓冲区已满且后面还有数据，说明缓冲块不是最后一块，可以处理
+		if c.nbuf == blockSize {
+			c.process(c.buf)
+			c.nbuf = 0
+		}
+		k := copy(c.buf[c.nbuf:], p)
+		c.nbuf += k
+		p = p[k:]
+	}
+	return n, nil
+}
+
+// process 将一个完整分组并入CBC链值
+func (c *cmac) process(block []byte) {
+	for i := range c.x {
+		c.x[i] ^= block[i]
+	}
+	// 分组长度固定，Encrypt不会返回错误
+	out, _ := c.cipher.Encrypt(c.x)
+	copy(c.x, out)
+}
+
+// Sum 计算MAC并追加到in之后，不改变当前状态
+func (c *cmac) Sum(in []byte) []byte {
+	blockSize := len(c.buf)
+	last := make([]byte, blockSize)
+	copy(last, c.buf[:c.nbuf])
+
+	if c.nbuf == blockSize {
+		// 最后一块完整：与K1异或
+		for i := range last {
+			last[i] ^= c.k1[i]
+		}
+	} else {
+		// 最后一块不完整：填充10*后与K2异或
+		last[c.nbuf] = 0x80
+		for i := range last {
+			last[i] ^= c.k2[i]
+		}
+	}
+
+	for i := range last {
+		last[i] ^= c.x[i]
+	}
+	tag, _ := c.cipher.Encrypt(last)
+	return append(in, tag...)
+}
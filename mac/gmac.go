@@ -0,0 +1,9 @@
+package mac
+
+import "github.com/laenix/gsc/modes"
+
+// NewGMAC 使用128位分组密码和nonce创建GMAC实例
+// 同一密钥下nonce绝不能重复使用；每个实例只认证一条消息，Sum之后不能再写入，见modes.GMAC
+func NewGMAC(cipher modes.BlockCipher, nonce []byte) (*modes.GMAC, error) {
+	return modes.NewGMAC(cipher, nonce)
+}
@@ -0,0 +1,38 @@
+package mac
+
+import (
+	"crypto/hmac"
+	"hash"
+
+	"github.com/laenix/gsc/modes"
+	"github.com/laenix/gsc/sm3"
+	"github.com/laenix/gsc/sm4"
+)
+
+// NewSM4CMAC 创建以SM4为分组密码的CMAC实例
+func NewSM4CMAC(key []byte) (hash.Hash, error) {
+	cipher, err := sm4.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return NewCMAC(cipher)
+}
+
+// NewSM4GMAC 创建以SM4为分组密码的GMAC实例
+func NewSM4GMAC(key, nonce []byte) (*modes.GMAC, error) {
+	cipher, err := sm4.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return NewGMAC(cipher, nonce)
+}
+
+// NewSM3HMAC 创建以SM3为杂凑函数的HMAC实例
+func NewSM3HMAC(key []byte) hash.Hash {
+	return hmac.New(sm3.New, key)
+}
+
+// Equal 以常量时间比较两个MAC值
+func Equal(mac1, mac2 []byte) bool {
+	return hmac.Equal(mac1, mac2)
+}
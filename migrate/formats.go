@@ -0,0 +1,155 @@
+package migrate
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+
+	"github.com/laenix/gsc"
+	"github.com/laenix/gsc/token"
+)
+
+// 内置格式：gsc的信封、分块流和文件容器，以及token包的令牌
+func init() {
+	Register(tokenFormat{})
+	Register(streamFormat{})
+	Register(containerFormat{})
+	Register(envelopeFormat{})
+}
+
+// splitAlgorithm 将gsc.Algorithm的名称拆分为算法和模式，如 AES-256-GCM -> AES-256, GCM
+func splitAlgorithm(alg gsc.Algorithm) (string, string, bool) {
+	if alg.KeySize() == 0 {
+		return "", "", false
+	}
+	name := alg.String()
+	i := strings.LastIndexByte(name, '-')
+	return name[:i], name[i+1:], true
+}
+
+// envelopeFormat 识别gsc.Seal生成的信封：魔数"GSCE" || 版本 || 算法 || ...
+type envelopeFormat struct{}
+
+func (envelopeFormat) Name() string { return "gsc-envelope" }
+
+func (envelopeFormat) Inspect(header []byte) (Profile, bool) {
+	if len(header) < 6 || !bytes.HasPrefix(header, []byte("GSCE")) {
+		return Profile{}, false
+	}
+	version := int(header[4])
+	if version != 1 && version != gsc.EnvelopeVersion {
+		return Profile{}, false
+	}
+	alg, mode, ok := splitAlgorithm(gsc.Algorithm(header[5]))
+	if !ok {
+		return Profile{}, false
+	}
+	return Profile{Version: version, Algorithm: alg, Mode: mode}, true
+}
+
+// streamFormat 识别gsc.NewStreamWriter生成的分块流：魔数"GSCS" || 版本 || 算法 || ...
+type streamFormat struct{}
+
+func (streamFormat) Name() string { return "gsc-stream" }
+
+func (streamFormat) Inspect(header []byte) (Profile, bool) {
+	alg, mode, ok := inspectStream(header)
+	if !ok {
+		return Profile{}, false
+	}
+	return Profile{Version: gsc.StreamVersion, Algorithm: alg, Mode: mode}, true
+}
+
+// inspectStream 解析分块流头部中的算法
+func inspectStream(header []byte) (string, string, bool) {
+	if len(header) < 6 || !bytes.HasPrefix(header, []byte("GSCS")) || header[4] != gsc.StreamVersion {
+		return "", "", false
+	}
+	return splitAlgorithm(gsc.Algorithm(header[5]))
+}
+
+// containerFormat 识别gsc.NewContainerWriter生成的文件容器：
+// 魔数"GSCF" || 版本 || KDF || KDF参数 || 盐长度 || 盐 || 分块流
+type containerFormat struct{}
+
+func (containerFormat) Name() string { return "gsc-container" }
+
+func (containerFormat) Inspect(header []byte) (Profile, bool) {
+	if len(header) < 6 || !bytes.HasPrefix(header, []byte("GSCF")) || header[4] != gsc.ContainerVersion {
+		return Profile{}, false
+	}
+	p := Profile{Version: gsc.ContainerVersion}
+	kdf := gsc.KDF(header[5])
+	off := 6
+	switch kdf {
+	case gsc.KDFNone:
+	case gsc.KDFArgon2id:
+		// time(4) || memory(4) || threads(1)
+		if len(header) < off+9 {
+			return Profile{}, false
+		}
+		p.KDF = "Argon2id"
+		p.Iterations = int(binary.BigEndian.Uint32(header[off:]))
+		off += 9
+	case gsc.KDFScrypt:
+		// logN(1) || r(4) || p(4)，迭代次数记为N
+		if len(header) < off+9 || header[off] >= 32 {
+			return Profile{}, false
+		}
+		p.KDF = "scrypt"
+		p.Iterations = 1 << header[off]
+		off += 9
+	default:
+		return Profile{}, false
+	}
+	if len(header) < off+1 {
+		return Profile{}, false
+	}
+	off += 1 + int(header[off])
+
+	var ok bool
+	if p.Algorithm, p.Mode, ok = inspectStream(header[min(off, len(header)):]); !ok {
+		return Profile{}, false
+	}
+	return p, true
+}
+
+// tokenFormat 识别token包生成的base64url令牌，文件中只取第一行
+// 令牌解码后为 版本(1) || 时间戳(8) || IV(16) || 密文 || MAC，头部可能只包含令牌的前一部分
+type tokenFormat struct{}
+
+func (tokenFormat) Name() string { return "gsc-token" }
+
+// tokenVersions 是令牌版本对应的算法和模式，模式中注明了CBC之后的MAC
+var tokenVersions = map[byte][2]string{
+	token.VersionFernet: {"AES-128", "CBC-HMAC-SHA256"},
+	token.VersionSM4:    {"SM4", "CBC-HMAC-SM3"},
+}
+
+func (tokenFormat) Inspect(header []byte) (Profile, bool) {
+	line, _, complete := bytes.Cut(header, []byte("\n"))
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	if !complete && len(header) < HeaderSize {
+		complete = true
+	}
+
+	// 版本(1)、时间戳(8)、IV(16)、一个密文分组(16)和最短的MAC(32)共73字节
+	const minTokenSize = 1 + 8 + 16 + 16 + 32
+	encoding := base64.URLEncoding
+	if !complete {
+		// 头部截断了令牌，只解码完整的4字符组
+		line = line[:len(line)/4*4]
+		encoding = encoding.WithPadding(base64.NoPadding)
+	}
+	data, err := encoding.DecodeString(string(line))
+	if err != nil || len(data) < 4 || complete && len(data) < minTokenSize {
+		return Profile{}, false
+	}
+	v, ok := tokenVersions[data[0]]
+	// 时间戳是Unix秒数，高24位为0，用于排除以相同字符开头的普通文本
+	if !ok || data[1] != 0 || data[2] != 0 || data[3] != 0 {
+		return Profile{}, false
+	}
+	return Profile{Version: int(data[0]), Algorithm: v[0], Mode: v[1]}, true
+}
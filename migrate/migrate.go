@@ -0,0 +1,339 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+const (
+	// HeaderSize 为识别格式时最多读取的头部字节数
+	HeaderSize = 512
+	// DefaultMaxSize 是建议的重新加密大小上限（字节），Reencrypt需要把整段密文读入内存
+	DefaultMaxSize = 64 << 20
+)
+
+// 错误定义
+var (
+	ErrUnknownFormat = gscerr.New(gscerr.ErrUnsupported, "migrate: unrecognized ciphertext format")
+	ErrNoEncrypter   = gscerr.New(gscerr.ErrParameter, "migrate: no encrypter for the target format")
+	ErrNoDecrypter   = gscerr.New(gscerr.ErrParameter, "migrate: no decrypter for the source format")
+	ErrTooLarge      = gscerr.New(gscerr.ErrParameter, "migrate: ciphertext exceeds the size limit")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrUnknownFormat: "migrate: 无法识别的密文格式",
+		ErrNoEncrypter:   "migrate: 未提供目标格式的加密函数",
+		ErrNoDecrypter:   "migrate: 未提供源格式的解密函数",
+		ErrTooLarge:      "migrate: 密文超出大小上限",
+	})
+}
+
+// Profile 描述一段密文所使用的算法和参数
+type Profile struct {
+	Format     string // 容器格式名称
+	Version    int    // 容器格式版本
+	Algorithm  string // 分组/流密码算法，如 AES-256、SM4、DES
+	Mode       string // 工作模式，如 CBC、GCM、ECB
+	KDF        string // 口令派生函数，如 PBKDF2-SHA256、Argon2id，为空表示直接使用密钥
+	Iterations int    // KDF迭代次数（Argon2id为时间参数，scrypt为N）
+}
+
+// Format 表示一种可被识别的密文容器格式
+type Format interface {
+	// Name 返回格式名称
+	Name() string
+	// Inspect 根据头部数据识别格式并解析出Profile，不属于该格式时返回false
+	Inspect(header []byte) (Profile, bool)
+}
+
+var (
+	formatsMu sync.RWMutex
+	formats   []Format
+)
+
+// Register 注册一种密文容器格式，后注册的格式优先匹配
+func Register(f Format) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	formats = append([]Format{f}, formats...)
+}
+
+// Identify 根据头部数据识别密文格式
+func Identify(header []byte) (Profile, error) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	for _, f := range formats {
+		if p, ok := f.Inspect(header); ok {
+			if p.Format == "" {
+				p.Format = f.Name()
+			}
+			return p, nil
+		}
+	}
+	return Profile{}, ErrUnknownFormat
+}
+
+// Severity 表示发现问题的严重程度
+type Severity int
+
+const (
+	// Info 仅作提示
+	Info Severity = iota
+	// Warning 建议迁移
+	Warning
+	// Critical 必须迁移
+	Critical
+)
+
+// String 返回严重程度的名称
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "INFO"
+	case Warning:
+		return "WARNING"
+	case Critical:
+		return "CRITICAL"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// Finding 表示对某个Profile的一条评估结果，Message与错误信息一样使用英文
+type Finding struct {
+	Severity Severity
+	Message  string
+}
+
+// Policy 定义可接受的算法和参数
+type Policy struct {
+	// ForbiddenAlgorithms 禁止使用的算法（不区分大小写，忽略密钥长度后缀，如 AES-256 按 AES 比较）
+	ForbiddenAlgorithms []string
+	// ForbiddenModes 禁止使用的工作模式
+	ForbiddenModes []string
+	// UnauthenticatedModes 不提供完整性保护的工作模式，仅给出警告
+	UnauthenticatedModes []string
+	// MinIterations 口令派生函数的最小迭代次数，不适用于Argon2id、scrypt等内存困难的KDF
+	MinIterations int
+}
+
+// DefaultPolicy 返回默认迁移策略
+func DefaultPolicy() Policy {
+	return Policy{
+		ForbiddenAlgorithms:  []string{"DES", "RC4", "2DES"},
+		ForbiddenModes:       []string{"ECB"},
+		UnauthenticatedModes: []string{"CBC", "CFB", "OFB", "CTR"},
+		MinIterations:        100000,
+	}
+}
+
+// Assess 评估Profile是否满足策略
+func (p Policy) Assess(profile Profile) []Finding {
+	var findings []Finding
+
+	for _, alg := range p.ForbiddenAlgorithms {
+		if strings.EqualFold(baseAlgorithm(profile.Algorithm), alg) {
+			findings = append(findings, Finding{Critical, fmt.Sprintf("algorithm %s is no longer secure", profile.Algorithm)})
+		}
+	}
+	for _, mode := range p.ForbiddenModes {
+		if strings.EqualFold(profile.Mode, mode) {
+			findings = append(findings, Finding{Critical, fmt.Sprintf("mode %s leaks plaintext structure", profile.Mode)})
+		}
+	}
+	for _, mode := range p.UnauthenticatedModes {
+		if strings.EqualFold(profile.Mode, mode) {
+			findings = append(findings, Finding{Warning, fmt.Sprintf("mode %s provides no authentication", profile.Mode)})
+		}
+	}
+	if profile.KDF != "" && !memoryHardKDFs[strings.ToLower(profile.KDF)] && profile.Iterations < p.MinIterations {
+		findings = append(findings, Finding{Warning, fmt.Sprintf("%s iteration count %d is below the required %d", profile.KDF, profile.Iterations, p.MinIterations)})
+	}
+
+	return findings
+}
+
+// memoryHardKDFs 是内存困难的KDF，强度主要取决于内存参数，不按迭代次数评估
+var memoryHardKDFs = map[string]bool{"argon2id": true, "argon2i": true, "scrypt": true}
+
+// baseAlgorithm 去掉算法名末尾的数字密钥长度后缀，如 AES-256 -> AES；
+// 其他后缀是算法名的一部分，如 DES-EDE3 保持不变
+func baseAlgorithm(alg string) string {
+	i := strings.LastIndexByte(alg, '-')
+	if i <= 0 || i == len(alg)-1 {
+		return alg
+	}
+	for _, c := range alg[i+1:] {
+		if c < '0' || c > '9' {
+			return alg
+		}
+	}
+	return alg[:i]
+}
+
+// Report 描述一个被扫描对象的识别和评估结果
+type Report struct {
+	Path     string
+	Profile  Profile
+	Findings []Finding
+	Err      error
+}
+
+// NeedsMigration 判断是否存在警告及以上级别的问题
+func (r Report) NeedsMigration() bool {
+	for _, f := range r.Findings {
+		if f.Severity >= Warning {
+			return true
+		}
+	}
+	return false
+}
+
+// Inspect 读取数据流头部，识别格式并按策略评估
+func Inspect(r io.Reader, policy Policy) Report {
+	header := make([]byte, HeaderSize)
+	n, err := io.ReadFull(r, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return Report{Err: err}
+	}
+
+	profile, err := Identify(header[:n])
+	if err != nil {
+		return Report{Err: err}
+	}
+	return Report{Profile: profile, Findings: policy.Assess(profile)}
+}
+
+// ScanDir 递归扫描目录下的所有文件，返回可识别文件的评估报告
+// 无法识别格式的文件会被跳过
+func ScanDir(root string, policy Policy) ([]Report, error) {
+	var reports []Report
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		report := Inspect(f, policy)
+		f.Close()
+
+		if errors.Is(report.Err, ErrUnknownFormat) {
+			return nil
+		}
+		report.Path = path
+		reports = append(reports, report)
+		return nil
+	})
+	return reports, err
+}
+
+// DecryptFunc 将旧格式的密文还原为明文
+type DecryptFunc func(ciphertext []byte) ([]byte, error)
+
+// EncryptFunc 使用目标格式加密明文
+type EncryptFunc func(plaintext []byte) ([]byte, error)
+
+// Reencrypt 读取旧密文，解密后使用目标格式重新加密写出
+// DecryptFunc需要完整的密文，因此整段密文会读入内存；src超过maxSize字节时返回ErrTooLarge，
+// 调用方可以使用DefaultMaxSize
+func Reencrypt(src io.Reader, dst io.Writer, maxSize int64, decrypt DecryptFunc, encrypt EncryptFunc) error {
+	if decrypt == nil {
+		return ErrNoDecrypter
+	}
+	if encrypt == nil {
+		return ErrNoEncrypter
+	}
+	if maxSize <= 0 {
+		return ErrTooLarge
+	}
+
+	// 多读1字节，用于判断src是否超过上限
+	ciphertext, err := io.ReadAll(io.LimitReader(src, maxSize+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(ciphertext)) > maxSize {
+		return ErrTooLarge
+	}
+	plaintext, err := decrypt(ciphertext)
+	if err != nil {
+		return err
+	}
+	out, err := encrypt(plaintext)
+	clear(plaintext)
+	if err != nil {
+		return err
+	}
+
+	_, err = dst.Write(out)
+	return err
+}
+
+// ReencryptFile 当文件不满足策略时原地重新加密，返回文件是否被改写
+// 新内容先写入同目录的临时文件，成功后再替换原文件；maxSize的含义与Reencrypt相同
+func ReencryptFile(path string, policy Policy, maxSize int64, decrypt DecryptFunc, encrypt EncryptFunc) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	report := Inspect(f, policy)
+	f.Close()
+	if report.Err != nil {
+		return false, report.Err
+	}
+	if !report.NeedsMigration() {
+		return false, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	if info.Size() > maxSize {
+		return false, ErrTooLarge
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer src.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".migrate-*")
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := Reencrypt(src, tmp, maxSize, decrypt, encrypt); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return false, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return false, err
+	}
+	return true, nil
+}
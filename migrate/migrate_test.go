@@ -0,0 +1,248 @@
+package migrate
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/laenix/gsc"
+	"github.com/laenix/gsc/token"
+)
+
+// testFormat 是测试用的简单格式：头部为 "TEST:<算法>:<模式>:<迭代次数>\n"
+type testFormat struct{}
+
+func (testFormat) Name() string { return "test" }
+
+func (testFormat) Inspect(header []byte) (Profile, bool) {
+	if !bytes.HasPrefix(header, []byte("TEST:")) {
+		return Profile{}, false
+	}
+	line, _, _ := strings.Cut(string(header[5:]), "\n")
+	fields := strings.Split(line, ":")
+	if len(fields) != 3 {
+		return Profile{}, false
+	}
+	iterations, _ := strconv.Atoi(fields[2])
+	return Profile{Algorithm: fields[0], Mode: fields[1], KDF: "PBKDF2-SHA256", Iterations: iterations}, true
+}
+
+func init() {
+	Register(testFormat{})
+}
+
+// 测试策略评估
+func TestAssess(t *testing.T) {
+	policy := DefaultPolicy()
+
+	tests := []struct {
+		profile  Profile
+		critical bool
+		migrate  bool
+	}{
+		{Profile{Algorithm: "AES-256", Mode: "GCM"}, false, false},
+		{Profile{Algorithm: "DES", Mode: "CBC"}, true, true},
+		{Profile{Algorithm: "SM4", Mode: "ECB"}, true, true},
+		{Profile{Algorithm: "AES-128", Mode: "GCM", KDF: "PBKDF2-SHA256", Iterations: 1000}, false, true},
+	}
+
+	for i, test := range tests {
+		report := Report{Profile: test.profile, Findings: policy.Assess(test.profile)}
+		critical := false
+		for _, f := range report.Findings {
+			if f.Severity == Critical {
+				critical = true
+			}
+		}
+		if critical != test.critical {
+			t.Errorf("测试 #%d: critical期望 %v，实际 %v (%v)", i, test.critical, critical, report.Findings)
+		}
+		if report.NeedsMigration() != test.migrate {
+			t.Errorf("测试 #%d: NeedsMigration期望 %v", i, test.migrate)
+		}
+	}
+}
+
+// 测试目录扫描与原地重新加密
+func TestScanAndReencrypt(t *testing.T) {
+	dir := t.TempDir()
+	weak := filepath.Join(dir, "weak.bin")
+	good := filepath.Join(dir, "good.bin")
+	other := filepath.Join(dir, "other.txt")
+
+	os.WriteFile(weak, []byte("TEST:DES:ECB:10\nsecret"), 0o600)
+	os.WriteFile(good, []byte("TEST:AES-256:GCM:600000\nsecret"), 0o600)
+	os.WriteFile(other, []byte("plain text"), 0o600)
+
+	reports, err := ScanDir(dir, DefaultPolicy())
+	if err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("期望识别2个文件，实际 %d", len(reports))
+	}
+
+	decrypt := func(c []byte) ([]byte, error) {
+		_, body, _ := bytes.Cut(c, []byte("\n"))
+		return append([]byte(nil), body...), nil
+	}
+	encrypt := func(p []byte) ([]byte, error) {
+		return append([]byte("TEST:AES-256:GCM:600000\n"), p...), nil
+	}
+
+	for _, path := range []string{weak, good} {
+		changed, err := ReencryptFile(path, DefaultPolicy(), DefaultMaxSize, decrypt, encrypt)
+		if err != nil {
+			t.Fatalf("%s 重新加密失败: %v", path, err)
+		}
+		if changed != (path == weak) {
+			t.Errorf("%s: 是否改写期望 %v，实际 %v", path, path == weak, changed)
+		}
+	}
+
+	data, _ := os.ReadFile(weak)
+	if string(data) != "TEST:AES-256:GCM:600000\nsecret" {
+		t.Errorf("重新加密结果不正确: %q", data)
+	}
+
+	if _, err := ReencryptFile(other, DefaultPolicy(), DefaultMaxSize, decrypt, encrypt); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("期望ErrUnknownFormat，实际: %v", err)
+	}
+}
+
+// 测试带连字符的算法名只去掉数字密钥长度后缀
+func TestBaseAlgorithm(t *testing.T) {
+	for alg, want := range map[string]string{
+		"AES-256":  "AES",
+		"SM4":      "SM4",
+		"DES-EDE3": "DES-EDE3",
+		"DES-EDE":  "DES-EDE",
+		"RC5-":     "RC5-",
+	} {
+		if got := baseAlgorithm(alg); got != want {
+			t.Errorf("baseAlgorithm(%q) = %q，期望 %q", alg, got, want)
+		}
+	}
+
+	policy := DefaultPolicy()
+	if findings := policy.Assess(Profile{Algorithm: "DES-EDE3", Mode: "GCM"}); len(findings) != 0 {
+		t.Errorf("DES-EDE3不应按单DES评估: %v", findings)
+	}
+}
+
+// 测试缺少解密函数时返回错误而不是panic
+func TestReencryptMissingFuncs(t *testing.T) {
+	identity := func(b []byte) ([]byte, error) { return b, nil }
+	if err := Reencrypt(strings.NewReader("x"), io.Discard, DefaultMaxSize, nil, identity); !errors.Is(err, ErrNoDecrypter) {
+		t.Errorf("期望ErrNoDecrypter，实际: %v", err)
+	}
+	if err := Reencrypt(strings.NewReader("x"), io.Discard, DefaultMaxSize, identity, nil); !errors.Is(err, ErrNoEncrypter) {
+		t.Errorf("期望ErrNoEncrypter，实际: %v", err)
+	}
+}
+
+// 测试超过大小上限的密文被拒绝，不会整段读入内存
+func TestReencryptLimit(t *testing.T) {
+	// Reencrypt会清零明文，因此返回副本
+	identity := func(b []byte) ([]byte, error) { return bytes.Clone(b), nil }
+	var out bytes.Buffer
+	if err := Reencrypt(strings.NewReader("12345"), &out, 5, identity, identity); err != nil || out.String() != "12345" {
+		t.Fatalf("恰好达到上限应成功: %v %q", err, out.String())
+	}
+	if err := Reencrypt(strings.NewReader("123456"), io.Discard, 5, identity, identity); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("期望ErrTooLarge，实际: %v", err)
+	}
+	if err := Reencrypt(strings.NewReader("x"), io.Discard, 0, identity, identity); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("上限为0时期望ErrTooLarge，实际: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "weak.bin")
+	os.WriteFile(path, []byte("TEST:DES:ECB:10\nsecret"), 0o600)
+	if _, err := ReencryptFile(path, DefaultPolicy(), 8, identity, identity); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("ReencryptFile期望ErrTooLarge，实际: %v", err)
+	}
+}
+
+// 测试扫描由gsc和token包实际生成的文件
+func TestScanBuiltinFormats(t *testing.T) {
+	dir := t.TempDir()
+	key := bytes.Repeat([]byte{0x42}, 32)
+	write := func(name string, data []byte) {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	envelope, err := gsc.Seal(gsc.SM4GCM, key[:16], "migrate", []byte("secret"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write("envelope.bin", envelope)
+
+	var stream bytes.Buffer
+	sw, err := gsc.NewStreamWriter(gsc.AES256GCM, key, "migrate", &stream, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sw.Write(bytes.Repeat([]byte("x"), 1000))
+	sw.Close()
+	write("stream.bin", stream.Bytes())
+
+	var container bytes.Buffer
+	cw, err := gsc.NewContainerWriter([]byte("password"), &container, &gsc.ContainerOptions{
+		Algorithm: gsc.AES128GCM, KDF: gsc.KDFScrypt, LogN: 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cw.Write([]byte("secret"))
+	cw.Close()
+	write("container.bin", container.Bytes())
+
+	codec, err := token.NewVersion(token.VersionFernet, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 较长的令牌超过HeaderSize，只有前一部分参与识别
+	for name, plaintext := range map[string][]byte{"short.token": []byte("secret"), "long.token": make([]byte, 1000)} {
+		tok, err := codec.Encrypt(plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		write(name, []byte(tok+"\n"))
+	}
+	write("notes.txt", []byte("gAAAAA is how a Fernet token starts\n"))
+
+	reports, err := ScanDir(dir, DefaultPolicy())
+	if err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+	want := map[string]Profile{
+		"envelope.bin":  {Format: "gsc-envelope", Version: gsc.EnvelopeVersion, Algorithm: "SM4", Mode: "GCM"},
+		"stream.bin":    {Format: "gsc-stream", Version: gsc.StreamVersion, Algorithm: "AES-256", Mode: "GCM"},
+		"container.bin": {Format: "gsc-container", Version: gsc.ContainerVersion, Algorithm: "AES-128", Mode: "GCM", KDF: "scrypt", Iterations: 1 << 10},
+		"short.token":   {Format: "gsc-token", Version: token.VersionFernet, Algorithm: "AES-128", Mode: "CBC-HMAC-SHA256"},
+		"long.token":    {Format: "gsc-token", Version: token.VersionFernet, Algorithm: "AES-128", Mode: "CBC-HMAC-SHA256"},
+	}
+	if len(reports) != len(want) {
+		t.Fatalf("期望识别%d个文件，实际 %d: %+v", len(want), len(reports), reports)
+	}
+	for _, r := range reports {
+		name := filepath.Base(r.Path)
+		if r.Err != nil {
+			t.Errorf("%s: %v", name, r.Err)
+			continue
+		}
+		if r.Profile != want[name] {
+			t.Errorf("%s: 期望 %+v，实际 %+v", name, want[name], r.Profile)
+		}
+		if r.NeedsMigration() {
+			t.Errorf("%s: 不应需要迁移: %v", name, r.Findings)
+		}
+	}
+}
@@ -0,0 +1,428 @@
+// Package minisign 实现与minisign兼容的Ed25519分离签名，可读写minisign的公钥、私钥和签名文件
+//
+// 签名文件包含对原文（或原文的BLAKE2b-512摘要）的签名，以及覆盖该签名和可信注释的全局签名，
+// 可信注释通常记录签名时间和文件名，验证通过后才可以信任。私钥文件可以用口令加密，
+// 口令经libsodium的scrypt（crypto_pwhash_scryptsalsa208sha256）派生密钥流后与私钥异或
+package minisign
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/laenix/gsc/blake2b"
+	"github.com/laenix/gsc/ed25519"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/kdf/scrypt"
+)
+
+const (
+	untrustedPrefix = "untrusted comment: "
+	trustedPrefix   = "trusted comment: "
+
+	// 签名算法：Ed为直接签名原文（旧格式），ED为签名原文的BLAKE2b-512摘要
+	algPure   = "Ed"
+	algHashed = "ED"
+	// 私钥的口令派生算法和校验算法
+	kdfScrypt = "Sc"
+	kdfNone   = "\x00\x00"
+	chkBLAKE2 = "B2"
+
+	keyIDSize     = 8
+	saltSize      = 32
+	publicKeySize = 2 + keyIDSize + ed25519.PublicKeySize
+	signatureSize = 2 + keyIDSize + ed25519.SignatureSize
+	// 被口令加密的部分：密钥ID || 私钥 || 校验值
+	keyNumSize     = keyIDSize + ed25519.PrivateKeySize + 32
+	privateKeySize = 2 + 2 + 2 + saltSize + 8 + 8 + keyNumSize
+
+	// 派生密钥允许使用的最大内存，防止异常的私钥文件耗尽内存
+	maxScryptMemory = 1 << 32
+)
+
+// 错误定义
+var (
+	ErrMalformedKey         = gscerr.New(gscerr.ErrMalformed, "minisign: malformed key")
+	ErrMalformedSignature   = gscerr.New(gscerr.ErrMalformed, "minisign: malformed signature")
+	ErrUnsupportedAlgorithm = gscerr.New(gscerr.ErrUnsupported, "minisign: unsupported algorithm")
+	ErrKeyIDMismatch        = gscerr.New(gscerr.ErrVerification, "minisign: signature was created with a different key")
+	ErrInvalidSignature     = gscerr.New(gscerr.ErrVerification, "minisign: invalid signature")
+	ErrInvalidGlobalSig     = gscerr.New(gscerr.ErrVerification, "minisign: invalid signature of the trusted comment")
+	ErrWrongPassword        = gscerr.New(gscerr.ErrAuthFailed, "minisign: wrong password or corrupted secret key")
+	ErrPasswordRequired     = gscerr.New(gscerr.ErrParameter, "minisign: secret key is encrypted and requires a password")
+	ErrKDFParams            = gscerr.New(gscerr.ErrParameter, "minisign: scrypt parameters out of range")
+	ErrComment              = gscerr.New(gscerr.ErrParameter, "minisign: comments must be a single line")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrMalformedKey:         "minisign: 密钥格式错误",
+		ErrMalformedSignature:   "minisign: 签名格式错误",
+		ErrUnsupportedAlgorithm: "minisign: 不支持的算法",
+		ErrKeyIDMismatch:        "minisign: 签名由其他密钥生成",
+		ErrInvalidSignature:     "minisign: 签名无效",
+		ErrInvalidGlobalSig:     "minisign: 可信注释的签名无效",
+		ErrWrongPassword:        "minisign: 口令错误或私钥已损坏",
+		ErrPasswordRequired:     "minisign: 私钥已加密，需要口令",
+		ErrKDFParams:            "minisign: scrypt参数超出范围",
+		ErrComment:              "minisign: 注释必须是单行",
+	})
+}
+
+// KDFParams 是加密私钥时libsodium scrypt的运算量和内存上限，与minisign的参数含义相同
+type KDFParams struct {
+	OpsLimit uint64
+	MemLimit uint64
+}
+
+// DefaultKDFParams 是minisign生成私钥时使用的参数（crypto_pwhash_scryptsalsa208sha256的SENSITIVE级别），
+// 派生一次密钥约需1GiB内存
+var DefaultKDFParams = KDFParams{OpsLimit: 1 << 25, MemLimit: 1 << 30}
+
+// PublicKey 是minisign公钥，ID是8字节的密钥ID（按小端序解释），签名中记录该ID以便选择公钥
+type PublicKey struct {
+	ID  uint64
+	Key ed25519.PublicKey
+}
+
+// PrivateKey 是minisign私钥
+type PrivateKey struct {
+	ID  uint64
+	Key ed25519.PrivateKey
+}
+
+// GenerateKey 生成密钥对和随机的密钥ID，random为nil时使用crypto/rand
+func GenerateKey(random io.Reader) (*PublicKey, *PrivateKey, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	var id [keyIDSize]byte
+	if _, err := io.ReadFull(random, id[:]); err != nil {
+		return nil, nil, err
+	}
+	pub, priv, err := ed25519.GenerateKey(random)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyID := binary.LittleEndian.Uint64(id[:])
+	return &PublicKey{ID: keyID, Key: pub}, &PrivateKey{ID: keyID, Key: priv}, nil
+}
+
+// Public 返回对应的公钥
+func (k *PrivateKey) Public() *PublicKey {
+	return &PublicKey{ID: k.ID, Key: k.Key.Public().(ed25519.PublicKey)}
+}
+
+// keyIDString 按minisign的显示方式将密钥ID格式化为16位大写十六进制
+func keyIDString(id uint64) string {
+	return fmt.Sprintf("%016X", id)
+}
+
+// String 返回公钥的base64编码，即minisign -P接受的形式
+func (k *PublicKey) String() string {
+	b := make([]byte, 0, publicKeySize)
+	b = append(b, algPure...)
+	b = binary.LittleEndian.AppendUint64(b, k.ID)
+	b = append(b, k.Key...)
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// Marshal 编码为minisign公钥文件（minisign.pub）
+func (k *PublicKey) Marshal() []byte {
+	return []byte(untrustedPrefix + "minisign public key " + keyIDString(k.ID) + "\n" + k.String() + "\n")
+}
+
+// ParsePublicKey 解析minisign公钥文件，或者单独一行的base64公钥
+func ParsePublicKey(data []byte) (*PublicKey, error) {
+	lines := splitLines(data)
+	if len(lines) == 2 && strings.HasPrefix(lines[0], untrustedPrefix) {
+		lines = lines[1:]
+	}
+	if len(lines) != 1 {
+		return nil, ErrMalformedKey
+	}
+	b, err := base64.StdEncoding.Strict().DecodeString(lines[0])
+	if err != nil || len(b) != publicKeySize {
+		return nil, ErrMalformedKey
+	}
+	if string(b[:2]) != algPure {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	return &PublicKey{
+		ID:  binary.LittleEndian.Uint64(b[2:]),
+		Key: ed25519.PublicKey(bytes.Clone(b[2+keyIDSize:])),
+	}, nil
+}
+
+// Marshal 编码为minisign私钥文件（minisign.key）
+// password为空时私钥不加密（相当于minisign -W）；params为nil时使用DefaultKDFParams
+func (k *PrivateKey) Marshal(password []byte, params *KDFParams) ([]byte, error) {
+	if params == nil {
+		params = &DefaultKDFParams
+	}
+	b := make([]byte, 0, privateKeySize)
+	b = append(b, algPure...)
+	salt := make([]byte, saltSize)
+	var ops, mem uint64
+	if len(password) == 0 {
+		b = append(b, kdfNone...)
+	} else {
+		b = append(b, kdfScrypt...)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		ops, mem = params.OpsLimit, params.MemLimit
+	}
+	b = append(b, chkBLAKE2...)
+	b = append(b, salt...)
+	b = binary.LittleEndian.AppendUint64(b, ops)
+	b = binary.LittleEndian.AppendUint64(b, mem)
+
+	keyNum := b[len(b):privateKeySize]
+	binary.LittleEndian.PutUint64(keyNum, k.ID)
+	copy(keyNum[keyIDSize:], k.Key)
+	chk := keyChecksum(keyNum)
+	copy(keyNum[keyIDSize+ed25519.PrivateKeySize:], chk[:])
+	if len(password) > 0 {
+		stream, err := deriveStream(password, salt, ops, mem)
+		if err != nil {
+			return nil, err
+		}
+		subtle.XORBytes(keyNum, keyNum, stream)
+	}
+	b = b[:privateKeySize]
+
+	comment := "minisign unencrypted secret key"
+	if len(password) > 0 {
+		comment = "minisign encrypted secret key"
+	}
+	return []byte(untrustedPrefix + comment + "\n" + base64.StdEncoding.EncodeToString(b) + "\n"), nil
+}
+
+// ParsePrivateKey 解析minisign私钥文件，加密的私钥需要提供口令
+// 口令错误和私钥损坏都返回ErrWrongPassword
+func ParsePrivateKey(data, password []byte) (*PrivateKey, error) {
+	lines := splitLines(data)
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], untrustedPrefix) {
+		return nil, ErrMalformedKey
+	}
+	b, err := base64.StdEncoding.Strict().DecodeString(lines[1])
+	if err != nil || len(b) != privateKeySize {
+		return nil, ErrMalformedKey
+	}
+	if string(b[:2]) != algPure || string(b[4:6]) != chkBLAKE2 {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	salt := b[6 : 6+saltSize]
+	ops := binary.LittleEndian.Uint64(b[6+saltSize:])
+	mem := binary.LittleEndian.Uint64(b[6+saltSize+8:])
+	keyNum := bytes.Clone(b[privateKeySize-keyNumSize:])
+
+	switch string(b[2:4]) {
+	case kdfScrypt:
+		if len(password) == 0 {
+			return nil, ErrPasswordRequired
+		}
+		stream, err := deriveStream(password, salt, ops, mem)
+		if err != nil {
+			return nil, err
+		}
+		subtle.XORBytes(keyNum, keyNum, stream)
+	case kdfNone:
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	chk := keyChecksum(keyNum)
+	if subtle.ConstantTimeCompare(chk[:], keyNum[keyIDSize+ed25519.PrivateKeySize:]) != 1 {
+		return nil, ErrWrongPassword
+	}
+	priv := ed25519.PrivateKey(keyNum[keyIDSize : keyIDSize+ed25519.PrivateKeySize])
+	// 私钥的后32字节是公钥，必须与种子一致
+	if full, err := ed25519.NewKeyFromSeed(priv.Seed()); err != nil || !bytes.Equal(full, priv) {
+		return nil, ErrMalformedKey
+	}
+	return &PrivateKey{ID: binary.LittleEndian.Uint64(keyNum), Key: priv}, nil
+}
+
+// keyChecksum 计算私钥的校验值：BLAKE2b-256(算法 || 密钥ID || 私钥)
+func keyChecksum(keyNum []byte) [blake2b.Size256]byte {
+	msg := make([]byte, 0, 2+keyIDSize+ed25519.PrivateKeySize)
+	msg = append(msg, algPure...)
+	msg = append(msg, keyNum[:keyIDSize+ed25519.PrivateKeySize]...)
+	return blake2b.Sum256(msg)
+}
+
+// deriveStream 用libsodium的scrypt从口令派生与私钥异或的密钥流
+func deriveStream(password, salt []byte, opsLimit, memLimit uint64) ([]byte, error) {
+	logN, r, p := scryptParams(opsLimit, memLimit)
+	if p < 1 || uint64(128*r)<<logN > maxScryptMemory {
+		return nil, ErrKDFParams
+	}
+	return scrypt.Key(password, salt, 1<<logN, r, p, keyNumSize)
+}
+
+// scryptParams 按libsodium的pickparams由运算量和内存上限选出scrypt的log2(N)、r和p
+func scryptParams(opsLimit, memLimit uint64) (logN uint, r, p int) {
+	opsLimit = max(opsLimit, 32768)
+	r = 8
+	var maxN uint64
+	if opsLimit < memLimit/32 {
+		p = 1
+		maxN = opsLimit / (uint64(r) * 4)
+	} else {
+		maxN = memLimit / (uint64(r) * 128)
+	}
+	for logN = 1; logN < 63; logN++ {
+		if uint64(1)<<logN > maxN/2 {
+			break
+		}
+	}
+	if opsLimit >= memLimit/32 {
+		maxrp := min((opsLimit/4)>>logN, 0x3fffffff)
+		p = int(maxrp / uint64(r))
+	}
+	return logN, r, p
+}
+
+// SignOptions 是签名选项
+type SignOptions struct {
+	// UntrustedComment 是签名文件第一行的注释，未被签名保护，为空时使用默认注释
+	UntrustedComment string
+	// TrustedComment 是受全局签名保护的注释，为空时为"timestamp:<Unix时间>"
+	TrustedComment string
+	// Legacy 为true时直接签名原文（Ed，minisign 0.8之前的格式），否则签名原文的BLAKE2b-512摘要（ED）
+	Legacy bool
+}
+
+// Signature 是解析后的签名文件
+type Signature struct {
+	// Hashed 表示签名的是原文的BLAKE2b-512摘要
+	Hashed           bool
+	KeyID            uint64
+	Signature        []byte
+	UntrustedComment string
+	TrustedComment   string
+	GlobalSignature  []byte
+}
+
+// Sign 签名message，返回minisign签名文件（.minisig）的内容
+func Sign(priv *PrivateKey, message []byte, opts *SignOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &SignOptions{}
+	}
+	untrusted, trusted := opts.UntrustedComment, opts.TrustedComment
+	if untrusted == "" {
+		untrusted = "signature from minisign secret key"
+	}
+	if trusted == "" {
+		trusted = fmt.Sprintf("timestamp:%d", time.Now().Unix())
+	}
+	if strings.ContainsAny(untrusted, "\r\n") || strings.ContainsAny(trusted, "\r\n") {
+		return nil, ErrComment
+	}
+
+	alg := algHashed
+	if opts.Legacy {
+		alg = algPure
+	} else {
+		sum := blake2b.Sum512(message)
+		message = sum[:]
+	}
+	detached, err := ed25519.Sign(priv.Key, message)
+	if err != nil {
+		return nil, err
+	}
+	sig := make([]byte, 0, signatureSize)
+	sig = append(sig, alg...)
+	sig = binary.LittleEndian.AppendUint64(sig, priv.ID)
+	sig = append(sig, detached...)
+	global, err := ed25519.Sign(priv.Key, append(bytes.Clone(sig[2+keyIDSize:]), trusted...))
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString(untrustedPrefix + untrusted + "\n")
+	b.WriteString(base64.StdEncoding.EncodeToString(sig) + "\n")
+	b.WriteString(trustedPrefix + trusted + "\n")
+	b.WriteString(base64.StdEncoding.EncodeToString(global) + "\n")
+	return []byte(b.String()), nil
+}
+
+// ParseSignature 解析签名文件，不验证签名
+func ParseSignature(data []byte) (*Signature, error) {
+	lines := splitLines(data)
+	if len(lines) != 4 {
+		return nil, ErrMalformedSignature
+	}
+	untrusted, ok1 := strings.CutPrefix(lines[0], untrustedPrefix)
+	trusted, ok2 := strings.CutPrefix(lines[2], trustedPrefix)
+	if !ok1 || !ok2 {
+		return nil, ErrMalformedSignature
+	}
+	sig, err := base64.StdEncoding.Strict().DecodeString(lines[1])
+	if err != nil || len(sig) != signatureSize {
+		return nil, ErrMalformedSignature
+	}
+	global, err := base64.StdEncoding.Strict().DecodeString(lines[3])
+	if err != nil || len(global) != ed25519.SignatureSize {
+		return nil, ErrMalformedSignature
+	}
+	var hashed bool
+	switch string(sig[:2]) {
+	case algHashed:
+		hashed = true
+	case algPure:
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+	return &Signature{
+		Hashed:           hashed,
+		KeyID:            binary.LittleEndian.Uint64(sig[2:]),
+		Signature:        sig[2+keyIDSize:],
+		UntrustedComment: untrusted,
+		TrustedComment:   trusted,
+		GlobalSignature:  global,
+	}, nil
+}
+
+// Verify 用pub验证message的签名文件，签名和可信注释的全局签名都通过时返回解析后的签名
+// 只有验证通过后返回的TrustedComment才可信
+func Verify(pub *PublicKey, message, signature []byte) (*Signature, error) {
+	sig, err := ParseSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	if sig.KeyID != pub.ID {
+		return nil, ErrKeyIDMismatch
+	}
+	if sig.Hashed {
+		sum := blake2b.Sum512(message)
+		message = sum[:]
+	}
+	if !ed25519.Verify(pub.Key, message, sig.Signature) {
+		return nil, ErrInvalidSignature
+	}
+	if !ed25519.Verify(pub.Key, append(bytes.Clone(sig.Signature), sig.TrustedComment...), sig.GlobalSignature) {
+		return nil, ErrInvalidGlobalSig
+	}
+	return sig, nil
+}
+
+// splitLines 按行拆分文件内容，去掉行尾的\r和末尾的空行
+func splitLines(data []byte) []string {
+	lines := strings.Split(strings.TrimRight(string(data), "\r\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
@@ -0,0 +1,173 @@
+package minisign
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// testdata中的文件由按minisign格式独立编写的脚本借助libsodium生成，
+// 私钥口令为"minisign test"，scrypt参数为opslimit=32768、memlimit=16MiB
+func readFile(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestVerifyVectors(t *testing.T) {
+	pub, err := ParsePublicKey(readFile(t, "minisign.pub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub.ID != 0x0807060504030201 {
+		t.Errorf("密钥ID %016X", pub.ID)
+	}
+	msg := readFile(t, "message.txt")
+	for _, name := range []string{"message.txt.minisig", "legacy.minisig"} {
+		sig, err := Verify(pub, msg, readFile(t, name))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if sig.TrustedComment != "timestamp:1700000000\tfile:message.txt" || sig.Hashed != (name == "message.txt.minisig") {
+			t.Errorf("%s: %+v", name, sig)
+		}
+		if _, err := Verify(pub, append(msg, '!'), readFile(t, name)); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: 篡改原文: %v", name, err)
+		}
+	}
+
+	// 只有base64一行的公钥
+	line := strings.Split(string(readFile(t, "minisign.pub")), "\n")[1]
+	if other, err := ParsePublicKey([]byte(line)); err != nil || other.String() != line || !other.Key.Equal(pub.Key) {
+		t.Errorf("单行公钥: %v", err)
+	}
+	if !bytes.Equal(pub.Marshal(), readFile(t, "minisign.pub")) {
+		t.Errorf("公钥文件编码不一致:\n%s", pub.Marshal())
+	}
+}
+
+func TestPrivateKeyVector(t *testing.T) {
+	data := readFile(t, "minisign.key")
+	priv, err := ParsePrivateKey(data, []byte("minisign test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, _ := ParsePublicKey(readFile(t, "minisign.pub"))
+	if priv.ID != pub.ID || !priv.Public().Key.Equal(pub.Key) {
+		t.Error("私钥与公钥不匹配")
+	}
+	if _, err := ParsePrivateKey(data, []byte("wrong")); !errors.Is(err, ErrWrongPassword) {
+		t.Errorf("错误口令: %v", err)
+	}
+	if _, err := ParsePrivateKey(data, nil); !errors.Is(err, ErrPasswordRequired) {
+		t.Errorf("缺少口令: %v", err)
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	pub, priv, err := GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("release-1.0.tar.gz")
+	for _, opts := range []*SignOptions{nil, {TrustedComment: "file:release-1.0.tar.gz", Legacy: true}} {
+		sig, err := Sign(priv, msg, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := Verify(pub, msg, sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if opts == nil && !strings.HasPrefix(parsed.TrustedComment, "timestamp:") {
+			t.Errorf("默认可信注释 %q", parsed.TrustedComment)
+		}
+	}
+
+	sig, _ := Sign(priv, msg, &SignOptions{TrustedComment: "trusted"})
+	// 修改可信注释会使全局签名失效
+	forged := bytes.Replace(sig, []byte("trusted comment: trusted"), []byte("trusted comment: forged"), 1)
+	if _, err := Verify(pub, msg, forged); !errors.Is(err, ErrInvalidGlobalSig) {
+		t.Errorf("篡改可信注释: %v", err)
+	}
+	// 未受信注释不受保护
+	relabeled := bytes.Replace(sig, []byte("signature from minisign secret key"), []byte("other"), 1)
+	if _, err := Verify(pub, msg, relabeled); err != nil {
+		t.Errorf("修改未受信注释: %v", err)
+	}
+	otherPub, _, _ := GenerateKey(nil)
+	if _, err := Verify(otherPub, msg, sig); !errors.Is(err, ErrKeyIDMismatch) {
+		t.Errorf("其他公钥: %v", err)
+	}
+	otherPub.ID = pub.ID
+	if _, err := Verify(otherPub, msg, sig); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("ID相同的其他公钥: %v", err)
+	}
+	if _, err := Sign(priv, msg, &SignOptions{TrustedComment: "a\nb"}); !errors.Is(err, ErrComment) {
+		t.Errorf("多行注释: %v", err)
+	}
+}
+
+func TestPrivateKeyRoundTrip(t *testing.T) {
+	_, priv, _ := GenerateKey(nil)
+	params := &KDFParams{OpsLimit: 32768, MemLimit: 1 << 20}
+	for _, password := range []string{"", "口令"} {
+		data, err := priv.Marshal([]byte(password), params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ParsePrivateKey(data, []byte(password))
+		if err != nil {
+			t.Fatalf("%q: %v", password, err)
+		}
+		if got.ID != priv.ID || !got.Key.Equal(priv.Key) {
+			t.Errorf("%q: 往返结果不一致", password)
+		}
+	}
+
+	if _, err := priv.Marshal([]byte("pw"), &KDFParams{OpsLimit: 1 << 40, MemLimit: 1 << 40}); !errors.Is(err, ErrKDFParams) {
+		t.Errorf("内存过大: %v", err)
+	}
+}
+
+// 测试scrypt参数的选择与libsodium一致
+func TestScryptParams(t *testing.T) {
+	tests := []struct {
+		ops, mem uint64
+		logN     uint
+		r, p     int
+	}{
+		{1 << 25, 1 << 30, 20, 8, 1}, // minisign默认参数
+		{32768, 1 << 24, 10, 8, 1},
+		{32768, 1 << 20, 10, 8, 1},
+		{1 << 20, 1 << 20, 10, 8, 32},
+	}
+	for _, tt := range tests {
+		logN, r, p := scryptParams(tt.ops, tt.mem)
+		if logN != tt.logN || r != tt.r || p != tt.p {
+			t.Errorf("%d/%d: N=2^%d r=%d p=%d", tt.ops, tt.mem, logN, r, p)
+		}
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	sig := string(readFile(t, "message.txt.minisig"))
+	for _, s := range []string{
+		"",
+		strings.Replace(sig, "trusted comment: ", "comment: ", 1),
+		strings.Replace(sig, "RUQB", "RXXB", 1),
+		sig + "extra\n",
+	} {
+		if _, err := ParseSignature([]byte(s)); err == nil {
+			t.Errorf("%q: 应解析失败", s)
+		}
+	}
+	if _, err := ParsePublicKey([]byte("untrusted comment: x\nAAAA\n")); !errors.Is(err, ErrMalformedKey) {
+		t.Errorf("公钥: %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package modes
+
+import "crypto/cipher"
+
+// NonceAEAD 是带固定nonce长度的认证加密模式，GCM和GCMSIV都实现了该接口
+type NonceAEAD interface {
+	Seal(nonce, plaintext, additionalData []byte) ([]byte, error)
+	Open(nonce, ciphertext, additionalData []byte) ([]byte, error)
+	NonceSize() int
+	Overhead() int
+}
+
+// appendAEAD 是可以将输出直接追加到调用方缓冲区的NonceAEAD，例如GCM
+type appendAEAD interface {
+	AppendSeal(dst, nonce, plaintext, additionalData []byte) ([]byte, error)
+	AppendOpen(dst, nonce, ciphertext, additionalData []byte) ([]byte, error)
+}
+
+// aead 将NonceAEAD适配为crypto/cipher.AEAD
+type aead struct {
+	mode NonceAEAD
+}
+
+// NewAEAD 将GCM、GCMSIV等认证加密模式包装为crypto/cipher.AEAD，
+// 可直接替换标准库的AEAD实现。与标准库一致：
+// Seal将密文追加到dst之后返回，nonce长度错误时panic；
+// Open先完成认证再输出明文，失败时返回ErrAuthFailed且不修改dst的内容
+func NewAEAD(mode NonceAEAD) cipher.AEAD {
+	return &aead{mode: mode}
+}
+
+func (a *aead) NonceSize() int {
+	return a.mode.NonceSize()
+}
+
+func (a *aead) Overhead() int {
+	return a.mode.Overhead()
+}
+
+func (a *aead) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != a.mode.NonceSize() {
+		panic("modes: incorrect nonce length")
+	}
+	if m, ok := a.mode.(appendAEAD); ok {
+		ret, err := m.AppendSeal(dst, nonce, plaintext, additionalData)
+		if err != nil {
+			panic(err)
+		}
+		return ret
+	}
+	sealed, err := a.mode.Seal(nonce, plaintext, additionalData)
+	if err != nil {
+		// nonce长度已检查，此处只可能是底层分组密码的内部错误
+		panic(err)
+	}
+	// 密文先写入独立的缓冲区再复制，因此dst与plaintext重叠（包括原地加密）也是安全的
+	ret, out := sliceForAppend(dst, len(sealed))
+	copy(out, sealed)
+	return ret
+}
+
+func (a *aead) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != a.mode.NonceSize() {
+		panic("modes: incorrect nonce length")
+	}
+	if len(ciphertext) < a.mode.Overhead() {
+		return nil, ErrAuthFailed
+	}
+	if m, ok := a.mode.(appendAEAD); ok {
+		ret, err := m.AppendOpen(dst, nonce, ciphertext, additionalData)
+		if err != nil {
+			return nil, ErrAuthFailed
+		}
+		return ret, nil
+	}
+
+	plaintext, err := a.mode.Open(nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, ErrAuthFailed
+	}
+	ret, out := sliceForAppend(dst, len(plaintext))
+	copy(out, plaintext)
+	clear(plaintext)
+	return ret, nil
+}
+
+// sliceForAppend 扩展in以容纳n个字节，返回扩展后的切片和新增的部分
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
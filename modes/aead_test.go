@@ -0,0 +1,88 @@
+package modes
+
+import (
+	"bytes"
+	stdaes "crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"testing"
+
+	"github.com/laenix/gsc/aes"
+)
+
+// 测试适配后的GCM与标准库AES-GCM输出一致，且遵循dst追加语义
+func TestAEADMatchesStdlib(t *testing.T) {
+	key := decodeHex(t, "feffe9928665731c6d6a8f9467308308")
+	nonce := decodeHex(t, "cafebabefacedbaddecaf888")
+	aad := []byte("header")
+	plaintext := []byte("drop-in replacement for crypto/cipher")
+
+	block, err := aes.New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var a cipher.AEAD = NewAEAD(gcm)
+
+	stdBlock, _ := stdaes.NewCipher(key)
+	std, _ := cipher.NewGCM(stdBlock)
+
+	prefix := []byte("prefix")
+	sealed := a.Seal(bytes.Clone(prefix), nonce, plaintext, aad)
+	if want := std.Seal(bytes.Clone(prefix), nonce, plaintext, aad); !bytes.Equal(sealed, want) {
+		t.Fatalf("Seal结果与标准库不一致:\n期望值: %x\n实际值: %x", want, sealed)
+	}
+
+	opened, err := a.Open(bytes.Clone(prefix), nonce, sealed[len(prefix):], aad)
+	if err != nil {
+		t.Fatalf("Open失败: %v", err)
+	}
+	if !bytes.Equal(opened, append(bytes.Clone(prefix), plaintext...)) {
+		t.Fatalf("Open结果不匹配: %q", opened)
+	}
+
+	// 原地加密和解密
+	buf := make([]byte, len(plaintext), len(plaintext)+a.Overhead())
+	copy(buf, plaintext)
+	inPlace := a.Seal(buf[:0], nonce, buf, aad)
+	if !bytes.Equal(inPlace, sealed[len(prefix):]) {
+		t.Fatal("原地加密结果不一致")
+	}
+	if out, err := a.Open(inPlace[:0], nonce, inPlace, aad); err != nil || !bytes.Equal(out, plaintext) {
+		t.Fatalf("原地解密失败: %v", err)
+	}
+}
+
+func TestAEADOpenFailure(t *testing.T) {
+	gcmSIV, err := NewGCMSIV(make([]byte, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewAEAD(gcmSIV)
+	nonce := make([]byte, a.NonceSize())
+
+	sealed := a.Seal(nil, nonce, []byte("message"), nil)
+	sealed[0] ^= 0x01
+
+	dst := []byte("dst")
+	out, err := a.Open(dst, nonce, sealed, nil)
+	if !errors.Is(err, ErrAuthFailed) || out != nil {
+		t.Fatalf("期望ErrAuthFailed且不返回明文，实际: %q, %v", out, err)
+	}
+	if string(dst) != "dst" {
+		t.Fatalf("失败时dst被修改: %q", dst)
+	}
+	if _, err := a.Open(nil, nonce, sealed[:3], nil); !errors.Is(err, ErrAuthFailed) {
+		t.Fatalf("密文过短时期望ErrAuthFailed，实际: %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("nonce长度错误时期望panic")
+		}
+	}()
+	a.Seal(nil, nonce[:8], nil, nil)
+}
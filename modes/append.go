@@ -0,0 +1,13 @@
+package modes
+
+import "github.com/laenix/gsc/modes/internal"
+
+// appendOutput 在dst之后预留n个字节的输出空间，约定与标准库的AEAD.Seal相同：
+// 输出可以与in完全重叠（原地处理，dst传in[:0]）或完全不重叠，部分重叠时panic
+func appendOutput(dst, in []byte, n int) (ret, out []byte) {
+	ret, out = sliceForAppend(dst, n)
+	if internal.InexactOverlap(out[:min(n, len(in))], in) {
+		panic("modes: invalid buffer overlap")
+	}
+	return ret, out
+}
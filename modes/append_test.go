@@ -0,0 +1,154 @@
+package modes
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/laenix/gsc/aes"
+)
+
+// appendMode 是具有Append变体的模式，每个用例返回新的实例以避免触发密钥流重用检测
+type appendMode struct {
+	name          string
+	new           func() appendCrypter
+	dataSize      int
+	allocsAllowed float64
+}
+
+type appendCrypter interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	AppendEncrypt(dst, plaintext []byte) ([]byte, error)
+	AppendDecrypt(dst, ciphertext []byte) ([]byte, error)
+}
+
+func appendModes(t *testing.T) []appendMode {
+	block, err := aes.New(make([]byte, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	iv := make([]byte, 16)
+	for i := range iv {
+		iv[i] = byte(i)
+	}
+	return []appendMode{
+		{"ECB", func() appendCrypter { c, _ := NewECB(block, AllowInsecure()); return c }, 160, 0},
+		{"CBC", func() appendCrypter { c, _ := NewCBC(block, iv); return c }, 160, 0},
+		{"CTR", func() appendCrypter { c, _ := NewCTR(block, iv); return c }, 157, 1},
+		{"OFB", func() appendCrypter { c, _ := NewOFB(block, iv); return c }, 157, 1},
+		{"CFB", func() appendCrypter { c, _ := NewCFB(block, iv); return c }, 157, 3},
+		{"CFB8", func() appendCrypter { c, _ := NewCFB(block, iv); c, _ = c.WithSegmentSize(1); return c }, 157, 3},
+	}
+}
+
+// 测试Append变体的结果追加在dst之后，且支持原地处理
+func TestAppendModes(t *testing.T) {
+	for _, m := range appendModes(t) {
+		plaintext := make([]byte, m.dataSize)
+		for i := range plaintext {
+			plaintext[i] = byte(i * 7)
+		}
+		want, err := m.new().Encrypt(plaintext)
+		if err != nil {
+			t.Fatalf("%s: %v", m.name, err)
+		}
+
+		prefix := []byte("prefix")
+		got, err := m.new().AppendEncrypt(bytes.Clone(prefix), plaintext)
+		if err != nil {
+			t.Fatalf("%s: %v", m.name, err)
+		}
+		if !bytes.Equal(got, append(bytes.Clone(prefix), want...)) {
+			t.Fatalf("%s: AppendEncrypt的结果与Encrypt不一致", m.name)
+		}
+
+		// 原地加密再原地解密
+		buf := bytes.Clone(plaintext)
+		out, err := m.new().AppendEncrypt(buf[:0], buf)
+		if err != nil {
+			t.Fatalf("%s: %v", m.name, err)
+		}
+		if &out[0] != &buf[0] || !bytes.Equal(out, want) {
+			t.Fatalf("%s: 原地加密的结果不正确", m.name)
+		}
+		out, err = m.new().AppendDecrypt(buf[:0], buf)
+		if err != nil {
+			t.Fatalf("%s: %v", m.name, err)
+		}
+		if !bytes.Equal(out, plaintext) {
+			t.Fatalf("%s: 原地解密未能还原明文", m.name)
+		}
+
+		// dst容量足够时不为结果分配内存
+		dst := make([]byte, 0, m.dataSize)
+		c := m.new()
+		allocs := testing.AllocsPerRun(10, func() {
+			if _, err := c.AppendDecrypt(dst, want); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if allocs > m.allocsAllowed {
+			t.Errorf("%s: AppendDecrypt分配了%v次内存，预期不超过%v次", m.name, allocs, m.allocsAllowed)
+		}
+	}
+}
+
+// 测试输出与输入部分重叠时panic
+func TestAppendInexactOverlap(t *testing.T) {
+	for _, m := range appendModes(t) {
+		buf := make([]byte, m.dataSize+16)
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: 部分重叠时应panic", m.name)
+				}
+			}()
+			m.new().AppendEncrypt(buf[:1], buf[16:])
+		}()
+	}
+}
+
+// 测试GCM的AppendSeal和AppendOpen
+func TestGCMAppend(t *testing.T) {
+	block, _ := aes.New(make([]byte, 16))
+	gcm, err := NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	plaintext := []byte("zero-allocation sealing in place")
+	aad := []byte("header")
+
+	want, err := gcm.Seal(nonce, plaintext, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, len(plaintext), len(plaintext)+gcm.Overhead())
+	copy(buf, plaintext)
+	sealed, err := gcm.AppendSeal(buf[:0], nonce, buf, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if &sealed[0] != &buf[0] || !bytes.Equal(sealed, want) {
+		t.Fatal("原地AppendSeal的结果不正确")
+	}
+
+	opened, err := gcm.AppendOpen(sealed[:0], nonce, sealed, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Fatal("原地AppendOpen未能还原明文")
+	}
+
+	// 认证失败时不写入dst的剩余容量
+	sealed, _ = gcm.AppendSeal(nil, nonce, plaintext, aad)
+	sealed[0] ^= 1
+	dst := make([]byte, 0, len(plaintext))
+	if _, err := gcm.AppendOpen(dst, nonce, sealed, aad); err != ErrAuthFailed {
+		t.Fatalf("篡改的密文应返回ErrAuthFailed，实际: %v", err)
+	}
+	if !bytes.Equal(dst[:cap(dst)], make([]byte, cap(dst))) {
+		t.Fatal("认证失败时不应写入输出")
+	}
+}
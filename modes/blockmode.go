@@ -0,0 +1,146 @@
+package modes
+
+import (
+	"crypto/cipher"
+
+	"github.com/laenix/gsc/modes/internal"
+)
+
+// CTR和OFB的XORKeyStream满足crypto/cipher.Stream
+var (
+	_ cipher.Stream = (*CTR)(nil)
+	_ cipher.Stream = (*OFB)(nil)
+)
+
+// checkBlocks 检查CryptBlocks的输入输出，与标准库一致，不满足要求时panic
+func checkBlocks(blockSize int, dst, src []byte) {
+	if len(src)%blockSize != 0 {
+		panic("modes: input not full blocks")
+	}
+	if len(dst) < len(src) {
+		panic("modes: output smaller than input")
+	}
+}
+
+// Encrypter 返回实现crypto/cipher.BlockMode的CBC加密器
+// 与标准库一致，链接状态在多次CryptBlocks调用之间延续；每次调用Encrypter都从IV重新开始
+func (c *CBC) Encrypter() cipher.BlockMode {
+	return &cbcEncrypter{cipher: c.cipher, prev: internal.DuplicateSlice(c.iv)}
+}
+
+// Decrypter 返回实现crypto/cipher.BlockMode的CBC解密器，约定与Encrypter相同
+func (c *CBC) Decrypter() cipher.BlockMode {
+	return &cbcDecrypter{cipher: c.cipher, prev: internal.DuplicateSlice(c.iv)}
+}
+
+type cbcEncrypter struct {
+	cipher BlockCipher
+	prev   []byte
+}
+
+func (x *cbcEncrypter) BlockSize() int { return len(x.prev) }
+
+func (x *cbcEncrypter) CryptBlocks(dst, src []byte) {
+	blockSize := len(x.prev)
+	checkBlocks(blockSize, dst, src)
+
+	block := make([]byte, blockSize)
+	for i := 0; i < len(src); i += blockSize {
+		internal.XORBytes(block, src[i:i+blockSize], x.prev)
+		copy(x.prev, mustEncrypt(x.cipher, block))
+		copy(dst[i:i+blockSize], x.prev)
+	}
+}
+
+type cbcDecrypter struct {
+	cipher BlockCipher
+	prev   []byte
+}
+
+func (x *cbcDecrypter) BlockSize() int { return len(x.prev) }
+
+func (x *cbcDecrypter) CryptBlocks(dst, src []byte) {
+	blockSize := len(x.prev)
+	checkBlocks(blockSize, dst, src)
+
+	// 原地解密时写入dst会覆盖密文，先将一批密文保存下来作为链接值
+	saved := make([]byte, min(len(src), batchBlocks*blockSize))
+	for len(src) > 0 {
+		n := copy(saved, src)
+		if err := decryptBlocks(x.cipher, dst[:n], saved[:n]); err != nil {
+			panic(err)
+		}
+		internal.XORBytes(dst, dst[:blockSize], x.prev)
+		for i := blockSize; i < n; i += blockSize {
+			internal.XORBytes(dst[i:], dst[i:i+blockSize], saved[i-blockSize:i])
+		}
+		copy(x.prev, saved[n-blockSize:n])
+		dst, src = dst[n:], src[n:]
+	}
+}
+
+// Encrypter 返回实现crypto/cipher.BlockMode的ECB加密器
+// 严格策略下未显式允许ECB时，CryptBlocks会以ErrInsecureMode panic
+func (e *ECB) Encrypter() cipher.BlockMode {
+	return &ecbBlockMode{ecb: e, encrypt: true}
+}
+
+// Decrypter 返回实现crypto/cipher.BlockMode的ECB解密器，约定与Encrypter相同
+func (e *ECB) Decrypter() cipher.BlockMode {
+	return &ecbBlockMode{ecb: e}
+}
+
+type ecbBlockMode struct {
+	ecb     *ECB
+	encrypt bool
+}
+
+func (x *ecbBlockMode) BlockSize() int { return x.ecb.BlockSize() }
+
+func (x *ecbBlockMode) CryptBlocks(dst, src []byte) {
+	if err := x.ecb.checkPolicy(); err != nil {
+		panic(err)
+	}
+	blockSize := x.ecb.BlockSize()
+	checkBlocks(blockSize, dst, src)
+
+	var err error
+	if x.encrypt {
+		err = encryptBlocks(x.ecb.cipher, dst[:len(src)], src)
+	} else {
+		err = decryptBlocks(x.ecb.cipher, dst[:len(src)], src)
+	}
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Encrypter 返回实现crypto/cipher.Stream的CFB加密流，段大小与当前实例相同
+// 返回的流拥有独立的状态，从IV开始，不影响本实例的Encrypt和EncryptStream
+func (c *CFB) Encrypter() cipher.Stream {
+	return &cfbStream{cfb: c.fresh(), encrypt: true}
+}
+
+// Decrypter 返回实现crypto/cipher.Stream的CFB解密流，约定与Encrypter相同
+func (c *CFB) Decrypter() cipher.Stream {
+	return &cfbStream{cfb: c.fresh()}
+}
+
+// fresh 返回参数相同、流式状态为初始状态的副本
+func (c *CFB) fresh() *CFB {
+	return &CFB{
+		cipher:      c.cipher,
+		iv:          internal.DuplicateSlice(c.iv),
+		segmentSize: c.segmentSize,
+		bitMode:     c.bitMode,
+	}
+}
+
+type cfbStream struct {
+	cfb     *CFB
+	encrypt bool
+}
+
+func (s *cfbStream) XORKeyStream(dst, src []byte) {
+	s.cfb.xorStream(dst, src, s.encrypt)
+}
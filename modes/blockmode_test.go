@@ -0,0 +1,105 @@
+package modes
+
+import (
+	"bytes"
+	stdaes "crypto/aes"
+	"crypto/cipher"
+	"io"
+	"testing"
+
+	"github.com/laenix/gsc/aes"
+)
+
+// 测试CBC的BlockMode适配与标准库一致，并在多次CryptBlocks之间延续链接状态
+func TestCBCBlockMode(t *testing.T) {
+	key := decodeHex(t, "2b7e151628aed2a6abf7158809cf4f3c")
+	iv := decodeHex(t, "000102030405060708090a0b0c0d0e0f")
+	plaintext := bytes.Repeat([]byte("sixteen byte blk"), 4)
+
+	block, _ := aes.New(key)
+	cbc, err := NewCBC(block, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdBlock, _ := stdaes.NewCipher(key)
+	want := make([]byte, len(plaintext))
+	cipher.NewCBCEncrypter(stdBlock, iv).CryptBlocks(want, plaintext)
+
+	var enc cipher.BlockMode = cbc.Encrypter()
+	got := make([]byte, len(plaintext))
+	enc.CryptBlocks(got[:16], plaintext[:16])
+	enc.CryptBlocks(got[16:], plaintext[16:])
+	if !bytes.Equal(got, want) {
+		t.Fatalf("CBC加密结果与标准库不一致:\n期望值: %x\n实际值: %x", want, got)
+	}
+
+	// 原地解密
+	dec := cbc.Decrypter()
+	dec.CryptBlocks(got[:32], got[:32])
+	dec.CryptBlocks(got[32:], got[32:])
+	if !bytes.Equal(got, plaintext) {
+		t.Fatal("CBC原地解密结果不匹配")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("输入不是整块时期望panic")
+		}
+	}()
+	enc.CryptBlocks(got, got[:15])
+}
+
+func TestECBBlockMode(t *testing.T) {
+	block, _ := aes.New(make([]byte, 16))
+	ecb, _ := NewECB(block, AllowInsecure())
+	plaintext := bytes.Repeat([]byte{0x5a}, 48)
+
+	want, err := ecb.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := bytes.Clone(plaintext)
+	ecb.Encrypter().CryptBlocks(got, got)
+	if !bytes.Equal(got, want) {
+		t.Fatal("ECB加密结果不一致")
+	}
+	ecb.Decrypter().CryptBlocks(got, got)
+	if !bytes.Equal(got, plaintext) {
+		t.Fatal("ECB解密结果不匹配")
+	}
+}
+
+// 测试CFB流与标准库一致，CTR可直接用于cipher.StreamReader
+func TestStreamInterfaces(t *testing.T) {
+	key := decodeHex(t, "2b7e151628aed2a6abf7158809cf4f3c")
+	iv := decodeHex(t, "000102030405060708090a0b0c0d0e0f")
+	plaintext := []byte("gsc modes used through crypto/cipher.Stream")
+
+	block, _ := aes.New(key)
+	stdBlock, _ := stdaes.NewCipher(key)
+
+	cfb, _ := NewCFB(block, iv)
+	got := make([]byte, len(plaintext))
+	cfb.Encrypter().XORKeyStream(got, plaintext)
+	want := make([]byte, len(plaintext))
+	//lint:ignore SA1019 仅用于对照测试
+	cipher.NewCFBEncrypter(stdBlock, iv).XORKeyStream(want, plaintext)
+	if !bytes.Equal(got, want) {
+		t.Fatalf("CFB结果与标准库不一致:\n期望值: %x\n实际值: %x", want, got)
+	}
+	cfb.Decrypter().XORKeyStream(got, got)
+	if !bytes.Equal(got, plaintext) {
+		t.Fatal("CFB解密结果不匹配")
+	}
+
+	ctr, _ := NewCTR(block, iv)
+	r := cipher.StreamReader{S: ctr, R: bytes.NewReader(plaintext)}
+	ciphertext, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cipher.NewCTR(stdBlock, iv).XORKeyStream(want, plaintext)
+	if !bytes.Equal(ciphertext, want) {
+		t.Fatal("CTR结果与标准库不一致")
+	}
+}
@@ -0,0 +1,42 @@
+package modes
+
+// MultiBlockCipher 是可以一次处理多个块的分组密码
+// ECB、CBC解密和CTR等可以批量处理的模式在分组密码实现了该接口时自动使用它，
+// 从而摊薄每次调用的开销，并让实现有机会流水线或向量化地并行处理多个块
+type MultiBlockCipher interface {
+	BlockCipher
+	// EncryptBlocks 加密src中的所有块并写入dst，dst与src可以是同一切片
+	EncryptBlocks(dst, src []byte) error
+	// DecryptBlocks 解密src中的所有块并写入dst，dst与src可以是同一切片
+	DecryptBlocks(dst, src []byte) error
+}
+
+// batchBlocks 是需要临时缓冲区的模式（如CTR）每批交给分组密码处理的块数
+const batchBlocks = 32
+
+// encryptBlocks 加密src中的所有块，分组密码不支持批量处理时逐块调用Encrypt
+func encryptBlocks(cipher BlockCipher, dst, src []byte) error {
+	if m, ok := cipher.(MultiBlockCipher); ok {
+		return m.EncryptBlocks(dst, src)
+	}
+	return eachBlock(cipher.BlockSize(), dst, src, cipher.Encrypt)
+}
+
+// decryptBlocks 解密src中的所有块，分组密码不支持批量处理时逐块调用Decrypt
+func decryptBlocks(cipher BlockCipher, dst, src []byte) error {
+	if m, ok := cipher.(MultiBlockCipher); ok {
+		return m.DecryptBlocks(dst, src)
+	}
+	return eachBlock(cipher.BlockSize(), dst, src, cipher.Decrypt)
+}
+
+func eachBlock(blockSize int, dst, src []byte, fn func([]byte) ([]byte, error)) error {
+	for i := 0; i < len(src); i += blockSize {
+		block, err := fn(src[i : i+blockSize])
+		if err != nil {
+			return err
+		}
+		copy(dst[i:i+blockSize], block)
+	}
+	return nil
+}
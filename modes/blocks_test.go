@@ -0,0 +1,109 @@
+package modes
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/laenix/gsc/aes"
+	"github.com/laenix/gsc/blowfish"
+	"github.com/laenix/gsc/des"
+	"github.com/laenix/gsc/rc5"
+	"github.com/laenix/gsc/sm4"
+	"github.com/laenix/gsc/twofish"
+)
+
+// singleBlock 隐藏分组密码的批量接口，使模式走逐块处理的路径
+type singleBlock struct{ BlockCipher }
+
+// 测试各分组密码的批量接口与逐块处理的结果相同，包括原地处理
+func TestMultiBlockCiphers(t *testing.T) {
+	aesCipher, _ := aes.New(make([]byte, 16))
+	desCipher, _ := des.New(make([]byte, 8))
+	tdesCipher, _ := des.NewTripleDES([]byte("0123456789abcdefFEDCBA98"))
+	sm4Cipher, _ := sm4.New(make([]byte, 16))
+	blowfishCipher, _ := blowfish.New(make([]byte, 16))
+	twofishCipher, _ := twofish.New(make([]byte, 16))
+	rc5Cipher, _ := rc5.New(make([]byte, 16))
+
+	for name, c := range map[string]MultiBlockCipher{
+		"AES":      aesCipher,
+		"DES":      desCipher,
+		"3DES":     tdesCipher,
+		"SM4":      sm4Cipher,
+		"Blowfish": blowfishCipher,
+		"Twofish":  twofishCipher,
+		"RC5":      rc5Cipher,
+	} {
+		blockSize := c.BlockSize()
+		src := make([]byte, 9*blockSize)
+		for i := range src {
+			src[i] = byte(i * 13)
+		}
+
+		want := make([]byte, len(src))
+		if err := eachBlock(blockSize, want, src, c.Encrypt); err != nil {
+			t.Fatal(err)
+		}
+		buf := bytes.Clone(src)
+		if err := c.EncryptBlocks(buf, buf); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !bytes.Equal(buf, want) {
+			t.Fatalf("%s: 批量加密与逐块加密的结果不同", name)
+		}
+		if err := c.DecryptBlocks(buf, buf); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !bytes.Equal(buf, src) {
+			t.Fatalf("%s: 批量解密未能还原明文", name)
+		}
+		if err := c.EncryptBlocks(buf, src[:blockSize+1]); err == nil {
+			t.Fatalf("%s: 长度不是块大小的整数倍时应返回错误", name)
+		}
+	}
+}
+
+// 测试使用批量接口的模式与逐块处理的结果相同，数据量超过一批以覆盖分批边界
+func TestModesUseMultiBlock(t *testing.T) {
+	block, _ := aes.New(make([]byte, 16))
+	iv := make([]byte, 16)
+	iv[15] = 0xf0
+	data := make([]byte, 16*(2*batchBlocks+3))
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+
+	for _, c := range []struct {
+		name string
+		run  func(BlockCipher) []byte
+	}{
+		{"ECB", func(b BlockCipher) []byte {
+			ecb, _ := NewECB(b, AllowInsecure())
+			out, _ := ecb.Encrypt(data)
+			return out
+		}},
+		{"CBC-Decrypt", func(b BlockCipher) []byte {
+			cbc, _ := NewCBC(b, iv)
+			out, _ := cbc.Decrypt(data)
+			return out
+		}},
+		{"CTR", func(b BlockCipher) []byte {
+			ctr, _ := NewCTR(b, iv)
+			out, _ := ctr.Encrypt(data[:len(data)-5])
+			return out
+		}},
+		{"CBC-Decrypter", func(b BlockCipher) []byte {
+			cbc, _ := NewCBC(b, iv)
+			out := bytes.Clone(data)
+			d := cbc.Decrypter()
+			d.CryptBlocks(out[:48], out[:48])
+			d.CryptBlocks(out[48:], out[48:])
+			return out
+		}},
+	} {
+		got, want := c.run(block), c.run(singleBlock{block})
+		if len(want) == 0 || !bytes.Equal(got, want) {
+			t.Errorf("%s: 使用批量接口的结果与逐块处理不同", c.name)
+		}
+	}
+}
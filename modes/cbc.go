@@ -1,10 +1,13 @@
 package modes
 
 import (
+	"bytes"
+
 	"github.com/laenix/gsc/modes/internal"
 )
 
 // CBC 结构体实现了密码块链接(CBC)模式
+// Encrypt和Decrypt只读取IV，可以被多个goroutine并发调用；Reset会修改IV，不能与其他方法并发
 type CBC struct {
 	cipher BlockCipher
 	iv     []byte
@@ -27,8 +30,30 @@ func NewCBC(cipher BlockCipher, iv []byte) (*CBC, error) {
 	}, nil
 }
 
+// Reset 将IV更换为iv，使实例可以复用于下一条消息而无需重新创建
+// iv长度必须等于块大小，否则返回ErrInvalidIV且实例保持不变
+func (c *CBC) Reset(iv []byte) error {
+	if len(iv) != len(c.iv) {
+		return ErrInvalidIV
+	}
+	copy(c.iv, iv)
+	return nil
+}
+
+// Clone 返回与c共享分组密码、IV独立的副本
+// 分组密码创建后只读，多个goroutine可以各自持有一个副本并用Reset设置自己的IV
+func (c *CBC) Clone() *CBC {
+	return &CBC{cipher: c.cipher, iv: bytes.Clone(c.iv)}
+}
+
 // Encrypt 使用CBC模式加密数据（不含填充，要求输入长度为块大小的整数倍）
 func (c *CBC) Encrypt(plaintext []byte) ([]byte, error) {
+	return c.AppendEncrypt(nil, plaintext)
+}
+
+// AppendEncrypt 与Encrypt相同，但将密文追加到dst之后返回
+// dst容量足够时不为结果分配内存；传入plaintext[:0]可以原地加密
+func (c *CBC) AppendEncrypt(dst, plaintext []byte) ([]byte, error) {
 	blockSize := c.cipher.BlockSize()
 
 	// 验证明文长度是否为块大小的整数倍
@@ -36,36 +61,34 @@ func (c *CBC) Encrypt(plaintext []byte) ([]byte, error) {
 		return nil, ErrInvalidDataSize
 	}
 
-	// 初始化向量
-	prev := make([]byte, blockSize)
-	copy(prev, c.iv)
-
-	ciphertext := make([]byte, len(plaintext))
+	ret, ciphertext := appendOutput(dst, plaintext, len(plaintext))
 
 	// 逐块加密
+	prev := c.iv
 	for i := 0; i < len(plaintext); i += blockSize {
 		// 1. 明文块与前一个密文块（或初始向量）异或
-		block := make([]byte, blockSize)
+		block := ciphertext[i : i+blockSize]
 		internal.XORBytes(block, plaintext[i:i+blockSize], prev)
 
-		// 2. 加密结果
-		encryptedBlock, err := c.cipher.Encrypt(block)
-		if err != nil {
+		// 2. 在输出位置上原地加密，结果即为下一块的链接值
+		if err := encryptBlocks(c.cipher, block, block); err != nil {
 			return nil, err
 		}
-
-		// 3. 复制结果到密文输出
-		copy(ciphertext[i:i+blockSize], encryptedBlock)
-
-		// 4. 更新前一个块
-		copy(prev, encryptedBlock)
+		prev = block
 	}
 
-	return ciphertext, nil
+	return ret, nil
 }
 
 // Decrypt 使用CBC模式解密数据（不移除填充，要求输入长度为块大小的整数倍）
+// CBC加密存在链式依赖只能顺序进行，解密则可以并行
 func (c *CBC) Decrypt(ciphertext []byte) ([]byte, error) {
+	return c.AppendDecrypt(nil, ciphertext)
+}
+
+// AppendDecrypt 与Decrypt相同，但将明文追加到dst之后返回，约定与AppendEncrypt相同
+// 原地解密时每块仍需要前一个密文块，因此会先复制一份密文
+func (c *CBC) AppendDecrypt(dst, ciphertext []byte) ([]byte, error) {
 	blockSize := c.cipher.BlockSize()
 
 	// 验证密文长度是否为块大小的整数倍
@@ -73,28 +96,44 @@ func (c *CBC) Decrypt(ciphertext []byte) ([]byte, error) {
 		return nil, ErrInvalidDataSize
 	}
 
-	// 初始化向量
-	prev := make([]byte, blockSize)
-	copy(prev, c.iv)
-
-	plaintext := make([]byte, len(ciphertext))
+	ret, plaintext := appendOutput(dst, ciphertext, len(ciphertext))
+	if internal.AnyOverlap(plaintext, ciphertext) {
+		ciphertext = internal.DuplicateSlice(ciphertext)
+	}
 
-	// 逐块解密
-	for i := 0; i < len(ciphertext); i += blockSize {
-		// 1. 解密当前密文块
-		decryptedBlock, err := c.cipher.Decrypt(ciphertext[i : i+blockSize])
-		if err != nil {
-			return nil, err
-		}
+	// 解密时每块只依赖前一个密文块，数据较大时由多个协程并行处理
+	var err error
+	if blocks := len(ciphertext) / blockSize; useParallel(len(ciphertext)) {
+		err = parallelBlocks(blocks, func(start, end int) error {
+			return c.decryptRange(plaintext, ciphertext, start*blockSize, end*blockSize)
+		})
+	} else {
+		err = c.decryptRange(plaintext, ciphertext, 0, len(ciphertext))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
 
-		// 2. 将解密结果与前一个密文块（或初始向量）异或
-		internal.XORBytes(plaintext[i:i+blockSize], decryptedBlock, prev)
+// decryptRange 解密ciphertext[lo:hi]中的块并写入plaintext的相同位置
+func (c *CBC) decryptRange(plaintext, ciphertext []byte, lo, hi int) error {
+	blockSize := c.cipher.BlockSize()
 
-		// 3. 更新前一个块
-		copy(prev, ciphertext[i:i+blockSize])
+	// 1. 批量解密密文块
+	if err := decryptBlocks(c.cipher, plaintext[lo:hi], ciphertext[lo:hi]); err != nil {
+		return err
 	}
 
-	return plaintext, nil
+	// 2. 将解密结果与前一个密文块（或初始向量）异或
+	for i := lo; i < hi; i += blockSize {
+		prev := c.iv
+		if i > 0 {
+			prev = ciphertext[i-blockSize : i]
+		}
+		internal.XORBytes(plaintext[i:i+blockSize], plaintext[i:i+blockSize], prev)
+	}
+	return nil
 }
 
 // BlockSize 返回块大小
@@ -0,0 +1,176 @@
+package modes
+
+import (
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/modes/internal"
+)
+
+// CTSVariant 表示CBC密文窃取的输出格式（NIST SP 800-38A 附录）
+type CTSVariant int
+
+const (
+	// CS1 保持分组顺序，倒数第二个分组被截断：... C(n-1)* || C(n)
+	CS1 CTSVariant = iota + 1
+	// CS2 仅在最后一个分组不完整时交换最后两个分组，数据为分组整数倍时与CBC相同
+	CS2
+	// CS3 总是交换最后两个分组：... C(n) || C(n-1)*，即Kerberos（RFC 3962）使用的格式
+	CS3
+)
+
+// 错误定义
+var (
+	ErrInvalidCTSVariant = gscerr.New(gscerr.ErrParameter, "cbc-cts: invalid ciphertext stealing variant")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidCTSVariant: "cbc-cts: 无效的密文窃取格式",
+	})
+}
+
+// CBCCTS 结构体实现了带密文窃取的CBC模式
+// 明文可以是不小于一个分组的任意长度，密文与明文等长，无需填充
+// 实例创建后只读，可以被多个goroutine并发使用
+type CBCCTS struct {
+	cipher  BlockCipher
+	iv      []byte
+	variant CTSVariant
+}
+
+// NewCBCCTS 创建一个新的CBC密文窃取模式封装器
+func NewCBCCTS(cipher BlockCipher, iv []byte, variant CTSVariant) (*CBCCTS, error) {
+	if variant < CS1 || variant > CS3 {
+		return nil, ErrInvalidCTSVariant
+	}
+	if len(iv) != cipher.BlockSize() {
+		return nil, ErrInvalidIV
+	}
+
+	// 复制iv避免外部修改
+	ivCopy := make([]byte, len(iv))
+	copy(ivCopy, iv)
+
+	return &CBCCTS{
+		cipher:  cipher,
+		iv:      ivCopy,
+		variant: variant,
+	}, nil
+}
+
+// Encrypt 使用CBC密文窃取模式加密数据，输入长度至少为一个分组
+func (c *CBCCTS) Encrypt(plaintext []byte) ([]byte, error) {
+	blockSize := c.cipher.BlockSize()
+	if len(plaintext) < blockSize {
+		return nil, ErrInvalidDataSize
+	}
+
+	// 以零填充到分组整数倍后执行普通CBC加密
+	n := (len(plaintext) + blockSize - 1) / blockSize
+	padded := make([]byte, n*blockSize)
+	copy(padded, plaintext)
+
+	cbc, err := NewCBC(c.cipher, c.iv)
+	if err != nil {
+		return nil, err
+	}
+	full, err := cbc.Encrypt(padded)
+	if err != nil {
+		return nil, err
+	}
+	if n == 1 {
+		return full, nil
+	}
+
+	// 截断倒数第二个密文分组，得到CS1格式
+	tail := len(plaintext) - (n-1)*blockSize
+	ciphertext := make([]byte, 0, len(plaintext))
+	ciphertext = append(ciphertext, full[:(n-2)*blockSize]...)
+	ciphertext = append(ciphertext, full[(n-2)*blockSize:(n-2)*blockSize+tail]...)
+	ciphertext = append(ciphertext, full[(n-1)*blockSize:]...)
+
+	c.swapLast(ciphertext, tail, true)
+	return ciphertext, nil
+}
+
+// Decrypt 使用CBC密文窃取模式解密数据
+func (c *CBCCTS) Decrypt(ciphertext []byte) ([]byte, error) {
+	blockSize := c.cipher.BlockSize()
+	if len(ciphertext) < blockSize {
+		return nil, ErrInvalidDataSize
+	}
+
+	n := (len(ciphertext) + blockSize - 1) / blockSize
+	if n == 1 {
+		cbc, err := NewCBC(c.cipher, c.iv)
+		if err != nil {
+			return nil, err
+		}
+		return cbc.Decrypt(ciphertext)
+	}
+
+	// 先转换为CS1格式
+	tail := len(ciphertext) - (n-1)*blockSize
+	in := make([]byte, len(ciphertext))
+	copy(in, ciphertext)
+	c.swapLast(in, tail, false)
+
+	// 解密最后一个完整分组，恢复被截断的倒数第二个密文分组
+	lastOff := (n-2)*blockSize + tail
+	z, err := c.cipher.Decrypt(in[lastOff:])
+	if err != nil {
+		return nil, err
+	}
+	prevBlock := make([]byte, blockSize)
+	copy(prevBlock, in[(n-2)*blockSize:lastOff])
+	copy(prevBlock[tail:], z[tail:])
+
+	plaintext := make([]byte, len(ciphertext))
+	internal.XORBytes(plaintext[(n-1)*blockSize:], z[:tail], prevBlock[:tail])
+
+	// 其余分组按普通CBC解密
+	rebuilt := make([]byte, 0, (n-1)*blockSize)
+	rebuilt = append(rebuilt, in[:(n-2)*blockSize]...)
+	rebuilt = append(rebuilt, prevBlock...)
+
+	cbc, err := NewCBC(c.cipher, c.iv)
+	if err != nil {
+		return nil, err
+	}
+	head, err := cbc.Decrypt(rebuilt)
+	if err != nil {
+		return nil, err
+	}
+	copy(plaintext, head)
+
+	return plaintext, nil
+}
+
+// BlockSize 返回块大小
+func (c *CBCCTS) BlockSize() int {
+	return c.cipher.BlockSize()
+}
+
+// swapLast 在CS1与CS2/CS3格式之间转换最后两个分组
+// toVariant为true时从CS1转换到c.variant，否则从c.variant转换到CS1
+func (c *CBCCTS) swapLast(data []byte, tail int, toVariant bool) {
+	blockSize := c.cipher.BlockSize()
+	if c.variant == CS1 || (c.variant == CS2 && tail == blockSize) {
+		return
+	}
+
+	start := len(data) - blockSize - tail
+	last := data[start:]
+	swapped := make([]byte, 0, len(last))
+	if toVariant {
+		// C(n-1)* || C(n) -> C(n) || C(n-1)*
+		swapped = append(swapped, last[tail:]...)
+		swapped = append(swapped, last[:tail]...)
+	} else {
+		// C(n) || C(n-1)* -> C(n-1)* || C(n)
+		swapped = append(swapped, last[blockSize:]...)
+		swapped = append(swapped, last[:blockSize]...)
+	}
+	copy(last, swapped)
+}
@@ -0,0 +1,118 @@
+package modes
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/laenix/gsc/aes"
+)
+
+// 测试RFC 3962附录B中的AES CTS测试向量（CS3格式）
+func TestCBCCTSKerberosVectors(t *testing.T) {
+	block, err := aes.New([]byte("chicken teriyaki"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cts, err := NewCBCCTS(block, make([]byte, 16), CS3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		plaintext  string
+		ciphertext string
+	}{
+		{"4920776f756c64206c696b652074686520", "c6353568f2bf8cb4d8a580362da7ff7f97"},
+		{"4920776f756c64206c696b65207468652047656e6572616c20476175277320", "fc00783e0efdb2c1d445d4c8eff7ed2297687268d6ecccc0c07b25e25ecfe5"},
+		{"4920776f756c64206c696b65207468652047656e6572616c2047617527732043", "39312523a78662d5be7fcbcc98ebf5a897687268d6ecccc0c07b25e25ecfe584"},
+	}
+
+	for i, tt := range tests {
+		plaintext := decodeHex(t, tt.plaintext)
+		want := decodeHex(t, tt.ciphertext)
+
+		got, err := cts.Encrypt(plaintext)
+		if err != nil {
+			t.Fatalf("向量%d加密失败: %v", i, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("向量%d密文不匹配:\n期望值: %x\n实际值: %x", i, want, got)
+		}
+
+		decrypted, err := cts.Decrypt(got)
+		if err != nil {
+			t.Fatalf("向量%d解密失败: %v", i, err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Fatalf("向量%d解密结果不匹配", i)
+		}
+	}
+}
+
+// 测试三种格式的往返加解密以及它们之间的关系
+func TestCBCCTSVariants(t *testing.T) {
+	block, err := aes.New(decodeHex(t, "000102030405060708090a0b0c0d0e0f"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	iv := decodeHex(t, "f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+
+	cbc, err := NewCBC(block, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{16, 17, 31, 32, 33, 48, 63} {
+		plaintext := make([]byte, n)
+		for i := range plaintext {
+			plaintext[i] = byte(i * 7)
+		}
+
+		outputs := make(map[CTSVariant][]byte)
+		for _, v := range []CTSVariant{CS1, CS2, CS3} {
+			cts, err := NewCBCCTS(block, iv, v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ct, err := cts.Encrypt(plaintext)
+			if err != nil {
+				t.Fatalf("CS%d长度%d加密失败: %v", v, n, err)
+			}
+			if len(ct) != n {
+				t.Fatalf("CS%d长度%d的密文长度为%d", v, n, len(ct))
+			}
+			pt, err := cts.Decrypt(ct)
+			if err != nil {
+				t.Fatalf("CS%d长度%d解密失败: %v", v, n, err)
+			}
+			if !bytes.Equal(pt, plaintext) {
+				t.Fatalf("CS%d长度%d解密结果不匹配", v, n)
+			}
+			outputs[v] = ct
+		}
+
+		if n%16 == 0 {
+			// 分组整数倍时CS1和CS2与普通CBC相同
+			want, err := cbc.Encrypt(plaintext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(outputs[CS1], want) || !bytes.Equal(outputs[CS2], want) {
+				t.Fatalf("长度%d的CS1/CS2输出与CBC不一致", n)
+			}
+		} else if !bytes.Equal(outputs[CS2], outputs[CS3]) {
+			t.Fatalf("长度%d的CS2与CS3输出不一致", n)
+		}
+	}
+
+	cts, err := NewCBCCTS(block, iv, CS1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cts.Encrypt(make([]byte, 15)); err != ErrInvalidDataSize {
+		t.Fatalf("不足一个分组的数据应返回ErrInvalidDataSize，实际: %v", err)
+	}
+	if _, err := NewCBCCTS(block, iv, CTSVariant(4)); err != ErrInvalidCTSVariant {
+		t.Fatalf("无效格式应返回ErrInvalidCTSVariant，实际: %v", err)
+	}
+}
@@ -1,13 +1,34 @@
 package modes
 
-import "github.com/laenix/gsc/modes/internal"
+import (
+	"bytes"
+
+	"github.com/laenix/gsc/modes/internal"
+)
+
+// CFB常用的段大小（位），对应NIST SP 800-38A中的CFB1、CFB8和CFB128
+const (
+	CFB1   = 1
+	CFB8   = 8
+	CFB128 = 128
+)
 
 // CFB 结构体实现了密码反馈(CFB)模式
+// 实例保存重用检测记录和流式状态，不能被多个goroutine并发使用，需要时用Clone为每个goroutine创建副本
 type CFB struct {
 	cipher BlockCipher
 	iv     []byte
-	// segment size，通常等于blockSize，但CFB模式允许更小的段大小
+	// segment size（字节），通常等于blockSize，但CFB模式允许更小的段大小
 	segmentSize int
+	// bitMode 为true时使用1位反馈（CFB1），逐位处理数据
+	bitMode bool
+	// 以下为EncryptStream/DecryptStream的流式状态，与Encrypt/Decrypt互不影响
+	register  []byte
+	keystream []byte
+	// segment 收集当前段的密文，凑满一段后移入寄存器
+	segment []byte
+	used    int
+	guard   ivGuard
 }
 
 // NewCFB 创建一个新的CFB模式封装器
@@ -28,97 +49,282 @@ func NewCFB(cipher BlockCipher, iv []byte) (*CFB, error) {
 	}, nil
 }
 
-// WithSegmentSize 设置CFB的段大小
+// NewCFB1 创建1位反馈的CFB模式（CFB1），每个字节按从高位到低位的顺序逐位加密
+func NewCFB1(cipher BlockCipher, iv []byte) (*CFB, error) {
+	c, err := NewCFB(cipher, iv)
+	if err != nil {
+		return nil, err
+	}
+	return c.WithSegmentBits(CFB1)
+}
+
+// NewCFB8 创建8位反馈的CFB模式（CFB8）
+func NewCFB8(cipher BlockCipher, iv []byte) (*CFB, error) {
+	c, err := NewCFB(cipher, iv)
+	if err != nil {
+		return nil, err
+	}
+	return c.WithSegmentBits(CFB8)
+}
+
+// WithSegmentSize 设置CFB的段大小（字节）
 func (c *CFB) WithSegmentSize(segmentSize int) (*CFB, error) {
 	if segmentSize <= 0 || segmentSize > c.cipher.BlockSize() {
 		return nil, ErrInvalidBlockSize
 	}
 	c.segmentSize = segmentSize
+	c.bitMode = false
+	c.register = nil
+	c.used = 0
 	return c, nil
 }
 
+// WithSegmentBits 设置CFB的段大小（位），支持1位以及不超过块大小的8的整数倍
+func (c *CFB) WithSegmentBits(bits int) (*CFB, error) {
+	if bits == CFB1 {
+		c.segmentSize = 1
+		c.bitMode = true
+		c.register = nil
+		c.used = 0
+		return c, nil
+	}
+	if bits%8 != 0 {
+		return nil, ErrInvalidBlockSize
+	}
+	return c.WithSegmentSize(bits / 8)
+}
+
+// Reset 将IV更换为iv，并将EncryptStream/EncryptNext等的流式状态重新从iv开始，
+// 段大小保持不变，约定与CTR.Reset相同
+func (c *CFB) Reset(iv []byte) error {
+	if len(iv) != len(c.iv) {
+		return ErrInvalidIV
+	}
+	c.guard.reset(c.iv, iv)
+	copy(c.iv, iv)
+	if c.register != nil {
+		copy(c.register, iv)
+		c.used = 0
+	}
+	return nil
+}
+
+// Clone 返回与c共享分组密码的副本，段大小和流式状态都被复制，约定与CTR.Clone相同
+func (c *CFB) Clone() *CFB {
+	return &CFB{
+		cipher:      c.cipher,
+		iv:          bytes.Clone(c.iv),
+		segmentSize: c.segmentSize,
+		bitMode:     c.bitMode,
+		register:    bytes.Clone(c.register),
+		keystream:   bytes.Clone(c.keystream),
+		segment:     bytes.Clone(c.segment),
+		used:        c.used,
+		guard:       c.guard,
+	}
+}
+
 // Encrypt 使用CFB模式加密数据
+// 每次调用都从IV重新开始，用同一实例加密两条消息会重用密钥流：
+// 严格策略下第二次调用返回ErrKeystreamReuse，否则通过警告钩子提示。
+// 记录型协议需要在多条消息间连续推进反馈寄存器时应使用EncryptNext
 func (c *CFB) Encrypt(plaintext []byte) ([]byte, error) {
-	blockSize := c.cipher.BlockSize()
+	return c.AppendEncrypt(nil, plaintext)
+}
+
+// AppendEncrypt 与Encrypt相同，但将密文追加到dst之后返回
+// dst容量足够时不为结果分配内存；传入plaintext[:0]可以原地加密
+func (c *CFB) AppendEncrypt(dst, plaintext []byte) ([]byte, error) {
+	if err := c.guard.check("CFB"); err != nil {
+		return nil, err
+	}
+	return c.crypt(dst, plaintext, true)
+}
+
+// Decrypt 使用CFB模式解密数据
+func (c *CFB) Decrypt(ciphertext []byte) ([]byte, error) {
+	return c.crypt(nil, ciphertext, false)
+}
 
+// AppendDecrypt 与Decrypt相同，但将明文追加到dst之后返回，约定与AppendEncrypt相同
+func (c *CFB) AppendDecrypt(dst, ciphertext []byte) ([]byte, error) {
+	return c.crypt(dst, ciphertext, false)
+}
+
+// crypt 从IV开始按分段处理数据并追加到dst
+func (c *CFB) crypt(dst, in []byte, encrypt bool) ([]byte, error) {
 	// CFB模式可以处理任意长度的数据，不需要填充
-	ciphertext := make([]byte, len(plaintext))
+	ret, out := appendOutput(dst, in, len(in))
+	if c.bitMode {
+		if err := c.cryptBits(out, in, encrypt); err != nil {
+			return nil, err
+		}
+		return ret, nil
+	}
+	blockSize := c.cipher.BlockSize()
 
 	// 初始化寄存器
-	register := make([]byte, blockSize)
-	copy(register, c.iv)
+	register := internal.DuplicateSlice(c.iv)
+	encrypted := make([]byte, blockSize)
+	// 原地解密时写入输出会覆盖密文，先保存当前分段的密文用于反馈
+	segment := make([]byte, c.segmentSize)
 
 	// 分段处理数据
-	for i := 0; i < len(plaintext); i += c.segmentSize {
+	for i := 0; i < len(in); i += c.segmentSize {
 		// 1. 加密寄存器
-		encrypted, err := c.cipher.Encrypt(register)
-		if err != nil {
+		if err := encryptBlocks(c.cipher, encrypted, register); err != nil {
 			return nil, err
 		}
 
 		// 2. 计算要处理的字节数（处理最后一个不完整的分段）
-		n := c.segmentSize
-		if i+n > len(plaintext) {
-			n = len(plaintext) - i
+		n := min(c.segmentSize, len(in)-i)
+		if !encrypt {
+			copy(segment, in[i:i+n])
 		}
 
-		// 3. 输出 = 明文 XOR 加密后的寄存器
-		internal.XORBytes(ciphertext[i:i+n], plaintext[i:i+n], encrypted[:n])
+		// 3. 输出 = 输入 XOR 加密后的寄存器
+		internal.XORBytes(out[i:i+n], in[i:i+n], encrypted[:n])
+		if encrypt {
+			copy(segment, out[i:i+n])
+		}
 
 		// 4. 更新寄存器 - 移位并添加新的密文
 		if blockSize > c.segmentSize {
 			// 如果分段大小小于块大小，需要移位
 			copy(register, register[c.segmentSize:])
-			copy(register[blockSize-c.segmentSize:], ciphertext[i:i+n])
+			copy(register[blockSize-c.segmentSize:], segment[:n])
 		} else {
 			// 分段大小等于块大小的情况
-			copy(register, ciphertext[i:i+n])
+			copy(register, segment[:n])
 		}
 	}
 
-	return ciphertext, nil
+	return ret, nil
 }
 
-// Decrypt 使用CFB模式解密数据
-func (c *CFB) Decrypt(ciphertext []byte) ([]byte, error) {
-	blockSize := c.cipher.BlockSize()
+// cryptBits 以1位反馈处理数据：每一位与E(寄存器)的最高位异或，
+// 然后寄存器左移一位并在末尾移入该位密文
+func (c *CFB) cryptBits(out, in []byte, encrypt bool) error {
+	register := internal.DuplicateSlice(c.iv)
 
-	// CFB模式可以处理任意长度的数据
-	plaintext := make([]byte, len(ciphertext))
+	for i := range in {
+		b, err := c.cryptByte(register, in[i], encrypt)
+		if err != nil {
+			return err
+		}
+		out[i] = b
+	}
 
-	// 初始化寄存器
-	register := make([]byte, blockSize)
-	copy(register, c.iv)
+	return nil
+}
 
-	// 分段处理数据
-	for i := 0; i < len(ciphertext); i += c.segmentSize {
-		// 1. 加密寄存器
+// cryptByte 以1位反馈处理一个字节，并推进寄存器
+func (c *CFB) cryptByte(register []byte, in byte, encrypt bool) (byte, error) {
+	var out byte
+	for bit := 7; bit >= 0; bit-- {
 		encrypted, err := c.cipher.Encrypt(register)
 		if err != nil {
-			return nil, err
+			return 0, err
 		}
 
-		// 2. 计算要处理的字节数（处理最后一个不完整的分段）
-		n := c.segmentSize
-		if i+n > len(ciphertext) {
-			n = len(ciphertext) - i
+		inBit := in >> bit & 1
+		outBit := inBit ^ encrypted[0]>>7
+		out |= outBit << bit
+
+		// 反馈的总是密文位
+		cipherBit := outBit
+		if !encrypt {
+			cipherBit = inBit
 		}
+		shiftLeft(register, cipherBit)
+	}
+	return out, nil
+}
 
-		// 3. 输出 = 密文 XOR 加密后的寄存器
-		internal.XORBytes(plaintext[i:i+n], ciphertext[i:i+n], encrypted[:n])
+// EncryptStream 以流式方式加密src并写入dst，dst与src可以是同一切片（原地处理）
+// 与Encrypt不同，反馈寄存器在多次调用之间连续推进：分多次处理的结果与一次处理整段数据相同。
+// 首次调用从IV开始；同一实例的流式状态只应用于一个方向；dst长度小于src时panic
+func (c *CFB) EncryptStream(dst, src []byte) {
+	c.xorStream(dst, src, true)
+}
 
-		// 4. 更新寄存器 - 移位并添加新的密文
-		if blockSize > c.segmentSize {
-			// 如果分段大小小于块大小，需要移位
-			copy(register, register[c.segmentSize:])
-			copy(register[blockSize-c.segmentSize:], ciphertext[i:i+n])
+// DecryptStream 以流式方式解密src并写入dst，约定与EncryptStream相同
+func (c *CFB) DecryptStream(dst, src []byte) {
+	c.xorStream(dst, src, false)
+}
+
+// EncryptNext 加密一条记录并返回新的切片，反馈寄存器在多次调用之间连续推进
+// 与EncryptStream共享流式状态：依次对各条记录调用EncryptNext的结果与一次加密全部数据相同
+func (c *CFB) EncryptNext(plaintext []byte) ([]byte, error) {
+	out := make([]byte, len(plaintext))
+	c.xorStream(out, plaintext, true)
+	return out, nil
+}
+
+// DecryptNext 解密一条EncryptNext输出的记录，记录须按加密时的顺序依次传入
+func (c *CFB) DecryptNext(ciphertext []byte) ([]byte, error) {
+	out := make([]byte, len(ciphertext))
+	c.xorStream(out, ciphertext, false)
+	return out, nil
+}
+
+// xorStream 实现EncryptStream和DecryptStream
+func (c *CFB) xorStream(dst, src []byte, encrypt bool) {
+	checkStream(dst, src)
+	if c.register == nil {
+		c.register = internal.DuplicateSlice(c.iv)
+		c.segment = make([]byte, c.segmentSize)
+	}
+
+	blockSize := len(c.register)
+	for i := range src {
+		// 先取出输入字节，原地处理时写入dst会覆盖src
+		in := src[i]
+
+		if c.bitMode {
+			out, err := c.cryptByte(c.register, in, encrypt)
+			if err != nil {
+				panic(err)
+			}
+			dst[i] = out
+			continue
+		}
+
+		if c.used == 0 {
+			c.keystream = mustEncrypt(c.cipher, c.register)
+		}
+		out := in ^ c.keystream[c.used]
+		if encrypt {
+			c.segment[c.used] = out
 		} else {
-			// 分段大小等于块大小的情况
-			copy(register, ciphertext[i:i+n])
+			c.segment[c.used] = in
+		}
+		dst[i] = out
+		c.used++
+
+		// 凑满一段后将该段密文移入寄存器
+		if c.used == c.segmentSize {
+			copy(c.register, c.register[c.segmentSize:])
+			copy(c.register[blockSize-c.segmentSize:], c.segment)
+			c.used = 0
 		}
 	}
+}
 
-	return plaintext, nil
+// shiftLeft 将寄存器整体左移一位，并在最低位移入bit
+func shiftLeft(register []byte, bit byte) {
+	for j := 0; j < len(register)-1; j++ {
+		register[j] = register[j]<<1 | register[j+1]>>7
+	}
+	register[len(register)-1] = register[len(register)-1]<<1 | bit
+}
+
+// SegmentBits 返回段大小（位），如CFB1、CFB8或CFB128
+func (c *CFB) SegmentBits() int {
+	if c.bitMode {
+		return CFB1
+	}
+	return c.segmentSize * 8
 }
 
 // BlockSize 返回块大小
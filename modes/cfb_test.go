@@ -0,0 +1,87 @@
+package modes
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/laenix/gsc/aes"
+)
+
+// 测试NIST SP 800-38A F.3中AES-128的CFB1、CFB8和CFB128测试向量
+func TestCFBSegmentSizes(t *testing.T) {
+	key := decodeHex(t, "2b7e151628aed2a6abf7158809cf4f3c")
+	iv := decodeHex(t, "000102030405060708090a0b0c0d0e0f")
+
+	tests := []struct {
+		bits       int
+		plaintext  string
+		ciphertext string
+	}{
+		// F.3.1：CFB1，16位明文 0110101111000001
+		{CFB1, "6bc1", "68b3"},
+		// F.3.7：CFB8
+		{CFB8, "6bc1bee22e409f96e93d7e117393172aae2d", "3b79424c9c0dd436bace9e0ed4586a4f32b9"},
+		// F.3.13：CFB128
+		{
+			CFB128,
+			"6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710",
+			"3b3fd92eb72dad20333449f8e83cfb4ac8a64537a0b3a93fcde3cdad9f1ce58b26751f67a3cbb140b1808cf187a4f4dfc04b05357c5d1c0eeac4c66f9ff7f2e6",
+		},
+		// 与openssl enc -aes-128-cfb1 的输出对照的多分组数据
+		{
+			CFB1,
+			"6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51",
+			"68b3a264f838f5f8c3101070d1ab4c2e22e7f950383a0b71ade4fad0095cb188",
+		},
+	}
+
+	block, err := aes.New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		cfb, err := NewCFB(block, iv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cfb.WithSegmentBits(tt.bits); err != nil {
+			t.Fatal(err)
+		}
+		if cfb.SegmentBits() != tt.bits {
+			t.Fatalf("SegmentBits返回%d，期望%d", cfb.SegmentBits(), tt.bits)
+		}
+
+		plaintext := decodeHex(t, tt.plaintext)
+		want := decodeHex(t, tt.ciphertext)
+		got, err := cfb.Encrypt(plaintext)
+		if err != nil {
+			t.Fatalf("CFB%d加密失败: %v", tt.bits, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("CFB%d密文不匹配:\n期望值: %x\n实际值: %x", tt.bits, want, got)
+		}
+
+		decrypted, err := cfb.Decrypt(got)
+		if err != nil {
+			t.Fatalf("CFB%d解密失败: %v", tt.bits, err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Fatalf("CFB%d解密结果不匹配", tt.bits)
+		}
+	}
+
+	cfb8, err := NewCFB8(block, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := cfb8.Encrypt(decodeHex(t, "6bc1")); !bytes.Equal(got, decodeHex(t, "3b79")) {
+		t.Fatalf("NewCFB8输出不正确: %x", got)
+	}
+
+	cfb, _ := NewCFB(block, iv)
+	for _, bits := range []int{0, 4, 136} {
+		if _, err := cfb.WithSegmentBits(bits); err != ErrInvalidBlockSize {
+			t.Fatalf("段大小%d位应返回ErrInvalidBlockSize，实际: %v", bits, err)
+		}
+	}
+}
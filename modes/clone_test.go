@@ -0,0 +1,122 @@
+package modes
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/laenix/gsc/aes"
+)
+
+// 测试Clone复制流式状态，且副本与原实例互不影响
+func TestCloneStreamState(t *testing.T) {
+	block, _ := aes.New(make([]byte, 16))
+	iv := make([]byte, 16)
+	data := bytes.Repeat([]byte("clone"), 20)
+
+	type stream interface {
+		XORKeyStream(dst, src []byte)
+	}
+	tests := []struct {
+		name  string
+		new   func() stream
+		clone func(stream) stream
+	}{
+		{"CTR", func() stream { c, _ := NewCTR(block, iv); return c }, func(s stream) stream { return s.(*CTR).Clone() }},
+		{"OFB", func() stream { o, _ := NewOFB(block, iv); return o }, func(s stream) stream { return s.(*OFB).Clone() }},
+	}
+	for _, tt := range tests {
+		orig := tt.new()
+		// 先推进到分组中间，使副本需要复制未用完的密钥流
+		head := make([]byte, 7)
+		orig.XORKeyStream(head, data[:7])
+
+		c := tt.clone(orig)
+		a := make([]byte, len(data)-7)
+		b := make([]byte, len(data)-7)
+		orig.XORKeyStream(a, data[7:])
+		c.XORKeyStream(b, data[7:])
+		if !bytes.Equal(a, b) {
+			t.Fatalf("%s: 副本未从原实例的位置继续", tt.name)
+		}
+
+		want := make([]byte, len(data))
+		tt.new().XORKeyStream(want, data)
+		if !bytes.Equal(append(head, a...), want) {
+			t.Fatalf("%s: 克隆后原实例的密钥流被改变", tt.name)
+		}
+	}
+
+	cfb, _ := NewCFB8(block, iv)
+	head := make([]byte, 5)
+	cfb.EncryptStream(head, data[:5])
+	c := cfb.Clone()
+	a := make([]byte, len(data)-5)
+	b := make([]byte, len(data)-5)
+	cfb.EncryptStream(a, data[5:])
+	c.EncryptStream(b, data[5:])
+	if !bytes.Equal(a, b) {
+		t.Fatal("CFB8: 副本未从原实例的位置继续")
+	}
+}
+
+// 测试副本复制重用检测记录：同一IV下副本再次加密仍被视为重用
+func TestCloneKeepsGuard(t *testing.T) {
+	SetStrictPolicy(true)
+	defer SetStrictPolicy(false)
+
+	block, _ := aes.New(make([]byte, 16))
+	ctr, _ := NewCTR(block, make([]byte, 16))
+	if _, err := ctr.Encrypt([]byte("first")); err != nil {
+		t.Fatal(err)
+	}
+	c := ctr.Clone()
+	if _, err := c.Encrypt([]byte("second")); err != ErrKeystreamReuse {
+		t.Fatalf("期望ErrKeystreamReuse，实际: %v", err)
+	}
+	iv := make([]byte, 16)
+	iv[15] = 1
+	if err := c.Reset(iv); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Encrypt([]byte("second")); err != nil {
+		t.Fatalf("Reset为新IV后应允许加密: %v", err)
+	}
+}
+
+// 测试多个goroutine共享同一AES实例，各自使用CBC和CTR的副本
+func TestCloneConcurrent(t *testing.T) {
+	block, _ := aes.New(make([]byte, 32))
+	cbc, _ := NewCBC(block, make([]byte, 16))
+	ctr, _ := NewCTR(block, make([]byte, 16))
+	plaintext := bytes.Repeat([]byte{0x5a}, 4096)
+
+	wantCBC, _ := cbc.Encrypt(plaintext)
+	wantCTR, _ := ctr.Clone().Encrypt(plaintext)
+
+	var wg sync.WaitGroup
+	errs := make(chan string, 16)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c := cbc.Clone()
+			s := ctr.Clone()
+			for range 20 {
+				if got, _ := c.Encrypt(plaintext); !bytes.Equal(got, wantCBC) {
+					errs <- "CBC副本的加密结果不正确"
+					return
+				}
+				if got, _ := s.Decrypt(wantCTR); !bytes.Equal(got, plaintext) {
+					errs <- "CTR副本的解密结果不正确"
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for msg := range errs {
+		t.Fatal(msg)
+	}
+}
@@ -1,11 +1,21 @@
 package modes
 
-import "github.com/laenix/gsc/modes/internal"
+import (
+	"bytes"
+
+	"github.com/laenix/gsc/modes/internal"
+)
 
 // CTR 结构体实现了计数器(CTR)模式
+// 实例保存重用检测记录和流式状态，不能被多个goroutine并发使用，需要时用Clone为每个goroutine创建副本
 type CTR struct {
 	cipher  BlockCipher
 	counter []byte
+	// 以下为XORKeyStream的流式状态，与Encrypt/Decrypt互不影响
+	streamCounter []byte
+	keystream     []byte
+	used          int
+	guard         ivGuard
 }
 
 // NewCTR 创建一个新的CTR模式封装器
@@ -25,50 +35,143 @@ func NewCTR(cipher BlockCipher, initialCounter []byte) (*CTR, error) {
 	}, nil
 }
 
+// Reset 将初始计数器更换为iv，并将XORKeyStream/Next的流式状态重新从iv开始
+// 换成不同的iv后Encrypt不再被视为重用密钥流；Reset为相同的iv则仍会被检测为重用。
+// iv长度必须等于块大小，否则返回ErrInvalidIV且实例保持不变
+func (c *CTR) Reset(iv []byte) error {
+	if len(iv) != len(c.counter) {
+		return ErrInvalidIV
+	}
+	c.guard.reset(c.counter, iv)
+	copy(c.counter, iv)
+	if c.streamCounter != nil {
+		copy(c.streamCounter, iv)
+		c.used = len(iv)
+	}
+	return nil
+}
+
+// Clone 返回与c共享分组密码的副本，初始计数器、XORKeyStream/Next的流式状态
+// 和重用检测记录都被复制，此后两者互不影响。副本与c处于同一IV下，
+// 用于加密时应先Reset为新的IV，否则仍会被视为重用密钥流
+func (c *CTR) Clone() *CTR {
+	return &CTR{
+		cipher:        c.cipher,
+		counter:       bytes.Clone(c.counter),
+		streamCounter: bytes.Clone(c.streamCounter),
+		keystream:     bytes.Clone(c.keystream),
+		used:          c.used,
+		guard:         c.guard,
+	}
+}
+
 // Encrypt 使用CTR模式加密数据
+// 每次调用都从初始计数器重新开始，用同一实例加密两条消息会重用密钥流：
+// 严格策略下第二次调用返回ErrKeystreamReuse，否则通过警告钩子提示。
+// 记录型协议需要在多条消息间连续推进计数器时应使用Next
 func (c *CTR) Encrypt(plaintext []byte) ([]byte, error) {
-	blockSize := c.cipher.BlockSize()
+	return c.AppendEncrypt(nil, plaintext)
+}
+
+// AppendEncrypt 与Encrypt相同，但将密文追加到dst之后返回
+// dst容量足够时不为结果分配内存；传入plaintext[:0]可以原地加密
+func (c *CTR) AppendEncrypt(dst, plaintext []byte) ([]byte, error) {
+	if err := c.guard.check("CTR"); err != nil {
+		return nil, err
+	}
+	return c.crypt(dst, plaintext)
+}
 
+// crypt 从初始计数器开始生成密钥流，与输入异或后追加到dst
+// 数据较大且有多个CPU时，按块将数据分给多个协程，各协程从相应偏移的计数器开始处理
+func (c *CTR) crypt(dst, plaintext []byte) ([]byte, error) {
 	// CTR模式可以处理任意长度的数据，不需要填充
-	ciphertext := make([]byte, len(plaintext))
-
-	// 复制计数器，避免修改原始计数器
-	counter := make([]byte, blockSize)
-	copy(counter, c.counter)
-
-	// 处理数据
-	for i := 0; i < len(plaintext); {
-		// 1. 加密计数器
-		encryptedCounter, err := c.cipher.Encrypt(counter)
-		if err != nil {
-			return nil, err
-		}
+	ret, ciphertext := appendOutput(dst, plaintext, len(plaintext))
+
+	var err error
+	if useParallel(len(plaintext)) {
+		blockSize := c.cipher.BlockSize()
+		blocks := (len(plaintext) + blockSize - 1) / blockSize
+		err = parallelBlocks(blocks, func(start, end int) error {
+			counter := internal.DuplicateSlice(c.counter)
+			internal.AddCounter(counter, uint64(start))
+			lo, hi := start*blockSize, min(end*blockSize, len(plaintext))
+			return c.cryptBlocks(ciphertext[lo:hi], plaintext[lo:hi], counter)
+		})
+	} else {
+		// 复制计数器，避免修改原始计数器
+		err = c.cryptBlocks(ciphertext, plaintext, internal.DuplicateSlice(c.counter))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
 
-		// 2. 计算要处理的字节数（处理最后一个不完整的块）
-		n := blockSize
-		if i+n > len(plaintext) {
-			n = len(plaintext) - i
+// cryptBlocks 从counter开始依次加密计数器并与src异或后写入dst，会修改counter
+// 每次填入一批计数器块后调用批量接口加密，减少对分组密码的调用次数
+func (c *CTR) cryptBlocks(dst, src, counter []byte) error {
+	blockSize := c.cipher.BlockSize()
+	blocks := (len(src) + blockSize - 1) / blockSize
+	batch := make([]byte, min(blocks, batchBlocks)*blockSize)
+
+	for len(src) > 0 {
+		// 1. 填入一批连续的计数器并加密，得到密钥流
+		n := min(len(batch), (len(src)+blockSize-1)/blockSize*blockSize)
+		for i := 0; i < n; i += blockSize {
+			copy(batch[i:i+blockSize], counter)
+			internal.Increment(counter)
 		}
-
-		// 3. 将加密后的计数器与明文异或
-		for j := 0; j < n; j++ {
-			ciphertext[i+j] = plaintext[i+j] ^ encryptedCounter[j]
+		if err := encryptBlocks(c.cipher, batch[:n], batch[:n]); err != nil {
+			return err
 		}
 
-		// 4. 递增计数器
-		internal.Increment(counter)
-
-		// 5. 更新索引
-		i += n
+		// 2. 将密钥流与输入异或，最后一个块可能不完整
+		m := internal.XORBytes(dst, src, batch[:n])
+		dst, src = dst[m:], src[m:]
 	}
-
-	return ciphertext, nil
+	return nil
 }
 
 // Decrypt 使用CTR模式解密数据（在CTR模式中，解密操作与加密操作相同）
+// 解密不会重用密钥流，因此不受重复加密检测的限制
 func (c *CTR) Decrypt(ciphertext []byte) ([]byte, error) {
 	// 由于CTR模式是将加密后的计数器与数据异或，解密和加密操作相同
-	return c.Encrypt(ciphertext)
+	return c.crypt(nil, ciphertext)
+}
+
+// AppendDecrypt 与Decrypt相同，但将明文追加到dst之后返回，约定与AppendEncrypt相同
+func (c *CTR) AppendDecrypt(dst, ciphertext []byte) ([]byte, error) {
+	return c.crypt(dst, ciphertext)
+}
+
+// Next 处理一条记录并返回新的切片，计数器在多次调用之间连续推进，加密和解密相同
+// 与XORKeyStream共享流式状态：依次对各条记录调用Next的结果与一次处理全部数据相同，
+// 对端以相同的记录顺序调用Next即可解密。同一实例的Next应只用于一个方向
+func (c *CTR) Next(data []byte) ([]byte, error) {
+	out := make([]byte, len(data))
+	c.XORKeyStream(out, data)
+	return out, nil
+}
+
+// XORKeyStream 将src与密钥流异或后写入dst，dst与src可以是同一切片（原地处理）
+// 与Encrypt不同，密钥流位置在多次调用之间连续推进：分多次处理的结果与一次处理整段数据相同。
+// 首次调用从初始计数器开始；dst长度小于src时panic
+func (c *CTR) XORKeyStream(dst, src []byte) {
+	checkStream(dst, src)
+	if c.streamCounter == nil {
+		c.streamCounter = internal.DuplicateSlice(c.counter)
+		c.used = len(c.counter)
+	}
+	for i := range src {
+		if c.used == len(c.streamCounter) {
+			c.keystream = mustEncrypt(c.cipher, c.streamCounter)
+			internal.Increment(c.streamCounter)
+			c.used = 0
+		}
+		dst[i] = src[i] ^ c.keystream[c.used]
+		c.used++
+	}
 }
 
 // BlockSize 返回块大小
@@ -1,62 +1,101 @@
 package modes
 
 // ECB 结构体实现了电子密码本(ECB)模式
+// 实例创建后只读，可以被多个goroutine并发使用
 type ECB struct {
 	cipher BlockCipher
+	// allowInsecure 记录构造时是否显式允许了ECB
+	allowInsecure bool
 }
 
+// ecbWarning 是ECB模式的安全警告
+const ecbWarning = "ECB模式会暴露明文中的重复块，不应用于加密结构化数据"
+
 // NewECB 创建一个新的ECB模式封装器
-func NewECB(cipher BlockCipher) *ECB {
+// 严格策略（SetStrictPolicy）开启时必须传入AllowInsecure()，否则返回ErrInsecureMode；
+// 严格策略开启前构造的未显式允许的实例，在Encrypt/Decrypt时同样返回ErrInsecureMode
+func NewECB(cipher BlockCipher, opts ...Option) (*ECB, error) {
+	o := applyOptions(opts)
+	if !o.allowInsecure {
+		if StrictPolicy() {
+			return nil, ErrInsecureMode
+		}
+		warn("ECB", ecbWarning)
+	}
 	return &ECB{
-		cipher: cipher,
+		cipher:        cipher,
+		allowInsecure: o.allowInsecure,
+	}, nil
+}
+
+// checkPolicy 检查当前策略是否允许使用该实例，用于策略在构造之后才开启的情形
+func (e *ECB) checkPolicy() error {
+	if StrictPolicy() && !e.allowInsecure {
+		return ErrInsecureMode
 	}
+	return nil
 }
 
 // Encrypt 使用ECB模式加密数据（不含填充，要求输入长度为块大小的整数倍）
 // 注意：ECB不安全，不推荐用于生产环境
 func (e *ECB) Encrypt(plaintext []byte) ([]byte, error) {
-	blockSize := e.cipher.BlockSize()
-
-	// 验证明文长度是否为块大小的整数倍
-	if len(plaintext)%blockSize != 0 {
-		return nil, ErrInvalidDataSize
-	}
+	return e.AppendEncrypt(nil, plaintext)
+}
 
-	ciphertext := make([]byte, len(plaintext))
+// Decrypt 使用ECB模式解密数据（不移除填充，要求输入长度为块大小的整数倍）
+func (e *ECB) Decrypt(ciphertext []byte) ([]byte, error) {
+	return e.AppendDecrypt(nil, ciphertext)
+}
 
-	// 逐块加密
-	for i := 0; i < len(plaintext); i += blockSize {
-		block, err := e.cipher.Encrypt(plaintext[i : i+blockSize])
-		if err != nil {
-			return nil, err
-		}
-		copy(ciphertext[i:i+blockSize], block)
-	}
+// AppendEncrypt 与Encrypt相同，但将密文追加到dst之后返回
+// dst容量足够时不为结果分配内存；传入plaintext[:0]可以原地加密
+func (e *ECB) AppendEncrypt(dst, plaintext []byte) ([]byte, error) {
+	return e.crypt(dst, plaintext, encryptBlocks)
+}
 
-	return ciphertext, nil
+// AppendDecrypt 与Decrypt相同，但将明文追加到dst之后返回，约定与AppendEncrypt相同
+func (e *ECB) AppendDecrypt(dst, ciphertext []byte) ([]byte, error) {
+	return e.crypt(dst, ciphertext, decryptBlocks)
 }
 
-// Decrypt 使用ECB模式解密数据（不移除填充，要求输入长度为块大小的整数倍）
-func (e *ECB) Decrypt(ciphertext []byte) ([]byte, error) {
-	blockSize := e.cipher.BlockSize()
+// crypt 用fn处理输入中的所有块并追加到dst，各块互不依赖，数据较大时由多个协程并行处理
+func (e *ECB) crypt(dst, in []byte, fn func(cipher BlockCipher, dst, src []byte) error) ([]byte, error) {
+	if err := e.checkPolicy(); err != nil {
+		return nil, err
+	}
 
-	// 验证密文长度是否为块大小的整数倍
-	if len(ciphertext)%blockSize != 0 {
+	// 验证输入长度是否为块大小的整数倍
+	blockSize := e.cipher.BlockSize()
+	if len(in)%blockSize != 0 {
 		return nil, ErrInvalidDataSize
 	}
 
-	plaintext := make([]byte, len(ciphertext))
+	ret, out := appendOutput(dst, in, len(in))
 
-	// 逐块解密
-	for i := 0; i < len(ciphertext); i += blockSize {
-		block, err := e.cipher.Decrypt(ciphertext[i : i+blockSize])
-		if err != nil {
-			return nil, err
-		}
-		copy(plaintext[i:i+blockSize], block)
+	var err error
+	if useParallel(len(in)) {
+		err = parallelBlocks(len(in)/blockSize, func(start, end int) error {
+			lo, hi := start*blockSize, end*blockSize
+			return fn(e.cipher, out[lo:hi], in[lo:hi])
+		})
+	} else {
+		err = fn(e.cipher, out, in)
 	}
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
 
-	return plaintext, nil
+// DecryptPadded 解密数据并使用unpad移除填充
+// 每次调用都会通过日志钩子输出ECB安全警告，便于在日志中定位仍在使用ECB的数据
+func (e *ECB) DecryptPadded(ciphertext []byte, unpad UnpaddingFunc) ([]byte, error) {
+	plaintext, err := e.Decrypt(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	warn("ECB", ecbWarning)
+	return unpad(plaintext)
 }
 
 // BlockSize 返回块大小
@@ -0,0 +1,67 @@
+package modes
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/laenix/gsc/aes"
+)
+
+func TestECBStrictPolicy(t *testing.T) {
+	block, err := aes.New(make([]byte, 16))
+	if err != nil {
+		t.Fatalf("创建AES实例失败: %v", err)
+	}
+
+	var warnings []string
+	SetWarningHook(func(mode, msg string) {
+		warnings = append(warnings, mode+": "+msg)
+	})
+	SetStrictPolicy(true)
+	defer func() {
+		SetStrictPolicy(false)
+		SetWarningHook(nil)
+	}()
+
+	// 严格策略下构造未显式允许的ECB即返回错误
+	if _, err := NewECB(block); !errors.Is(err, ErrInsecureMode) {
+		t.Fatalf("期望ErrInsecureMode，实际: %v", err)
+	}
+
+	// 策略开启前构造的实例在使用时返回错误
+	SetStrictPolicy(false)
+	early, err := NewECB(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetStrictPolicy(true)
+	if _, err := early.Encrypt(make([]byte, 16)); !errors.Is(err, ErrInsecureMode) {
+		t.Fatalf("期望ErrInsecureMode，实际: %v", err)
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("未显式允许时应输出1条警告，实际: %v", warnings)
+	}
+
+	// 显式允许后可以正常使用，DecryptPadded输出警告
+	ecb, err := NewECB(block, AllowInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := ecb.Encrypt(bytes.Repeat([]byte{0x10}, 16))
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	plaintext, err := ecb.DecryptPadded(ciphertext, func(b []byte) ([]byte, error) {
+		return b[:len(b)-int(b[len(b)-1])], nil
+	})
+	if err != nil {
+		t.Fatalf("解密失败: %v", err)
+	}
+	if len(plaintext) != 0 {
+		t.Fatalf("去除填充后应为空，实际: %x", plaintext)
+	}
+	if len(warnings) != 2 {
+		t.Fatalf("DecryptPadded应输出警告，实际: %v", warnings)
+	}
+}
@@ -0,0 +1,89 @@
+package modes_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/laenix/gsc/aes"
+	"github.com/laenix/gsc/modes"
+	"github.com/laenix/gsc/padding"
+)
+
+// 本例展示CBC模式的完整流程：加密前填充，解密后去除填充
+func ExampleCBC() {
+	key := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	iv := []byte{0x0f, 0x0e, 0x0d, 0x0c, 0x0b, 0x0a, 0x09, 0x08, 0x07, 0x06, 0x05, 0x04, 0x03, 0x02, 0x01, 0x00}
+
+	block, err := aes.New(key)
+	if err != nil {
+		panic(err)
+	}
+	cbc, err := modes.NewCBC(block, iv)
+	if err != nil {
+		panic(err)
+	}
+
+	padded, _ := padding.PKCS7Padding([]byte("Hello, gsc!"), block.BlockSize())
+	ciphertext, err := cbc.Encrypt(padded)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%x\n", ciphertext)
+
+	decrypted, err := cbc.Decrypt(ciphertext)
+	if err != nil {
+		panic(err)
+	}
+	plaintext, err := padding.PKCS7UnPadding(decrypted)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s\n", plaintext)
+	// Output:
+	// 95374a000f0882aafea530c332b25725
+	// Hello, gsc!
+}
+
+// 本例展示GCM的nonce用法：同一密钥下每条消息必须使用不同的nonce，
+// 这里用递增的消息序号作为nonce；nonce无需保密，通常与密文一起传输
+func ExampleGCM() {
+	key := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	block, err := aes.New(key)
+	if err != nil {
+		panic(err)
+	}
+	gcm, err := modes.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+
+	aad := []byte("header")
+	nonce := make([]byte, gcm.NonceSize())
+	var sealed []byte
+	for seq := uint32(1); seq <= 2; seq++ {
+		binary.BigEndian.PutUint32(nonce[8:], seq)
+		sealed, err = gcm.Seal(nonce, []byte("Hello, gsc!"), aad)
+		if err != nil {
+			panic(err)
+		}
+		// 相同的明文在不同nonce下得到不同的密文，末尾16字节为认证标签
+		fmt.Printf("%x\n", sealed)
+	}
+
+	plaintext, err := gcm.Open(nonce, sealed, aad)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s\n", plaintext)
+
+	// AAD或nonce不一致时认证失败，不返回任何明文
+	binary.BigEndian.PutUint32(nonce[8:], 1)
+	_, err = gcm.Open(nonce, sealed, aad)
+	fmt.Println(errors.Is(err, modes.ErrAuthFailed))
+	// Output:
+	// f2b0c30fa2c5ea493d27656ef7d6531c804f3dc60b68ba1baa7d4f
+	// 0ecc523dbd6c574337977f20e9c1648f33cf8d22e6a91519a274a9
+	// Hello, gsc!
+	// true
+}
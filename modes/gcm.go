@@ -1,10 +1,13 @@
 package modes
 
 import (
-	"bytes"
-	"errors"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
 
+	"github.com/laenix/gsc/gscerr"
 	"github.com/laenix/gsc/modes/internal"
+	"github.com/laenix/gsc/subtle"
 )
 
 const (
@@ -12,14 +15,27 @@ const (
 	defaultGCMTagSize = 16
 	// 默认的GCM nonce长度（字节）
 	defaultGCMNonceSize = 12
+	// 密钥承诺值长度（字节）
+	gcmCommitmentSize = 32
 )
 
+// gcmCommitmentLabel 是计算密钥承诺值时使用的固定标签，最后一个字节留作块序号
+var gcmCommitmentLabel = []byte("gsc-gcm-commit\x00\x00")
+
 // GCM 结构体实现了伽罗瓦计数器模式 (GCM)
+// Seal和Open可以被多个goroutine并发调用；With系列方法修改配置，应在共享实例之前调用
 type GCM struct {
-	cipher  BlockCipher
-	tagSize int
+	cipher    BlockCipher
+	tagSize   int
+	nonceSize int
 	// H = cipher(zeros)
 	h []byte
+	// uniformTiming 为true时，Open失败路径也执行完整的解密计算
+	uniformTiming bool
+	// keyCommitment 为true时，密文末尾附加密钥承诺值
+	keyCommitment bool
+	// random 是RandomNonce使用的随机源，为nil时使用crypto/rand
+	random io.Reader
 }
 
 // NewGCM 创建一个新的GCM模式封装器
@@ -29,12 +45,26 @@ func NewGCM(cipher BlockCipher) (*GCM, error) {
 
 // NewGCMWithTagSize 创建一个自定义标签大小的GCM模式封装器
 func NewGCMWithTagSize(cipher BlockCipher, tagSize int) (*GCM, error) {
+	return newGCM(cipher, defaultGCMNonceSize, tagSize)
+}
+
+// NewGCMWithNonceSize 创建一个自定义nonce长度的GCM模式封装器
+// 非12字节的nonce按规范通过GHASH派生初始计数器，仅在需要兼容已有系统时使用
+func NewGCMWithNonceSize(cipher BlockCipher, nonceSize int) (*GCM, error) {
+	return newGCM(cipher, nonceSize, defaultGCMTagSize)
+}
+
+func newGCM(cipher BlockCipher, nonceSize, tagSize int) (*GCM, error) {
 	if tagSize < 4 || tagSize > 16 {
-		return nil, errors.New("gcm: 标签大小必须在4和16之间")
+		return nil, gscerr.New(gscerr.ErrParameter, "gcm: tag size must be between 4 and 16")
+	}
+
+	if nonceSize <= 0 {
+		return nil, ErrInvalidNonce
 	}
 
 	if cipher.BlockSize() != 16 {
-		return nil, errors.New("gcm: 需要块大小为16字节的加密算法")
+		return nil, gscerr.New(gscerr.ErrBlockSize, "gcm: cipher with a 16-byte block is required")
 	}
 
 	// 计算H = E(0)
@@ -45,27 +75,87 @@ func NewGCMWithTagSize(cipher BlockCipher, tagSize int) (*GCM, error) {
 	}
 
 	return &GCM{
-		cipher:  cipher,
-		tagSize: tagSize,
-		h:       h,
+		cipher:    cipher,
+		tagSize:   tagSize,
+		nonceSize: nonceSize,
+		h:         h,
 	}, nil
 }
 
+// WithUniformTiming 设置Open是否使用等量计算模式
+// 开启后，无论nonce、长度还是认证标签校验失败，Open都会完成与成功路径相同的
+// GHASH和CTR计算后再返回错误，用于削弱网络服务中基于响应时间的解密预言攻击
+func (g *GCM) WithUniformTiming(enabled bool) *GCM {
+	g.uniformTiming = enabled
+	return g
+}
+
+// WithKeyCommitment 设置是否在密文末尾附加密钥承诺值
+// 承诺值为使用当前密钥加密两个固定标签块得到的32字节，Open时一并校验。
+// GCM本身不承诺密钥，同一密文可能在多个不同密钥下都通过认证，
+// 在多密钥场景中会被用于分区预言（partitioning oracle）等攻击；
+// 开启后密文只能被生成它的密钥打开。开启与未开启时的密文格式互不兼容
+func (g *GCM) WithKeyCommitment(enabled bool) *GCM {
+	g.keyCommitment = enabled
+	return g
+}
+
+// WithRand 设置RandomNonce使用的随机源，传入nil恢复为crypto/rand
+func (g *GCM) WithRand(random io.Reader) *GCM {
+	g.random = random
+	return g
+}
+
+// RandomNonce 从随机源读取一个NonceSize长度的nonce
+// 随机nonce在同一密钥下的使用次数不应超过2^32次
+func (g *GCM) RandomNonce() ([]byte, error) {
+	random := g.random
+	if random == nil {
+		random = rand.Reader
+	}
+	nonce := make([]byte, g.nonceSize)
+	if _, err := io.ReadFull(random, nonce); err != nil {
+		return nil, err
+	}
+	return nonce, nil
+}
+
+// TagSize 返回认证标签长度（字节）
+func (g *GCM) TagSize() int {
+	return g.tagSize
+}
+
+// KeyCommitment 返回是否启用了密钥承诺
+func (g *GCM) KeyCommitment() bool {
+	return g.keyCommitment
+}
+
 // NonceSize 返回GCM的nonce大小
 func (g *GCM) NonceSize() int {
-	return defaultGCMNonceSize
+	return g.nonceSize
 }
 
-// Overhead 返回额外数据长度（认证标签的长度）
+// Overhead 返回额外数据长度（认证标签及密钥承诺值的长度）
 func (g *GCM) Overhead() int {
+	if g.keyCommitment {
+		return g.tagSize + gcmCommitmentSize
+	}
 	return g.tagSize
 }
 
 // Seal 加密数据并添加认证标签
 func (g *GCM) Seal(nonce, plaintext, additionalData []byte) ([]byte, error) {
-	if len(nonce) != defaultGCMNonceSize {
+	return g.AppendSeal(nil, nonce, plaintext, additionalData)
+}
+
+// AppendSeal 与Seal相同，但将密文和标签追加到dst之后返回
+// dst容量足够时不为结果分配内存；传入plaintext[:0]可以原地加密
+func (g *GCM) AppendSeal(dst, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != g.nonceSize {
 		return nil, ErrInvalidNonce
 	}
+	ret, out := appendOutput(dst, plaintext, len(plaintext)+g.Overhead())
+	ciphertext := out[:len(plaintext)]
 
 	// 1. 派生初始计数器 J0
 	j0 := g.deriveJ0(nonce)
@@ -80,27 +170,68 @@ func (g *GCM) Seal(nonce, plaintext, additionalData []byte) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	if _, err := ctrMode.crypt(ciphertext[:0], plaintext); err != nil {
+		return nil, err
+	}
 
-	ciphertext, err := ctrMode.Encrypt(plaintext)
+	// 4. 计算认证标签
+	tag, err := g.computeTag(j0, additionalData, ciphertext)
 	if err != nil {
 		return nil, err
 	}
 
-	// 4. 计算认证标签
-	tag := g.computeTag(j0, additionalData, ciphertext)
+	// 5. 将认证标签写在密文后
+	copy(out[len(plaintext):], tag[:g.tagSize])
 
-	// 5. 将认证标签追加到密文后
-	return append(ciphertext, tag[:g.tagSize]...), nil
+	// 6. 如开启密钥承诺，再写入承诺值
+	if g.keyCommitment {
+		commitment, err := g.commitment()
+		if err != nil {
+			return nil, err
+		}
+		copy(out[len(plaintext)+g.tagSize:], commitment)
+	}
+
+	return ret, nil
 }
 
 // Open 解密数据并验证认证标签
+// 任何失败（nonce长度错误、密文过短、标签或密钥承诺值不匹配）都只返回ErrAuthFailed，
+// 且不会返回任何部分解密的明文
 func (g *GCM) Open(nonce, ciphertext, additionalData []byte) ([]byte, error) {
-	if len(nonce) != defaultGCMNonceSize {
-		return nil, ErrInvalidNonce
+	return g.AppendOpen(nil, nonce, ciphertext, additionalData)
+}
+
+// AppendOpen 与Open相同，但将明文追加到dst之后返回，约定与AppendSeal相同
+// 默认先验证标签再解密，失败时不写入输出；等量计算模式下失败时输出区域会被清零
+func (g *GCM) AppendOpen(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	overhead := g.Overhead()
+	valid := len(nonce) == g.nonceSize && len(ciphertext) >= overhead
+	if !valid {
+		if !g.uniformTiming {
+			return nil, ErrAuthFailed
+		}
+		// 等量计算模式下使用占位输入走完整个流程
+		nonce = make([]byte, g.nonceSize)
+		if len(ciphertext) < overhead {
+			ciphertext = make([]byte, overhead)
+		}
 	}
 
-	if len(ciphertext) < g.tagSize {
-		return nil, ErrInvalidDataSize
+	// 0. 如开启密钥承诺，分离并校验承诺值
+	if g.keyCommitment {
+		commitStart := len(ciphertext) - gcmCommitmentSize
+		expected, err := g.commitment()
+		if err != nil {
+			return nil, ErrAuthFailed
+		}
+		if subtle.ConstantTimeCompare(expected, ciphertext[commitStart:]) != 1 {
+			if !g.uniformTiming {
+				return nil, ErrAuthFailed
+			}
+			valid = false
+		}
+		ciphertext = ciphertext[:commitStart]
 	}
 
 	// 1. 分离密文和认证标签
@@ -112,11 +243,15 @@ func (g *GCM) Open(nonce, ciphertext, additionalData []byte) ([]byte, error) {
 	j0 := g.deriveJ0(nonce)
 
 	// 3. 计算认证标签
-	expectedTag := g.computeTag(j0, additionalData, actualCiphertext)
+	expectedTag, err := g.computeTag(j0, additionalData, actualCiphertext)
+	if err != nil {
+		return nil, ErrAuthFailed
+	}
 
-	// 4. 验证标签
-	if !bytes.Equal(expectedTag[:g.tagSize], tag) {
-		return nil, ErrTagMismatch
+	// 4. 以常量时间验证标签
+	ok := subtle.ConstantTimeCompare(expectedTag[:g.tagSize], tag) == 1 && valid
+	if !ok && !g.uniformTiming {
+		return nil, ErrAuthFailed
 	}
 
 	// 5. 递增J0得到实际解密用的计数器值
@@ -127,25 +262,31 @@ func (g *GCM) Open(nonce, ciphertext, additionalData []byte) ([]byte, error) {
 	// 6. 使用CTR模式解密密文
 	ctrMode, err := NewCTR(g.cipher, counter)
 	if err != nil {
-		return nil, err
+		return nil, ErrAuthFailed
 	}
 
-	plaintext, err := ctrMode.Decrypt(actualCiphertext)
-	if err != nil {
-		return nil, err
+	ret, plaintext := appendOutput(dst, actualCiphertext, len(actualCiphertext))
+	if _, err := ctrMode.crypt(plaintext[:0], actualCiphertext); err != nil {
+		return nil, ErrAuthFailed
+	}
+
+	// 7. 等量计算模式下，校验失败时擦除已解密的数据
+	if !ok {
+		clear(plaintext)
+		return nil, ErrAuthFailed
 	}
 
-	return plaintext, nil
+	return ret, nil
 }
 
 // Encrypt GCM不直接支持Encrypt/Decrypt，必须使用Seal/Open
 func (g *GCM) Encrypt(plaintext []byte) ([]byte, error) {
-	return nil, errors.New("gcm: 必须通过Seal/Open方法使用GCM模式")
+	return nil, gscerr.New(gscerr.ErrMisuse, "gcm: use Seal/Open with GCM mode")
 }
 
 // Decrypt GCM不直接支持Encrypt/Decrypt，必须使用Seal/Open
 func (g *GCM) Decrypt(ciphertext []byte) ([]byte, error) {
-	return nil, errors.New("gcm: 必须通过Seal/Open方法使用GCM模式")
+	return nil, gscerr.New(gscerr.ErrMisuse, "gcm: use Seal/Open with GCM mode")
 }
 
 // BlockSize 返回块大小
@@ -163,27 +304,40 @@ func (g *GCM) deriveJ0(nonce []byte) []byte {
 		return j0
 	}
 
-	// 否则，使用GHASH计算J0
-	// 注意：在实际实现中，我们应该考虑非12字节nonce的情况
-	// 这里为简化，先假设nonce总是12字节
-	return nil
+	// 否则 J0 = GHASH(H, nonce || 0^(s+64) || [len(nonce)]64)
+	j0 := make([]byte, 16)
+	ghash := internal.NewGHASH(g.h)
+	// Update对不足16字节的最后一块按补0处理
+	ghash.Update(nonce, j0)
+	lengthBlock := make([]byte, 16)
+	binary.BigEndian.PutUint64(lengthBlock[8:], uint64(len(nonce))*8)
+	ghash.Update(lengthBlock, j0)
+	return j0
 }
 
-// computeTag 计算认证标签
-func (g *GCM) computeTag(j0 []byte, aad, ciphertext []byte) []byte {
-	// 对齐AAD为16字节的倍数
-	paddedAAD := internal.DuplicateSlice(aad)
-	if len(paddedAAD)%16 != 0 {
-		padding := make([]byte, 16-(len(paddedAAD)%16))
-		paddedAAD = append(paddedAAD, padding...)
+// commitment 计算密钥承诺值 E(K, label||1) || E(K, label||2)
+func (g *GCM) commitment() ([]byte, error) {
+	out := make([]byte, 0, gcmCommitmentSize)
+	block := make([]byte, 16)
+	for i := byte(1); len(out) < gcmCommitmentSize; i++ {
+		copy(block, gcmCommitmentLabel)
+		block[15] = i
+		c, err := g.cipher.Encrypt(block)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c...)
 	}
+	return out, nil
+}
 
-	// 对齐密文为16字节的倍数
-	paddedCiphertext := internal.DuplicateSlice(ciphertext)
-	if len(paddedCiphertext)%16 != 0 {
-		padding := make([]byte, 16-(len(paddedCiphertext)%16))
-		paddedCiphertext = append(paddedCiphertext, padding...)
+// computeTag 计算认证标签
+func (g *GCM) computeTag(j0 []byte, aad, ciphertext []byte) ([]byte, error) {
+	// 标签 = GHASH(H, A, C) XOR E(K, J0)
+	encryptedJ0, err := g.cipher.Encrypt(j0)
+	if err != nil {
+		return nil, err
 	}
-
-	return internal.GMAC(g.h, j0, paddedAAD, paddedCiphertext)
+	// GMAC内部负责补齐到16字节并按原始长度编码长度块
+	return internal.GMAC(g.h, encryptedJ0, aad, ciphertext), nil
 }
@@ -0,0 +1,256 @@
+package modes
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/laenix/gsc/aes"
+)
+
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("无效的十六进制字符串 %q: %v", s, err)
+	}
+	return b
+}
+
+// newTestCase4 返回GCM规范（McGrew & Viega）中的测试用例4
+func newTestCase4(t *testing.T) (*GCM, []byte, []byte, []byte, []byte) {
+	t.Helper()
+	key := decodeHex(t, "feffe9928665731c6d6a8f9467308308")
+	nonce := decodeHex(t, "cafebabefacedbaddecaf888")
+	aad := decodeHex(t, "feedfacedeadbeeffeedfacedeadbeefabaddad2")
+	plaintext := decodeHex(t, "d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a721c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b39")
+	expected := decodeHex(t, "42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091"+
+		"5bc94fbc3221a5db94fae95ae7121a47")
+
+	block, err := aes.New(key)
+	if err != nil {
+		t.Fatalf("创建AES实例失败: %v", err)
+	}
+	gcm, err := NewGCM(block)
+	if err != nil {
+		t.Fatalf("创建GCM失败: %v", err)
+	}
+	return gcm, nonce, aad, plaintext, expected
+}
+
+// 测试标准测试向量
+func TestGCMVector(t *testing.T) {
+	gcm, nonce, aad, plaintext, expected := newTestCase4(t)
+
+	sealed, err := gcm.Seal(nonce, plaintext, aad)
+	if err != nil {
+		t.Fatalf("Seal失败: %v", err)
+	}
+	if !bytes.Equal(sealed, expected) {
+		t.Fatalf("Seal结果不匹配:\n期望值: %x\n实际值: %x", expected, sealed)
+	}
+
+	opened, err := gcm.Open(nonce, sealed, aad)
+	if err != nil {
+		t.Fatalf("Open失败: %v", err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Fatalf("Open结果不匹配:\n期望值: %x\n实际值: %x", plaintext, opened)
+	}
+}
+
+// 测试所有Open失败路径都返回同一个错误且不返回明文
+func TestGCMOpenUniformErrors(t *testing.T) {
+	for _, uniform := range []bool{false, true} {
+		gcm, nonce, aad, plaintext, _ := newTestCase4(t)
+		gcm.WithUniformTiming(uniform)
+
+		sealed, err := gcm.Seal(nonce, plaintext, aad)
+		if err != nil {
+			t.Fatalf("Seal失败: %v", err)
+		}
+
+		tampered := append([]byte(nil), sealed...)
+		tampered[0] ^= 0x01
+
+		cases := []struct {
+			name       string
+			nonce      []byte
+			ciphertext []byte
+			aad        []byte
+		}{
+			{"篡改密文", nonce, tampered, aad},
+			{"篡改AAD", nonce, sealed, []byte("other")},
+			{"nonce长度错误", nonce[:8], sealed, aad},
+			{"密文过短", nonce, sealed[:4], aad},
+		}
+
+		for _, c := range cases {
+			out, err := gcm.Open(c.nonce, c.ciphertext, c.aad)
+			if !errors.Is(err, ErrAuthFailed) {
+				t.Errorf("uniform=%v %s: 期望ErrAuthFailed，实际: %v", uniform, c.name, err)
+			}
+			if out != nil {
+				t.Errorf("uniform=%v %s: 失败时不应返回明文", uniform, c.name)
+			}
+		}
+	}
+}
+
+// 测试密钥承诺：其他密钥下无法打开，篡改承诺值会导致认证失败
+func TestGCMKeyCommitment(t *testing.T) {
+	gcm, nonce, aad, plaintext, expected := newTestCase4(t)
+	gcm.WithKeyCommitment(true)
+
+	sealed, err := gcm.Seal(nonce, plaintext, aad)
+	if err != nil {
+		t.Fatalf("Seal失败: %v", err)
+	}
+	if len(sealed) != len(plaintext)+gcm.Overhead() {
+		t.Fatalf("密文长度错误: 期望%d，实际%d", len(plaintext)+gcm.Overhead(), len(sealed))
+	}
+	if !bytes.Equal(sealed[:len(expected)], expected) {
+		t.Fatalf("承诺值之前的部分应与普通GCM输出一致")
+	}
+
+	opened, err := gcm.Open(nonce, sealed, aad)
+	if err != nil {
+		t.Fatalf("Open失败: %v", err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Fatalf("Open结果不匹配:\n期望值: %x\n实际值: %x", plaintext, opened)
+	}
+
+	// 不带承诺值的密文不能被开启承诺的实例打开
+	if _, err := gcm.Open(nonce, expected, aad); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("缺少承诺值时期望ErrAuthFailed，实际: %v", err)
+	}
+
+	for _, uniform := range []bool{false, true} {
+		gcm.WithUniformTiming(uniform)
+		tampered := append([]byte(nil), sealed...)
+		tampered[len(tampered)-1] ^= 0x01
+		out, err := gcm.Open(nonce, tampered, aad)
+		if !errors.Is(err, ErrAuthFailed) || out != nil {
+			t.Errorf("uniform=%v 篡改承诺值: 期望ErrAuthFailed且无明文，实际: %v", uniform, err)
+		}
+	}
+
+	otherBlock, err := aes.New(decodeHex(t, "000102030405060708090a0b0c0d0e0f"))
+	if err != nil {
+		t.Fatalf("创建AES实例失败: %v", err)
+	}
+	other, err := NewGCM(otherBlock)
+	if err != nil {
+		t.Fatalf("创建GCM失败: %v", err)
+	}
+	other.WithKeyCommitment(true)
+	if _, err := other.Open(nonce, sealed, aad); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("其他密钥打开时期望ErrAuthFailed，实际: %v", err)
+	}
+}
+
+// 测试参数查询方法
+func TestGCMParams(t *testing.T) {
+	block, err := aes.New(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.KeySize() != 32 || block.Rounds() != 14 {
+		t.Fatalf("AES-256参数不正确: KeySize=%d, Rounds=%d", block.KeySize(), block.Rounds())
+	}
+
+	gcm, err := NewGCMWithTagSize(block, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gcm.TagSize() != 12 || gcm.NonceSize() != 12 || gcm.KeyCommitment() {
+		t.Fatalf("GCM参数不正确: TagSize=%d, NonceSize=%d", gcm.TagSize(), gcm.NonceSize())
+	}
+	if !gcm.WithKeyCommitment(true).KeyCommitment() {
+		t.Fatal("启用密钥承诺后KeyCommitment应返回true")
+	}
+}
+
+// 测试非96位nonce，使用GCM规范中的测试用例5（8字节）和测试用例6（60字节）
+func TestGCMNonceSizes(t *testing.T) {
+	key := decodeHex(t, "feffe9928665731c6d6a8f9467308308")
+	aad := decodeHex(t, "feedfacedeadbeeffeedfacedeadbeefabaddad2")
+	plaintext := decodeHex(t, "d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a721c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b39")
+
+	tests := []struct {
+		nonce    string
+		expected string
+	}{
+		{
+			nonce: "cafebabefacedbad",
+			expected: "61353b4c2806934a777ff51fa22a4755699b2a714fcdc6f83766e5f97b6c742373806900e49f24b22b097544d4896b424989b5e1ebac0f07c23f4598" +
+				"3612d2e79e3b0785561be14aaca2fccb",
+		},
+		{
+			nonce: "9313225df88406e555909c5aff5269aa6a7a9538534f7da1e4c303d2a318a728c3c0c95156809539fcf0e2429a6b525416aedbf5a0de6a57a637b39b",
+			expected: "8ce24998625615b603a033aca13fb894be9112a5c3a211a8ba262a3cca7e2ca701e4a9a4fba43c90ccdcb281d48c7c6fd62875d2aca417034c34aee5" +
+				"619cc5aefffe0bfa462af43c1699d050",
+		},
+	}
+
+	block, err := aes.New(key)
+	if err != nil {
+		t.Fatalf("创建AES实例失败: %v", err)
+	}
+	for _, tt := range tests {
+		nonce := decodeHex(t, tt.nonce)
+		gcm, err := NewGCMWithNonceSize(block, len(nonce))
+		if err != nil {
+			t.Fatalf("创建GCM失败: %v", err)
+		}
+		if gcm.NonceSize() != len(nonce) {
+			t.Fatalf("NonceSize期望%d，实际%d", len(nonce), gcm.NonceSize())
+		}
+
+		sealed, err := gcm.Seal(nonce, plaintext, aad)
+		if err != nil {
+			t.Fatalf("Seal失败: %v", err)
+		}
+		if expected := decodeHex(t, tt.expected); !bytes.Equal(sealed, expected) {
+			t.Fatalf("%d字节nonce的Seal结果不匹配:\n期望值: %x\n实际值: %x", len(nonce), expected, sealed)
+		}
+
+		opened, err := gcm.Open(nonce, sealed, aad)
+		if err != nil || !bytes.Equal(opened, plaintext) {
+			t.Fatalf("%d字节nonce的Open失败: %v", len(nonce), err)
+		}
+		if _, err := gcm.Seal(make([]byte, 12), plaintext, aad); !errors.Is(err, ErrInvalidNonce) {
+			t.Errorf("期望ErrInvalidNonce，实际: %v", err)
+		}
+	}
+
+	if _, err := NewGCMWithNonceSize(block, 0); !errors.Is(err, ErrInvalidNonce) {
+		t.Errorf("期望ErrInvalidNonce，实际: %v", err)
+	}
+}
+
+// 测试RandomNonce使用注入的随机源
+func TestGCMRandomNonce(t *testing.T) {
+	gcm, _, _, _, _ := newTestCase4(t)
+
+	random := bytes.NewReader(bytes.Repeat([]byte{0xab}, 12))
+	nonce, err := gcm.WithRand(random).RandomNonce()
+	if err != nil {
+		t.Fatalf("RandomNonce失败: %v", err)
+	}
+	if !bytes.Equal(nonce, bytes.Repeat([]byte{0xab}, 12)) {
+		t.Fatalf("nonce未从注入的随机源读取: %x", nonce)
+	}
+
+	// 随机源耗尽时返回错误
+	if _, err := gcm.RandomNonce(); err == nil {
+		t.Fatal("随机源耗尽时期望返回错误")
+	}
+
+	// 恢复默认随机源
+	if nonce, err := gcm.WithRand(nil).RandomNonce(); err != nil || len(nonce) != gcm.NonceSize() {
+		t.Fatalf("默认随机源RandomNonce失败: %v", err)
+	}
+}
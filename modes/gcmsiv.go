@@ -0,0 +1,196 @@
+package modes
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+
+	"github.com/laenix/gsc/aes"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/modes/internal"
+)
+
+const (
+	// GCM-SIV的nonce长度（字节）
+	gcmSIVNonceSize = 12
+	// GCM-SIV的认证标签长度（字节）
+	gcmSIVTagSize = 16
+	// 明文和AAD的最大长度（2^36字节）
+	gcmSIVMaxLength = 1 << 36
+)
+
+// GCMSIV 实现了抗nonce误用的AES-GCM-SIV认证加密（RFC 8452）
+// 与GCM不同，重复使用nonce只会暴露两条消息是否相同，不会泄露明文或认证密钥，
+// 适合难以保证nonce唯一的场景。每次调用都会从nonce派生独立的认证和加密密钥
+type GCMSIV struct {
+	// 密钥生成密钥对应的AES实例
+	keyGen *aes.AES
+	// 派生加密密钥的长度，与原始密钥长度相同
+	keySize int
+}
+
+// NewGCMSIV 使用16或32字节的AES密钥创建GCM-SIV实例
+func NewGCMSIV(key []byte) (*GCMSIV, error) {
+	if len(key) != aes.KeySize128 && len(key) != aes.KeySize256 {
+		return nil, gscerr.New(gscerr.ErrKeySize, "gcm-siv: key must be 16 or 32 bytes")
+	}
+
+	keyGen, err := aes.New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GCMSIV{
+		keyGen:  keyGen,
+		keySize: len(key),
+	}, nil
+}
+
+// NonceSize 返回GCM-SIV的nonce大小
+func (g *GCMSIV) NonceSize() int {
+	return gcmSIVNonceSize
+}
+
+// Overhead 返回额外数据长度（认证标签的长度）
+func (g *GCMSIV) Overhead() int {
+	return gcmSIVTagSize
+}
+
+// Seal 加密数据并附加认证标签
+func (g *GCMSIV) Seal(nonce, plaintext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != gcmSIVNonceSize {
+		return nil, ErrInvalidNonce
+	}
+	if uint64(len(plaintext)) > gcmSIVMaxLength || uint64(len(additionalData)) > gcmSIVMaxLength {
+		return nil, ErrDataTooLarge
+	}
+
+	// 1. 从nonce派生本次消息的认证密钥和加密密钥
+	authKey, encCipher, err := g.deriveKeys(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 对明文计算标签（SIV）
+	tag, err := gcmSIVTag(authKey, encCipher, nonce, plaintext, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 以标签作为初始计数器进行CTR加密
+	ciphertext, err := gcmSIVCTR(encCipher, tag, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(ciphertext, tag...), nil
+}
+
+// Open 解密数据并验证认证标签
+// 任何失败都只返回ErrAuthFailed，且不会返回任何解密数据
+func (g *GCMSIV) Open(nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != gcmSIVNonceSize || len(ciphertext) < gcmSIVTagSize {
+		return nil, ErrAuthFailed
+	}
+	if uint64(len(ciphertext)) > gcmSIVMaxLength+gcmSIVTagSize || uint64(len(additionalData)) > gcmSIVMaxLength {
+		return nil, ErrAuthFailed
+	}
+
+	tagStart := len(ciphertext) - gcmSIVTagSize
+	tag := ciphertext[tagStart:]
+
+	authKey, encCipher, err := g.deriveKeys(nonce)
+	if err != nil {
+		return nil, ErrAuthFailed
+	}
+
+	// SIV模式必须先解密才能重新计算标签
+	plaintext, err := gcmSIVCTR(encCipher, tag, ciphertext[:tagStart])
+	if err != nil {
+		return nil, ErrAuthFailed
+	}
+
+	expectedTag, err := gcmSIVTag(authKey, encCipher, nonce, plaintext, additionalData)
+	if err != nil || subtle.ConstantTimeCompare(expectedTag, tag) != 1 {
+		clear(plaintext)
+		return nil, ErrAuthFailed
+	}
+
+	return plaintext, nil
+}
+
+// Encrypt GCM-SIV不直接支持Encrypt/Decrypt，必须使用Seal/Open
+func (g *GCMSIV) Encrypt(plaintext []byte) ([]byte, error) {
+	return nil, gscerr.New(gscerr.ErrMisuse, "gcm-siv: use Seal/Open with GCM-SIV mode")
+}
+
+// Decrypt GCM-SIV不直接支持Encrypt/Decrypt，必须使用Seal/Open
+func (g *GCMSIV) Decrypt(ciphertext []byte) ([]byte, error) {
+	return nil, gscerr.New(gscerr.ErrMisuse, "gcm-siv: use Seal/Open with GCM-SIV mode")
+}
+
+// BlockSize 返回块大小
+func (g *GCMSIV) BlockSize() int {
+	return aes.BlockSize
+}
+
+// deriveKeys 派生消息认证密钥和消息加密密钥
+// 第i个块为 AES(K, LE32(i) || nonce)，每块只取前8字节
+func (g *GCMSIV) deriveKeys(nonce []byte) ([]byte, BlockCipher, error) {
+	blocks := 2 + g.keySize/8
+	material := make([]byte, 0, blocks*8)
+
+	input := make([]byte, 16)
+	copy(input[4:], nonce)
+	for i := 0; i < blocks; i++ {
+		binary.LittleEndian.PutUint32(input[:4], uint32(i))
+		out, err := g.keyGen.Encrypt(input)
+		if err != nil {
+			return nil, nil, err
+		}
+		material = append(material, out[:8]...)
+	}
+
+	encCipher, err := aes.New(material[16:])
+	if err != nil {
+		return nil, nil, err
+	}
+	return material[:16], encCipher, nil
+}
+
+// gcmSIVTag 计算标签：AES(加密密钥, (POLYVAL(AAD || 明文 || 长度块) ⊕ nonce) & ~0x80)
+func gcmSIVTag(authKey []byte, encCipher BlockCipher, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	polyval := internal.NewPOLYVAL(authKey)
+	polyval.Update(additionalData)
+	polyval.Update(plaintext)
+
+	// 长度块为以比特计的AAD和明文长度（小端序）
+	lengthBlock := make([]byte, 16)
+	binary.LittleEndian.PutUint64(lengthBlock[:8], uint64(len(additionalData))*8)
+	binary.LittleEndian.PutUint64(lengthBlock[8:], uint64(len(plaintext))*8)
+	polyval.Update(lengthBlock)
+
+	s := polyval.Sum()
+	internal.XORBytes(s, s, nonce)
+	s[15] &= 0x7f
+
+	return encCipher.Encrypt(s)
+}
+
+// gcmSIVCTR 以标签（最高位置1）为初始计数器执行CTR变换
+// 计数器为前4字节的32位小端整数，溢出时回绕
+func gcmSIVCTR(encCipher BlockCipher, tag, data []byte) ([]byte, error) {
+	counter := make([]byte, 16)
+	copy(counter, tag)
+	counter[15] |= 0x80
+
+	out := make([]byte, len(data))
+	for i := 0; i < len(data); i += 16 {
+		keystream, err := encCipher.Encrypt(counter)
+		if err != nil {
+			return nil, err
+		}
+		internal.XORBytes(out[i:], data[i:], keystream)
+		binary.LittleEndian.PutUint32(counter[:4], binary.LittleEndian.Uint32(counter[:4])+1)
+	}
+	return out, nil
+}
@@ -0,0 +1,88 @@
+package modes
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/laenix/gsc/modes/internal"
+)
+
+// 测试RFC 8452附录A中的POLYVAL示例
+func TestPOLYVAL(t *testing.T) {
+	p := internal.NewPOLYVAL(decodeHex(t, "25629347589242761d31f826ba4b757b"))
+	p.Update(decodeHex(t, "4f4f95668c83dfb6401762bb2d01a262d1a24ddd2721d006bbe45f20d3c9f362"))
+	if got, want := p.Sum(), decodeHex(t, "f7a3b47b846119fae5b7866cf5e5b77e"); !bytes.Equal(got, want) {
+		t.Fatalf("POLYVAL不匹配:\n期望值: %x\n实际值: %x", want, got)
+	}
+}
+
+// 测试RFC 8452附录C中的测试向量
+func TestGCMSIVVectors(t *testing.T) {
+	tests := []struct {
+		key       string
+		nonce     string
+		plaintext string
+		aad       string
+		result    string
+	}{
+		{"01000000000000000000000000000000", "030000000000000000000000", "", "", "dc20e2d83f25705bb49e439eca56de25"},
+		{"01000000000000000000000000000000", "030000000000000000000000", "0100000000000000", "", "b5d839330ac7b786578782fff6013b815b287c22493a364c"},
+		{"01000000000000000000000000000000", "030000000000000000000000", "010000000000000000000000", "", "7323ea61d05932260047d942a4978db357391a0bc4fdec8b0d106639"},
+		{"0100000000000000000000000000000000000000000000000000000000000000", "030000000000000000000000", "", "", "07f5f4169bbf55a8400cd47ea6fd400f"},
+		{"0100000000000000000000000000000000000000000000000000000000000000", "030000000000000000000000", "0100000000000000", "", "c2ef328e5c71c83b843122130f7364b761e0b97427e3df28"},
+		// "Hello world"，AAD为"example"
+		{"ee8e1ed9ff2540ae8f2ba9f50bc2f27c", "752abad3e0afb5f434dc4310", "48656c6c6f20776f726c64", "6578616d706c65", "5d349ead175ef6b1def6fd4fbcdeb7e4793f4a1d7e4faa70100af1"},
+	}
+
+	for i, tt := range tests {
+		siv, err := NewGCMSIV(decodeHex(t, tt.key))
+		if err != nil {
+			t.Fatalf("测试 #%d: 创建GCM-SIV失败: %v", i, err)
+		}
+		nonce := decodeHex(t, tt.nonce)
+		plaintext := decodeHex(t, tt.plaintext)
+		aad := decodeHex(t, tt.aad)
+		want := decodeHex(t, tt.result)
+
+		sealed, err := siv.Seal(nonce, plaintext, aad)
+		if err != nil {
+			t.Fatalf("测试 #%d: Seal失败: %v", i, err)
+		}
+		if !bytes.Equal(sealed, want) {
+			t.Fatalf("测试 #%d: Seal结果不匹配:\n期望值: %x\n实际值: %x", i, want, sealed)
+		}
+
+		opened, err := siv.Open(nonce, sealed, aad)
+		if err != nil {
+			t.Fatalf("测试 #%d: Open失败: %v", i, err)
+		}
+		if !bytes.Equal(opened, plaintext) {
+			t.Fatalf("测试 #%d: Open结果不匹配", i)
+		}
+	}
+}
+
+func TestGCMSIVTamper(t *testing.T) {
+	siv, err := NewGCMSIV(decodeHex(t, "ee8e1ed9ff2540ae8f2ba9f50bc2f27c"))
+	if err != nil {
+		t.Fatalf("创建GCM-SIV失败: %v", err)
+	}
+	nonce := decodeHex(t, "752abad3e0afb5f434dc4310")
+	sealed, err := siv.Seal(nonce, []byte("Hello world"), []byte("example"))
+	if err != nil {
+		t.Fatalf("Seal失败: %v", err)
+	}
+
+	tampered := append([]byte(nil), sealed...)
+	tampered[0] ^= 0x01
+	if out, err := siv.Open(nonce, tampered, []byte("example")); !errors.Is(err, ErrAuthFailed) || out != nil {
+		t.Errorf("篡改密文期望ErrAuthFailed，实际: %v", err)
+	}
+	if _, err := siv.Open(nonce, sealed, []byte("other")); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("篡改AAD期望ErrAuthFailed，实际: %v", err)
+	}
+	if _, err := NewGCMSIV(make([]byte, 24)); err == nil {
+		t.Error("24字节密钥应返回错误")
+	}
+}
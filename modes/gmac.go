@@ -0,0 +1,107 @@
+package modes
+
+import (
+	"encoding/binary"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/modes/internal"
+)
+
+// ErrGMACFinished 表示GMAC已经输出认证码，不能再写入数据
+var ErrGMACFinished = gscerr.New(gscerr.ErrMisuse, "gmac: Write called after Sum, each GMAC instance authenticates a single message")
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrGMACFinished: "gmac: Sum之后不能再写入，每个GMAC实例只认证一条消息",
+	})
+}
+
+// GMAC 增量计算GMAC认证码，即明文为空、消息全部作为附加认证数据的GCM（NIST SP 800-38D）
+//
+// 同一nonce下认证两条不同的消息会泄露GHASH密钥H，因此每个实例只认证一条消息：
+// 没有Reset，第一次调用Sum后Write返回ErrGMACFinished。数据写入时即参与GHASH计算，不在内存中缓存
+type GMAC struct {
+	ghash *internal.GHASH
+	// encryptedJ0 是 E(K, J0)，与GHASH结果异或得到认证码
+	encryptedJ0 []byte
+	y           [16]byte
+	// buf 缓存不足一个块的数据
+	buf [16]byte
+	n   int
+	// length 是已写入的字节数
+	length uint64
+	// tag 在第一次Sum时计算，非nil表示实例已结束
+	tag []byte
+}
+
+// NewGMAC 使用128位分组密码和12字节nonce创建GMAC
+// 同一密钥下nonce绝不能重复使用
+func NewGMAC(cipher BlockCipher, nonce []byte) (*GMAC, error) {
+	gcm, err := NewGCM(cipher)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, ErrInvalidNonce
+	}
+	encryptedJ0, err := cipher.Encrypt(gcm.deriveJ0(nonce))
+	if err != nil {
+		return nil, err
+	}
+	return &GMAC{
+		ghash:       internal.NewGHASH(gcm.h),
+		encryptedJ0: encryptedJ0,
+	}, nil
+}
+
+// Size 返回认证码长度
+func (m *GMAC) Size() int {
+	return defaultGCMTagSize
+}
+
+// BlockSize 返回分组大小
+func (m *GMAC) BlockSize() int {
+	return 16
+}
+
+// Write 添加数据，Sum之后调用返回ErrGMACFinished
+func (m *GMAC) Write(p []byte) (int, error) {
+	if m.tag != nil {
+		return 0, ErrGMACFinished
+	}
+	n := len(p)
+	m.length += uint64(n)
+	if m.n > 0 {
+		k := copy(m.buf[m.n:], p)
+		m.n += k
+		p = p[k:]
+		if m.n < len(m.buf) {
+			return n, nil
+		}
+		m.ghash.Update(m.buf[:], m.y[:])
+		m.n = 0
+	}
+	full := len(p) &^ 15
+	m.ghash.Update(p[:full], m.y[:])
+	m.n = copy(m.buf[:], p[full:])
+	return n, nil
+}
+
+// Sum 将认证码追加到b之后返回
+// 第一次调用后实例结束，再次调用返回同一认证码
+func (m *GMAC) Sum(b []byte) []byte {
+	if m.tag == nil {
+		// Update对不足16字节的最后一块按补0处理
+		m.ghash.Update(m.buf[:m.n], m.y[:])
+		var lengths [16]byte
+		binary.BigEndian.PutUint64(lengths[:8], m.length*8)
+		m.ghash.Update(lengths[:], m.y[:])
+
+		m.tag = make([]byte, defaultGCMTagSize)
+		internal.XORBytes(m.tag, m.y[:], m.encryptedJ0)
+		clear(m.buf[:])
+	}
+	return append(b, m.tag...)
+}
@@ -0,0 +1,98 @@
+package modes
+
+import (
+	"github.com/laenix/gsc/modes/internal"
+)
+
+// IGE 结构体实现了无限错误扩展(Infinite Garble Extension)模式
+// 采用Telegram MTProto的双IV约定：IV长度为两个分组，
+// 前一半作为初始的"前一个密文块"c0，后一半作为初始的"前一个明文块"p0
+//
+//	加密: c[i] = E(p[i] ⊕ c[i-1]) ⊕ p[i-1]
+//	解密: p[i] = D(c[i] ⊕ p[i-1]) ⊕ c[i-1]
+//
+// 实例创建后只读，可以被多个goroutine并发使用
+type IGE struct {
+	cipher BlockCipher
+	iv     []byte
+}
+
+// NewIGE 创建一个新的IGE模式封装器，iv长度必须是块大小的两倍
+func NewIGE(cipher BlockCipher, iv []byte) (*IGE, error) {
+	blockSize := cipher.BlockSize()
+	if len(iv) != 2*blockSize {
+		return nil, ErrInvalidIV
+	}
+
+	// 复制iv避免外部修改
+	ivCopy := make([]byte, len(iv))
+	copy(ivCopy, iv)
+
+	return &IGE{
+		cipher: cipher,
+		iv:     ivCopy,
+	}, nil
+}
+
+// Encrypt 使用IGE模式加密数据（不含填充，要求输入长度为块大小的整数倍）
+func (g *IGE) Encrypt(plaintext []byte) ([]byte, error) {
+	blockSize := g.cipher.BlockSize()
+	if len(plaintext)%blockSize != 0 {
+		return nil, ErrInvalidDataSize
+	}
+
+	prevCipher := g.iv[:blockSize]
+	prevPlain := g.iv[blockSize:]
+
+	ciphertext := make([]byte, len(plaintext))
+	block := make([]byte, blockSize)
+	for i := 0; i < len(plaintext); i += blockSize {
+		p := plaintext[i : i+blockSize]
+		internal.XORBytes(block, p, prevCipher)
+
+		encrypted, err := g.cipher.Encrypt(block)
+		if err != nil {
+			return nil, err
+		}
+		internal.XORBytes(ciphertext[i:i+blockSize], encrypted, prevPlain)
+
+		prevCipher = ciphertext[i : i+blockSize]
+		prevPlain = p
+	}
+
+	return ciphertext, nil
+}
+
+// Decrypt 使用IGE模式解密数据（不移除填充，要求输入长度为块大小的整数倍）
+func (g *IGE) Decrypt(ciphertext []byte) ([]byte, error) {
+	blockSize := g.cipher.BlockSize()
+	if len(ciphertext)%blockSize != 0 {
+		return nil, ErrInvalidDataSize
+	}
+
+	prevCipher := g.iv[:blockSize]
+	prevPlain := g.iv[blockSize:]
+
+	plaintext := make([]byte, len(ciphertext))
+	block := make([]byte, blockSize)
+	for i := 0; i < len(ciphertext); i += blockSize {
+		c := ciphertext[i : i+blockSize]
+		internal.XORBytes(block, c, prevPlain)
+
+		decrypted, err := g.cipher.Decrypt(block)
+		if err != nil {
+			return nil, err
+		}
+		internal.XORBytes(plaintext[i:i+blockSize], decrypted, prevCipher)
+
+		prevCipher = c
+		prevPlain = plaintext[i : i+blockSize]
+	}
+
+	return plaintext, nil
+}
+
+// BlockSize 返回块大小
+func (g *IGE) BlockSize() int {
+	return g.cipher.BlockSize()
+}
@@ -0,0 +1,19 @@
+package internal
+
+import "unsafe"
+
+// AnyOverlap 判断x和y是否共享内存，与标准库crypto/internal/alias相同
+func AnyOverlap(x, y []byte) bool {
+	return len(x) > 0 && len(y) > 0 &&
+		uintptr(unsafe.Pointer(&x[0])) <= uintptr(unsafe.Pointer(&y[len(y)-1])) &&
+		uintptr(unsafe.Pointer(&y[0])) <= uintptr(unsafe.Pointer(&x[len(x)-1]))
+}
+
+// InexactOverlap 判断x和y是否部分重叠：共享内存但起始位置不同
+// 原地处理（x与y起始位置相同）是允许的，部分重叠则会在写入时破坏尚未读取的输入
+func InexactOverlap(x, y []byte) bool {
+	if len(x) == 0 || len(y) == 0 || &x[0] == &y[0] {
+		return false
+	}
+	return AnyOverlap(x, y)
+}
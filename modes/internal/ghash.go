@@ -1,75 +1,112 @@
 package internal
 
+import "encoding/binary"
+
 // GHASH 是GCM模式用于生成认证标签的哈希函数
 type GHASH struct {
 	// H是加密密钥后的值 E(0)
 	h []byte
-	// 预计算的GF(2^128)乘法表
-	// 为简化，我们只实现基础版本，没有使用乘法表优化
+	// productTable[reverseBits(i)] = i·H，i为4位值，用于按4位查表的乘法（Shoup方法）
+	productTable [16]fieldElement
+}
+
+// fieldElement 是GF(2^128)中的元素，low为块的前8字节，high为后8字节（均按大端读取）
+// GCM的位序中low的最高位是x^0的系数
+type fieldElement struct {
+	low, high uint64
 }
 
 // NewGHASH 创建一个新的GHASH实例
 func NewGHASH(h []byte) *GHASH {
 	hCopy := make([]byte, 16)
 	copy(hCopy, h)
-	return &GHASH{
+	g := &GHASH{
 		h: hCopy,
 	}
+
+	// 预计算H的0~15倍：偶数倍由半数倍乘x得到，奇数倍再加H
+	x := fieldElement{binary.BigEndian.Uint64(h[:8]), binary.BigEndian.Uint64(h[8:])}
+	g.productTable[reverseBits(1)] = x
+	for i := 2; i < 16; i += 2 {
+		g.productTable[reverseBits(i)] = double(g.productTable[reverseBits(i/2)])
+		g.productTable[reverseBits(i+1)] = add(g.productTable[reverseBits(i)], x)
+	}
+	return g
 }
 
 // Update 更新GHASH状态
 func (g *GHASH) Update(data []byte, y []byte) {
-	// 确保数据是16字节的倍数（应该由调用者保证）
+	// 不足16字节的最后一块视为右侧补0
 	for i := 0; i < len(data); i += 16 {
 		// 将当前状态与数据块异或
-		for j := 0; j < 16 && i+j < len(data); j++ {
-			if i+j < len(data) {
-				y[j] ^= data[i+j]
-			}
-		}
+		XORBytes(y, y, data[i:min(i+16, len(data))])
 		// 在GF(2^128)上乘以H
 		g.multiply(y)
 	}
 }
 
 // multiply 在GF(2^128)上执行乘法 y = y * H
-// 使用Horner方法计算
+// 每次处理y的4位：累加器乘以x^4（右移4位并用约简表消去溢出的4位），再加上查表得到的倍数
 func (g *GHASH) multiply(y []byte) {
-	// 使用简化的GF(2^128)乘法实现
-	// 在实际生产代码中，应该使用更高效的算法和预计算表
-	var z [16]byte
-	var v [16]byte
-	copy(v[:], g.h)
-
-	for i := 0; i < 16; i++ {
-		for j := uint(0); j < 8; j++ {
-			if (y[i] & (1 << (7 - j))) != 0 {
-				// 如果该位为1，则累加H的相应倍数
-				for k := 0; k < 16; k++ {
-					z[k] ^= v[k]
-				}
-			}
-			// 计算下一个倍数 (v = v * x)
-			bit := v[15] & 1
-			shiftRight(&v)
-			if bit == 1 {
-				v[0] ^= 0xe1 // GF(2^128)多项式的低位系数
-			}
+	if hasGHASHAsm {
+		gcmMulHW(y, g.h)
+		return
+	}
+
+	var z fieldElement
+	// 从x的最高次项开始（即块的最后4位）应用Horner方法
+	for _, word := range [2]uint64{binary.BigEndian.Uint64(y[8:]), binary.BigEndian.Uint64(y[:8])} {
+		for j := 0; j < 64; j += 4 {
+			msw := z.high & 0xf
+			z.high >>= 4
+			z.high |= z.low << 60
+			z.low >>= 4
+			z.low ^= uint64(reductionTable[msw]) << 48
+
+			t := &g.productTable[word&0xf]
+			z.low ^= t.low
+			z.high ^= t.high
+			word >>= 4
 		}
 	}
-	copy(y, z[:])
+	binary.BigEndian.PutUint64(y[:8], z.low)
+	binary.BigEndian.PutUint64(y[8:], z.high)
 }
 
-// shiftRight 将16字节数组向右移动1位
-func shiftRight(v *[16]byte) {
-	for i := 15; i > 0; i-- {
-		v[i] = (v[i] >> 1) | (v[i-1] << 7)
+// reductionTable[i]是右移4位时溢出的4位i（x^128~x^131）约简后的值，位于结果的最高16位
+var reductionTable = [16]uint16{
+	0x0000, 0x1c20, 0x3840, 0x2460, 0x7080, 0x6ca0, 0x48c0, 0x54e0,
+	0xe100, 0xfd20, 0xd940, 0xc560, 0x9180, 0x8da0, 0xa9c0, 0xb5e0,
+}
+
+// reverseBits 翻转4位值的位序
+func reverseBits(i int) int {
+	i = ((i << 2) & 0xc) | ((i >> 2) & 0x3)
+	i = ((i << 1) & 0xa) | ((i >> 1) & 0x5)
+	return i
+}
+
+// add 返回x + y，GF(2^128)中的加法即异或
+func add(x, y fieldElement) fieldElement {
+	return fieldElement{x.low ^ y.low, x.high ^ y.high}
+}
+
+// double 返回x·x：GCM位序下乘以x即整体右移一位，溢出的x^128按x^7+x^2+x+1约简
+func double(x fieldElement) fieldElement {
+	msbSet := x.high&1 == 1
+	d := fieldElement{
+		low:  x.low >> 1,
+		high: x.high>>1 | x.low<<63,
+	}
+	if msbSet {
+		d.low ^= 0xe100000000000000
 	}
-	v[0] >>= 1
+	return d
 }
 
 // GMAC 计算给定数据的认证码
-func GMAC(h, j0 []byte, aad, ciphertext []byte) []byte {
+// encryptedJ0 为加密后的初始计数器块 E(K, J0)
+func GMAC(h, encryptedJ0 []byte, aad, ciphertext []byte) []byte {
 	// 初始化GHASH
 	ghash := NewGHASH(h)
 
@@ -77,25 +114,14 @@ func GMAC(h, j0 []byte, aad, ciphertext []byte) []byte {
 	y := make([]byte, 16)
 
 	// 处理额外认证数据 (AAD)
+	// Update对不足16字节的最后一块按补0处理，无需额外填充
 	if len(aad) > 0 {
 		ghash.Update(aad, y)
-		// 如果aad长度不是16的倍数，需要填充0
-		padding := 16 - (len(aad) % 16)
-		if padding < 16 {
-			padBytes := make([]byte, padding)
-			ghash.Update(padBytes, y)
-		}
 	}
 
 	// 处理密文
 	if len(ciphertext) > 0 {
 		ghash.Update(ciphertext, y)
-		// 如果密文长度不是16的倍数，需要填充0
-		padding := 16 - (len(ciphertext) % 16)
-		if padding < 16 {
-			padBytes := make([]byte, padding)
-			ghash.Update(padBytes, y)
-		}
 	}
 
 	// 添加AAD和密文长度信息（以bit为单位，以big-endian格式存储）
@@ -123,9 +149,9 @@ func GMAC(h, j0 []byte, aad, ciphertext []byte) []byte {
 
 	ghash.Update(lengthBytes, y)
 
-	// 最后与J0异或得到认证标签
+	// 最后与E(K, J0)异或得到认证标签
 	tag := make([]byte, 16)
-	XORBytes(tag, y, j0)
+	XORBytes(tag, y, encryptedJ0)
 
 	return tag
 }
@@ -0,0 +1,17 @@
+//go:build !purego
+
+package internal
+
+import "github.com/laenix/gsc/internal/cpu"
+
+// hasGHASHAsm 记录CPU是否支持PMULL，支持时GHASH使用无进位乘法指令
+var hasGHASHAsm = cpu.ARM64.HasPMULL
+
+// gcmMulAsm 使用PMULL计算y = y * h
+//
+//go:noescape
+func gcmMulAsm(y, h *byte)
+
+func gcmMulHW(y, h []byte) {
+	gcmMulAsm(&y[0], &h[0])
+}
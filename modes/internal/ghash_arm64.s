@@ -0,0 +1,48 @@
+//go:build !purego
+
+#include "textflag.h"
+
+// GCM的位序中首字节的最高位是x^0。每个字节内先做位翻转，再按小端读入，
+// 就得到常规表示：低64位的第i位为x^i的系数。这样可以直接用PMULL做
+// 无进位乘法，并按x^128 = x^7 + x^2 + x + 1 (0x87)约简，最后再翻转回来
+
+// func gcmMulAsm(y, h *byte)
+TEXT ·gcmMulAsm(SB), NOSPLIT, $0-16
+	MOVD y+0(FP), R0
+	MOVD h+8(FP), R1
+
+	VLD1  (R0), [V0.B16]
+	VLD1  (R1), [V1.B16]
+	VRBIT V0.B16, V0.B16
+	VRBIT V1.B16, V1.B16
+	VEOR  V31.B16, V31.B16, V31.B16
+
+	// 128×128位乘法：lo = X0·H0，hi = X1·H1，mid = X0·H1 ⊕ X1·H0
+	VPMULL  V0.D1, V1.D1, V2.Q1
+	VPMULL2 V0.D2, V1.D2, V3.Q1
+	VEXT    $8, V1.B16, V1.B16, V4.B16
+	VPMULL  V0.D1, V4.D1, V5.Q1
+	VPMULL2 V0.D2, V4.D2, V6.Q1
+	VEOR    V5.B16, V6.B16, V5.B16
+
+	// 将mid加到乘积的中间两个64位字：V2 = P1:P0，V3 = P3:P2
+	VEXT $8, V5.B16, V31.B16, V7.B16
+	VEXT $8, V31.B16, V5.B16, V8.B16
+	VEOR V7.B16, V2.B16, V2.B16
+	VEOR V8.B16, V3.B16, V3.B16
+
+	// 约简：P ≡ (P1:P0) ⊕ P2·r ⊕ P3·r·x^64，P3·r超出128位的部分再乘一次r
+	MOVD    $0x87, R2
+	VDUP    R2, V9.D2
+	VPMULL  V3.D1, V9.D1, V10.Q1
+	VPMULL2 V3.D2, V9.D2, V11.Q1
+	VEXT    $8, V31.B16, V11.B16, V12.B16
+	VPMULL  V12.D1, V9.D1, V13.Q1
+	VEXT    $8, V11.B16, V31.B16, V14.B16
+	VEOR    V10.B16, V2.B16, V2.B16
+	VEOR    V13.B16, V2.B16, V2.B16
+	VEOR    V14.B16, V2.B16, V2.B16
+
+	VRBIT V2.B16, V2.B16
+	VST1  [V2.B16], (R0)
+	RET
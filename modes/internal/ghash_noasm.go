@@ -0,0 +1,10 @@
+//go:build !arm64 || purego
+
+package internal
+
+// 没有无进位乘法实现的平台使用通用实现
+const hasGHASHAsm = false
+
+func gcmMulHW(y, h []byte) {
+	panic("ghash: hardware acceleration is not available on this platform")
+}
@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"testing"
+)
+
+// mulBitwise 逐位计算GF(2^128)乘法 y = y * h，作为查表实现的参照
+func mulBitwise(y, h []byte) {
+	var z, v [16]byte
+	copy(v[:], h)
+	for i := range 16 {
+		for j := range 8 {
+			if y[i]&(0x80>>j) != 0 {
+				XORBytes(z[:], z[:], v[:])
+			}
+			bit := v[15] & 1
+			for k := 15; k > 0; k-- {
+				v[k] = v[k]>>1 | v[k-1]<<7
+			}
+			v[0] >>= 1
+			if bit == 1 {
+				v[0] ^= 0xe1
+			}
+		}
+	}
+	copy(y, z[:])
+}
+
+// 测试查表乘法（以及可用时的硬件乘法）与逐位乘法的结果相同
+func TestGHASHMultiply(t *testing.T) {
+	r := rand.New(rand.NewPCG(5, 6))
+	random := func() []byte {
+		b := make([]byte, 16)
+		for i := range b {
+			b[i] = byte(r.Uint32())
+		}
+		return b
+	}
+
+	for range 1000 {
+		h, y := random(), random()
+		want := bytes.Clone(y)
+		mulBitwise(want, h)
+		NewGHASH(h).multiply(y)
+		if !bytes.Equal(y, want) {
+			t.Fatalf("H=%x的乘法结果不匹配:\n期望值: %x\n实际值: %x", h, want, y)
+		}
+	}
+}
+
+func BenchmarkGHASH(b *testing.B) {
+	g := NewGHASH(bytes.Repeat([]byte{0x42}, 16))
+	data := make([]byte, 16<<10)
+	y := make([]byte, 16)
+	b.SetBytes(int64(len(data)))
+	for b.Loop() {
+		g.Update(data, y)
+	}
+}
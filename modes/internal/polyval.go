@@ -0,0 +1,55 @@
+package internal
+
+import "encoding/binary"
+
+// POLYVAL 是AES-GCM-SIV（RFC 8452）使用的通用哈希函数
+// 其域运算与GHASH同构，这里按RFC 8452附录A的方法借助GHASH实现：
+// POLYVAL(H, X_1, ..., X_n) = ByteReverse(GHASH(mulX_GHASH(ByteReverse(H)),
+// ByteReverse(X_1), ..., ByteReverse(X_n)))
+type POLYVAL struct {
+	ghash *GHASH
+	// 以GHASH字节序保存的累加值
+	y [16]byte
+}
+
+// NewPOLYVAL 使用16字节的认证密钥创建POLYVAL实例
+func NewPOLYVAL(h []byte) *POLYVAL {
+	var key [16]byte
+	reverse(key[:], h)
+
+	// mulX_GHASH：在GHASH的比特序下乘以x
+	x := double(fieldElement{binary.BigEndian.Uint64(key[:8]), binary.BigEndian.Uint64(key[8:])})
+	binary.BigEndian.PutUint64(key[:8], x.low)
+	binary.BigEndian.PutUint64(key[8:], x.high)
+
+	return &POLYVAL{ghash: NewGHASH(key[:])}
+}
+
+// Update 处理数据，不足16字节的最后一块右侧补0
+func (p *POLYVAL) Update(data []byte) {
+	var block [16]byte
+	for i := 0; i < len(data); i += 16 {
+		clear(block[:])
+		copy(block[:], data[i:min(i+16, len(data))])
+		reverse(block[:], block[:])
+		p.ghash.Update(block[:], p.y[:])
+	}
+}
+
+// Sum 返回当前的POLYVAL值
+func (p *POLYVAL) Sum() []byte {
+	out := make([]byte, 16)
+	reverse(out, p.y[:])
+	return out
+}
+
+// reverse 将src按字节逆序写入dst，dst与src可以相同
+func reverse(dst, src []byte) {
+	n := len(src)
+	for i := 0; i < n/2; i++ {
+		dst[i], dst[n-1-i] = src[n-1-i], src[i]
+	}
+	if n%2 == 1 {
+		dst[n/2] = src[n/2]
+	}
+}
@@ -20,6 +20,15 @@ func Increment(counter []byte) {
 	}
 }
 
+// AddCounter 将大端计数器加上n，溢出时按计数器长度回绕
+func AddCounter(counter []byte, n uint64) {
+	for i := len(counter) - 1; i >= 0 && n > 0; i-- {
+		sum := uint64(counter[i]) + n&0xff
+		counter[i] = byte(sum)
+		n = n>>8 + sum>>8
+	}
+}
+
 // DuplicateSlice 复制切片
 func DuplicateSlice(src []byte) []byte {
 	dst := make([]byte, len(src))
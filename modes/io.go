@@ -0,0 +1,296 @@
+package modes
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+// AEAD分块流的nonce后缀长度：nonce = 前缀 || 分块序号(4) || 末块标志(1)
+const aeadStreamSuffixSize = 5
+
+// ErrInvalidChunkSize 表示分块大小不是正数
+var ErrInvalidChunkSize = gscerr.New(gscerr.ErrParameter, "invalid chunk size")
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidChunkSize: "无效的分块大小",
+	})
+}
+
+// streamBufferSize 是StreamWriter每次加密并写出的最大字节数
+const streamBufferSize = 32 << 10
+
+// StreamReader 用流式模式处理从底层Reader读出的数据
+// 流式模式的加密与解密通常相同（CTR、OFB），CFB需分别使用Encrypter或Decrypter
+type StreamReader struct {
+	s cipher.Stream
+	r io.Reader
+}
+
+// NewStreamReader 返回读取时用s处理r中数据的StreamReader
+// s可以是CTR、OFB，或CFB的Encrypter/Decrypter返回的流
+func NewStreamReader(s cipher.Stream, r io.Reader) *StreamReader {
+	return &StreamReader{s: s, r: r}
+}
+
+// Read 从底层Reader读取数据并原地处理
+func (r *StreamReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.s.XORKeyStream(p[:n], p[:n])
+	return n, err
+}
+
+// StreamWriter 用流式模式处理数据后写入底层Writer
+type StreamWriter struct {
+	s   cipher.Stream
+	w   io.Writer
+	buf []byte
+	err error
+}
+
+// NewStreamWriter 返回写入时用s处理数据再写入w的StreamWriter，约定与NewStreamReader相同
+func NewStreamWriter(s cipher.Stream, w io.Writer) *StreamWriter {
+	return &StreamWriter{s: s, w: w}
+}
+
+// Write 处理p并写入底层Writer，不修改p的内容
+// 底层写入失败后密钥流位置已无法与对端对齐，后续调用均返回同一错误
+func (w *StreamWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.buf == nil {
+		w.buf = make([]byte, streamBufferSize)
+	}
+
+	n := 0
+	for len(p) > 0 {
+		m := min(len(p), len(w.buf))
+		w.s.XORKeyStream(w.buf[:m], p[:m])
+		written, err := w.w.Write(w.buf[:m])
+		n += written
+		if err == nil && written != m {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			w.err = err
+			return n, err
+		}
+		p = p[m:]
+	}
+	return n, nil
+}
+
+// Close 与crypto/cipher.StreamWriter一致，底层Writer实现了io.Closer时将其关闭
+func (w *StreamWriter) Close() error {
+	if c, ok := w.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// AEADWriter 以STREAM构造分块加密写入的数据
+// 每个分块独立调用Seal，nonce由调用方给出的前缀、分块序号和末块标志组成，
+// 分块被删除、重排或截断都会在AEADReader解密时被发现。输出不含任何头部，
+// 前缀和分块大小需由上层协议传给解密方；同一密钥下每个流必须使用不同的前缀
+type AEADWriter struct {
+	w         io.Writer
+	aead      NonceAEAD
+	prefix    []byte
+	aad       []byte
+	counter   uint32
+	chunkSize int
+	buf       []byte
+	closed    bool
+	err       error
+}
+
+// NewAEADWriter 返回向w写入分块密文的AEADWriter
+// prefix的长度必须为aead.NonceSize()-5，否则返回ErrInvalidNonce；aad绑定到每个分块。
+// 写完后必须调用Close输出末块，否则解密端会将流视为被截断
+func NewAEADWriter(aead NonceAEAD, prefix []byte, w io.Writer, aad []byte, chunkSize int) (*AEADWriter, error) {
+	if err := checkAEADStream(aead, prefix, chunkSize); err != nil {
+		return nil, err
+	}
+	return &AEADWriter{
+		w:         w,
+		aead:      aead,
+		prefix:    append([]byte(nil), prefix...),
+		aad:       append([]byte(nil), aad...),
+		chunkSize: chunkSize,
+		buf:       make([]byte, 0, chunkSize),
+	}, nil
+}
+
+// Write 缓存并加密数据，每凑满一个分块且后续还有数据时写出该分块
+func (s *AEADWriter) Write(p []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	if s.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	n := 0
+	for len(p) > 0 {
+		// 缓冲区已满且还有数据，说明当前分块不是末块
+		if len(s.buf) == s.chunkSize {
+			if err := s.flush(false); err != nil {
+				s.err = err
+				return n, err
+			}
+		}
+		m := min(s.chunkSize-len(s.buf), len(p))
+		s.buf = append(s.buf, p[:m]...)
+		p = p[m:]
+		n += m
+	}
+	return n, nil
+}
+
+// Close 加密并写出末块，不会关闭底层的io.Writer
+func (s *AEADWriter) Close() error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if err := s.flush(true); err != nil {
+		s.err = err
+		return err
+	}
+	clear(s.buf[:cap(s.buf)])
+	return nil
+}
+
+// flush 加密并写出缓冲区中的分块
+func (s *AEADWriter) flush(final bool) error {
+	if !final && s.counter == math.MaxUint32 {
+		return ErrDataTooLarge
+	}
+	sealed, err := s.aead.Seal(aeadStreamNonce(s.prefix, s.counter, final), s.buf, s.aad)
+	if err != nil {
+		return err
+	}
+	if _, err := s.w.Write(sealed); err != nil {
+		return err
+	}
+	s.counter++
+	s.buf = s.buf[:0]
+	return nil
+}
+
+// AEADReader 解密AEADWriter输出的分块密文
+// 每个分块在通过认证后才会返回其明文；只有读到认证通过的末块时Read才返回io.EOF，
+// 流被截断、分块被篡改或重排时返回ErrAuthFailed
+type AEADReader struct {
+	r         io.Reader
+	aead      NonceAEAD
+	prefix    []byte
+	aad       []byte
+	counter   uint32
+	chunkSize int
+	// chunk 容纳一个完整的加密分块及1字节预读，用于判断当前分块是否为末块
+	chunk []byte
+	n     int
+	plain []byte
+	err   error
+}
+
+// NewAEADReader 返回从r解密数据的AEADReader，参数必须与加密时的NewAEADWriter相同
+func NewAEADReader(aead NonceAEAD, prefix []byte, r io.Reader, aad []byte, chunkSize int) (*AEADReader, error) {
+	if err := checkAEADStream(aead, prefix, chunkSize); err != nil {
+		return nil, err
+	}
+	return &AEADReader{
+		r:         r,
+		aead:      aead,
+		prefix:    append([]byte(nil), prefix...),
+		aad:       append([]byte(nil), aad...),
+		chunkSize: chunkSize,
+		chunk:     make([]byte, chunkSize+aead.Overhead()+1),
+	}, nil
+}
+
+// Read 返回已认证的明文
+func (s *AEADReader) Read(p []byte) (int, error) {
+	for len(s.plain) == 0 {
+		if s.err != nil {
+			return 0, s.err
+		}
+		s.err = s.next()
+	}
+	n := copy(p, s.plain)
+	s.plain = s.plain[n:]
+	return n, nil
+}
+
+// next 读取并解密下一个分块，读到末块后返回io.EOF
+func (s *AEADReader) next() error {
+	sealedSize := s.chunkSize + s.aead.Overhead()
+
+	n, err := io.ReadFull(s.r, s.chunk[s.n:])
+	s.n += n
+	final := false
+	switch err {
+	case nil:
+		// 读满了一个分块加1字节预读，说明后面还有数据
+	case io.EOF, io.ErrUnexpectedEOF:
+		final = true
+	default:
+		return err
+	}
+
+	size := sealedSize
+	if final {
+		size = s.n
+	}
+	if !final && s.counter == math.MaxUint32 {
+		return ErrDataTooLarge
+	}
+
+	plain, err := s.aead.Open(aeadStreamNonce(s.prefix, s.counter, final), s.chunk[:size], s.aad)
+	if err != nil {
+		return ErrAuthFailed
+	}
+	s.counter++
+	s.plain = plain
+
+	if final {
+		s.n = 0
+		return io.EOF
+	}
+	s.chunk[0] = s.chunk[sealedSize]
+	s.n = 1
+	return nil
+}
+
+// checkAEADStream 检查分块流的nonce前缀和分块大小
+func checkAEADStream(aead NonceAEAD, prefix []byte, chunkSize int) error {
+	if len(prefix) != aead.NonceSize()-aeadStreamSuffixSize {
+		return ErrInvalidNonce
+	}
+	if chunkSize <= 0 {
+		return ErrInvalidChunkSize
+	}
+	return nil
+}
+
+// aeadStreamNonce 构造分块nonce：前缀 || 分块序号 || 末块标志
+func aeadStreamNonce(prefix []byte, counter uint32, final bool) []byte {
+	nonce := make([]byte, 0, len(prefix)+aeadStreamSuffixSize)
+	nonce = append(nonce, prefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, counter)
+	if final {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
@@ -0,0 +1,139 @@
+package modes
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/laenix/gsc/aes"
+)
+
+// 测试StreamWriter/StreamReader与一次性加密的结果相同，且不修改输入
+func TestStreamReaderWriter(t *testing.T) {
+	block, _ := aes.New(make([]byte, 16))
+	iv := make([]byte, 16)
+	plaintext := make([]byte, streamBufferSize+1000)
+	for i := range plaintext {
+		plaintext[i] = byte(i)
+	}
+	input := bytes.Clone(plaintext)
+
+	ctr, _ := NewCTR(block, iv)
+	var buf bytes.Buffer
+	w := NewStreamWriter(ctr, &buf)
+	if _, err := w.Write(input[:100]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(input[100:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(input, plaintext) {
+		t.Fatal("Write修改了输入")
+	}
+
+	want, _ := ctr.crypt(nil, plaintext)
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatal("StreamWriter的输出与一次性加密不一致")
+	}
+
+	cfb, _ := NewCFB(block, iv)
+	var cfbBuf bytes.Buffer
+	if _, err := io.Copy(NewStreamWriter(cfb.Encrypter(), &cfbBuf), bytes.NewReader(plaintext)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		s    func() io.Reader
+	}{
+		{"CTR", func() io.Reader {
+			dec, _ := NewCTR(block, iv)
+			return NewStreamReader(dec, &buf)
+		}},
+		{"CFB", func() io.Reader { return NewStreamReader(cfb.Decrypter(), &cfbBuf) }},
+	} {
+		got, err := io.ReadAll(tt.s())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Fatalf("%s的StreamReader解密结果不匹配", tt.name)
+		}
+	}
+}
+
+// 测试AEAD分块流的往返，以及截断、重排和篡改检测
+func TestAEADReaderWriter(t *testing.T) {
+	block, _ := aes.New(make([]byte, 16))
+	gcm, err := NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefix := []byte("prefix!")
+	aad := []byte("header")
+	const chunkSize = 16
+
+	seal := func(plaintext []byte) []byte {
+		var buf bytes.Buffer
+		w, err := NewAEADWriter(gcm, prefix, &buf, aad, chunkSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(plaintext); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	open := func(ciphertext []byte) ([]byte, error) {
+		r, err := NewAEADReader(gcm, prefix, bytes.NewReader(ciphertext), aad, chunkSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return io.ReadAll(r)
+	}
+
+	for _, n := range []int{0, 1, 16, 17, 48, 100} {
+		plaintext := bytes.Repeat([]byte{'a'}, n)
+		ciphertext := seal(plaintext)
+		chunks := max(1, (n+chunkSize-1)/chunkSize)
+		if len(ciphertext) != n+chunks*gcm.Overhead() {
+			t.Fatalf("长度%d的密文长度不正确: %d", n, len(ciphertext))
+		}
+		got, err := open(ciphertext)
+		if err != nil {
+			t.Fatalf("长度%d解密失败: %v", n, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Fatalf("长度%d解密结果不匹配", n)
+		}
+	}
+
+	ciphertext := seal(bytes.Repeat([]byte{'a'}, 48))
+	sealedSize := chunkSize + gcm.Overhead()
+	swapped := append(bytes.Clone(ciphertext[sealedSize:2*sealedSize]), ciphertext[:sealedSize]...)
+	swapped = append(swapped, ciphertext[2*sealedSize:]...)
+	tampered := bytes.Clone(ciphertext)
+	tampered[5] ^= 1
+	for name, bad := range map[string][]byte{
+		"截断": ciphertext[:2*sealedSize],
+		"重排": swapped,
+		"篡改": tampered,
+	} {
+		if _, err := open(bad); err != ErrAuthFailed {
+			t.Fatalf("%s的流应返回ErrAuthFailed，实际: %v", name, err)
+		}
+	}
+
+	if _, err := NewAEADWriter(gcm, []byte("short"), io.Discard, nil, chunkSize); err != ErrInvalidNonce {
+		t.Fatalf("前缀长度错误应返回ErrInvalidNonce，实际: %v", err)
+	}
+	if _, err := NewAEADReader(gcm, prefix, nil, nil, 0); err != ErrInvalidChunkSize {
+		t.Fatalf("分块大小为0应返回ErrInvalidChunkSize，实际: %v", err)
+	}
+}
@@ -1,19 +1,41 @@
 package modes
 
-import "errors"
+import (
+	"github.com/laenix/gsc/gscerr"
+
+	"github.com/laenix/gsc/i18n"
+)
 
 // 常见错误
 var (
-	ErrInvalidBlockSize = errors.New("无效的块大小")
-	ErrInvalidDataSize  = errors.New("数据长度必须是块大小的整数倍")
-	ErrInvalidPadding   = errors.New("无效的填充")
-	ErrInvalidIV        = errors.New("无效的初始化向量")
-	ErrInvalidNonce     = errors.New("无效的nonce")
-	ErrDataTooLarge     = errors.New("数据长度超过限制")
-	ErrTagMismatch      = errors.New("认证标签不匹配")
+	ErrInvalidBlockSize = gscerr.New(gscerr.ErrBlockSize, "invalid block size")
+	ErrInvalidDataSize  = gscerr.New(gscerr.ErrParameter, "data length must be a multiple of the block size")
+	ErrInvalidPadding   = gscerr.New(gscerr.ErrPadding, "invalid padding")
+	ErrInvalidIV        = gscerr.New(gscerr.ErrNonceSize, "invalid initialization vector")
+	ErrInvalidNonce     = gscerr.New(gscerr.ErrNonceSize, "invalid nonce")
+	ErrDataTooLarge     = gscerr.New(gscerr.ErrParameter, "data length exceeds the limit")
+	ErrTagMismatch      = gscerr.New(gscerr.ErrAuthFailed, "authentication tag mismatch")
+	// ErrAuthFailed 是认证解密模式Open失败时返回的唯一错误，
+	// 不区分具体失败原因，避免向调用方泄露可被利用的信息
+	ErrAuthFailed = gscerr.New(gscerr.ErrAuthFailed, "authenticated decryption failed")
 )
 
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidBlockSize: "无效的块大小",
+		ErrInvalidDataSize:  "数据长度必须是块大小的整数倍",
+		ErrInvalidPadding:   "无效的填充",
+		ErrInvalidIV:        "无效的初始化向量",
+		ErrInvalidNonce:     "无效的nonce",
+		ErrDataTooLarge:     "数据长度超过限制",
+		ErrTagMismatch:      "认证标签不匹配",
+		ErrAuthFailed:       "认证解密失败",
+	})
+}
+
 // BlockCipher 接口定义块加密算法应实现的方法
+// 实现必须支持并发调用Encrypt和Decrypt：CTR等模式在处理大块数据时会并行调用
 type BlockCipher interface {
 	// Encrypt 加密单个块
 	Encrypt([]byte) ([]byte, error)
@@ -47,3 +69,19 @@ type PaddingFunc func([]byte, int) ([]byte, error)
 
 // UnpaddingFunc 定义了取消填充函数的类型
 type UnpaddingFunc func([]byte) ([]byte, error)
+
+// checkStream 检查流式接口的输出缓冲区，与crypto/cipher.Stream一致，长度不足时panic
+func checkStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("modes: output smaller than input")
+	}
+}
+
+// mustEncrypt 加密单个块，流式接口没有错误返回值，块长度正确时分组密码不应出错
+func mustEncrypt(cipher BlockCipher, block []byte) []byte {
+	out, err := cipher.Encrypt(block)
+	if err != nil {
+		panic(err)
+	}
+	return out
+}
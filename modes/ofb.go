@@ -1,11 +1,20 @@
 package modes
 
-import "github.com/laenix/gsc/modes/internal"
+import (
+	"bytes"
+
+	"github.com/laenix/gsc/modes/internal"
+)
 
 // OFB 结构体实现了输出反馈(OFB)模式
+// 实例保存重用检测记录和流式状态，不能被多个goroutine并发使用，需要时用Clone为每个goroutine创建副本
 type OFB struct {
 	cipher BlockCipher
 	iv     []byte
+	// 以下为XORKeyStream的流式状态，register同时也是当前的密钥流块
+	register []byte
+	used     int
+	guard    ivGuard
 }
 
 // NewOFB 创建一个新的OFB模式封装器
@@ -25,51 +34,104 @@ func NewOFB(cipher BlockCipher, iv []byte) (*OFB, error) {
 	}, nil
 }
 
-// Encrypt 使用OFB模式加密数据
-func (o *OFB) Encrypt(plaintext []byte) ([]byte, error) {
-	blockSize := o.cipher.BlockSize()
+// Reset 将IV更换为iv，并将XORKeyStream/Next的流式状态重新从iv开始，约定与CTR.Reset相同
+func (o *OFB) Reset(iv []byte) error {
+	if len(iv) != len(o.iv) {
+		return ErrInvalidIV
+	}
+	o.guard.reset(o.iv, iv)
+	copy(o.iv, iv)
+	if o.register != nil {
+		copy(o.register, iv)
+		o.used = len(iv)
+	}
+	return nil
+}
 
-	// OFB模式可以处理任意长度的数据，不需要填充
-	ciphertext := make([]byte, len(plaintext))
-
-	// 初始化寄存器
-	register := make([]byte, blockSize)
-	copy(register, o.iv)
-
-	// 处理完整块
-	i := 0
-	for ; i+blockSize <= len(plaintext); i += blockSize {
-		// 1. 加密寄存器
-		encryptedRegister, err := o.cipher.Encrypt(register)
-		if err != nil {
-			return nil, err
-		}
+// Clone 返回与o共享分组密码的副本，约定与CTR.Clone相同
+func (o *OFB) Clone() *OFB {
+	return &OFB{
+		cipher:   o.cipher,
+		iv:       bytes.Clone(o.iv),
+		register: bytes.Clone(o.register),
+		used:     o.used,
+		guard:    o.guard,
+	}
+}
 
-		// 2. 将加密后的寄存器与明文异或
-		internal.XORBytes(ciphertext[i:i+blockSize], plaintext[i:i+blockSize], encryptedRegister)
+// Encrypt 使用OFB模式加密数据
+// 每次调用都从IV重新开始，用同一实例加密两条消息会重用密钥流：
+// 严格策略下第二次调用返回ErrKeystreamReuse，否则通过警告钩子提示。
+// 记录型协议需要在多条消息间连续推进密钥流时应使用Next
+func (o *OFB) Encrypt(plaintext []byte) ([]byte, error) {
+	return o.AppendEncrypt(nil, plaintext)
+}
 
-		// 3. 更新寄存器为加密后的结果（而不是密文）
-		copy(register, encryptedRegister)
+// AppendEncrypt 与Encrypt相同，但将密文追加到dst之后返回
+// dst容量足够时不为结果分配内存；传入plaintext[:0]可以原地加密
+func (o *OFB) AppendEncrypt(dst, plaintext []byte) ([]byte, error) {
+	if err := o.guard.check("OFB"); err != nil {
+		return nil, err
 	}
+	return o.crypt(dst, plaintext)
+}
+
+// crypt 从IV开始生成密钥流，与输入异或后追加到dst
+func (o *OFB) crypt(dst, plaintext []byte) ([]byte, error) {
+	// OFB模式可以处理任意长度的数据，不需要填充
+	ret, ciphertext := appendOutput(dst, plaintext, len(plaintext))
 
-	// 处理最后一个不完整块
-	if i < len(plaintext) {
-		encryptedRegister, err := o.cipher.Encrypt(register)
-		if err != nil {
+	// 寄存器即当前的密钥流块，每次原地加密得到下一块
+	register := internal.DuplicateSlice(o.iv)
+	for i := 0; i < len(plaintext); i += len(register) {
+		if err := encryptBlocks(o.cipher, register, register); err != nil {
 			return nil, err
 		}
-
-		// 处理剩余字节
-		internal.XORBytes(ciphertext[i:], plaintext[i:], encryptedRegister)
+		// 最后一个块可能不完整，XORBytes只处理较短的部分
+		internal.XORBytes(ciphertext[i:], plaintext[i:], register)
 	}
 
-	return ciphertext, nil
+	return ret, nil
 }
 
 // Decrypt 使用OFB模式解密数据（在OFB模式中，解密操作与加密操作相同）
+// 解密不会重用密钥流，因此不受重复加密检测的限制
 func (o *OFB) Decrypt(ciphertext []byte) ([]byte, error) {
 	// 由于OFB模式是将密钥流与数据异或，解密和加密操作相同
-	return o.Encrypt(ciphertext)
+	return o.crypt(nil, ciphertext)
+}
+
+// AppendDecrypt 与Decrypt相同，但将明文追加到dst之后返回，约定与AppendEncrypt相同
+func (o *OFB) AppendDecrypt(dst, ciphertext []byte) ([]byte, error) {
+	return o.crypt(dst, ciphertext)
+}
+
+// Next 处理一条记录并返回新的切片，密钥流在多次调用之间连续推进，加密和解密相同
+// 与XORKeyStream共享流式状态：依次对各条记录调用Next的结果与一次处理全部数据相同，
+// 对端以相同的记录顺序调用Next即可解密。同一实例的Next应只用于一个方向
+func (o *OFB) Next(data []byte) ([]byte, error) {
+	out := make([]byte, len(data))
+	o.XORKeyStream(out, data)
+	return out, nil
+}
+
+// XORKeyStream 将src与密钥流异或后写入dst，dst与src可以是同一切片（原地处理）
+// 与Encrypt不同，密钥流位置在多次调用之间连续推进：分多次处理的结果与一次处理整段数据相同。
+// 首次调用从IV开始；dst长度小于src时panic
+func (o *OFB) XORKeyStream(dst, src []byte) {
+	checkStream(dst, src)
+	if o.register == nil {
+		o.register = internal.DuplicateSlice(o.iv)
+		o.used = len(o.iv)
+	}
+	for i := range src {
+		if o.used == len(o.register) {
+			o.register = mustEncrypt(o.cipher, o.register)
+			o.used = 0
+		}
+		dst[i] = src[i] ^ o.register[o.used]
+		o.used++
+	}
 }
 
 // BlockSize 返回块大小
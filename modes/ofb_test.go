@@ -0,0 +1,94 @@
+package modes
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/laenix/gsc/aes"
+)
+
+// NIST SP 800-38A F.4.1中OFB-AES128的测试向量，F.3.13（CFB128）使用相同的密钥、IV和明文
+const (
+	sp80038aPlaintext = "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710"
+	sp80038aOFB       = "3b3fd92eb72dad20333449f8e83cfb4a7789508d16918f03f53c52dac54ed8259740051e9c5fecf64344f7a82260edcc304c6528f659c77866a510d9c1d6ae5e"
+	sp80038aCFB128    = "3b3fd92eb72dad20333449f8e83cfb4ac8a64537a0b3a93fcde3cdad9f1ce58b26751f67a3cbb140b1808cf187a4f4dfc04b05357c5d1c0eeac4c66f9ff7f2e6"
+	// sp80038aOFBOutput1 是F.4.1中第一块的输出块，即第二块的输入块
+	sp80038aOFBOutput1 = "50fe67cc996d32b6da0937e99bafec60"
+)
+
+// 测试OFB和CFB128在最后一块不完整时输出密文前缀，解密可以还原
+func TestOFBCFBPartialFinalBlock(t *testing.T) {
+	key := decodeHex(t, "2b7e151628aed2a6abf7158809cf4f3c")
+	iv := decodeHex(t, "000102030405060708090a0b0c0d0e0f")
+	plaintext := decodeHex(t, sp80038aPlaintext)
+	block, err := aes.New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		want []byte
+		mode func() (Mode, error)
+	}{
+		{"OFB", decodeHex(t, sp80038aOFB), func() (Mode, error) { return NewOFB(block, iv) }},
+		{"CFB128", decodeHex(t, sp80038aCFB128), func() (Mode, error) { return NewCFB(block, iv) }},
+	} {
+		for _, n := range []int{1, 15, 17, 33, 63, 64} {
+			m, err := tt.mode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := m.Encrypt(plaintext[:n])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.want[:n]) {
+				t.Fatalf("%s长度%d的密文不匹配:\n期望值: %x\n实际值: %x", tt.name, n, tt.want[:n], got)
+			}
+			decrypted, err := m.Decrypt(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decrypted, plaintext[:n]) {
+				t.Fatalf("%s长度%d的解密结果不匹配", tt.name, n)
+			}
+		}
+	}
+}
+
+// 测试IV链接：从第二块开始加密时，OFB以上一块的输出块、CFB以上一块的密文作为IV，
+// 结果与整段加密的后续密文相同
+func TestOFBCFBIVChaining(t *testing.T) {
+	key := decodeHex(t, "2b7e151628aed2a6abf7158809cf4f3c")
+	plaintext := decodeHex(t, sp80038aPlaintext)
+	block, err := aes.New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ofb, err := NewOFB(block, decodeHex(t, sp80038aOFBOutput1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ofb.Encrypt(plaintext[16:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := decodeHex(t, sp80038aOFB)[16:]; !bytes.Equal(got, want) {
+		t.Fatalf("OFB链接后的密文不匹配:\n期望值: %x\n实际值: %x", want, got)
+	}
+
+	cfbCiphertext := decodeHex(t, sp80038aCFB128)
+	cfb, err := NewCFB(block, cfbCiphertext[:16])
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = cfb.Encrypt(plaintext[16:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, cfbCiphertext[16:]) {
+		t.Fatalf("CFB链接后的密文不匹配:\n期望值: %x\n实际值: %x", cfbCiphertext[16:], got)
+	}
+}
@@ -0,0 +1,60 @@
+package modes
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultParallelThreshold 是启用并行处理的默认最小数据长度（字节）
+// 低于该长度时协程调度的开销超过多核带来的收益，仍按顺序处理
+const DefaultParallelThreshold = 256 << 10
+
+// parallelThreshold 保存当前的并行阈值，不大于0表示关闭并行处理
+var parallelThreshold atomic.Int64
+
+func init() {
+	parallelThreshold.Store(DefaultParallelThreshold)
+}
+
+// SetParallelThreshold 设置CTR加解密、ECB加解密和CBC解密启用多协程并行处理的最小数据长度
+// n不大于0时关闭并行处理，所有模式按顺序逐块处理
+func SetParallelThreshold(n int) {
+	parallelThreshold.Store(int64(n))
+}
+
+// ParallelThreshold 返回当前的并行阈值
+func ParallelThreshold() int {
+	return int(parallelThreshold.Load())
+}
+
+// useParallel 判断长度为n的数据是否值得并行处理
+func useParallel(n int) bool {
+	threshold := ParallelThreshold()
+	return threshold > 0 && n >= threshold && runtime.GOMAXPROCS(0) > 1
+}
+
+// parallelBlocks 将[0, blocks)个块均分给最多GOMAXPROCS个协程，
+// fn处理[start, end)范围内的块，返回任一协程遇到的第一个错误
+func parallelBlocks(blocks int, fn func(start, end int) error) error {
+	workers := min(runtime.GOMAXPROCS(0), blocks)
+	per := (blocks + workers - 1) / workers
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for start := 0; start < blocks; start += per {
+		end := min(start+per, blocks)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(start, end); err != nil {
+				once.Do(func() { firstErr = err })
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
@@ -0,0 +1,107 @@
+package modes
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/laenix/gsc/aes"
+	"github.com/laenix/gsc/modes/internal"
+)
+
+// withParallelThreshold 临时调低并行阈值，使测试数据走并行路径
+func withParallelThreshold(t *testing.T, n int) {
+	old := ParallelThreshold()
+	SetParallelThreshold(n)
+	t.Cleanup(func() { SetParallelThreshold(old) })
+}
+
+// 测试CTR并行路径与流式逐字节处理的结果相同，包括计数器跨字节进位和不完整末块
+func TestParallelCTR(t *testing.T) {
+	withParallelThreshold(t, 1)
+	block, _ := aes.New(make([]byte, 16))
+	plaintext := make([]byte, 16*1000+7)
+	for i := range plaintext {
+		plaintext[i] = byte(i * 31)
+	}
+
+	// 计数器低字节接近溢出，检查偏移计数器的进位
+	for _, iv := range [][]byte{
+		make([]byte, 16),
+		decodeHex(t, "00000000000000000000000000fffff0"),
+		bytes.Repeat([]byte{0xff}, 16),
+	} {
+		ctr, _ := NewCTR(block, iv)
+		got, err := ctr.Encrypt(plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := make([]byte, len(plaintext))
+		ref, _ := NewCTR(block, iv)
+		ref.XORKeyStream(want, plaintext)
+		if !bytes.Equal(got, want) {
+			t.Fatalf("IV %x的并行CTR结果与顺序处理不一致", iv)
+		}
+	}
+}
+
+func TestAddCounter(t *testing.T) {
+	for _, n := range []uint64{0, 1, 255, 256, 65537} {
+		counter := decodeHex(t, "00ff00ffffffff00")
+		want := bytes.Clone(counter)
+		for range n {
+			internal.Increment(want)
+		}
+		internal.AddCounter(counter, n)
+		if !bytes.Equal(counter, want) {
+			t.Fatalf("AddCounter(%d) = %x，期望%x", n, counter, want)
+		}
+	}
+
+	// 超出计数器长度时回绕
+	counter := decodeHex(t, "ffff")
+	internal.AddCounter(counter, 1<<40+3)
+	if !bytes.Equal(counter, decodeHex(t, "0002")) {
+		t.Fatalf("回绕结果不正确: %x", counter)
+	}
+}
+
+// 测试ECB加解密和CBC解密的并行路径与顺序处理的结果相同
+func TestParallelECBCBC(t *testing.T) {
+	block, _ := aes.New(make([]byte, 16))
+	iv := decodeHex(t, "000102030405060708090a0b0c0d0e0f")
+	plaintext := make([]byte, 16*1001)
+	for i := range plaintext {
+		plaintext[i] = byte(i * 13)
+	}
+
+	run := func(threshold int) (ecbCT, cbcPT []byte) {
+		withParallelThreshold(t, threshold)
+		ecb, _ := NewECB(block, AllowInsecure())
+		ecbCT, err := ecb.Encrypt(plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := ecb.Decrypt(ecbCT); !bytes.Equal(got, plaintext) {
+			t.Fatal("ECB解密结果不匹配")
+		}
+
+		cbc, _ := NewCBC(block, iv)
+		ciphertext, err := cbc.Encrypt(plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cbcPT, err = cbc.Decrypt(ciphertext); err != nil {
+			t.Fatal(err)
+		}
+		return ecbCT, cbcPT
+	}
+
+	seqECB, seqCBC := run(0)
+	parECB, parCBC := run(1)
+	if !bytes.Equal(seqECB, parECB) {
+		t.Fatal("ECB并行加密结果与顺序处理不一致")
+	}
+	if !bytes.Equal(seqCBC, plaintext) || !bytes.Equal(parCBC, plaintext) {
+		t.Fatal("CBC解密结果不匹配")
+	}
+}
@@ -0,0 +1,115 @@
+package modes
+
+import (
+	"bytes"
+	"sync/atomic"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+// ErrInsecureMode 表示在严格策略下使用了未显式允许的不安全模式
+var ErrInsecureMode = gscerr.New(gscerr.ErrMisuse, "insecure mode of operation, must be explicitly allowed")
+
+// WarningFunc 是安全警告的日志钩子，mode为模式名称，msg为警告内容
+type WarningFunc func(mode, msg string)
+
+var (
+	// strictPolicy 为true时，ECB等不安全模式必须通过AllowInsecure显式允许
+	strictPolicy atomic.Bool
+	// warningHook 保存当前的警告钩子
+	warningHook atomic.Pointer[WarningFunc]
+)
+
+// SetStrictPolicy 开启或关闭严格安全策略
+// 开启后，未传入AllowInsecure()的NewECB返回ErrInsecureMode，
+// 开启前已构造的此类ECB实例在Encrypt/Decrypt时返回ErrInsecureMode
+func SetStrictPolicy(enabled bool) {
+	strictPolicy.Store(enabled)
+}
+
+// StrictPolicy 返回严格安全策略是否开启
+func StrictPolicy() bool {
+	return strictPolicy.Load()
+}
+
+// SetWarningHook 设置安全警告的日志钩子，传入nil表示不输出警告
+func SetWarningHook(fn WarningFunc) {
+	if fn == nil {
+		warningHook.Store(nil)
+		return
+	}
+	warningHook.Store(&fn)
+}
+
+// warn 通过日志钩子输出警告
+func warn(mode, msg string) {
+	if fn := warningHook.Load(); fn != nil {
+		(*fn)(mode, msg)
+	}
+}
+
+// Option 是构造模式时的可选配置
+type Option func(*modeOptions)
+
+// modeOptions 汇总构造模式时的可选配置
+type modeOptions struct {
+	allowInsecure bool
+}
+
+// AllowInsecure 显式允许使用不安全的模式（如ECB）
+// 调用方应确认数据不存在重复块或仅用于与旧系统互通
+func AllowInsecure() Option {
+	return func(o *modeOptions) {
+		o.allowInsecure = true
+	}
+}
+
+// applyOptions 合并可选配置
+func applyOptions(opts []Option) modeOptions {
+	var o modeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// ErrKeystreamReuse 表示严格策略下用同一实例（即同一IV）多次调用Encrypt
+var ErrKeystreamReuse = gscerr.New(gscerr.ErrMisuse, "the same IV was reused for encryption, keystream reused")
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInsecureMode:   "不安全的工作模式，需要显式允许",
+		ErrKeystreamReuse: "同一IV被重复用于加密，密钥流被重用",
+	})
+}
+
+// keystreamReuseWarning 是重复使用IV加密时的安全警告
+const keystreamReuseWarning = "同一实例多次调用Encrypt会从同一IV重新开始，重用密钥流；多条记录应使用Next或新的IV"
+
+// ivGuard 检测CTR、OFB、CFB等流式模式在同一IV下的重复加密
+// Encrypt每次都从构造时的IV开始，第二次调用即意味着密钥流被重用
+type ivGuard struct {
+	used bool
+}
+
+// check 在Encrypt开始时调用：重复使用时严格策略下返回ErrKeystreamReuse，否则输出警告
+func (g *ivGuard) check(mode string) error {
+	if g.used {
+		if StrictPolicy() {
+			return ErrKeystreamReuse
+		}
+		warn(mode, keystreamReuseWarning)
+	}
+	g.used = true
+	return nil
+}
+
+// reset 在Reset更换IV时调用：只有换成不同的IV才清除使用记录，
+// 因此Reset为同一IV后再次加密仍会被视为重用
+func (g *ivGuard) reset(oldIV, newIV []byte) {
+	if !bytes.Equal(oldIV, newIV) {
+		g.used = false
+	}
+}
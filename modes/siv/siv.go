@@ -0,0 +1,189 @@
+package siv
+
+import (
+	"crypto/subtle"
+	"hash"
+
+	"github.com/laenix/gsc/aes"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/mac"
+	"github.com/laenix/gsc/modes"
+)
+
+const (
+	// 分组大小，SIV只支持128位分组密码
+	blockSize = 16
+	// MaxAdditionalData 是附加数据向量的最大个数（S2V最多处理127个字符串，最后一个为明文）
+	MaxAdditionalData = 126
+)
+
+// 错误定义
+var (
+	ErrInvalidKeySize   = gscerr.New(gscerr.ErrKeySize, "siv: key must be 32, 48 or 64 bytes")
+	ErrInvalidBlockSize = gscerr.New(gscerr.ErrBlockSize, "siv: cipher with a 16-byte block is required")
+	ErrTooManyAD        = gscerr.New(gscerr.ErrParameter, "siv: too many associated data items")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidKeySize:   "siv: 密钥长度必须是32、48或64字节",
+		ErrInvalidBlockSize: "siv: 需要块大小为16字节的加密算法",
+		ErrTooManyAD:        "siv: 附加数据向量过多",
+	})
+}
+
+// SIV 实现了确定性认证加密SIV模式（RFC 5297）
+// 相同的密钥、附加数据和明文总是得到相同的密文，无需nonce即可安全使用，
+// 适用于密钥封装和加密存储去重；需要语义安全时可把随机nonce作为最后一个附加数据传入
+type SIV struct {
+	// macCipher 用于S2V（CMAC）
+	macCipher modes.BlockCipher
+	// ctrCipher 用于CTR加密
+	ctrCipher modes.BlockCipher
+}
+
+// New 创建AES-SIV实例，key为32、48或64字节，
+// 前一半用于S2V，后一半用于CTR加密（对应AES-SIV-CMAC-256/384/512）
+func New(key []byte) (*SIV, error) {
+	switch len(key) {
+	case 2 * aes.KeySize128, 2 * aes.KeySize192, 2 * aes.KeySize256:
+	default:
+		return nil, gscerr.KeySize(ErrInvalidKeySize, "AES-SIV", len(key), 2*aes.KeySize128, 2*aes.KeySize192, 2*aes.KeySize256)
+	}
+
+	half := len(key) / 2
+	macCipher, err := aes.New(key[:half])
+	if err != nil {
+		return nil, err
+	}
+	ctrCipher, err := aes.New(key[half:])
+	if err != nil {
+		return nil, err
+	}
+	return NewWithCiphers(macCipher, ctrCipher)
+}
+
+// NewWithCiphers 使用两个独立密钥的128位分组密码创建SIV实例，可用于SM4-SIV等变体
+func NewWithCiphers(macCipher, ctrCipher modes.BlockCipher) (*SIV, error) {
+	if macCipher.BlockSize() != blockSize || ctrCipher.BlockSize() != blockSize {
+		return nil, ErrInvalidBlockSize
+	}
+	return &SIV{
+		macCipher: macCipher,
+		ctrCipher: ctrCipher,
+	}, nil
+}
+
+// Overhead 返回密文比明文多出的长度（合成IV的长度）
+func (s *SIV) Overhead() int {
+	return blockSize
+}
+
+// Seal 加密明文，输出 V || C，其中V为合成IV
+// additionalData按顺序参与认证，解密时必须以相同的顺序提供
+func (s *SIV) Seal(plaintext []byte, additionalData ...[]byte) ([]byte, error) {
+	if len(additionalData) > MaxAdditionalData {
+		return nil, ErrTooManyAD
+	}
+
+	v, err := s.s2v(additionalData, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := s.ctr(v, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return append(v, ciphertext...), nil
+}
+
+// Open 解密Seal的输出并验证合成IV
+// 任何失败都只返回modes.ErrAuthFailed，且不会返回任何解密数据
+func (s *SIV) Open(ciphertext []byte, additionalData ...[]byte) ([]byte, error) {
+	if len(ciphertext) < blockSize || len(additionalData) > MaxAdditionalData {
+		return nil, modes.ErrAuthFailed
+	}
+
+	v := ciphertext[:blockSize]
+	plaintext, err := s.ctr(v, ciphertext[blockSize:])
+	if err != nil {
+		return nil, modes.ErrAuthFailed
+	}
+
+	expected, err := s.s2v(additionalData, plaintext)
+	if err != nil || subtle.ConstantTimeCompare(expected, v) != 1 {
+		clear(plaintext)
+		return nil, modes.ErrAuthFailed
+	}
+	return plaintext, nil
+}
+
+// s2v 计算合成IV（RFC 5297 2.4）
+func (s *SIV) s2v(additionalData [][]byte, plaintext []byte) ([]byte, error) {
+	h, err := mac.NewCMAC(s.macCipher)
+	if err != nil {
+		return nil, err
+	}
+
+	// D = CMAC(K, <zero>)
+	d := sum(h, make([]byte, blockSize))
+
+	// D = dbl(D) xor CMAC(K, Si)
+	for _, ad := range additionalData {
+		d = dbl(d)
+		subtle.XORBytes(d, d, sum(h, ad))
+	}
+
+	var t []byte
+	if len(plaintext) >= blockSize {
+		// T = Sn xorend D
+		t = append([]byte(nil), plaintext...)
+		subtle.XORBytes(t[len(t)-blockSize:], t[len(t)-blockSize:], d)
+	} else {
+		// T = dbl(D) xor pad(Sn)
+		t = dbl(d)
+		padded := make([]byte, blockSize)
+		copy(padded, plaintext)
+		padded[len(plaintext)] = 0x80
+		subtle.XORBytes(t, t, padded)
+	}
+
+	return sum(h, t), nil
+}
+
+// ctr 以合成IV为初始计数器执行CTR变换
+// 计数器的第31位和第63位（从右数）被清零，便于实现使用32/64位加法
+func (s *SIV) ctr(v, data []byte) ([]byte, error) {
+	q := append([]byte(nil), v...)
+	q[8] &= 0x7f
+	q[12] &= 0x7f
+
+	ctr, err := modes.NewCTR(s.ctrCipher, q)
+	if err != nil {
+		return nil, err
+	}
+	return ctr.Encrypt(data)
+}
+
+// sum 计算单个字符串的CMAC
+func sum(h hash.Hash, data []byte) []byte {
+	h.Reset()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// dbl 在GF(2^128)上乘以x（左移一位，必要时异或0x87）
+func dbl(b []byte) []byte {
+	out := make([]byte, blockSize)
+	for i := 0; i < blockSize-1; i++ {
+		out[i] = b[i]<<1 | b[i+1]>>7
+	}
+	out[blockSize-1] = b[blockSize-1] << 1
+	if b[0]&0x80 != 0 {
+		out[blockSize-1] ^= 0x87
+	}
+	return out
+}
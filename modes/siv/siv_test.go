@@ -0,0 +1,117 @@
+package siv
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/laenix/gsc/modes"
+	"github.com/laenix/gsc/sm4"
+)
+
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("无效的十六进制字符串 %q: %v", s, err)
+	}
+	return b
+}
+
+// 测试RFC 5297附录A中的测试向量
+func TestRFC5297Vectors(t *testing.T) {
+	tests := []struct {
+		name      string
+		key       string
+		ad        []string
+		plaintext string
+		output    string
+	}{
+		{
+			name:      "A.1 确定性认证加密",
+			key:       "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff",
+			ad:        []string{"101112131415161718191a1b1c1d1e1f2021222324252627"},
+			plaintext: "112233445566778899aabbccddee",
+			output:    "85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c",
+		},
+		{
+			name: "A.2 基于nonce的认证加密",
+			key:  "7f7e7d7c7b7a79787776757473727170404142434445464748494a4b4c4d4e4f",
+			ad: []string{
+				"00112233445566778899aabbccddeeffdeaddadadeaddadaffeeddccbbaa99887766554433221100",
+				"102030405060708090a0",
+				"09f911029d74e35bd84156c5635688c0",
+			},
+			plaintext: "7468697320697320736f6d6520706c61696e7465787420746f20656e6372797074207573696e67205349562d414553",
+			output:    "7bdb6e3b432667eb06f4d14bff2fbd0fcb900f2fddbe404326601965c889bf17dba77ceb094fa663b7a3f748ba8af829ea64ad544a272e9c485b62a3fd5c0d",
+		},
+	}
+
+	for _, tt := range tests {
+		s, err := New(decodeHex(t, tt.key))
+		if err != nil {
+			t.Fatalf("%s: 创建SIV失败: %v", tt.name, err)
+		}
+		var ad [][]byte
+		for _, a := range tt.ad {
+			ad = append(ad, decodeHex(t, a))
+		}
+		plaintext := decodeHex(t, tt.plaintext)
+		want := decodeHex(t, tt.output)
+
+		sealed, err := s.Seal(plaintext, ad...)
+		if err != nil {
+			t.Fatalf("%s: Seal失败: %v", tt.name, err)
+		}
+		if !bytes.Equal(sealed, want) {
+			t.Fatalf("%s: Seal结果不匹配:\n期望值: %x\n实际值: %x", tt.name, want, sealed)
+		}
+
+		opened, err := s.Open(sealed, ad...)
+		if err != nil {
+			t.Fatalf("%s: Open失败: %v", tt.name, err)
+		}
+		if !bytes.Equal(opened, plaintext) {
+			t.Fatalf("%s: Open结果不匹配", tt.name)
+		}
+
+		// 附加数据的顺序参与认证
+		if len(ad) > 1 {
+			ad[0], ad[1] = ad[1], ad[0]
+			if _, err := s.Open(sealed, ad...); !errors.Is(err, modes.ErrAuthFailed) {
+				t.Fatalf("%s: 交换附加数据顺序期望ErrAuthFailed，实际: %v", tt.name, err)
+			}
+		}
+	}
+}
+
+func TestSM4SIV(t *testing.T) {
+	k1, _ := sm4.New(make([]byte, 16))
+	k2, _ := sm4.New(bytes.Repeat([]byte{1}, 16))
+	s, err := NewWithCiphers(k1, k2)
+	if err != nil {
+		t.Fatalf("创建SM4-SIV失败: %v", err)
+	}
+
+	for _, plaintext := range [][]byte{nil, []byte("short"), bytes.Repeat([]byte("x"), 100)} {
+		sealed, err := s.Seal(plaintext, []byte("header"))
+		if err != nil {
+			t.Fatalf("Seal失败: %v", err)
+		}
+		// 确定性：相同输入得到相同密文
+		again, _ := s.Seal(plaintext, []byte("header"))
+		if !bytes.Equal(sealed, again) {
+			t.Fatal("相同输入应得到相同密文")
+		}
+
+		sealed[len(sealed)-1] ^= 0x01
+		if out, err := s.Open(sealed, []byte("header")); !errors.Is(err, modes.ErrAuthFailed) || out != nil {
+			t.Fatalf("篡改密文期望ErrAuthFailed，实际: %v", err)
+		}
+	}
+
+	if _, err := New(make([]byte, 16)); !errors.Is(err, ErrInvalidKeySize) {
+		t.Fatalf("期望ErrInvalidKeySize，实际: %v", err)
+	}
+}
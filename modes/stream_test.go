@@ -0,0 +1,266 @@
+package modes
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/laenix/gsc/aes"
+)
+
+// 测试流式接口分段处理的结果与一次性Encrypt相同，且支持原地处理
+func TestXORKeyStream(t *testing.T) {
+	key := decodeHex(t, "2b7e151628aed2a6abf7158809cf4f3c")
+	iv := decodeHex(t, "000102030405060708090a0b0c0d0e0f")
+	block, err := aes.New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := make([]byte, 100)
+	for i := range plaintext {
+		plaintext[i] = byte(i * 7)
+	}
+
+	type streamMode struct {
+		name    string
+		mode    Mode
+		encrypt func(dst, src []byte)
+		decrypt func(dst, src []byte)
+	}
+	newModes := func() []streamMode {
+		ctr, _ := NewCTR(block, iv)
+		ctrDec, _ := NewCTR(block, iv)
+		ofb, _ := NewOFB(block, iv)
+		ofbDec, _ := NewOFB(block, iv)
+		out := []streamMode{
+			{"CTR", ctr, ctr.XORKeyStream, ctrDec.XORKeyStream},
+			{"OFB", ofb, ofb.XORKeyStream, ofbDec.XORKeyStream},
+		}
+		for _, bits := range []int{CFB1, CFB8, 64, CFB128} {
+			enc, _ := NewCFB(block, iv)
+			dec, _ := NewCFB(block, iv)
+			enc.WithSegmentBits(bits)
+			dec.WithSegmentBits(bits)
+			out = append(out, streamMode{"CFB", enc, enc.EncryptStream, dec.DecryptStream})
+		}
+		return out
+	}
+
+	// 以不规则的分段长度处理数据
+	chunks := []int{1, 15, 16, 3, 33, 0, 32}
+	for _, m := range newModes() {
+		want, err := m.mode.Encrypt(plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		buf := bytes.Clone(plaintext)
+		for off, i := 0, 0; off < len(buf); i++ {
+			n := min(chunks[i%len(chunks)], len(buf)-off)
+			m.encrypt(buf[off:off+n], buf[off:off+n])
+			off += n
+		}
+		if !bytes.Equal(buf, want) {
+			t.Fatalf("%s(%d位): 分段加密结果与Encrypt不一致", m.name, segmentBits(m.mode))
+		}
+
+		for off, i := 0, 0; off < len(buf); i++ {
+			n := min(chunks[(i+3)%len(chunks)], len(buf)-off)
+			m.decrypt(buf[off:off+n], buf[off:off+n])
+			off += n
+		}
+		if !bytes.Equal(buf, plaintext) {
+			t.Fatalf("%s(%d位): 分段解密结果不匹配", m.name, segmentBits(m.mode))
+		}
+	}
+}
+
+func segmentBits(m Mode) int {
+	if c, ok := m.(*CFB); ok {
+		return c.SegmentBits()
+	}
+	return m.BlockSize() * 8
+}
+
+func TestXORKeyStreamShortDst(t *testing.T) {
+	block, _ := aes.New(make([]byte, 16))
+	ctr, _ := NewCTR(block, make([]byte, 16))
+	defer func() {
+		if recover() == nil {
+			t.Fatal("dst过短时期望panic")
+		}
+	}()
+	ctr.XORKeyStream(make([]byte, 1), make([]byte, 2))
+}
+
+// 测试Next按记录连续推进密钥流，各条记录拼接后与官方向量一致
+func TestNextRecords(t *testing.T) {
+	key := decodeHex(t, "2b7e151628aed2a6abf7158809cf4f3c")
+	iv := decodeHex(t, "000102030405060708090a0b0c0d0e0f")
+	plaintext := decodeHex(t, sp80038aPlaintext)
+	block, err := aes.New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ofb, _ := NewOFB(block, iv)
+	ofbDec, _ := NewOFB(block, iv)
+	cfb, _ := NewCFB(block, iv)
+	cfbDec, _ := NewCFB(block, iv)
+	for _, tt := range []struct {
+		name    string
+		want    []byte
+		encrypt func([]byte) ([]byte, error)
+		decrypt func([]byte) ([]byte, error)
+	}{
+		{"OFB", decodeHex(t, sp80038aOFB), ofb.Next, ofbDec.Next},
+		{"CFB128", decodeHex(t, sp80038aCFB128), cfb.EncryptNext, cfbDec.DecryptNext},
+	} {
+		// 记录长度故意不与块边界对齐
+		var ciphertext, decrypted []byte
+		for _, r := range [][2]int{{0, 5}, {5, 21}, {21, 21}, {21, 48}, {48, 64}} {
+			record, err := tt.encrypt(plaintext[r[0]:r[1]])
+			if err != nil {
+				t.Fatal(err)
+			}
+			ciphertext = append(ciphertext, record...)
+			opened, err := tt.decrypt(record)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decrypted = append(decrypted, opened...)
+		}
+		if !bytes.Equal(ciphertext, tt.want) {
+			t.Fatalf("%s按记录加密的结果不匹配:\n期望值: %x\n实际值: %x", tt.name, tt.want, ciphertext)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Fatalf("%s按记录解密的结果不匹配", tt.name)
+		}
+	}
+
+	// CTR的两条记录不应重用密钥流
+	ctr, _ := NewCTR(block, iv)
+	first, _ := ctr.Next(make([]byte, 16))
+	second, _ := ctr.Next(make([]byte, 16))
+	if bytes.Equal(first, second) {
+		t.Fatal("CTR的Next重用了密钥流")
+	}
+}
+
+// 测试同一实例重复Encrypt的检测：默认输出警告，严格策略下返回ErrKeystreamReuse
+func TestKeystreamReuseGuard(t *testing.T) {
+	block, _ := aes.New(make([]byte, 16))
+	iv := make([]byte, 16)
+
+	var warnings []string
+	SetWarningHook(func(mode, msg string) { warnings = append(warnings, mode) })
+	defer SetWarningHook(nil)
+
+	ctr, _ := NewCTR(block, iv)
+	ofb, _ := NewOFB(block, iv)
+	cfb, _ := NewCFB(block, iv)
+	for _, m := range []Mode{ctr, ofb, cfb} {
+		ciphertext, err := m.Encrypt([]byte("first"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 解密不受限制
+		for range 2 {
+			if _, err := m.Decrypt(ciphertext); err != nil {
+				t.Fatalf("解密不应被拒绝: %v", err)
+			}
+		}
+		if _, err := m.Encrypt([]byte("second")); err != nil {
+			t.Fatalf("非严格策略下不应返回错误: %v", err)
+		}
+	}
+	if len(warnings) != 3 {
+		t.Fatalf("期望3条警告，实际: %v", warnings)
+	}
+
+	SetStrictPolicy(true)
+	defer SetStrictPolicy(false)
+	ctr, _ = NewCTR(block, iv)
+	ofb, _ = NewOFB(block, iv)
+	cfb, _ = NewCFB(block, iv)
+	for _, m := range []Mode{ctr, ofb, cfb} {
+		if _, err := m.Encrypt([]byte("first")); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := m.Encrypt([]byte("second")); err != ErrKeystreamReuse {
+			t.Fatalf("期望ErrKeystreamReuse，实际: %v", err)
+		}
+	}
+}
+
+// 测试Reset更换IV后的结果与新建实例相同，且重置为相同的IV仍被视为重用
+func TestReset(t *testing.T) {
+	block, _ := aes.New(make([]byte, 16))
+	iv1 := make([]byte, 16)
+	iv2 := bytes.Repeat([]byte{0x5a}, 16)
+	plaintext := make([]byte, 50)
+
+	type resetMode interface {
+		Mode
+		Reset(iv []byte) error
+	}
+	newModes := func(iv []byte) []resetMode {
+		cbc, _ := NewCBC(block, iv)
+		ctr, _ := NewCTR(block, iv)
+		ofb, _ := NewOFB(block, iv)
+		cfb, _ := NewCFB8(block, iv)
+		return []resetMode{cbc, ctr, ofb, cfb}
+	}
+
+	SetStrictPolicy(true)
+	defer SetStrictPolicy(false)
+	fresh := newModes(iv2)
+	for i, m := range newModes(iv1) {
+		if _, err := m.Encrypt(plaintext[:48]); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.Reset(iv2[:8]); err != ErrInvalidIV {
+			t.Fatalf("%T: 期望ErrInvalidIV，实际: %v", m, err)
+		}
+		if err := m.Reset(iv2); err != nil {
+			t.Fatal(err)
+		}
+		got, err := m.Encrypt(plaintext[:48])
+		if err != nil {
+			t.Fatalf("%T: 更换IV后不应视为重用: %v", m, err)
+		}
+		want, _ := fresh[i].Encrypt(plaintext[:48])
+		if !bytes.Equal(got, want) {
+			t.Fatalf("%T: Reset后的结果与新建实例不同", m)
+		}
+
+		if _, ok := m.(*CBC); ok {
+			continue
+		}
+		if err := m.Reset(iv2); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := m.Encrypt(plaintext); err != ErrKeystreamReuse {
+			t.Fatalf("%T: 重置为相同的IV后期望ErrKeystreamReuse，实际: %v", m, err)
+		}
+	}
+
+	// 流式状态也从新的IV重新开始
+	ctr, _ := NewCTR(block, iv1)
+	ctr.XORKeyStream(make([]byte, 7), plaintext[:7])
+	ctr.Reset(iv2)
+	got := make([]byte, len(plaintext))
+	ctr.XORKeyStream(got, plaintext)
+	want, _ := NewCTR(block, iv2)
+	if out, _ := want.Next(plaintext); !bytes.Equal(got, out) {
+		t.Fatal("CTR: Reset后流式状态未从新的IV开始")
+	}
+
+	cfb, _ := NewCFB8(block, iv1)
+	cfb.EncryptStream(make([]byte, 7), plaintext[:7])
+	cfb.Reset(iv2)
+	cfb.EncryptStream(got, plaintext)
+	wantCFB, _ := NewCFB8(block, iv2)
+	if out, _ := wantCFB.EncryptNext(plaintext); !bytes.Equal(got, out) {
+		t.Fatal("CFB: Reset后流式状态未从新的IV开始")
+	}
+}
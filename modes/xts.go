@@ -0,0 +1,174 @@
+package modes
+
+import (
+	"encoding/binary"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/modes/internal"
+)
+
+// xtsBlockSize 是XTS要求的分组大小
+const xtsBlockSize = 16
+
+// XTS 结构体实现了XTS模式（IEEE 1619 / NIST SP 800-38E），用于磁盘和卷加密
+// 每个数据单元（扇区）使用独立的128位调整值（tweak），相同明文在不同扇区得到不同密文，
+// 且密文与明文等长。XTS不提供完整性保护
+// 实例创建后只读，可以被多个goroutine并发使用
+type XTS struct {
+	// 加密数据使用的分组密码（K1）
+	cipher BlockCipher
+	// 加密调整值使用的分组密码（K2），必须与K1使用不同的密钥
+	tweakCipher BlockCipher
+}
+
+// NewXTS 创建一个新的XTS模式封装器
+// cipher和tweakCipher是以两个独立密钥创建的同一算法实例
+func NewXTS(cipher, tweakCipher BlockCipher) (*XTS, error) {
+	if cipher.BlockSize() != xtsBlockSize || tweakCipher.BlockSize() != xtsBlockSize {
+		return nil, gscerr.New(gscerr.ErrBlockSize, "xts: cipher with a 16-byte block is required")
+	}
+	return &XTS{
+		cipher:      cipher,
+		tweakCipher: tweakCipher,
+	}, nil
+}
+
+// EncryptSector 加密一个扇区，调整值为扇区号的128位小端序编码
+func (x *XTS) EncryptSector(plaintext []byte, sector uint64) ([]byte, error) {
+	return x.EncryptWithTweak(plaintext, sectorTweak(sector))
+}
+
+// DecryptSector 解密一个扇区
+func (x *XTS) DecryptSector(ciphertext []byte, sector uint64) ([]byte, error) {
+	return x.DecryptWithTweak(ciphertext, sectorTweak(sector))
+}
+
+// EncryptWithTweak 使用任意16字节调整值加密一个数据单元
+// 数据长度至少为一个分组；不是分组整数倍时使用密文窃取（ciphertext stealing）
+func (x *XTS) EncryptWithTweak(plaintext, tweak []byte) ([]byte, error) {
+	return x.crypt(plaintext, tweak, true)
+}
+
+// DecryptWithTweak 使用任意16字节调整值解密一个数据单元
+func (x *XTS) DecryptWithTweak(ciphertext, tweak []byte) ([]byte, error) {
+	return x.crypt(ciphertext, tweak, false)
+}
+
+// Encrypt XTS需要扇区号或调整值，必须使用EncryptSector/EncryptWithTweak
+func (x *XTS) Encrypt(plaintext []byte) ([]byte, error) {
+	return nil, gscerr.New(gscerr.ErrMisuse, "xts: use EncryptSector or EncryptWithTweak with XTS mode")
+}
+
+// Decrypt XTS需要扇区号或调整值，必须使用DecryptSector/DecryptWithTweak
+func (x *XTS) Decrypt(ciphertext []byte) ([]byte, error) {
+	return nil, gscerr.New(gscerr.ErrMisuse, "xts: use DecryptSector or DecryptWithTweak with XTS mode")
+}
+
+// BlockSize 返回块大小
+func (x *XTS) BlockSize() int {
+	return xtsBlockSize
+}
+
+// crypt 执行XTS加密或解密
+func (x *XTS) crypt(in, tweak []byte, encrypt bool) ([]byte, error) {
+	if len(tweak) != xtsBlockSize {
+		return nil, ErrInvalidIV
+	}
+	if len(in) < xtsBlockSize {
+		return nil, ErrInvalidDataSize
+	}
+
+	// T = E(K2, tweak)
+	t, err := x.tweakCipher.Encrypt(tweak)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, len(in))
+	full := len(in) / xtsBlockSize
+	tail := len(in) % xtsBlockSize
+	if tail != 0 {
+		// 最后一个完整分组与不完整分组一起按密文窃取处理
+		full--
+	}
+
+	for i := 0; i < full; i++ {
+		off := i * xtsBlockSize
+		block, err := x.cryptBlock(in[off:off+xtsBlockSize], t, encrypt)
+		if err != nil {
+			return nil, err
+		}
+		copy(out[off:], block)
+		mulAlpha(t)
+	}
+
+	if tail == 0 {
+		return out, nil
+	}
+
+	// 密文窃取：最后两个分组
+	off := full * xtsBlockSize
+	last := in[off : off+xtsBlockSize]
+	partial := in[off+xtsBlockSize:]
+
+	// 加密时依次使用T_{m-1}、T_m；解密时顺序相反
+	t1 := append([]byte(nil), t...)
+	t2 := append([]byte(nil), t...)
+	mulAlpha(t2)
+	if !encrypt {
+		t1, t2 = t2, t1
+	}
+
+	cc, err := x.cryptBlock(last, t1, encrypt)
+	if err != nil {
+		return nil, err
+	}
+
+	pp := make([]byte, xtsBlockSize)
+	copy(pp, partial)
+	copy(pp[tail:], cc[tail:])
+
+	block, err := x.cryptBlock(pp, t2, encrypt)
+	if err != nil {
+		return nil, err
+	}
+	copy(out[off:], block)
+	copy(out[off+xtsBlockSize:], cc[:tail])
+
+	return out, nil
+}
+
+// cryptBlock 计算 E(K1, P ⊕ T) ⊕ T 或 D(K1, C ⊕ T) ⊕ T
+func (x *XTS) cryptBlock(in, t []byte, encrypt bool) ([]byte, error) {
+	buf := make([]byte, xtsBlockSize)
+	internal.XORBytes(buf, in, t)
+
+	var out []byte
+	var err error
+	if encrypt {
+		out, err = x.cipher.Encrypt(buf)
+	} else {
+		out, err = x.cipher.Decrypt(buf)
+	}
+	if err != nil {
+		return nil, err
+	}
+	internal.XORBytes(out, out, t)
+	return out, nil
+}
+
+// sectorTweak 将扇区号编码为128位小端序调整值
+func sectorTweak(sector uint64) []byte {
+	tweak := make([]byte, xtsBlockSize)
+	binary.LittleEndian.PutUint64(tweak, sector)
+	return tweak
+}
+
+// mulAlpha 在GF(2^128)上将调整值乘以α（小端序左移一位，溢出时异或0x87）
+func mulAlpha(t []byte) {
+	carry := t[xtsBlockSize-1] >> 7
+	for i := xtsBlockSize - 1; i > 0; i-- {
+		t[i] = t[i]<<1 | t[i-1]>>7
+	}
+	t[0] = t[0]<<1 ^ carry*0x87
+}
@@ -0,0 +1,119 @@
+package modes
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/laenix/gsc/aes"
+)
+
+func newTestXTS(t *testing.T, key1, key2 string) *XTS {
+	t.Helper()
+	c1, err := aes.New(decodeHex(t, key1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := aes.New(decodeHex(t, key2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	xts, err := NewXTS(c1, c2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return xts
+}
+
+// 测试IEEE 1619-2007附录B中的XTS-AES-128测试向量
+func TestXTSVectors(t *testing.T) {
+	tests := []struct {
+		key1       string
+		key2       string
+		sector     uint64
+		plaintext  string
+		ciphertext string
+	}{
+		// 向量1
+		{
+			"00000000000000000000000000000000", "00000000000000000000000000000000", 0,
+			"0000000000000000000000000000000000000000000000000000000000000000",
+			"917cf69ebd68b2ec9b9fe9a3eadda692cd43d2f59598ed858c02c2652fbf922e",
+		},
+		// 向量2
+		{
+			"11111111111111111111111111111111", "22222222222222222222222222222222", 0x3333333333,
+			"4444444444444444444444444444444444444444444444444444444444444444",
+			"c454185e6a16936e39334038acef838bfb186fff7480adc4289382ecd6d394f0",
+		},
+		// 向量15，17字节数据，使用密文窃取
+		{
+			"fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0", "bfbebdbcbbbab9b8b7b6b5b4b3b2b1b0", 0x123456789a,
+			"000102030405060708090a0b0c0d0e0f10",
+			"6c1625db4671522d3d7599601de7ca09ed",
+		},
+	}
+
+	for i, tt := range tests {
+		xts := newTestXTS(t, tt.key1, tt.key2)
+		plaintext := decodeHex(t, tt.plaintext)
+		want := decodeHex(t, tt.ciphertext)
+
+		got, err := xts.EncryptSector(plaintext, tt.sector)
+		if err != nil {
+			t.Fatalf("向量%d加密失败: %v", i, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("向量%d密文不匹配:\n期望值: %x\n实际值: %x", i, want, got)
+		}
+
+		decrypted, err := xts.DecryptSector(got, tt.sector)
+		if err != nil {
+			t.Fatalf("向量%d解密失败: %v", i, err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Fatalf("向量%d解密结果不匹配", i)
+		}
+	}
+}
+
+// 测试各种长度（含密文窃取）的往返加解密，以及扇区号对密文的影响
+func TestXTSRoundTrip(t *testing.T) {
+	xts := newTestXTS(t, "000102030405060708090a0b0c0d0e0f", "101112131415161718191a1b1c1d1e1f")
+
+	for _, n := range []int{16, 17, 31, 32, 33, 512, 527} {
+		plaintext := make([]byte, n)
+		for i := range plaintext {
+			plaintext[i] = byte(i)
+		}
+
+		ct, err := xts.EncryptSector(plaintext, 7)
+		if err != nil {
+			t.Fatalf("长度%d加密失败: %v", n, err)
+		}
+		if len(ct) != n {
+			t.Fatalf("长度%d的密文长度为%d", n, len(ct))
+		}
+		pt, err := xts.DecryptSector(ct, 7)
+		if err != nil {
+			t.Fatalf("长度%d解密失败: %v", n, err)
+		}
+		if !bytes.Equal(pt, plaintext) {
+			t.Fatalf("长度%d解密结果不匹配", n)
+		}
+
+		other, err := xts.EncryptSector(plaintext, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Equal(other, ct) {
+			t.Fatalf("长度%d在不同扇区得到了相同的密文", n)
+		}
+	}
+
+	if _, err := xts.EncryptSector(make([]byte, 15), 0); err != ErrInvalidDataSize {
+		t.Fatalf("不足一个分组的数据应返回ErrInvalidDataSize，实际: %v", err)
+	}
+	if _, err := xts.EncryptWithTweak(make([]byte, 16), make([]byte, 8)); err != ErrInvalidIV {
+		t.Fatalf("调整值长度错误应返回ErrInvalidIV，实际: %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+// Package box 实现NaCl的crypto_box（Curve25519-XSalsa20-Poly1305）和libsodium的匿名加密crypto_box_seal，
+// 输出与libsodium逐字节一致
+// 密文格式与secretbox相同，为 认证标签(16字节) || 密文；匿名密文为 临时公钥(32字节) || 认证标签 || 密文
+package box
+
+import (
+	"crypto/rand"
+	"io"
+
+	"github.com/laenix/gsc/blake2b"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/nacl/secretbox"
+	"github.com/laenix/gsc/salsa20"
+	"github.com/laenix/gsc/x25519"
+)
+
+const (
+	// PublicKeySize 是公钥长度（字节）
+	PublicKeySize = 32
+	// PrivateKeySize 是私钥长度（字节）
+	PrivateKeySize = 32
+	// SharedKeySize 是Precompute得到的共享密钥长度（字节）
+	SharedKeySize = secretbox.KeySize
+	// NonceSize 是nonce长度（字节），足以随机生成
+	NonceSize = secretbox.NonceSize
+	// Overhead 是密文比明文多出的长度（字节）
+	Overhead = secretbox.Overhead
+	// AnonymousOverhead 是匿名密文比明文多出的长度（字节）
+	AnonymousOverhead = PublicKeySize + Overhead
+)
+
+// 错误定义
+var (
+	ErrAuthFailed       = gscerr.New(gscerr.ErrAuthFailed, "box: message authentication failed")
+	ErrInvalidPublicKey = gscerr.New(gscerr.ErrParameter, "box: invalid public key")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrAuthFailed:       "box: 消息认证失败",
+		ErrInvalidPublicKey: "box: 公钥无效",
+	})
+}
+
+// GenerateKey 生成Curve25519密钥对，random为nil时使用crypto/rand
+func GenerateKey(random io.Reader) (publicKey *[PublicKeySize]byte, privateKey *[PrivateKeySize]byte, err error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	privateKey = new([PrivateKeySize]byte)
+	if _, err := io.ReadFull(random, privateKey[:]); err != nil {
+		return nil, nil, err
+	}
+	pub, err := x25519.X25519(privateKey[:], x25519.Basepoint())
+	if err != nil {
+		return nil, nil, err
+	}
+	publicKey = new([PublicKeySize]byte)
+	copy(publicKey[:], pub)
+	return publicKey, privateKey, nil
+}
+
+// Precompute 计算crypto_box_beforenm：共享密钥 = HSalsa20(X25519(privateKey, peersPublicKey), 0)
+// 与同一对端交换多条消息时预先计算可以省去每条消息的标量乘法。对端公钥是小阶点时返回ErrInvalidPublicKey
+func Precompute(sharedKey *[SharedKeySize]byte, peersPublicKey *[PublicKeySize]byte, privateKey *[PrivateKeySize]byte) error {
+	// 共享值为全零时X25519返回错误，与libsodium拒绝小阶点一致
+	shared, err := x25519.X25519(privateKey[:], peersPublicKey[:])
+	if err != nil {
+		return ErrInvalidPublicKey
+	}
+	key, err := salsa20.HSalsa20(shared, make([]byte, 16))
+	if err != nil {
+		return err
+	}
+	copy(sharedKey[:], key)
+	return nil
+}
+
+// Seal 使用自己的私钥和对端公钥加密并认证message，将结果追加到out之后返回
+// 同一对密钥下nonce绝不能重复使用
+func Seal(out, message []byte, nonce *[NonceSize]byte, peersPublicKey *[PublicKeySize]byte, privateKey *[PrivateKeySize]byte) ([]byte, error) {
+	var sharedKey [SharedKeySize]byte
+	if err := Precompute(&sharedKey, peersPublicKey, privateKey); err != nil {
+		return nil, err
+	}
+	return SealAfterPrecomputation(out, message, nonce, &sharedKey), nil
+}
+
+// Open 使用自己的私钥和对端公钥验证并解密box，将明文追加到out之后返回，认证失败时返回ErrAuthFailed
+func Open(out, box []byte, nonce *[NonceSize]byte, peersPublicKey *[PublicKeySize]byte, privateKey *[PrivateKeySize]byte) ([]byte, error) {
+	var sharedKey [SharedKeySize]byte
+	if err := Precompute(&sharedKey, peersPublicKey, privateKey); err != nil {
+		return nil, err
+	}
+	return OpenAfterPrecomputation(out, box, nonce, &sharedKey)
+}
+
+// SealAfterPrecomputation 与Seal相同，但使用Precompute得到的共享密钥
+func SealAfterPrecomputation(out, message []byte, nonce *[NonceSize]byte, sharedKey *[SharedKeySize]byte) []byte {
+	return secretbox.Seal(out, message, nonce, sharedKey)
+}
+
+// OpenAfterPrecomputation 与Open相同，但使用Precompute得到的共享密钥
+func OpenAfterPrecomputation(out, box []byte, nonce *[NonceSize]byte, sharedKey *[SharedKeySize]byte) ([]byte, error) {
+	ret, err := secretbox.Open(out, box, nonce, sharedKey)
+	if err != nil {
+		return nil, ErrAuthFailed
+	}
+	return ret, nil
+}
+
+// SealAnonymous 实现crypto_box_seal：使用一次性临时密钥对为recipient加密message，将结果追加到out之后返回
+// nonce = BLAKE2b-192(临时公钥 || 接收者公钥)，接收者无法得知发送者的身份。random为nil时使用crypto/rand
+func SealAnonymous(out, message []byte, recipient *[PublicKeySize]byte, random io.Reader) ([]byte, error) {
+	ephemeralPublic, ephemeralPrivate, err := GenerateKey(random)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(ephemeralPrivate[:])
+	nonce, err := sealNonce(ephemeralPublic, recipient)
+	if err != nil {
+		return nil, err
+	}
+	var sharedKey [SharedKeySize]byte
+	if err := Precompute(&sharedKey, recipient, ephemeralPrivate); err != nil {
+		return nil, err
+	}
+	out = append(out, ephemeralPublic[:]...)
+	return SealAfterPrecomputation(out, message, nonce, &sharedKey), nil
+}
+
+// OpenAnonymous 实现crypto_box_seal_open：使用接收者的密钥对解密SealAnonymous的结果，将明文追加到out之后返回
+func OpenAnonymous(out, box []byte, publicKey *[PublicKeySize]byte, privateKey *[PrivateKeySize]byte) ([]byte, error) {
+	if len(box) < AnonymousOverhead {
+		return nil, ErrAuthFailed
+	}
+	var ephemeralPublic [PublicKeySize]byte
+	copy(ephemeralPublic[:], box)
+	nonce, err := sealNonce(&ephemeralPublic, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	var sharedKey [SharedKeySize]byte
+	if err := Precompute(&sharedKey, &ephemeralPublic, privateKey); err != nil {
+		return nil, ErrAuthFailed
+	}
+	return OpenAfterPrecomputation(out, box[PublicKeySize:], nonce, &sharedKey)
+}
+
+// sealNonce 计算匿名加密的nonce：BLAKE2b-192(ephemeralPublic || recipient)
+func sealNonce(ephemeralPublic, recipient *[PublicKeySize]byte) (*[NonceSize]byte, error) {
+	h, err := blake2b.New(NonceSize, nil)
+	if err != nil {
+		return nil, err
+	}
+	h.Write(ephemeralPublic[:])
+	h.Write(recipient[:])
+	var nonce [NonceSize]byte
+	copy(nonce[:], h.Sum(nil))
+	return &nonce, nil
+}
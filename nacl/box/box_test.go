@@ -0,0 +1,124 @@
+package box
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func key32(t *testing.T, s string) *[32]byte {
+	t.Helper()
+	var k [32]byte
+	copy(k[:], mustHex(t, s))
+	return &k
+}
+
+// NaCl发行包tests/box.c中的密钥对
+const (
+	aliceSecret = "77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a"
+	alicePublic = "8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a"
+	bobSecret   = "5dab087e624a8a4b79e17f8b83800ee66f3bb1292618b6fd1c2f8b27ff88e0eb"
+	bobPublic   = "de9edb7d7b7dc1b4d35b61c2ece435373f8343c85b78674dadfc7e146f882b4f"
+)
+
+// 测试与NaCl发行包tests/box.c、tests/box2.c的输出一致
+func TestNaClVector(t *testing.T) {
+	var nonce [NonceSize]byte
+	copy(nonce[:], mustHex(t, "69696ee955b62b73cd62bda875fc73d68219e0036b7a0b37"))
+	message := mustHex(t, "be075fc53c81f2d5cf141316ebeb0c7b5228c52a4c62cbd44b66849b64244ffce5ecbaaf33bd751a1ac728d45e6c61296cdc3c01233561f41db66cce314adb310e3be8250c46f06dceea3a7fa1348057e2f6556ad6b1318a024a838f21af1fde048977eb48f59ffd4924ca1c60902e52f0a089bc76897040e082f937763848645e0705")
+	want := mustHex(t, "f3ffc7703f9400e52a7dfb4b3d3305d98e993b9f48681273c29650ba32fc76ce48332ea7164d96a4476fb8c531a1186ac0dfc17c98dce87b4da7f011ec48c97271d2c20f9b928fe2270d6fb863d51738b48eeee314a7cc8ab932164548e526ae90224368517acfeabd6bb3732bc0e9da99832b61ca01b6de56244a9e88d5f9b37973f622a43d14a6599b1f654cb45a74e355a5")
+
+	var shared [SharedKeySize]byte
+	if err := Precompute(&shared, key32(t, bobPublic), key32(t, aliceSecret)); err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(shared[:]) != "1b27556473e985d462cd51197a9a46c76009549eac6474f206c4ee0844f68389" {
+		t.Errorf("共享密钥 %x", shared)
+	}
+
+	box, err := Seal(nil, message, &nonce, key32(t, bobPublic), key32(t, aliceSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(box, want) {
+		t.Fatalf("密文不匹配\n预期: %x\n实际: %x", want, box)
+	}
+	opened, err := Open(nil, box, &nonce, key32(t, alicePublic), key32(t, bobSecret))
+	if err != nil || !bytes.Equal(opened, message) {
+		t.Fatalf("解密失败: %v", err)
+	}
+	box[0] ^= 1
+	if _, err := Open(nil, box, &nonce, key32(t, alicePublic), key32(t, bobSecret)); err != ErrAuthFailed {
+		t.Errorf("篡改的密文应返回ErrAuthFailed，实际: %v", err)
+	}
+}
+
+// 测试打开libsodium的crypto_box_seal生成的匿名密文
+func TestOpenAnonymousLibsodium(t *testing.T) {
+	sealed := mustHex(t, "23adfdb5e49edd1c749b50cf69cc448c3c40ee44f9b96ab52f57f79536ec8f2a17b5637daf22341cc8ec8e2bff30f007b0198118e6932095baeeb9a57bef5c117ef9")
+	opened, err := OpenAnonymous(nil, sealed, key32(t, bobPublic), key32(t, bobSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(opened) != "sealed box interop" {
+		t.Errorf("%q", opened)
+	}
+}
+
+func TestSealAnonymous(t *testing.T) {
+	pub, priv, err := GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("匿名消息")
+	sealed, err := SealAnonymous([]byte("prefix"), message, pub, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed = sealed[len("prefix"):]
+	if len(sealed) != len(message)+AnonymousOverhead {
+		t.Fatalf("密文长度 %d", len(sealed))
+	}
+	opened, err := OpenAnonymous(nil, sealed, pub, priv)
+	if err != nil || !bytes.Equal(opened, message) {
+		t.Fatalf("解密失败: %v", err)
+	}
+
+	for i := range sealed {
+		tampered := bytes.Clone(sealed)
+		tampered[i] ^= 1
+		if _, err := OpenAnonymous(nil, tampered, pub, priv); err != ErrAuthFailed {
+			t.Fatalf("篡改第%d字节后应返回ErrAuthFailed，实际: %v", i, err)
+		}
+	}
+	if _, err := OpenAnonymous(nil, sealed[:AnonymousOverhead-1], pub, priv); err != ErrAuthFailed {
+		t.Errorf("过短的密文: %v", err)
+	}
+	otherPub, otherPriv, _ := GenerateKey(nil)
+	if _, err := OpenAnonymous(nil, sealed, otherPub, otherPriv); err != ErrAuthFailed {
+		t.Errorf("其他接收者: %v", err)
+	}
+}
+
+// 测试拒绝小阶点公钥
+func TestLowOrderPublicKey(t *testing.T) {
+	_, priv, _ := GenerateKey(nil)
+	var zero [PublicKeySize]byte
+	var nonce [NonceSize]byte
+	if _, err := Seal(nil, []byte("x"), &nonce, &zero, priv); !errors.Is(err, ErrInvalidPublicKey) {
+		t.Errorf("全零公钥: %v", err)
+	}
+	if _, err := SealAnonymous(nil, []byte("x"), &zero, nil); !errors.Is(err, ErrInvalidPublicKey) {
+		t.Errorf("匿名加密的全零公钥: %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+// Package secretbox 实现NaCl的crypto_secretbox（XSalsa20-Poly1305），输出与libsodium逐字节一致
+// 密文格式为 认证标签(16字节) || 密文，不含nonce
+package secretbox
+
+import (
+	"crypto/subtle"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/internal/alias"
+	"github.com/laenix/gsc/poly1305"
+	"github.com/laenix/gsc/salsa20"
+)
+
+const (
+	// KeySize 是密钥长度（字节）
+	KeySize = salsa20.KeySize
+	// NonceSize 是nonce长度（字节），足以随机生成
+	NonceSize = salsa20.NonceSizeX
+	// Overhead 是密文比明文多出的长度（字节）
+	Overhead = poly1305.TagSize
+)
+
+// ErrAuthFailed 表示密文认证失败
+var ErrAuthFailed = gscerr.New(gscerr.ErrAuthFailed, "secretbox: message authentication failed")
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrAuthFailed: "secretbox: 消息认证失败",
+	})
+}
+
+// Seal 加密并认证message，将结果追加到out之后返回
+// 同一密钥下nonce绝不能重复使用。out与message部分重叠时panic
+func Seal(out, message []byte, nonce *[NonceSize]byte, key *[KeySize]byte) []byte {
+	s, polyKey := setup(nonce, key)
+
+	ret, box := sliceForAppend(out, len(message)+Overhead)
+	if alias.InexactOverlap(box, message) {
+		panic("secretbox: invalid buffer overlap")
+	}
+	// 标签写在密文之前，原地加密时须先完成加密再写入标签
+	ciphertext := box[Overhead:]
+	copy(ciphertext, message)
+	s.XORKeyStream(ciphertext, ciphertext)
+
+	var tag [poly1305.TagSize]byte
+	poly1305.Sum(&tag, ciphertext, polyKey)
+	copy(box, tag[:])
+	return ret
+}
+
+// Open 验证并解密box，将明文追加到out之后返回，认证失败时返回ErrAuthFailed
+func Open(out, box []byte, nonce *[NonceSize]byte, key *[KeySize]byte) ([]byte, error) {
+	if len(box) < Overhead {
+		return nil, ErrAuthFailed
+	}
+	s, polyKey := setup(nonce, key)
+
+	var tag [poly1305.TagSize]byte
+	poly1305.Sum(&tag, box[Overhead:], polyKey)
+	if subtle.ConstantTimeCompare(tag[:], box[:Overhead]) != 1 {
+		return nil, ErrAuthFailed
+	}
+
+	ret, plaintext := sliceForAppend(out, len(box)-Overhead)
+	if alias.InexactOverlap(plaintext, box[Overhead:]) {
+		panic("secretbox: invalid buffer overlap")
+	}
+	s.XORKeyStream(plaintext, box[Overhead:])
+	return ret, nil
+}
+
+// setup 返回XSalsa20密钥流及由其前32字节构成的Poly1305一次性密钥
+// 与NaCl一致，密钥流第一个块的后32字节用于加密消息开头
+func setup(nonce *[NonceSize]byte, key *[KeySize]byte) (*salsa20.Cipher, *[poly1305.KeySize]byte) {
+	s, err := salsa20.New(key[:], nonce[:])
+	if err != nil {
+		panic(err)
+	}
+	var polyKey [poly1305.KeySize]byte
+	s.XORKeyStream(polyKey[:], polyKey[:])
+	return s, &polyKey
+}
+
+// sliceForAppend 将in扩展n字节，返回扩展后的切片及新增部分，与标准库相同
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
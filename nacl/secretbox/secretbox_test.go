@@ -0,0 +1,60 @@
+package secretbox
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// 测试与NaCl发行包tests/secretbox.c的输出一致
+func TestNaClVector(t *testing.T) {
+	var key [KeySize]byte
+	var nonce [NonceSize]byte
+	copy(key[:], mustHex(t, "1b27556473e985d462cd51197a9a46c76009549eac6474f206c4ee0844f68389"))
+	copy(nonce[:], mustHex(t, "69696ee955b62b73cd62bda875fc73d68219e0036b7a0b37"))
+	message := mustHex(t, "be075fc53c81f2d5cf141316ebeb0c7b5228c52a4c62cbd44b66849b64244ffce5ecbaaf33bd751a1ac728d45e6c61296cdc3c01233561f41db66cce314adb310e3be8250c46f06dceea3a7fa1348057e2f6556ad6b1318a024a838f21af1fde048977eb48f59ffd4924ca1c60902e52f0a089bc76897040e082f937763848645e0705")
+	want := mustHex(t, "f3ffc7703f9400e52a7dfb4b3d3305d98e993b9f48681273c29650ba32fc76ce48332ea7164d96a4476fb8c531a1186ac0dfc17c98dce87b4da7f011ec48c97271d2c20f9b928fe2270d6fb863d51738b48eeee314a7cc8ab932164548e526ae90224368517acfeabd6bb3732bc0e9da99832b61ca01b6de56244a9e88d5f9b37973f622a43d14a6599b1f654cb45a74e355a5")
+
+	box := Seal(nil, message, &nonce, &key)
+	if !bytes.Equal(box, want) {
+		t.Fatalf("密文不匹配\n预期: %x\n实际: %x", want, box)
+	}
+	opened, err := Open(nil, box, &nonce, &key)
+	if err != nil || !bytes.Equal(opened, message) {
+		t.Fatalf("解密失败: %v", err)
+	}
+
+	// 原地加密
+	buf := make([]byte, len(message), len(message)+Overhead)
+	copy(buf, message)
+	if sealed := Seal(buf[:0], buf, &nonce, &key); !bytes.Equal(sealed, want) {
+		t.Fatal("原地加密的结果不正确")
+	}
+}
+
+// 测试篡改的密文认证失败
+func TestOpenTampered(t *testing.T) {
+	var key [KeySize]byte
+	var nonce [NonceSize]byte
+	box := Seal([]byte("prefix"), []byte("秘密消息"), &nonce, &key)[len("prefix"):]
+
+	for i := range box {
+		tampered := bytes.Clone(box)
+		tampered[i] ^= 1
+		if _, err := Open(nil, tampered, &nonce, &key); err != ErrAuthFailed {
+			t.Fatalf("篡改第%d字节后应返回ErrAuthFailed，实际: %v", i, err)
+		}
+	}
+	if _, err := Open(nil, box[:Overhead-1], &nonce, &key); err != ErrAuthFailed {
+		t.Fatalf("过短的密文应返回ErrAuthFailed，实际: %v", err)
+	}
+}
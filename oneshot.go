@@ -0,0 +1,113 @@
+package gsc
+
+import (
+	"crypto/rand"
+	"io"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+// 错误定义
+var (
+	ErrCiphertextTooShort = gscerr.New(gscerr.ErrMalformed, "gsc: ciphertext too short to contain the IV")
+	ErrAADUnsupported     = gscerr.New(gscerr.ErrUnsupported, "gsc: additional data requires a GCM cipher suite")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrCiphertextTooShort: "gsc: 密文过短，无法取出IV",
+		ErrAADUnsupported:     "gsc: 只有GCM密码套件支持附加认证数据",
+	})
+}
+
+// EncryptOptions 是Encrypt和Decrypt的选项，nil表示全部使用默认值
+type EncryptOptions struct {
+	// Suite 是使用的密码套件（见NewCipherSuite和ParseTransformation），
+	// 为nil时按密钥长度使用AES-128/192/256-GCM
+	Suite *CipherSuite
+	// Random 是生成IV或nonce的随机源，为nil时使用crypto/rand.Reader
+	Random io.Reader
+	// AdditionalData 是参与认证但不加密的附加数据，解密时必须相同；
+	// 只有GCM套件可以使用，其余套件设置时返回ErrAADUnsupported
+	AdditionalData []byte
+}
+
+// Encrypt 使用随机生成的IV（GCM为nonce）加密plaintext，输出 IV || 密文
+// 每次调用都生成新的IV，同一密钥可以安全地加密多条消息，调用方无需自行管理IV
+func Encrypt(key, plaintext []byte, opts *EncryptOptions) ([]byte, error) {
+	suite, aad, err := opts.params(key)
+	if err != nil {
+		return nil, err
+	}
+	random := rand.Reader
+	if opts != nil && opts.Random != nil {
+		random = opts.Random
+	}
+
+	var iv []byte
+	if n := suite.IVSize(); n > 0 {
+		iv = make([]byte, n)
+		if _, err := io.ReadFull(random, iv); err != nil {
+			return nil, err
+		}
+	}
+	enc, err := suite.newCipher(key, iv)
+	if err != nil {
+		return nil, err
+	}
+	enc.aad = aad
+	ciphertext, err := enc.Encrypt(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return append(iv, ciphertext...), nil
+}
+
+// Decrypt 从ciphertext开头取出IV并解密Encrypt的输出，opts中的套件须与加密时相同
+func Decrypt(key, ciphertext []byte, opts *EncryptOptions) ([]byte, error) {
+	suite, aad, err := opts.params(key)
+	if err != nil {
+		return nil, err
+	}
+	n := suite.IVSize()
+	if len(ciphertext) < n {
+		return nil, ErrCiphertextTooShort
+	}
+
+	var iv []byte
+	if n > 0 {
+		iv = ciphertext[:n]
+	}
+	dec, err := suite.newCipher(key, iv)
+	if err != nil {
+		return nil, err
+	}
+	dec.aad = aad
+	return dec.Decrypt(ciphertext[n:])
+}
+
+// params 返回选项中的套件和附加数据，附加数据只能用于GCM套件
+func (o *EncryptOptions) params(key []byte) (*CipherSuite, []byte, error) {
+	suite, err := o.suite(key)
+	if err != nil || o == nil || len(o.AdditionalData) == 0 {
+		return suite, nil, err
+	}
+	if suite.mode != "GCM" {
+		return nil, nil, ErrAADUnsupported
+	}
+	return suite, o.AdditionalData, nil
+}
+
+// suite 返回选项中的套件，未指定时按密钥长度选择AES-GCM
+func (o *EncryptOptions) suite(key []byte) (*CipherSuite, error) {
+	if o != nil && o.Suite != nil {
+		return o.Suite, nil
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return newCipherSuite(suiteAlgorithms["AES"], len(key)*8, "GCM", "")
+	}
+	return nil, ErrInvalidKeySize
+}
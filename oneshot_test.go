@@ -0,0 +1,111 @@
+package gsc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/laenix/gsc/modes"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	plaintext := []byte("one-shot encryption with a fresh IV")
+
+	for _, spec := range []string{"", "AES-128-CBC", "SM4-CTR", "3DES-CBC/PKCS5", "AES/CFB8/NoPadding"} {
+		var opts *EncryptOptions
+		key := bytes.Repeat([]byte{0x42}, 32)
+		if spec != "" {
+			s, err := NewCipherSuite(spec)
+			if err != nil {
+				if s, err = ParseTransformation(spec); err != nil {
+					t.Fatalf("%s: %v", spec, err)
+				}
+			}
+			opts = &EncryptOptions{Suite: s}
+			if s.KeySize() > 0 {
+				key = key[:s.KeySize()]
+			} else {
+				key = key[:16]
+			}
+		}
+
+		c1, err := Encrypt(key, plaintext, opts)
+		if err != nil {
+			t.Fatalf("%q: %v", spec, err)
+		}
+		c2, err := Encrypt(key, plaintext, opts)
+		if err != nil {
+			t.Fatalf("%q: %v", spec, err)
+		}
+		if bytes.Equal(c1, c2) {
+			t.Fatalf("%q: 两次加密应使用不同的IV", spec)
+		}
+		got, err := Decrypt(key, c1, opts)
+		if err != nil || !bytes.Equal(got, plaintext) {
+			t.Fatalf("%q: 解密结果不正确: %v", spec, err)
+		}
+	}
+}
+
+// 测试默认套件为AES-GCM，输出为 nonce || 密文 || 标签
+func TestEncryptDefaultSuite(t *testing.T) {
+	key := bytes.Repeat([]byte{0x01}, 24)
+	nonce := bytes.Repeat([]byte{0x02}, 12)
+	plaintext := []byte("default suite")
+
+	got, err := Encrypt(key, plaintext, &EncryptOptions{Random: bytes.NewReader(nonce)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := NewCipherSuite("AES-192-GCM")
+	enc, _ := s.NewEncryptor(key, nonce)
+	want, _ := enc.Encrypt(plaintext)
+	if !bytes.Equal(got, append(nonce, want...)) {
+		t.Fatal("默认套件的输出格式不正确")
+	}
+
+	got[len(got)-1] ^= 1
+	if _, err := Decrypt(key, got, nil); err != modes.ErrAuthFailed {
+		t.Fatalf("篡改的密文应返回ErrAuthFailed，实际: %v", err)
+	}
+	if _, err := Decrypt(key, got[:11], nil); err != ErrCiphertextTooShort {
+		t.Fatalf("过短的密文应返回ErrCiphertextTooShort，实际: %v", err)
+	}
+	if _, err := Encrypt(key[:10], plaintext, nil); err != ErrInvalidKeySize {
+		t.Fatalf("10字节密钥应返回ErrInvalidKeySize，实际: %v", err)
+	}
+}
+
+// 测试GCM套件的附加认证数据，非GCM套件拒绝附加数据
+func TestEncryptAdditionalData(t *testing.T) {
+	key := bytes.Repeat([]byte{0x03}, 16)
+	plaintext := []byte("authenticated header")
+
+	for _, spec := range []string{"AES-128-GCM", "SM4-GCM"} {
+		s, err := NewCipherSuite(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts := &EncryptOptions{Suite: s, AdditionalData: []byte("header")}
+		ct, err := Encrypt(key, plaintext, opts)
+		if err != nil {
+			t.Fatalf("%s: %v", spec, err)
+		}
+		if got, err := Decrypt(key, ct, opts); err != nil || !bytes.Equal(got, plaintext) {
+			t.Fatalf("%s: 解密结果不正确: %v", spec, err)
+		}
+		if _, err := Decrypt(key, ct, &EncryptOptions{Suite: s, AdditionalData: []byte("other")}); err != modes.ErrAuthFailed {
+			t.Fatalf("%s: 附加数据不同应返回ErrAuthFailed，实际: %v", spec, err)
+		}
+		if _, err := Decrypt(key, ct, &EncryptOptions{Suite: s}); err != modes.ErrAuthFailed {
+			t.Fatalf("%s: 缺少附加数据应返回ErrAuthFailed，实际: %v", spec, err)
+		}
+	}
+
+	s, _ := NewCipherSuite("AES-128-CBC")
+	if _, err := Encrypt(key, plaintext, &EncryptOptions{Suite: s, AdditionalData: []byte("x")}); err != ErrAADUnsupported {
+		t.Fatalf("CBC套件应返回ErrAADUnsupported，实际: %v", err)
+	}
+	if _, err := Decrypt(key, make([]byte, 32), &EncryptOptions{Suite: s, AdditionalData: []byte("x")}); err != ErrAADUnsupported {
+		t.Fatalf("CBC套件应返回ErrAADUnsupported，实际: %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+package openssl
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"hash"
+	"strings"
+
+	"github.com/laenix/gsc/aes"
+	"github.com/laenix/gsc/des"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/kdf/evp"
+	"github.com/laenix/gsc/kdf/pbkdf2"
+	"github.com/laenix/gsc/modes"
+	"github.com/laenix/gsc/padding"
+	"github.com/laenix/gsc/sm4"
+)
+
+const (
+	// SaltedMagic 是openssl enc输出的文件头
+	SaltedMagic = "Salted__"
+	// SaltSize 是文件头中盐的长度
+	SaltSize = evp.SaltSize
+	// DefaultIterations 是openssl enc -pbkdf2 的默认迭代次数
+	DefaultIterations = 10000
+)
+
+// 错误定义
+var (
+	ErrUnsupportedCipher = gscerr.New(gscerr.ErrUnsupported, "openssl: unsupported cipher")
+	ErrNotSalted         = gscerr.New(gscerr.ErrMalformed, "openssl: missing Salted__ header")
+	ErrInvalidSaltSize   = gscerr.New(gscerr.ErrParameter, "openssl: salt must be 8 bytes")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrUnsupportedCipher: "openssl: 不支持的算法",
+		ErrNotSalted:         "openssl: 缺少Salted__文件头",
+		ErrInvalidSaltSize:   "openssl: 盐必须是8字节",
+	})
+}
+
+// Options 对应openssl enc的密钥派生选项
+type Options struct {
+	// Digest 为密钥派生使用的摘要（-md），默认SHA-256；OpenSSL 1.1.0之前的默认值为MD5
+	Digest func() hash.Hash
+	// PBKDF2 对应 -pbkdf2，使用PBKDF2代替EVP_BytesToKey
+	PBKDF2 bool
+	// Iterations 对应 -iter；PBKDF2为true且未设置时使用DefaultIterations
+	Iterations int
+	// Salt 加密时使用的盐（-S），为空时随机生成
+	Salt []byte
+	// AllowInsecure 显式允许ECB等不安全的模式，作为modes.AllowInsecure()传给modes.NewECB；
+	// 未设置时ECB会输出安全警告，严格策略（modes.SetStrictPolicy）下返回modes.ErrInsecureMode
+	AllowInsecure bool
+}
+
+// cipherSpec 描述一个openssl算法名对应的分组算法和模式
+type cipherSpec struct {
+	keySize int
+	ivSize  int
+	mode    string
+	newFunc func(key []byte) (modes.BlockCipher, error)
+}
+
+// cipherAliases 是openssl中省略模式的三重DES算法名
+var cipherAliases = map[string]string{
+	"des3":     "des-ede3-cbc",
+	"des-ede3": "des-ede3-ecb",
+	"des-ede":  "des-ede-ecb",
+}
+
+// lookupCipher 解析openssl的算法名，如 aes-256-cbc、sm4-ctr、des-cbc、des-ede3-cbc
+func lookupCipher(name string) (*cipherSpec, error) {
+	name = strings.ToLower(name)
+	if alias, ok := cipherAliases[name]; ok {
+		name = alias
+	}
+	parts := strings.Split(name, "-")
+
+	spec := &cipherSpec{}
+	switch {
+	case len(parts) == 3 && parts[0] == "aes":
+		switch parts[1] {
+		case "128":
+			spec.keySize = aes.KeySize128
+		case "192":
+			spec.keySize = aes.KeySize192
+		case "256":
+			spec.keySize = aes.KeySize256
+		default:
+			return nil, ErrUnsupportedCipher
+		}
+		spec.ivSize = aes.BlockSize
+		spec.newFunc = func(key []byte) (modes.BlockCipher, error) { return aes.New(key) }
+	case len(parts) == 2 && parts[0] == "sm4":
+		spec.keySize = 16
+		spec.ivSize = 16
+		spec.newFunc = func(key []byte) (modes.BlockCipher, error) { return sm4.New(key) }
+	case len(parts) == 2 && parts[0] == "des":
+		spec.keySize = 8
+		spec.ivSize = 8
+		spec.newFunc = func(key []byte) (modes.BlockCipher, error) { return des.New(key) }
+	case len(parts) == 3 && parts[0] == "des" && (parts[1] == "ede" || parts[1] == "ede3"):
+		// ede为双密钥3DES（K3 = K1），ede3为三密钥3DES
+		spec.keySize = des.TripleKeySize
+		if parts[1] == "ede" {
+			spec.keySize = 2 * des.KeySize
+		}
+		spec.ivSize = des.BlockSize
+		spec.newFunc = func(key []byte) (modes.BlockCipher, error) { return des.NewTripleDES(key) }
+	default:
+		return nil, ErrUnsupportedCipher
+	}
+
+	spec.mode = parts[len(parts)-1]
+	switch spec.mode {
+	case "cbc", "cfb", "cfb1", "cfb8", "ofb", "ctr":
+	case "ecb":
+		spec.ivSize = 0
+	default:
+		return nil, ErrUnsupportedCipher
+	}
+	return spec, nil
+}
+
+// Encrypt 以openssl enc兼容的方式加密，输出 "Salted__" || 盐 || 密文
+// 等价于 openssl enc -<cipherName> -pass pass:<password> [-md ...] [-pbkdf2 -iter ...]
+func Encrypt(cipherName string, password, plaintext []byte, opts *Options) ([]byte, error) {
+	spec, err := lookupCipher(cipherName)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	salt := opts.Salt
+	if salt == nil {
+		salt = make([]byte, SaltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+	} else if len(salt) != SaltSize {
+		return nil, ErrInvalidSaltSize
+	}
+
+	key, iv, err := deriveKey(spec, password, salt, opts)
+	if err != nil {
+		return nil, err
+	}
+	body, err := crypt(spec, key, iv, plaintext, true, opts.AllowInsecure)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(SaltedMagic)+SaltSize+len(body))
+	out = append(out, SaltedMagic...)
+	out = append(out, salt...)
+	return append(out, body...), nil
+}
+
+// Decrypt 解密openssl enc生成的带Salted__文件头的数据
+// opts中的Digest、PBKDF2和Iterations必须与加密时一致，Salt字段被忽略
+func Decrypt(cipherName string, password, data []byte, opts *Options) ([]byte, error) {
+	spec, err := lookupCipher(cipherName)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	salt, body, err := ParseSalted(data)
+	if err != nil {
+		return nil, err
+	}
+
+	key, iv, err := deriveKey(spec, password, salt, opts)
+	if err != nil {
+		return nil, err
+	}
+	return crypt(spec, key, iv, body, false, opts.AllowInsecure)
+}
+
+// ParseSalted 拆分 "Salted__" || 盐 || 密文 格式的数据
+func ParseSalted(data []byte) ([]byte, []byte, error) {
+	if len(data) < len(SaltedMagic)+SaltSize || !bytes.HasPrefix(data, []byte(SaltedMagic)) {
+		return nil, nil, ErrNotSalted
+	}
+	salt := data[len(SaltedMagic) : len(SaltedMagic)+SaltSize]
+	return salt, data[len(SaltedMagic)+SaltSize:], nil
+}
+
+// deriveKey 按openssl enc的规则从口令派生密钥和IV
+func deriveKey(spec *cipherSpec, password, salt []byte, opts *Options) ([]byte, []byte, error) {
+	digest := opts.Digest
+	if digest == nil {
+		digest = sha256.New
+	}
+
+	if !opts.PBKDF2 {
+		iterations := opts.Iterations
+		if iterations == 0 {
+			iterations = 1
+		}
+		return evp.BytesToKey(digest, password, salt, iterations, spec.keySize, spec.ivSize)
+	}
+
+	iterations := opts.Iterations
+	if iterations == 0 {
+		iterations = DefaultIterations
+	}
+	// -pbkdf2 一次性派生密钥和IV
+	material, err := pbkdf2.Key(digest, password, salt, iterations, spec.keySize+spec.ivSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	return material[:spec.keySize], material[spec.keySize:], nil
+}
+
+// crypt 按模式执行加密或解密；ECB和CBC使用PKCS#7填充，allowInsecure为调用方对ECB的显式许可
+func crypt(spec *cipherSpec, key, iv, data []byte, encrypt, allowInsecure bool) ([]byte, error) {
+	block, err := spec.newFunc(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var mode modes.Mode
+	switch spec.mode {
+	case "ecb":
+		var opts []modes.Option
+		if allowInsecure {
+			opts = append(opts, modes.AllowInsecure())
+		}
+		mode, err = modes.NewECB(block, opts...)
+	case "cbc":
+		mode, err = modes.NewCBC(block, iv)
+	case "cfb":
+		mode, err = modes.NewCFB(block, iv)
+	case "cfb1":
+		mode, err = modes.NewCFB1(block, iv)
+	case "cfb8":
+		mode, err = modes.NewCFB8(block, iv)
+	case "ofb":
+		mode, err = modes.NewOFB(block, iv)
+	case "ctr":
+		mode, err = modes.NewCTR(block, iv)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	padded := spec.mode == "ecb" || spec.mode == "cbc"
+	if encrypt {
+		if padded {
+			if data, err = padding.PKCS7Padding(data, block.BlockSize()); err != nil {
+				return nil, err
+			}
+		}
+		return mode.Encrypt(data)
+	}
+
+	out, err := mode.Decrypt(data)
+	if err != nil {
+		return nil, err
+	}
+	if padded {
+		return padding.PKCS7UnPadding(out)
+	}
+	return out, nil
+}
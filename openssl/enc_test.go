@@ -0,0 +1,118 @@
+package openssl
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/laenix/gsc/modes"
+	"github.com/laenix/gsc/sm3"
+)
+
+// 测试向量由以下命令生成（OpenSSL 3，-S指定盐时输出不含文件头，测试中补上）：
+// echo -n "The quick brown fox jumps over the lazy dog" | openssl enc -<算法> -pass pass:gsc -S a1b2c3d4e5f60718 [选项]
+func TestOpenSSLVectors(t *testing.T) {
+	plaintext := []byte("The quick brown fox jumps over the lazy dog")
+	salt, _ := hex.DecodeString("a1b2c3d4e5f60718")
+
+	tests := []struct {
+		cipher string
+		opts   *Options
+		body   string
+	}{
+		{"aes-256-cbc", nil, "2e40db28aa2b989bc0dfc40fb96536c6fffc02eac0dad7fbe9013bde2e04a3500cc861568925ccfd172797763eb4ee12"},
+		{"aes-128-ctr", nil, "635007465de3f825289a1646d9d6d9f708246600d2de0ce22230ec9be6525ea2d136175ba850547000813b"},
+		{"aes-128-cfb", nil, "635007465de3f825289a1646d9d6d9f746838b5253e5c6c80b17ab9ac3b27366eff63b9982fd038571b92d"},
+		{"aes-128-cfb1", nil, "080bd2651bed790f095e229e2336d7fce18fd8a106b127bc62ef9cfff3e58fa8a4af3f89c9a21148967fe7"},
+		{"aes-128-cfb8", nil, "636671a58f8659c88314226647ffc3d7dfd7c3d56b8c5c473840fd57ff4f748c06093308970a52d8331d5f"},
+		{"sm4-cbc", nil, "56864c872ad053a35e84dbe5a5c49e3a4517d0432b7c7b509c28f5f7bea531af9001b4d16d63aed42fba03466782cfd5"},
+		{"des-cbc", nil, "98aab5871a81824922568a7fc64badb3dc4c0fab51dd3bbb8988e96974b8ba5f7258b57af51fe8714f171b12c52ecd5e"},
+		{"des-ede3-cbc", nil, "963ee4d979746c76765ff70d661ba561905610b71fc2b5bcd4cfaa3f5df2f9f456456a5aa0a19454de712490f4db1312"},
+		{"des3", nil, "963ee4d979746c76765ff70d661ba561905610b71fc2b5bcd4cfaa3f5df2f9f456456a5aa0a19454de712490f4db1312"},
+		{"des-ede3-ofb", nil, "906b96964cc63ff91f2a2183cace69ab859aab7a5048245b3c2ed30a0e48879160b862c0948eabe7e3154d"},
+		{"des-ede-cbc", nil, "90d1f5976995a66d0860326ee2b17a8f3a9d870133f40ea4cc35ef8f83c81817cf4e269d157ef302dbe6ed7c7784de63"},
+		{"des-ede3", &Options{AllowInsecure: true}, "c6ac86a37442f610a77fe15c1d2b67637717c793c00b932a5893eae5c7026b7c2801257a1de8a49d95931c18649d7b02"},
+		{"aes-256-cbc", &Options{Digest: md5.New}, "e6c85d3d0cadf1b4921df3dc602a060152cfde72d64024d6500b9478b1c56a63f32b6d1e3d4aaa233271bceabcf482d0"},
+		{"aes-256-cbc", &Options{PBKDF2: true}, "b43786f9f69d1133eaf48371924d3864385973db65b6b9a4d26f41fd8f0022bbba7d3270ce9a87020ced5acd0ad03f5a"},
+		{"sm4-ofb", &Options{PBKDF2: true, Iterations: 1000, Digest: sm3.New}, "85ade58afbc36fe1f52e4bdf87b5fd99fcd1af0b8a706f231ef03eb4d9f61098cee4b84d1ecf270f23fc7b"},
+	}
+
+	for i, tt := range tests {
+		body, _ := hex.DecodeString(tt.body)
+		data := append(append([]byte(SaltedMagic), salt...), body...)
+
+		got, err := Decrypt(tt.cipher, []byte("gsc"), data, tt.opts)
+		if err != nil {
+			t.Fatalf("测试 #%d %s: 解密失败: %v", i, tt.cipher, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Fatalf("测试 #%d %s: 明文不匹配: %q", i, tt.cipher, got)
+		}
+
+		opts := Options{Salt: salt}
+		if tt.opts != nil {
+			opts = *tt.opts
+			opts.Salt = salt
+		}
+		enc, err := Encrypt(tt.cipher, []byte("gsc"), plaintext, &opts)
+		if err != nil {
+			t.Fatalf("测试 #%d %s: 加密失败: %v", i, tt.cipher, err)
+		}
+		if !bytes.Equal(enc, data) {
+			t.Fatalf("测试 #%d %s: 密文不匹配:\n期望值: %x\n实际值: %x", i, tt.cipher, data, enc)
+		}
+	}
+}
+
+func TestRoundTripRandomSalt(t *testing.T) {
+	plaintext := []byte("随机盐往返测试")
+	data, err := Encrypt("aes-128-cbc", []byte("pw"), plaintext, nil)
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	got, err := Decrypt("aes-128-cbc", []byte("pw"), data, nil)
+	if err != nil {
+		t.Fatalf("解密失败: %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Fatalf("明文不匹配: %q", got)
+	}
+}
+
+func TestInvalidInput(t *testing.T) {
+	if _, err := Encrypt("rc4", nil, nil, nil); !errors.Is(err, ErrUnsupportedCipher) {
+		t.Errorf("期望ErrUnsupportedCipher，实际: %v", err)
+	}
+	if _, err := Decrypt("aes-128-cbc", nil, []byte("not salted data"), nil); !errors.Is(err, ErrNotSalted) {
+		t.Errorf("期望ErrNotSalted，实际: %v", err)
+	}
+	if _, err := Encrypt("aes-128-cbc", nil, nil, &Options{Salt: []byte{1}}); !errors.Is(err, ErrInvalidSaltSize) {
+		t.Errorf("期望ErrInvalidSaltSize，实际: %v", err)
+	}
+}
+
+// 测试严格策略下ECB必须由调用方通过AllowInsecure显式允许
+func TestECBRequiresOptIn(t *testing.T) {
+	modes.SetStrictPolicy(true)
+	defer modes.SetStrictPolicy(false)
+
+	plaintext := []byte("legacy record")
+	if _, err := Encrypt("aes-128-ecb", []byte("pw"), plaintext, nil); !errors.Is(err, modes.ErrInsecureMode) {
+		t.Fatalf("未显式允许时期望ErrInsecureMode，实际: %v", err)
+	}
+
+	opts := &Options{AllowInsecure: true}
+	data, err := Encrypt("aes-128-ecb", []byte("pw"), plaintext, opts)
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	if _, err := Decrypt("aes-128-ecb", []byte("pw"), data, nil); !errors.Is(err, modes.ErrInsecureMode) {
+		t.Fatalf("解密未显式允许时期望ErrInsecureMode，实际: %v", err)
+	}
+	got, err := Decrypt("aes-128-ecb", []byte("pw"), data, opts)
+	if err != nil || !bytes.Equal(got, plaintext) {
+		t.Fatalf("解密失败: %v %q", err, got)
+	}
+}
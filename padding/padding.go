@@ -3,9 +3,33 @@ package padding
 import (
 	"bytes"
 	"crypto/rand"
-	"errors"
+	"crypto/subtle"
+	"io"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+// 错误定义
+var (
+	ErrEmptyData          = gscerr.New(gscerr.ErrPadding, "padding: empty data")
+	ErrInvalidPaddingSize = gscerr.New(gscerr.ErrPadding, "padding: invalid padding size")
+	ErrInvalidPadding     = gscerr.New(gscerr.ErrPadding, "padding: invalid padding")
+	ErrPaddingNotFound    = gscerr.New(gscerr.ErrPadding, "padding: padding byte 0x80 not found")
+	ErrAllZero            = gscerr.New(gscerr.ErrPadding, "padding: data is all zeros")
 )
 
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrEmptyData:          "padding: 数据为空",
+		ErrInvalidPaddingSize: "padding: 填充长度无效",
+		ErrInvalidPadding:     "padding: 填充格式无效",
+		ErrPaddingNotFound:    "padding: 未找到0x80填充字节",
+		ErrAllZero:            "padding: 数据全部为0",
+	})
+}
+
 // PKCS#7 填充
 func PKCS7Padding(data []byte, blockSize int) ([]byte, error) {
 	padding := blockSize - len(data)%blockSize
@@ -79,10 +103,19 @@ func PKCS5Padding(data []byte) ([]byte, error) {
 
 // ISO10126 填充 (除最后一个字节外使用随机字节填充)
 func ISO10126Padding(data []byte, blockSize int) ([]byte, error) {
+	return ISO10126PaddingWithRand(data, blockSize, rand.Reader)
+}
+
+// ISO10126 填充，随机字节从random读取，random为nil时使用crypto/rand
+// 可传入确定性随机源用于测试，或传入DRBG用于需要审计随机源的部署
+func ISO10126PaddingWithRand(data []byte, blockSize int, random io.Reader) ([]byte, error) {
+	if random == nil {
+		random = rand.Reader
+	}
 	padding := blockSize - len(data)%blockSize
 	padtext := make([]byte, padding)
 	// 生成随机字节
-	if _, err := rand.Read(padtext[:padding-1]); err != nil {
+	if _, err := io.ReadFull(random, padtext[:padding-1]); err != nil {
 		return nil, err
 	}
 	// 最后一个字节表示填充长度
@@ -104,15 +137,26 @@ func TBCPadding(data []byte, blockSize int) ([]byte, error) {
 }
 
 // PKCS7 解填充
+// 填充的校验以常量时间进行：执行时间只与数据长度有关，与填充长度和内容无关，
+// 且任何格式错误都只返回ErrInvalidPadding，避免CBC等模式下构成填充预言攻击
 func PKCS7UnPadding(data []byte) ([]byte, error) {
 	length := len(data)
 	if length == 0 {
-		return nil, errors.New("empty data")
+		return nil, ErrEmptyData
 	}
 
 	unpadding := int(data[length-1])
-	if unpadding > length {
-		return nil, errors.New("invalid padding size")
+	// 填充长度必须在[1, length]之间
+	good := subtle.ConstantTimeLessOrEq(1, unpadding) & subtle.ConstantTimeLessOrEq(unpadding, length)
+
+	// 无论填充长度是多少，都检查末尾最多255个字节，逐字节校验位于填充内的字节
+	for i := 1; i <= min(length, 255); i++ {
+		inPadding := subtle.ConstantTimeLessOrEq(i, unpadding)
+		equal := subtle.ConstantTimeByteEq(data[length-i], byte(unpadding))
+		good &= ^(inPadding &^ equal) & 1
+	}
+	if good != 1 {
+		return nil, ErrInvalidPadding
 	}
 
 	return data[:(length - unpadding)], nil
@@ -123,11 +167,26 @@ func PKCS5UnPadding(data []byte) ([]byte, error) {
 	return PKCS7UnPadding(data)
 }
 
+// ISO10126 解填充，填充内容是随机的，只校验最后一个字节表示的填充长度
+func ISO10126UnPadding(data []byte) ([]byte, error) {
+	length := len(data)
+	if length == 0 {
+		return nil, ErrEmptyData
+	}
+
+	unpadding := int(data[length-1])
+	if subtle.ConstantTimeLessOrEq(1, unpadding)&subtle.ConstantTimeLessOrEq(unpadding, length) != 1 {
+		return nil, ErrInvalidPadding
+	}
+
+	return data[:(length - unpadding)], nil
+}
+
 // ISO7816 解填充
 func ISO7816UnPadding(data []byte) ([]byte, error) {
 	length := len(data)
 	if length == 0 {
-		return nil, errors.New("empty data")
+		return nil, ErrEmptyData
 	}
 
 	// 从后向前查找0x80
@@ -136,28 +195,28 @@ func ISO7816UnPadding(data []byte) ([]byte, error) {
 			return data[:i], nil
 		}
 		if data[i] != 0x00 {
-			return nil, errors.New("invalid ISO7816 padding")
+			return nil, ErrInvalidPadding
 		}
 	}
-	return nil, errors.New("padding byte 0x80 not found")
+	return nil, ErrPaddingNotFound
 }
 
 // ANSIX923 解填充
 func ANSIX923UnPadding(data []byte) ([]byte, error) {
 	length := len(data)
 	if length == 0 {
-		return nil, errors.New("empty data")
+		return nil, ErrEmptyData
 	}
 
 	unpadding := int(data[length-1])
 	if unpadding > length {
-		return nil, errors.New("invalid padding size")
+		return nil, ErrInvalidPaddingSize
 	}
 
 	// 验证填充字节是否都为0
 	for i := length - unpadding; i < length-1; i++ {
 		if data[i] != 0x00 {
-			return nil, errors.New("invalid ANSI X.923 padding")
+			return nil, ErrInvalidPadding
 		}
 	}
 
@@ -167,7 +226,7 @@ func ANSIX923UnPadding(data []byte) ([]byte, error) {
 // Zero 解填充
 func ZeroUnPadding(data []byte) ([]byte, error) {
 	if len(data) == 0 {
-		return nil, errors.New("empty data")
+		return nil, ErrEmptyData
 	}
 
 	// 从后向前查找非零字节
@@ -176,14 +235,14 @@ func ZeroUnPadding(data []byte) ([]byte, error) {
 			return data[:(i + 1)], nil
 		}
 	}
-	return nil, errors.New("all zero data")
+	return nil, ErrAllZero
 }
 
 // M1 解填充
 func M1UnPadding(data []byte) ([]byte, error) {
 	length := len(data)
 	if length == 0 {
-		return nil, errors.New("empty data")
+		return nil, ErrEmptyData
 	}
 
 	// 从后向前查找0x80
@@ -192,10 +251,10 @@ func M1UnPadding(data []byte) ([]byte, error) {
 			return data[:i], nil
 		}
 		if data[i] != 0x00 {
-			return nil, errors.New("invalid M1 padding")
+			return nil, ErrInvalidPadding
 		}
 	}
-	return nil, errors.New("padding byte 0x80 not found")
+	return nil, ErrPaddingNotFound
 }
 
 // M2 解填充
@@ -207,7 +266,7 @@ func M2UnPadding(data []byte) ([]byte, error) {
 func TBCUnPadding(data []byte) ([]byte, error) {
 	length := len(data)
 	if length == 0 {
-		return nil, errors.New("empty data")
+		return nil, ErrEmptyData
 	}
 
 	lastByte := data[length-1]
@@ -219,5 +278,5 @@ func TBCUnPadding(data []byte) ([]byte, error) {
 			return data[:i+1], nil
 		}
 	}
-	return nil, errors.New("invalid TBC padding")
+	return nil, ErrInvalidPadding
 }
@@ -0,0 +1,107 @@
+package padding
+
+import (
+	"bytes"
+	"testing"
+)
+
+// 测试PKCS7解填充接受合法填充，并对各种非法填充返回同一错误
+func TestPKCS7UnPadding(t *testing.T) {
+	for n := 0; n <= 40; n++ {
+		data := bytes.Repeat([]byte{0xaa}, n)
+		for _, blockSize := range []int{8, 16} {
+			padded, _ := PKCS7Padding(bytes.Clone(data), blockSize)
+			got, err := PKCS7UnPadding(padded)
+			if err != nil || !bytes.Equal(got, data) {
+				t.Fatalf("n=%d blockSize=%d: 解填充失败: %v", n, blockSize, err)
+			}
+		}
+	}
+
+	for _, tt := range []struct {
+		name string
+		data []byte
+	}{
+		{"填充长度为0", []byte{1, 2, 3, 0}},
+		{"填充长度超过数据长度", []byte{5, 5, 5, 5}},
+		{"填充字节不一致", []byte{1, 2, 4, 3, 3}},
+		{"填充首字节不一致", []byte{9, 4, 4, 4, 4, 4, 4, 4, 8}},
+		{"255字节填充中有错误", append(append([]byte{0}, bytes.Repeat([]byte{0xff}, 100)...), append([]byte{0}, bytes.Repeat([]byte{0xff}, 154)...)...)},
+	} {
+		if _, err := PKCS7UnPadding(tt.data); err != ErrInvalidPadding {
+			t.Errorf("%s: 期望ErrInvalidPadding，实际: %v", tt.name, err)
+		}
+	}
+
+	valid := append([]byte{0}, bytes.Repeat([]byte{0xff}, 255)...)
+	if got, err := PKCS7UnPadding(valid); err != nil || len(got) != 1 {
+		t.Fatalf("255字节填充应合法: %v", err)
+	}
+	if _, err := PKCS7UnPadding(nil); err != ErrEmptyData {
+		t.Fatalf("期望ErrEmptyData，实际: %v", err)
+	}
+}
+
+// 测试按名称查找填充方式，以及注册自定义填充方式
+func TestRegistry(t *testing.T) {
+	data := []byte("registry")
+	for _, name := range []string{"PKCS#7", "pkcs7", "PKCS5", "ANSIX923", "ISO10126", "M1(+0)", "None"} {
+		pad, unpad, err := Get(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		padded, err := pad(bytes.Clone(data), 16)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		got, err := unpad(padded)
+		if err != nil || !bytes.Equal(got, data) {
+			t.Fatalf("%s: 填充后未能还原数据: %v", name, err)
+		}
+	}
+
+	if _, _, err := Get("PKCS#1"); err != ErrUnknownScheme {
+		t.Fatalf("期望ErrUnknownScheme，实际: %v", err)
+	}
+
+	// 注册只能进行一次，测试结束后移除，使-count>1时仍可重复运行
+	t.Cleanup(func() {
+		schemesMu.Lock()
+		delete(schemes, normalize("Test-Length"))
+		schemesMu.Unlock()
+	})
+	Register("Test-Length", func(data []byte, blockSize int) ([]byte, error) {
+		return append(data, byte(len(data))), nil
+	}, func(data []byte) ([]byte, error) {
+		return data[:len(data)-1], nil
+	})
+	pad, _, err := Get("TEST_LENGTH")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if padded, _ := pad([]byte{1, 2}, 8); !bytes.Equal(padded, []byte{1, 2, 2}) {
+		t.Fatalf("自定义填充方式的结果错误: %x", padded)
+	}
+}
+
+// 测试重复注册同名填充方式时panic，按规范化后的名称比较
+func TestRegisterDuplicate(t *testing.T) {
+	for _, name := range []string{"PKCS#7", "pkcs7", "zero"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("重复注册%s时期望panic", name)
+				}
+			}()
+			Register(name, ZeroPadding, ZeroUnPadding)
+		}()
+	}
+	// 内置方式未被替换
+	pad, _, err := Get("PKCS7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if padded, _ := pad([]byte{1}, 4); !bytes.Equal(padded, []byte{1, 3, 3, 3}) {
+		t.Fatalf("PKCS#7被替换: %x", padded)
+	}
+}
@@ -0,0 +1,107 @@
+package padding
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+// ErrUnknownScheme 表示按名称查找时没有对应的填充方式
+var ErrUnknownScheme = gscerr.New(gscerr.ErrUnsupported, "padding: unknown padding scheme")
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrUnknownScheme: "padding: 未知的填充方式",
+	})
+}
+
+// PadFunc 按块大小blockSize填充数据
+type PadFunc func(data []byte, blockSize int) ([]byte, error)
+
+// UnpadFunc 移除PadFunc添加的填充
+type UnpadFunc func(data []byte) ([]byte, error)
+
+type scheme struct {
+	pad   PadFunc
+	unpad UnpadFunc
+}
+
+var (
+	schemesMu sync.RWMutex
+	schemes   = map[string]scheme{}
+)
+
+func init() {
+	Register("PKCS#7", PKCS7Padding, PKCS7UnPadding)
+	// 与Java等常见实现一致，按名称使用PKCS#5时按实际块大小填充，8字节块下与PKCS5Padding相同
+	Register("PKCS#5", PKCS7Padding, PKCS5UnPadding)
+	Register("M1", M1Padding, M1UnPadding)
+	Register("M1(+0)", M1PlusZeroPadding, M1UnPadding)
+	Register("M2", M2Padding, M2UnPadding)
+	Register("ISO7816", ISO7816Padding, ISO7816UnPadding)
+	Register("ANSIX923", ANSIX923Padding, ANSIX923UnPadding)
+	Register("ISO10126", ISO10126Padding, ISO10126UnPadding)
+	Register("Zero", ZeroPadding, ZeroUnPadding)
+	Register("TBC", TBCPadding, TBCUnPadding)
+	Register("None", NoPadding, noUnPadding)
+}
+
+// Register 以name注册一种填充方式
+// 名称不区分大小写，并忽略其中的'#'、'-'、'_'和空格，如"PKCS#7"与"pkcs7"相同。
+// 与database/sql.Register一样，name为空、pad或unpad为nil，或同名方式已注册时panic，
+// 内置的填充方式不会被悄悄替换
+func Register(name string, pad PadFunc, unpad UnpadFunc) {
+	key := normalize(name)
+	if key == "" || pad == nil || unpad == nil {
+		panic("padding: Register called with an invalid padding")
+	}
+	schemesMu.Lock()
+	defer schemesMu.Unlock()
+	if _, dup := schemes[key]; dup {
+		panic("padding: Register called twice for " + name)
+	}
+	schemes[key] = scheme{pad: pad, unpad: unpad}
+}
+
+// Get 按名称查找填充方式，返回对应的填充和解填充函数，名称规则见Register
+func Get(name string) (PadFunc, UnpadFunc, error) {
+	schemesMu.RLock()
+	defer schemesMu.RUnlock()
+	s, ok := schemes[normalize(name)]
+	if !ok {
+		return nil, nil, ErrUnknownScheme
+	}
+	return s.pad, s.unpad, nil
+}
+
+// Names 返回已注册填充方式的规范化名称，按字母顺序排列
+func Names() []string {
+	schemesMu.RLock()
+	defer schemesMu.RUnlock()
+	names := make([]string, 0, len(schemes))
+	for name := range schemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// normalize 将名称转为大写并去掉分隔符
+func normalize(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '#', '-', '_', ' ':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(name))
+}
+
+// noUnPadding 是None填充的解填充，原样返回数据
+func noUnPadding(data []byte) ([]byte, error) {
+	return data, nil
+}
@@ -0,0 +1,309 @@
+// Package paseto 实现PASETO（Platform-Agnostic Security Tokens）的v2和v4版本
+//
+// local令牌使用对称密钥加密并认证载荷：v2为XChaCha20-Poly1305，v4为XChaCha20加BLAKE2b-MAC；
+// public令牌使用Ed25519签名，载荷不加密。令牌格式为 版本.用途.base64url(载荷) [.base64url(footer)]。
+// 与JWT不同，算法完全由版本和用途决定，验证方在调用时指定版本，不读取令牌自带的算法字段，
+// 因此不存在算法混淆和"none"算法问题。
+//
+// footer以明文形式附在令牌末尾并参与认证，适合放置密钥标识等信息；
+// v4还支持implicit assertion，参与认证但不出现在令牌中
+package paseto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/laenix/gsc/blake2b"
+	"github.com/laenix/gsc/chacha20"
+	"github.com/laenix/gsc/chacha20poly1305"
+	"github.com/laenix/gsc/ed25519"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/subtle"
+)
+
+// Version 是PASETO协议版本
+type Version int
+
+// 支持的协议版本
+const (
+	V2 Version = 2
+	V4 Version = 4
+)
+
+const (
+	// KeySize 是local令牌的对称密钥长度（字节）
+	KeySize = 32
+
+	// v2 local的nonce长度
+	v2NonceSize = chacha20poly1305.NonceSizeX
+	// v4 local的随机nonce和认证标签长度
+	v4NonceSize = 32
+	v4TagSize   = 32
+)
+
+// 错误定义
+var (
+	ErrUnsupportedVersion = gscerr.New(gscerr.ErrUnsupported, "paseto: unsupported version")
+	ErrInvalidKeySize     = gscerr.New(gscerr.ErrKeySize, "paseto: invalid key size")
+	ErrInvalidToken       = gscerr.New(gscerr.ErrMalformed, "paseto: invalid token format")
+	ErrHeaderMismatch     = gscerr.New(gscerr.ErrVerification, "paseto: token header does not match the expected version and purpose")
+	ErrAuthFailed         = gscerr.New(gscerr.ErrAuthFailed, "paseto: message authentication failed")
+	ErrInvalidSignature   = gscerr.New(gscerr.ErrVerification, "paseto: invalid signature")
+	ErrImplicitAssertion  = gscerr.New(gscerr.ErrParameter, "paseto: v2 does not support implicit assertions")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrUnsupportedVersion: "paseto: 不支持的版本",
+		ErrInvalidKeySize:     "paseto: 密钥长度无效",
+		ErrInvalidToken:       "paseto: 令牌格式无效",
+		ErrHeaderMismatch:     "paseto: 令牌头部与预期的版本和用途不一致",
+		ErrAuthFailed:         "paseto: 令牌认证失败",
+		ErrInvalidSignature:   "paseto: 签名无效",
+		ErrImplicitAssertion:  "paseto: v2不支持implicit assertion",
+	})
+}
+
+// b64 是PASETO使用的无填充base64url编码
+var b64 = base64.RawURLEncoding
+
+// GenerateKey 生成随机的local令牌密钥
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Encrypt 使用key生成local令牌，implicit只用于v4，v2时必须为空
+func Encrypt(v Version, key, message, footer, implicit []byte) (string, error) {
+	var nonce []byte
+	switch v {
+	case V2:
+		nonce = make([]byte, v2NonceSize)
+	case V4:
+		nonce = make([]byte, v4NonceSize)
+	default:
+		return "", ErrUnsupportedVersion
+	}
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	return encrypt(v, key, message, footer, implicit, nonce)
+}
+
+// Decrypt 验证并解密local令牌，返回载荷和footer
+// footer未经调用方确认前不应信任，需要时应与预期值比较
+func Decrypt(v Version, key []byte, token string, implicit []byte) (message, footer []byte, err error) {
+	if err := checkLocal(v, key, implicit); err != nil {
+		return nil, nil, err
+	}
+	h := header(v, "local")
+	payload, footer, err := split(token, h)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if v == V2 {
+		if len(payload) < v2NonceSize+chacha20poly1305.Overhead {
+			return nil, nil, ErrInvalidToken
+		}
+		aead, err := chacha20poly1305.NewX(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		n, c := payload[:v2NonceSize], payload[v2NonceSize:]
+		message, err := aead.Open(nil, n, c, pae([]byte(h), n, footer))
+		if err != nil {
+			return nil, nil, ErrAuthFailed
+		}
+		return message, footer, nil
+	}
+
+	if len(payload) < v4NonceSize+v4TagSize {
+		return nil, nil, ErrInvalidToken
+	}
+	n, c, t := payload[:v4NonceSize], payload[v4NonceSize:len(payload)-v4TagSize], payload[len(payload)-v4TagSize:]
+	ek, n2, ak := v4Keys(key, n)
+	mac, _ := blake2b.New256(ak)
+	mac.Write(pae([]byte(h), n, c, footer, implicit))
+	if subtle.ConstantTimeCompare(mac.Sum(nil), t) != 1 {
+		return nil, nil, ErrAuthFailed
+	}
+	xc, err := chacha20.New(ek, n2)
+	if err != nil {
+		return nil, nil, err
+	}
+	message = make([]byte, len(c))
+	xc.XORKeyStream(message, c)
+	return message, footer, nil
+}
+
+// Sign 使用Ed25519私钥生成public令牌，implicit只用于v4，v2时必须为空
+// crypto/ed25519的私钥格式相同，可直接转换为ed25519.PrivateKey
+func Sign(v Version, priv ed25519.PrivateKey, message, footer, implicit []byte) (string, error) {
+	if err := checkPublic(v, len(priv), ed25519.PrivateKeySize, implicit); err != nil {
+		return "", err
+	}
+	h := header(v, "public")
+	sig, err := ed25519.Sign(priv, signedData(v, h, message, footer, implicit))
+	if err != nil {
+		return "", err
+	}
+	return join(h, append(bytes.Clone(message), sig...), footer), nil
+}
+
+// Verify 验证public令牌的签名，返回载荷和footer
+func Verify(v Version, pub ed25519.PublicKey, token string, implicit []byte) (message, footer []byte, err error) {
+	if err := checkPublic(v, len(pub), ed25519.PublicKeySize, implicit); err != nil {
+		return nil, nil, err
+	}
+	h := header(v, "public")
+	payload, footer, err := split(token, h)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(payload) < ed25519.SignatureSize {
+		return nil, nil, ErrInvalidToken
+	}
+	message, sig := payload[:len(payload)-ed25519.SignatureSize], payload[len(payload)-ed25519.SignatureSize:]
+	if !ed25519.Verify(pub, signedData(v, h, message, footer, implicit), sig) {
+		return nil, nil, ErrInvalidSignature
+	}
+	return message, footer, nil
+}
+
+// encrypt 使用给定的nonce生成local令牌；v2的nonce是派生真正nonce的BLAKE2b密钥
+func encrypt(v Version, key, message, footer, implicit, nonce []byte) (string, error) {
+	if err := checkLocal(v, key, implicit); err != nil {
+		return "", err
+	}
+	h := header(v, "local")
+
+	if v == V2 {
+		// nonce = BLAKE2b-192(key = 随机数, message)，随机数生成器有缺陷时仍不会对不同消息重复nonce
+		d, err := blake2b.New(v2NonceSize, nonce)
+		if err != nil {
+			return "", err
+		}
+		d.Write(message)
+		n := d.Sum(nil)
+		aead, err := chacha20poly1305.NewX(key)
+		if err != nil {
+			return "", err
+		}
+		return join(h, aead.Seal(n, n, message, pae([]byte(h), n, footer)), footer), nil
+	}
+
+	ek, n2, ak := v4Keys(key, nonce)
+	xc, err := chacha20.New(ek, n2)
+	if err != nil {
+		return "", err
+	}
+	payload := append(bytes.Clone(nonce), make([]byte, len(message))...)
+	c := payload[v4NonceSize:]
+	xc.XORKeyStream(c, message)
+	mac, _ := blake2b.New256(ak)
+	mac.Write(pae([]byte(h), nonce, c, footer, implicit))
+	return join(h, mac.Sum(payload), footer), nil
+}
+
+// v4Keys 从密钥和nonce派生v4 local的加密密钥、XChaCha20 nonce和认证密钥
+func v4Keys(key, nonce []byte) (ek, n2, ak []byte) {
+	d, _ := blake2b.New(56, key)
+	d.Write([]byte("paseto-encryption-key"))
+	d.Write(nonce)
+	tmp := d.Sum(nil)
+
+	d, _ = blake2b.New256(key)
+	d.Write([]byte("paseto-auth-key-for-aead"))
+	d.Write(nonce)
+	return tmp[:chacha20.KeySize], tmp[chacha20.KeySize:], d.Sum(nil)
+}
+
+// checkLocal 检查local令牌的版本、密钥和implicit assertion
+func checkLocal(v Version, key, implicit []byte) error {
+	if v != V2 && v != V4 {
+		return ErrUnsupportedVersion
+	}
+	if len(key) != KeySize {
+		return gscerr.KeySize(ErrInvalidKeySize, "PASETO local", len(key), KeySize)
+	}
+	if v == V2 && len(implicit) > 0 {
+		return ErrImplicitAssertion
+	}
+	return nil
+}
+
+// checkPublic 检查public令牌的版本、密钥长度和implicit assertion
+func checkPublic(v Version, keyLen, want int, implicit []byte) error {
+	if v != V2 && v != V4 {
+		return ErrUnsupportedVersion
+	}
+	if keyLen != want {
+		return gscerr.KeySize(ErrInvalidKeySize, "Ed25519", keyLen, want)
+	}
+	if v == V2 && len(implicit) > 0 {
+		return ErrImplicitAssertion
+	}
+	return nil
+}
+
+// signedData 返回public令牌的签名输入，v2的PAE中没有implicit assertion
+func signedData(v Version, h string, message, footer, implicit []byte) []byte {
+	if v == V2 {
+		return pae([]byte(h), message, footer)
+	}
+	return pae([]byte(h), message, footer, implicit)
+}
+
+// header 返回令牌头部，如"v4.local."
+func header(v Version, purpose string) string {
+	return "v" + strconv.Itoa(int(v)) + "." + purpose + "."
+}
+
+// join 拼接头部、编码后的载荷和footer
+func join(h string, payload, footer []byte) string {
+	token := h + b64.EncodeToString(payload)
+	if len(footer) > 0 {
+		token += "." + b64.EncodeToString(footer)
+	}
+	return token
+}
+
+// split 检查令牌头部并解码载荷和footer
+func split(token, h string) (payload, footer []byte, err error) {
+	if !strings.HasPrefix(token, h) {
+		return nil, nil, ErrHeaderMismatch
+	}
+	body, foot, hasFooter := strings.Cut(token[len(h):], ".")
+	if payload, err = b64.DecodeString(body); err != nil {
+		return nil, nil, ErrInvalidToken
+	}
+	if hasFooter {
+		if footer, err = b64.DecodeString(foot); err != nil || len(footer) == 0 {
+			return nil, nil, ErrInvalidToken
+		}
+	}
+	return payload, footer, nil
+}
+
+// pae 是PASETO的预认证编码（Pre-Authentication Encoding）：
+// LE64(片段数) || 每个片段的 LE64(长度) || 片段，LE64清除最高位
+func pae(pieces ...[]byte) []byte {
+	var out []byte
+	out = binary.LittleEndian.AppendUint64(out, uint64(len(pieces))&(1<<63-1))
+	for _, p := range pieces {
+		out = binary.LittleEndian.AppendUint64(out, uint64(len(p))&(1<<63-1))
+		out = append(out, p...)
+	}
+	return out
+}
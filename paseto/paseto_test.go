@@ -0,0 +1,170 @@
+package paseto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/laenix/gsc/ed25519"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// PASETO官方测试向量（github.com/paseto-standard/test-vectors）2-E-1、4-E-1、2-S-1和4-S-1
+var (
+	vectorKey    = mustHex("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	vectorSecret = mustHex("b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a3774" +
+		"1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+)
+
+func TestLocalVectors(t *testing.T) {
+	tests := []struct {
+		v       Version
+		nonce   []byte
+		payload string
+		token   string
+	}{
+		{V2, make([]byte, v2NonceSize), `{"data":"this is a signed message","exp":"2019-01-01T00:00:00+00:00"}`,
+			"v2.local.97TTOvgwIxNGvV80XKiGZg_kD3tsXM_-qB4dZGHOeN1cTkgQ4PnW8888l802W8d9AvEGnoNBY3BnqHORy8a5cC8aKpbA0En8XELw2yDk2f1sVODyfnDbi6rEGMY3pSfCbLWMM2oHJxvlEl2XbQ"},
+		{V4, make([]byte, v4NonceSize), `{"data":"this is a secret message","exp":"2022-01-01T00:00:00+00:00"}`,
+			"v4.local.AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAQAr68PS4AXe7If_ZgesdkUMvSwscFlAl1pk5HC0e8kApeaqMfGo_7OpBnwJOAbY9V7WU6abu74MmcUE8YWAiaArVI8XJ5hOb_4v9RmDkneN0S92dx0OW4pgy7omxgf3S8c3LlQg"},
+	}
+	for _, tt := range tests {
+		tok, err := encrypt(tt.v, vectorKey, []byte(tt.payload), nil, nil, tt.nonce)
+		if err != nil || tok != tt.token {
+			t.Errorf("v%d: 生成的令牌 = %s, %v", tt.v, tok, err)
+		}
+		msg, footer, err := Decrypt(tt.v, vectorKey, tt.token, nil)
+		if err != nil || string(msg) != tt.payload || footer != nil {
+			t.Errorf("v%d: 解密结果 = %q, %q, %v", tt.v, msg, footer, err)
+		}
+	}
+}
+
+func TestPublicVectors(t *testing.T) {
+	priv := ed25519.PrivateKey(vectorSecret)
+	pub := priv.Public().(ed25519.PublicKey)
+	tests := []struct {
+		v       Version
+		payload string
+		token   string
+	}{
+		{V2, `{"data":"this is a signed message","exp":"2019-01-01T00:00:00+00:00"}`,
+			"v2.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAxOS0wMS0wMVQwMDowMDowMCswMDowMCJ9HQr8URrGntTu7Dz9J2IF23d1M7-9lH9xiqdGyJNvzp4angPW5Esc7C5huy_M8I8_DjJK2ZXC2SUYuOFM-Q_5Cw"},
+		{V4, `{"data":"this is a signed message","exp":"2022-01-01T00:00:00+00:00"}`,
+			"v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9bg_XBBzds8lTZShVlwwKSgeKpLT3yukTw6JUz3W4h_ExsQV-P0V54zemZDcAxFaSeef1QlXEFtkqxT1ciiQEDA"},
+	}
+	for _, tt := range tests {
+		tok, err := Sign(tt.v, priv, []byte(tt.payload), nil, nil)
+		if err != nil || tok != tt.token {
+			t.Errorf("v%d: 生成的令牌 = %s, %v", tt.v, tok, err)
+		}
+		msg, _, err := Verify(tt.v, pub, tt.token, nil)
+		if err != nil || string(msg) != tt.payload {
+			t.Errorf("v%d: 验证结果 = %q, %v", tt.v, msg, err)
+		}
+	}
+}
+
+// 测试footer和implicit assertion参与认证
+func TestFooterAndImplicit(t *testing.T) {
+	key, _ := GenerateKey()
+	priv, _ := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{7}, ed25519.SeedSize))
+	pub := priv.Public().(ed25519.PublicKey)
+	footer := []byte(`{"kid":"key-1"}`)
+
+	for _, v := range []Version{V2, V4} {
+		var implicit []byte
+		if v == V4 {
+			implicit = []byte("user-42")
+		}
+		local, err := Encrypt(v, key, []byte("payload"), footer, implicit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		public, err := Sign(v, priv, []byte("payload"), footer, implicit)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		msg, gotFooter, err := Decrypt(v, key, local, implicit)
+		if err != nil || string(msg) != "payload" || !bytes.Equal(gotFooter, footer) {
+			t.Errorf("v%d local: %q, %q, %v", v, msg, gotFooter, err)
+		}
+		msg, gotFooter, err = Verify(v, pub, public, implicit)
+		if err != nil || string(msg) != "payload" || !bytes.Equal(gotFooter, footer) {
+			t.Errorf("v%d public: %q, %q, %v", v, msg, gotFooter, err)
+		}
+
+		// 替换footer
+		other := "." + b64.EncodeToString([]byte(`{"kid":"key-2"}`))
+		if _, _, err := Decrypt(v, key, local[:strings.LastIndex(local, ".")]+other, implicit); !errors.Is(err, ErrAuthFailed) {
+			t.Errorf("v%d local替换footer: %v", v, err)
+		}
+		if _, _, err := Verify(v, pub, public[:strings.LastIndex(public, ".")]+other, implicit); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("v%d public替换footer: %v", v, err)
+		}
+
+		if v == V4 {
+			if _, _, err := Decrypt(v, key, local, []byte("user-43")); !errors.Is(err, ErrAuthFailed) {
+				t.Errorf("implicit不同时local应失败: %v", err)
+			}
+			if _, _, err := Verify(v, pub, public, nil); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("implicit不同时public应失败: %v", err)
+			}
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	key, _ := GenerateKey()
+	priv, _ := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	v4local, _ := Encrypt(V4, key, []byte("m"), nil, nil)
+	v2local, _ := Encrypt(V2, key, []byte("m"), nil, nil)
+	v4public, _ := Sign(V4, priv, []byte("m"), nil, nil)
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"未知版本", func() error { _, err := Encrypt(3, key, nil, nil, nil); return err }(), ErrUnsupportedVersion},
+		{"密钥长度", func() error { _, err := Encrypt(V4, key[:16], nil, nil, nil); return err }(), ErrInvalidKeySize},
+		{"v2 implicit", func() error { _, err := Encrypt(V2, key, nil, nil, []byte("x")); return err }(), ErrImplicitAssertion},
+		{"v2 public implicit", func() error { _, err := Sign(V2, priv, nil, nil, []byte("x")); return err }(), ErrImplicitAssertion},
+		// 验证方指定版本和用途，令牌头部不同即拒绝
+		{"版本不符", func() error { _, _, err := Decrypt(V4, key, v2local, nil); return err }(), ErrHeaderMismatch},
+		{"用途不符", func() error { _, _, err := Decrypt(V4, key, v4public, nil); return err }(), ErrHeaderMismatch},
+		{"载荷非base64url", func() error { _, _, err := Decrypt(V4, key, "v4.local.!!!", nil); return err }(), ErrInvalidToken},
+		{"载荷过短", func() error { _, _, err := Decrypt(V4, key, "v4.local.AAAA", nil); return err }(), ErrInvalidToken},
+		{"空footer", func() error { _, _, err := Decrypt(V4, key, v4local+".", nil); return err }(), ErrInvalidToken},
+		{"密钥错误", func() error { _, _, err := Decrypt(V2, make([]byte, KeySize), v2local, nil); return err }(), ErrAuthFailed},
+		{"签名公钥错误", func() error {
+			_, _, err := Verify(V4, make(ed25519.PublicKey, ed25519.PublicKeySize), v4public, nil)
+			return err
+		}(), ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.want) {
+			t.Errorf("%s: 期望%v，实际%v", tt.name, tt.want, tt.err)
+		}
+	}
+
+	// 修改v4 local的任意字节都使认证失败
+	raw, _ := b64.DecodeString(strings.TrimPrefix(v4local, "v4.local."))
+	for _, i := range []int{0, v4NonceSize, len(raw) - 1} {
+		bad := bytes.Clone(raw)
+		bad[i] ^= 1
+		if _, _, err := Decrypt(V4, key, "v4.local."+b64.EncodeToString(bad), nil); !errors.Is(err, ErrAuthFailed) {
+			t.Errorf("修改第%d字节: %v", i, err)
+		}
+	}
+}
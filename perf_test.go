@@ -0,0 +1,180 @@
+package gsc
+
+import (
+	"encoding/json"
+	"flag"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/laenix/gsc/aes"
+	"github.com/laenix/gsc/modes"
+	"github.com/laenix/gsc/sm3"
+	"github.com/laenix/gsc/sm4"
+)
+
+// 性能回归测试
+//
+// testdata/bench.json 按硬件类别记录各算法的吞吐量基线（MB/s）。
+// 普通的 go test 不会执行回归比较，需要通过环境变量指定硬件类别：
+//
+//	GSC_BENCH_CLASS=ci-amd64 go test -run TestPerfRegression .
+//
+// 在目标机器上更新基线：
+//
+//	GSC_BENCH_CLASS=ci-amd64 go test -run TestPerfRegression -update-baseline .
+var updateBaseline = flag.Bool("update-baseline", false, "将本次测得的吞吐量写入testdata/bench.json")
+
+// benchSize 是单次操作处理的数据量
+const benchSize = 8 * 1024
+
+// baselineFile 是基线文件的结构
+type baselineFile struct {
+	// Threshold 为允许的最大吞吐量下降比例
+	Threshold float64 `json:"threshold"`
+	// Classes 为硬件类别 -> 算法名 -> 吞吐量（MB/s）
+	Classes map[string]map[string]float64 `json:"classes"`
+}
+
+// perfCase 是一个需要跟踪性能的算法
+type perfCase struct {
+	name string
+	fn   func(b *testing.B)
+}
+
+func perfCases() []perfCase {
+	return []perfCase{
+		{"AES-128-GCM", BenchmarkAESGCM},
+		{"SM4-CTR", BenchmarkSM4CTR},
+		{"SM3", BenchmarkSM3},
+	}
+}
+
+func BenchmarkAESGCM(b *testing.B) {
+	block, err := aes.New(make([]byte, 16))
+	if err != nil {
+		b.Fatal(err)
+	}
+	gcm, err := modes.NewGCM(block)
+	if err != nil {
+		b.Fatal(err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	data := make([]byte, benchSize)
+
+	b.SetBytes(benchSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := gcm.Seal(nonce, data, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSM4CTR(b *testing.B) {
+	block, err := sm4.New(make([]byte, 16))
+	if err != nil {
+		b.Fatal(err)
+	}
+	ctr, err := modes.NewCTR(block, make([]byte, 16))
+	if err != nil {
+		b.Fatal(err)
+	}
+	data := make([]byte, benchSize)
+
+	b.SetBytes(benchSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ctr.Encrypt(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSM3(b *testing.B) {
+	h := sm3.New()
+	data := make([]byte, benchSize)
+
+	b.SetBytes(benchSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.Reset()
+		h.Write(data)
+		h.Sum(nil)
+	}
+}
+
+// TestPerfRegression 与基线比较吞吐量，下降超过阈值时失败
+func TestPerfRegression(t *testing.T) {
+	class := os.Getenv("GSC_BENCH_CLASS")
+	if class == "" {
+		t.Skip("未设置GSC_BENCH_CLASS，跳过性能回归测试")
+	}
+	if testing.Short() {
+		t.Skip("short模式下跳过性能回归测试")
+	}
+
+	path := filepath.Join("testdata", "bench.json")
+	baseline, err := loadBaseline(path)
+	if err != nil {
+		t.Fatalf("读取基线失败: %v", err)
+	}
+
+	measured := make(map[string]float64)
+	for _, c := range perfCases() {
+		r := testing.Benchmark(c.fn)
+		mbps := float64(r.Bytes) * float64(r.N) / r.T.Seconds() / 1e6
+		measured[c.name] = math.Round(mbps*100) / 100
+		t.Logf("%s: %.2f MB/s (%s/%s)", c.name, measured[c.name], runtime.GOOS, runtime.GOARCH)
+	}
+
+	if *updateBaseline {
+		baseline.Classes[class] = measured
+		if err := saveBaseline(path, baseline); err != nil {
+			t.Fatalf("写入基线失败: %v", err)
+		}
+		return
+	}
+
+	expected, ok := baseline.Classes[class]
+	if !ok {
+		t.Fatalf("基线中没有硬件类别 %q", class)
+	}
+	for name, want := range expected {
+		got, ok := measured[name]
+		if !ok {
+			t.Errorf("%s: 基线中的算法没有对应的基准测试", name)
+			continue
+		}
+		if got < want*(1-baseline.Threshold) {
+			t.Errorf("%s: 吞吐量 %.2f MB/s 低于基线 %.2f MB/s 超过 %.0f%%", name, got, want, baseline.Threshold*100)
+		}
+	}
+}
+
+// loadBaseline 读取基线文件
+func loadBaseline(path string) (*baselineFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	baseline := &baselineFile{}
+	if err := json.Unmarshal(data, baseline); err != nil {
+		return nil, err
+	}
+	if baseline.Classes == nil {
+		baseline.Classes = make(map[string]map[string]float64)
+	}
+	return baseline, nil
+}
+
+// saveBaseline 写入基线文件
+func saveBaseline(path string, baseline *baselineFile) error {
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
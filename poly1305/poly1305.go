@@ -0,0 +1,173 @@
+// Package poly1305 实现Poly1305一次性消息认证码（RFC 8439）
+// 同一个密钥只能认证一条消息，通常由ChaCha20或XSalsa20的密钥流生成
+package poly1305
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"math/bits"
+)
+
+const (
+	// KeySize 是一次性密钥长度（字节）
+	KeySize = 32
+	// TagSize 是认证标签长度（字节）
+	TagSize = 16
+)
+
+// 模数p = 2^130 - 5 按64位分段
+const (
+	p0 = 0xFFFFFFFFFFFFFFFB
+	p1 = 0xFFFFFFFFFFFFFFFF
+	p2 = 0x0000000000000003
+)
+
+// Sum 计算msg在key下的认证标签
+func Sum(out *[TagSize]byte, msg []byte, key *[KeySize]byte) {
+	m := New(key)
+	m.Write(msg)
+	m.finalize(out)
+}
+
+// Verify 以常数时间判断mac是否为msg在key下的认证标签
+func Verify(mac *[TagSize]byte, msg []byte, key *[KeySize]byte) bool {
+	var tag [TagSize]byte
+	Sum(&tag, msg, key)
+	return subtle.ConstantTimeCompare(tag[:], mac[:]) == 1
+}
+
+// MAC 增量计算Poly1305认证标签
+type MAC struct {
+	// h 是累加器，r 是截断后的乘数，s 是最后加上的掩码
+	h [3]uint64
+	r [2]uint64
+	s [2]uint64
+	// buf 缓存不足一个块的数据
+	buf    [TagSize]byte
+	offset int
+}
+
+// New 使用一次性密钥key创建MAC
+func New(key *[KeySize]byte) *MAC {
+	m := &MAC{}
+	m.r[0] = binary.LittleEndian.Uint64(key[0:8]) & 0x0FFFFFFC0FFFFFFF
+	m.r[1] = binary.LittleEndian.Uint64(key[8:16]) & 0x0FFFFFFC0FFFFFFC
+	m.s[0] = binary.LittleEndian.Uint64(key[16:24])
+	m.s[1] = binary.LittleEndian.Uint64(key[24:32])
+	return m
+}
+
+// Size 返回认证标签长度
+func (m *MAC) Size() int { return TagSize }
+
+// Write 添加数据，总是返回len(p)和nil
+func (m *MAC) Write(p []byte) (int, error) {
+	n := len(p)
+	if m.offset > 0 {
+		k := copy(m.buf[m.offset:], p)
+		m.offset += k
+		p = p[k:]
+		if m.offset < TagSize {
+			return n, nil
+		}
+		m.update(m.buf[:], true)
+		m.offset = 0
+	}
+	if full := len(p) - len(p)%TagSize; full > 0 {
+		m.update(p[:full], true)
+		p = p[full:]
+	}
+	m.offset = copy(m.buf[:], p)
+	return n, nil
+}
+
+// Sum 将认证标签追加到b之后返回，不改变当前状态
+func (m *MAC) Sum(b []byte) []byte {
+	var tag [TagSize]byte
+	c := *m
+	c.finalize(&tag)
+	return append(b, tag[:]...)
+}
+
+// Verify 以常数时间判断expected是否为已写入数据的认证标签
+func (m *MAC) Verify(expected []byte) bool {
+	return subtle.ConstantTimeCompare(m.Sum(nil), expected) == 1
+}
+
+// update 处理完整的16字节块，full为false时msg是最后一个不完整的块
+func (m *MAC) update(msg []byte, full bool) {
+	h0, h1, h2 := m.h[0], m.h[1], m.h[2]
+	r0, r1 := m.r[0], m.r[1]
+
+	for len(msg) > 0 {
+		var c uint64
+		if full {
+			h0, c = bits.Add64(h0, binary.LittleEndian.Uint64(msg[0:8]), 0)
+			h1, c = bits.Add64(h1, binary.LittleEndian.Uint64(msg[8:16]), c)
+			// 每个完整块末尾追加的0x01位于第128位
+			h2 += c + 1
+			msg = msg[TagSize:]
+		} else {
+			var block [TagSize]byte
+			copy(block[:], msg)
+			block[len(msg)] = 1
+			h0, c = bits.Add64(h0, binary.LittleEndian.Uint64(block[0:8]), 0)
+			h1, c = bits.Add64(h1, binary.LittleEndian.Uint64(block[8:16]), c)
+			h2 += c
+			msg = nil
+		}
+
+		// h *= r，h2不超过7且r的高位已被截断，h2*r不会超过64位
+		h0r0hi, h0r0lo := bits.Mul64(h0, r0)
+		h1r0hi, h1r0lo := bits.Mul64(h1, r0)
+		h0r1hi, h0r1lo := bits.Mul64(h0, r1)
+		h1r1hi, h1r1lo := bits.Mul64(h1, r1)
+		h2r0 := h2 * r0
+		h2r1 := h2 * r1
+
+		m1lo, c := bits.Add64(h1r0lo, h0r1lo, 0)
+		m1hi, _ := bits.Add64(h1r0hi, h0r1hi, c)
+		m2lo, c := bits.Add64(h2r0, h1r1lo, 0)
+		m2hi, _ := bits.Add64(0, h1r1hi, c)
+
+		t0 := h0r0lo
+		t1, c := bits.Add64(m1lo, h0r0hi, 0)
+		t2, c := bits.Add64(m2lo, m1hi, c)
+		t3, _ := bits.Add64(h2r1, m2hi, c)
+
+		// 按2^130 ≡ 5 (mod p)约简：高位部分cc为4倍的溢出值，再加上cc/4即为5倍
+		h0, h1, h2 = t0, t1, t2&3
+		cclo, cchi := t2&^3, t3
+		h0, c = bits.Add64(h0, cclo, 0)
+		h1, c = bits.Add64(h1, cchi, c)
+		h2 += c
+		cclo, cchi = cclo>>2|cchi<<62, cchi>>2
+		h0, c = bits.Add64(h0, cclo, 0)
+		h1, c = bits.Add64(h1, cchi, c)
+		h2 += c
+	}
+
+	m.h[0], m.h[1], m.h[2] = h0, h1, h2
+}
+
+// finalize 处理缓存的数据并输出认证标签
+func (m *MAC) finalize(out *[TagSize]byte) {
+	if m.offset > 0 {
+		m.update(m.buf[:m.offset], false)
+	}
+	h0, h1, h2 := m.h[0], m.h[1], m.h[2]
+
+	// 常数时间地计算h mod p：h-p不借位时取h-p
+	t0, b := bits.Sub64(h0, p0, 0)
+	t1, b := bits.Sub64(h1, p1, b)
+	_, b = bits.Sub64(h2, p2, b)
+	mask := b - 1
+	h0 = h0&^mask | t0&mask
+	h1 = h1&^mask | t1&mask
+
+	var c uint64
+	h0, c = bits.Add64(h0, m.s[0], 0)
+	h1, _ = bits.Add64(h1, m.s[1], c)
+	binary.LittleEndian.PutUint64(out[0:8], h0)
+	binary.LittleEndian.PutUint64(out[8:16], h1)
+}
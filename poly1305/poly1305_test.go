@@ -0,0 +1,76 @@
+package poly1305
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// 测试RFC 8439第2.5.2节的向量
+func TestSum(t *testing.T) {
+	var key [KeySize]byte
+	k, _ := hex.DecodeString("85d6be7857556d337f4452fe42d506a80103808afb0db2fd4abff6af4149f51b")
+	copy(key[:], k)
+	msg := []byte("Cryptographic Forum Research Group")
+	want, _ := hex.DecodeString("a8061dc1305136c6c22b8baf0c0127a9")
+
+	var tag [TagSize]byte
+	Sum(&tag, msg, &key)
+	if !bytes.Equal(tag[:], want) {
+		t.Fatalf("标签不匹配\n预期: %x\n实际: %x", want, tag)
+	}
+	if !Verify(&tag, msg, &key) {
+		t.Fatal("Verify应接受正确的标签")
+	}
+	tag[0] ^= 1
+	if Verify(&tag, msg, &key) {
+		t.Fatal("Verify应拒绝错误的标签")
+	}
+}
+
+// 测试分多次写入与一次性计算的结果相同
+func TestMACWrite(t *testing.T) {
+	var key [KeySize]byte
+	for i := range key {
+		key[i] = byte(i * 13)
+	}
+	msg := make([]byte, 200)
+	for i := range msg {
+		msg[i] = byte(i)
+	}
+
+	var want [TagSize]byte
+	Sum(&want, msg, &key)
+	for _, step := range []int{1, 7, 15, 16, 17, 64} {
+		m := New(&key)
+		for i := 0; i < len(msg); i += step {
+			m.Write(msg[i:min(i+step, len(msg))])
+		}
+		if !m.Verify(want[:]) {
+			t.Fatalf("每次写入%d字节时标签不匹配", step)
+		}
+	}
+}
+
+// 测试累加器接近2^130-5时的约简
+func TestSumEdgeCases(t *testing.T) {
+	// r = 0时标签等于s
+	var key [KeySize]byte
+	for i := 16; i < KeySize; i++ {
+		key[i] = 0xff
+	}
+	var tag [TagSize]byte
+	Sum(&tag, bytes.Repeat([]byte{0xff}, 48), &key)
+	if !bytes.Equal(tag[:], key[16:]) {
+		t.Fatalf("r为0时标签应等于s，实际: %x", tag)
+	}
+
+	// RFC 8439附录A.3的测试向量#10：乘法结果需要多次进位
+	key = [KeySize]byte{0: 1, 8: 4}
+	msg, _ := hex.DecodeString("e33594d7505e43b900000000000000003394d7505e4379cd01000000000000000000000000000000000000000000000001000000000000000000000000000000")
+	Sum(&tag, msg, &key)
+	want, _ := hex.DecodeString("14000000000000005500000000000000")
+	if !bytes.Equal(tag[:], want) {
+		t.Fatalf("标签不匹配\n预期: %x\n实际: %x", want, tag)
+	}
+}
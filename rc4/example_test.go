@@ -0,0 +1,42 @@
+package rc4_test
+
+import (
+	"fmt"
+
+	"github.com/laenix/gsc/rc4"
+)
+
+func Example() {
+	key := []byte("这是一个RC4密钥")
+	plaintext := []byte("这是需要加密的明文消息")
+
+	cipher, err := rc4.New(key)
+	if err != nil {
+		panic(err)
+	}
+	ciphertext, err := cipher.Encrypt(plaintext)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%x\n", ciphertext)
+
+	// RC4是流密码，解密需要从密钥流的起点开始，因此重新初始化
+	decipher, err := rc4.New(key)
+	if err != nil {
+		panic(err)
+	}
+	decrypted, err := decipher.Decrypt(ciphertext)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s\n", decrypted)
+
+	// Reset后再次加密得到相同的密文
+	cipher.Reset(key)
+	again, _ := cipher.Encrypt(plaintext)
+	fmt.Printf("%x\n", again)
+	// Output:
+	// be2045e8395b9d267c7b5680dcb9ce58bea3c8fa10588d5406a161aa40d3516690
+	// 这是需要加密的明文消息
+	// be2045e8395b9d267c7b5680dcb9ce58bea3c8fa10588d5406a161aa40d3516690
+}
@@ -1,7 +1,8 @@
 package rc4
 
 import (
-	"errors"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
 )
 
 const (
@@ -15,24 +16,32 @@ const (
 
 // RC4 结构体定义RC4密码
 type RC4 struct {
-	s    [StateSize]byte // 状态数组
-	i, j byte            // 状态索引
+	s       [StateSize]byte // 状态数组
+	i, j    byte            // 状态索引
+	keySize int             // 密钥长度（字节）
 }
 
 // 错误定义
 var (
-	ErrInvalidKeySize = errors.New("rc4: 密钥长度必须在1-256字节之间")
+	ErrInvalidKeySize = gscerr.New(gscerr.ErrKeySize, "rc4: key must be 1-256 bytes")
 )
 
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidKeySize: "rc4: 密钥长度必须在1-256字节之间",
+	})
+}
+
 // New 创建一个新的RC4实例
 func New(key []byte) (*RC4, error) {
 	// 验证密钥长度
 	if len(key) < MinKeySize || len(key) > MaxKeySize {
-		return nil, ErrInvalidKeySize
+		return nil, gscerr.KeySize(ErrInvalidKeySize, "RC4", len(key))
 	}
 
 	// 创建RC4实例
-	rc4 := &RC4{}
+	rc4 := &RC4{keySize: len(key)}
 
 	// 初始化状态
 	rc4.initState(key)
@@ -75,9 +84,19 @@ func (r *RC4) Decrypt(data []byte) ([]byte, error) {
 // crypt 对数据进行RC4加密/解密
 func (r *RC4) crypt(data []byte) []byte {
 	output := make([]byte, len(data))
-	copy(output, data)
+	r.XORKeyStream(output, data)
+	return output
+}
+
+// XORKeyStream 将src与密钥流异或后写入dst，dst与src可以是同一切片（原地处理），
+// 不分配内存。密钥流位置在多次调用之间连续推进（Encrypt/Decrypt也共享同一状态）；
+// dst长度小于src时panic
+func (r *RC4) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("rc4: output smaller than input")
+	}
 
-	for k := 0; k < len(output); k++ {
+	for k := range src {
 		// 更新状态索引
 		r.i = r.i + 1
 		r.j = r.j + r.s[r.i]
@@ -87,20 +106,24 @@ func (r *RC4) crypt(data []byte) []byte {
 
 		// 生成密钥流并与数据XOR
 		t := r.s[r.i] + r.s[r.j]
-		output[k] ^= r.s[t]
+		dst[k] = src[k] ^ r.s[t]
 	}
-
-	return output
 }
 
 // Reset 重置RC4状态为初始状态
 func (r *RC4) Reset(key []byte) error {
 	// 验证密钥长度
 	if len(key) < MinKeySize || len(key) > MaxKeySize {
-		return ErrInvalidKeySize
+		return gscerr.KeySize(ErrInvalidKeySize, "RC4", len(key))
 	}
 
 	// 重新初始化状态
+	r.keySize = len(key)
 	r.initState(key)
 	return nil
 }
+
+// KeySize 返回当前密钥的长度（字节）
+func (r *RC4) KeySize() int {
+	return r.keySize
+}
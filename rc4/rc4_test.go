@@ -175,3 +175,24 @@ func TestInvalidKeySize(t *testing.T) {
 		t.Error("超长密钥应该返回错误")
 	}
 }
+
+// 测试XORKeyStream分段原地处理的结果与一次性Encrypt相同，且不分配内存
+func TestRC4XORKeyStream(t *testing.T) {
+	key := []byte("Key")
+	plaintext := []byte("Plaintext streamed in several pieces")
+
+	c1, _ := New(key)
+	want, _ := c1.Encrypt(plaintext)
+
+	c2, _ := New(key)
+	buf := bytes.Clone(plaintext)
+	c2.XORKeyStream(buf[:5], buf[:5])
+	c2.XORKeyStream(buf[5:], buf[5:])
+	if !bytes.Equal(buf, want) {
+		t.Fatalf("分段结果不一致:\n期望值: %x\n实际值: %x", want, buf)
+	}
+
+	if allocs := testing.AllocsPerRun(10, func() { c2.XORKeyStream(buf, buf) }); allocs != 0 {
+		t.Fatalf("XORKeyStream分配了%v次内存", allocs)
+	}
+}
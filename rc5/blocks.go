@@ -0,0 +1,36 @@
+package rc5
+
+// EncryptBlocks 加密src中的多个块并写入dst，dst与src可以是同一切片
+// src长度必须是块大小的整数倍且dst不短于src，否则返回ErrInvalidBlockSize；
+// 与Encrypt一样，目前只支持32位字长
+func (r *RC5) EncryptBlocks(dst, src []byte) error {
+	if err := r.checkBlocks(dst, src); err != nil {
+		return err
+	}
+	for i := 0; i < len(src); i += r.blockSize {
+		r.encryptBlock(dst[i:i+r.blockSize], src[i:i+r.blockSize])
+	}
+	return nil
+}
+
+// DecryptBlocks 解密src中的多个块并写入dst，约定与EncryptBlocks相同
+func (r *RC5) DecryptBlocks(dst, src []byte) error {
+	if err := r.checkBlocks(dst, src); err != nil {
+		return err
+	}
+	for i := 0; i < len(src); i += r.blockSize {
+		r.decryptBlock(dst[i:i+r.blockSize], src[i:i+r.blockSize])
+	}
+	return nil
+}
+
+// checkBlocks 检查批量接口的输入输出长度和字长
+func (r *RC5) checkBlocks(dst, src []byte) error {
+	if len(src)%r.blockSize != 0 || len(dst) < len(src) {
+		return ErrInvalidBlockSize
+	}
+	if r.wordSize != 32 {
+		return ErrInvalidWordSize
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package rc5_test
+
+import (
+	"fmt"
+
+	"github.com/laenix/gsc/rc5"
+)
+
+func ExampleNew() {
+	// 默认参数为RC5-32/12/16
+	cipher, err := rc5.New([]byte("0123456789abcdef"))
+	if err != nil {
+		panic(err)
+	}
+
+	plaintext := []byte("Hello RC")
+	ciphertext, err := cipher.Encrypt(plaintext)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%x\n", ciphertext)
+
+	decrypted, err := cipher.Decrypt(ciphertext)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s\n", decrypted)
+	// Output:
+	// 95dd6b7042d223d0
+	// Hello RC
+}
+
+func ExampleNewWithParams() {
+	// RC5-32/16/24：24字节密钥，16轮
+	cipher, err := rc5.NewWithParams([]byte("0123456789abcdefghijklmn"), 16, 32)
+	if err != nil {
+		panic(err)
+	}
+
+	ciphertext, err := cipher.Encrypt([]byte("Hello RC"))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%x\n", ciphertext)
+	// Output:
+	// 6189928c68f0f10b
+}
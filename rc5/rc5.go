@@ -2,9 +2,11 @@ package rc5
 
 import (
 	"encoding/binary"
-	"errors"
 	"math"
 	"math/bits"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
 )
 
 const (
@@ -33,12 +35,22 @@ type RC5 struct {
 
 // 错误定义
 var (
-	ErrInvalidKeySize   = errors.New("rc5: 密钥长度必须在1-255字节之间")
-	ErrInvalidBlockSize = errors.New("rc5: 数据块大小不匹配")
-	ErrInvalidWordSize  = errors.New("rc5: 字长必须是32位(4字节)或64位(8字节)")
-	ErrInvalidRounds    = errors.New("rc5: 轮数必须在1-255之间")
+	ErrInvalidKeySize   = gscerr.New(gscerr.ErrKeySize, "rc5: key must be 1-255 bytes")
+	ErrInvalidBlockSize = gscerr.New(gscerr.ErrBlockSize, "rc5: block size mismatch")
+	ErrInvalidWordSize  = gscerr.New(gscerr.ErrParameter, "rc5: word size must be 32 bits (4 bytes) or 64 bits (8 bytes)")
+	ErrInvalidRounds    = gscerr.New(gscerr.ErrParameter, "rc5: rounds must be 1-255")
 )
 
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidKeySize:   "rc5: 密钥长度必须在1-255字节之间",
+		ErrInvalidBlockSize: "rc5: 数据块大小不匹配",
+		ErrInvalidWordSize:  "rc5: 字长必须是32位(4字节)或64位(8字节)",
+		ErrInvalidRounds:    "rc5: 轮数必须在1-255之间",
+	})
+}
+
 // New 创建一个新的RC5实例，使用默认参数(RC5-32/12/16)
 func New(key []byte) (*RC5, error) {
 	return NewWithParams(key, DefaultRounds, DefaultWordSize)
@@ -48,7 +60,7 @@ func New(key []byte) (*RC5, error) {
 func NewWithParams(key []byte, rounds, wordSize int) (*RC5, error) {
 	// 验证密钥长度
 	if len(key) < MinKeySize || len(key) > MaxKeySize {
-		return nil, ErrInvalidKeySize
+		return nil, gscerr.KeySize(ErrInvalidKeySize, "RC5", len(key))
 	}
 
 	// 验证轮数
@@ -79,6 +91,12 @@ func NewWithParams(key []byte, rounds, wordSize int) (*RC5, error) {
 	return rc5, nil
 }
 
+// Params 返回实例的参数：轮数、字长（位）和密钥长度（字节），
+// 即RC5-w/r/b记法中的r、w和b
+func (r *RC5) Params() (rounds, wordSize, keySize int) {
+	return r.rounds, r.wordSize, r.keySize
+}
+
 // BlockSize 返回区块大小
 func (r *RC5) BlockSize() int {
 	return r.blockSize
@@ -89,20 +107,20 @@ func (r *RC5) Encrypt(block []byte) ([]byte, error) {
 	if len(block) != r.blockSize {
 		return nil, ErrInvalidBlockSize
 	}
-
-	// 创建返回结果
+	// 未实现64位支持
+	if r.wordSize != 32 {
+		return nil, ErrInvalidWordSize
+	}
 	result := make([]byte, r.blockSize)
-	copy(result, block)
+	r.encryptBlock(result, block)
+	return result, nil
+}
 
+// encryptBlock 加密一个32位字长的块，dst与src可以是同一切片
+func (r *RC5) encryptBlock(dst, src []byte) {
 	// 读取A和B（两个字）
-	var A, B uint32
-	if r.wordSize == 32 {
-		A = binary.LittleEndian.Uint32(result[0:4])
-		B = binary.LittleEndian.Uint32(result[4:8])
-	} else {
-		// 未实现64位支持
-		return nil, ErrInvalidWordSize
-	}
+	A := binary.LittleEndian.Uint32(src[0:4])
+	B := binary.LittleEndian.Uint32(src[4:8])
 
 	// 执行加密
 	A = A + r.subKeys[0]
@@ -114,12 +132,8 @@ func (r *RC5) Encrypt(block []byte) ([]byte, error) {
 	}
 
 	// 写回结果
-	if r.wordSize == 32 {
-		binary.LittleEndian.PutUint32(result[0:4], A)
-		binary.LittleEndian.PutUint32(result[4:8], B)
-	}
-
-	return result, nil
+	binary.LittleEndian.PutUint32(dst[0:4], A)
+	binary.LittleEndian.PutUint32(dst[4:8], B)
 }
 
 // Decrypt 解密单个区块
@@ -127,20 +141,20 @@ func (r *RC5) Decrypt(block []byte) ([]byte, error) {
 	if len(block) != r.blockSize {
 		return nil, ErrInvalidBlockSize
 	}
-
-	// 创建返回结果
+	// 未实现64位支持
+	if r.wordSize != 32 {
+		return nil, ErrInvalidWordSize
+	}
 	result := make([]byte, r.blockSize)
-	copy(result, block)
+	r.decryptBlock(result, block)
+	return result, nil
+}
 
+// decryptBlock 解密一个32位字长的块，dst与src可以是同一切片
+func (r *RC5) decryptBlock(dst, src []byte) {
 	// 读取A和B（两个字）
-	var A, B uint32
-	if r.wordSize == 32 {
-		A = binary.LittleEndian.Uint32(result[0:4])
-		B = binary.LittleEndian.Uint32(result[4:8])
-	} else {
-		// 未实现64位支持
-		return nil, ErrInvalidWordSize
-	}
+	A := binary.LittleEndian.Uint32(src[0:4])
+	B := binary.LittleEndian.Uint32(src[4:8])
 
 	// 执行解密（逆序）
 	for i := r.rounds; i >= 1; i-- {
@@ -152,12 +166,8 @@ func (r *RC5) Decrypt(block []byte) ([]byte, error) {
 	A = A - r.subKeys[0]
 
 	// 写回结果
-	if r.wordSize == 32 {
-		binary.LittleEndian.PutUint32(result[0:4], A)
-		binary.LittleEndian.PutUint32(result[4:8], B)
-	}
-
-	return result, nil
+	binary.LittleEndian.PutUint32(dst[0:4], A)
+	binary.LittleEndian.PutUint32(dst[4:8], B)
 }
 
 // expandKey 生成轮子密钥
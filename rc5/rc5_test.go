@@ -3,6 +3,7 @@ package rc5
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"testing"
 )
 
@@ -74,14 +75,14 @@ func TestRc5EncryptDecrypt(t *testing.T) {
 func TestRc5InvalidParameters(t *testing.T) {
 	// 测试无效密钥长度
 	_, err := New([]byte{})
-	if err != ErrInvalidKeySize {
+	if !errors.Is(err, ErrInvalidKeySize) {
 		t.Errorf("对于空密钥，预期 ErrInvalidKeySize，但得到：%v", err)
 	}
 
 	// 测试溢出密钥长度
 	longKey := make([]byte, MaxKeySize+1)
 	_, err = New(longKey)
-	if err != ErrInvalidKeySize {
+	if !errors.Is(err, ErrInvalidKeySize) {
 		t.Errorf("对于过长密钥，预期 ErrInvalidKeySize，但得到：%v", err)
 	}
 
@@ -174,3 +175,16 @@ func TestRc5BlockSize(t *testing.T) {
 		t.Errorf("预期块大小为 %d，但得到：%d", BlockSize, cipher.BlockSize())
 	}
 }
+
+// 测试RC5的Params方法
+func TestRc5Params(t *testing.T) {
+	cipher, err := NewWithParams([]byte("0123456789abcdefghijklmn"), 16, 32)
+	if err != nil {
+		t.Fatalf("创建RC5实例失败: %v", err)
+	}
+
+	rounds, wordSize, keySize := cipher.Params()
+	if rounds != 16 || wordSize != 32 || keySize != 24 {
+		t.Errorf("预期参数为 RC5-32/16/24，但得到：RC5-%d/%d/%d", wordSize, rounds, keySize)
+	}
+}
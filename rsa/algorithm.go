@@ -0,0 +1,281 @@
+package rsa
+
+import "github.com/laenix/gsc/der"
+
+// RSASSA-PSS和RSAES-OAEP的算法标识（RFC 4055、RFC 8017附录C）
+var (
+	// OIDRSASSAPSS 是id-RSASSA-PSS，参数为RSASSA-PSS-params
+	OIDRSASSAPSS = der.OID{1, 2, 840, 113549, 1, 1, 10}
+	// OIDRSAESOAEP 是id-RSAES-OAEP，参数为RSAES-OAEP-params
+	OIDRSAESOAEP = der.OID{1, 2, 840, 113549, 1, 1, 7}
+
+	oidMGF1       = der.OID{1, 2, 840, 113549, 1, 1, 8}
+	oidPSpecified = der.OID{1, 2, 840, 113549, 1, 1, 9}
+)
+
+// 参数中各字段的默认值：SHA-1、MGF1-SHA-1、盐长度20、尾部字段1
+const (
+	defaultPSSSaltLength = 20
+	trailerFieldBC       = 1
+)
+
+// MarshalPSSAlgorithm 编码签名算法标识 AlgorithmIdentifier { id-RSASSA-PSS, RSASSA-PSS-params }，
+// 用于证书、CMS等结构中的signatureAlgorithm。opts为nil时盐长度等于摘要长度；
+// 盐长度必须是确定的值，不能是PSSSaltLengthAuto。与默认值相同的字段按DER要求省略
+//
+//	RSASSA-PSS-params ::= SEQUENCE {
+//	    hashAlgorithm      [0] HashAlgorithm    DEFAULT sha1,
+//	    maskGenAlgorithm   [1] MaskGenAlgorithm DEFAULT mgf1SHA1,
+//	    saltLength         [2] INTEGER          DEFAULT 20,
+//	    trailerField       [3] TrailerField     DEFAULT trailerFieldBC }
+func MarshalPSSAlgorithm(hash *Hash, opts *PSSOptions) ([]byte, error) {
+	saltLen := opts.saltLength()
+	switch {
+	case saltLen == PSSSaltLengthEqualsHash:
+		saltLen = hash.Size()
+	case saltLen <= 0:
+		return nil, ErrPSSSaltLength
+	}
+	mgfHash := opts.mgfHash(hash)
+
+	var b der.Builder
+	b.AddSequence(func(b *der.Builder) {
+		b.AddOID(OIDRSASSAPSS)
+		b.AddSequence(func(b *der.Builder) {
+			if hash != SHA1 {
+				b.AddExplicit(0, func(b *der.Builder) { addHashAlgorithm(b, hash) })
+			}
+			if mgfHash != SHA1 {
+				b.AddExplicit(1, func(b *der.Builder) { addMGF1Algorithm(b, mgfHash) })
+			}
+			if saltLen != defaultPSSSaltLength {
+				b.AddExplicit(2, func(b *der.Builder) { b.AddInt64(int64(saltLen)) })
+			}
+		})
+	})
+	return b.Bytes()
+}
+
+// ParsePSSAlgorithm 解析id-RSASSA-PSS的AlgorithmIdentifier，返回摘要算法和PSS参数
+// 返回的opts中盐长度和MGF1摘要算法都是确定的值，可直接用于VerifyPSS
+func ParsePSSAlgorithm(data []byte) (*Hash, *PSSOptions, error) {
+	params, err := parseAlgorithm(data, OIDRSASSAPSS)
+	if err != nil {
+		return nil, nil, err
+	}
+	hash, mgfHash := SHA1, SHA1
+	saltLen := int64(defaultPSSSaltLength)
+	if inner, present, err := params.ReadExplicit(0); err != nil {
+		return nil, nil, ErrMalformedAlgorithm
+	} else if present {
+		if hash, err = readHashAlgorithm(inner); err != nil || inner.Finish() != nil {
+			return nil, nil, algorithmError(err)
+		}
+	}
+	if inner, present, err := params.ReadExplicit(1); err != nil {
+		return nil, nil, ErrMalformedAlgorithm
+	} else if present {
+		if mgfHash, err = readMGF1Algorithm(inner); err != nil || inner.Finish() != nil {
+			return nil, nil, algorithmError(err)
+		}
+	}
+	if inner, present, err := params.ReadExplicit(2); err != nil {
+		return nil, nil, ErrMalformedAlgorithm
+	} else if present {
+		if saltLen, err = inner.ReadInt64(); err != nil || inner.Finish() != nil || saltLen < 0 || saltLen > 1<<16 {
+			return nil, nil, ErrMalformedAlgorithm
+		}
+	}
+	if inner, present, err := params.ReadExplicit(3); err != nil {
+		return nil, nil, ErrMalformedAlgorithm
+	} else if present {
+		trailer, err := inner.ReadInt64()
+		if err != nil || inner.Finish() != nil {
+			return nil, nil, ErrMalformedAlgorithm
+		}
+		if trailer != trailerFieldBC {
+			return nil, nil, ErrUnsupportedAlgorithm
+		}
+	}
+	if params.Finish() != nil {
+		return nil, nil, ErrMalformedAlgorithm
+	}
+	// PSSOptions中盐长度0表示PSSSaltLengthAuto，无法表示确定的0字节盐
+	if saltLen == 0 {
+		return nil, nil, ErrUnsupportedAlgorithm
+	}
+	return hash, &PSSOptions{SaltLength: int(saltLen), MGFHash: mgfHash}, nil
+}
+
+// MarshalOAEPAlgorithm 编码密钥加密算法标识 AlgorithmIdentifier { id-RSAES-OAEP, RSAES-OAEP-params }，
+// 用于CMS的KeyTransRecipientInfo等结构。与默认值相同的字段按DER要求省略
+//
+//	RSAES-OAEP-params ::= SEQUENCE {
+//	    hashAlgorithm      [0] HashAlgorithm     DEFAULT sha1,
+//	    maskGenAlgorithm   [1] MaskGenAlgorithm  DEFAULT mgf1SHA1,
+//	    pSourceAlgorithm   [2] PSourceAlgorithm  DEFAULT pSpecifiedEmpty }
+func MarshalOAEPAlgorithm(opts *OAEPOptions) ([]byte, error) {
+	hash, mgfHash, label := opts.hashes()
+
+	var b der.Builder
+	b.AddSequence(func(b *der.Builder) {
+		b.AddOID(OIDRSAESOAEP)
+		b.AddSequence(func(b *der.Builder) {
+			if hash != SHA1 {
+				b.AddExplicit(0, func(b *der.Builder) { addHashAlgorithm(b, hash) })
+			}
+			if mgfHash != SHA1 {
+				b.AddExplicit(1, func(b *der.Builder) { addMGF1Algorithm(b, mgfHash) })
+			}
+			if len(label) > 0 {
+				b.AddExplicit(2, func(b *der.Builder) {
+					b.AddSequence(func(b *der.Builder) {
+						b.AddOID(oidPSpecified)
+						b.AddOctetString(label)
+					})
+				})
+			}
+		})
+	})
+	return b.Bytes()
+}
+
+// ParseOAEPAlgorithm 解析id-RSAES-OAEP的AlgorithmIdentifier，返回的参数可直接用于DecryptOAEP
+// 注意OAEPOptions的零值使用SHA-256，而参数省略时的默认值是SHA-1，返回值中总是显式给出摘要算法
+func ParseOAEPAlgorithm(data []byte) (*OAEPOptions, error) {
+	params, err := parseAlgorithm(data, OIDRSAESOAEP)
+	if err != nil {
+		return nil, err
+	}
+	opts := &OAEPOptions{Hash: SHA1, MGFHash: SHA1}
+	if inner, present, err := params.ReadExplicit(0); err != nil {
+		return nil, ErrMalformedAlgorithm
+	} else if present {
+		if opts.Hash, err = readHashAlgorithm(inner); err != nil || inner.Finish() != nil {
+			return nil, algorithmError(err)
+		}
+	}
+	if inner, present, err := params.ReadExplicit(1); err != nil {
+		return nil, ErrMalformedAlgorithm
+	} else if present {
+		if opts.MGFHash, err = readMGF1Algorithm(inner); err != nil || inner.Finish() != nil {
+			return nil, algorithmError(err)
+		}
+	}
+	if inner, present, err := params.ReadExplicit(2); err != nil {
+		return nil, ErrMalformedAlgorithm
+	} else if present {
+		seq, err := inner.ReadSequence()
+		if err != nil || inner.Finish() != nil {
+			return nil, ErrMalformedAlgorithm
+		}
+		oid, err := seq.ReadOID()
+		if err != nil {
+			return nil, ErrMalformedAlgorithm
+		}
+		if !oid.Equal(oidPSpecified) {
+			return nil, ErrUnsupportedAlgorithm
+		}
+		if opts.Label, err = seq.ReadOctetString(); err != nil || seq.Finish() != nil {
+			return nil, ErrMalformedAlgorithm
+		}
+		if len(opts.Label) == 0 {
+			opts.Label = nil
+		}
+	}
+	if params.Finish() != nil {
+		return nil, ErrMalformedAlgorithm
+	}
+	return opts, nil
+}
+
+// parseAlgorithm 解析AlgorithmIdentifier，要求算法为oid，返回参数SEQUENCE的Parser
+func parseAlgorithm(data []byte, oid der.OID) (*der.Parser, error) {
+	p := der.NewParser(data)
+	seq, err := p.ReadSequence()
+	if err != nil || p.Finish() != nil {
+		return nil, ErrMalformedAlgorithm
+	}
+	got, err := seq.ReadOID()
+	if err != nil {
+		return nil, ErrMalformedAlgorithm
+	}
+	if !got.Equal(oid) {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	params, err := seq.ReadSequence()
+	if err != nil || seq.Finish() != nil {
+		return nil, ErrMalformedAlgorithm
+	}
+	return params, nil
+}
+
+// addHashAlgorithm 追加摘要算法的AlgorithmIdentifier，参数为NULL（与OpenSSL和crypto/x509一致）
+func addHashAlgorithm(b *der.Builder, hash *Hash) {
+	b.AddSequence(func(b *der.Builder) {
+		b.AddOID(hash.oid)
+		b.AddNull()
+	})
+}
+
+// addMGF1Algorithm 追加 AlgorithmIdentifier { id-mgf1, 摘要算法 }
+func addMGF1Algorithm(b *der.Builder, hash *Hash) {
+	b.AddSequence(func(b *der.Builder) {
+		b.AddOID(oidMGF1)
+		addHashAlgorithm(b, hash)
+	})
+}
+
+// readHashAlgorithm 读取摘要算法的AlgorithmIdentifier，参数可以是NULL或省略（RFC 4055第2.1节）
+func readHashAlgorithm(p *der.Parser) (*Hash, error) {
+	seq, err := p.ReadSequence()
+	if err != nil {
+		return nil, ErrMalformedAlgorithm
+	}
+	oid, err := seq.ReadOID()
+	if err != nil {
+		return nil, ErrMalformedAlgorithm
+	}
+	if !seq.Empty() {
+		if seq.ReadNull() != nil || seq.Finish() != nil {
+			return nil, ErrMalformedAlgorithm
+		}
+	}
+	for _, h := range supportedHashes {
+		if h.oid.Equal(oid) {
+			return h, nil
+		}
+	}
+	return nil, ErrUnsupportedAlgorithm
+}
+
+// readMGF1Algorithm 读取 AlgorithmIdentifier { id-mgf1, 摘要算法 }，其他掩码生成函数不支持
+func readMGF1Algorithm(p *der.Parser) (*Hash, error) {
+	seq, err := p.ReadSequence()
+	if err != nil {
+		return nil, ErrMalformedAlgorithm
+	}
+	oid, err := seq.ReadOID()
+	if err != nil {
+		return nil, ErrMalformedAlgorithm
+	}
+	if !oid.Equal(oidMGF1) {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	hash, err := readHashAlgorithm(seq)
+	if err != nil {
+		return nil, err
+	}
+	if seq.Finish() != nil {
+		return nil, ErrMalformedAlgorithm
+	}
+	return hash, nil
+}
+
+// algorithmError 将参数解析中的错误归为ErrUnsupportedAlgorithm或ErrMalformedAlgorithm
+func algorithmError(err error) error {
+	if err == ErrUnsupportedAlgorithm {
+		return err
+	}
+	return ErrMalformedAlgorithm
+}
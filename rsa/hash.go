@@ -0,0 +1,61 @@
+package rsa
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+
+	"github.com/laenix/gsc/der"
+	"github.com/laenix/gsc/rsa/internal"
+	"github.com/laenix/gsc/sm3"
+)
+
+// Hash 是RSA签名使用的摘要算法，记录构造函数、摘要长度、算法OID和PKCS#1 v1.5签名中DigestInfo的编码前缀
+type Hash struct {
+	name   string
+	size   int
+	new    func() hash.Hash
+	oid    der.OID
+	prefix []byte
+}
+
+// 支持的摘要算法
+var (
+	SHA1   = &Hash{"SHA-1", sha1.Size, sha1.New, der.OID{1, 3, 14, 3, 2, 26}, internal.SHA1Prefix}
+	SHA224 = &Hash{"SHA-224", sha256.Size224, sha256.New224, der.OID{2, 16, 840, 1, 101, 3, 4, 2, 4}, internal.SHA224Prefix}
+	SHA256 = &Hash{"SHA-256", sha256.Size, sha256.New, der.OID{2, 16, 840, 1, 101, 3, 4, 2, 1}, internal.SHA256Prefix}
+	SHA384 = &Hash{"SHA-384", sha512.Size384, sha512.New384, der.OID{2, 16, 840, 1, 101, 3, 4, 2, 2}, internal.SHA384Prefix}
+	SHA512 = &Hash{"SHA-512", sha512.Size, sha512.New, der.OID{2, 16, 840, 1, 101, 3, 4, 2, 3}, internal.SHA512Prefix}
+	SM3    = &Hash{"SM3", sm3.Size, sm3.New, der.OID{1, 2, 156, 10197, 1, 401}, internal.SM3Prefix}
+)
+
+// supportedHashes 是全部支持的摘要算法，用于按OID查找
+var supportedHashes = []*Hash{SHA1, SHA224, SHA256, SHA384, SHA512, SM3}
+
+// String 返回算法名称
+func (h *Hash) String() string {
+	return h.name
+}
+
+// Size 返回摘要长度（字节）
+func (h *Hash) Size() int {
+	return h.size
+}
+
+// New 返回新的hash.Hash
+func (h *Hash) New() hash.Hash {
+	return h.new()
+}
+
+// Sum 计算data的摘要
+func (h *Hash) Sum(data []byte) []byte {
+	d := h.new()
+	d.Write(data)
+	return d.Sum(nil)
+}
+
+// OID 返回算法的对象标识符
+func (h *Hash) OID() der.OID {
+	return h.oid
+}
@@ -0,0 +1,82 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+// decryptNaive 是改为常量时间实现之前的私钥运算：不盲化，直接用big.Int按CRT求幂
+// 只用于核对结果和基准测试对比
+func decryptNaive(priv *PrivateKey, c *big.Int) *big.Int {
+	if priv.Precomputed.Dp == nil {
+		return new(big.Int).Exp(c, priv.D, priv.N)
+	}
+	p, q := priv.Primes[0], priv.Primes[1]
+	m := new(big.Int).Exp(c, priv.Precomputed.Dp, p)
+	m2 := new(big.Int).Exp(c, priv.Precomputed.Dq, q)
+	m.Sub(m, m2)
+	if m.Sign() < 0 {
+		m.Add(m, p)
+	}
+	m.Mul(m, priv.Precomputed.Qinv)
+	m.Mod(m, p)
+	m.Mul(m, q)
+	m.Add(m, m2)
+	for i, v := range priv.Precomputed.CRTValues {
+		mi := new(big.Int).Exp(c, v.Exp, priv.Primes[2+i])
+		mi.Sub(mi, m)
+		mi.Mul(mi, v.Coeff)
+		mi.Mod(mi, priv.Primes[2+i])
+		mi.Mul(mi, v.R)
+		m.Add(m, mi)
+	}
+	return m
+}
+
+func TestDecryptMatchesNaive(t *testing.T) {
+	key, _ := loadKey(t, "key.pem")
+	multi, _ := loadKey(t, "multiprime.pem")
+	// 没有素因子时走非CRT路径
+	plain := &PrivateKey{PublicKey: key.PublicKey, D: key.D}
+	for _, priv := range []*PrivateKey{key, multi, plain} {
+		for range 10 {
+			c, _ := rand.Int(rand.Reader, priv.N)
+			m, err := decrypt(priv, c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := decryptNaive(priv, c); m.Cmp(want) != 0 {
+				t.Fatalf("%d个素因子: 结果与big.Int实现不一致", len(priv.Primes))
+			}
+		}
+	}
+
+	// 没有调用Precompute的私钥临时构造模数
+	fresh := &PrivateKey{PublicKey: key.PublicKey, D: key.D, Primes: key.Primes}
+	fresh.precomputeCRT()
+	c := big.NewInt(12345)
+	if m, err := decrypt(fresh, c); err != nil || m.Cmp(decryptNaive(key, c)) != 0 {
+		t.Errorf("未预计算的私钥: %v", err)
+	}
+}
+
+// BenchmarkDecrypt 比较盲化加常量时间求幂与直接使用big.Int的私钥运算
+func BenchmarkDecrypt(b *testing.B) {
+	for _, name := range []string{"key.pem", "multiprime.pem"} {
+		priv, _ := loadKey(b, name)
+		c, _ := rand.Int(rand.Reader, priv.N)
+		b.Run(name+"/constant-time", func(b *testing.B) {
+			for b.Loop() {
+				if _, err := decrypt(priv, c); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(name+"/naive", func(b *testing.B) {
+			for b.Loop() {
+				decryptNaive(priv, c)
+			}
+		})
+	}
+}
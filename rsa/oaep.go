@@ -0,0 +1,113 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+
+	"github.com/laenix/gsc/kdf/mgf1"
+	"github.com/laenix/gsc/subtle"
+)
+
+// OAEPOptions 是RSAES-OAEP的参数，加密和解密必须使用相同的参数
+// 传入nil等价于零值：哈希和MGF1均为SHA-256，标签为空
+type OAEPOptions struct {
+	// Hash 用于计算标签的摘要，为nil时使用SHA-256
+	Hash *Hash
+	// MGFHash 是MGF1使用的摘要算法，为nil时与Hash相同
+	MGFHash *Hash
+	// Label 与密文绑定但不被加密，解密时标签不一致会失败
+	Label []byte
+}
+
+// hashes 返回标签和MGF1实际使用的摘要算法
+func (opts *OAEPOptions) hashes() (hash, mgfHash *Hash, label []byte) {
+	hash = SHA256
+	if opts != nil {
+		mgfHash, label = opts.MGFHash, opts.Label
+		if opts.Hash != nil {
+			hash = opts.Hash
+		}
+	}
+	if mgfHash == nil {
+		mgfHash = hash
+	}
+	return hash, mgfHash, label
+}
+
+// EncryptOAEP 用RSAES-OAEP（RFC 8017 7.1）加密msg，msg最长为模数字节长度减去2倍摘要长度再减2
+// random为nil时使用crypto/rand
+func EncryptOAEP(random io.Reader, pub *PublicKey, msg []byte, opts *OAEPOptions) ([]byte, error) {
+	if err := checkPublicKey(pub); err != nil {
+		return nil, err
+	}
+	hash, mgfHash, label := opts.hashes()
+	k := pub.Size()
+	hLen := hash.Size()
+	if len(msg) > k-2*hLen-2 {
+		return nil, ErrMessageTooLong
+	}
+	if random == nil {
+		random = rand.Reader
+	}
+
+	// EM = 0x00 || maskedSeed || maskedDB，DB = lHash || PS || 0x01 || M
+	em := make([]byte, k)
+	seed := em[1 : 1+hLen]
+	db := em[1+hLen:]
+	copy(db, hash.Sum(label))
+	db[len(db)-len(msg)-1] = 1
+	copy(db[len(db)-len(msg):], msg)
+	if _, err := io.ReadFull(random, seed); err != nil {
+		return nil, err
+	}
+	mgf1.XOR(mgfHash.New, db, seed)
+	mgf1.XOR(mgfHash.New, seed, db)
+
+	c := encrypt(pub, new(big.Int).SetBytes(em))
+	return c.FillBytes(em), nil
+}
+
+// DecryptOAEP 解密RSAES-OAEP密文，opts必须与加密时相同
+// 密文长度、填充或标签错误统一返回ErrDecryption，检查过程不泄露具体的失败原因
+func DecryptOAEP(priv *PrivateKey, ciphertext []byte, opts *OAEPOptions) ([]byte, error) {
+	if err := checkPublicKey(&priv.PublicKey); err != nil {
+		return nil, err
+	}
+	hash, mgfHash, label := opts.hashes()
+	k := priv.Size()
+	hLen := hash.Size()
+	if len(ciphertext) != k || k < 2*hLen+2 {
+		return nil, ErrDecryption
+	}
+	m, err := decrypt(priv, new(big.Int).SetBytes(ciphertext))
+	if err != nil {
+		return nil, err
+	}
+	em := m.FillBytes(make([]byte, k))
+
+	firstByteIsZero := subtle.ConstantTimeByteEq(em[0], 0)
+	seed := em[1 : 1+hLen]
+	db := em[1+hLen:]
+	mgf1.XOR(mgfHash.New, seed, db)
+	mgf1.XOR(mgfHash.New, db, seed)
+	lHashGood := subtle.ConstantTimeCompare(db[:hLen], hash.Sum(label))
+
+	// PS之后应是0x01，查找过程不依赖其位置：
+	// invalid记录在找到0x01之前是否出现了非零字节
+	var lookingForIndex, index, invalid int
+	lookingForIndex = 1
+	rest := db[hLen:]
+	for i := 0; i < len(rest); i++ {
+		equals0 := subtle.ConstantTimeByteEq(rest[i], 0)
+		equals1 := subtle.ConstantTimeByteEq(rest[i], 1)
+		index = subtle.ConstantTimeSelect(lookingForIndex&equals1, i, index)
+		lookingForIndex = subtle.ConstantTimeSelect(equals1, 0, lookingForIndex)
+		invalid = subtle.ConstantTimeSelect(lookingForIndex&^equals0, 1, invalid)
+	}
+
+	if firstByteIsZero&lHashGood&^invalid&^lookingForIndex != 1 {
+		return nil, ErrDecryption
+	}
+	return rest[index+1:], nil
+}
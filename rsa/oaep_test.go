@@ -0,0 +1,101 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	stdrsa "crypto/rsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/laenix/gsc/vectors"
+)
+
+func TestOAEPVectors(t *testing.T) {
+	vectors.Run(t, "testdata/oaep-vect.rsp", func(t *testing.T, c *vectors.Case) {
+		n, _ := new(big.Int).SetString(c.Params["n"], 16)
+		d, _ := new(big.Int).SetString(c.Params["d"], 16)
+		e, err := c.Param("e")
+		if err != nil || n == nil || d == nil {
+			t.Fatal("密钥参数无效")
+		}
+		msg, _ := c.Hex("Msg")
+		seed, _ := c.Hex("Seed")
+		want, _ := c.Hex("CT")
+
+		// 向量只给出n、e、d，解密走非CRT路径
+		priv := &PrivateKey{PublicKey: PublicKey{N: n, E: e}, D: d}
+		opts := &OAEPOptions{Hash: SHA1}
+		ct, err := EncryptOAEP(bytes.NewReader(seed), &priv.PublicKey, msg, opts)
+		if err != nil || !bytes.Equal(ct, want) {
+			t.Fatalf("加密结果不符: %v", err)
+		}
+		if pt, err := DecryptOAEP(priv, want, opts); err != nil || !bytes.Equal(pt, msg) {
+			t.Fatalf("解密结果不符: %v", err)
+		}
+	})
+}
+
+func TestOAEPInterop(t *testing.T) {
+	priv, _ := loadKey(t, "key.pem")
+	std := priv.ToStd()
+	msg := []byte("OAEP互通")
+	label := []byte("gsc")
+	stdHash := map[*Hash]crypto.Hash{SHA1: crypto.SHA1, SHA256: crypto.SHA256, SHA512: crypto.SHA512}
+	for i, opts := range []*OAEPOptions{
+		nil,
+		{Hash: SHA1, Label: label},
+		{Hash: SHA512, Label: label},
+		{Hash: SHA256, MGFHash: SHA1},
+	} {
+		hash, mgfHash, label := opts.hashes()
+		stdOpts := &stdrsa.OAEPOptions{Hash: stdHash[hash], MGFHash: stdHash[mgfHash], Label: label}
+
+		ct, err := EncryptOAEP(nil, &priv.PublicKey, msg, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pt, err := std.Decrypt(nil, ct, stdOpts); err != nil || !bytes.Equal(pt, msg) {
+			t.Errorf("#%d: crypto/rsa解密失败: %v", i, err)
+		}
+		if hash == mgfHash {
+			ct, _ = stdrsa.EncryptOAEP(stdHash[hash].New(), rand.Reader, &std.PublicKey, msg, label)
+			if pt, err := DecryptOAEP(priv, ct, opts); err != nil || !bytes.Equal(pt, msg) {
+				t.Errorf("#%d: 解密crypto/rsa密文失败: %v", i, err)
+			}
+		}
+	}
+}
+
+func TestOAEPErrors(t *testing.T) {
+	priv, _ := loadKey(t, "key.pem")
+	opts := &OAEPOptions{Hash: SHA256, Label: []byte("label")}
+	maxLen := priv.Size() - 2*SHA256.Size() - 2
+	if _, err := EncryptOAEP(nil, &priv.PublicKey, make([]byte, maxLen+1), opts); !errors.Is(err, ErrMessageTooLong) {
+		t.Errorf("消息过长: %v", err)
+	}
+	ct, err := EncryptOAEP(nil, &priv.PublicKey, make([]byte, maxLen), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pt, err := DecryptOAEP(priv, ct, opts); err != nil || len(pt) != maxLen {
+		t.Errorf("最长消息: %v", err)
+	}
+
+	for name, tt := range map[string]struct {
+		ct   []byte
+		opts *OAEPOptions
+	}{
+		"标签不同":          {ct, &OAEPOptions{Hash: SHA256, Label: []byte("other")}},
+		"哈希不同":          {ct, &OAEPOptions{Hash: SHA1, Label: opts.Label}},
+		"MGF1不同":        {ct, &OAEPOptions{Hash: SHA256, MGFHash: SHA1, Label: opts.Label}},
+		"长度错误":          {ct[1:], opts},
+		"密文篡改":          {append(bytes.Clone(ct[:len(ct)-1]), ct[len(ct)-1]^1), opts},
+		"PKCS#1 v1.5密文": {readFile(t, "message.enc"), opts},
+	} {
+		if _, err := DecryptOAEP(priv, tt.ct, tt.opts); !errors.Is(err, ErrDecryption) {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
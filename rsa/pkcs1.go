@@ -0,0 +1,177 @@
+package rsa
+
+import (
+	"math/big"
+
+	"github.com/laenix/gsc/der"
+)
+
+// 多素数私钥允许的最大素因子个数，防止异常输入耗费大量计算
+const maxPrimes = 16
+
+// MarshalPKCS1PrivateKey 将私钥编码为PKCS#1 RSAPrivateKey（RFC 8017附录A.1.2）
+// 多素数密钥的版本为1，额外的素因子编码在otherPrimeInfos中
+func MarshalPKCS1PrivateKey(priv *PrivateKey) ([]byte, error) {
+	if err := priv.Validate(); err != nil {
+		return nil, err
+	}
+	priv.Precompute()
+
+	var b der.Builder
+	b.AddSequence(func(b *der.Builder) {
+		version := int64(0)
+		if len(priv.Primes) > 2 {
+			version = 1
+		}
+		b.AddInt64(version)
+		b.AddInteger(priv.N)
+		b.AddInt64(int64(priv.E))
+		b.AddInteger(priv.D)
+		b.AddInteger(priv.Primes[0])
+		b.AddInteger(priv.Primes[1])
+		b.AddInteger(priv.Precomputed.Dp)
+		b.AddInteger(priv.Precomputed.Dq)
+		b.AddInteger(priv.Precomputed.Qinv)
+		if version == 0 {
+			return
+		}
+		// OtherPrimeInfo ::= SEQUENCE { prime, exponent, coefficient }
+		b.AddSequence(func(b *der.Builder) {
+			for i, v := range priv.Precomputed.CRTValues {
+				b.AddSequence(func(b *der.Builder) {
+					b.AddInteger(priv.Primes[2+i])
+					b.AddInteger(v.Exp)
+					b.AddInteger(v.Coeff)
+				})
+			}
+		})
+	})
+	return b.Bytes()
+}
+
+// ParsePKCS1PrivateKey 解析PKCS#1 RSAPrivateKey并检查密钥的一致性
+// 编码中的CRT参数不被信任，解析后由素因子重新计算
+func ParsePKCS1PrivateKey(data []byte) (*PrivateKey, error) {
+	p := der.NewParser(data)
+	seq, err := p.ReadSequence()
+	if err != nil || p.Finish() != nil {
+		return nil, ErrInvalidKey
+	}
+	version, err := seq.ReadInt64()
+	if err != nil || version > 1 || version < 0 {
+		return nil, ErrInvalidKey
+	}
+	// n, e, d, p, q, dP, dQ, qInv
+	var ints [8]*big.Int
+	for i := range ints {
+		if ints[i], err = seq.ReadInteger(); err != nil || ints[i].Sign() <= 0 {
+			return nil, ErrInvalidKey
+		}
+	}
+	if !ints[1].IsInt64() || ints[1].Int64() > 1<<31-1 {
+		return nil, ErrInvalidKey
+	}
+	priv := &PrivateKey{
+		PublicKey: PublicKey{N: ints[0], E: int(ints[1].Int64())},
+		D:         ints[2],
+		Primes:    []*big.Int{ints[3], ints[4]},
+	}
+
+	if version == 1 {
+		others, err := seq.ReadSequence()
+		if err != nil || others.Empty() {
+			return nil, ErrInvalidKey
+		}
+		for !others.Empty() {
+			info, err := others.ReadSequence()
+			if err != nil || len(priv.Primes) == maxPrimes {
+				return nil, ErrInvalidKey
+			}
+			prime, err := info.ReadInteger()
+			if err != nil || prime.Sign() <= 0 {
+				return nil, ErrInvalidKey
+			}
+			// exponent和coefficient由Precompute重新计算
+			if _, err := info.ReadInteger(); err != nil {
+				return nil, ErrInvalidKey
+			}
+			if _, err := info.ReadInteger(); err != nil || info.Finish() != nil {
+				return nil, ErrInvalidKey
+			}
+			priv.Primes = append(priv.Primes, prime)
+		}
+	}
+	if seq.Finish() != nil {
+		return nil, ErrInvalidKey
+	}
+	if err := priv.Validate(); err != nil {
+		return nil, ErrInvalidKey
+	}
+	priv.Precompute()
+	return priv, nil
+}
+
+// MarshalPKCS1PublicKey 将公钥编码为PKCS#1 RSAPublicKey ::= SEQUENCE { n, e }
+func MarshalPKCS1PublicKey(pub *PublicKey) ([]byte, error) {
+	if err := checkPublicKey(pub); err != nil {
+		return nil, err
+	}
+	var b der.Builder
+	b.AddSequence(func(b *der.Builder) {
+		b.AddInteger(pub.N)
+		b.AddInt64(int64(pub.E))
+	})
+	return b.Bytes()
+}
+
+// ParsePKCS1PublicKey 解析PKCS#1 RSAPublicKey
+func ParsePKCS1PublicKey(data []byte) (*PublicKey, error) {
+	p := der.NewParser(data)
+	seq, err := p.ReadSequence()
+	if err != nil || p.Finish() != nil {
+		return nil, ErrInvalidPublicKey
+	}
+	n, err := seq.ReadInteger()
+	if err != nil {
+		return nil, ErrInvalidPublicKey
+	}
+	e, err := seq.ReadInt64()
+	if err != nil || seq.Finish() != nil || e > 1<<31-1 {
+		return nil, ErrInvalidPublicKey
+	}
+	pub := &PublicKey{N: n, E: int(e)}
+	if err := checkPublicKey(pub); err != nil {
+		return nil, err
+	}
+	return pub, nil
+}
+
+// MarshalBinary 实现encoding.BinaryMarshaler，编码为PKCS#1 RSAPublicKey
+func (pub *PublicKey) MarshalBinary() ([]byte, error) {
+	return MarshalPKCS1PublicKey(pub)
+}
+
+// UnmarshalBinary 实现encoding.BinaryUnmarshaler
+func (pub *PublicKey) UnmarshalBinary(data []byte) error {
+	key, err := ParsePKCS1PublicKey(data)
+	if err != nil {
+		return err
+	}
+	*pub = *key
+	return nil
+}
+
+// MarshalBinary 实现encoding.BinaryMarshaler，编码为PKCS#1 RSAPrivateKey
+func (priv *PrivateKey) MarshalBinary() ([]byte, error) {
+	return MarshalPKCS1PrivateKey(priv)
+}
+
+// UnmarshalBinary 实现encoding.BinaryUnmarshaler
+func (priv *PrivateKey) UnmarshalBinary(data []byte) error {
+	key, err := ParsePKCS1PrivateKey(data)
+	if err != nil {
+		return err
+	}
+	*priv = *key
+	return nil
+}
@@ -0,0 +1,180 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+
+	"github.com/laenix/gsc/subtle"
+)
+
+// EncryptPKCS1v15 用RSAES-PKCS1-v1_5加密msg，msg最长为模数字节长度减11
+// 新的应用应优先使用OAEP；random为nil时使用crypto/rand
+func EncryptPKCS1v15(random io.Reader, pub *PublicKey, msg []byte) ([]byte, error) {
+	if err := checkPublicKey(pub); err != nil {
+		return nil, err
+	}
+	k := pub.Size()
+	if len(msg) > k-11 {
+		return nil, ErrMessageTooLong
+	}
+	if random == nil {
+		random = rand.Reader
+	}
+
+	// EM = 0x00 || 0x02 || PS || 0x00 || M，PS为非零随机字节
+	em := make([]byte, k)
+	em[1] = 2
+	ps := em[2 : k-len(msg)-1]
+	if err := nonZeroRandomBytes(ps, random); err != nil {
+		return nil, err
+	}
+	copy(em[k-len(msg):], msg)
+
+	c := encrypt(pub, new(big.Int).SetBytes(em))
+	return c.FillBytes(em), nil
+}
+
+// DecryptPKCS1v15 解密RSAES-PKCS1-v1_5密文
+// 填充错误统一返回ErrDecryption；调用方如果会把解密失败暴露给对端，
+// 仍可能受到Bleichenbacher攻击，这类协议应改用OAEP
+func DecryptPKCS1v15(priv *PrivateKey, ciphertext []byte) ([]byte, error) {
+	valid, em, index, err := decryptPKCS1v15(priv, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if valid == 0 {
+		return nil, ErrDecryption
+	}
+	return em[index:], nil
+}
+
+// DecryptPKCS1v15SessionKey 解密RSAES-PKCS1-v1_5加密的定长会话密钥，用于CMS等协议
+// 调用方应先在key中填入随机字节：填充无效或明文长度不等于len(key)时key保持不变且不返回错误，
+// 错误只在之后使用密钥时才出现，对端无法借此区分填充是否有效（RFC 3218第2.3.2节）。
+// 只有密文长度错误或私钥无效时返回错误
+func DecryptPKCS1v15SessionKey(priv *PrivateKey, ciphertext, key []byte) error {
+	if priv.Size()-(len(key)+3+8) < 0 {
+		return ErrDecryption
+	}
+	valid, em, index, err := decryptPKCS1v15(priv, ciphertext)
+	if err != nil {
+		return err
+	}
+	valid &= subtle.ConstantTimeEq(int32(len(em)-index), int32(len(key)))
+	subtle.ConstantTimeCopy(valid, key, em[len(em)-len(key):])
+	return nil
+}
+
+// decryptPKCS1v15 解密并以常量时间检查填充，返回填充是否有效、编码消息和明文的起始位置
+func decryptPKCS1v15(priv *PrivateKey, ciphertext []byte) (valid int, em []byte, index int, err error) {
+	if err := checkPublicKey(&priv.PublicKey); err != nil {
+		return 0, nil, 0, err
+	}
+	k := priv.Size()
+	if k < 11 || len(ciphertext) != k {
+		return 0, nil, 0, ErrDecryption
+	}
+	m, err := decrypt(priv, new(big.Int).SetBytes(ciphertext))
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	em = m.FillBytes(make([]byte, k))
+
+	firstByteIsZero := subtle.ConstantTimeByteEq(em[0], 0)
+	secondByteIsTwo := subtle.ConstantTimeByteEq(em[1], 2)
+
+	// 找到PS之后的第一个0字节，查找过程不依赖其位置
+	lookingForIndex := 1
+	for i := 2; i < len(em); i++ {
+		equals0 := subtle.ConstantTimeByteEq(em[i], 0)
+		index = subtle.ConstantTimeSelect(lookingForIndex&equals0, i, index)
+		lookingForIndex = subtle.ConstantTimeSelect(equals0, 0, lookingForIndex)
+	}
+	// PS至少8个字节
+	validPS := subtle.ConstantTimeLessOrEq(2+8, index)
+
+	valid = firstByteIsZero & secondByteIsTwo & (^lookingForIndex & 1) & validPS
+	index = subtle.ConstantTimeSelect(valid, index+1, 0)
+	return valid, em, index, nil
+}
+
+// nonZeroRandomBytes 用非零随机字节填满s
+func nonZeroRandomBytes(s []byte, random io.Reader) error {
+	if _, err := io.ReadFull(random, s); err != nil {
+		return err
+	}
+	for i := range s {
+		for s[i] == 0 {
+			if _, err := io.ReadFull(random, s[i:i+1]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SignPKCS1v15 用RSASSA-PKCS1-v1_5签名摘要digest，digest必须是hash的摘要值
+// hash为nil时直接签名digest而不加DigestInfo，仅用于TLS 1.1等旧协议中的MD5+SHA1拼接摘要
+func SignPKCS1v15(priv *PrivateKey, hash *Hash, digest []byte) ([]byte, error) {
+	em, err := pkcs1v15EncodeDigest(hash, digest, priv.Size())
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPublicKey(&priv.PublicKey); err != nil {
+		return nil, err
+	}
+	s, err := decryptAndCheck(priv, new(big.Int).SetBytes(em))
+	if err != nil {
+		return nil, err
+	}
+	return s.FillBytes(em), nil
+}
+
+// VerifyPKCS1v15 验证RSASSA-PKCS1-v1_5签名，hash和digest的含义与SignPKCS1v15相同
+// 签名无效时返回ErrVerification
+func VerifyPKCS1v15(pub *PublicKey, hash *Hash, digest, sig []byte) error {
+	if err := checkPublicKey(pub); err != nil {
+		return err
+	}
+	k := pub.Size()
+	if len(sig) != k {
+		return ErrVerification
+	}
+	want, err := pkcs1v15EncodeDigest(hash, digest, k)
+	if err != nil {
+		return err
+	}
+	s := new(big.Int).SetBytes(sig)
+	if s.Cmp(pub.N) >= 0 {
+		return ErrVerification
+	}
+	em := encrypt(pub, s).FillBytes(make([]byte, k))
+	if subtle.ConstantTimeCompare(em, want) != 1 {
+		return ErrVerification
+	}
+	return nil
+}
+
+// pkcs1v15EncodeDigest 按EMSA-PKCS1-v1_5编码：0x00 || 0x01 || 0xFF... || 0x00 || DigestInfo
+func pkcs1v15EncodeDigest(hash *Hash, digest []byte, k int) ([]byte, error) {
+	var prefix []byte
+	if hash != nil {
+		if len(digest) != hash.Size() {
+			return nil, ErrInvalidDigest
+		}
+		prefix = hash.prefix
+	}
+	tLen := len(prefix) + len(digest)
+	if k < tLen+11 {
+		return nil, ErrMessageTooLong
+	}
+	em := make([]byte, k)
+	em[1] = 1
+	for i := 2; i < k-tLen-1; i++ {
+		em[i] = 0xff
+	}
+	copy(em[k-tLen:], prefix)
+	copy(em[k-len(digest):], digest)
+	return em, nil
+}
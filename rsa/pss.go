@@ -0,0 +1,186 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"math/big"
+
+	"github.com/laenix/gsc/kdf/mgf1"
+	"github.com/laenix/gsc/subtle"
+)
+
+// PSS盐长度的特殊取值
+const (
+	// PSSSaltLengthAuto 签名时使用密钥允许的最大盐长度，验证时从签名中自动识别盐长度
+	PSSSaltLengthAuto = 0
+	// PSSSaltLengthEqualsHash 盐长度等于摘要长度，是RFC 8017推荐的取值
+	PSSSaltLengthEqualsHash = -1
+)
+
+// PSSOptions 是RSASSA-PSS的参数，传入nil等价于盐长度等于摘要长度、MGF1使用签名的摘要算法
+type PSSOptions struct {
+	// SaltLength 是盐的字节长度，也可以是PSSSaltLengthAuto或PSSSaltLengthEqualsHash
+	SaltLength int
+	// MGFHash 是MGF1使用的摘要算法，为nil时与签名的摘要算法相同
+	MGFHash *Hash
+}
+
+// saltLength 返回盐长度的设置值，nil时为PSSSaltLengthEqualsHash
+func (opts *PSSOptions) saltLength() int {
+	if opts == nil {
+		return PSSSaltLengthEqualsHash
+	}
+	return opts.SaltLength
+}
+
+// mgfHash 返回MGF1实际使用的摘要算法
+func (opts *PSSOptions) mgfHash(hash *Hash) *Hash {
+	if opts == nil || opts.MGFHash == nil {
+		return hash
+	}
+	return opts.MGFHash
+}
+
+// SignPSS 用RSASSA-PSS（RFC 8017 8.1）签名摘要digest，digest必须是hash的摘要值
+// random用于生成盐，为nil时使用crypto/rand
+func SignPSS(random io.Reader, priv *PrivateKey, hash *Hash, digest []byte, opts *PSSOptions) ([]byte, error) {
+	if err := checkPublicKey(&priv.PublicKey); err != nil {
+		return nil, err
+	}
+	if len(digest) != hash.Size() {
+		return nil, ErrInvalidDigest
+	}
+	emBits := priv.N.BitLen() - 1
+	emLen := (emBits + 7) / 8
+	saltLen := opts.saltLength()
+	switch {
+	case saltLen == PSSSaltLengthAuto:
+		saltLen = emLen - hash.Size() - 2
+	case saltLen == PSSSaltLengthEqualsHash:
+		saltLen = hash.Size()
+	}
+	if emLen < hash.Size()+2 {
+		return nil, ErrKeySize
+	}
+	if saltLen < 0 || emLen < hash.Size()+saltLen+2 {
+		return nil, ErrPSSSaltLength
+	}
+	if random == nil {
+		random = rand.Reader
+	}
+	salt := make([]byte, saltLen)
+	if _, err := io.ReadFull(random, salt); err != nil {
+		return nil, err
+	}
+
+	em := emsaPSSEncode(digest, emBits, salt, hash, opts.mgfHash(hash))
+	s, err := decryptAndCheck(priv, new(big.Int).SetBytes(em))
+	if err != nil {
+		return nil, err
+	}
+	return s.FillBytes(make([]byte, priv.Size())), nil
+}
+
+// VerifyPSS 验证RSASSA-PSS签名，hash、digest和opts的含义与SignPSS相同
+// opts的盐长度为PSSSaltLengthAuto时接受任意盐长度；签名无效时返回ErrVerification
+func VerifyPSS(pub *PublicKey, hash *Hash, digest, sig []byte, opts *PSSOptions) error {
+	if err := checkPublicKey(pub); err != nil {
+		return err
+	}
+	if len(digest) != hash.Size() {
+		return ErrInvalidDigest
+	}
+	saltLen := opts.saltLength()
+	switch {
+	case saltLen == PSSSaltLengthEqualsHash:
+		saltLen = hash.Size()
+	case saltLen < 0:
+		return ErrPSSSaltLength
+	}
+	k := pub.Size()
+	if len(sig) != k {
+		return ErrVerification
+	}
+	s := new(big.Int).SetBytes(sig)
+	if s.Cmp(pub.N) >= 0 {
+		return ErrVerification
+	}
+
+	// 模数位数减1是8的倍数时EM比模数短1字节，此时最高字节必须为0
+	emBits := pub.N.BitLen() - 1
+	emLen := (emBits + 7) / 8
+	em := encrypt(pub, s).FillBytes(make([]byte, k))
+	if emLen < k {
+		if em[0] != 0 {
+			return ErrVerification
+		}
+		em = em[1:]
+	}
+	return emsaPSSVerify(digest, em, emBits, saltLen, hash, opts.mgfHash(hash))
+}
+
+// emsaPSSEncode 按EMSA-PSS编码：EM = maskedDB || H || 0xbc，
+// 其中H = Hash(0x00×8 || mHash || salt)，DB = PS || 0x01 || salt
+func emsaPSSEncode(mHash []byte, emBits int, salt []byte, hash, mgfHash *Hash) []byte {
+	hLen := hash.Size()
+	emLen := (emBits + 7) / 8
+	em := make([]byte, emLen)
+	db := em[:emLen-hLen-1]
+	h := em[emLen-hLen-1 : emLen-1]
+
+	d := hash.New()
+	d.Write(make([]byte, 8))
+	d.Write(mHash)
+	d.Write(salt)
+	d.Sum(h[:0])
+
+	db[len(db)-len(salt)-1] = 1
+	copy(db[len(db)-len(salt):], salt)
+	mgf1.XOR(mgfHash.New, db, h)
+	// 清除最高的8·emLen-emBits位，保证EM小于模数
+	db[0] &= 0xff >> (8*emLen - emBits)
+	em[emLen-1] = 0xbc
+	return em
+}
+
+// emsaPSSVerify 检查EM是否是mHash的EMSA-PSS编码，saltLen为PSSSaltLengthAuto时从DB中识别盐长度
+func emsaPSSVerify(mHash, em []byte, emBits, saltLen int, hash, mgfHash *Hash) error {
+	hLen := hash.Size()
+	emLen := (emBits + 7) / 8
+	if emLen != len(em) || emLen < hLen+saltLen+2 || em[emLen-1] != 0xbc {
+		return ErrVerification
+	}
+	db := bytes.Clone(em[:emLen-hLen-1])
+	h := em[emLen-hLen-1 : emLen-1]
+	bitMask := byte(0xff >> (8*emLen - emBits))
+	if db[0]&^bitMask != 0 {
+		return ErrVerification
+	}
+	mgf1.XOR(mgfHash.New, db, h)
+	db[0] &= bitMask
+
+	// DB = 0x00... || 0x01 || salt
+	psLen := bytes.IndexByte(db, 1)
+	if psLen < 0 {
+		return ErrVerification
+	}
+	for _, b := range db[:psLen] {
+		if b != 0 {
+			return ErrVerification
+		}
+	}
+	if saltLen != PSSSaltLengthAuto && len(db)-psLen-1 != saltLen {
+		return ErrVerification
+	}
+	salt := db[psLen+1:]
+
+	d := hash.New()
+	d.Write(make([]byte, 8))
+	d.Write(mHash)
+	d.Write(salt)
+	if subtle.ConstantTimeCompare(d.Sum(nil), h) != 1 {
+		return ErrVerification
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	stdrsa "crypto/rsa"
+	stdx509 "crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/laenix/gsc/der"
+	"github.com/laenix/gsc/vectors"
+)
+
+func TestPSSVectors(t *testing.T) {
+	vectors.Run(t, "testdata/pss-vect.rsp", func(t *testing.T, c *vectors.Case) {
+		n, _ := new(big.Int).SetString(c.Params["n"], 16)
+		d, _ := new(big.Int).SetString(c.Params["d"], 16)
+		e, err := c.Param("e")
+		if err != nil || n == nil || d == nil {
+			t.Fatal("密钥参数无效")
+		}
+		msg, _ := c.Hex("Msg")
+		salt, _ := c.Hex("Salt")
+		want, _ := c.Hex("S")
+
+		priv := &PrivateKey{PublicKey: PublicKey{N: n, E: e}, D: d}
+		digest := SHA1.Sum(msg)
+		opts := &PSSOptions{SaltLength: len(salt)}
+		sig, err := SignPSS(bytes.NewReader(salt), priv, SHA1, digest, opts)
+		if err != nil || !bytes.Equal(sig, want) {
+			t.Fatalf("签名结果不符: %v", err)
+		}
+		for _, opts := range []*PSSOptions{opts, {SaltLength: PSSSaltLengthAuto}, nil} {
+			if err := VerifyPSS(&priv.PublicKey, SHA1, digest, want, opts); err != nil {
+				t.Errorf("验证失败: %v", err)
+			}
+		}
+	})
+}
+
+// splitCertificate 拆分证书为待签名部分、签名算法标识和签名值
+func splitCertificate(t *testing.T, cert []byte) (tbs, alg, sig []byte) {
+	t.Helper()
+	p := der.NewParser(cert)
+	seq, err := p.ReadSequence()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tbs, err = seq.ReadRaw(der.TagSequence); err != nil {
+		t.Fatal(err)
+	}
+	if alg, err = seq.ReadRaw(der.TagSequence); err != nil {
+		t.Fatal(err)
+	}
+	if sig, err = seq.ReadBitString(); err != nil {
+		t.Fatal(err)
+	}
+	return tbs, alg, sig
+}
+
+// testdata中的pss-*.der是OpenSSL用key.pem以RSASSA-PSS签发的自签名证书
+func TestPSSOpenSSLCertificates(t *testing.T) {
+	priv, _ := loadKey(t, "key.pem")
+	for _, tt := range []struct {
+		file    string
+		hash    *Hash
+		saltLen int
+		mgfHash *Hash
+	}{
+		{"pss-sha256.der", SHA256, 32, SHA256},
+		{"pss-sha384-mgf1sha1.der", SHA384, 20, SHA1},
+	} {
+		tbs, alg, sig := splitCertificate(t, readFile(t, tt.file))
+		hash, opts, err := ParsePSSAlgorithm(alg)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.file, err)
+		}
+		if hash != tt.hash || opts.SaltLength != tt.saltLen || opts.MGFHash != tt.mgfHash {
+			t.Fatalf("%s: 参数为%s/%d/%s", tt.file, hash, opts.SaltLength, opts.MGFHash)
+		}
+		if err := VerifyPSS(&priv.PublicKey, hash, hash.Sum(tbs), sig, opts); err != nil {
+			t.Errorf("%s: %v", tt.file, err)
+		}
+		if enc, err := MarshalPSSAlgorithm(hash, opts); err != nil || !bytes.Equal(enc, alg) {
+			t.Errorf("%s: 算法标识编码不一致: %x", tt.file, enc)
+		}
+	}
+}
+
+func TestPSSInterop(t *testing.T) {
+	priv, _ := loadKey(t, "key.pem")
+	std := priv.ToStd()
+	msg := []byte("PSS互通")
+	for _, tt := range []struct {
+		hash    *Hash
+		std     crypto.Hash
+		saltLen int
+	}{
+		{SHA256, crypto.SHA256, PSSSaltLengthEqualsHash},
+		{SHA384, crypto.SHA384, 0},
+		{SHA512, crypto.SHA512, 7},
+	} {
+		digest := tt.hash.Sum(msg)
+		sig, err := SignPSS(nil, priv, tt.hash, digest, &PSSOptions{SaltLength: tt.saltLen})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := stdrsa.VerifyPSS(&std.PublicKey, tt.std, digest, sig, &stdrsa.PSSOptions{SaltLength: tt.saltLen}); err != nil {
+			t.Errorf("%s: crypto/rsa验证失败: %v", tt.hash, err)
+		}
+		sig, _ = stdrsa.SignPSS(rand.Reader, std, tt.std, digest, &stdrsa.PSSOptions{SaltLength: tt.saltLen})
+		if err := VerifyPSS(&priv.PublicKey, tt.hash, digest, sig, &PSSOptions{SaltLength: tt.saltLen}); err != nil {
+			t.Errorf("%s: 验证crypto/rsa签名失败: %v", tt.hash, err)
+		}
+	}
+
+	// crypto/x509签发的PSS证书的签名算法标识与MarshalPSSAlgorithm一致
+	tmpl := &stdx509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "pss"}, SignatureAlgorithm: stdx509.SHA256WithRSAPSS}
+	cert, err := stdx509.CreateCertificate(rand.Reader, tmpl, tmpl, &std.PublicKey, std)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, alg, _ := splitCertificate(t, cert)
+	if enc, err := MarshalPSSAlgorithm(SHA256, nil); err != nil || !bytes.Equal(enc, alg) {
+		t.Errorf("与crypto/x509的算法标识不一致: %x", enc)
+	}
+}
+
+func TestPSSErrors(t *testing.T) {
+	priv, _ := loadKey(t, "key.pem")
+	digest := SHA256.Sum([]byte("abc"))
+	maxSalt := priv.Size() - SHA256.Size() - 2
+	if _, err := SignPSS(nil, priv, SHA256, digest, &PSSOptions{SaltLength: maxSalt + 1}); !errors.Is(err, ErrPSSSaltLength) {
+		t.Errorf("盐过长: %v", err)
+	}
+	if _, err := SignPSS(nil, priv, SHA256, digest[1:], nil); !errors.Is(err, ErrInvalidDigest) {
+		t.Errorf("摘要长度错误: %v", err)
+	}
+	sig, err := SignPSS(nil, priv, SHA256, digest, &PSSOptions{SaltLength: PSSSaltLengthAuto})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyPSS(&priv.PublicKey, SHA256, digest, sig, &PSSOptions{SaltLength: maxSalt}); err != nil {
+		t.Errorf("最大盐长度: %v", err)
+	}
+	for name, tt := range map[string]struct {
+		sig  []byte
+		hash *Hash
+		opts *PSSOptions
+	}{
+		"盐长度不符":  {sig, SHA256, nil},
+		"MGF1不同": {sig, SHA256, &PSSOptions{MGFHash: SHA1}},
+		"签名篡改":   {append(bytes.Clone(sig[:len(sig)-1]), sig[len(sig)-1]^1), SHA256, &PSSOptions{}},
+		"长度错误":   {sig[1:], SHA256, &PSSOptions{}},
+		"v1.5签名": {readFile(t, "message.sha256.sig"), SHA256, &PSSOptions{}},
+	} {
+		if err := VerifyPSS(&priv.PublicKey, tt.hash, digest, tt.sig, tt.opts); !errors.Is(err, ErrVerification) {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestPSSAlgorithm(t *testing.T) {
+	for _, tt := range []struct {
+		hash *Hash
+		opts *PSSOptions
+		want string
+	}{
+		// 全部参数为默认值时是空的SEQUENCE
+		{SHA1, &PSSOptions{SaltLength: 20}, "300d06092a864886f70d01010a3000"},
+		{SM3, &PSSOptions{SaltLength: 32, MGFHash: SHA256}, ""},
+		{SHA512, &PSSOptions{SaltLength: 1, MGFHash: SHA1}, ""},
+	} {
+		enc, err := MarshalPSSAlgorithm(tt.hash, tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.want != "" && hex.EncodeToString(enc) != tt.want {
+			t.Errorf("%s: %x", tt.hash, enc)
+		}
+		hash, opts, err := ParsePSSAlgorithm(enc)
+		if err != nil || hash != tt.hash || opts.SaltLength != tt.opts.SaltLength || opts.MGFHash != tt.opts.mgfHash(tt.hash) {
+			t.Errorf("%s: 往返失败: %v", tt.hash, err)
+		}
+	}
+	if _, err := MarshalPSSAlgorithm(SHA256, &PSSOptions{SaltLength: PSSSaltLengthAuto}); !errors.Is(err, ErrPSSSaltLength) {
+		t.Errorf("自动盐长度: %v", err)
+	}
+
+	for name, tt := range map[string]struct {
+		hex  string
+		want error
+	}{
+		"sha256WithRSAEncryption": {"300d06092a864886f70d01010b0500", ErrUnsupportedAlgorithm},
+		"缺少参数":                    {"300b06092a864886f70d01010a", ErrMalformedAlgorithm},
+		"尾部字段为2":                  {"301206092a864886f70d01010a3005a303020102", ErrUnsupportedAlgorithm},
+		"SHA3-256":                {"301e06092a864886f70d01010a3011a00f300d06096086480165030402080500", ErrUnsupportedAlgorithm},
+		"MD5":                     {"301d06092a864886f70d01010a3010a00e300c06082a864886f70d02050500", ErrUnsupportedAlgorithm},
+		"截断":                      {"301c06092a864886f70d01010a300fa00d300b06082a864886f70d020505", ErrMalformedAlgorithm},
+		"盐长度为0":                   {"301206092a864886f70d01010a3005a203020100", ErrUnsupportedAlgorithm},
+		"多余数据":                    {"300f06092a864886f70d01010a30000000", ErrMalformedAlgorithm},
+	} {
+		data, _ := hex.DecodeString(tt.hex)
+		if _, _, err := ParsePSSAlgorithm(data); !errors.Is(err, tt.want) {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+// testdata中的oaep-sha256-label.*取自OpenSSL生成的CMS数字信封（rsa_oaep_md:sha256，标签"glabel"），
+// 其中摘要算法标识省略了NULL参数
+func TestOAEPAlgorithm(t *testing.T) {
+	priv, _ := loadKey(t, "key.pem")
+	alg := readFile(t, "oaep-sha256-label.der")
+	opts, err := ParseOAEPAlgorithm(alg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Hash != SHA256 || opts.MGFHash != SHA256 || string(opts.Label) != "glabel" {
+		t.Fatalf("参数为%s/%s/%q", opts.Hash, opts.MGFHash, opts.Label)
+	}
+	if key, err := DecryptOAEP(priv, readFile(t, "oaep-sha256-label.enc"), opts); err != nil || len(key) != 16 {
+		t.Errorf("解密OpenSSL加密的内容密钥: %v", err)
+	}
+
+	for _, opts := range []*OAEPOptions{nil, opts, {Hash: SHA1}, {Hash: SM3, MGFHash: SHA1}} {
+		enc, err := MarshalOAEPAlgorithm(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := ParseOAEPAlgorithm(enc)
+		hash, mgfHash, label := opts.hashes()
+		if err != nil || parsed.Hash != hash || parsed.MGFHash != mgfHash || !bytes.Equal(parsed.Label, label) {
+			t.Errorf("往返失败: %v", err)
+		}
+	}
+	if enc, _ := MarshalOAEPAlgorithm(&OAEPOptions{Hash: SHA1}); hex.EncodeToString(enc) != "300d06092a864886f70d0101073000" {
+		t.Errorf("默认参数: %x", enc)
+	}
+	if _, err := ParseOAEPAlgorithm(readFile(t, "pss-sha256.der")); !errors.Is(err, ErrMalformedAlgorithm) {
+		t.Errorf("非算法标识: %v", err)
+	}
+}
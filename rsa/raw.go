@@ -0,0 +1,33 @@
+package rsa
+
+import "math/big"
+
+// EncryptRaw 计算RSAEP原语 c = m^e mod n（RFC 8017 5.1.1），m按大端整数解释且必须小于N，
+// 返回与模数等长的c。不带填充的RSA不能直接用于加密，只供RSA-KEM等自行构造输入的方案使用
+func EncryptRaw(pub *PublicKey, m []byte) ([]byte, error) {
+	if err := checkPublicKey(pub); err != nil {
+		return nil, err
+	}
+	x := new(big.Int).SetBytes(m)
+	if x.Cmp(pub.N) >= 0 {
+		return nil, ErrMessageTooLong
+	}
+	return encrypt(pub, x).FillBytes(make([]byte, pub.Size())), nil
+}
+
+// DecryptRaw 计算RSADP原语 m = c^d mod n（RFC 8017 5.1.2），返回与模数等长的m
+// c的长度必须等于模数的字节长度且数值小于N，否则返回ErrDecryption；私钥运算与其他解密相同经过盲化且为常量时间
+func DecryptRaw(priv *PrivateKey, c []byte) ([]byte, error) {
+	if err := checkPublicKey(&priv.PublicKey); err != nil {
+		return nil, err
+	}
+	k := priv.Size()
+	if len(c) != k {
+		return nil, ErrDecryption
+	}
+	m, err := decrypt(priv, new(big.Int).SetBytes(c))
+	if err != nil {
+		return nil, err
+	}
+	return m.FillBytes(make([]byte, k)), nil
+}
@@ -1 +1,368 @@
+// Package rsa 实现RSA公钥算法（RFC 8017）：多素数密钥生成、PKCS#1 v1.5加密和签名、OAEP加密、PSS签名，
+// 以及PKCS#1 DER密钥编码、PSS/OAEP参数的AlgorithmIdentifier编码和供RSA-KEM使用的RSAEP/RSADP原语
+//
+// 密钥结构与crypto/rsa相同，私钥运算使用中国剩余定理（CRT）加速，签名后用公钥验算结果，
+// 防止CRT计算出错时泄露素因子。私钥运算的输入先经过随机盲化，求幂使用定长的Montgomery乘法
+// 和固定窗口，运算时间不取决于私钥和密文的数值。签名的摘要算法可以是SHA-1、SHA-2系列或SM3。
+// 新的加密应用应使用OAEP，PKCS#1 v1.5加密只为兼容旧系统保留
 package rsa
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+
+	"github.com/laenix/gsc/entropy"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/internal/nat"
+	"github.com/laenix/gsc/rsa/internal"
+)
+
+const (
+	// MinKeyBits 是GenerateKey允许的最小模数长度（位）
+	MinKeyBits = 1024
+	// 多素数密钥中每个素因子的最小长度（位）
+	minPrimeBits = 256
+)
+
+// 错误定义
+var (
+	ErrKeySize              = gscerr.New(gscerr.ErrKeySize, "rsa: key size too small")
+	ErrTooManyPrimes        = gscerr.New(gscerr.ErrParameter, "rsa: too many primes for the key size")
+	ErrInvalidPublicKey     = gscerr.New(gscerr.ErrMalformed, "rsa: invalid public key")
+	ErrInvalidKey           = gscerr.New(gscerr.ErrMalformed, "rsa: invalid private key")
+	ErrMessageTooLong       = gscerr.New(gscerr.ErrParameter, "rsa: message too long for RSA key size")
+	ErrDecryption           = gscerr.New(gscerr.ErrAuthFailed, "rsa: decryption error")
+	ErrVerification         = gscerr.New(gscerr.ErrVerification, "rsa: verification error")
+	ErrInvalidDigest        = gscerr.New(gscerr.ErrParameter, "rsa: digest length does not match the hash")
+	ErrSigningFailed        = gscerr.New(gscerr.ErrMisuse, "rsa: private key operation produced an incorrect result")
+	ErrPSSSaltLength        = gscerr.New(gscerr.ErrParameter, "rsa: invalid PSS salt length")
+	ErrMalformedAlgorithm   = gscerr.New(gscerr.ErrMalformed, "rsa: malformed algorithm identifier")
+	ErrUnsupportedAlgorithm = gscerr.New(gscerr.ErrUnsupported, "rsa: unsupported algorithm parameters")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrKeySize:              "rsa: 密钥长度过短",
+		ErrTooManyPrimes:        "rsa: 素因子数量对该密钥长度过多",
+		ErrInvalidPublicKey:     "rsa: 无效的公钥",
+		ErrInvalidKey:           "rsa: 无效的私钥",
+		ErrMessageTooLong:       "rsa: 消息长度超过密钥允许的范围",
+		ErrDecryption:           "rsa: 解密失败",
+		ErrVerification:         "rsa: 验证失败",
+		ErrInvalidDigest:        "rsa: 摘要长度与哈希算法不符",
+		ErrSigningFailed:        "rsa: 私钥运算结果错误",
+		ErrPSSSaltLength:        "rsa: PSS盐长度无效",
+		ErrMalformedAlgorithm:   "rsa: 算法标识格式错误",
+		ErrUnsupportedAlgorithm: "rsa: 不支持的算法参数",
+	})
+}
+
+// PublicKey 是RSA公钥
+type PublicKey struct {
+	N *big.Int // 模数
+	E int      // 公钥指数
+}
+
+// Size 返回模数的字节长度，即签名和密文的长度
+func (pub *PublicKey) Size() int {
+	return (pub.N.BitLen() + 7) / 8
+}
+
+// Equal 判断两个公钥是否相同
+func (pub *PublicKey) Equal(other *PublicKey) bool {
+	return other != nil && pub.N.Cmp(other.N) == 0 && pub.E == other.E
+}
+
+// PrivateKey 是RSA私钥，Primes为N的全部素因子（多素数密钥多于两个）
+type PrivateKey struct {
+	PublicKey
+	D      *big.Int   // 私钥指数
+	Primes []*big.Int // 素因子
+
+	// Precomputed 是CRT运算需要的预计算值，由Precompute填充
+	Precomputed PrecomputedValues
+}
+
+// PrecomputedValues 是CRT运算的预计算值
+type PrecomputedValues struct {
+	Dp, Dq *big.Int // D mod (P-1)、D mod (Q-1)
+	Qinv   *big.Int // Q^-1 mod P
+
+	// CRTValues 是第三个及以后的素因子的预计算值
+	CRTValues []CRTValue
+
+	mont *montValues
+}
+
+// CRTValue 是多素数密钥中一个额外素因子的预计算值
+type CRTValue struct {
+	Exp   *big.Int // D mod (prime-1)
+	Coeff *big.Int // R·Coeff ≡ 1 mod prime
+	R     *big.Int // 之前全部素因子的乘积
+}
+
+// Public 返回对应的公钥
+func (priv *PrivateKey) Public() *PublicKey {
+	return &priv.PublicKey
+}
+
+// GenerateKey 生成bits位模数的两素数密钥，公钥指数为65537
+// random为nil时使用带连续健康测试的entropy.Default
+func GenerateKey(random io.Reader, bits int) (*PrivateKey, error) {
+	return GenerateMultiPrimeKey(random, 2, bits)
+}
+
+// GenerateMultiPrimeKey 生成nprimes个素因子的多素数密钥（RFC 8017第3节）
+// 素因子越多私钥运算越快，但每个素因子至少需要256位，否则返回ErrTooManyPrimes
+func GenerateMultiPrimeKey(random io.Reader, nprimes, bits int) (*PrivateKey, error) {
+	if bits < MinKeyBits {
+		return nil, ErrKeySize
+	}
+	if nprimes < 2 || bits/nprimes < minPrimeBits {
+		return nil, ErrTooManyPrimes
+	}
+	if random == nil {
+		random = entropy.Default
+	}
+
+	e := big.NewInt(internal.DefaultExponent)
+	primes := make([]*big.Int, nprimes)
+	for {
+		// 每个素因子的最高两位都为1，乘积的位数可能多出几位，多素数时略微减少目标位数
+		todo := bits
+		if nprimes >= 7 {
+			todo += (nprimes - 2) / 5
+		}
+		var err error
+		for i := range primes {
+			if primes[i], err = rand.Prime(random, todo/(nprimes-i)); err != nil {
+				return nil, err
+			}
+			todo -= primes[i].BitLen()
+		}
+
+		n := big.NewInt(1)
+		totient := big.NewInt(1)
+		distinct := true
+		for i, p := range primes {
+			for _, q := range primes[:i] {
+				if p.Cmp(q) == 0 {
+					distinct = false
+				}
+			}
+			n.Mul(n, p)
+			totient.Mul(totient, new(big.Int).Sub(p, bigOne))
+		}
+		if !distinct || n.BitLen() != bits {
+			continue
+		}
+		d := new(big.Int).ModInverse(e, totient)
+		if d == nil {
+			// gcd(e, φ(n)) != 1
+			continue
+		}
+
+		priv := &PrivateKey{
+			PublicKey: PublicKey{N: n, E: internal.DefaultExponent},
+			D:         d,
+			Primes:    primes,
+		}
+		priv.Precompute()
+		return priv, nil
+	}
+}
+
+var bigOne = big.NewInt(1)
+
+// Precompute 计算CRT运算需要的预计算值和常量时间运算使用的模数，加快私钥运算
+// Precompute会修改私钥，不能与使用该私钥的其他运算并发调用
+func (priv *PrivateKey) Precompute() {
+	if priv.Precomputed.mont != nil || checkPublicKey(&priv.PublicKey) != nil {
+		return
+	}
+	if priv.Precomputed.Dp == nil && len(priv.Primes) >= 2 {
+		priv.precomputeCRT()
+	}
+	priv.Precomputed.mont = newMontValues(priv)
+}
+
+// precomputeCRT 计算PrecomputedValues中的CRT参数
+func (priv *PrivateKey) precomputeCRT() {
+	p, q := priv.Primes[0], priv.Primes[1]
+	priv.Precomputed.Dp = new(big.Int).Mod(priv.D, new(big.Int).Sub(p, bigOne))
+	priv.Precomputed.Dq = new(big.Int).Mod(priv.D, new(big.Int).Sub(q, bigOne))
+	priv.Precomputed.Qinv = new(big.Int).ModInverse(q, p)
+
+	r := new(big.Int).Mul(p, q)
+	priv.Precomputed.CRTValues = make([]CRTValue, len(priv.Primes)-2)
+	for i, prime := range priv.Primes[2:] {
+		priv.Precomputed.CRTValues[i] = CRTValue{
+			Exp:   new(big.Int).Mod(priv.D, new(big.Int).Sub(prime, bigOne)),
+			Coeff: new(big.Int).ModInverse(r, prime),
+			R:     new(big.Int).Set(r),
+		}
+		r.Mul(r, prime)
+	}
+}
+
+// Validate 检查私钥的一致性：素因子的乘积等于N，且D·E ≡ 1 mod (prime-1)对每个素因子成立
+func (priv *PrivateKey) Validate() error {
+	if err := checkPublicKey(&priv.PublicKey); err != nil {
+		return err
+	}
+	if priv.D == nil || priv.D.Sign() <= 0 || len(priv.Primes) < 2 {
+		return ErrInvalidKey
+	}
+	n := big.NewInt(1)
+	for _, p := range priv.Primes {
+		if p == nil || p.Cmp(bigOne) <= 0 {
+			return ErrInvalidKey
+		}
+		n.Mul(n, p)
+	}
+	if n.Cmp(priv.N) != 0 {
+		return ErrInvalidKey
+	}
+
+	de := new(big.Int).Mul(priv.D, big.NewInt(int64(priv.E)))
+	for _, p := range priv.Primes {
+		pminus1 := new(big.Int).Sub(p, bigOne)
+		if new(big.Int).Mod(de, pminus1).Cmp(bigOne) != 0 {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
+
+// checkPublicKey 检查模数为正奇数，公钥指数为不小于3的奇数
+func checkPublicKey(pub *PublicKey) error {
+	if pub == nil || pub.N == nil || pub.N.Sign() <= 0 || pub.N.Bit(0) == 0 {
+		return ErrInvalidPublicKey
+	}
+	if pub.E < 3 || pub.E&1 == 0 || pub.E > 1<<31-1 {
+		return ErrInvalidPublicKey
+	}
+	return nil
+}
+
+// encrypt 计算 c = m^e mod n
+func encrypt(pub *PublicKey, m *big.Int) *big.Int {
+	return new(big.Int).Exp(m, big.NewInt(int64(pub.E)), pub.N)
+}
+
+// decrypt 计算 m = c^d mod n
+// c先乘以随机数r的e次方进行盲化，私钥运算的输入与c无关，结果再乘以r^-1去除盲化；
+// 私钥运算本身用nat实现，有预计算值时按CRT分别对每个素因子求幂后合并
+func decrypt(priv *PrivateKey, c *big.Int) (*big.Int, error) {
+	if c.Cmp(priv.N) >= 0 {
+		return nil, ErrDecryption
+	}
+
+	// 选取与N互素的r，c' = c·r^e mod n
+	var r, rInv *big.Int
+	for {
+		var err error
+		r, err = rand.Int(rand.Reader, priv.N)
+		if err != nil {
+			return nil, err
+		}
+		if r.Sign() == 0 {
+			continue
+		}
+		if rInv = new(big.Int).ModInverse(r, priv.N); rInv != nil {
+			break
+		}
+	}
+	blinded := encrypt(&priv.PublicKey, r)
+	blinded.Mul(blinded, c)
+	blinded.Mod(blinded, priv.N)
+
+	m := privateExp(priv, blinded)
+	m.Mul(m, rInv)
+	return m.Mod(m, priv.N), nil
+}
+
+// privateExp 以常量时间计算c^d mod n，没有调用过Precompute时临时构造所需的模数
+func privateExp(priv *PrivateKey, c *big.Int) *big.Int {
+	mv := priv.Precomputed.mont
+	if mv == nil {
+		mv = newMontValues(priv)
+	}
+	cn := nat.FromBig(c, mv.n.Size())
+	if mv.p == nil {
+		return mv.n.Exp(cn, priv.D.FillBytes(make([]byte, (priv.N.BitLen()+7)/8))).Big()
+	}
+
+	// m1 = c^dP mod p, m2 = c^dQ mod q, h = qInv·(m1 - m2) mod p, m = m2 + h·q
+	m1 := mv.p.Exp(mv.p.Reduce(cn), exponentBytes(priv.Precomputed.Dp, priv.Primes[0]))
+	m2 := mv.q.Exp(mv.q.Reduce(cn), exponentBytes(priv.Precomputed.Dq, priv.Primes[1]))
+	mv.p.ModSub(m1, mv.p.Reduce(m2))
+	mv.p.MontMul(m1, m1, mv.qInv)
+	m := make(nat.Nat, mv.n.Size())
+	copy(m, m2)
+	m.AddMul(m1, mv.q.Nat())
+
+	// 多素数时按Garner算法逐个合并剩余的素因子：m += R·(Coeff·(mi - m) mod prime)
+	for i, v := range mv.crt {
+		mi := v.prime.Exp(v.prime.Reduce(cn), exponentBytes(priv.Precomputed.CRTValues[i].Exp, priv.Primes[2+i]))
+		v.prime.ModSub(mi, v.prime.Reduce(m))
+		v.prime.MontMul(mi, mi, v.coeff)
+		m.AddMul(mi, v.r)
+	}
+	return m.Big()
+}
+
+// exponentBytes 将CRT指数编码为与素因子等长的字节串，使求幂的轮数只取决于素因子的长度
+func exponentBytes(exp, prime *big.Int) []byte {
+	return exp.FillBytes(make([]byte, (prime.BitLen()+7)/8))
+}
+
+// montValues 是常量时间私钥运算使用的模数和Montgomery形式的CRT系数
+type montValues struct {
+	n    *nat.Modulus
+	p, q *nat.Modulus
+	qInv nat.Nat // Qinv·R mod p
+	crt  []montCRT
+}
+
+// montCRT 对应CRTValues中的一项
+type montCRT struct {
+	prime *nat.Modulus
+	coeff nat.Nat // Coeff·R mod prime
+	r     nat.Nat // 长度与n相同
+}
+
+// newMontValues 由私钥和CRT预计算值构造montValues，没有CRT预计算值时只构造n
+func newMontValues(priv *PrivateKey) *montValues {
+	mv := &montValues{n: nat.NewModulus(priv.N)}
+	pre := &priv.Precomputed
+	if pre.Dp == nil || len(priv.Primes) != 2+len(pre.CRTValues) {
+		return mv
+	}
+	mv.p, mv.q = nat.NewModulus(priv.Primes[0]), nat.NewModulus(priv.Primes[1])
+	mv.qInv = mv.p.ToMont(nat.FromBig(pre.Qinv, mv.p.Size()))
+	for i, v := range pre.CRTValues {
+		prime := nat.NewModulus(priv.Primes[2+i])
+		mv.crt = append(mv.crt, montCRT{
+			prime: prime,
+			coeff: prime.ToMont(nat.FromBig(v.Coeff, prime.Size())),
+			r:     nat.FromBig(v.R, mv.n.Size()),
+		})
+	}
+	return mv
+}
+
+// decryptAndCheck 执行私钥运算并用公钥验算结果，用于签名以防止CRT故障攻击
+func decryptAndCheck(priv *PrivateKey, c *big.Int) (*big.Int, error) {
+	m, err := decrypt(priv, c)
+	if err != nil {
+		return nil, err
+	}
+	if encrypt(&priv.PublicKey, m).Cmp(c) != 0 {
+		return nil, ErrSigningFailed
+	}
+	return m, nil
+}
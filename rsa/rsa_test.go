@@ -0,0 +1,253 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	stdrsa "crypto/rsa"
+	"crypto/sha256"
+	stdx509 "crypto/x509"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"testing"
+)
+
+// testdata中的密钥、签名和密文由OpenSSL 3.0生成，multiprime.pem为三素数密钥
+func readFile(t testing.TB, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func loadKey(t testing.TB, name string) (*PrivateKey, []byte) {
+	t.Helper()
+	block, _ := pem.Decode(readFile(t, name))
+	priv, err := ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	return priv, block.Bytes
+}
+
+func TestOpenSSLVectors(t *testing.T) {
+	msg := readFile(t, "message.txt")
+	digest := SHA256.Sum(msg)
+	for _, tt := range []struct{ key, sig string }{
+		{"key.pem", "message.sha256.sig"},
+		{"multiprime.pem", "message.multiprime.sig"},
+	} {
+		priv, raw := loadKey(t, tt.key)
+		if len(priv.Primes) != map[string]int{"key.pem": 2, "multiprime.pem": 3}[tt.key] {
+			t.Errorf("%s: %d个素因子", tt.key, len(priv.Primes))
+		}
+		// 重新编码后与OpenSSL的DER逐字节一致（包括otherPrimeInfos中的CRT参数）
+		if der, err := MarshalPKCS1PrivateKey(priv); err != nil || !bytes.Equal(der, raw) {
+			t.Errorf("%s: PKCS#1编码不一致: %v", tt.key, err)
+		}
+
+		sig := readFile(t, tt.sig)
+		if err := VerifyPKCS1v15(&priv.PublicKey, SHA256, digest, sig); err != nil {
+			t.Errorf("%s: %v", tt.sig, err)
+		}
+		// PKCS#1 v1.5签名是确定性的
+		if ours, err := SignPKCS1v15(priv, SHA256, digest); err != nil || !bytes.Equal(ours, sig) {
+			t.Errorf("%s: 签名与OpenSSL不一致: %v", tt.key, err)
+		}
+	}
+
+	priv, _ := loadKey(t, "key.pem")
+	if pt, err := DecryptPKCS1v15(priv, readFile(t, "message.enc")); err != nil || !bytes.Equal(pt, msg) {
+		t.Errorf("解密OpenSSL密文: %q %v", pt, err)
+	}
+}
+
+func TestGenerateKey(t *testing.T) {
+	for _, nprimes := range []int{2, 3} {
+		priv, err := GenerateMultiPrimeKey(nil, nprimes, 1024)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if priv.N.BitLen() != 1024 || len(priv.Primes) != nprimes || priv.Validate() != nil {
+			t.Fatalf("%d: 密钥无效", nprimes)
+		}
+		std := priv.ToStd()
+		if err := std.Validate(); err != nil {
+			t.Fatalf("%d: crypto/rsa检查失败: %v", nprimes, err)
+		}
+
+		msg := []byte("多素数RSA")
+		ct, err := EncryptPKCS1v15(nil, &priv.PublicKey, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pt, err := stdrsa.DecryptPKCS1v15(nil, std, ct); err != nil || !bytes.Equal(pt, msg) {
+			t.Errorf("%d: crypto/rsa解密失败: %v", nprimes, err)
+		}
+		ct, _ = stdrsa.EncryptPKCS1v15(rand.Reader, &std.PublicKey, msg)
+		if pt, err := DecryptPKCS1v15(priv, ct); err != nil || !bytes.Equal(pt, msg) {
+			t.Errorf("%d: 解密crypto/rsa密文失败: %v", nprimes, err)
+		}
+
+		digest := sha256.Sum256(msg)
+		sig, err := SignPKCS1v15(priv, SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := stdrsa.VerifyPKCS1v15(&std.PublicKey, crypto.SHA256, digest[:], sig); err != nil {
+			t.Errorf("%d: crypto/rsa验签失败: %v", nprimes, err)
+		}
+
+		der, _ := MarshalPKCS1PrivateKey(priv)
+		if nprimes == 2 {
+			if want := stdx509.MarshalPKCS1PrivateKey(std); !bytes.Equal(der, want) {
+				t.Errorf("私钥编码与crypto/x509不一致")
+			}
+		}
+		var parsed PrivateKey
+		if err := parsed.UnmarshalBinary(der); err != nil || !parsed.Equal(&priv.PublicKey) || parsed.D.Cmp(priv.D) != 0 {
+			t.Errorf("%d: 私钥往返失败: %v", nprimes, err)
+		}
+	}
+}
+
+func TestSignHashes(t *testing.T) {
+	priv, _ := loadKey(t, "key.pem")
+	msg := []byte("abc")
+	for _, h := range []*Hash{SHA1, SHA224, SHA256, SHA384, SHA512, SM3} {
+		sig, err := SignPKCS1v15(priv, h, h.Sum(msg))
+		if err != nil {
+			t.Fatalf("%s: %v", h, err)
+		}
+		if err := VerifyPKCS1v15(&priv.PublicKey, h, h.Sum(msg), sig); err != nil {
+			t.Errorf("%s: %v", h, err)
+		}
+		// SM3不在crypto.Hash中，其余与crypto/rsa互通
+		if stdHash := map[*Hash]crypto.Hash{SHA1: crypto.SHA1, SHA224: crypto.SHA224, SHA256: crypto.SHA256, SHA384: crypto.SHA384, SHA512: crypto.SHA512}[h]; stdHash != 0 {
+			if err := stdrsa.VerifyPKCS1v15(priv.PublicKey.ToStd(), stdHash, h.Sum(msg), sig); err != nil {
+				t.Errorf("%s: crypto/rsa验签失败: %v", h, err)
+			}
+		}
+		sig[len(sig)-1] ^= 1
+		if err := VerifyPKCS1v15(&priv.PublicKey, h, h.Sum(msg), sig); !errors.Is(err, ErrVerification) {
+			t.Errorf("%s: 篡改签名: %v", h, err)
+		}
+	}
+	if _, err := SignPKCS1v15(priv, SHA256, []byte("short")); !errors.Is(err, ErrInvalidDigest) {
+		t.Errorf("摘要长度错误: %v", err)
+	}
+	// 不带DigestInfo的签名
+	if sig, err := SignPKCS1v15(priv, nil, []byte("raw")); err != nil || VerifyPKCS1v15(&priv.PublicKey, nil, []byte("raw"), sig) != nil {
+		t.Errorf("无DigestInfo签名: %v", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := GenerateKey(nil, 512); !errors.Is(err, ErrKeySize) {
+		t.Errorf("密钥过短: %v", err)
+	}
+	if _, err := GenerateMultiPrimeKey(nil, 5, 1024); !errors.Is(err, ErrTooManyPrimes) {
+		t.Errorf("素因子过多: %v", err)
+	}
+
+	priv, _ := loadKey(t, "key.pem")
+	if _, err := EncryptPKCS1v15(nil, &priv.PublicKey, make([]byte, priv.Size()-10)); !errors.Is(err, ErrMessageTooLong) {
+		t.Errorf("消息过长: %v", err)
+	}
+	ct := readFile(t, "message.enc")
+	for _, bad := range [][]byte{ct[1:], append(bytes.Clone(ct[:len(ct)-1]), ct[len(ct)-1]^1), bytes.Repeat([]byte{0xff}, len(ct))} {
+		if _, err := DecryptPKCS1v15(priv, bad); !errors.Is(err, ErrDecryption) {
+			t.Errorf("无效密文: %v", err)
+		}
+	}
+
+	// 素因子与模数不符
+	bad := *priv
+	bad.Primes = []*big.Int{priv.Primes[0], new(big.Int).Add(priv.Primes[1], big.NewInt(2))}
+	if err := bad.Validate(); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("无效私钥: %v", err)
+	}
+	if _, err := ParsePKCS1PublicKey([]byte{0x30, 0x00}); !errors.Is(err, ErrInvalidPublicKey) {
+		t.Errorf("无效公钥: %v", err)
+	}
+	pubDER, _ := MarshalPKCS1PublicKey(&priv.PublicKey)
+	if want := stdx509.MarshalPKCS1PublicKey(priv.PublicKey.ToStd()); !bytes.Equal(pubDER, want) {
+		t.Error("公钥编码与crypto/x509不一致")
+	}
+}
+
+// 测试会话密钥解密：填充无效或长度不符时保留调用方的随机密钥，与crypto/rsa的行为一致
+func TestDecryptPKCS1v15SessionKey(t *testing.T) {
+	priv, _ := loadKey(t, "key.pem")
+	std := priv.ToStd()
+	sessionKey := bytes.Repeat([]byte{0x5a}, 16)
+	ct, err := EncryptPKCS1v15(nil, &priv.PublicKey, sessionKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrupt := bytes.Clone(ct)
+	corrupt[len(corrupt)-1] ^= 1
+
+	for _, tt := range []struct {
+		name    string
+		ct      []byte
+		keySize int
+		ok      bool
+	}{
+		{"有效", ct, 16, true},
+		{"长度不符", ct, 32, false},
+		{"密文被修改", corrupt, 16, false},
+	} {
+		key := bytes.Repeat([]byte{0xaa}, tt.keySize)
+		if err := DecryptPKCS1v15SessionKey(priv, tt.ct, key); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := bytes.Equal(key, sessionKey); got != tt.ok {
+			t.Errorf("%s: 得到 %x", tt.name, key)
+		}
+		if !tt.ok && !bytes.Equal(key, bytes.Repeat([]byte{0xaa}, tt.keySize)) {
+			t.Errorf("%s: 随机密钥被修改", tt.name)
+		}
+		stdKey := bytes.Repeat([]byte{0xaa}, tt.keySize)
+		stdrsa.DecryptPKCS1v15SessionKey(nil, std, tt.ct, stdKey)
+		if !bytes.Equal(key, stdKey) {
+			t.Errorf("%s: 与crypto/rsa的结果不同", tt.name)
+		}
+	}
+	if err := DecryptPKCS1v15SessionKey(priv, ct[1:], make([]byte, 16)); !errors.Is(err, ErrDecryption) {
+		t.Errorf("密文长度错误: %v", err)
+	}
+}
+
+// 测试RSAEP/RSADP原语：结果与直接求幂一致，输入超出范围时返回错误
+func TestRaw(t *testing.T) {
+	for _, name := range []string{"key.pem", "multiprime.pem"} {
+		priv, _ := loadKey(t, name)
+		m := bytes.Repeat([]byte{0x42}, priv.Size()-1)
+		c, err := EncryptRaw(&priv.PublicKey, m)
+		if err != nil || len(c) != priv.Size() {
+			t.Fatalf("%s: %v", name, err)
+		}
+		want := new(big.Int).Exp(new(big.Int).SetBytes(m), big.NewInt(int64(priv.E)), priv.N)
+		if new(big.Int).SetBytes(c).Cmp(want) != 0 {
+			t.Errorf("%s: RSAEP结果错误", name)
+		}
+		if got, err := DecryptRaw(priv, c); err != nil || !bytes.Equal(got[1:], m) || got[0] != 0 {
+			t.Errorf("%s: RSADP结果错误: %v", name, err)
+		}
+
+		if _, err := EncryptRaw(&priv.PublicKey, priv.N.Bytes()); !errors.Is(err, ErrMessageTooLong) {
+			t.Errorf("%s: m = N: %v", name, err)
+		}
+		if _, err := DecryptRaw(priv, priv.N.FillBytes(make([]byte, priv.Size()))); !errors.Is(err, ErrDecryption) {
+			t.Errorf("%s: c = N: %v", name, err)
+		}
+		if _, err := DecryptRaw(priv, c[1:]); !errors.Is(err, ErrDecryption) {
+			t.Errorf("%s: 密文长度错误: %v", name, err)
+		}
+	}
+}
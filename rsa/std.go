@@ -0,0 +1,64 @@
+package rsa
+
+import (
+	"crypto"
+	stdrsa "crypto/rsa"
+	"math/big"
+)
+
+// FromStdPublicKey 将crypto/rsa的公钥转换为本包的公钥，N被复制
+func FromStdPublicKey(pub *stdrsa.PublicKey) *PublicKey {
+	return &PublicKey{N: new(big.Int).Set(pub.N), E: pub.E}
+}
+
+// FromStdPrivateKey 将crypto/rsa的私钥（包括多素数私钥）转换为本包的私钥并完成预计算
+// 转换不检查私钥的一致性，需要时调用Validate
+func FromStdPrivateKey(priv *stdrsa.PrivateKey) *PrivateKey {
+	k := &PrivateKey{
+		PublicKey: *FromStdPublicKey(&priv.PublicKey),
+		D:         new(big.Int).Set(priv.D),
+		Primes:    make([]*big.Int, len(priv.Primes)),
+	}
+	for i, p := range priv.Primes {
+		k.Primes[i] = new(big.Int).Set(p)
+	}
+	k.Precompute()
+	return k
+}
+
+// ToStd 将公钥转换为crypto/rsa的公钥，用于只接受标准库类型的接口
+func (pub *PublicKey) ToStd() *stdrsa.PublicKey {
+	return &stdrsa.PublicKey{N: new(big.Int).Set(pub.N), E: pub.E}
+}
+
+// ToStd 将私钥转换为crypto/rsa的私钥并完成预计算，用于只接受标准库类型的接口
+func (priv *PrivateKey) ToStd() *stdrsa.PrivateKey {
+	k := &stdrsa.PrivateKey{
+		PublicKey: *priv.PublicKey.ToStd(),
+		D:         new(big.Int).Set(priv.D),
+		Primes:    make([]*big.Int, len(priv.Primes)),
+	}
+	for i, p := range priv.Primes {
+		k.Primes[i] = new(big.Int).Set(p)
+	}
+	k.Precompute()
+	return k
+}
+
+// HashFor 返回crypto.Hash对应的摘要算法，不支持的算法返回nil
+// SM3没有对应的crypto.Hash，只能直接使用SM3
+func HashFor(h crypto.Hash) *Hash {
+	switch h {
+	case crypto.SHA1:
+		return SHA1
+	case crypto.SHA224:
+		return SHA224
+	case crypto.SHA256:
+		return SHA256
+	case crypto.SHA384:
+		return SHA384
+	case crypto.SHA512:
+		return SHA512
+	}
+	return nil
+}
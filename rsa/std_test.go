@@ -0,0 +1,56 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto"
+	stdrsa "crypto/rsa"
+	"testing"
+)
+
+// 测试与crypto/rsa密钥的相互转换：转换后的密钥可以互相验证签名和解密
+func TestStdConversion(t *testing.T) {
+	for _, name := range []string{"key.pem", "multiprime.pem"} {
+		priv, raw := loadKey(t, name)
+		std := priv.ToStd()
+		if err := std.Validate(); err != nil {
+			t.Fatalf("%s: crypto/rsa检查失败: %v", name, err)
+		}
+		back := FromStdPrivateKey(std)
+		if err := back.Validate(); err != nil {
+			t.Fatalf("%s: 转换回来的私钥无效: %v", name, err)
+		}
+		if der, err := MarshalPKCS1PrivateKey(back); err != nil || !bytes.Equal(der, raw) {
+			t.Errorf("%s: 往返转换后编码不一致", name)
+		}
+		if !FromStdPublicKey(&std.PublicKey).Equal(&priv.PublicKey) || !priv.PublicKey.ToStd().Equal(&std.PublicKey) {
+			t.Errorf("%s: 公钥转换不一致", name)
+		}
+
+		// 转换得到的是副本，修改标准库密钥不影响原密钥
+		std.N.SetInt64(1)
+		if back.N.Cmp(priv.N) != 0 {
+			t.Fatalf("%s: 转换结果与标准库密钥共享了N", name)
+		}
+
+		digest := SHA256.Sum([]byte("message"))
+		sig, err := SignPSS(nil, back, SHA256, digest, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts := &stdrsa.PSSOptions{SaltLength: stdrsa.PSSSaltLengthEqualsHash}
+		if err := stdrsa.VerifyPSS(priv.PublicKey.ToStd(), crypto.SHA256, digest, sig, opts); err != nil {
+			t.Errorf("%s: crypto/rsa验证失败: %v", name, err)
+		}
+	}
+}
+
+func TestHashFor(t *testing.T) {
+	for h, want := range map[crypto.Hash]*Hash{
+		crypto.SHA1: SHA1, crypto.SHA224: SHA224, crypto.SHA256: SHA256,
+		crypto.SHA384: SHA384, crypto.SHA512: SHA512, crypto.MD5: nil,
+	} {
+		if got := HashFor(h); got != want {
+			t.Errorf("HashFor(%v) = %v，期望 %v", h, got, want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Package salsa20 实现Salsa20/20流密码及其扩展nonce变体XSalsa20
+package salsa20
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/internal/alias"
+)
+
+const (
+	// KeySize 是密钥长度（字节）
+	KeySize = 32
+	// NonceSize 是Salsa20的nonce长度（字节）
+	NonceSize = 8
+	// NonceSizeX 是XSalsa20的nonce长度（字节）
+	NonceSizeX = 24
+	// BlockSize 是每次生成的密钥流长度（字节）
+	BlockSize = 64
+)
+
+// 错误定义
+var (
+	ErrInvalidKeySize   = gscerr.New(gscerr.ErrKeySize, "salsa20: key must be 32 bytes")
+	ErrInvalidNonceSize = gscerr.New(gscerr.ErrNonceSize, "salsa20: nonce must be 8 or 24 bytes")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidKeySize:   "salsa20: 密钥长度必须为32字节",
+		ErrInvalidNonceSize: "salsa20: nonce长度必须为8或24字节",
+	})
+}
+
+// sigma 是常量"expand 32-byte k"
+var sigma = [4]uint32{0x61707865, 0x3320646e, 0x79622d32, 0x6b206574}
+
+// Cipher 是Salsa20密钥流生成器，实现crypto/cipher.Stream
+type Cipher struct {
+	key     [8]uint32
+	nonce   [2]uint32
+	counter uint64
+
+	// buf 缓存当前块中尚未使用的密钥流
+	buf [BlockSize]byte
+	len int
+}
+
+// New 使用32字节密钥和nonce创建Salsa20实例
+// nonce为8字节时为Salsa20，为24字节时为XSalsa20：
+// 先以HSalsa20从密钥和nonce的前16字节派生子密钥，再以剩余8字节作为nonce
+func New(key, nonce []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, gscerr.KeySize(ErrInvalidKeySize, "Salsa20", len(key), KeySize)
+	}
+	switch len(nonce) {
+	case NonceSize:
+	case NonceSizeX:
+		subKey, _ := HSalsa20(key, nonce[:16])
+		key, nonce = subKey, nonce[16:]
+	default:
+		return nil, ErrInvalidNonceSize
+	}
+
+	c := &Cipher{}
+	for i := range c.key {
+		c.key[i] = binary.LittleEndian.Uint32(key[i*4:])
+	}
+	c.nonce[0] = binary.LittleEndian.Uint32(nonce[0:])
+	c.nonce[1] = binary.LittleEndian.Uint32(nonce[4:])
+	return c, nil
+}
+
+// XORKeyStream 将src与密钥流异或写入dst，满足crypto/cipher.Stream的约定
+// dst短于src或dst与src部分重叠时panic
+func (c *Cipher) XORKeyStream(dst, src []byte) {
+	if len(src) == 0 {
+		return
+	}
+	if len(dst) < len(src) {
+		panic("salsa20: output smaller than input")
+	}
+	dst = dst[:len(src)]
+	if alias.InexactOverlap(dst, src) {
+		panic("salsa20: invalid buffer overlap")
+	}
+
+	// 先使用上次剩余的密钥流
+	if c.len > 0 {
+		n := subtle.XORBytes(dst, src, c.buf[BlockSize-c.len:])
+		c.len -= n
+		dst, src = dst[n:], src[n:]
+	}
+
+	for len(src) > 0 {
+		c.block(&c.buf)
+		c.counter++
+		n := subtle.XORBytes(dst, src, c.buf[:])
+		c.len = BlockSize - n
+		dst, src = dst[n:], src[n:]
+	}
+}
+
+// block 以当前计数器生成一个密钥流块
+func (c *Cipher) block(out *[BlockSize]byte) {
+	var x [16]uint32
+	x[0], x[5], x[10], x[15] = sigma[0], sigma[1], sigma[2], sigma[3]
+	copy(x[1:5], c.key[:4])
+	copy(x[11:15], c.key[4:])
+	x[6], x[7] = c.nonce[0], c.nonce[1]
+	x[8], x[9] = uint32(c.counter), uint32(c.counter>>32)
+
+	input := x
+	rounds(&x)
+	for i := range x {
+		binary.LittleEndian.PutUint32(out[i*4:], x[i]+input[i])
+	}
+}
+
+// HSalsa20 从32字节密钥和16字节输入派生32字节子密钥，用于XSalsa20和NaCl的box
+func HSalsa20(key, input []byte) ([]byte, error) {
+	if len(key) != KeySize {
+		return nil, gscerr.KeySize(ErrInvalidKeySize, "Salsa20", len(key), KeySize)
+	}
+	if len(input) != 16 {
+		return nil, ErrInvalidNonceSize
+	}
+
+	var x [16]uint32
+	x[0], x[5], x[10], x[15] = sigma[0], sigma[1], sigma[2], sigma[3]
+	for i := 0; i < 4; i++ {
+		x[1+i] = binary.LittleEndian.Uint32(key[i*4:])
+		x[11+i] = binary.LittleEndian.Uint32(key[16+i*4:])
+		x[6+i] = binary.LittleEndian.Uint32(input[i*4:])
+	}
+	rounds(&x)
+
+	out := make([]byte, KeySize)
+	for i, j := range [8]int{0, 5, 10, 15, 6, 7, 8, 9} {
+		binary.LittleEndian.PutUint32(out[i*4:], x[j])
+	}
+	return out, nil
+}
+
+// rounds 执行20轮（10次列轮和行轮）变换
+func rounds(x *[16]uint32) {
+	for i := 0; i < 10; i++ {
+		// 列轮
+		x[0], x[4], x[8], x[12] = quarterRound(x[0], x[4], x[8], x[12])
+		x[5], x[9], x[13], x[1] = quarterRound(x[5], x[9], x[13], x[1])
+		x[10], x[14], x[2], x[6] = quarterRound(x[10], x[14], x[2], x[6])
+		x[15], x[3], x[7], x[11] = quarterRound(x[15], x[3], x[7], x[11])
+		// 行轮
+		x[0], x[1], x[2], x[3] = quarterRound(x[0], x[1], x[2], x[3])
+		x[5], x[6], x[7], x[4] = quarterRound(x[5], x[6], x[7], x[4])
+		x[10], x[11], x[8], x[9] = quarterRound(x[10], x[11], x[8], x[9])
+		x[15], x[12], x[13], x[14] = quarterRound(x[15], x[12], x[13], x[14])
+	}
+}
+
+// quarterRound 是Salsa20的四分之一轮函数
+func quarterRound(a, b, c, d uint32) (uint32, uint32, uint32, uint32) {
+	b ^= bits.RotateLeft32(a+d, 7)
+	c ^= bits.RotateLeft32(b+a, 9)
+	d ^= bits.RotateLeft32(c+b, 13)
+	a ^= bits.RotateLeft32(d+c, 18)
+	return a, b, c, d
+}
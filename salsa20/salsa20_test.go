@@ -0,0 +1,75 @@
+package salsa20
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// 测试XSalsa20等价于以HSalsa20子密钥运行的Salsa20
+// 密钥流的正确性由nacl/secretbox的NaCl测试向量覆盖
+func TestXSalsa20(t *testing.T) {
+	key := make([]byte, KeySize)
+	nonce := make([]byte, NonceSizeX)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	for i := range nonce {
+		nonce[i] = byte(i + 0x40)
+	}
+
+	x, err := New(key, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subKey, _ := HSalsa20(key, nonce[:16])
+	s, _ := New(subKey, nonce[16:])
+
+	a := make([]byte, 300)
+	b := make([]byte, 300)
+	x.XORKeyStream(a, a)
+	s.XORKeyStream(b, b)
+	if !bytes.Equal(a, b) {
+		t.Fatal("XSalsa20的密钥流不正确")
+	}
+
+	// 分段调用的结果与一次调用相同
+	for _, step := range []int{1, 31, 64, 100} {
+		c, _ := New(key, nonce)
+		got := make([]byte, len(a))
+		for i := 0; i < len(got); i += step {
+			end := min(i+step, len(got))
+			c.XORKeyStream(got[i:end], got[i:end])
+		}
+		if !bytes.Equal(got, a) {
+			t.Fatalf("每次处理%d字节时密钥流不匹配", step)
+		}
+	}
+}
+
+// 测试Salsa20/20的官方向量（eSTREAM，密钥为0x80后接31个0，nonce全0）
+func TestSalsa20(t *testing.T) {
+	key := make([]byte, KeySize)
+	key[0] = 0x80
+	c, err := New(key, make([]byte, NonceSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]byte, 64)
+	c.XORKeyStream(got, got)
+	want := []byte{
+		0xe3, 0xbe, 0x8f, 0xdd, 0x8b, 0xec, 0xa2, 0xe3, 0xea, 0x8e, 0xf9, 0x47, 0x5b, 0x29, 0xa6, 0xe7,
+	}
+	if !bytes.Equal(got[:16], want) {
+		t.Fatalf("密钥流不匹配\n预期: %x\n实际: %x", want, got[:16])
+	}
+}
+
+func TestInvalidParameters(t *testing.T) {
+	if _, err := New(make([]byte, 16), make([]byte, NonceSize)); !errors.Is(err, ErrInvalidKeySize) {
+		t.Errorf("16字节密钥应返回ErrInvalidKeySize，实际: %v", err)
+	}
+	if _, err := New(make([]byte, KeySize), make([]byte, 12)); err != ErrInvalidNonceSize {
+		t.Errorf("12字节nonce应返回ErrInvalidNonceSize，实际: %v", err)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !linux && !darwin
+
+package secure
+
+import "errors"
+
+// errNoMlock 表示当前平台不支持锁定内存
+var errNoMlock = errors.New("secure: mlock not supported on this platform")
+
+func mlock([]byte) error { return errNoMlock }
+
+func munlock([]byte) {}
@@ -0,0 +1,57 @@
+//go:build linux || darwin
+
+package secure
+
+import (
+	"os"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// mlock和munlock以整页为单位，同一页的多次锁定不会嵌套：
+// 两个缓冲区共用一页时，一个解除锁定会使另一个也失去锁定。
+// lockedPages按页记录锁定该页的缓冲区数量，只有计数降为0时才解除该页的锁定
+var (
+	pagesMu     sync.Mutex
+	lockedPages = make(map[uintptr]int)
+	pageSize    = uintptr(os.Getpagesize())
+)
+
+// mlock 锁定b所在的内存页，使其不被换出
+func mlock(b []byte) error {
+	pagesMu.Lock()
+	defer pagesMu.Unlock()
+	if err := syscall.Mlock(b); err != nil {
+		return err
+	}
+	forEachPage(b, func(page uintptr, _ []byte) {
+		lockedPages[page]++
+	})
+	return nil
+}
+
+// munlock 解除b的锁定，只解除不再被其他缓冲区使用的页，失败时忽略
+func munlock(b []byte) {
+	pagesMu.Lock()
+	defer pagesMu.Unlock()
+	forEachPage(b, func(page uintptr, part []byte) {
+		if lockedPages[page]--; lockedPages[page] > 0 {
+			return
+		}
+		delete(lockedPages, page)
+		// part位于该页之内，内核按页解除锁定
+		_ = syscall.Munlock(part)
+	})
+}
+
+// forEachPage 对b覆盖的每一页调用f，part是b落在该页内的部分
+func forEachPage(b []byte, f func(page uintptr, part []byte)) {
+	start := uintptr(unsafe.Pointer(unsafe.SliceData(b)))
+	for off := uintptr(0); off < uintptr(len(b)); {
+		page := (start + off) &^ (pageSize - 1)
+		end := min(page+pageSize-start, uintptr(len(b)))
+		f(page, b[off:end])
+		off = end
+	}
+}
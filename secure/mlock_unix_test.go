@@ -0,0 +1,51 @@
+//go:build linux || darwin
+
+package secure
+
+import "testing"
+
+// 测试同一页上的两个缓冲区：解除其中一个的锁定后，该页仍保持锁定
+func TestNestedLock(t *testing.T) {
+	buf := make([]byte, 64)
+	a, b := buf[:32], buf[32:]
+	if err := mlock(a); err != nil {
+		t.Skipf("mlock不可用: %v", err)
+	}
+	if err := mlock(b); err != nil {
+		munlock(a)
+		t.Skipf("mlock不可用: %v", err)
+	}
+	page := uintptr(0)
+	forEachPage(a, func(p uintptr, _ []byte) { page = p })
+
+	munlock(a)
+	pagesMu.Lock()
+	n := lockedPages[page]
+	pagesMu.Unlock()
+	if n != 1 {
+		t.Fatalf("解除一个缓冲区后页计数为%d，期望1", n)
+	}
+	munlock(b)
+	pagesMu.Lock()
+	_, ok := lockedPages[page]
+	pagesMu.Unlock()
+	if ok {
+		t.Fatal("所有缓冲区解除后该页仍有计数")
+	}
+}
+
+// 测试跨页的缓冲区按页拆分
+func TestForEachPage(t *testing.T) {
+	buf := make([]byte, 3*int(pageSize))
+	total, pages := 0, 0
+	forEachPage(buf[1:], func(p uintptr, part []byte) {
+		if p%pageSize != 0 || len(part) == 0 || len(part) > int(pageSize) {
+			t.Fatalf("页%#x的部分长度为%d", p, len(part))
+		}
+		total += len(part)
+		pages++
+	})
+	if total != len(buf)-1 || pages < 3 {
+		t.Fatalf("覆盖%d字节、%d页", total, pages)
+	}
+}
@@ -0,0 +1,101 @@
+// Package secure 提供保存密钥材料的内存缓冲区
+//
+// Bytes（即SecureBytes）在创建时复制传入的数据，支持显式清零（Wipe），
+// 并尽力用mlock锁定内存页，避免密钥被换出到磁盘。锁定按页计数，多个缓冲区共用一页时，
+// 最后一个缓冲区清零或被回收后才解除该页的锁定。Go的垃圾回收器可能移动或复制
+// 普通切片，本包无法防止运行时在别处留下副本，只能缩短密钥在内存中停留的时间。
+//
+// 分组密码的轮密钥（aes、des、sm4）保存在普通内存中，只在Wipe时用本包的Wipe函数清零，
+// 不做mlock锁定：每次创建实例都锁定会增加一次系统调用，且受RLIMIT_MEMLOCK限制
+package secure
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Bytes 是保存密钥材料的缓冲区
+// 零值为空缓冲区；实例被垃圾回收时内容会被自动清零，但仍应在用完后尽早调用Wipe
+type Bytes struct {
+	mu     sync.Mutex
+	buf    []byte
+	locked bool
+}
+
+// New 返回长度为n、内容全为0的缓冲区
+func New(n int) *Bytes {
+	b := &Bytes{buf: make([]byte, n)}
+	if n > 0 {
+		b.locked = mlock(b.buf) == nil
+		// 清理函数只引用底层数组，不引用b本身，否则b永远不会被回收
+		runtime.AddCleanup(b, release, region{buf: b.buf, locked: b.locked})
+	}
+	return b
+}
+
+// From 返回保存data副本的缓冲区，之后修改data不影响缓冲区
+// 调用方应在复制后自行清零data（可使用Wipe函数）
+func From(data []byte) *Bytes {
+	b := New(len(data))
+	copy(b.buf, data)
+	return b
+}
+
+// Bytes 返回缓冲区的底层切片，不复制
+// 返回的切片在Wipe后内容全为0，调用方不应在Wipe之后继续持有它
+func (b *Bytes) Bytes() []byte {
+	return b.buf
+}
+
+// Copy 返回缓冲区内容的副本，副本不受Wipe影响，也不会被锁定或自动清零
+func (b *Bytes) Copy() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.buf...)
+}
+
+// Len 返回缓冲区长度
+func (b *Bytes) Len() int {
+	return len(b.buf)
+}
+
+// Locked 报告缓冲区的内存页是否已被mlock锁定
+// 平台不支持或超出RLIMIT_MEMLOCK限制时返回false，缓冲区仍可正常使用
+func (b *Bytes) Locked() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.locked
+}
+
+// Wipe 将缓冲区清零并解除内存锁定，可重复调用
+// 清零后长度不变，内容全为0
+func (b *Bytes) Wipe() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	Wipe(b.buf)
+	if b.locked {
+		munlock(b.buf)
+		b.locked = false
+	}
+}
+
+// Wipe 将s清零，用于清除以字或字节保存的轮密钥等秘密数据
+func Wipe[T ~uint8 | ~uint16 | ~uint32 | ~uint64](s []T) {
+	clear(s)
+	// 阻止编译器把对即将不再使用的切片的写入当作无用写入消除
+	runtime.KeepAlive(s)
+}
+
+// region 是清理函数使用的缓冲区信息
+type region struct {
+	buf    []byte
+	locked bool
+}
+
+// release 在缓冲区被回收时清零并解除锁定；Wipe之后再次清零和munlock都是无害的
+func release(r region) {
+	Wipe(r.buf)
+	if r.locked {
+		munlock(r.buf)
+	}
+}
@@ -0,0 +1,49 @@
+package secure
+
+import (
+	"bytes"
+	"testing"
+)
+
+// 测试From复制数据，Wipe清零缓冲区但不影响Copy返回的副本
+func TestBytes(t *testing.T) {
+	data := []byte("0123456789abcdef")
+	b := From(data)
+	data[0] = 'x'
+	if got := b.Bytes(); !bytes.Equal(got, []byte("0123456789abcdef")) {
+		t.Fatalf("From未复制数据: %q", got)
+	}
+	if b.Len() != 16 {
+		t.Fatalf("Len() = %d", b.Len())
+	}
+
+	c := b.Copy()
+	view := b.Bytes()
+	b.Wipe()
+	if !bytes.Equal(view, make([]byte, 16)) {
+		t.Fatal("Wipe后缓冲区应全为0")
+	}
+	if !bytes.Equal(c, []byte("0123456789abcdef")) {
+		t.Fatal("Copy返回的副本不应受Wipe影响")
+	}
+	if b.Locked() {
+		t.Fatal("Wipe后应解除锁定")
+	}
+	b.Wipe()
+
+	var zero Bytes
+	zero.Wipe()
+	if zero.Len() != 0 || New(0).Len() != 0 {
+		t.Fatal("空缓冲区长度应为0")
+	}
+}
+
+func TestWipe(t *testing.T) {
+	words := []uint32{1, 2, 3}
+	Wipe(words)
+	for _, w := range words {
+		if w != 0 {
+			t.Fatal("Wipe未清零")
+		}
+	}
+}
@@ -0,0 +1,251 @@
+// Package signify 实现与OpenBSD signify兼容的Ed25519分离签名，可读写signify的公钥、私钥和签名文件
+//
+// 每个文件由一行"untrusted comment: "开头的注释和一行base64数据组成，注释不受签名保护。
+// 私钥可以用口令加密：口令经bcrypt_pbkdf派生64字节的密钥流后与私钥异或。
+// 不支持signify -e的内嵌签名和-z的gzip签名
+package signify
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"strings"
+
+	"github.com/laenix/gsc/ed25519"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/kdf/bcrypt"
+)
+
+const (
+	// DefaultRounds 是signify加密私钥时bcrypt_pbkdf的默认轮数
+	DefaultRounds = 42
+
+	commentPrefix = "untrusted comment: "
+	algEd25519    = "Ed"
+	kdfBcrypt     = "BK"
+
+	keyNumSize    = 8
+	saltSize      = 16
+	checksumSize  = 8
+	publicKeySize = 2 + keyNumSize + ed25519.PublicKeySize
+	signatureSize = 2 + keyNumSize + ed25519.SignatureSize
+	// 算法 || KDF算法 || 轮数 || 盐 || 校验值 || 密钥编号 || 私钥
+	privateKeySize = 2 + 2 + 4 + saltSize + checksumSize + keyNumSize + ed25519.PrivateKeySize
+)
+
+// 错误定义
+var (
+	ErrMalformedKey         = gscerr.New(gscerr.ErrMalformed, "signify: malformed key")
+	ErrMalformedSignature   = gscerr.New(gscerr.ErrMalformed, "signify: malformed signature")
+	ErrUnsupportedAlgorithm = gscerr.New(gscerr.ErrUnsupported, "signify: unsupported algorithm")
+	ErrKeyMismatch          = gscerr.New(gscerr.ErrVerification, "signify: signature was created with a different key")
+	ErrInvalidSignature     = gscerr.New(gscerr.ErrVerification, "signify: invalid signature")
+	ErrWrongPassword        = gscerr.New(gscerr.ErrAuthFailed, "signify: incorrect passphrase")
+	ErrPasswordRequired     = gscerr.New(gscerr.ErrParameter, "signify: secret key is encrypted and requires a passphrase")
+	ErrComment              = gscerr.New(gscerr.ErrParameter, "signify: comment must be a single line")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrMalformedKey:         "signify: 密钥格式错误",
+		ErrMalformedSignature:   "signify: 签名格式错误",
+		ErrUnsupportedAlgorithm: "signify: 不支持的算法",
+		ErrKeyMismatch:          "signify: 签名由其他密钥生成",
+		ErrInvalidSignature:     "signify: 签名无效",
+		ErrWrongPassword:        "signify: 口令错误",
+		ErrPasswordRequired:     "signify: 私钥已加密，需要口令",
+		ErrComment:              "signify: 注释必须是单行",
+	})
+}
+
+// PublicKey 是signify公钥，KeyNum是随机的密钥编号，签名中记录该编号以检查公钥是否匹配
+type PublicKey struct {
+	KeyNum [keyNumSize]byte
+	Key    ed25519.PublicKey
+}
+
+// PrivateKey 是signify私钥
+type PrivateKey struct {
+	KeyNum [keyNumSize]byte
+	Key    ed25519.PrivateKey
+}
+
+// GenerateKey 生成密钥对和随机的密钥编号，random为nil时使用crypto/rand
+func GenerateKey(random io.Reader) (*PublicKey, *PrivateKey, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	priv := new(PrivateKey)
+	if _, err := io.ReadFull(random, priv.KeyNum[:]); err != nil {
+		return nil, nil, err
+	}
+	pub, key, err := ed25519.GenerateKey(random)
+	if err != nil {
+		return nil, nil, err
+	}
+	priv.Key = key
+	return &PublicKey{KeyNum: priv.KeyNum, Key: pub}, priv, nil
+}
+
+// Public 返回对应的公钥
+func (k *PrivateKey) Public() *PublicKey {
+	return &PublicKey{KeyNum: k.KeyNum, Key: k.Key.Public().(ed25519.PublicKey)}
+}
+
+// Marshal 编码为signify公钥文件，comment为空时使用"signify public key"
+func (k *PublicKey) Marshal(comment string) ([]byte, error) {
+	if comment == "" {
+		comment = "signify public key"
+	}
+	b := make([]byte, 0, publicKeySize)
+	b = append(b, algEd25519...)
+	b = append(b, k.KeyNum[:]...)
+	b = append(b, k.Key...)
+	return marshalFile(comment, b)
+}
+
+// ParsePublicKey 解析signify公钥文件
+func ParsePublicKey(data []byte) (*PublicKey, error) {
+	_, b, err := parseFile(data, publicKeySize, ErrMalformedKey)
+	if err != nil {
+		return nil, err
+	}
+	pub := &PublicKey{Key: ed25519.PublicKey(b[2+keyNumSize:])}
+	copy(pub.KeyNum[:], b[2:])
+	return pub, nil
+}
+
+// Marshal 编码为signify私钥文件，comment为空时使用"signify secret key"
+// password为空时私钥不加密（相当于signify -n），否则以rounds轮bcrypt_pbkdf加密，rounds为0时使用DefaultRounds
+func (k *PrivateKey) Marshal(password []byte, rounds int, comment string) ([]byte, error) {
+	if comment == "" {
+		comment = "signify secret key"
+	}
+	if len(password) == 0 {
+		rounds = 0
+	} else if rounds == 0 {
+		rounds = DefaultRounds
+	}
+
+	b := make([]byte, privateKeySize)
+	copy(b, algEd25519+kdfBcrypt)
+	binary.BigEndian.PutUint32(b[4:], uint32(rounds))
+	salt := b[8 : 8+saltSize]
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	sum := sha512.Sum512(k.Key)
+	copy(b[8+saltSize:], sum[:checksumSize])
+	copy(b[8+saltSize+checksumSize:], k.KeyNum[:])
+	key := b[privateKeySize-ed25519.PrivateKeySize:]
+	copy(key, k.Key)
+	if rounds > 0 {
+		stream, err := bcrypt.Key(password, salt, rounds, ed25519.PrivateKeySize)
+		if err != nil {
+			return nil, err
+		}
+		subtle.XORBytes(key, key, stream)
+	}
+	return marshalFile(comment, b)
+}
+
+// ParsePrivateKey 解析signify私钥文件，加密的私钥需要提供口令
+func ParsePrivateKey(data, password []byte) (*PrivateKey, error) {
+	_, b, err := parseFile(data, privateKeySize, ErrMalformedKey)
+	if err != nil {
+		return nil, err
+	}
+	if string(b[2:4]) != kdfBcrypt {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	rounds := binary.BigEndian.Uint32(b[4:])
+	salt := b[8 : 8+saltSize]
+	checksum := b[8+saltSize : 8+saltSize+checksumSize]
+	key := b[privateKeySize-ed25519.PrivateKeySize:]
+	if rounds > 0 {
+		if len(password) == 0 {
+			return nil, ErrPasswordRequired
+		}
+		stream, err := bcrypt.Key(password, salt, int(rounds), ed25519.PrivateKeySize)
+		if err != nil {
+			return nil, err
+		}
+		subtle.XORBytes(key, key, stream)
+	}
+	sum := sha512.Sum512(key)
+	if subtle.ConstantTimeCompare(sum[:checksumSize], checksum) != 1 {
+		return nil, ErrWrongPassword
+	}
+	priv := &PrivateKey{Key: ed25519.PrivateKey(key)}
+	// 私钥的后32字节是公钥，必须与种子一致
+	if full, err := ed25519.NewKeyFromSeed(priv.Key.Seed()); err != nil || !bytes.Equal(full, priv.Key) {
+		return nil, ErrMalformedKey
+	}
+	copy(priv.KeyNum[:], b[8+saltSize+checksumSize:])
+	return priv, nil
+}
+
+// Sign 签名message，返回signify签名文件（.sig）的内容；comment为空时使用"signature from signify secret key"
+func Sign(priv *PrivateKey, message []byte, comment string) ([]byte, error) {
+	if comment == "" {
+		comment = "signature from signify secret key"
+	}
+	sig, err := ed25519.Sign(priv.Key, message)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, 0, signatureSize)
+	b = append(b, algEd25519...)
+	b = append(b, priv.KeyNum[:]...)
+	b = append(b, sig...)
+	return marshalFile(comment, b)
+}
+
+// Verify 用pub验证message的签名文件，通过时返回签名文件中（不受保护的）注释
+func Verify(pub *PublicKey, message, signature []byte) (comment string, err error) {
+	comment, b, err := parseFile(signature, signatureSize, ErrMalformedSignature)
+	if err != nil {
+		return "", err
+	}
+	if !bytes.Equal(b[2:2+keyNumSize], pub.KeyNum[:]) {
+		return "", ErrKeyMismatch
+	}
+	if !ed25519.Verify(pub.Key, message, b[2+keyNumSize:]) {
+		return "", ErrInvalidSignature
+	}
+	return comment, nil
+}
+
+// marshalFile 编码注释行和base64数据行
+func marshalFile(comment string, b []byte) ([]byte, error) {
+	if strings.ContainsAny(comment, "\r\n") {
+		return nil, ErrComment
+	}
+	return []byte(commentPrefix + comment + "\n" + base64.StdEncoding.EncodeToString(b) + "\n"), nil
+}
+
+// parseFile 解析注释行和base64数据行，检查数据长度和签名算法
+func parseFile(data []byte, size int, malformed error) (string, []byte, error) {
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2 {
+		return "", nil, malformed
+	}
+	comment, ok := strings.CutPrefix(lines[0], commentPrefix)
+	if !ok {
+		return "", nil, malformed
+	}
+	b, err := base64.StdEncoding.Strict().DecodeString(lines[1])
+	if err != nil || len(b) != size {
+		return "", nil, malformed
+	}
+	if string(b[:2]) != algEd25519 {
+		return "", nil, ErrUnsupportedAlgorithm
+	}
+	return comment, b, nil
+}
@@ -0,0 +1,113 @@
+package signify
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// testdata中的文件由按signify格式独立编写的脚本借助libsodium生成，私钥未加密
+func readFile(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestVectors(t *testing.T) {
+	pub, err := ParsePublicKey(readFile(t, "key.pub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := ParsePrivateKey(readFile(t, "key.sec"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if priv.KeyNum != pub.KeyNum || !priv.Public().Key.Equal(pub.Key) {
+		t.Error("私钥与公钥不匹配")
+	}
+
+	msg, sig := readFile(t, "message.txt"), readFile(t, "message.txt.sig")
+	if comment, err := Verify(pub, msg, sig); err != nil || comment != "verify with key.pub" {
+		t.Errorf("验证失败: %q %v", comment, err)
+	}
+	// Ed25519签名是确定性的
+	if ours, _ := Sign(priv, msg, "verify with key.pub"); !bytes.Equal(ours, sig) {
+		t.Errorf("签名文件不一致:\n%s", ours)
+	}
+	if data, _ := pub.Marshal(""); !bytes.Equal(data, readFile(t, "key.pub")) {
+		t.Errorf("公钥文件不一致:\n%s", data)
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	pub, priv, err := GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("SHA256 (bsd.rd) = ...\n")
+	sig, err := Sign(priv, msg, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(pub, msg, sig); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(pub, msg[1:], sig); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("篡改原文: %v", err)
+	}
+	other, _, _ := GenerateKey(nil)
+	if _, err := Verify(other, msg, sig); !errors.Is(err, ErrKeyMismatch) {
+		t.Errorf("其他公钥: %v", err)
+	}
+	if _, err := Sign(priv, msg, "a\nb"); !errors.Is(err, ErrComment) {
+		t.Errorf("多行注释: %v", err)
+	}
+}
+
+func TestPrivateKeyRoundTrip(t *testing.T) {
+	_, priv, _ := GenerateKey(nil)
+	for _, password := range []string{"", "passphrase"} {
+		data, err := priv.Marshal([]byte(password), 4, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ParsePrivateKey(data, []byte(password))
+		if err != nil {
+			t.Fatalf("%q: %v", password, err)
+		}
+		if got.KeyNum != priv.KeyNum || !got.Key.Equal(priv.Key) {
+			t.Errorf("%q: 往返结果不一致", password)
+		}
+	}
+
+	data, _ := priv.Marshal([]byte("passphrase"), 4, "")
+	if _, err := ParsePrivateKey(data, []byte("wrong")); !errors.Is(err, ErrWrongPassword) {
+		t.Errorf("错误口令: %v", err)
+	}
+	if _, err := ParsePrivateKey(data, nil); !errors.Is(err, ErrPasswordRequired) {
+		t.Errorf("缺少口令: %v", err)
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	sig := string(readFile(t, "message.txt.sig"))
+	pub, _ := ParsePublicKey(readFile(t, "key.pub"))
+	for _, s := range []string{
+		"",
+		strings.TrimPrefix(sig, "untrusted "),
+		strings.Replace(sig, "\n", "\n\n", 1),
+		sig[:len(sig)-5] + "\n",
+	} {
+		if _, err := Verify(pub, nil, []byte(s)); !errors.Is(err, ErrMalformedSignature) {
+			t.Errorf("%q: %v", s, err)
+		}
+	}
+	if _, err := ParsePublicKey(readFile(t, "message.txt.sig")); !errors.Is(err, ErrMalformedKey) {
+		t.Errorf("签名不是公钥: %v", err)
+	}
+}
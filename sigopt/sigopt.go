@@ -0,0 +1,80 @@
+package sigopt
+
+import (
+	"hash"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+// 错误定义
+var (
+	ErrInvalidDigestSize = gscerr.New(gscerr.ErrParameter, "sigopt: digest size does not match the signature algorithm")
+	ErrUnsupportedOpts   = gscerr.New(gscerr.ErrUnsupported, "sigopt: unsupported signer options")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidDigestSize: "sigopt: 摘要长度与签名算法不匹配",
+		ErrUnsupportedOpts:   "sigopt: 不支持的签名选项",
+	})
+}
+
+// Opts 描述传给签名算法的数据形式
+// 签名API通过它区分“已经是摘要”和“原始消息”，
+// 避免把任意长度的未哈希数据直接当作摘要签名
+type Opts interface {
+	// Prehashed 返回数据是否已经是摘要
+	Prehashed() bool
+}
+
+// PreHashed 表示待签名数据已经是摘要
+// Size为期望的摘要长度（字节），为0时使用签名算法的默认长度
+type PreHashed struct {
+	Size int
+}
+
+// Prehashed 实现Opts接口
+func (PreHashed) Prehashed() bool { return true }
+
+// Message 表示待签名数据是原始消息，由签名算法负责计算摘要
+// Hash为空时使用签名算法的默认摘要（如SM2使用SM3）；
+// UID为身份标识，仅对需要计算身份杂凑值的算法（SM2的ZA）有效
+type Message struct {
+	Hash func() hash.Hash
+	UID  []byte
+}
+
+// Prehashed 实现Opts接口
+func (Message) Prehashed() bool { return false }
+
+// Digest 根据选项得到最终参与签名的摘要
+// PreHashed时校验长度后原样返回数据；Message时计算 Hash(prefix || data)，
+// prefix用于SM2的ZA等由算法定义的前缀。size为签名算法要求的摘要长度
+func Digest(opts Opts, defaultHash func() hash.Hash, size int, prefix, data []byte) ([]byte, error) {
+	switch o := opts.(type) {
+	case PreHashed:
+		want := o.Size
+		if want == 0 {
+			want = size
+		}
+		if len(data) != want || want != size {
+			return nil, ErrInvalidDigestSize
+		}
+		return data, nil
+	case Message:
+		newHash := o.Hash
+		if newHash == nil {
+			newHash = defaultHash
+		}
+		h := newHash()
+		if h.Size() != size {
+			return nil, ErrInvalidDigestSize
+		}
+		h.Write(prefix)
+		h.Write(data)
+		return h.Sum(nil), nil
+	}
+	return nil, ErrUnsupportedOpts
+}
@@ -0,0 +1,31 @@
+package sigopt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"testing"
+)
+
+func TestDigest(t *testing.T) {
+	digest := sha256.Sum256([]byte("msg"))
+
+	got, err := Digest(PreHashed{}, sha256.New, sha256.Size, nil, digest[:])
+	if err != nil || !bytes.Equal(got, digest[:]) {
+		t.Fatalf("PreHashed应原样返回摘要: %x, %v", got, err)
+	}
+
+	if _, err := Digest(PreHashed{}, sha256.New, sha256.Size, nil, []byte("msg")); !errors.Is(err, ErrInvalidDigestSize) {
+		t.Fatalf("未哈希的数据期望ErrInvalidDigestSize，实际: %v", err)
+	}
+
+	got, err = Digest(Message{}, sha256.New, sha256.Size, []byte("m"), []byte("sg"))
+	if err != nil || !bytes.Equal(got, digest[:]) {
+		t.Fatalf("Message应计算Hash(prefix || data): %x, %v", got, err)
+	}
+
+	if _, err := Digest(Message{Hash: sha512.New}, sha256.New, sha256.Size, nil, nil); !errors.Is(err, ErrInvalidDigestSize) {
+		t.Fatalf("摘要长度不符期望ErrInvalidDigestSize，实际: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package sm2_test
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/laenix/gsc/sm2"
+)
+
+// detReader 是仅用于示例的确定性随机源（SHA-256计数器模式），使输出可复现
+type detReader struct {
+	counter uint64
+	buf     []byte
+}
+
+func (r *detReader) Read(p []byte) (int, error) {
+	for n := 0; n < len(p); {
+		if len(r.buf) == 0 {
+			var block [8]byte
+			binary.BigEndian.PutUint64(block[:], r.counter)
+			r.counter++
+			sum := sha256.Sum256(block[:])
+			r.buf = sum[:]
+		}
+		c := copy(p[n:], r.buf)
+		r.buf = r.buf[c:]
+		n += c
+	}
+	return len(p), nil
+}
+
+func Example() {
+	s := sm2.New()
+	random := &detReader{}
+
+	privateKey, err := s.GenerateKey(random)
+	if err != nil {
+		panic(err)
+	}
+
+	// 公钥加密，私钥解密
+	ciphertext, err := s.Encrypt(&privateKey.PublicKey, []byte("Hello, SM2加密!"), random)
+	if err != nil {
+		panic(err)
+	}
+	decrypted, err := s.Decrypt(privateKey, ciphertext)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s\n", decrypted)
+
+	// 带用户标识的签名与验证
+	uid := []byte("1234567812345678")
+	message := []byte("需要签名的信息")
+	signature, err := s.SignWithId(privateKey, message, uid)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(s.VerifyWithId(&privateKey.PublicKey, message, signature, uid))
+	fmt.Println(s.VerifyWithId(&privateKey.PublicKey, []byte("错误的消息"), signature, uid))
+	// Output:
+	// Hello, SM2加密!
+	// true
+	// false
+}
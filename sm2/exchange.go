@@ -0,0 +1,216 @@
+package sm2
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/laenix/gsc/entropy"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/kdf/sm3kdf"
+	"github.com/laenix/gsc/sm3"
+	"github.com/laenix/gsc/subtle"
+)
+
+// 错误定义
+var (
+	ErrConfirmationFailed = gscerr.New(gscerr.ErrVerification, "sm2: key confirmation failed")
+	ErrInvalidKeyLength   = gscerr.New(gscerr.ErrParameter, "sm2: agreed key length must be greater than 0")
+	ErrKeyExchangeUsed    = gscerr.New(gscerr.ErrMisuse, "sm2: key exchange already used, create a new KeyExchange for each agreement")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrConfirmationFailed: "sm2: 密钥确认失败",
+		ErrInvalidKeyLength:   "sm2: 协商的密钥长度必须大于0",
+		ErrKeyExchangeUsed:    "sm2: 密钥交换已使用，每次协商须创建新的KeyExchange",
+	})
+}
+
+// KeyExchange 是GB/T 32918.3的SM2密钥交换中的一方
+// 发起方A与响应方B各自以NewKeyExchange生成临时密钥，交换EphemeralKey后调用Agree得到相同的密钥。
+// 需要密钥确认时，B将Confirmation发送给A，A用VerifyConfirmation检查后再将自己的Confirmation发给B
+type KeyExchange struct {
+	curve     *sm2Curve
+	priv      *PrivateKey
+	uid       []byte
+	initiator bool
+
+	// r为临时私钥，R为临时公钥；r在Agree中用完即清零并置为nil
+	r      *big.Int
+	rx, ry *big.Int
+
+	// 协商完成后的确认值：s2为B发送的SB（前缀0x02），s3为A发送的SA（前缀0x03）
+	s2, s3 []byte
+}
+
+// NewKeyExchange 使用静态私钥priv和用户标识uid创建密钥交换的一方，并生成临时密钥
+// initiator为true时为发起方A，否则为响应方B；uid为空时使用默认标识。
+// random为nil时使用entropy.Default。每个KeyExchange只能协商一次，再次调用Agree返回ErrKeyExchangeUsed
+func (s *SM2) NewKeyExchange(priv *PrivateKey, uid []byte, initiator bool, random io.Reader) (*KeyExchange, error) {
+	if priv == nil || priv.D == nil || priv.X == nil || priv.Y == nil {
+		return nil, ErrInvalidPrivateKey
+	}
+	if _, err := ComputeZA(&priv.PublicKey, uid); err != nil {
+		return nil, err
+	}
+	if random == nil {
+		random = entropy.Default
+	}
+
+	r, err := randFieldElement(s.curve, random)
+	if err != nil {
+		return nil, err
+	}
+	return newKeyExchange(sm2P256Curve, priv, uid, initiator, r), nil
+}
+
+// newKeyExchange 以给定曲线和临时私钥r创建密钥交换的一方
+func newKeyExchange(curve *sm2Curve, priv *PrivateKey, uid []byte, initiator bool, r *big.Int) *KeyExchange {
+	rx, ry := curve.ScalarBaseMult(r.Bytes())
+	return &KeyExchange{
+		curve:     curve,
+		priv:      priv,
+		uid:       append([]byte(nil), uid...),
+		initiator: initiator,
+		r:         r,
+		rx:        rx,
+		ry:        ry,
+	}
+}
+
+// EphemeralKey 返回需要发送给对方的临时公钥 04 || X(32) || Y(32)
+func (kx *KeyExchange) EphemeralKey() []byte {
+	return marshalPoint(kx.rx, kx.ry)
+}
+
+// Agree 使用对方的静态公钥、用户标识和临时公钥计算长度为keyLen字节的共享密钥
+// K = KDF(xU || yU || ZA || ZB, klen)，其中U = [tA](PB + [x̄2]RB)。
+// 输入有误时可以修正后重试；一旦开始计算，临时私钥即被清零，之后再调用返回ErrKeyExchangeUsed
+func (kx *KeyExchange) Agree(peer *PublicKey, peerUID, peerEphemeral []byte, keyLen int) ([]byte, error) {
+	curve := kx.curve
+	if peer == nil || peer.X == nil || peer.Y == nil || !curve.IsOnCurve(peer.X, peer.Y) {
+		return nil, ErrInvalidPublicKey
+	}
+	px, py, err := curve.unmarshalPoint(peerEphemeral)
+	if err != nil {
+		return nil, err
+	}
+	if keyLen <= 0 {
+		return nil, ErrInvalidKeyLength
+	}
+	za, err := curve.computeZA(&kx.priv.PublicKey, kx.uid)
+	if err != nil {
+		return nil, err
+	}
+	zb, err := curve.computeZA(peer, peerUID)
+	if err != nil {
+		return nil, err
+	}
+	if kx.r == nil {
+		return nil, ErrKeyExchangeUsed
+	}
+
+	// t = (d + x̄ * r) mod n，算出t后立即清除r，同一临时密钥不会用于第二次协商
+	n := curve.N
+	t := new(big.Int).Mul(reduceX(kx.rx), kx.r)
+	t.Add(t, kx.priv.D)
+	t.Mod(t, n)
+	clear(kx.r.Bits())
+	kx.r = nil
+
+	// U = [t](P + [x̄]R)，余因子h为1
+	x, y := curve.ScalarMult(px, py, reduceX(px).Bytes())
+	x, y = curve.Add(peer.X, peer.Y, x, y)
+	tb := t.Bytes()
+	ux, uy := curve.ScalarMult(x, y, tb)
+	clear(tb)
+	clear(t.Bits())
+	if ux.Sign() == 0 && uy.Sign() == 0 {
+		return nil, ErrInvalidPublicKey
+	}
+
+	// 按发起方在前排列：R1为A的临时公钥，R2为B的临时公钥
+	r1x, r1y, r2x, r2y := kx.rx, kx.ry, px, py
+	if !kx.initiator {
+		za, zb = zb, za
+		r1x, r1y, r2x, r2y = px, py, kx.rx, kx.ry
+	}
+
+	uxb := ux.FillBytes(make([]byte, PrivateKeySize))
+	uyb := uy.FillBytes(make([]byte, PrivateKeySize))
+	seed := make([]byte, 0, 2*PrivateKeySize+2*sm3.Size)
+	seed = append(append(append(append(seed, uxb...), uyb...), za...), zb...)
+	key := sm3kdf.Key(seed, keyLen)
+
+	// 确认值 S = SM3(前缀 || yU || SM3(xU || ZA || ZB || x1 || y1 || x2 || y2))
+	h := sm3.New()
+	h.Write(uxb)
+	h.Write(za)
+	h.Write(zb)
+	for _, c := range []*big.Int{r1x, r1y, r2x, r2y} {
+		h.Write(c.FillBytes(make([]byte, PrivateKeySize)))
+	}
+	inner := h.Sum(nil)
+	confirm := func(prefix byte) []byte {
+		h := sm3.New()
+		h.Write([]byte{prefix})
+		h.Write(uyb)
+		h.Write(inner)
+		return h.Sum(nil)
+	}
+	kx.s2, kx.s3 = confirm(0x02), confirm(0x03)
+	return key, nil
+}
+
+// Confirmation 返回需要发送给对方的确认值：响应方为SB，发起方为SA，须在Agree之后调用
+func (kx *KeyExchange) Confirmation() []byte {
+	if kx.initiator {
+		return append([]byte(nil), kx.s3...)
+	}
+	return append([]byte(nil), kx.s2...)
+}
+
+// VerifyConfirmation 检查对方发送的确认值，不匹配时返回ErrConfirmationFailed
+func (kx *KeyExchange) VerifyConfirmation(peer []byte) error {
+	want := kx.s3
+	if kx.initiator {
+		want = kx.s2
+	}
+	if want == nil || subtle.ConstantTimeCompare(want, peer) != 1 {
+		return ErrConfirmationFailed
+	}
+	return nil
+}
+
+// reduceX 计算 x̄ = 2^w + (x & (2^w - 1))，其中w = ceil(ceil(log2(n)) / 2) - 1 = 127
+func reduceX(x *big.Int) *big.Int {
+	const w = 127
+	mask := new(big.Int).Lsh(big.NewInt(1), w)
+	r := new(big.Int).Sub(mask, big.NewInt(1))
+	r.And(r, x)
+	return r.Add(r, mask)
+}
+
+// marshalPoint 将点编码为定长的未压缩格式
+func marshalPoint(x, y *big.Int) []byte {
+	out := make([]byte, PublicKeySize)
+	out[0] = 0x04
+	x.FillBytes(out[1 : 1+PrivateKeySize])
+	y.FillBytes(out[1+PrivateKeySize:])
+	return out
+}
+
+// unmarshalPoint 解析定长的未压缩格式，并验证点是否在曲线上
+func (curve *sm2Curve) unmarshalPoint(data []byte) (*big.Int, *big.Int, error) {
+	if len(data) != PublicKeySize || data[0] != 0x04 {
+		return nil, nil, ErrInvalidPublicKey
+	}
+	x := new(big.Int).SetBytes(data[1 : 1+PrivateKeySize])
+	y := new(big.Int).SetBytes(data[1+PrivateKeySize:])
+	if !curve.IsOnCurve(x, y) {
+		return nil, nil, ErrInvalidPublicKey
+	}
+	return x, y, nil
+}
@@ -0,0 +1,204 @@
+package sm2
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+// exchange 在A、B之间完成一次密钥交换，返回双方的KeyExchange和密钥
+func exchange(t *testing.T, a, b *PrivateKey, uidA, uidB []byte) (*KeyExchange, *KeyExchange, []byte, []byte) {
+	t.Helper()
+	s := New()
+	kxA, err := s.NewKeyExchange(a, uidA, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kxB, err := s.NewKeyExchange(b, uidB, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyB, err := kxB.Agree(&a.PublicKey, uidA, kxA.EphemeralKey(), 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyA, err := kxA.Agree(&b.PublicKey, uidB, kxB.EphemeralKey(), 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kxA, kxB, keyA, keyB
+}
+
+func TestKeyExchange(t *testing.T) {
+	s := New()
+	a, _ := s.GenerateKey(nil)
+	b, _ := s.GenerateKey(nil)
+	uidA := []byte("ALICE123@YAHOO.COM")
+	uidB := []byte("BILL456@YAHOO.COM")
+
+	kxA, kxB, keyA, keyB := exchange(t, a, b, uidA, uidB)
+	if !bytes.Equal(keyA, keyB) {
+		t.Fatal("双方协商的密钥不一致")
+	}
+
+	// B发送SB，A验证后发送SA
+	if err := kxA.VerifyConfirmation(kxB.Confirmation()); err != nil {
+		t.Fatalf("A验证SB失败: %v", err)
+	}
+	if err := kxB.VerifyConfirmation(kxA.Confirmation()); err != nil {
+		t.Fatalf("B验证SA失败: %v", err)
+	}
+	if err := kxB.VerifyConfirmation(kxB.Confirmation()); err != ErrConfirmationFailed {
+		t.Fatal("SB不应被当作SA接受")
+	}
+
+	// 每次交换使用新的临时密钥，密钥不同
+	_, _, again, _ := exchange(t, a, b, uidA, uidB)
+	if bytes.Equal(keyA, again) {
+		t.Fatal("两次交换不应得到相同的密钥")
+	}
+}
+
+// 测试用户标识不一致时双方得到不同的密钥，且密钥确认失败
+func TestKeyExchangeUIDMismatch(t *testing.T) {
+	s := New()
+	a, _ := s.GenerateKey(nil)
+	b, _ := s.GenerateKey(nil)
+
+	kxA, err := s.NewKeyExchange(a, []byte("alice"), true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kxB, _ := s.NewKeyExchange(b, []byte("bob"), false, nil)
+	keyB, _ := kxB.Agree(&a.PublicKey, []byte("mallory"), kxA.EphemeralKey(), 16)
+	keyA, _ := kxA.Agree(&b.PublicKey, []byte("bob"), kxB.EphemeralKey(), 16)
+	if bytes.Equal(keyA, keyB) {
+		t.Fatal("用户标识不一致时密钥不应相同")
+	}
+	if err := kxA.VerifyConfirmation(kxB.Confirmation()); err != ErrConfirmationFailed {
+		t.Fatalf("应返回ErrConfirmationFailed，实际: %v", err)
+	}
+}
+
+func TestKeyExchangeInvalidInput(t *testing.T) {
+	s := New()
+	a, _ := s.GenerateKey(nil)
+	b, _ := s.GenerateKey(nil)
+	kx, _ := s.NewKeyExchange(a, nil, true, nil)
+
+	bad := kx.EphemeralKey()
+	bad[PublicKeySize-1] ^= 1
+	if _, err := kx.Agree(&b.PublicKey, nil, bad, 16); err != ErrInvalidPublicKey {
+		t.Errorf("不在曲线上的临时公钥应返回ErrInvalidPublicKey，实际: %v", err)
+	}
+	if _, err := kx.Agree(&b.PublicKey, nil, kx.EphemeralKey(), 0); err != ErrInvalidKeyLength {
+		t.Errorf("密钥长度为0应返回ErrInvalidKeyLength，实际: %v", err)
+	}
+	if kx.VerifyConfirmation(nil) != ErrConfirmationFailed {
+		t.Error("协商之前的确认应失败")
+	}
+	if _, err := s.NewKeyExchange(nil, nil, true, nil); err != ErrInvalidPrivateKey {
+		t.Errorf("空私钥应返回ErrInvalidPrivateKey，实际: %v", err)
+	}
+}
+
+// 测试每个KeyExchange只能协商一次，临时私钥在协商后被清除
+func TestKeyExchangeSingleUse(t *testing.T) {
+	s := New()
+	a, _ := s.GenerateKey(nil)
+	b, _ := s.GenerateKey(nil)
+
+	kxA, kxB, _, _ := exchange(t, a, b, nil, nil)
+	if kxA.r != nil || kxB.r != nil {
+		t.Fatal("协商之后临时私钥应被清除")
+	}
+	if _, err := kxA.Agree(&b.PublicKey, nil, kxB.EphemeralKey(), 16); err != ErrKeyExchangeUsed {
+		t.Fatalf("再次协商应返回ErrKeyExchangeUsed，实际: %v", err)
+	}
+	// 已完成的协商仍可进行密钥确认
+	if err := kxA.VerifyConfirmation(kxB.Confirmation()); err != nil {
+		t.Fatalf("A验证SB失败: %v", err)
+	}
+}
+
+// hexInt 将十六进制字符串解析为大整数
+func hexInt(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("sm2: invalid hex in test vector")
+	}
+	return v
+}
+
+// GB/T 32918.3附录A中Fp-256示例曲线上的密钥交换示例
+func TestKeyExchangeStandardExample(t *testing.T) {
+	curve := &sm2Curve{
+		CurveParams: &elliptic.CurveParams{
+			Name:    "sample Fp-256",
+			BitSize: 256,
+			P:       hexInt("8542D69E4C044F18E8B92435BF6FF7DE457283915C45517D722EDB8B08F1DFC3"),
+			N:       hexInt("8542D69E4C044F18E8B92435BF6FF7DD297720630485628D5AE74EE7C32E79B7"),
+			B:       hexInt("63E4C6D3B23B0C849CF84241484BFE48F61D59A5B16BA06E6E12D1DA27C5249A"),
+			Gx:      hexInt("421DEBD61B62EAB6746434EBC3CC315E32220B3BADD50BDC4C4E6C147FEDD43D"),
+			Gy:      hexInt("0680512BCBB42C07D47349D2153B70C4E5D7FDFCBFA36EA1A85841B9E46E09A2"),
+		},
+		A: hexInt("787968B4FA32C3FD2417842E73BBFEFF2F3C848B6831D7E0EC65228B3937E498"),
+	}
+	key := func(d string) *PrivateKey {
+		priv := &PrivateKey{D: hexInt(d)}
+		priv.X, priv.Y = curve.ScalarBaseMult(priv.D.Bytes())
+		return priv
+	}
+	a := key("6FCBA2EF9AE0AB902BC3BDE3FF915D44BA4CC78F88E2F8E7F8996D3B8CCEEDEE")
+	b := key("5E35D7D3F3C54DBAC72E61819E730B019A84208CA3A35E4C2E353DFCCB2A3B53")
+	uidA := []byte("ALICE123@YAHOO.COM")
+	uidB := []byte("BILL456@YAHOO.COM")
+
+	if got := hex.EncodeToString(marshalPoint(a.X, a.Y)); got != "04"+
+		"3099093bf3c137d8fcbbcdf4a2ae50f3b0f216c3122d79425fe03a45dbfe1655"+
+		"3df79e8dac1cf0ecbaa2f2b49d51a4b387f2efaf482339086a27a8e05baed98b" {
+		t.Fatalf("PA错误: %s", got)
+	}
+	za, _ := curve.computeZA(&a.PublicKey, uidA)
+	if got := hex.EncodeToString(za); got != "e4d1d0c3ca4c7f11bc8ff8cb3f4c02a78f108fa098e51a668487240f75e20f31" {
+		t.Fatalf("ZA错误: %s", got)
+	}
+	zb, _ := curve.computeZA(&b.PublicKey, uidB)
+	if got := hex.EncodeToString(zb); got != "6b4b6d0e276691bd4a11bf72f4fb501ae309fdacb72fa6cc336e6656119abd67" {
+		t.Fatalf("ZB错误: %s", got)
+	}
+
+	kxA := newKeyExchange(curve, a, uidA, true, hexInt("83A2C9C8B96E5AF70BD480B472409A9A327257F1EBB73F5B073354B248668563"))
+	kxB := newKeyExchange(curve, b, uidB, false, hexInt("33FE21940342161C55619C4A0C060293D543C80AF19748CE176D83477DE71C80"))
+	if got := hex.EncodeToString(kxA.EphemeralKey()); got != "04"+
+		"6cb5633816f4dd560b1dec458310cbcc6856c09505324a6d23150c408f162bf0"+
+		"0d6fcf62f1036c0a1b6daccf57399223a65f7d7bf2d9637e5bbbeb857961bf1a" {
+		t.Fatalf("RA错误: %s", got)
+	}
+	if got := hex.EncodeToString(kxB.EphemeralKey()); got != "04"+
+		"1799b2a2c778295300d9a2325c686129b8f2b5337b3dcf4514e8bbc19d900ee5"+
+		"54c9288c82733efdf7808ae7f27d0e732f7c73a7d9ac98b7d8740a91d0db3cf4" {
+		t.Fatalf("RB错误: %s", got)
+	}
+
+	keyB, err := kxB.Agree(&a.PublicKey, uidA, kxA.EphemeralKey(), 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyA, err := kxA.Agree(&b.PublicKey, uidB, kxB.EphemeralKey(), 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const wantKey = "55b0ac62a6b927ba23703832c853ded4"
+	if hex.EncodeToString(keyA) != wantKey || hex.EncodeToString(keyB) != wantKey {
+		t.Fatalf("共享密钥错误: A=%x B=%x", keyA, keyB)
+	}
+	if got := hex.EncodeToString(kxB.Confirmation()); got != "284c8f198f141b502e81250f1581c7e9eeb4ca6990f9e02df388b45471f5bc5c" {
+		t.Fatalf("SB错误: %s", got)
+	}
+	if got := hex.EncodeToString(kxA.Confirmation()); got != "23444daf8ed7534366cb901c84b3bdbb63504f4065c1116c91a4c00697e6cf7a" {
+		t.Fatalf("SA错误: %s", got)
+	}
+}
@@ -30,7 +30,7 @@ var SM2P256V1 = struct {
 		0xDD, 0xBC, 0xBD, 0x41, 0x4D, 0x94, 0x0E, 0x93,
 	},
 	N: []byte{
-		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0xFF, 0xFF, 0xFF, 0xFE, 0xFF, 0xFF, 0xFF, 0xFF,
 		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
 		0x72, 0x03, 0xDF, 0x6B, 0x21, 0xC6, 0x05, 0x2B,
 		0x53, 0xBB, 0xF4, 0x09, 0x39, 0xD5, 0x41, 0x23,
@@ -0,0 +1,221 @@
+package sm2
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+// KeyFormatVersion 是密钥序列化格式的版本号
+// JSON与二进制编码都会写入版本号，解码时拒绝未知版本
+const KeyFormatVersion = 1
+
+// 曲线名称，写入JSON编码以便在引入其他曲线后区分
+const curveName = "SM2P256V1"
+
+// 二进制编码长度：版本(1) + 标量或点
+const (
+	binaryPublicKeySize  = 1 + PublicKeySize
+	binaryPrivateKeySize = 1 + PrivateKeySize
+)
+
+// 错误定义
+var (
+	ErrUnsupportedKeyVersion = gscerr.New(gscerr.ErrUnsupported, "sm2: unsupported key format version")
+	ErrInvalidKeyEncoding    = gscerr.New(gscerr.ErrMalformed, "sm2: invalid key encoding")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrUnsupportedKeyVersion: "sm2: 不支持的密钥格式版本",
+		ErrInvalidKeyEncoding:    "sm2: 密钥编码无效",
+	})
+}
+
+// jsonKey 是公私钥的JSON结构，坐标和私钥均为定长（32字节）十六进制
+type jsonKey struct {
+	Version int    `json:"version"`
+	Curve   string `json:"curve"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+	D       string `json:"d,omitempty"`
+}
+
+// MarshalJSON 将公钥编码为 {"version":1,"curve":"SM2P256V1","x":"...","y":"..."}
+func (pub *PublicKey) MarshalJSON() ([]byte, error) {
+	if pub.X == nil || pub.Y == nil {
+		return nil, ErrInvalidPublicKey
+	}
+	return json.Marshal(jsonKey{
+		Version: KeyFormatVersion,
+		Curve:   curveName,
+		X:       hex.EncodeToString(fixedBytes(pub.X)),
+		Y:       hex.EncodeToString(fixedBytes(pub.Y)),
+	})
+}
+
+// UnmarshalJSON 解码MarshalJSON的输出，并验证版本、曲线以及点是否在曲线上
+func (pub *PublicKey) UnmarshalJSON(data []byte) error {
+	var k jsonKey
+	if err := json.Unmarshal(data, &k); err != nil {
+		return err
+	}
+	if err := k.check(); err != nil {
+		return err
+	}
+	decoded, err := k.publicKey()
+	if err != nil {
+		return err
+	}
+	*pub = *decoded
+	return nil
+}
+
+// MarshalBinary 将公钥编码为 版本(1) || 04 || X(32) || Y(32)，同时用于encoding/gob
+func (pub *PublicKey) MarshalBinary() ([]byte, error) {
+	if pub.X == nil || pub.Y == nil {
+		return nil, ErrInvalidPublicKey
+	}
+	out := make([]byte, 0, binaryPublicKeySize)
+	out = append(out, KeyFormatVersion, 0x04)
+	out = append(out, fixedBytes(pub.X)...)
+	return append(out, fixedBytes(pub.Y)...), nil
+}
+
+// UnmarshalBinary 解码MarshalBinary的输出，并验证点是否在曲线上
+func (pub *PublicKey) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return ErrInvalidKeyEncoding
+	}
+	if data[0] != KeyFormatVersion {
+		return ErrUnsupportedKeyVersion
+	}
+	if len(data) != binaryPublicKeySize {
+		return ErrInvalidKeyEncoding
+	}
+	decoded, err := New().DecodePublicKey(data[1:])
+	if err != nil {
+		return err
+	}
+	*pub = *decoded
+	return nil
+}
+
+// MarshalJSON 将私钥编码为包含公钥坐标和私钥d的JSON对象
+func (priv *PrivateKey) MarshalJSON() ([]byte, error) {
+	if priv.D == nil || priv.X == nil || priv.Y == nil {
+		return nil, ErrInvalidPrivateKey
+	}
+	return json.Marshal(jsonKey{
+		Version: KeyFormatVersion,
+		Curve:   curveName,
+		X:       hex.EncodeToString(fixedBytes(priv.X)),
+		Y:       hex.EncodeToString(fixedBytes(priv.Y)),
+		D:       hex.EncodeToString(fixedBytes(priv.D)),
+	})
+}
+
+// UnmarshalJSON 解码私钥，验证d的取值范围，并检查公钥坐标与d一致
+func (priv *PrivateKey) UnmarshalJSON(data []byte) error {
+	var k jsonKey
+	if err := json.Unmarshal(data, &k); err != nil {
+		return err
+	}
+	if err := k.check(); err != nil {
+		return err
+	}
+
+	d, err := decodeScalar(k.D)
+	if err != nil {
+		return err
+	}
+	decoded, err := New().DecodePrivateKey(d)
+	if err != nil {
+		return err
+	}
+	pub, err := k.publicKey()
+	if err != nil {
+		return err
+	}
+	if pub.X.Cmp(decoded.X) != 0 || pub.Y.Cmp(decoded.Y) != 0 {
+		return ErrInvalidPrivateKey
+	}
+	*priv = *decoded
+	return nil
+}
+
+// MarshalBinary 将私钥编码为 版本(1) || d(32)，公钥在解码时重新计算
+func (priv *PrivateKey) MarshalBinary() ([]byte, error) {
+	if priv.D == nil {
+		return nil, ErrInvalidPrivateKey
+	}
+	out := make([]byte, 0, binaryPrivateKeySize)
+	out = append(out, KeyFormatVersion)
+	return append(out, fixedBytes(priv.D)...), nil
+}
+
+// UnmarshalBinary 解码MarshalBinary的输出，并验证d的取值范围
+func (priv *PrivateKey) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return ErrInvalidKeyEncoding
+	}
+	if data[0] != KeyFormatVersion {
+		return ErrUnsupportedKeyVersion
+	}
+	if len(data) != binaryPrivateKeySize {
+		return ErrInvalidKeyEncoding
+	}
+	decoded, err := New().DecodePrivateKey(data[1:])
+	if err != nil {
+		return err
+	}
+	*priv = *decoded
+	return nil
+}
+
+// check 验证JSON中的版本和曲线
+func (k *jsonKey) check() error {
+	if k.Version != KeyFormatVersion {
+		return ErrUnsupportedKeyVersion
+	}
+	if k.Curve != curveName {
+		return ErrInvalidKeyEncoding
+	}
+	return nil
+}
+
+// publicKey 解析JSON中的公钥坐标并验证点是否在曲线上
+func (k *jsonKey) publicKey() (*PublicKey, error) {
+	x, err := decodeScalar(k.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := decodeScalar(k.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	point := make([]byte, 0, PublicKeySize)
+	point = append(point, 0x04)
+	point = append(point, x...)
+	point = append(point, y...)
+	return New().DecodePublicKey(point)
+}
+
+// decodeScalar 解码32字节的十六进制字符串
+func decodeScalar(s string) ([]byte, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != PrivateKeySize {
+		return nil, ErrInvalidKeyEncoding
+	}
+	return b, nil
+}
+
+// fixedBytes 将大整数编码为32字节大端序
+func fixedBytes(v *big.Int) []byte {
+	return v.FillBytes(make([]byte, PrivateKeySize))
+}
@@ -0,0 +1,114 @@
+package sm2
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestKeyJSON(t *testing.T) {
+	priv := leadingZeroKey(t)
+
+	data, err := json.Marshal(&priv.PublicKey)
+	if err != nil {
+		t.Fatalf("公钥编码失败: %v", err)
+	}
+	// 首字节为0的坐标也应编码为64个十六进制字符
+	want := `{"version":1,"curve":"SM2P256V1","x":"00d062045840b1f4b0a64d6e6c5bc582079fc0af8c366eba632b35f5e217385b","y":"5032f04533c064a41a7616cbb528b168c79a247d46f1c3667e1a2f5921aca9a4"}`
+	if string(data) != want {
+		t.Fatalf("公钥JSON不匹配:\n期望值: %s\n实际值: %s", want, data)
+	}
+
+	var pub PublicKey
+	if err := json.Unmarshal(data, &pub); err != nil {
+		t.Fatalf("公钥解码失败: %v", err)
+	}
+	if pub.X.Cmp(priv.X) != 0 || pub.Y.Cmp(priv.Y) != 0 {
+		t.Fatal("公钥解码结果不一致")
+	}
+
+	data, err = json.Marshal(priv)
+	if err != nil {
+		t.Fatalf("私钥编码失败: %v", err)
+	}
+	var decoded PrivateKey
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("私钥解码失败: %v", err)
+	}
+	if decoded.D.Cmp(priv.D) != 0 || decoded.X.Cmp(priv.X) != 0 {
+		t.Fatal("私钥解码结果不一致")
+	}
+
+	tests := []struct {
+		name string
+		data string
+		want error
+	}{
+		{"未知版本", strings.Replace(want, `"version":1`, `"version":2`, 1), ErrUnsupportedKeyVersion},
+		{"未知曲线", strings.Replace(want, "SM2P256V1", "P-256", 1), ErrInvalidKeyEncoding},
+		{"坐标过短", strings.Replace(want, `"x":"00`, `"x":"`, 1), ErrInvalidKeyEncoding},
+		{"点不在曲线上", strings.Replace(want, `a9a4"`, `a9a5"`, 1), ErrInvalidPublicKey},
+	}
+	for _, tt := range tests {
+		if err := json.Unmarshal([]byte(tt.data), &pub); err != tt.want {
+			t.Errorf("%s: 期望%v，实际: %v", tt.name, tt.want, err)
+		}
+	}
+
+	// 公钥坐标与d不一致的私钥
+	other, err := New().GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mismatched := *other
+	mismatched.D = priv.D
+	data, _ = json.Marshal(&mismatched)
+	if err := json.Unmarshal(data, &decoded); err != ErrInvalidPrivateKey {
+		t.Errorf("公钥与私钥不一致时期望ErrInvalidPrivateKey，实际: %v", err)
+	}
+}
+
+func TestKeyBinaryAndGob(t *testing.T) {
+	priv := leadingZeroKey(t)
+
+	data, err := priv.PublicKey.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 66 || data[0] != KeyFormatVersion || data[1] != 0x04 || data[2] != 0x00 {
+		t.Fatalf("公钥二进制编码不正确: %x", data)
+	}
+	var pub PublicKey
+	if err := pub.UnmarshalBinary(data); err != nil || pub.X.Cmp(priv.X) != 0 {
+		t.Fatalf("公钥二进制解码失败: %v", err)
+	}
+
+	data[0] = 2
+	if err := pub.UnmarshalBinary(data); err != ErrUnsupportedKeyVersion {
+		t.Fatalf("期望ErrUnsupportedKeyVersion，实际: %v", err)
+	}
+
+	var decoded PrivateKey
+	if err := decoded.UnmarshalBinary(append([]byte{KeyFormatVersion}, make([]byte, 32)...)); err != ErrInvalidPrivateKey {
+		t.Fatalf("d为0时期望ErrInvalidPrivateKey，实际: %v", err)
+	}
+
+	// encoding/gob通过BinaryMarshaler编码
+	type record struct {
+		Name string
+		Key  *PrivateKey
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(record{"alice", priv}); err != nil {
+		t.Fatalf("gob编码失败: %v", err)
+	}
+	var r record
+	if err := gob.NewDecoder(&buf).Decode(&r); err != nil {
+		t.Fatalf("gob解码失败: %v", err)
+	}
+	if r.Name != "alice" || r.Key.D.Cmp(priv.D) != 0 || r.Key.X.Cmp(priv.X) != 0 || r.Key.Y.Cmp(priv.Y) != 0 {
+		t.Fatal("gob解码结果不一致")
+	}
+}
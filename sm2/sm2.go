@@ -3,24 +3,45 @@ package sm2
 import (
 	"crypto/elliptic"
 	"crypto/rand"
-	"errors"
 	"io"
 	"math/big"
 
+	"github.com/laenix/gsc/entropy"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/hashutil"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/internal/rfc6979"
+	"github.com/laenix/gsc/kdf/sm3kdf"
+	"github.com/laenix/gsc/sigopt"
 	"github.com/laenix/gsc/sm2/internal"
 	"github.com/laenix/gsc/sm3"
+	"github.com/laenix/gsc/subtle"
 )
 
 // 错误定义
 var (
-	ErrInvalidPrivateKey  = errors.New("sm2: 无效的私钥")
-	ErrInvalidPublicKey   = errors.New("sm2: 无效的公钥")
-	ErrInvalidSignature   = errors.New("sm2: 无效的签名")
-	ErrInvalidCiphertext  = errors.New("sm2: 无效的密文")
-	ErrDecryptionFailed   = errors.New("sm2: 解密失败")
-	ErrVerificationFailed = errors.New("sm2: 验证失败")
+	ErrInvalidPrivateKey  = gscerr.New(gscerr.ErrMalformed, "sm2: invalid private key")
+	ErrInvalidPublicKey   = gscerr.New(gscerr.ErrMalformed, "sm2: invalid public key")
+	ErrInvalidSignature   = gscerr.New(gscerr.ErrMalformed, "sm2: invalid signature")
+	ErrInvalidCiphertext  = gscerr.New(gscerr.ErrMalformed, "sm2: invalid ciphertext")
+	ErrDecryptionFailed   = gscerr.New(gscerr.ErrAuthFailed, "sm2: decryption failed")
+	ErrVerificationFailed = gscerr.New(gscerr.ErrVerification, "sm2: verification failed")
+	ErrInvalidUID         = gscerr.New(gscerr.ErrParameter, "sm2: user ID too long")
 )
 
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidPrivateKey:  "sm2: 无效的私钥",
+		ErrInvalidPublicKey:   "sm2: 无效的公钥",
+		ErrInvalidSignature:   "sm2: 无效的签名",
+		ErrInvalidCiphertext:  "sm2: 无效的密文",
+		ErrDecryptionFailed:   "sm2: 解密失败",
+		ErrVerificationFailed: "sm2: 验证失败",
+		ErrInvalidUID:         "sm2: 用户标识过长",
+	})
+}
+
 // 密钥大小（字节）
 const (
 	// SM2使用256位曲线，私钥为32字节
@@ -31,9 +52,10 @@ const (
 	SignatureSize = 64
 )
 
-// SM2曲线实现
+// SM2曲线实现，a不在elliptic.CurveParams中，单独保存
 type sm2Curve struct {
 	*elliptic.CurveParams
+	A *big.Int
 }
 
 // 实现SM2曲线，对应国家密码局规定的SM2椭圆曲线参数
@@ -52,15 +74,7 @@ func init() {
 	sm2P256Curve.B = new(big.Int).SetBytes(internal.SM2P256V1.B)
 	sm2P256Curve.Gx = new(big.Int).SetBytes(internal.SM2P256V1.X)
 	sm2P256Curve.Gy = new(big.Int).SetBytes(internal.SM2P256V1.Y)
-	// A参数不在CurveParams结构体中，需要单独存储
-}
-
-// A 存储SM2曲线的A参数值
-var sm2A = new(big.Int)
-
-// 初始化A参数
-func init() {
-	sm2A = new(big.Int).SetBytes(internal.SM2P256V1.A)
+	sm2P256Curve.A = new(big.Int).SetBytes(internal.SM2P256V1.A)
 }
 
 // 检查点是否在曲线上
@@ -77,7 +91,7 @@ func (curve *sm2Curve) IsOnCurve(x, y *big.Int) bool {
 
 	// 计算等式右边: x³ + ax + b
 	x3 := new(big.Int).Exp(x, big.NewInt(3), curve.P)
-	ax := new(big.Int).Mul(sm2A, x)
+	ax := new(big.Int).Mul(curve.A, x)
 	ax.Mod(ax, curve.P)
 
 	right := new(big.Int).Add(x3, ax)
@@ -144,7 +158,7 @@ func (curve *sm2Curve) Double(x, y *big.Int) (*big.Int, *big.Int) {
 	// 计算λ = (3x² + a) / (2y) mod p
 	numerator := new(big.Int).Exp(x, big.NewInt(2), curve.P)
 	numerator.Mul(numerator, big.NewInt(3))
-	numerator.Add(numerator, sm2A)
+	numerator.Add(numerator, curve.A)
 	numerator.Mod(numerator, curve.P)
 
 	denominator := new(big.Int).Lsh(y, 1) // 2y
@@ -217,6 +231,12 @@ type PublicKey struct {
 // SM2 封装SM2算法功能
 type SM2 struct {
 	curve elliptic.Curve // 使用的椭圆曲线
+	// requireHealthyEntropy 为true时，密钥生成只接受通过健康测试的熵源
+	requireHealthyEntropy bool
+	// legacyEmptyPlaintext 为true时沿用旧版本的空明文表示（单个0x00字节）
+	legacyEmptyPlaintext bool
+	// deterministicNonce 为true时签名随机数k按RFC 6979由私钥和摘要导出
+	deterministicNonce bool
 }
 
 // New 创建一个新的SM2实例
@@ -227,6 +247,31 @@ func New() *SM2 {
 	}
 }
 
+// WithHealthyEntropy 设置密钥生成是否必须使用通过健康测试的熵源
+// 开启后GenerateKey只接受实现entropy.HealthChecker且状态健康的random，
+// 否则返回entropy.ErrUnhealthy
+func (s *SM2) WithHealthyEntropy(enabled bool) *SM2 {
+	s.requireHealthyEntropy = enabled
+	return s
+}
+
+// WithLegacyEmptyPlaintext 设置是否兼容旧版本对空明文的处理
+// 旧版本将空明文替换为单个0x00字节加密，并把解密出的单个0x00字节还原为空明文，
+// 导致合法的单字节0x00消息无法正确往返。当前版本按GB/T 32918允许C2为空；
+// 仅在需要解开旧版本生成的密文或与旧版本互通时开启
+func (s *SM2) WithLegacyEmptyPlaintext(enabled bool) *SM2 {
+	s.legacyEmptyPlaintext = enabled
+	return s
+}
+
+// WithDeterministicNonce 设置签名是否使用确定性随机数
+// 开启后Sign和SignWithOpts按RFC 6979以HMAC-SM3由私钥和摘要导出k，不再读取随机数发生器，
+// 同一私钥对同一摘要总是得到相同的签名；签名仍符合GB/T 32918，验证方无需任何改动
+func (s *SM2) WithDeterministicNonce(enabled bool) *SM2 {
+	s.deterministicNonce = enabled
+	return s
+}
+
 // P256 返回SM2推荐曲线参数
 func P256() elliptic.Curve {
 	// 返回真正的SM2曲线参数
@@ -234,15 +279,29 @@ func P256() elliptic.Curve {
 }
 
 // GenerateKey 生成SM2密钥对
+// random为nil时使用带连续健康测试的entropy.Default
 func (s *SM2) GenerateKey(random io.Reader) (*PrivateKey, error) {
-	if random == nil {
-		random = rand.Reader
+	if s.requireHealthyEntropy {
+		var err error
+		if random, err = entropy.Require(random); err != nil {
+			return nil, err
+		}
+	} else if random == nil {
+		random = entropy.Default
 	}
 
-	// 生成私钥
-	k, x, y, err := elliptic.GenerateKey(s.curve, random)
-	if err != nil {
-		return nil, err
+	// 生成私钥，d = n-1时重新生成
+	var k []byte
+	var x, y *big.Int
+	for {
+		var err error
+		k, x, y, err = elliptic.GenerateKey(s.curve, random)
+		if err != nil {
+			return nil, err
+		}
+		if validPrivateScalar(new(big.Int).SetBytes(k), s.curve.Params().N) {
+			break
+		}
 	}
 
 	priv := &PrivateKey{
@@ -266,49 +325,36 @@ func (s *SM2) Encrypt(pub *PublicKey, plaintext []byte, random io.Reader) ([]byt
 		random = rand.Reader
 	}
 
-	// 处理空明文的特殊情况
-	if len(plaintext) == 0 {
-		plaintext = []byte{0} // 使用一个字节表示空明文
-	}
-
-	// 1. 生成临时密钥对
-	k, err := randFieldElement(s.curve, random)
-	if err != nil {
-		return nil, err
+	// 兼容模式下沿用旧版本的空明文表示
+	if len(plaintext) == 0 && s.legacyEmptyPlaintext {
+		plaintext = []byte{0}
 	}
 
-	// 计算kG点
-	x1, y1 := s.curve.ScalarBaseMult(k.Bytes())
+	byteLen := (s.curve.Params().BitSize + 7) / 8
 
-	// 2. 计算共享密钥点(x2, y2) = k * PB
-	x2, y2 := s.curve.ScalarMult(pub.X, pub.Y, k.Bytes())
+	var x1, y1 *big.Int
+	var x2Bytes, y2Bytes, kdf []byte
+	for {
+		// 1. 生成临时密钥对
+		k, err := randFieldElement(s.curve, random)
+		if err != nil {
+			return nil, err
+		}
 
-	// 3. 计算t = KDF(x2 || y2, klen)
-	byteLen := (s.curve.Params().BitSize + 7) / 8
-	x2Bytes := x2.FillBytes(make([]byte, byteLen))
-	y2Bytes := y2.FillBytes(make([]byte, byteLen))
+		// 计算kG点
+		x1, y1 = s.curve.ScalarBaseMult(k.Bytes())
 
-	// 使用SM3哈希算法作为KDF的基础
-	kdf := make([]byte, len(plaintext))
-	hash := sm3.New()
+		// 2. 计算共享密钥点(x2, y2) = k * PB
+		x2, y2 := s.curve.ScalarMult(pub.X, pub.Y, k.Bytes())
 
-	// 简化版KDF：此处应实现完整的KDF算法
-	hash.Write(x2Bytes)
-	hash.Write(y2Bytes)
-	tmp := hash.Sum(nil)
+		// 3. 计算t = KDF(x2 || y2, klen)
+		x2Bytes = x2.FillBytes(make([]byte, byteLen))
+		y2Bytes = y2.FillBytes(make([]byte, byteLen))
+		kdf = sm3kdf.Key(append(append([]byte{}, x2Bytes...), y2Bytes...), len(plaintext))
 
-	// 如果明文长度大于哈希输出长度，需要多次哈希
-	for i := 0; i < len(plaintext); i++ {
-		if i < len(tmp) {
-			kdf[i] = tmp[i]
-		} else {
-			// 简单示例，实际上应该使用计数器模式KDF
-			hash.Reset()
-			hash.Write(x2Bytes)
-			hash.Write(y2Bytes)
-			hash.Write([]byte{byte(i / len(tmp))})
-			tmp = hash.Sum(nil)
-			kdf[i] = tmp[i%len(tmp)]
+		// 若t为全0比特串，需要重新选择k；空明文时t为空串，无需检查
+		if len(plaintext) == 0 || !allZero(kdf) {
+			break
 		}
 	}
 
@@ -319,7 +365,7 @@ func (s *SM2) Encrypt(pub *PublicKey, plaintext []byte, random io.Reader) ([]byt
 	}
 
 	// 5. 计算C3 = SM3(x2 || M || y2)
-	hash.Reset()
+	hash := sm3.New()
 	hash.Write(x2Bytes)
 	hash.Write(plaintext)
 	hash.Write(y2Bytes)
@@ -357,7 +403,7 @@ func (s *SM2) Decrypt(priv *PrivateKey, ciphertext []byte) ([]byte, error) {
 
 	// 解析密文
 	if ciphertext[0] != 0x04 {
-		return nil, errors.New("sm2: 不支持的点压缩格式")
+		return nil, gscerr.New(gscerr.ErrMalformed, "sm2: unsupported point compression format")
 	}
 
 	// 解析C1(x1, y1)
@@ -366,7 +412,7 @@ func (s *SM2) Decrypt(priv *PrivateKey, ciphertext []byte) ([]byte, error) {
 
 	// 验证C1是否在曲线上
 	if !s.curve.IsOnCurve(x1, y1) {
-		return nil, errors.New("sm2: C1点不在曲线上")
+		return nil, gscerr.New(gscerr.ErrMalformed, "sm2: C1 is not on the curve")
 	}
 
 	// 计算共享密钥点 (x2, y2) = d * C1
@@ -375,34 +421,17 @@ func (s *SM2) Decrypt(priv *PrivateKey, ciphertext []byte) ([]byte, error) {
 	c3Len := 32 // SM3哈希输出32字节
 	c2Len := len(ciphertext) - (1 + 2*byteLen + c3Len)
 
-	if c2Len <= 0 {
+	if c2Len < 0 {
 		return nil, ErrInvalidCiphertext
 	}
 
 	x2Bytes := x2.FillBytes(make([]byte, byteLen))
 	y2Bytes := y2.FillBytes(make([]byte, byteLen))
 
-	// 使用KDF计算t
-	kdf := make([]byte, c2Len)
-	hash := sm3.New()
-
-	// 简化版KDF：同样应该实现完整的KDF算法
-	hash.Write(x2Bytes)
-	hash.Write(y2Bytes)
-	tmp := hash.Sum(nil)
-
-	for i := 0; i < c2Len; i++ {
-		if i < len(tmp) {
-			kdf[i] = tmp[i]
-		} else {
-			// 简单示例，实际上应该使用计数器模式KDF
-			hash.Reset()
-			hash.Write(x2Bytes)
-			hash.Write(y2Bytes)
-			hash.Write([]byte{byte(i / len(tmp))})
-			tmp = hash.Sum(nil)
-			kdf[i] = tmp[i%len(tmp)]
-		}
+	// 使用KDF计算t，t为全0比特串时密文无效
+	kdf := sm3kdf.Key(append(append([]byte{}, x2Bytes...), y2Bytes...), c2Len)
+	if c2Len > 0 && allZero(kdf) {
+		return nil, ErrDecryptionFailed
 	}
 
 	// 解密C2得到M: M = C2 ⊕ t
@@ -413,57 +442,70 @@ func (s *SM2) Decrypt(priv *PrivateKey, ciphertext []byte) ([]byte, error) {
 	}
 
 	// 计算C3' = SM3(x2 || M || y2)
-	hash.Reset()
+	hash := sm3.New()
 	hash.Write(x2Bytes)
 	hash.Write(plaintext)
 	hash.Write(y2Bytes)
 	c3 := hash.Sum(nil)
 
-	// 验证C3' == C3
-	receivedC3 := ciphertext[1+2*byteLen+c2Len:]
-	for i := 0; i < len(c3); i++ {
-		if c3[i] != receivedC3[i] {
-			return nil, ErrDecryptionFailed
-		}
+	// 以常量时间验证C3' == C3，逐字节比较并提前返回会泄露匹配的前缀长度
+	if subtle.ConstantTimeCompare(c3, ciphertext[1+2*byteLen+c2Len:]) != 1 {
+		return nil, ErrDecryptionFailed
 	}
 
-	// 处理空明文的特殊情况
-	if len(plaintext) == 1 && plaintext[0] == 0 {
+	// 兼容模式下将旧版本的空明文表示还原为空明文
+	if s.legacyEmptyPlaintext && len(plaintext) == 1 && plaintext[0] == 0 {
 		return []byte{}, nil
 	}
 
 	return plaintext, nil
 }
 
-// Sign 使用SM2算法签名消息
+// Sign 使用SM2算法对摘要签名
+// digest必须是32字节的摘要e = SM3(ZA || M)，传入其他长度的数据返回
+// sigopt.ErrInvalidDigestSize；需要自动计算ZA和摘要时使用SignWithOpts
+// 随机数k默认从crypto/rand读取，开启WithDeterministicNonce后按RFC 6979导出
 func (s *SM2) Sign(priv *PrivateKey, digest []byte) ([]byte, error) {
 	if priv == nil || priv.D == nil {
 		return nil, ErrInvalidPrivateKey
 	}
+	if len(digest) != sm3.Size {
+		return nil, sigopt.ErrInvalidDigestSize
+	}
 
 	n := s.curve.Params().N
 	one := new(big.Int).SetInt64(1)
 
-	// 确保私钥合法
-	if priv.D.Cmp(one) < 0 || priv.D.Cmp(new(big.Int).Sub(n, one)) > 0 {
+	// 确保私钥合法，d = n-1时1+d没有逆元
+	if !validPrivateScalar(priv.D, n) {
 		return nil, ErrInvalidPrivateKey
 	}
 
+	// 确定性签名的k按RFC 6979导出，重试时依次取下一个候选值
+	var nonces *rfc6979.Generator
+	if s.deterministicNonce {
+		nonces = rfc6979.New(sm3.New, n, priv.D, digest)
+	}
+
 retry:
 	// SM2签名算法
 	e := new(big.Int).SetBytes(digest)
 
-	// 生成随机数k
+	// 生成随机数k，确定性签名的候选值已在[1, n-1]内
 	var k *big.Int
-	var err error
-	for {
-		k, err = randFieldElement(s.curve, rand.Reader)
-		if err != nil {
-			return nil, err
-		}
-		// 确保k满足条件
-		if k.Cmp(one) >= 0 && k.Cmp(new(big.Int).Sub(n, one)) <= 0 {
-			break
+	if nonces != nil {
+		k = nonces.Next()
+	} else {
+		var err error
+		for {
+			k, err = randFieldElement(s.curve, rand.Reader)
+			if err != nil {
+				return nil, err
+			}
+			// 确保k满足条件
+			if k.Cmp(one) >= 0 && k.Cmp(new(big.Int).Sub(n, one)) <= 0 {
+				break
+			}
 		}
 	}
 
@@ -520,8 +562,8 @@ func (s *SM2) Verify(pub *PublicKey, digest []byte, signature []byte) bool {
 		return false
 	}
 
-	// 验证签名长度
-	if len(signature) != SignatureSize {
+	// 验证签名和摘要长度
+	if len(signature) != SignatureSize || len(digest) != sm3.Size {
 		return false
 	}
 
@@ -561,6 +603,15 @@ func (s *SM2) Verify(pub *PublicKey, digest []byte, signature []byte) bool {
 	return R.Cmp(r) == 0
 }
 
+// allZero 判断字节串是否全为0
+func allZero(b []byte) bool {
+	var acc byte
+	for _, v := range b {
+		acc |= v
+	}
+	return acc == 0
+}
+
 // randFieldElement 返回[1, n-1]之间的随机数
 func randFieldElement(curve elliptic.Curve, random io.Reader) (*big.Int, error) {
 	n := curve.Params().N
@@ -579,80 +630,95 @@ func randFieldElement(curve elliptic.Curve, random io.Reader) (*big.Int, error)
 	}
 }
 
-// 辅助函数：计算ZA = SM3(ENTLA || IDA || a || b || Gx || Gy || Px || Py)
-func (s *SM2) getZ(pub *PublicKey, uid []byte) []byte {
+// ComputeZA 计算用户身份杂凑值 ZA = SM3(ENTLA || IDA || a || b || Gx || Gy || Px || Py)
+// uid为空时使用默认标识"1234567812345678"。所有坐标都按32字节定长编码，
+// 可直接用于TLCP等需要单独计算ZA的协议
+func ComputeZA(pub *PublicKey, uid []byte) ([]byte, error) {
+	return sm2P256Curve.computeZA(pub, uid)
+}
+
+// computeZA 按曲线curve的参数计算ZA，密钥交换的示例向量使用的不是推荐曲线
+func (curve *sm2Curve) computeZA(pub *PublicKey, uid []byte) ([]byte, error) {
+	if pub == nil || pub.X == nil || pub.Y == nil {
+		return nil, ErrInvalidPublicKey
+	}
 	if uid == nil || len(uid) == 0 {
 		uid = internal.DefaultUID
 	}
 
-	// ENTLA是关于用户标识长度的两个字节
-	entla := uint16(len(uid) * 8)
-
-	// 计算ZA = SM3(ENTLA || IDA || a || b || Gx || Gy || Px || Py)
-	h := sm3.New()
-
-	// 写入ENTLA
-	var entlaBytes [2]byte
-	entlaBytes[0] = byte(entla >> 8)
-	entlaBytes[1] = byte(entla)
-	h.Write(entlaBytes[:])
-
-	// 写入用户标识
-	h.Write(uid)
-
-	// 写入椭圆曲线参数a,b - 使用真实的SM2曲线参数
-	h.Write(internal.SM2P256V1.A)
-	h.Write(internal.SM2P256V1.B)
+	// ENTLA为用户标识按位计的长度，只有两个字节
+	if len(uid) > hashutil.MaxTagSize {
+		return nil, ErrInvalidUID
+	}
 
-	// 写入基点G的坐标
-	h.Write(internal.SM2P256V1.X)
-	h.Write(internal.SM2P256V1.Y)
+	// ENTLA || IDA 正是以用户标识为标签的域分离前缀
+	h := hashutil.Domain(string(uid), sm3.New())
 
-	// 写入公钥坐标
-	pxBytes := pub.X.Bytes()
-	pyBytes := pub.Y.Bytes()
-	h.Write(pxBytes)
-	h.Write(pyBytes)
+	// 依次写入曲线参数a、b，基点G的坐标和公钥坐标，都必须补齐到32字节，
+	// 否则首字节为0的公钥会得到错误的ZA
+	for _, v := range []*big.Int{curve.A, curve.B, curve.Gx, curve.Gy, pub.X, pub.Y} {
+		h.Write(v.FillBytes(make([]byte, PrivateKeySize)))
+	}
 
-	return h.Sum(nil)
+	return h.Sum(nil), nil
 }
 
-// SignWithId 使用SM2算法和用户标识进行数字签名
-func (s *SM2) SignWithId(priv *PrivateKey, msg []byte, uid []byte) ([]byte, error) {
+// SignWithOpts 按选项签名
+// opts为sigopt.PreHashed时data必须是32字节摘要；为sigopt.Message时data为原始消息，
+// 自动计算 e = SM3(ZA || M)，其中ZA使用opts.UID（为空时使用默认标识）
+func (s *SM2) SignWithOpts(priv *PrivateKey, data []byte, opts sigopt.Opts) ([]byte, error) {
 	if priv == nil || priv.D == nil {
 		return nil, ErrInvalidPrivateKey
 	}
 
-	// 计算ZA = SM3(ENTLA || IDA || a || b || Gx || Gy || Px || Py)
-	za := s.getZ(&priv.PublicKey, uid)
+	digest, err := s.digest(&priv.PublicKey, data, opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.Sign(priv, digest)
+}
 
-	// 计算e = SM3(ZA || M)
-	h := sm3.New()
-	h.Write(za)
-	h.Write(msg)
-	digest := h.Sum(nil)
+// VerifyWithOpts 按选项验证签名，opts的含义与SignWithOpts相同
+func (s *SM2) VerifyWithOpts(pub *PublicKey, data []byte, signature []byte, opts sigopt.Opts) bool {
+	if pub == nil || pub.X == nil || pub.Y == nil {
+		return false
+	}
 
-	// 使用e进行签名
-	return s.Sign(priv, digest)
+	digest, err := s.digest(pub, data, opts)
+	if err != nil {
+		return false
+	}
+	return s.Verify(pub, digest, signature)
+}
+
+// SignWithId 使用SM2算法和用户标识进行数字签名
+// 等价于 SignWithOpts(priv, msg, sigopt.Message{UID: uid})
+func (s *SM2) SignWithId(priv *PrivateKey, msg []byte, uid []byte) ([]byte, error) {
+	return s.SignWithOpts(priv, msg, sigopt.Message{UID: uid})
 }
 
 // VerifyWithId 使用SM2算法和用户标识验证数字签名
 func (s *SM2) VerifyWithId(pub *PublicKey, msg []byte, signature []byte, uid []byte) bool {
-	if pub == nil || pub.X == nil || pub.Y == nil {
-		return false
+	return s.VerifyWithOpts(pub, msg, signature, sigopt.Message{UID: uid})
+}
+
+// digest 根据签名选项计算待签名的摘要
+func (s *SM2) digest(pub *PublicKey, data []byte, opts sigopt.Opts) ([]byte, error) {
+	if opts == nil {
+		return nil, sigopt.ErrUnsupportedOpts
 	}
 
-	// 计算ZA = SM3(ENTLA || IDA || a || b || Gx || Gy || Px || Py)
-	za := s.getZ(pub, uid)
+	var za []byte
+	if msg, ok := opts.(sigopt.Message); ok {
+		// 计算ZA = SM3(ENTLA || IDA || a || b || Gx || Gy || Px || Py)
+		var err error
+		if za, err = ComputeZA(pub, msg.UID); err != nil {
+			return nil, err
+		}
+	}
 
 	// 计算e = SM3(ZA || M)
-	h := sm3.New()
-	h.Write(za)
-	h.Write(msg)
-	digest := h.Sum(nil)
-
-	// 使用e进行签名验证
-	return s.Verify(pub, digest, signature)
+	return sigopt.Digest(opts, sm3.New, sm3.Size, za, data)
 }
 
 // 以下是一些辅助函数
@@ -676,7 +742,13 @@ func (pub *PublicKey) EncodePublicKey() []byte {
 	return result
 }
 
-// DecodePrivateKey 从字节流解码私钥
+// validPrivateScalar 判断d是否在GB/T 32918.1规定的私钥范围[1, n-2]内
+// 签名需要计算(1+d)^-1 mod n，d = n-1时逆元不存在
+func validPrivateScalar(d, n *big.Int) bool {
+	return d.Sign() > 0 && d.Cmp(new(big.Int).Sub(n, big.NewInt(2))) <= 0
+}
+
+// DecodePrivateKey 从字节流解码私钥，d必须在[1, n-2]内
 func (s *SM2) DecodePrivateKey(data []byte) (*PrivateKey, error) {
 	if len(data) == 0 {
 		return nil, ErrInvalidPrivateKey
@@ -686,9 +758,7 @@ func (s *SM2) DecodePrivateKey(data []byte) (*PrivateKey, error) {
 	d := new(big.Int).SetBytes(data)
 
 	// 验证私钥是否合法
-	n := s.curve.Params().N
-	one := new(big.Int).SetInt64(1)
-	if d.Cmp(one) < 0 || d.Cmp(new(big.Int).Sub(n, one)) > 0 {
+	if !validPrivateScalar(d, s.curve.Params().N) {
 		return nil, ErrInvalidPrivateKey
 	}
 
@@ -3,7 +3,14 @@ package sm2
 import (
 	"bytes"
 	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math/big"
 	"testing"
+
+	"github.com/laenix/gsc/entropy"
+	"github.com/laenix/gsc/sigopt"
+	"github.com/laenix/gsc/sm3"
 )
 
 // 测试生成密钥对
@@ -88,6 +95,51 @@ func TestEncryptDecrypt(t *testing.T) {
 	})
 }
 
+// 测试空明文按标准生成空C2，单字节0x00能正确往返，以及旧格式的兼容开关
+func TestEncryptEmptyAndZeroByte(t *testing.T) {
+	sm2Instance := New()
+	privateKey, err := sm2Instance.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("生成密钥失败: %v", err)
+	}
+
+	empty, err := sm2Instance.Encrypt(&privateKey.PublicKey, nil, rand.Reader)
+	if err != nil {
+		t.Fatalf("空明文加密失败: %v", err)
+	}
+	if len(empty) != 1+64+32 {
+		t.Fatalf("空明文密文长度应为97字节，实际: %d", len(empty))
+	}
+
+	zero, err := sm2Instance.Encrypt(&privateKey.PublicKey, []byte{0}, rand.Reader)
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	decrypted, err := sm2Instance.Decrypt(privateKey, zero)
+	if err != nil || !bytes.Equal(decrypted, []byte{0}) {
+		t.Fatalf("单字节0x00未能正确往返: %x, %v", decrypted, err)
+	}
+
+	// 旧版本把空明文加密为单个0x00字节
+	legacy := New().WithLegacyEmptyPlaintext(true)
+	old, err := legacy.Encrypt(&privateKey.PublicKey, nil, rand.Reader)
+	if err != nil {
+		t.Fatalf("兼容模式加密失败: %v", err)
+	}
+	if len(old) != 1+64+1+32 {
+		t.Fatalf("兼容模式密文长度应为98字节，实际: %d", len(old))
+	}
+	if decrypted, err := legacy.Decrypt(privateKey, old); err != nil || len(decrypted) != 0 {
+		t.Fatalf("兼容模式应将旧格式还原为空明文: %x, %v", decrypted, err)
+	}
+	if decrypted, err := sm2Instance.Decrypt(privateKey, old); err != nil || !bytes.Equal(decrypted, []byte{0}) {
+		t.Fatalf("默认模式应原样返回0x00: %x, %v", decrypted, err)
+	}
+	if decrypted, err := legacy.Decrypt(privateKey, empty); err != nil || len(decrypted) != 0 {
+		t.Fatalf("兼容模式也应能解开空C2密文: %x, %v", decrypted, err)
+	}
+}
+
 // 测试签名和验证
 func TestSignVerify(t *testing.T) {
 	sm2Instance := New()
@@ -107,8 +159,10 @@ func TestSignVerify(t *testing.T) {
 	}
 
 	for i, message := range messages {
-		// 计算消息摘要（在实际应用中，通常会使用SM3哈希算法）
-		digest := message // 简化，直接使用消息作为摘要
+		// 计算消息摘要，Sign只接受32字节的SM3摘要
+		sum := sm3.New()
+		sum.Write(message)
+		digest := sum.Sum(nil)
 
 		// 签名
 		signature, err := sm2Instance.Sign(privateKey, digest)
@@ -179,6 +233,67 @@ func TestSignVerifyWithId(t *testing.T) {
 	}
 }
 
+// 测试GB/T 32918.2签名算法的已知答案：私钥d和随机数k取标准示例中的值，签名按标准独立计算并由OpenSSL验证
+// 该签名满足r + s ≥ n，曲线阶n错误时t = (r + s) mod n随之错误，验证失败
+func TestVerifyKnownAnswer(t *testing.T) {
+	d, _ := new(big.Int).SetString("3945208f7b2144b13f36e38ac6d39f95889393692860b51a42fb81ef4df7c5b8", 16)
+	x, _ := new(big.Int).SetString("09f9df311e5421a150dd7d161e4bc5c672179fad1833fc076bb08ff356f35020", 16)
+	y, _ := new(big.Int).SetString("ccea490ce26775a52dc6ea718cc1aa600aed05fbf35e084a6632f6072da9ad13", 16)
+	priv := &PrivateKey{D: d, PublicKey: PublicKey{X: x, Y: y}}
+	sig, _ := hex.DecodeString("b0e3e7d4ac2178f833ad73fa9d1191e41c76c8bfedb5ad89040ba2e5184bde58" +
+		"cc8d096578f7dd2669ac1ac42f7e722bcfa42b9e0be0b1b5df7ca0b53fdd5750")
+
+	s := New()
+	if gx, gy := s.curve.ScalarBaseMult(d.Bytes()); gx.Cmp(x) != 0 || gy.Cmp(y) != 0 {
+		t.Fatal("公钥与私钥不匹配")
+	}
+	if !s.VerifyWithId(&priv.PublicKey, []byte("message digest"), sig, []byte("ALICE123@YAHOO.COM")) {
+		t.Fatal("已知答案签名验证失败")
+	}
+
+	// [n-1]G = -G
+	params := s.curve.Params()
+	nMinus1 := new(big.Int).Sub(params.N, big.NewInt(1))
+	gx, gy := s.curve.ScalarBaseMult(nMinus1.Bytes())
+	if gx.Cmp(params.Gx) != 0 || gy.Cmp(new(big.Int).Sub(params.P, params.Gy)) != 0 {
+		t.Fatal("[n-1]G应等于-G，曲线阶n错误")
+	}
+}
+
+// 测试私钥范围为[1, n-2]：d = n-1时1+d没有逆元，解码和签名都应返回错误而不是panic
+func TestPrivateKeyRange(t *testing.T) {
+	s := New()
+	n := s.curve.Params().N
+	for _, tt := range []struct {
+		d  *big.Int
+		ok bool
+	}{
+		{big.NewInt(1), true},
+		{new(big.Int).Sub(n, big.NewInt(2)), true},
+		{new(big.Int).Sub(n, big.NewInt(1)), false},
+		{n, false},
+	} {
+		_, err := s.DecodePrivateKey(tt.d.Bytes())
+		if ok := err == nil; ok != tt.ok {
+			t.Errorf("d = %x: %v", tt.d, err)
+		}
+	}
+	if _, err := s.DecodePrivateKey([]byte{0}); !errors.Is(err, ErrInvalidPrivateKey) {
+		t.Errorf("d = 0: %v", err)
+	}
+
+	x, y := s.curve.ScalarBaseMult(new(big.Int).Sub(n, big.NewInt(1)).Bytes())
+	priv := &PrivateKey{D: new(big.Int).Sub(n, big.NewInt(1)), PublicKey: PublicKey{X: x, Y: y}}
+	if _, err := s.Sign(priv, make([]byte, 32)); !errors.Is(err, ErrInvalidPrivateKey) {
+		t.Errorf("d = n-1时签名: %v", err)
+	}
+	var decoded PrivateKey
+	data := append([]byte{KeyFormatVersion}, priv.D.Bytes()...)
+	if err := decoded.UnmarshalBinary(data); !errors.Is(err, ErrInvalidPrivateKey) {
+		t.Errorf("UnmarshalBinary: %v", err)
+	}
+}
+
 // 测试编码和解码私钥
 func TestEncodeDecodePrivateKey(t *testing.T) {
 	sm2Instance := New()
@@ -259,6 +374,16 @@ func TestErrorCases(t *testing.T) {
 		t.Fatal("解密过短的密文应当失败")
 	}
 
+	// 测试C3被篡改的密文
+	ciphertext, err := sm2Instance.Encrypt(&privateKey.PublicKey, []byte("test"), rand.Reader)
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	ciphertext[len(ciphertext)-1] ^= 1
+	if _, err = sm2Instance.Decrypt(privateKey, ciphertext); err != ErrDecryptionFailed {
+		t.Fatalf("C3被篡改时应返回ErrDecryptionFailed，实际: %v", err)
+	}
+
 	// 测试无效的私钥签名
 	_, err = sm2Instance.Sign(invalidPrivateKey, []byte("test"))
 	if err == nil {
@@ -343,3 +468,140 @@ func BenchmarkVerify(b *testing.B) {
 		sm2Instance.Verify(&privateKey.PublicKey, message, signature)
 	}
 }
+
+// leadingZeroKey 返回公钥X坐标首字节为0的测试密钥（d = 0x147）
+func leadingZeroKey(t *testing.T) *PrivateKey {
+	t.Helper()
+	x, _ := new(big.Int).SetString("00d062045840b1f4b0a64d6e6c5bc582079fc0af8c366eba632b35f5e217385b", 16)
+	y, _ := new(big.Int).SetString("5032f04533c064a41a7616cbb528b168c79a247d46f1c3667e1a2f5921aca9a4", 16)
+	return &PrivateKey{D: big.NewInt(0x147), PublicKey: PublicKey{X: x, Y: y}}
+}
+
+// 测试ZA对首字节为0的坐标使用定长编码
+func TestComputeZALeadingZero(t *testing.T) {
+	priv := leadingZeroKey(t)
+
+	za, err := ComputeZA(&priv.PublicKey, nil)
+	if err != nil {
+		t.Fatalf("计算ZA失败: %v", err)
+	}
+	// 期望值按GB/T 32918.2独立计算
+	want := "ce8356b3b291a02907e71e25fc2c7a8240e0bf7ab840062ecad68aeafacc20f4"
+	if hex.EncodeToString(za) != want {
+		t.Fatalf("ZA不匹配:\n期望值: %s\n实际值: %x", want, za)
+	}
+
+	if _, err := ComputeZA(&priv.PublicKey, make([]byte, 8192)); err != ErrInvalidUID {
+		t.Fatalf("用户标识过长时期望ErrInvalidUID，实际: %v", err)
+	}
+}
+
+// 测试验证OpenSSL生成的签名
+// openssl pkeyutl -sign -rawin -digest sm3 -pkeyopt distid:1234567812345678
+func TestVerifyOpenSSLSignature(t *testing.T) {
+	priv := leadingZeroKey(t)
+	sig, _ := hex.DecodeString("db69958d50f3c86ff7ca537bb2f0959c2f58f7f6a80b36233c65f54d6752512f" +
+		"662e6c247609083c92e7ec2b9afc1a9b4c9df5ad87c9047ef6c8dc3abbbb8d58")
+
+	if !New().VerifyWithId(&priv.PublicKey, []byte("message digest"), sig, []byte("1234567812345678")) {
+		t.Fatal("OpenSSL签名验证失败")
+	}
+}
+
+// 测试签名选项：原始消息不能被当作摘要签名
+func TestSignWithOpts(t *testing.T) {
+	sm2Instance := New()
+	privateKey, err := sm2Instance.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("生成密钥对失败: %v", err)
+	}
+	message := []byte("arbitrary length message to be signed")
+
+	if _, err := sm2Instance.Sign(privateKey, message); err != sigopt.ErrInvalidDigestSize {
+		t.Fatalf("直接签名原始消息期望ErrInvalidDigestSize，实际: %v", err)
+	}
+	if _, err := sm2Instance.SignWithOpts(privateKey, message, sigopt.PreHashed{}); err != sigopt.ErrInvalidDigestSize {
+		t.Fatalf("PreHashed传入原始消息期望ErrInvalidDigestSize，实际: %v", err)
+	}
+
+	// Message选项自动计算ZA和摘要，与SignWithId互通
+	signature, err := sm2Instance.SignWithOpts(privateKey, message, sigopt.Message{})
+	if err != nil {
+		t.Fatalf("签名失败: %v", err)
+	}
+	if !sm2Instance.VerifyWithId(&privateKey.PublicKey, message, signature, nil) {
+		t.Fatal("Message选项的签名应能被VerifyWithId验证")
+	}
+
+	// PreHashed选项使用调用方计算的 e = SM3(ZA || M)
+	za, err := ComputeZA(&privateKey.PublicKey, nil)
+	if err != nil {
+		t.Fatalf("计算ZA失败: %v", err)
+	}
+	h := sm3.New()
+	h.Write(za)
+	h.Write(message)
+	if !sm2Instance.VerifyWithOpts(&privateKey.PublicKey, h.Sum(nil), signature, sigopt.PreHashed{}) {
+		t.Fatal("PreHashed选项验证失败")
+	}
+}
+
+// 测试要求健康熵源时拒绝未经健康测试的随机源
+func TestGenerateKeyHealthyEntropy(t *testing.T) {
+	sm2Instance := New().WithHealthyEntropy(true)
+
+	if _, err := sm2Instance.GenerateKey(rand.Reader); err != entropy.ErrUnhealthy {
+		t.Fatalf("期望entropy.ErrUnhealthy，实际: %v", err)
+	}
+	if _, err := sm2Instance.GenerateKey(nil); err != nil {
+		t.Fatalf("使用默认健康熵源生成密钥失败: %v", err)
+	}
+}
+
+// 测试确定性签名：同一私钥对同一摘要得到相同的签名，且签名可正常验证
+func TestSignDeterministicNonce(t *testing.T) {
+	sm2Instance := New().WithDeterministicNonce(true)
+	privateKey := leadingZeroKey(t)
+	message := []byte("message digest")
+
+	sig1, err := sm2Instance.SignWithOpts(privateKey, message, sigopt.Message{})
+	if err != nil {
+		t.Fatalf("签名失败: %v", err)
+	}
+	sig2, err := sm2Instance.SignWithOpts(privateKey, message, sigopt.Message{})
+	if err != nil {
+		t.Fatalf("签名失败: %v", err)
+	}
+	if !bytes.Equal(sig1, sig2) {
+		t.Fatal("确定性签名对同一消息应得到相同的结果")
+	}
+	if !New().VerifyWithOpts(&privateKey.PublicKey, message, sig1, sigopt.Message{}) {
+		t.Fatal("确定性签名验证失败")
+	}
+
+	// 不同的消息和不同的私钥都应得到不同的签名
+	sig3, err := sm2Instance.SignWithOpts(privateKey, []byte("message digesT"), sigopt.Message{})
+	if err != nil {
+		t.Fatalf("签名失败: %v", err)
+	}
+	if bytes.Equal(sig1, sig3) {
+		t.Fatal("不同消息的确定性签名不应相同")
+	}
+	otherKey, err := sm2Instance.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("生成密钥对失败: %v", err)
+	}
+	digest := make([]byte, sm3.Size)
+	sig4, _ := sm2Instance.Sign(privateKey, digest)
+	sig5, _ := sm2Instance.Sign(otherKey, digest)
+	if bytes.Equal(sig4, sig5) {
+		t.Fatal("不同私钥的确定性签名不应相同")
+	}
+
+	// 默认的随机签名每次都不同
+	sig6, _ := New().Sign(privateKey, digest)
+	sig7, _ := New().Sign(privateKey, digest)
+	if bytes.Equal(sig6, sig7) {
+		t.Fatal("随机签名不应重复")
+	}
+}
@@ -0,0 +1,34 @@
+package sm3_test
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/laenix/gsc/dump"
+	"github.com/laenix/gsc/sm3"
+)
+
+func ExampleSum() {
+	hash := sm3.Sum([]byte("abc"))
+	fmt.Printf("%x\n", hash)
+	// 按32位大端序字输出，便于与GB/T 32905附录中的中间值对照
+	fmt.Println(dump.Words(hash[:], 4, binary.BigEndian))
+	// Output:
+	// 66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0
+	// 66c7f0f4 62eeedd9 d1f2d46b dc10e4e2 4167c487 5cf2f7a2 297da02b 8f4ba8e0
+}
+
+func ExampleNew() {
+	// 分多次写入与一次性计算的结果相同
+	h := sm3.New()
+	h.Write([]byte("abcd"))
+	h.Write([]byte("efgh"))
+	fmt.Printf("%x\n", h.Sum(nil))
+
+	// Reset后可以重用哈希实例
+	h.Reset()
+	fmt.Printf("%x\n", h.Sum(nil))
+	// Output:
+	// 1fe46fe782fa5618721cdf61de2e50c0639f4b26f6568f9c67b128f5610ced68
+	// 1ab21d8355cfa17f8e61194831e81a8f22bec8c728fefb747ed035eb5082aa2b
+}
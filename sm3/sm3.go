@@ -105,12 +105,9 @@ func (d *digest) checkSum() [Size]byte {
 	var tmp [BlockSize]byte
 	tmp[0] = 0x80
 
-	// 计算填充的0的个数
-	// 需要确保最后有8个字节用于存储长度
-	padLen := BlockSize - ((int(len) + 1 + 8) % BlockSize)
-	if padLen <= 0 {
-		padLen += BlockSize
-	}
+	// 计算填充的0的个数：需要确保最后有8个字节用于存储长度，即(len+1+padLen) mod 64 = 56，
+	// padLen在0到63之间，与0x80一起不超过一个块
+	padLen := (55 - int(len%BlockSize) + BlockSize) % BlockSize
 
 	// 写入填充
 	d.Write(tmp[:1+padLen])
@@ -135,13 +132,21 @@ func (d *digest) checkSum() [Size]byte {
 	return digest
 }
 
-// block 处理一个完整的SM3数据块
+// block 处理若干完整的SM3数据块，支持时使用汇编实现
 func (d *digest) block(p []byte) {
-	// 在此函数中实现SM3的核心压缩函数
+	if useAsm {
+		blockAsm(&d.h, p)
+		return
+	}
+	blockGeneric(&d.h, p)
+}
+
+// blockGeneric 是SM3压缩函数的通用实现
+func blockGeneric(state *[8]uint32, p []byte) {
 	var w [68]uint32
 	var w1 [64]uint32
 
-	h0, h1, h2, h3, h4, h5, h6, h7 := d.h[0], d.h[1], d.h[2], d.h[3], d.h[4], d.h[5], d.h[6], d.h[7]
+	h0, h1, h2, h3, h4, h5, h6, h7 := state[0], state[1], state[2], state[3], state[4], state[5], state[6], state[7]
 
 	for len(p) >= BlockSize {
 		// 将消息分组扩展为132个字
@@ -193,7 +198,7 @@ func (d *digest) block(p []byte) {
 	}
 
 	// 保存当前哈希状态
-	d.h[0], d.h[1], d.h[2], d.h[3], d.h[4], d.h[5], d.h[6], d.h[7] = h0, h1, h2, h3, h4, h5, h6, h7
+	state[0], state[1], state[2], state[3], state[4], state[5], state[6], state[7] = h0, h1, h2, h3, h4, h5, h6, h7
 }
 
 // SM3算法中的置换函数ff
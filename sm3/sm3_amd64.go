@@ -0,0 +1,17 @@
+//go:build !purego
+
+package sm3
+
+import "github.com/laenix/gsc/internal/cpu"
+
+// useAsm 记录能否使用汇编实现：消息扩展使用AVX指令，压缩函数使用BMI2的RORX
+var useAsm = cpu.X86.HasAVX2 && cpu.X86.HasBMI2
+
+// blockAVX2 处理p中所有完整的64字节块，p的长度必须是64的整数倍
+//
+//go:noescape
+func blockAVX2(h *[8]uint32, p []byte)
+
+func blockAsm(h *[8]uint32, p []byte) {
+	blockAVX2(h, p)
+}
@@ -0,0 +1,263 @@
+//go:build !purego
+
+#include "textflag.h"
+
+// 消息扩展使用VEX编码的128位SIMD指令，每次由寄存器中的W[i-16..i-1]计算W[i..i+3]，
+// 并穿插在压缩函数的轮之间，与标量代码并行执行。W[i+3]依赖同一次计算出的W[i]，
+// 先以0代替W[i]计算，再利用P1的线性补上P1(W[i] <<< 15)。
+// 压缩函数的64轮为标量代码，用BMI2的RORX做循环移位，通过轮换寄存器代替变量之间的赋值，
+// 常量T_j <<< j在生成代码时预先计算
+
+// 栈上的W[0..67]，供各轮读取W_j和W'_j = W_j ^ W_(j+4)
+#define W(i) ((i)*4)(SP)
+#define count 272(SP)
+
+// ROTL(n, x, tmp) 将x中的4个字循环左移n位
+#define ROTL(n, x, tmp) \
+	VPSLLD $(n), x, tmp \
+	VPSRLD $(32-n), x, x \
+	VPOR   tmp, x, x
+
+// P1(x) 计算 x ^ (x <<< 15) ^ (x <<< 23)，使用X7、X8
+#define P1(x) \
+	VPSLLD $15, x, X7 \
+	VPSRLD $17, x, X8 \
+	VPOR   X8, X7, X7 \
+	VPXOR  X7, x, X7 \
+	VPSLLD $23, x, X8 \
+	VPSRLD $9, x, x \
+	VPOR   X8, x, x \
+	VPXOR  X7, x, x
+
+// EXPAND(i, w0, w1, w2, w3, out) 由w0..w3中的W[i-16..i-1]计算W[i..i+3]，写入out和栈
+// W[j] = P1(W[j-16] ^ W[j-9] ^ (W[j-3] <<< 15)) ^ (W[j-13] <<< 7) ^ W[j-6]
+#define EXPAND(i, w0, w1, w2, w3, out) \
+	VPALIGNR $12, w1, w2, X5 \
+	VPXOR    w0, X5, X5 \
+	VPSRLDQ  $4, w3, X6 \
+	ROTL(15, X6, X7) \
+	VPXOR    X6, X5, X5 \
+	P1(X5) \
+	VPALIGNR $12, w0, w1, X6 \
+	ROTL(7, X6, X7) \
+	VPXOR    X6, X5, X5 \
+	VPALIGNR $8, w2, w3, X6 \
+	VPXOR    X6, X5, out \
+	VPSLLDQ  $12, out, X6 \
+	ROTL(15, X6, X7) \
+	P1(X6) \
+	VPXOR    X6, out, out \
+	VMOVDQU  out, W(i)
+
+// ROUND_HEAD 计算SS1（BX）、SS2 + W'_j（AX）和h + SS1 + W_j（h）
+#define ROUND_HEAD(j, t, a, e, h) \
+	RORXL $20, a, AX \
+	MOVL e, BX \
+	ADDL $(t), BX \
+	ADDL AX, BX \
+	ROLL $7, BX \
+	XORL BX, AX \
+	MOVL W(j), CX \
+	ADDL BX, h \
+	ADDL CX, h \
+	XORL W(j+4), CX \
+	ADDL CX, AX
+
+// ROUND_TAIL 计算 d = TT1，h = P0(TT2)，b <<<= 9，f <<<= 19
+#define ROUND_TAIL(b, d, f, h) \
+	ADDL  AX, d \
+	RORXL $23, h, CX \
+	RORXL $15, h, DX \
+	XORL  CX, h \
+	XORL  DX, h \
+	RORXL $23, b, b \
+	RORXL $13, f, f
+
+// 0 ≤ j ≤ 15：FF = a ^ b ^ c，GG = e ^ f ^ g
+#define ROUND0(j, t, a, b, c, d, e, f, g, h) \
+	ROUND_HEAD(j, t, a, e, h) \
+	MOVL a, CX \
+	XORL b, CX \
+	XORL c, CX \
+	ADDL CX, AX \
+	MOVL e, CX \
+	XORL f, CX \
+	XORL g, CX \
+	ADDL CX, h \
+	ROUND_TAIL(b, d, f, h)
+
+// 16 ≤ j ≤ 63：FF = (a & b) | ((a | b) & c)，GG = ((f ^ g) & e) ^ g
+#define ROUND1(j, t, a, b, c, d, e, f, g, h) \
+	ROUND_HEAD(j, t, a, e, h) \
+	MOVL a, CX \
+	MOVL a, DX \
+	ANDL b, CX \
+	ORL  b, DX \
+	ANDL c, DX \
+	ORL  DX, CX \
+	ADDL CX, AX \
+	MOVL f, CX \
+	XORL g, CX \
+	ANDL e, CX \
+	XORL g, CX \
+	ADDL CX, h \
+	ROUND_TAIL(b, d, f, h)
+
+// func blockAVX2(h *[8]uint32, p []byte)
+TEXT ·blockAVX2(SB), NOSPLIT, $280-32
+	MOVQ h+0(FP), DI
+	MOVQ p_base+8(FP), SI
+	MOVQ p_len+16(FP), DX
+	SHRQ $6, DX
+	JZ   done
+	MOVQ DX, count
+
+	MOVL 0(DI), R8
+	MOVL 4(DI), R9
+	MOVL 8(DI), R10
+	MOVL 12(DI), R11
+	MOVL 16(DI), R12
+	MOVL 20(DI), R13
+	MOVL 24(DI), R14
+	MOVL 28(DI), R15
+
+loop:
+	// 消息按大端序读入W[0..15]
+	VMOVDQU bswapMask<>(SB), X4
+	VMOVDQU 0(SI), X0
+	VMOVDQU 16(SI), X1
+	VMOVDQU 32(SI), X2
+	VMOVDQU 48(SI), X3
+	VPSHUFB X4, X0, X0
+	VPSHUFB X4, X1, X1
+	VPSHUFB X4, X2, X2
+	VPSHUFB X4, X3, X3
+	VMOVDQU X0, W(0)
+	VMOVDQU X1, W(4)
+	VMOVDQU X2, W(8)
+	VMOVDQU X3, W(12)
+
+	EXPAND(16, X0, X1, X2, X3, X4)
+	ROUND0(0, 0x79cc4519, R8, R9, R10, R11, R12, R13, R14, R15)
+	ROUND0(1, 0xf3988a32, R11, R8, R9, R10, R15, R12, R13, R14)
+	ROUND0(2, 0xe7311465, R10, R11, R8, R9, R14, R15, R12, R13)
+	ROUND0(3, 0xce6228cb, R9, R10, R11, R8, R13, R14, R15, R12)
+
+	EXPAND(20, X1, X2, X3, X4, X0)
+	ROUND0(4, 0x9cc45197, R8, R9, R10, R11, R12, R13, R14, R15)
+	ROUND0(5, 0x3988a32f, R11, R8, R9, R10, R15, R12, R13, R14)
+	ROUND0(6, 0x7311465e, R10, R11, R8, R9, R14, R15, R12, R13)
+	ROUND0(7, 0xe6228cbc, R9, R10, R11, R8, R13, R14, R15, R12)
+
+	EXPAND(24, X2, X3, X4, X0, X1)
+	ROUND0(8, 0xcc451979, R8, R9, R10, R11, R12, R13, R14, R15)
+	ROUND0(9, 0x988a32f3, R11, R8, R9, R10, R15, R12, R13, R14)
+	ROUND0(10, 0x311465e7, R10, R11, R8, R9, R14, R15, R12, R13)
+	ROUND0(11, 0x6228cbce, R9, R10, R11, R8, R13, R14, R15, R12)
+
+	EXPAND(28, X3, X4, X0, X1, X2)
+	ROUND0(12, 0xc451979c, R8, R9, R10, R11, R12, R13, R14, R15)
+	ROUND0(13, 0x88a32f39, R11, R8, R9, R10, R15, R12, R13, R14)
+	ROUND0(14, 0x11465e73, R10, R11, R8, R9, R14, R15, R12, R13)
+	ROUND0(15, 0x228cbce6, R9, R10, R11, R8, R13, R14, R15, R12)
+
+	EXPAND(32, X4, X0, X1, X2, X3)
+	ROUND1(16, 0x9d8a7a87, R8, R9, R10, R11, R12, R13, R14, R15)
+	ROUND1(17, 0x3b14f50f, R11, R8, R9, R10, R15, R12, R13, R14)
+	ROUND1(18, 0x7629ea1e, R10, R11, R8, R9, R14, R15, R12, R13)
+	ROUND1(19, 0xec53d43c, R9, R10, R11, R8, R13, R14, R15, R12)
+
+	EXPAND(36, X0, X1, X2, X3, X4)
+	ROUND1(20, 0xd8a7a879, R8, R9, R10, R11, R12, R13, R14, R15)
+	ROUND1(21, 0xb14f50f3, R11, R8, R9, R10, R15, R12, R13, R14)
+	ROUND1(22, 0x629ea1e7, R10, R11, R8, R9, R14, R15, R12, R13)
+	ROUND1(23, 0xc53d43ce, R9, R10, R11, R8, R13, R14, R15, R12)
+
+	EXPAND(40, X1, X2, X3, X4, X0)
+	ROUND1(24, 0x8a7a879d, R8, R9, R10, R11, R12, R13, R14, R15)
+	ROUND1(25, 0x14f50f3b, R11, R8, R9, R10, R15, R12, R13, R14)
+	ROUND1(26, 0x29ea1e76, R10, R11, R8, R9, R14, R15, R12, R13)
+	ROUND1(27, 0x53d43cec, R9, R10, R11, R8, R13, R14, R15, R12)
+
+	EXPAND(44, X2, X3, X4, X0, X1)
+	ROUND1(28, 0xa7a879d8, R8, R9, R10, R11, R12, R13, R14, R15)
+	ROUND1(29, 0x4f50f3b1, R11, R8, R9, R10, R15, R12, R13, R14)
+	ROUND1(30, 0x9ea1e762, R10, R11, R8, R9, R14, R15, R12, R13)
+	ROUND1(31, 0x3d43cec5, R9, R10, R11, R8, R13, R14, R15, R12)
+
+	EXPAND(48, X3, X4, X0, X1, X2)
+	ROUND1(32, 0x7a879d8a, R8, R9, R10, R11, R12, R13, R14, R15)
+	ROUND1(33, 0xf50f3b14, R11, R8, R9, R10, R15, R12, R13, R14)
+	ROUND1(34, 0xea1e7629, R10, R11, R8, R9, R14, R15, R12, R13)
+	ROUND1(35, 0xd43cec53, R9, R10, R11, R8, R13, R14, R15, R12)
+
+	EXPAND(52, X4, X0, X1, X2, X3)
+	ROUND1(36, 0xa879d8a7, R8, R9, R10, R11, R12, R13, R14, R15)
+	ROUND1(37, 0x50f3b14f, R11, R8, R9, R10, R15, R12, R13, R14)
+	ROUND1(38, 0xa1e7629e, R10, R11, R8, R9, R14, R15, R12, R13)
+	ROUND1(39, 0x43cec53d, R9, R10, R11, R8, R13, R14, R15, R12)
+
+	EXPAND(56, X0, X1, X2, X3, X4)
+	ROUND1(40, 0x879d8a7a, R8, R9, R10, R11, R12, R13, R14, R15)
+	ROUND1(41, 0x0f3b14f5, R11, R8, R9, R10, R15, R12, R13, R14)
+	ROUND1(42, 0x1e7629ea, R10, R11, R8, R9, R14, R15, R12, R13)
+	ROUND1(43, 0x3cec53d4, R9, R10, R11, R8, R13, R14, R15, R12)
+
+	EXPAND(60, X1, X2, X3, X4, X0)
+	ROUND1(44, 0x79d8a7a8, R8, R9, R10, R11, R12, R13, R14, R15)
+	ROUND1(45, 0xf3b14f50, R11, R8, R9, R10, R15, R12, R13, R14)
+	ROUND1(46, 0xe7629ea1, R10, R11, R8, R9, R14, R15, R12, R13)
+	ROUND1(47, 0xcec53d43, R9, R10, R11, R8, R13, R14, R15, R12)
+
+	EXPAND(64, X2, X3, X4, X0, X1)
+	ROUND1(48, 0x9d8a7a87, R8, R9, R10, R11, R12, R13, R14, R15)
+	ROUND1(49, 0x3b14f50f, R11, R8, R9, R10, R15, R12, R13, R14)
+	ROUND1(50, 0x7629ea1e, R10, R11, R8, R9, R14, R15, R12, R13)
+	ROUND1(51, 0xec53d43c, R9, R10, R11, R8, R13, R14, R15, R12)
+
+	ROUND1(52, 0xd8a7a879, R8, R9, R10, R11, R12, R13, R14, R15)
+	ROUND1(53, 0xb14f50f3, R11, R8, R9, R10, R15, R12, R13, R14)
+	ROUND1(54, 0x629ea1e7, R10, R11, R8, R9, R14, R15, R12, R13)
+	ROUND1(55, 0xc53d43ce, R9, R10, R11, R8, R13, R14, R15, R12)
+
+	ROUND1(56, 0x8a7a879d, R8, R9, R10, R11, R12, R13, R14, R15)
+	ROUND1(57, 0x14f50f3b, R11, R8, R9, R10, R15, R12, R13, R14)
+	ROUND1(58, 0x29ea1e76, R10, R11, R8, R9, R14, R15, R12, R13)
+	ROUND1(59, 0x53d43cec, R9, R10, R11, R8, R13, R14, R15, R12)
+
+	ROUND1(60, 0xa7a879d8, R8, R9, R10, R11, R12, R13, R14, R15)
+	ROUND1(61, 0x4f50f3b1, R11, R8, R9, R10, R15, R12, R13, R14)
+	ROUND1(62, 0x9ea1e762, R10, R11, R8, R9, R14, R15, R12, R13)
+	ROUND1(63, 0x3d43cec5, R9, R10, R11, R8, R13, R14, R15, R12)
+
+	// V(i+1) = ABCDEFGH ^ V(i)
+	XORL 0(DI), R8
+	XORL 4(DI), R9
+	XORL 8(DI), R10
+	XORL 12(DI), R11
+	XORL 16(DI), R12
+	XORL 20(DI), R13
+	XORL 24(DI), R14
+	XORL 28(DI), R15
+	MOVL R8, 0(DI)
+	MOVL R9, 4(DI)
+	MOVL R10, 8(DI)
+	MOVL R11, 12(DI)
+	MOVL R12, 16(DI)
+	MOVL R13, 20(DI)
+	MOVL R14, 24(DI)
+	MOVL R15, 28(DI)
+
+	ADDQ $64, SI
+	DECQ count
+	JNZ  loop
+
+	VZEROUPPER
+
+done:
+	RET
+
+// bswapMask 将每个32位字转换为大端序
+DATA bswapMask<>+0x00(SB)/8, $0x0405060700010203
+DATA bswapMask<>+0x08(SB)/8, $0x0c0d0e0f08090a0b
+GLOBL bswapMask<>(SB), RODATA|NOPTR, $16
@@ -0,0 +1,15 @@
+//go:build !purego
+
+package sm3
+
+// arm64必然支持NEON（高级SIMD），总是使用汇编实现
+const useAsm = true
+
+// blockNEON 处理p中所有完整的64字节块，p的长度必须是64的整数倍
+//
+//go:noescape
+func blockNEON(h *[8]uint32, p []byte)
+
+func blockAsm(h *[8]uint32, p []byte) {
+	blockNEON(h, p)
+}
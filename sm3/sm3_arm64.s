@@ -0,0 +1,234 @@
+//go:build !purego
+
+#include "textflag.h"
+
+// 消息扩展使用NEON，每次由寄存器中的W[i-16..i-1]计算W[i..i+3]，并穿插在压缩函数的轮之间。
+// W[i+3]依赖同一次计算出的W[i]，先以0代替W[i]计算，再利用P1的线性补上P1(W[i] <<< 15)。
+// 压缩函数的64轮为标量代码，通过轮换寄存器代替变量之间的赋值，
+// 常量T_j <<< j在生成代码时预先计算。R19指向栈上的W[0..67]，V9恒为0
+
+#define W(i) ((i)*4)(R19)
+
+// ROTL(n, x, y) 将x中的4个字循环左移n位，结果写入y
+#define ROTL(n, x, y) \
+	VSHL $(n), x.S4, y.S4 \
+	VSRI $(32-n), x.S4, y.S4
+
+// P1(x, t1, t2) 计算 x ^ (x <<< 15) ^ (x <<< 23)
+#define P1(x, t1, t2) \
+	ROTL(15, x, t1) \
+	ROTL(23, x, t2) \
+	VEOR t1.B16, x.B16, x.B16 \
+	VEOR t2.B16, x.B16, x.B16
+
+// EXPAND(i, w0, w1, w2, w3, out) 由w0..w3中的W[i-16..i-1]计算W[i..i+3]，写入out和栈
+// W[j] = P1(W[j-16] ^ W[j-9] ^ (W[j-3] <<< 15)) ^ (W[j-13] <<< 7) ^ W[j-6]
+#define EXPAND(i, w0, w1, w2, w3, out) \
+	VEXT $12, w2.B16, w1.B16, V5.B16 \
+	VEOR w0.B16, V5.B16, V5.B16 \
+	VEXT $4, V9.B16, w3.B16, V6.B16 \
+	ROTL(15, V6, V7) \
+	VEOR V7.B16, V5.B16, V5.B16 \
+	P1(V5, V7, V8) \
+	VEXT $12, w1.B16, w0.B16, V6.B16 \
+	ROTL(7, V6, V7) \
+	VEOR V7.B16, V5.B16, V5.B16 \
+	VEXT $8, w3.B16, w2.B16, V6.B16 \
+	VEOR V6.B16, V5.B16, out.B16 \
+	VEXT $4, out.B16, V9.B16, V6.B16 \
+	ROTL(15, V6, V5) \
+	P1(V5, V7, V8) \
+	VEOR V5.B16, out.B16, out.B16 \
+	ADD  $((i)*4), R19, R20 \
+	VST1 [out.S4], (R20)
+
+// ROUND_HEAD 计算SS2 + W'_j（R12）和h + SS1 + W_j（h）
+#define ROUND_HEAD(j, t, a, e, h) \
+	RORW  $20, a, R12 \
+	MOVW  $(t), R13 \
+	ADDW  e, R13, R13 \
+	ADDW  R12, R13, R13 \
+	RORW  $25, R13, R13 \
+	EORW  R13, R12, R12 \
+	MOVWU W(j), R14 \
+	MOVWU W(j+4), R15 \
+	ADDW  R13, h, h \
+	ADDW  R14, h, h \
+	EORW  R14, R15, R15 \
+	ADDW  R15, R12, R12
+
+// ROUND_TAIL 计算 d = TT1，h = P0(TT2)，b <<<= 9，f <<<= 19
+#define ROUND_TAIL(b, d, f, h) \
+	ADDW R12, d, d \
+	RORW $23, h, R14 \
+	RORW $15, h, R15 \
+	EORW R14, h, h \
+	EORW R15, h, h \
+	RORW $23, b, b \
+	RORW $13, f, f
+
+// 0 ≤ j ≤ 15：FF = a ^ b ^ c，GG = e ^ f ^ g
+#define ROUND0(j, t, a, b, c, d, e, f, g, h) \
+	ROUND_HEAD(j, t, a, e, h) \
+	EORW b, a, R14 \
+	EORW c, R14, R14 \
+	ADDW R14, R12, R12 \
+	EORW f, e, R14 \
+	EORW g, R14, R14 \
+	ADDW R14, h, h \
+	ROUND_TAIL(b, d, f, h)
+
+// 16 ≤ j ≤ 63：FF = (a & b) | ((a | b) & c)，GG = (e & f) | (g &^ e)
+#define ROUND1(j, t, a, b, c, d, e, f, g, h) \
+	ROUND_HEAD(j, t, a, e, h) \
+	ANDW b, a, R14 \
+	ORRW b, a, R15 \
+	ANDW c, R15, R15 \
+	ORRW R15, R14, R14 \
+	ADDW R14, R12, R12 \
+	ANDW f, e, R14 \
+	BICW e, g, R15 \
+	ORRW R15, R14, R14 \
+	ADDW R14, h, h \
+	ROUND_TAIL(b, d, f, h)
+
+// func blockNEON(h *[8]uint32, p []byte)
+TEXT ·blockNEON(SB), NOSPLIT, $272-32
+	MOVD h+0(FP), R0
+	MOVD p_base+8(FP), R1
+	MOVD p_len+16(FP), R3
+	LSR  $6, R3
+	CBZ  R3, done
+
+	ADD  $8, RSP, R19
+	VEOR V9.B16, V9.B16, V9.B16
+	LDPW 0(R0), (R4, R5)
+	LDPW 8(R0), (R6, R7)
+	LDPW 16(R0), (R8, R9)
+	LDPW 24(R0), (R10, R11)
+
+loop:
+	// 消息按大端序读入W[0..15]
+	VLD1.P 64(R1), [V0.B16, V1.B16, V2.B16, V3.B16]
+	VREV32 V0.B16, V0.B16
+	VREV32 V1.B16, V1.B16
+	VREV32 V2.B16, V2.B16
+	VREV32 V3.B16, V3.B16
+	VST1   [V0.S4, V1.S4, V2.S4, V3.S4], (R19)
+
+	EXPAND(16, V0, V1, V2, V3, V4)
+	ROUND0(0, 0x79cc4519, R4, R5, R6, R7, R8, R9, R10, R11)
+	ROUND0(1, 0xf3988a32, R7, R4, R5, R6, R11, R8, R9, R10)
+	ROUND0(2, 0xe7311465, R6, R7, R4, R5, R10, R11, R8, R9)
+	ROUND0(3, 0xce6228cb, R5, R6, R7, R4, R9, R10, R11, R8)
+
+	EXPAND(20, V1, V2, V3, V4, V0)
+	ROUND0(4, 0x9cc45197, R4, R5, R6, R7, R8, R9, R10, R11)
+	ROUND0(5, 0x3988a32f, R7, R4, R5, R6, R11, R8, R9, R10)
+	ROUND0(6, 0x7311465e, R6, R7, R4, R5, R10, R11, R8, R9)
+	ROUND0(7, 0xe6228cbc, R5, R6, R7, R4, R9, R10, R11, R8)
+
+	EXPAND(24, V2, V3, V4, V0, V1)
+	ROUND0(8, 0xcc451979, R4, R5, R6, R7, R8, R9, R10, R11)
+	ROUND0(9, 0x988a32f3, R7, R4, R5, R6, R11, R8, R9, R10)
+	ROUND0(10, 0x311465e7, R6, R7, R4, R5, R10, R11, R8, R9)
+	ROUND0(11, 0x6228cbce, R5, R6, R7, R4, R9, R10, R11, R8)
+
+	EXPAND(28, V3, V4, V0, V1, V2)
+	ROUND0(12, 0xc451979c, R4, R5, R6, R7, R8, R9, R10, R11)
+	ROUND0(13, 0x88a32f39, R7, R4, R5, R6, R11, R8, R9, R10)
+	ROUND0(14, 0x11465e73, R6, R7, R4, R5, R10, R11, R8, R9)
+	ROUND0(15, 0x228cbce6, R5, R6, R7, R4, R9, R10, R11, R8)
+
+	EXPAND(32, V4, V0, V1, V2, V3)
+	ROUND1(16, 0x9d8a7a87, R4, R5, R6, R7, R8, R9, R10, R11)
+	ROUND1(17, 0x3b14f50f, R7, R4, R5, R6, R11, R8, R9, R10)
+	ROUND1(18, 0x7629ea1e, R6, R7, R4, R5, R10, R11, R8, R9)
+	ROUND1(19, 0xec53d43c, R5, R6, R7, R4, R9, R10, R11, R8)
+
+	EXPAND(36, V0, V1, V2, V3, V4)
+	ROUND1(20, 0xd8a7a879, R4, R5, R6, R7, R8, R9, R10, R11)
+	ROUND1(21, 0xb14f50f3, R7, R4, R5, R6, R11, R8, R9, R10)
+	ROUND1(22, 0x629ea1e7, R6, R7, R4, R5, R10, R11, R8, R9)
+	ROUND1(23, 0xc53d43ce, R5, R6, R7, R4, R9, R10, R11, R8)
+
+	EXPAND(40, V1, V2, V3, V4, V0)
+	ROUND1(24, 0x8a7a879d, R4, R5, R6, R7, R8, R9, R10, R11)
+	ROUND1(25, 0x14f50f3b, R7, R4, R5, R6, R11, R8, R9, R10)
+	ROUND1(26, 0x29ea1e76, R6, R7, R4, R5, R10, R11, R8, R9)
+	ROUND1(27, 0x53d43cec, R5, R6, R7, R4, R9, R10, R11, R8)
+
+	EXPAND(44, V2, V3, V4, V0, V1)
+	ROUND1(28, 0xa7a879d8, R4, R5, R6, R7, R8, R9, R10, R11)
+	ROUND1(29, 0x4f50f3b1, R7, R4, R5, R6, R11, R8, R9, R10)
+	ROUND1(30, 0x9ea1e762, R6, R7, R4, R5, R10, R11, R8, R9)
+	ROUND1(31, 0x3d43cec5, R5, R6, R7, R4, R9, R10, R11, R8)
+
+	EXPAND(48, V3, V4, V0, V1, V2)
+	ROUND1(32, 0x7a879d8a, R4, R5, R6, R7, R8, R9, R10, R11)
+	ROUND1(33, 0xf50f3b14, R7, R4, R5, R6, R11, R8, R9, R10)
+	ROUND1(34, 0xea1e7629, R6, R7, R4, R5, R10, R11, R8, R9)
+	ROUND1(35, 0xd43cec53, R5, R6, R7, R4, R9, R10, R11, R8)
+
+	EXPAND(52, V4, V0, V1, V2, V3)
+	ROUND1(36, 0xa879d8a7, R4, R5, R6, R7, R8, R9, R10, R11)
+	ROUND1(37, 0x50f3b14f, R7, R4, R5, R6, R11, R8, R9, R10)
+	ROUND1(38, 0xa1e7629e, R6, R7, R4, R5, R10, R11, R8, R9)
+	ROUND1(39, 0x43cec53d, R5, R6, R7, R4, R9, R10, R11, R8)
+
+	EXPAND(56, V0, V1, V2, V3, V4)
+	ROUND1(40, 0x879d8a7a, R4, R5, R6, R7, R8, R9, R10, R11)
+	ROUND1(41, 0x0f3b14f5, R7, R4, R5, R6, R11, R8, R9, R10)
+	ROUND1(42, 0x1e7629ea, R6, R7, R4, R5, R10, R11, R8, R9)
+	ROUND1(43, 0x3cec53d4, R5, R6, R7, R4, R9, R10, R11, R8)
+
+	EXPAND(60, V1, V2, V3, V4, V0)
+	ROUND1(44, 0x79d8a7a8, R4, R5, R6, R7, R8, R9, R10, R11)
+	ROUND1(45, 0xf3b14f50, R7, R4, R5, R6, R11, R8, R9, R10)
+	ROUND1(46, 0xe7629ea1, R6, R7, R4, R5, R10, R11, R8, R9)
+	ROUND1(47, 0xcec53d43, R5, R6, R7, R4, R9, R10, R11, R8)
+
+	EXPAND(64, V2, V3, V4, V0, V1)
+	ROUND1(48, 0x9d8a7a87, R4, R5, R6, R7, R8, R9, R10, R11)
+	ROUND1(49, 0x3b14f50f, R7, R4, R5, R6, R11, R8, R9, R10)
+	ROUND1(50, 0x7629ea1e, R6, R7, R4, R5, R10, R11, R8, R9)
+	ROUND1(51, 0xec53d43c, R5, R6, R7, R4, R9, R10, R11, R8)
+
+	ROUND1(52, 0xd8a7a879, R4, R5, R6, R7, R8, R9, R10, R11)
+	ROUND1(53, 0xb14f50f3, R7, R4, R5, R6, R11, R8, R9, R10)
+	ROUND1(54, 0x629ea1e7, R6, R7, R4, R5, R10, R11, R8, R9)
+	ROUND1(55, 0xc53d43ce, R5, R6, R7, R4, R9, R10, R11, R8)
+
+	ROUND1(56, 0x8a7a879d, R4, R5, R6, R7, R8, R9, R10, R11)
+	ROUND1(57, 0x14f50f3b, R7, R4, R5, R6, R11, R8, R9, R10)
+	ROUND1(58, 0x29ea1e76, R6, R7, R4, R5, R10, R11, R8, R9)
+	ROUND1(59, 0x53d43cec, R5, R6, R7, R4, R9, R10, R11, R8)
+
+	ROUND1(60, 0xa7a879d8, R4, R5, R6, R7, R8, R9, R10, R11)
+	ROUND1(61, 0x4f50f3b1, R7, R4, R5, R6, R11, R8, R9, R10)
+	ROUND1(62, 0x9ea1e762, R6, R7, R4, R5, R10, R11, R8, R9)
+	ROUND1(63, 0x3d43cec5, R5, R6, R7, R4, R9, R10, R11, R8)
+
+	// V(i+1) = ABCDEFGH ^ V(i)
+	LDPW 0(R0), (R12, R13)
+	LDPW 8(R0), (R14, R15)
+	EORW R12, R4
+	EORW R13, R5
+	EORW R14, R6
+	EORW R15, R7
+	LDPW 16(R0), (R12, R13)
+	LDPW 24(R0), (R14, R15)
+	EORW R12, R8
+	EORW R13, R9
+	EORW R14, R10
+	EORW R15, R11
+	STPW (R4, R5), 0(R0)
+	STPW (R6, R7), 8(R0)
+	STPW (R8, R9), 16(R0)
+	STPW (R10, R11), 24(R0)
+
+	SUBS $1, R3
+	BNE  loop
+
+done:
+	RET
@@ -0,0 +1,10 @@
+//go:build (!amd64 && !arm64) || purego
+
+package sm3
+
+// 没有汇编实现的平台只使用通用实现
+const useAsm = false
+
+func blockAsm(h *[8]uint32, p []byte) {
+	panic("sm3: assembly implementation is not available on this platform")
+}
@@ -2,8 +2,12 @@ package sm3
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/hex"
+	"strings"
 	"testing"
+
+	"github.com/laenix/gsc/sm3/internal"
 )
 
 type sm3Test struct {
@@ -20,6 +24,9 @@ var golden = []sm3Test{
 	{"abc", "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0"},
 	// 示例3: 长度为64字节的字符串
 	{"abcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcd", "debe9ff92275b8a138604889c18e5a4d6fdb70e5387e5765293dcba39c0c5732"},
+	// 长度为55和119字节（模64余55）时，0x80之后不需要填充0，期望值由OpenSSL计算
+	{strings.Repeat("a", 55), "288337eef51eec62e7544d7270424c8dbe656254c99852870a73b2453a6a7fb1"},
+	{strings.Repeat("a", 119), "53282a90724e9eb79b18d06b5b8f7f02d046e18b29247dcdb064a136d5c4459a"},
 }
 
 // 测试Sum函数
@@ -75,6 +82,59 @@ func TestBlockWrites(t *testing.T) {
 	}
 }
 
+// 测试汇编实现与通用实现的结果一致
+func TestBlockAsm(t *testing.T) {
+	if !useAsm {
+		t.Skip("当前平台不使用汇编实现")
+	}
+	data := make([]byte, 9*BlockSize)
+	for i := range data {
+		data[i] = byte(i*31 + 7)
+	}
+	for n := 0; n <= len(data); n += BlockSize {
+		h1 := internal.IV
+		h2 := internal.IV
+		blockAsm(&h1, data[:n])
+		blockGeneric(&h2, data[:n])
+		if h1 != h2 {
+			t.Fatalf("%d个块：汇编实现 %08x，通用实现 %08x", n/BlockSize, h1, h2)
+		}
+	}
+}
+
+// 测试0到256字节的每种长度：按GB/T 32905手工填充后用通用压缩函数计算，与Sum和逐字节写入的结果对比
+func TestPaddingLengths(t *testing.T) {
+	data := make([]byte, 256)
+	for i := range data {
+		data[i] = byte(i*13 + 5)
+	}
+	for n := 0; n <= len(data); n++ {
+		padded := append([]byte{}, data[:n]...)
+		padded = append(padded, 0x80)
+		for len(padded)%BlockSize != BlockSize-8 {
+			padded = append(padded, 0)
+		}
+		padded = binary.BigEndian.AppendUint64(padded, uint64(n)*8)
+		state := internal.IV
+		blockGeneric(&state, padded)
+		var want [Size]byte
+		for i, v := range state {
+			binary.BigEndian.PutUint32(want[i*4:], v)
+		}
+
+		if got := Sum(data[:n]); got != want {
+			t.Fatalf("%d字节：Sum为%x，期望%x", n, got, want)
+		}
+		h := New()
+		for i := range n {
+			h.Write(data[i : i+1])
+		}
+		if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+			t.Fatalf("%d字节逐字节写入：%x，期望%x", n, got, want)
+		}
+	}
+}
+
 // 测试Reset方法
 func TestReset(t *testing.T) {
 	h1 := New()
@@ -0,0 +1,30 @@
+package sm4
+
+// EncryptBlocks 加密src中的多个块并写入dst，dst与src可以是同一切片
+// src长度必须是16的整数倍且dst不短于src，否则返回ErrInvalidBlockSize。
+// 支持AES-NI时每次并行处理4个块，批量处理比逐块调用Encrypt快得多
+func (s *SM4) EncryptBlocks(dst, src []byte) error {
+	return s.cryptBlocks(&s.roundKeys, dst, src)
+}
+
+// DecryptBlocks 解密src中的多个块并写入dst，约定与EncryptBlocks相同
+func (s *SM4) DecryptBlocks(dst, src []byte) error {
+	return s.cryptBlocks(&s.decRoundKeys, dst, src)
+}
+
+// cryptBlocks 按给定顺序的轮密钥处理多个块，不足4块的尾部使用通用实现
+func (s *SM4) cryptBlocks(rk *[32]uint32, dst, src []byte) error {
+	if len(src)%BlockSize != 0 || len(dst) < len(src) {
+		return ErrInvalidBlockSize
+	}
+	if supportsAESNI {
+		for len(src) >= 4*BlockSize {
+			cryptBlocks4Asm(&rk[0], &dst[0], &src[0])
+			src, dst = src[4*BlockSize:], dst[4*BlockSize:]
+		}
+	}
+	for i := 0; i < len(src); i += BlockSize {
+		cryptBlock(rk, dst[i:i+BlockSize], src[i:i+BlockSize])
+	}
+	return nil
+}
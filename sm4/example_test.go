@@ -0,0 +1,84 @@
+package sm4_test
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/laenix/gsc/dump"
+	"github.com/laenix/gsc/modes"
+	"github.com/laenix/gsc/padding"
+	"github.com/laenix/gsc/sm4"
+)
+
+// 本例展示CBC模式下带PKCS#7填充的完整加解密流程
+func Example_cbc() {
+	key, _ := hex.DecodeString("0123456789ABCDEFFEDCBA9876543210")
+	iv := []byte("1234567890123456")
+	plaintext := []byte("这是SM4加密算法的测试文本，长度超过一个块。")
+
+	cipher, err := sm4.New(key)
+	if err != nil {
+		panic(err)
+	}
+	cbc, err := modes.NewCBC(cipher, iv)
+	if err != nil {
+		panic(err)
+	}
+
+	padded, _ := padding.PKCS7Padding(plaintext, cipher.BlockSize())
+	ciphertext, err := cbc.Encrypt(padded)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Print(dump.Blocks(ciphertext, cipher.BlockSize()))
+
+	decrypted, err := cbc.Decrypt(ciphertext)
+	if err != nil {
+		panic(err)
+	}
+	decrypted, err = padding.PKCS7UnPadding(decrypted)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s\n", decrypted)
+	// Output:
+	// 块 1 [0000]: c5d5ed01a0ac098cb9cfabb9aa3b9351
+	// 块 2 [0010]: e3a023622fa6def46c0e3fa183744011
+	// 块 3 [0020]: e7212af397b71fea8c535af5a669675d
+	// 块 4 [0030]: f1ea993ddc53fc32fb21638b719b7d60
+	// 这是SM4加密算法的测试文本，长度超过一个块。
+}
+
+// 本例展示CTR模式：无需填充，密文与明文等长，解密时需使用相同的初始计数器
+func Example_ctr() {
+	key, _ := hex.DecodeString("0123456789ABCDEFFEDCBA9876543210")
+	counter := []byte("1234567890123456")
+	plaintext := []byte("这是SM4加密算法的测试文本，长度超过一个块。")
+
+	cipher, err := sm4.New(key)
+	if err != nil {
+		panic(err)
+	}
+	ctr, err := modes.NewCTR(cipher, counter)
+	if err != nil {
+		panic(err)
+	}
+	ciphertext, err := ctr.Encrypt(plaintext)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(dump.Hex(ciphertext, 16))
+
+	ctr, err = modes.NewCTR(cipher, counter)
+	if err != nil {
+		panic(err)
+	}
+	decrypted, err := ctr.Decrypt(ciphertext)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s\n", decrypted)
+	// Output:
+	// bb1571e105b0ac7e89c008dbbdc73540 94ae47fca617784d671c683b9a83c3df 3ae5c343a75335a9fbe61d6f098d8a7c cb10838640ae5e63d9e39ddf2bd691
+	// 这是SM4加密算法的测试文本，长度超过一个块。
+}
@@ -2,8 +2,10 @@ package sm4
 
 import (
 	"encoding/binary"
-	"errors"
 
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/secure"
 	"github.com/laenix/gsc/sm4/internal"
 )
 
@@ -14,22 +16,31 @@ const (
 	KeySize = 16
 )
 
-// SM4 结构体定义SM4密码
+// SM4 结构体定义SM4密码，创建后只读，可并发使用
 type SM4 struct {
-	roundKeys [32]uint32 // 轮密钥
+	roundKeys    [32]uint32 // 轮密钥
+	decRoundKeys [32]uint32 // 逆序的轮密钥，解密时使用
 }
 
 // 错误定义
 var (
-	ErrInvalidKeySize   = errors.New("sm4: 密钥长度必须是16字节（128位）")
-	ErrInvalidBlockSize = errors.New("sm4: 数据块长度必须是16字节（128位）")
+	ErrInvalidKeySize   = gscerr.New(gscerr.ErrKeySize, "sm4: key must be 16 bytes (128 bits)")
+	ErrInvalidBlockSize = gscerr.New(gscerr.ErrBlockSize, "sm4: block must be 16 bytes (128 bits)")
 )
 
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidKeySize:   "sm4: 密钥长度必须是16字节（128位）",
+		ErrInvalidBlockSize: "sm4: 数据块长度必须是16字节（128位）",
+	})
+}
+
 // New 创建一个新的SM4实例
 func New(key []byte) (*SM4, error) {
 	// 验证密钥长度
 	if len(key) != KeySize {
-		return nil, ErrInvalidKeySize
+		return nil, gscerr.KeySize(ErrInvalidKeySize, "SM4", len(key), KeySize)
 	}
 
 	// 创建SM4实例
@@ -37,40 +48,36 @@ func New(key []byte) (*SM4, error) {
 
 	// 生成轮密钥
 	sm4.expandKey(key)
+	for i, rk := range sm4.roundKeys {
+		sm4.decRoundKeys[31-i] = rk
+	}
 
 	return sm4, nil
 }
 
+// KeySize 返回密钥长度（字节），SM4固定为16
+func (s *SM4) KeySize() int {
+	return KeySize
+}
+
 // BlockSize 返回区块大小
 func (s *SM4) BlockSize() int {
 	return BlockSize
 }
 
+// Wipe 清零轮密钥，之后实例不能再使用
+func (s *SM4) Wipe() {
+	secure.Wipe(s.roundKeys[:])
+	secure.Wipe(s.decRoundKeys[:])
+}
+
 // Encrypt 加密单个区块（16字节）
 func (s *SM4) Encrypt(plaintext []byte) ([]byte, error) {
 	if len(plaintext) != BlockSize {
 		return nil, ErrInvalidBlockSize
 	}
-
-	// 将输入转为4个32位字
-	X := make([]uint32, 4)
-	X[0] = binary.BigEndian.Uint32(plaintext[0:4])
-	X[1] = binary.BigEndian.Uint32(plaintext[4:8])
-	X[2] = binary.BigEndian.Uint32(plaintext[8:12])
-	X[3] = binary.BigEndian.Uint32(plaintext[12:16])
-
-	// 32轮加密
-	for i := 0; i < 32; i++ {
-		X[0], X[1], X[2], X[3] = X[1], X[2], X[3], X[0]^feistelFunction(X[1]^X[2]^X[3]^s.roundKeys[i])
-	}
-
-	// 反序输出结果
 	result := make([]byte, BlockSize)
-	binary.BigEndian.PutUint32(result[0:4], X[3])
-	binary.BigEndian.PutUint32(result[4:8], X[2])
-	binary.BigEndian.PutUint32(result[8:12], X[1])
-	binary.BigEndian.PutUint32(result[12:16], X[0])
-
+	cryptBlock(&s.roundKeys, result, plaintext)
 	return result, nil
 }
 
@@ -79,27 +86,30 @@ func (s *SM4) Decrypt(ciphertext []byte) ([]byte, error) {
 	if len(ciphertext) != BlockSize {
 		return nil, ErrInvalidBlockSize
 	}
+	// 解密与加密结构相同，只是使用逆序轮密钥
+	result := make([]byte, BlockSize)
+	cryptBlock(&s.decRoundKeys, result, ciphertext)
+	return result, nil
+}
 
+// cryptBlock 使用给定顺序的轮密钥处理一个块
+func cryptBlock(rk *[32]uint32, dst, src []byte) {
 	// 将输入转为4个32位字
-	X := make([]uint32, 4)
-	X[0] = binary.BigEndian.Uint32(ciphertext[0:4])
-	X[1] = binary.BigEndian.Uint32(ciphertext[4:8])
-	X[2] = binary.BigEndian.Uint32(ciphertext[8:12])
-	X[3] = binary.BigEndian.Uint32(ciphertext[12:16])
-
-	// 32轮解密（使用逆序轮密钥）
-	for i := 31; i >= 0; i-- {
-		X[0], X[1], X[2], X[3] = X[1], X[2], X[3], X[0]^feistelFunction(X[1]^X[2]^X[3]^s.roundKeys[i])
+	x0 := binary.BigEndian.Uint32(src[0:4])
+	x1 := binary.BigEndian.Uint32(src[4:8])
+	x2 := binary.BigEndian.Uint32(src[8:12])
+	x3 := binary.BigEndian.Uint32(src[12:16])
+
+	// 32轮迭代
+	for i := 0; i < 32; i++ {
+		x0, x1, x2, x3 = x1, x2, x3, x0^feistelFunction(x1^x2^x3^rk[i])
 	}
 
 	// 反序输出结果
-	result := make([]byte, BlockSize)
-	binary.BigEndian.PutUint32(result[0:4], X[3])
-	binary.BigEndian.PutUint32(result[4:8], X[2])
-	binary.BigEndian.PutUint32(result[8:12], X[1])
-	binary.BigEndian.PutUint32(result[12:16], X[0])
-
-	return result, nil
+	binary.BigEndian.PutUint32(dst[0:4], x3)
+	binary.BigEndian.PutUint32(dst[4:8], x2)
+	binary.BigEndian.PutUint32(dst[8:12], x1)
+	binary.BigEndian.PutUint32(dst[12:16], x0)
 }
 
 // expandKey 生成轮密钥
@@ -144,21 +154,12 @@ func keyTransform(input uint32) uint32 {
 }
 
 // feistelFunction 为SM4的T变换（加密过程中使用）
+// 非线性变换τ与线性变换L合并为每字节一次查表，见internal.SBox0~SBox3
 func feistelFunction(input uint32) uint32 {
-	// 非线性变换τ（S盒替换）
-	a := byte(input >> 24)
-	b := byte(input >> 16)
-	c := byte(input >> 8)
-	d := byte(input)
-
-	a = internal.SBOX[a]
-	b = internal.SBOX[b]
-	c = internal.SBOX[c]
-	d = internal.SBOX[d]
-
-	// 线性变换L
-	ret := uint32(a)<<24 | uint32(b)<<16 | uint32(c)<<8 | uint32(d)
-	return ret ^ rotateLeft(ret, 2) ^ rotateLeft(ret, 10) ^ rotateLeft(ret, 18) ^ rotateLeft(ret, 24)
+	return internal.SBox3[input>>24] ^
+		internal.SBox2[byte(input>>16)] ^
+		internal.SBox1[byte(input>>8)] ^
+		internal.SBox0[byte(input)]
 }
 
 // rotateLeft 循环左移
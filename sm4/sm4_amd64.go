@@ -0,0 +1,14 @@
+//go:build !purego
+
+package sm4
+
+import "github.com/laenix/gsc/internal/cpu"
+
+// supportsAESNI 记录能否借助AES-NI计算SM4的S盒（还需要SSSE3的PSHUFB）
+var supportsAESNI = cpu.X86.HasAES && cpu.X86.HasSSSE3
+
+// cryptBlocks4Asm 用rk中的32个轮密钥并行处理4个块（64字节）
+// 加密和解密只是轮密钥顺序不同
+//
+//go:noescape
+func cryptBlocks4Asm(rk *uint32, dst, src *byte)
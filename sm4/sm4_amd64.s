@@ -0,0 +1,168 @@
+//go:build !purego
+
+#include "textflag.h"
+
+// SM4的S盒与AES的S盒都由有限域求逆加仿射变换构成，两个有限域同构，因此
+// S_SM4(x) = A2(S_AES(A1(x)))，A1、A2为GF(2)上的仿射变换。
+// 仿射变换按高低4位拆成两次PSHUFB查表，S_AES由密钥为0的AESENCLAST完成，
+// 事先用逆ShiftRows打乱字节以抵消AESENCLAST中的ShiftRows。
+// 4个块并行处理：转置后每个XMM寄存器保存4个块的同一个字
+
+// A1的低4位、高4位查表（低4位的表包含常数项）
+DATA m1Low<>+0x00(SB)/8, $0x078B37BB820EB23E
+DATA m1Low<>+0x08(SB)/8, $0x9814A8241D912DA1
+GLOBL m1Low<>(SB), (NOPTR+RODATA), $16
+
+DATA m1High<>+0x00(SB)/8, $0x37EB19C5F22EDC00
+DATA m1High<>+0x08(SB)/8, $0x3FE311CDFA26D408
+GLOBL m1High<>(SB), (NOPTR+RODATA), $16
+
+// A2的低4位、高4位查表
+DATA m2Low<>+0x00(SB)/8, $0x2098EA521EA6D46C
+DATA m2Low<>+0x08(SB)/8, $0x47FF8D3579C1B30B
+GLOBL m2Low<>(SB), (NOPTR+RODATA), $16
+
+DATA m2High<>+0x00(SB)/8, $0x2DCD7D9DB050E000
+DATA m2High<>+0x08(SB)/8, $0xED0DBD5D709020C0
+GLOBL m2High<>(SB), (NOPTR+RODATA), $16
+
+DATA invShiftRows<>+0x00(SB)/8, $0x0B0E0104070A0D00
+DATA invShiftRows<>+0x08(SB)/8, $0x0306090C0F020508
+GLOBL invShiftRows<>(SB), (NOPTR+RODATA), $16
+
+// 每个32位字循环左移8、16、24位的字节重排
+DATA rol8<>+0x00(SB)/8, $0x0605040702010003
+DATA rol8<>+0x08(SB)/8, $0x0E0D0C0F0A09080B
+GLOBL rol8<>(SB), (NOPTR+RODATA), $16
+
+DATA rol16<>+0x00(SB)/8, $0x0504070601000302
+DATA rol16<>+0x08(SB)/8, $0x0D0C0F0E09080B0A
+GLOBL rol16<>(SB), (NOPTR+RODATA), $16
+
+DATA rol24<>+0x00(SB)/8, $0x0407060500030201
+DATA rol24<>+0x08(SB)/8, $0x0C0F0E0D080B0A09
+GLOBL rol24<>(SB), (NOPTR+RODATA), $16
+
+// 每个32位字的字节序翻转
+DATA bswap<>+0x00(SB)/8, $0x0405060700010203
+DATA bswap<>+0x08(SB)/8, $0x0C0D0E0F08090A0B
+GLOBL bswap<>(SB), (NOPTR+RODATA), $16
+
+DATA nibbleMask<>+0x00(SB)/8, $0x0F0F0F0F0F0F0F0F
+DATA nibbleMask<>+0x08(SB)/8, $0x0F0F0F0F0F0F0F0F
+GLOBL nibbleMask<>(SB), (NOPTR+RODATA), $16
+
+// 4×4个32位字转置，t0、t1为临时寄存器
+#define TRANSPOSE(r0, r1, r2, r3, t0, t1) \
+	MOVOU      r0, t0; \
+	PUNPCKHLQ  r1, t0; \
+	PUNPCKLLQ  r1, r0; \
+	MOVOU      r2, t1; \
+	PUNPCKLLQ  r3, t1; \
+	PUNPCKHLQ  r3, r2; \
+	MOVOU      r0, r1; \
+	PUNPCKHQDQ t1, r1; \
+	PUNPCKLQDQ t1, r0; \
+	MOVOU      t0, r3; \
+	PUNPCKHQDQ r2, r3; \
+	PUNPCKLQDQ r2, t0; \
+	MOVOU      t0, r2
+
+// x中每个字节过SM4的S盒，使用X5、X6
+#define SBOX(x) \
+	MOVOU      x, X5; \
+	PAND       X7, X5; \
+	MOVOU      X8, X6; \
+	PSHUFB     X5, X6; \
+	PSRLQ      $4, x; \
+	PAND       X7, x; \
+	MOVOU      X9, X5; \
+	PSHUFB     x, X5; \
+	PXOR       X6, X5; \
+	PSHUFB     X12, X5; \
+	AESENCLAST X15, X5; \
+	MOVOU      X5, X6; \
+	PAND       X7, X6; \
+	MOVOU      X10, x; \
+	PSHUFB     X6, x; \
+	PSRLQ      $4, X5; \
+	PAND       X7, X5; \
+	MOVOU      X11, X6; \
+	PSHUFB     X5, X6; \
+	PXOR       X6, x
+
+// a ^= L(x)，L(x) = x ⊕ (x<<<24) ⊕ ((x ⊕ (x<<<8) ⊕ (x<<<16))<<<2)，使用X5、X6
+#define XOR_L(x, a) \
+	MOVOU  x, X5; \
+	PSHUFB X13, X5; \
+	MOVOU  x, X6; \
+	PSHUFB X14, X6; \
+	PXOR   x, X5; \
+	PXOR   X6, X5; \
+	MOVOU  X5, X6; \
+	PSLLL  $2, X5; \
+	PSRLL  $30, X6; \
+	PXOR   X6, X5; \
+	PXOR   X5, a; \
+	PXOR   x, a; \
+	PSHUFB rol24<>(SB), x; \
+	PXOR   x, a
+
+// 一轮：a ^= T(b ⊕ c ⊕ d ⊕ rk)
+#define ROUND(index, a, b, c, d) \
+	MOVL    (index*4)(AX), X4; \
+	PSHUFD  $0, X4, X4; \
+	PXOR    b, X4; \
+	PXOR    c, X4; \
+	PXOR    d, X4; \
+	SBOX(X4); \
+	XOR_L(X4, a)
+
+// func cryptBlocks4Asm(rk *uint32, dst, src *byte)
+TEXT ·cryptBlocks4Asm(SB), NOSPLIT, $0-24
+	MOVQ rk+0(FP), AX
+	MOVQ dst+8(FP), BX
+	MOVQ src+16(FP), CX
+
+	MOVOU nibbleMask<>(SB), X7
+	MOVOU m1Low<>(SB), X8
+	MOVOU m1High<>(SB), X9
+	MOVOU m2Low<>(SB), X10
+	MOVOU m2High<>(SB), X11
+	MOVOU invShiftRows<>(SB), X12
+	MOVOU rol8<>(SB), X13
+	MOVOU rol16<>(SB), X14
+	PXOR  X15, X15
+
+	MOVOU  0(CX), X0
+	MOVOU  16(CX), X1
+	MOVOU  32(CX), X2
+	MOVOU  48(CX), X3
+	PSHUFB bswap<>(SB), X0
+	PSHUFB bswap<>(SB), X1
+	PSHUFB bswap<>(SB), X2
+	PSHUFB bswap<>(SB), X3
+	TRANSPOSE(X0, X1, X2, X3, X4, X5)
+
+	MOVQ $8, DX
+
+loop:
+	ROUND(0, X0, X1, X2, X3)
+	ROUND(1, X1, X2, X3, X0)
+	ROUND(2, X2, X3, X0, X1)
+	ROUND(3, X3, X0, X1, X2)
+	ADDQ $16, AX
+	DECQ DX
+	JNZ  loop
+
+	// 输出按X3、X2、X1、X0的顺序（反序变换R）
+	TRANSPOSE(X3, X2, X1, X0, X4, X5)
+	PSHUFB bswap<>(SB), X3
+	PSHUFB bswap<>(SB), X2
+	PSHUFB bswap<>(SB), X1
+	PSHUFB bswap<>(SB), X0
+	MOVOU  X3, 0(BX)
+	MOVOU  X2, 16(BX)
+	MOVOU  X1, 32(BX)
+	MOVOU  X0, 48(BX)
+	RET
@@ -0,0 +1,10 @@
+//go:build !amd64 || purego
+
+package sm4
+
+// 没有硬件加速实现的平台只使用通用实现
+const supportsAESNI = false
+
+func cryptBlocks4Asm(rk *uint32, dst, src *byte) {
+	panic("sm4: hardware acceleration is not available on this platform")
+}
@@ -3,7 +3,10 @@ package sm4
 import (
 	"bytes"
 	"encoding/hex"
+	"math/bits"
 	"testing"
+
+	"github.com/laenix/gsc/sm4/internal"
 )
 
 // 测试加密和解密的正确性
@@ -52,7 +55,7 @@ func TestVectors(t *testing.T) {
 	// 测试向量 2
 	key2, _ := hex.DecodeString("FEDCBA98765432100123456789ABCDEF")
 	plaintext2, _ := hex.DecodeString("FEDCBA98765432100123456789ABCDEF")
-	expected2, _ := hex.DecodeString("9CAD22E0676AB60CEDE9CFADC30B3D89")
+	expected2, _ := hex.DecodeString("FCAD24D11BE5ED6F508568719EAB1462")
 
 	cipher2, _ := New(key2)
 	ciphertext2, _ := cipher2.Encrypt(plaintext2)
@@ -116,3 +119,89 @@ func TestMultipleBlocks(t *testing.T) {
 		}
 	}
 }
+
+// 测试导出的常量表与标准一致，且修改副本不影响内部实现
+func TestStandardTables(t *testing.T) {
+	tables := StandardTables()
+	if tables.SBox[0] != 0xd6 || tables.FK[0] != 0xa3b1bac6 || tables.CK[0] != 0x00070e15 {
+		t.Fatalf("常量表与GB/T 32907不一致")
+	}
+
+	tables.SBox[0] = 0
+	if StandardTables().SBox[0] != 0xd6 {
+		t.Fatalf("修改副本影响了内部S盒")
+	}
+}
+
+// 测试批量接口与逐块处理的结果相同，覆盖4块并行路径、尾部块和原地处理
+func TestEncryptBlocks(t *testing.T) {
+	key, _ := hex.DecodeString("0123456789ABCDEFFEDCBA9876543210")
+	cipher, _ := New(key)
+
+	for _, blocks := range []int{0, 1, 3, 4, 5, 8, 11} {
+		src := make([]byte, blocks*BlockSize)
+		for i := range src {
+			src[i] = byte(i * 7)
+		}
+		want := make([]byte, 0, len(src))
+		for i := 0; i < len(src); i += BlockSize {
+			block, _ := cipher.Encrypt(src[i : i+BlockSize])
+			want = append(want, block...)
+		}
+
+		buf := bytes.Clone(src)
+		if err := cipher.EncryptBlocks(buf, buf); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf, want) {
+			t.Fatalf("%d个块的批量加密结果不匹配", blocks)
+		}
+		if err := cipher.DecryptBlocks(buf, buf); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf, src) {
+			t.Fatalf("%d个块的批量解密结果不匹配", blocks)
+		}
+	}
+
+	if err := cipher.EncryptBlocks(make([]byte, 32), make([]byte, 17)); err != ErrInvalidBlockSize {
+		t.Fatalf("长度不是块大小的整数倍时应返回ErrInvalidBlockSize，实际: %v", err)
+	}
+	if err := cipher.EncryptBlocks(make([]byte, 16), make([]byte, 32)); err != ErrInvalidBlockSize {
+		t.Fatalf("dst过短时应返回ErrInvalidBlockSize，实际: %v", err)
+	}
+}
+
+func BenchmarkEncryptBlocks(b *testing.B) {
+	cipher, _ := New(make([]byte, KeySize))
+	buf := make([]byte, 4096)
+	b.SetBytes(int64(len(buf)))
+	for b.Loop() {
+		cipher.EncryptBlocks(buf, buf)
+	}
+}
+
+// 测试合并后的T表与逐字节S盒替换加线性变换L的结果一致
+func TestFeistelTables(t *testing.T) {
+	reference := func(x uint32) uint32 {
+		x = uint32(internal.SBOX[x>>24])<<24 | uint32(internal.SBOX[byte(x>>16)])<<16 |
+			uint32(internal.SBOX[byte(x>>8)])<<8 | uint32(internal.SBOX[byte(x)])
+		return x ^ bits.RotateLeft32(x, 2) ^ bits.RotateLeft32(x, 10) ^ bits.RotateLeft32(x, 18) ^ bits.RotateLeft32(x, 24)
+	}
+	for i := range 256 {
+		for _, x := range []uint32{uint32(i), uint32(i) << 8, uint32(i) << 16, uint32(i) << 24, uint32(i) * 0x01010101} {
+			if got, want := feistelFunction(x), reference(x); got != want {
+				t.Fatalf("T(%08x) = %08x，期望 %08x", x, got, want)
+			}
+		}
+	}
+}
+
+// 测试Wipe清零加密和解密轮密钥
+func TestWipe(t *testing.T) {
+	c, _ := New(bytes.Repeat([]byte{0x5a}, KeySize))
+	c.Wipe()
+	if c.roundKeys != [32]uint32{} || c.decRoundKeys != [32]uint32{} {
+		t.Fatal("Wipe后轮密钥应全为0")
+	}
+}
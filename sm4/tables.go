@@ -0,0 +1,19 @@
+package sm4
+
+import "github.com/laenix/gsc/sm4/internal"
+
+// Tables 是SM4标准常量表的只读副本，供分析和教学工具使用
+type Tables struct {
+	SBox [256]byte
+	FK   [4]uint32
+	CK   [32]uint32
+}
+
+// StandardTables 返回SM4标准常量表的副本，修改返回值不会影响加密实现
+func StandardTables() Tables {
+	return Tables{
+		SBox: internal.SBOX,
+		FK:   internal.FK,
+		CK:   internal.CK,
+	}
+}
@@ -36,7 +36,10 @@ func TestSM4ECBVerification(t *testing.T) {
 	// ECB模式加密测试
 	t.Run("ECBEncryptionCheck", func(t *testing.T) {
 		// 使用ECB模式
-		ecb := modes.NewECB(cipher)
+		ecb, err := modes.NewECB(cipher)
+		if err != nil {
+			t.Fatal(err)
+		}
 
 		// PKCS7填充
 		paddedPlaintext, _ := padding.PKCS7Padding(plaintext, cipher.BlockSize())
@@ -0,0 +1,228 @@
+package gsc
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/modes"
+)
+
+const (
+	// StreamVersion 是当前分块流格式版本
+	StreamVersion = 1
+	// DefaultStreamChunkSize 是默认的明文分块大小（字节）
+	DefaultStreamChunkSize = 64 << 10
+	// MaxStreamChunkSize 是允许的最大明文分块大小，限制解密端的内存占用
+	MaxStreamChunkSize = 16 << 20
+	// 流魔数
+	streamMagic = "GSCS"
+	// nonce前缀长度：nonce = 前缀(7) || 分块序号(4) || 末块标志(1)
+	streamPrefixSize = 7
+	// 每个分块的认证标签长度
+	streamTagSize = 16
+)
+
+// 错误定义
+var (
+	ErrInvalidChunkSize = gscerr.New(gscerr.ErrParameter, "gsc: invalid chunk size")
+	ErrStreamTooLong    = gscerr.New(gscerr.ErrMisuse, "gsc: stream exceeds the maximum number of chunks")
+	ErrStreamClosed     = gscerr.New(gscerr.ErrMisuse, "gsc: stream already closed")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidChunkSize: "gsc: 分块大小无效",
+		ErrStreamTooLong:    "gsc: 流的分块数量超出上限",
+		ErrStreamClosed:     "gsc: 流已关闭",
+	})
+}
+
+// StreamWriter 以STREAM构造（Hoang等，2015）分块加密任意长度的数据
+// 每个分块独立使用AEAD加密，nonce由随机前缀、分块序号和末块标志组成，
+// 因此分块被删除、重排或截断都会在解密时被发现。内存占用只与分块大小有关。
+// 分块加密由modes.AEADWriter完成，StreamWriter只负责头部和密钥上下文
+type StreamWriter struct {
+	w *modes.AEADWriter
+}
+
+// NewStreamWriter 创建一个向w写入加密流的StreamWriter，分块大小为DefaultStreamChunkSize
+// 头部在创建时立即写入w；purpose与aad的含义与Seal相同。
+// 写完后必须调用Close输出末块，否则解密端会将流视为被截断
+func NewStreamWriter(alg Algorithm, key []byte, purpose string, w io.Writer, aad []byte) (*StreamWriter, error) {
+	return NewStreamWriterSize(alg, key, purpose, w, aad, DefaultStreamChunkSize)
+}
+
+// NewStreamWriterSize 与NewStreamWriter相同，但使用指定的明文分块大小
+func NewStreamWriterSize(alg Algorithm, key []byte, purpose string, w io.Writer, aad []byte, chunkSize int) (*StreamWriter, error) {
+	if chunkSize <= 0 || chunkSize > MaxStreamChunkSize {
+		return nil, ErrInvalidChunkSize
+	}
+	aead, err := alg.newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	context := streamContext(alg, purpose)
+	if len(context) > maxContextSize {
+		return nil, ErrContextTooLong
+	}
+
+	prefix := make([]byte, streamPrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, err
+	}
+
+	header := marshalStreamHeader(alg, KeyID(key), chunkSize, context, prefix)
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	sw, err := modes.NewAEADWriter(aead, prefix, w, contextAAD(header, aad), chunkSize)
+	if err != nil {
+		return nil, err
+	}
+	return &StreamWriter{w: sw}, nil
+}
+
+// Write 缓存并加密数据，每凑满一个分块且后续还有数据时写出该分块
+func (s *StreamWriter) Write(p []byte) (int, error) {
+	n, err := s.w.Write(p)
+	return n, streamError(err)
+}
+
+// Close 加密并写出末块，不会关闭底层的io.Writer
+func (s *StreamWriter) Close() error {
+	return streamError(s.w.Close())
+}
+
+// StreamReader 解密StreamWriter生成的加密流
+// 每个分块在通过认证后才会返回其明文；只有读到认证通过的末块时Read才返回io.EOF，
+// 流被截断、分块被篡改或重排时返回modes.ErrAuthFailed
+type StreamReader struct {
+	r *modes.AEADReader
+}
+
+// NewStreamReader 读取并校验流头部，返回从r解密数据的StreamReader
+// 算法由头部决定；purpose必须与加密时一致，否则返回ErrContextMismatch
+func NewStreamReader(key []byte, purpose string, r io.Reader, aad []byte) (*StreamReader, error) {
+	header, h, err := readStreamHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	if h.context != streamContext(h.alg, purpose) {
+		return nil, ErrContextMismatch
+	}
+	aead, err := h.alg.newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	sr, err := modes.NewAEADReader(aead, h.prefix, r, contextAAD(header, aad), h.chunkSize)
+	if err != nil {
+		return nil, err
+	}
+	return &StreamReader{r: sr}, nil
+}
+
+// Read 返回已认证的明文
+func (s *StreamReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	return n, streamError(err)
+}
+
+// streamError 将modes分块流的错误换成本包的错误
+func streamError(err error) error {
+	switch err {
+	case modes.ErrDataTooLarge:
+		return ErrStreamTooLong
+	case io.ErrClosedPipe:
+		return ErrStreamClosed
+	}
+	return err
+}
+
+// streamHeader 是解析后的流头部
+type streamHeader struct {
+	alg       Algorithm
+	chunkSize int
+	context   string
+	prefix    []byte
+}
+
+// streamContext 返回分块流的规范上下文字符串
+func streamContext(alg Algorithm, purpose string) string {
+	return fmt.Sprintf("gsc/stream/v%d/%s/%s", StreamVersion, alg, purpose)
+}
+
+// marshalStreamHeader 编码流头部：
+// 魔数 || 版本 || 算法 || 密钥标识长度(1) || 密钥标识 || 分块大小(4) || 上下文长度(2) || 上下文 || nonce前缀
+func marshalStreamHeader(alg Algorithm, keyID []byte, chunkSize int, context string, prefix []byte) []byte {
+	header := make([]byte, 0, 4+3+len(keyID)+6+len(context)+len(prefix))
+	header = append(header, streamMagic...)
+	header = append(header, StreamVersion, byte(alg))
+	header = append(header, byte(len(keyID)))
+	header = append(header, keyID...)
+	header = binary.BigEndian.AppendUint32(header, uint32(chunkSize))
+	header = binary.BigEndian.AppendUint16(header, uint16(len(context)))
+	header = append(header, context...)
+	return append(header, prefix...)
+}
+
+// readStreamHeader 从r读取流头部，返回原始头部字节和解析结果
+func readStreamHeader(r io.Reader) ([]byte, *streamHeader, error) {
+	header := make([]byte, 7)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, nil, ErrInvalidEnvelope
+	}
+	if !bytes.Equal(header[:4], []byte(streamMagic)) {
+		return nil, nil, ErrInvalidEnvelope
+	}
+	if header[4] != StreamVersion {
+		return nil, nil, ErrUnsupportedVersion
+	}
+	h := &streamHeader{alg: Algorithm(header[5])}
+	if h.alg.KeySize() == 0 {
+		return nil, nil, ErrUnsupportedAlgorithm
+	}
+
+	// 读取密钥标识、分块大小和上下文长度
+	keyIDLen := int(header[6])
+	header, err := readMore(r, header, keyIDLen+4+2)
+	if err != nil {
+		return nil, nil, err
+	}
+	// 密钥标识供按标识查找密钥的调用方使用，这里只需跳过
+	off := 7 + keyIDLen
+	chunkSize := binary.BigEndian.Uint32(header[off:])
+	if chunkSize == 0 || chunkSize > MaxStreamChunkSize {
+		return nil, nil, ErrInvalidChunkSize
+	}
+	h.chunkSize = int(chunkSize)
+	off += 4
+	contextLen := int(binary.BigEndian.Uint16(header[off:]))
+	off += 2
+
+	// 读取上下文和nonce前缀
+	if header, err = readMore(r, header, contextLen+streamPrefixSize); err != nil {
+		return nil, nil, err
+	}
+	h.context = string(header[off : off+contextLen])
+	h.prefix = header[off+contextLen:]
+	return header, h, nil
+}
+
+// readMore 从r再读取n个字节追加到buf之后
+func readMore(r io.Reader, buf []byte, n int) ([]byte, error) {
+	start := len(buf)
+	buf = append(buf, make([]byte, n)...)
+	if _, err := io.ReadFull(r, buf[start:]); err != nil {
+		return nil, ErrInvalidEnvelope
+	}
+	return buf, nil
+}
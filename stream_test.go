@@ -0,0 +1,146 @@
+package gsc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/laenix/gsc/modes"
+)
+
+// sealStream 使用小分块加密data，返回完整的加密流
+func sealStream(t *testing.T, key, data []byte, chunkSize int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewStreamWriterSize(SM4GCM, key, "backup", &buf, []byte("aad"), chunkSize)
+	if err != nil {
+		t.Fatalf("创建StreamWriter失败: %v", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("关闭失败: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// openStream 解密加密流并返回全部明文
+func openStream(key, stream []byte) ([]byte, error) {
+	r, err := NewStreamReader(key, "backup", bytes.NewReader(stream), []byte("aad"))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 16)
+	const chunkSize = 16
+
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3 * chunkSize, 100} {
+		data := bytes.Repeat([]byte{0xa5}, size)
+		stream := sealStream(t, key, data, chunkSize)
+
+		got, err := openStream(key, stream)
+		if err != nil {
+			t.Fatalf("长度%d: 解密失败: %v", size, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatalf("长度%d: 解密结果不匹配", size)
+		}
+
+		// 逐字节读取时结果相同
+		r, err := NewStreamReader(key, "backup", iotest.OneByteReader(bytes.NewReader(stream)), []byte("aad"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := io.ReadAll(r); err != nil || !bytes.Equal(got, data) {
+			t.Fatalf("长度%d: 逐字节读取失败: %v", size, err)
+		}
+	}
+}
+
+// 测试分多次写入与一次写入得到的明文相同
+func TestStreamMultipleWrites(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 16)
+	var buf bytes.Buffer
+	w, err := NewStreamWriterSize(SM4GCM, key, "backup", &buf, []byte("aad"), 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []byte
+	for i := range 20 {
+		part := bytes.Repeat([]byte{byte(i)}, i)
+		want = append(want, part...)
+		if _, err := w.Write(part); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte{1}); !errors.Is(err, ErrStreamClosed) {
+		t.Fatalf("期望ErrStreamClosed，实际: %v", err)
+	}
+
+	got, err := openStream(key, buf.Bytes())
+	if err != nil || !bytes.Equal(got, want) {
+		t.Fatalf("解密失败: %v", err)
+	}
+}
+
+// 测试截断、重排、篡改以及上下文不一致都会被发现
+func TestStreamTampering(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 16)
+	const chunkSize = 16
+	sealedSize := chunkSize + streamTagSize
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), 3)
+	stream := sealStream(t, key, data, chunkSize)
+	// 明文恰好是3个整块，第3块即为末块
+	headerLen := len(stream) - 3*sealedSize
+	chunk := func(i int) []byte {
+		return stream[headerLen+i*sealedSize : headerLen+(i+1)*sealedSize]
+	}
+	header := stream[:headerLen]
+
+	cases := map[string][]byte{
+		// 在分块边界处截断，剩余的最后一块不是以末块身份加密的
+		"截断末块": stream[:headerLen+2*sealedSize],
+		"截断分块": stream[:len(stream)-1],
+		"重排分块": bytes.Join([][]byte{header, chunk(1), chunk(0), chunk(2)}, nil),
+		"篡改密文": func() []byte {
+			tampered := bytes.Clone(stream)
+			tampered[headerLen+5] ^= 0x01
+			return tampered
+		}(),
+	}
+	for name, tampered := range cases {
+		if _, err := openStream(key, tampered); !errors.Is(err, modes.ErrAuthFailed) {
+			t.Errorf("%s: 期望ErrAuthFailed，实际: %v", name, err)
+		}
+	}
+
+	if _, err := NewStreamReader(key, "other", bytes.NewReader(stream), []byte("aad")); !errors.Is(err, ErrContextMismatch) {
+		t.Errorf("期望ErrContextMismatch，实际: %v", err)
+	}
+	r, err := NewStreamReader(key, "backup", bytes.NewReader(stream), []byte("other"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(r); !errors.Is(err, modes.ErrAuthFailed) {
+		t.Errorf("AAD不一致时期望ErrAuthFailed，实际: %v", err)
+	}
+}
+
+func TestStreamInvalidChunkSize(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 16)
+	for _, size := range []int{0, -1, MaxStreamChunkSize + 1} {
+		if _, err := NewStreamWriterSize(SM4GCM, key, "backup", io.Discard, nil, size); !errors.Is(err, ErrInvalidChunkSize) {
+			t.Errorf("分块大小%d: 期望ErrInvalidChunkSize，实际: %v", size, err)
+		}
+	}
+}
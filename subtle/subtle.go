@@ -0,0 +1,57 @@
+// Package subtle 提供常量时间的比较、选择和复制函数
+//
+// 函数的执行时间只取决于输入长度，不取决于输入内容，用于校验认证标签、
+// 比较哈希值等需要避免计时侧信道的场合。各函数的约定与标准库crypto/subtle相同，
+// 实现保持简单直观，便于对照学习
+package subtle
+
+// ConstantTimeCompare 比较x和y的内容，相等时返回1，否则返回0
+// 执行时间只取决于切片长度；长度不同时立即返回0
+func ConstantTimeCompare(x, y []byte) int {
+	if len(x) != len(y) {
+		return 0
+	}
+
+	// 累积所有字节的差异，中途不提前退出
+	var v byte
+	for i := range x {
+		v |= x[i] ^ y[i]
+	}
+	return ConstantTimeByteEq(v, 0)
+}
+
+// ConstantTimeSelect v为1时返回x，v为0时返回y，v取其他值时结果未定义
+func ConstantTimeSelect(v, x, y int) int {
+	return ^(v-1)&x | (v-1)&y
+}
+
+// ConstantTimeCopy v为1时将y复制到x，v为0时x保持不变，v取其他值时结果未定义
+// x和y长度不同时panic
+func ConstantTimeCopy(v int, x, y []byte) {
+	if len(x) != len(y) {
+		panic("subtle: slices have different lengths")
+	}
+
+	xmask := byte(v - 1)
+	ymask := byte(^(v - 1))
+	for i := range x {
+		x[i] = x[i]&xmask | y[i]&ymask
+	}
+}
+
+// ConstantTimeByteEq x等于y时返回1，否则返回0
+func ConstantTimeByteEq(x, y uint8) int {
+	// x^y为0时减1下溢，最高位变为1
+	return int((uint32(x^y) - 1) >> 31)
+}
+
+// ConstantTimeEq x等于y时返回1，否则返回0
+func ConstantTimeEq(x, y int32) int {
+	return int((uint64(uint32(x^y)) - 1) >> 63)
+}
+
+// ConstantTimeLessOrEq x <= y时返回1，否则返回0，x和y必须在[0, 2^31-1]范围内
+func ConstantTimeLessOrEq(x, y int) int {
+	x32, y32 := int32(x), int32(y)
+	return int(((x32 - y32 - 1) >> 31) & 1)
+}
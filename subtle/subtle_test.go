@@ -0,0 +1,79 @@
+package subtle
+
+import (
+	"bytes"
+	stdsubtle "crypto/subtle"
+	"math/rand/v2"
+	"testing"
+)
+
+// 测试各函数与标准库crypto/subtle的结果一致
+func TestAgainstStdlib(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for range 1000 {
+		n := r.IntN(40)
+		x := make([]byte, n)
+		for i := range x {
+			x[i] = byte(r.Uint32())
+		}
+		y := bytes.Clone(x)
+		if n > 0 && r.IntN(2) == 0 {
+			y[r.IntN(n)] ^= 1 << r.IntN(8)
+		}
+		if r.IntN(10) == 0 {
+			y = append(y, 0)
+		}
+		if got, want := ConstantTimeCompare(x, y), stdsubtle.ConstantTimeCompare(x, y); got != want {
+			t.Fatalf("ConstantTimeCompare(%x, %x) = %d，期望 %d", x, y, got, want)
+		}
+
+		a, b := uint8(r.Uint32()), uint8(r.Uint32())
+		if r.IntN(2) == 0 {
+			b = a
+		}
+		if got, want := ConstantTimeByteEq(a, b), stdsubtle.ConstantTimeByteEq(a, b); got != want {
+			t.Fatalf("ConstantTimeByteEq(%d, %d) = %d，期望 %d", a, b, got, want)
+		}
+
+		i, j := int32(r.Uint32()), int32(r.Uint32())
+		if r.IntN(2) == 0 {
+			j = i
+		}
+		if got, want := ConstantTimeEq(i, j), stdsubtle.ConstantTimeEq(i, j); got != want {
+			t.Fatalf("ConstantTimeEq(%d, %d) = %d，期望 %d", i, j, got, want)
+		}
+
+		p, q := r.IntN(1<<31-1), r.IntN(1<<31-1)
+		if r.IntN(4) == 0 {
+			q = p
+		}
+		if got, want := ConstantTimeLessOrEq(p, q), stdsubtle.ConstantTimeLessOrEq(p, q); got != want {
+			t.Fatalf("ConstantTimeLessOrEq(%d, %d) = %d，期望 %d", p, q, got, want)
+		}
+
+		v := r.IntN(2)
+		if got, want := ConstantTimeSelect(v, p, q), stdsubtle.ConstantTimeSelect(v, p, q); got != want {
+			t.Fatalf("ConstantTimeSelect(%d, %d, %d) = %d，期望 %d", v, p, q, got, want)
+		}
+	}
+}
+
+func TestConstantTimeCopy(t *testing.T) {
+	x := []byte("original")
+	y := []byte("replaced")
+	ConstantTimeCopy(0, x, y)
+	if string(x) != "original" {
+		t.Fatalf("v=0时不应复制: %q", x)
+	}
+	ConstantTimeCopy(1, x, y)
+	if string(x) != "replaced" {
+		t.Fatalf("v=1时应复制: %q", x)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("长度不同时应panic")
+		}
+	}()
+	ConstantTimeCopy(1, x, y[:3])
+}
@@ -0,0 +1,283 @@
+package gsc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/laenix/gsc/aes"
+	"github.com/laenix/gsc/blowfish"
+	"github.com/laenix/gsc/des"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/modes"
+	"github.com/laenix/gsc/padding"
+	"github.com/laenix/gsc/sm4"
+	"github.com/laenix/gsc/twofish"
+)
+
+// ErrInvalidSuite 表示密码套件规格字符串的格式无效
+var ErrInvalidSuite = gscerr.New(gscerr.ErrMalformed, "gsc: invalid cipher suite specification")
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidSuite: "gsc: 密码套件规格无效",
+	})
+}
+
+// Encryptor 加密数据，密文不含IV
+type Encryptor interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+}
+
+// Decryptor 解密Encryptor输出的密文
+type Decryptor interface {
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// suiteAlgorithm 描述规格字符串中可用的分组密码
+type suiteAlgorithm struct {
+	name string
+	// bitsRequired 为true时规格中必须给出密钥长度，如AES-256
+	bitsRequired bool
+	// defaultBits 是未给出密钥长度时使用的长度（位）
+	defaultBits int
+	blockSize   int
+	validBits   func(bits int) bool
+	newCipher   func(key []byte) (modes.BlockCipher, error)
+}
+
+var suiteAlgorithms = map[string]suiteAlgorithm{
+	"AES": {
+		name:         "AES",
+		bitsRequired: true,
+		blockSize:    aes.BlockSize,
+		validBits:    func(bits int) bool { return bits == 128 || bits == 192 || bits == 256 },
+		newCipher:    func(key []byte) (modes.BlockCipher, error) { return aes.New(key) },
+	},
+	"SM4": {
+		name:        "SM4",
+		defaultBits: 128,
+		blockSize:   sm4.BlockSize,
+		validBits:   func(bits int) bool { return bits == 128 },
+		newCipher:   func(key []byte) (modes.BlockCipher, error) { return sm4.New(key) },
+	},
+	"DES": {
+		name:        "DES",
+		defaultBits: 64,
+		blockSize:   des.BlockSize,
+		validBits:   func(bits int) bool { return bits == 64 },
+		newCipher:   func(key []byte) (modes.BlockCipher, error) { return des.New(key) },
+	},
+	"3DES": {
+		name:        "3DES",
+		defaultBits: 192,
+		blockSize:   des.BlockSize,
+		validBits:   func(bits int) bool { return bits == 64 || bits == 128 || bits == 192 },
+		newCipher:   func(key []byte) (modes.BlockCipher, error) { return des.NewTripleDES(key) },
+	},
+	"BLOWFISH": {
+		name:         "Blowfish",
+		bitsRequired: true,
+		blockSize:    blowfish.BlockSize,
+		validBits: func(bits int) bool {
+			return bits%8 == 0 && bits >= blowfish.MinKeySize*8 && bits <= blowfish.MaxKeySize*8
+		},
+		newCipher: func(key []byte) (modes.BlockCipher, error) { return blowfish.New(key) },
+	},
+	"TWOFISH": {
+		name:         "Twofish",
+		bitsRequired: true,
+		blockSize:    twofish.BlockSize,
+		validBits:    func(bits int) bool { return bits == 128 || bits == 192 || bits == 256 },
+		newCipher:    func(key []byte) (modes.BlockCipher, error) { return twofish.New(key) },
+	},
+}
+
+// CipherSuite 是按规格字符串组装的分组密码、工作模式和填充方式
+type CipherSuite struct {
+	alg suiteAlgorithm
+	// bits 是密钥长度（位），为0时由传入的密钥长度决定
+	bits    int
+	mode    string
+	padding string
+	pad     padding.PadFunc
+	unpad   padding.UnpadFunc
+	// transformation 是ParseTransformation解析的Java风格名称
+	transformation string
+}
+
+// NewCipherSuite 解析形如"AES-256-CBC/PKCS7"的规格字符串
+// 格式为 算法[-密钥位数]-模式[/填充]，不区分大小写。算法支持AES、SM4、DES、3DES、Blowfish和Twofish，
+// 其中AES、Blowfish和Twofish必须给出密钥位数，3DES默认为三密钥（192位），128位为双密钥；模式支持ECB、CBC、CFB、CFB8、CFB1、OFB、CTR和GCM；
+// 填充按padding.Get的名称查找，ECB和CBC默认为PKCS7，其余模式默认不填充，GCM不能指定填充。
+// ECB与直接使用modes.NewECB一样受严格策略约束
+func NewCipherSuite(spec string) (*CipherSuite, error) {
+	name, padName, hasPadding := strings.Cut(spec, "/")
+	if hasPadding && padName == "" {
+		return nil, ErrInvalidSuite
+	}
+	parts := strings.Split(strings.ToUpper(name), "-")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, ErrInvalidSuite
+	}
+
+	alg, ok := suiteAlgorithms[parts[0]]
+	if !ok {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	bits := alg.defaultBits
+	if len(parts) == 3 {
+		n, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, ErrInvalidSuite
+		}
+		bits = n
+	} else if alg.bitsRequired {
+		return nil, ErrInvalidSuite
+	}
+	if !alg.validBits(bits) {
+		return nil, ErrInvalidKeySize
+	}
+
+	return newCipherSuite(alg, bits, parts[len(parts)-1], strings.ToUpper(padName))
+}
+
+// newCipherSuite 检查模式并查找填充方式，padName为空时使用模式的默认填充
+func newCipherSuite(alg suiteAlgorithm, bits int, mode, padName string) (*CipherSuite, error) {
+	s := &CipherSuite{alg: alg, bits: bits, mode: mode, padding: padName}
+	switch s.mode {
+	case "ECB", "CBC":
+		if s.padding == "" {
+			s.padding = "PKCS7"
+		}
+	case "CFB", "CFB8", "CFB1", "OFB", "CTR", "GCM":
+		if s.padding == "" {
+			s.padding = "NONE"
+		}
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	pad, unpad, err := padding.Get(s.padding)
+	if err != nil {
+		return nil, err
+	}
+	if s.mode == "GCM" && s.padding != "NONE" {
+		return nil, ErrInvalidSuite
+	}
+	s.pad, s.unpad = pad, unpad
+	return s, nil
+}
+
+// String 返回规范化的规格字符串，如"AES-256-CBC/PKCS7"
+// ParseTransformation解析得到的套件返回Java风格的规范名称，如"AES/CBC/PKCS5Padding"
+func (s *CipherSuite) String() string {
+	if s.transformation != "" {
+		return s.transformation
+	}
+	if s.alg.bitsRequired || s.bits != s.alg.defaultBits {
+		return fmt.Sprintf("%s-%d-%s/%s", s.alg.name, s.bits, s.mode, s.padding)
+	}
+	return fmt.Sprintf("%s-%s/%s", s.alg.name, s.mode, s.padding)
+}
+
+// KeySize 返回密钥长度（字节）
+// 返回0表示接受该算法支持的任意密钥长度，由NewEncryptor传入的密钥决定
+func (s *CipherSuite) KeySize() int {
+	return s.bits / 8
+}
+
+// IVSize 返回IV长度（字节）：GCM为nonce长度，ECB为0，其余模式为块大小
+func (s *CipherSuite) IVSize() int {
+	switch s.mode {
+	case "ECB":
+		return 0
+	case "GCM":
+		return 12
+	}
+	return s.alg.blockSize
+}
+
+// NewEncryptor 返回使用key和iv加密的Encryptor，ECB模式下iv应为nil
+// 流式模式和GCM中同一个key和iv只应加密一条消息，重复调用Encrypt会重用密钥流或nonce
+func (s *CipherSuite) NewEncryptor(key, iv []byte) (Encryptor, error) {
+	return s.newCipher(key, iv)
+}
+
+// NewDecryptor 返回使用key和iv解密的Decryptor，参数须与加密时相同
+func (s *CipherSuite) NewDecryptor(key, iv []byte) (Decryptor, error) {
+	return s.newCipher(key, iv)
+}
+
+func (s *CipherSuite) newCipher(key, iv []byte) (*suiteCipher, error) {
+	if s.bits == 0 && !s.alg.validBits(len(key)*8) || s.bits != 0 && len(key) != s.KeySize() {
+		return nil, ErrInvalidKeySize
+	}
+	if len(iv) != s.IVSize() {
+		return nil, modes.ErrInvalidIV
+	}
+	block, err := s.alg.newCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &suiteCipher{suite: s}
+	switch s.mode {
+	case "ECB":
+		c.mode, err = modes.NewECB(block)
+	case "CBC":
+		c.mode, err = modes.NewCBC(block, iv)
+	case "CFB":
+		c.mode, err = modes.NewCFB(block, iv)
+	case "CFB8":
+		c.mode, err = modes.NewCFB8(block, iv)
+	case "CFB1":
+		c.mode, err = modes.NewCFB1(block, iv)
+	case "OFB":
+		c.mode, err = modes.NewOFB(block, iv)
+	case "CTR":
+		c.mode, err = modes.NewCTR(block, iv)
+	case "GCM":
+		c.gcm, err = modes.NewGCM(block)
+		c.nonce = append([]byte(nil), iv...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// suiteCipher 同时实现Encryptor和Decryptor
+type suiteCipher struct {
+	suite *CipherSuite
+	mode  modes.Mode
+	gcm   *modes.GCM
+	nonce []byte
+	// aad 是GCM的附加认证数据，由Encrypt和Decrypt的EncryptOptions.AdditionalData设置
+	aad []byte
+}
+
+func (c *suiteCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	if c.gcm != nil {
+		return c.gcm.Seal(c.nonce, plaintext, c.aad)
+	}
+	// 填充函数可能直接追加到plaintext的底层数组，先复制一份
+	padded, err := c.suite.pad(append([]byte(nil), plaintext...), c.suite.alg.blockSize)
+	if err != nil {
+		return nil, err
+	}
+	return c.mode.Encrypt(padded)
+}
+
+func (c *suiteCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	if c.gcm != nil {
+		return c.gcm.Open(c.nonce, ciphertext, c.aad)
+	}
+	plaintext, err := c.mode.Decrypt(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return c.suite.unpad(plaintext)
+}
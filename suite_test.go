@@ -0,0 +1,94 @@
+package gsc
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/laenix/gsc/padding"
+)
+
+func TestCipherSuiteRoundTrip(t *testing.T) {
+	plaintext := []byte("cipher suite factory test message")
+	for _, spec := range []string{
+		"AES-128-ECB", "AES-192-CBC/PKCS7", "aes-256-cbc/iso7816", "AES-256-CFB", "AES-128-CFB8",
+		"AES-128-CFB1", "AES-128-OFB", "AES-256-CTR", "AES-256-GCM", "SM4-CBC", "SM4-128-GCM",
+		"DES-CBC/PKCS#5", "3DES-CBC", "3DES-128-OFB", "Blowfish-128-CBC", "Twofish-256-CTR/None",
+	} {
+		s, err := NewCipherSuite(spec)
+		if err != nil {
+			t.Fatalf("%s: %v", spec, err)
+		}
+		key := bytes.Repeat([]byte{0x11}, s.KeySize())
+		var iv []byte
+		if s.IVSize() > 0 {
+			iv = bytes.Repeat([]byte{0x22}, s.IVSize())
+		}
+		enc, err := s.NewEncryptor(key, iv)
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		ciphertext, err := enc.Encrypt(plaintext)
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		dec, _ := s.NewDecryptor(key, iv)
+		got, err := dec.Decrypt(ciphertext)
+		if err != nil || !bytes.Equal(got, plaintext) {
+			t.Fatalf("%s: 解密结果不正确: %v", s, err)
+		}
+	}
+}
+
+// 测试套件的输出与标准库相同的组合一致
+func TestCipherSuiteMatchesStdlib(t *testing.T) {
+	key := bytes.Repeat([]byte{0x33}, 32)
+	iv := bytes.Repeat([]byte{0x44}, 16)
+	plaintext := []byte("sixteen byte msg and some more")
+
+	s, err := NewCipherSuite("AES-256-CBC/PKCS7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.String() != "AES-256-CBC/PKCS7" {
+		t.Fatalf("规范名称错误: %s", s)
+	}
+	enc, _ := s.NewEncryptor(key, iv)
+	got, err := enc.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, _ := aes.NewCipher(key)
+	padded, _ := padding.PKCS7Padding(bytes.Clone(plaintext), 16)
+	want := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(want, padded)
+	if !bytes.Equal(got, want) {
+		t.Fatal("AES-256-CBC/PKCS7的结果与标准库不一致")
+	}
+}
+
+func TestCipherSuiteInvalid(t *testing.T) {
+	for spec, want := range map[string]error{
+		"AES-CBC":             ErrInvalidSuite,
+		"AES-abc-CBC":         ErrInvalidSuite,
+		"AES-256":             ErrInvalidSuite,
+		"AES-512-CBC":         ErrInvalidKeySize,
+		"RC2-128-CBC":         ErrUnsupportedAlgorithm,
+		"AES-128-XEX":         ErrUnsupportedAlgorithm,
+		"AES-128-CBC/PKCS#1":  padding.ErrUnknownScheme,
+		"AES-128-GCM/PKCS7":   ErrInvalidSuite,
+		"SM4-256-CBC":         ErrInvalidKeySize,
+		"AES-128-CBC-PKCS7/x": ErrInvalidSuite,
+	} {
+		if _, err := NewCipherSuite(spec); err != want {
+			t.Errorf("%s: 期望%v，实际: %v", spec, want, err)
+		}
+	}
+
+	s, _ := NewCipherSuite("AES-128-CBC")
+	if _, err := s.NewEncryptor(make([]byte, 32), make([]byte, 16)); err != ErrInvalidKeySize {
+		t.Fatalf("期望ErrInvalidKeySize，实际: %v", err)
+	}
+}
@@ -0,0 +1,22 @@
+package token
+
+import "encoding/base64"
+
+// GenerateFernetKey 生成随机密钥并编码为Fernet密钥字符串（base64url，44个字符）
+func GenerateFernetKey() (string, error) {
+	key, err := GenerateKey()
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(key), nil
+}
+
+// NewFernet 使用Fernet密钥字符串创建版本为VersionFernet的Codec
+// key与Python cryptography.fernet.Fernet的参数相同，是32字节密钥的base64url编码
+func NewFernet(key string) (*Codec, error) {
+	raw, err := base64.URLEncoding.DecodeString(key)
+	if err != nil || len(raw) != KeySize {
+		return nil, ErrInvalidKeySize
+	}
+	return NewVersion(VersionFernet, raw)
+}
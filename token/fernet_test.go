@@ -0,0 +1,71 @@
+package token
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// Fernet规范仓库（github.com/fernet/spec）generate.json和verify.json中的向量
+const (
+	fernetSecret = "cw_0x689RpI-jtRR7oE8h_eQsKImvJapLeSbXpwF4e4="
+	fernetToken  = "gAAAAAAdwJ6wAAECAwQFBgcICQoLDA0ODy021cpGVWKZ_eEwCGM4BLLF_5CV9dOPmrhuVUPgJobwOz7JcbmrR64jVmpU4IwqDA=="
+)
+
+func TestFernetVectors(t *testing.T) {
+	c, err := NewFernet(fernetSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issued, _ := time.Parse(time.RFC3339, "1985-10-26T01:20:00-07:00")
+	iv := []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	if got, err := c.seal([]byte("hello"), issued, iv); err != nil || got != fernetToken {
+		t.Errorf("生成的令牌 = %s, %v", got, err)
+	}
+
+	got, err := c.DecryptAt(fernetToken, 60*time.Second, issued.Add(time.Second))
+	if err != nil || string(got) != "hello" {
+		t.Errorf("解密结果 = %q, %v", got, err)
+	}
+	if _, err := c.DecryptAt(fernetToken, 60*time.Second, issued.Add(90*time.Second)); !errors.Is(err, ErrExpired) {
+		t.Errorf("过期令牌: %v", err)
+	}
+}
+
+func TestFernetRoundTrip(t *testing.T) {
+	key, err := GenerateFernetKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != 44 {
+		t.Errorf("Fernet密钥长度 %d", len(key))
+	}
+	c, err := NewFernet(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tok, err := c.Encrypt([]byte("fernet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok[:4] != "gAAA" {
+		t.Errorf("Fernet令牌应以版本0x80开头: %s", tok)
+	}
+	if got, err := c.Decrypt(tok, time.Minute); err != nil || string(got) != "fernet" {
+		t.Errorf("往返结果 = %q, %v", got, err)
+	}
+
+	// SM4版本的Codec不接受Fernet令牌
+	sm4c, _ := New(make([]byte, KeySize))
+	if _, err := sm4c.Decrypt(tok, 0); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("版本不同: %v", err)
+	}
+}
+
+func TestNewFernetErrors(t *testing.T) {
+	for _, key := range []string{"", "not base64!", "AAAA", fernetSecret[:43]} {
+		if _, err := NewFernet(key); !errors.Is(err, ErrInvalidKeySize) {
+			t.Errorf("%q: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+// Package token 提供类似Fernet的加密令牌，用于在应用中保存和传递简单的秘密
+//
+// 令牌格式为 版本(1) || 时间戳(8) || IV(16) || 密文 || MAC，整体以base64url编码为一个字符串。
+// 时间戳是生成令牌时的Unix秒数（大端），解密时可以据此拒绝超过有效期的令牌；
+// 密文使用CBC模式和PKCS#7填充，MAC覆盖它之前的全部字节，先验证MAC再解密。
+// 密钥为32字节，前16字节是MAC密钥，后16字节是加密密钥。
+//
+// VersionFernet遵循Fernet规范（AES-128-CBC和HMAC-SHA256），令牌可与Python cryptography.fernet
+// 等实现互通；VersionSM4使用SM4-CBC和HMAC-SM3，格式相同
+package token
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/laenix/gsc/aes"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/mac"
+	"github.com/laenix/gsc/modes"
+	"github.com/laenix/gsc/padding"
+	"github.com/laenix/gsc/sm4"
+)
+
+const (
+	// KeySize 是令牌密钥长度（字节）
+	KeySize = 32
+	// VersionFernet 是Fernet规范的令牌版本（AES-128-CBC和HMAC-SHA256），见NewFernet
+	VersionFernet = 0x80
+	// VersionSM4 是使用SM4-CBC和HMAC-SM3的令牌版本
+	VersionSM4 = 0x91
+	// MaxClockSkew 是允许令牌时间戳超前于当前时间的最大值，超过时视为无效
+	MaxClockSkew = 60 * time.Second
+
+	// 时间戳长度
+	timestampSize = 8
+	// 分组长度，也是IV长度；各版本的分组密码都是128位
+	blockSize = 16
+)
+
+// 错误定义
+var (
+	ErrInvalidKeySize     = gscerr.New(gscerr.ErrKeySize, "token: invalid key size")
+	ErrInvalidToken       = gscerr.New(gscerr.ErrMalformed, "token: invalid token format")
+	ErrUnsupportedVersion = gscerr.New(gscerr.ErrUnsupported, "token: unsupported token version")
+	ErrAuthFailed         = gscerr.New(gscerr.ErrAuthFailed, "token: message authentication failed")
+	ErrExpired            = gscerr.New(gscerr.ErrVerification, "token: token has expired")
+	ErrFromFuture         = gscerr.New(gscerr.ErrVerification, "token: token timestamp is in the future")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidKeySize:     "token: 令牌密钥必须是32字节",
+		ErrInvalidToken:       "token: 令牌格式无效",
+		ErrUnsupportedVersion: "token: 不支持的令牌版本",
+		ErrAuthFailed:         "token: 令牌认证失败",
+		ErrExpired:            "token: 令牌已过期",
+		ErrFromFuture:         "token: 令牌时间戳晚于当前时间",
+	})
+}
+
+// version 描述一个令牌版本使用的分组密码和MAC
+type version struct {
+	newCipher func(key []byte) (modes.BlockCipher, error)
+	newMAC    func(key []byte) hash.Hash
+}
+
+var versions = map[byte]version{
+	VersionFernet: {
+		newCipher: func(key []byte) (modes.BlockCipher, error) { return aes.New(key) },
+		newMAC:    func(key []byte) hash.Hash { return hmac.New(sha256.New, key) },
+	},
+	VersionSM4: {
+		newCipher: func(key []byte) (modes.BlockCipher, error) { return sm4.New(key) },
+		newMAC:    mac.NewSM3HMAC,
+	},
+}
+
+// Codec 使用固定的密钥和版本生成、解开令牌，可以被多个goroutine同时使用
+type Codec struct {
+	ver     byte
+	v       version
+	macKey  []byte
+	encKey  []byte
+	macSize int
+}
+
+// GenerateKey 生成随机的令牌密钥
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// New 使用key创建版本为VersionSM4的Codec
+func New(key []byte) (*Codec, error) {
+	return NewVersion(VersionSM4, key)
+}
+
+// NewVersion 使用key创建指定版本的Codec
+// Codec只接受同一版本的令牌，更换版本时为旧令牌另建一个Codec
+func NewVersion(ver byte, key []byte) (*Codec, error) {
+	v, ok := versions[ver]
+	if !ok {
+		return nil, ErrUnsupportedVersion
+	}
+	if len(key) != KeySize {
+		return nil, gscerr.KeySize(ErrInvalidKeySize, "token", len(key), KeySize)
+	}
+	return &Codec{
+		ver:     ver,
+		v:       v,
+		macKey:  bytes.Clone(key[:KeySize/2]),
+		encKey:  bytes.Clone(key[KeySize/2:]),
+		macSize: v.newMAC(nil).Size(),
+	}, nil
+}
+
+// Encrypt 加密plaintext并返回以当前时间为时间戳的令牌
+func (c *Codec) Encrypt(plaintext []byte) (string, error) {
+	return c.EncryptAt(plaintext, time.Now())
+}
+
+// EncryptAt 加密plaintext并返回以t为时间戳的令牌
+func (c *Codec) EncryptAt(plaintext []byte, t time.Time) (string, error) {
+	iv := make([]byte, blockSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return "", err
+	}
+	return c.seal(plaintext, t, iv)
+}
+
+// seal 使用给定的IV生成令牌
+func (c *Codec) seal(plaintext []byte, t time.Time, iv []byte) (string, error) {
+	block, err := c.v.newCipher(c.encKey)
+	if err != nil {
+		return "", err
+	}
+	cbc, err := modes.NewCBC(block, iv)
+	if err != nil {
+		return "", err
+	}
+	padded, err := padding.PKCS7Padding(plaintext, blockSize)
+	if err != nil {
+		return "", err
+	}
+
+	out := make([]byte, 0, 1+timestampSize+blockSize+len(padded)+c.macSize)
+	out = append(out, c.ver)
+	out = binary.BigEndian.AppendUint64(out, uint64(t.Unix()))
+	out = append(out, iv...)
+	if out, err = cbc.AppendEncrypt(out, padded); err != nil {
+		return "", err
+	}
+	m := c.v.newMAC(c.macKey)
+	m.Write(out)
+	out = m.Sum(out)
+	return base64.URLEncoding.EncodeToString(out), nil
+}
+
+// Decrypt 验证并解密令牌，ttl大于0时拒绝生成时间早于ttl之前的令牌
+func (c *Codec) Decrypt(token string, ttl time.Duration) ([]byte, error) {
+	return c.DecryptAt(token, ttl, time.Now())
+}
+
+// DecryptAt 与Decrypt相同，但以now作为当前时间
+// ttl大于0时，过期的令牌返回ErrExpired，时间戳超前now超过MaxClockSkew的令牌返回ErrFromFuture；
+// ttl为0时不检查时间戳，与Python cryptography.fernet的行为一致
+func (c *Codec) DecryptAt(token string, ttl time.Duration, now time.Time) ([]byte, error) {
+	data, ts, err := c.verify(token)
+	if err != nil {
+		return nil, err
+	}
+	if ttl > 0 {
+		if now.After(ts.Add(ttl)) {
+			return nil, ErrExpired
+		}
+		if ts.After(now.Add(MaxClockSkew)) {
+			return nil, ErrFromFuture
+		}
+	}
+
+	block, err := c.v.newCipher(c.encKey)
+	if err != nil {
+		return nil, err
+	}
+	cbc, err := modes.NewCBC(block, data[1+timestampSize:1+timestampSize+blockSize])
+	if err != nil {
+		return nil, err
+	}
+	padded, err := cbc.Decrypt(data[1+timestampSize+blockSize : len(data)-c.macSize])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	plaintext, err := padding.PKCS7UnPadding(padded)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	return plaintext, nil
+}
+
+// Timestamp 验证令牌的MAC并返回其中的时间戳，不检查有效期
+func (c *Codec) Timestamp(token string) (time.Time, error) {
+	_, ts, err := c.verify(token)
+	return ts, err
+}
+
+// verify 解码令牌、检查版本和长度并验证MAC，返回原始字节和时间戳
+func (c *Codec) verify(token string) ([]byte, time.Time, error) {
+	data, err := base64.URLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, time.Time{}, ErrInvalidToken
+	}
+	if len(data) == 0 {
+		return nil, time.Time{}, ErrInvalidToken
+	}
+	if data[0] != c.ver {
+		return nil, time.Time{}, ErrUnsupportedVersion
+	}
+	// 密文至少一个分组且为分组长度的整数倍
+	n := len(data) - 1 - timestampSize - blockSize - c.macSize
+	if n <= 0 || n%blockSize != 0 {
+		return nil, time.Time{}, ErrInvalidToken
+	}
+
+	m := c.v.newMAC(c.macKey)
+	m.Write(data[:len(data)-c.macSize])
+	if !mac.Equal(m.Sum(nil), data[len(data)-c.macSize:]) {
+		return nil, time.Time{}, ErrAuthFailed
+	}
+	ts := int64(binary.BigEndian.Uint64(data[1:]))
+	return data, time.Unix(ts, 0), nil
+}
@@ -0,0 +1,115 @@
+package token
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newCodec(t *testing.T) *Codec {
+	t.Helper()
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestRoundTrip(t *testing.T) {
+	c := newCodec(t)
+	for _, size := range []int{0, 1, 15, 16, 17, 100} {
+		msg := bytes.Repeat([]byte{0x5a}, size)
+		tok, err := c.Encrypt(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := c.Decrypt(tok, time.Minute)
+		if err != nil {
+			t.Fatalf("长度%d: 解密失败: %v", size, err)
+		}
+		if !bytes.Equal(got, msg) {
+			t.Fatalf("长度%d: 解密结果不匹配", size)
+		}
+	}
+}
+
+func TestTTL(t *testing.T) {
+	c := newCodec(t)
+	issued := time.Unix(1700000000, 0)
+	tok, err := c.EncryptAt([]byte("secret"), issued)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts, err := c.Timestamp(tok); err != nil || !ts.Equal(issued) {
+		t.Errorf("Timestamp = %v, %v", ts, err)
+	}
+
+	tests := []struct {
+		ttl  time.Duration
+		now  time.Time
+		want error
+	}{
+		{time.Hour, issued.Add(time.Hour), nil},
+		{time.Hour, issued.Add(time.Hour + time.Second), ErrExpired},
+		{0, issued.Add(1000 * time.Hour), nil},
+		{0, issued.Add(-time.Hour), nil},
+		{time.Hour, issued.Add(-MaxClockSkew), nil},
+		{time.Hour, issued.Add(-MaxClockSkew - time.Second), ErrFromFuture},
+	}
+	for _, tt := range tests {
+		if _, err := c.DecryptAt(tok, tt.ttl, tt.now); !errors.Is(err, tt.want) {
+			t.Errorf("ttl=%v now=%v: 期望%v，实际%v", tt.ttl, tt.now.Sub(issued), tt.want, err)
+		}
+	}
+}
+
+func TestTampering(t *testing.T) {
+	c := newCodec(t)
+	tok, _ := c.Encrypt([]byte("secret"))
+	raw, _ := base64.URLEncoding.DecodeString(tok)
+
+	// 修改时间戳、IV、密文或MAC中的任意一字节都使MAC验证失败
+	for _, i := range []int{1, 1 + timestampSize, len(raw) - 33, len(raw) - 1} {
+		bad := bytes.Clone(raw)
+		bad[i] ^= 1
+		if _, err := c.Decrypt(base64.URLEncoding.EncodeToString(bad), 0); !errors.Is(err, ErrAuthFailed) {
+			t.Errorf("修改第%d字节: %v", i, err)
+		}
+	}
+
+	if _, err := newCodec(t).Decrypt(tok, 0); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("密钥错误: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"非base64url", tok[:len(tok)-4] + "!!!!", ErrInvalidToken},
+		{"空", "", ErrInvalidToken},
+		{"版本", base64.URLEncoding.EncodeToString(append([]byte{VersionFernet}, raw[1:]...)), ErrUnsupportedVersion},
+		{"截断", base64.URLEncoding.EncodeToString(raw[:len(raw)-1]), ErrInvalidToken},
+		{"无密文", base64.URLEncoding.EncodeToString(raw[:1+timestampSize+blockSize]), ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		if _, err := c.Decrypt(tt.token, 0); !errors.Is(err, tt.want) {
+			t.Errorf("%s: 期望%v，实际%v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	if _, err := New(make([]byte, 16)); !errors.Is(err, ErrInvalidKeySize) {
+		t.Errorf("密钥长度错误: %v", err)
+	}
+	if _, err := NewVersion(0x01, make([]byte, KeySize)); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("未知版本: %v", err)
+	}
+}
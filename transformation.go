@@ -0,0 +1,115 @@
+package gsc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// javaPaddings 将javax.crypto（含BouncyCastle）的填充名称映射到padding包的名称
+// Java中的PKCS5Padding实际按块大小填充，与PKCS#7相同
+var javaPaddings = map[string]string{
+	"NOPADDING":         "NONE",
+	"PKCS5PADDING":      "PKCS7",
+	"PKCS7PADDING":      "PKCS7",
+	"ISO10126PADDING":   "ISO10126",
+	"ISO10126-2PADDING": "ISO10126",
+	"ISO7816-4PADDING":  "ISO7816",
+	"X9.23PADDING":      "ANSIX923",
+	"X923PADDING":       "ANSIX923",
+	"ZEROBYTEPADDING":   "ZERO",
+	"TBCPADDING":        "TBC",
+}
+
+// javaAlgorithms 是Java风格名称中的算法名，AES_128等形式固定密钥长度
+var javaAlgorithms = map[string]struct {
+	alg  string
+	name string
+	bits int
+}{
+	"AES":       {"AES", "AES", 0},
+	"AES_128":   {"AES", "AES_128", 128},
+	"AES_192":   {"AES", "AES_192", 192},
+	"AES_256":   {"AES", "AES_256", 256},
+	"SM4":       {"SM4", "SM4", 128},
+	"DES":       {"DES", "DES", 64},
+	"DESEDE":    {"3DES", "DESede", 0},
+	"TRIPLEDES": {"3DES", "TripleDES", 0},
+	"BLOWFISH":  {"BLOWFISH", "Blowfish", 0},
+	"TWOFISH":   {"TWOFISH", "Twofish", 0},
+}
+
+// ParseTransformation 按javax.crypto.Cipher.getInstance的规则解析转换字符串，
+// 如"AES/CBC/PKCS5Padding"、"SM4/ECB/NoPadding"、"AES/GCM/NoPadding"
+// 格式为 算法 或 算法/模式/填充，不区分大小写；只给出算法时与Java一样使用ECB和PKCS5Padding。
+// 与Java一致，除AES_128等形式外密钥长度由传入的密钥决定；CFB、OFB可带反馈位数（如CFB8），
+// 不带位数时为整块反馈。GCM的IV长度为12字节，输出为密文||16字节标签，与Java的默认参数相同
+func ParseTransformation(transformation string) (*CipherSuite, error) {
+	parts := strings.Split(transformation, "/")
+	switch len(parts) {
+	case 1:
+		parts = append(parts, "ECB", "PKCS5Padding")
+	case 3:
+	default:
+		return nil, ErrInvalidSuite
+	}
+	for i := range parts {
+		parts[i] = strings.ToUpper(strings.TrimSpace(parts[i]))
+	}
+
+	javaAlg, ok := javaAlgorithms[parts[0]]
+	if !ok {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	alg := suiteAlgorithms[javaAlg.alg]
+	mode, err := javaMode(parts[1], alg.blockSize)
+	if err != nil {
+		return nil, err
+	}
+	padName, ok := javaPaddings[parts[2]]
+	if !ok {
+		return nil, ErrInvalidSuite
+	}
+
+	s, err := newCipherSuite(alg, javaAlg.bits, mode, padName)
+	if err != nil {
+		return nil, err
+	}
+	s.transformation = fmt.Sprintf("%s/%s/%s", javaAlg.name, parts[1], javaPaddingName(parts[2]))
+	return s, nil
+}
+
+// javaMode 将Java的模式名映射到套件的模式，CFBn和OFBn中n为反馈位数
+func javaMode(name string, blockSize int) (string, error) {
+	for _, prefix := range []string{"CFB", "OFB"} {
+		bits, ok := strings.CutPrefix(name, prefix)
+		if !ok || bits == "" {
+			continue
+		}
+		n, err := strconv.Atoi(bits)
+		if err != nil {
+			return "", ErrInvalidSuite
+		}
+		switch {
+		case n == blockSize*8:
+			return prefix, nil
+		case prefix == "CFB" && (n == 8 || n == 1):
+			return name, nil
+		}
+		return "", ErrUnsupportedAlgorithm
+	}
+	return name, nil
+}
+
+// javaPaddingName 返回填充的Java规范写法，用于String
+func javaPaddingName(name string) string {
+	for _, canonical := range []string{
+		"NoPadding", "PKCS5Padding", "PKCS7Padding", "ISO10126Padding", "ISO10126-2Padding",
+		"ISO7816-4Padding", "X9.23Padding", "X923Padding", "ZeroBytePadding", "TBCPadding",
+	} {
+		if strings.EqualFold(name, canonical) {
+			return canonical
+		}
+	}
+	return name
+}
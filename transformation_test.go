@@ -0,0 +1,97 @@
+package gsc
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/laenix/gsc/padding"
+)
+
+func TestParseTransformation(t *testing.T) {
+	for in, want := range map[string]string{
+		"AES/CBC/PKCS5Padding":      "AES/CBC/PKCS5Padding",
+		"aes/cbc/pkcs5padding":      "AES/CBC/PKCS5Padding",
+		"AES":                       "AES/ECB/PKCS5Padding",
+		"SM4/ECB/NoPadding":         "SM4/ECB/NoPadding",
+		"AES_256/GCM/NoPadding":     "AES_256/GCM/NoPadding",
+		"AES/CFB8/NoPadding":        "AES/CFB8/NoPadding",
+		"AES/CFB128/NoPadding":      "AES/CFB128/NoPadding",
+		"DES/CFB64/NoPadding":       "DES/CFB64/NoPadding",
+		"Blowfish/CBC/PKCS5Padding": "Blowfish/CBC/PKCS5Padding",
+		"DESede/CBC/PKCS5Padding":   "DESede/CBC/PKCS5Padding",
+		"AES/ECB/ISO7816-4Padding":  "AES/ECB/ISO7816-4Padding",
+	} {
+		s, err := ParseTransformation(in)
+		if err != nil {
+			t.Fatalf("%s: %v", in, err)
+		}
+		if s.String() != want {
+			t.Errorf("%s: 规范名称为%s，期望%s", in, s, want)
+		}
+	}
+
+	for in, want := range map[string]error{
+		"AES/CBC":              ErrInvalidSuite,
+		"AES/CBC/PKCS1Padding": ErrInvalidSuite,
+		"RC2/CBC/PKCS5Padding": ErrUnsupportedAlgorithm,
+		"AES/XTS/NoPadding":    ErrUnsupportedAlgorithm,
+		"AES/CFB32/NoPadding":  ErrUnsupportedAlgorithm,
+		"AES/GCM/PKCS5Padding": ErrInvalidSuite,
+	} {
+		if _, err := ParseTransformation(in); err != want {
+			t.Errorf("%s: 期望%v，实际: %v", in, want, err)
+		}
+	}
+}
+
+// 测试Java风格的套件与标准库相同组合的输出一致，密钥长度由密钥决定
+func TestTransformationMatchesStdlib(t *testing.T) {
+	plaintext := []byte("ported from javax.crypto.Cipher")
+	iv := bytes.Repeat([]byte{0x24}, 16)
+
+	s, err := ParseTransformation("AES/CBC/PKCS5Padding")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, keySize := range []int{16, 24, 32} {
+		key := bytes.Repeat([]byte{0x42}, keySize)
+		enc, err := s.NewEncryptor(key, iv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := enc.Encrypt(plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		block, _ := aes.NewCipher(key)
+		padded, _ := padding.PKCS7Padding(bytes.Clone(plaintext), 16)
+		want := make([]byte, len(padded))
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(want, padded)
+		if !bytes.Equal(got, want) {
+			t.Fatalf("AES-%d/CBC/PKCS5Padding的结果与标准库不一致", keySize*8)
+		}
+	}
+	if _, err := s.NewEncryptor(make([]byte, 20), iv); err != ErrInvalidKeySize {
+		t.Fatalf("期望ErrInvalidKeySize，实际: %v", err)
+	}
+
+	s, _ = ParseTransformation("AES_128/GCM/NoPadding")
+	key := bytes.Repeat([]byte{0x42}, 16)
+	nonce := iv[:12]
+	enc, err := s.NewEncryptor(key, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := enc.Encrypt(plaintext)
+	block, _ := aes.NewCipher(key)
+	gcm, _ := cipher.NewGCM(block)
+	if !bytes.Equal(got, gcm.Seal(nil, nonce, plaintext, nil)) {
+		t.Fatal("AES/GCM/NoPadding的结果与标准库不一致")
+	}
+	if _, err := s.NewEncryptor(make([]byte, 32), nonce); err != ErrInvalidKeySize {
+		t.Fatalf("AES_128应固定密钥长度，实际: %v", err)
+	}
+}
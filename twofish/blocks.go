@@ -0,0 +1,24 @@
+package twofish
+
+// EncryptBlocks 加密src中的多个块并写入dst，dst与src可以是同一切片
+// src长度必须是16的整数倍且dst不短于src，否则返回ErrInvalidBlockSize
+func (t *Twofish) EncryptBlocks(dst, src []byte) error {
+	if len(src)%BlockSize != 0 || len(dst) < len(src) {
+		return ErrInvalidBlockSize
+	}
+	for i := 0; i < len(src); i += BlockSize {
+		t.encryptBlock(dst[i:i+BlockSize], src[i:i+BlockSize])
+	}
+	return nil
+}
+
+// DecryptBlocks 解密src中的多个块并写入dst，约定与EncryptBlocks相同
+func (t *Twofish) DecryptBlocks(dst, src []byte) error {
+	if len(src)%BlockSize != 0 || len(dst) < len(src) {
+		return ErrInvalidBlockSize
+	}
+	for i := 0; i < len(src); i += BlockSize {
+		t.decryptBlock(dst[i:i+BlockSize], src[i:i+BlockSize])
+	}
+	return nil
+}
@@ -1,7 +1,8 @@
 package twofish
 
 import (
-	"errors"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
 )
 
 const (
@@ -22,15 +23,23 @@ type Twofish struct {
 
 // 错误定义
 var (
-	ErrInvalidKeySize   = errors.New("twofish: 密钥长度必须是16, 24或32字节")
-	ErrInvalidBlockSize = errors.New("twofish: 数据块必须是16字节")
+	ErrInvalidKeySize   = gscerr.New(gscerr.ErrKeySize, "twofish: key must be 16, 24 or 32 bytes")
+	ErrInvalidBlockSize = gscerr.New(gscerr.ErrBlockSize, "twofish: block must be 16 bytes")
 )
 
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidKeySize:   "twofish: 密钥长度必须是16, 24或32字节",
+		ErrInvalidBlockSize: "twofish: 数据块必须是16字节",
+	})
+}
+
 // New 创建一个新的Twofish实例
 func New(key []byte) (*Twofish, error) {
 	keyLen := len(key)
 	if keyLen != KeySize128 && keyLen != KeySize192 && keyLen != KeySize256 {
-		return nil, ErrInvalidKeySize
+		return nil, gscerr.KeySize(ErrInvalidKeySize, "Twofish", keyLen, KeySize128, KeySize192, KeySize256)
 	}
 
 	// 创建Twofish实例
@@ -50,6 +59,11 @@ func New(key []byte) (*Twofish, error) {
 	return t, nil
 }
 
+// KeySize 返回密钥长度（字节）：16、24或32
+func (t *Twofish) KeySize() int {
+	return t.keySize
+}
+
 // BlockSize 返回区块大小
 func (t *Twofish) BlockSize() int {
 	return BlockSize
@@ -60,16 +74,18 @@ func (t *Twofish) Encrypt(block []byte) ([]byte, error) {
 	if len(block) != BlockSize {
 		return nil, ErrInvalidBlockSize
 	}
-
-	// 复制输入块以避免就地修改
 	result := make([]byte, BlockSize)
-	copy(result, block)
+	t.encryptBlock(result, block)
+	return result, nil
+}
 
+// encryptBlock 加密一个块，dst与src可以是同一切片
+func (t *Twofish) encryptBlock(dst, src []byte) {
 	// 将16字节明文分成4个32位字
-	w0 := bytesToUint32(result[0:4])
-	w1 := bytesToUint32(result[4:8])
-	w2 := bytesToUint32(result[8:12])
-	w3 := bytesToUint32(result[12:16])
+	w0 := bytesToUint32(src[0:4])
+	w1 := bytesToUint32(src[4:8])
+	w2 := bytesToUint32(src[8:12])
+	w3 := bytesToUint32(src[12:16])
 
 	// 输入白化
 	w0 ^= t.k[0]
@@ -104,12 +120,10 @@ func (t *Twofish) Encrypt(block []byte) ([]byte, error) {
 	w1 ^= t.k[7]
 
 	// 写回结果
-	uint32ToBytes(w2, result[0:4])
-	uint32ToBytes(w3, result[4:8])
-	uint32ToBytes(w0, result[8:12])
-	uint32ToBytes(w1, result[12:16])
-
-	return result, nil
+	uint32ToBytes(w2, dst[0:4])
+	uint32ToBytes(w3, dst[4:8])
+	uint32ToBytes(w0, dst[8:12])
+	uint32ToBytes(w1, dst[12:16])
 }
 
 // Decrypt 解密单个区块（16字节）
@@ -117,16 +131,18 @@ func (t *Twofish) Decrypt(block []byte) ([]byte, error) {
 	if len(block) != BlockSize {
 		return nil, ErrInvalidBlockSize
 	}
-
-	// 复制输入块以避免就地修改
 	result := make([]byte, BlockSize)
-	copy(result, block)
+	t.decryptBlock(result, block)
+	return result, nil
+}
 
+// decryptBlock 解密一个块，dst与src可以是同一切片
+func (t *Twofish) decryptBlock(dst, src []byte) {
 	// 将16字节密文分成4个32位字
-	w2 := bytesToUint32(result[0:4])
-	w3 := bytesToUint32(result[4:8])
-	w0 := bytesToUint32(result[8:12])
-	w1 := bytesToUint32(result[12:16])
+	w2 := bytesToUint32(src[0:4])
+	w3 := bytesToUint32(src[4:8])
+	w0 := bytesToUint32(src[8:12])
+	w1 := bytesToUint32(src[12:16])
 
 	// 输入白化（使用输出白化密钥）
 	w2 ^= t.k[4]
@@ -161,12 +177,10 @@ func (t *Twofish) Decrypt(block []byte) ([]byte, error) {
 	w3 ^= t.k[3]
 
 	// 写回结果
-	uint32ToBytes(w0, result[0:4])
-	uint32ToBytes(w1, result[4:8])
-	uint32ToBytes(w2, result[8:12])
-	uint32ToBytes(w3, result[12:16])
-
-	return result, nil
+	uint32ToBytes(w0, dst[0:4])
+	uint32ToBytes(w1, dst[4:8])
+	uint32ToBytes(w2, dst[8:12])
+	uint32ToBytes(w3, dst[12:16])
 }
 
 // g0和g1是Twofish的G函数
@@ -0,0 +1,246 @@
+// Package vectors 解析NIST CAVP的.rsp测试向量文件和GB/T国密标准中的运算示例，
+// 用于以官方数据驱动表格测试，而不是手工抄写常量
+//
+// 两种格式都按"名称 = 值"逐行记录字段，空行分隔用例，#开头的行为注释，
+// 方括号行（如[ENCRYPT]、[Keylen = 128]）设置其后各用例共用的参数。
+// GB/T格式另外允许用全角或半角冒号分隔名称和值，并允许以空白开头的续行，
+// 以便直接粘贴标准文本中按8个十六进制字符分组、跨行排版的数据
+package vectors
+
+import (
+	"bufio"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+// 错误定义
+var (
+	ErrSyntax       = gscerr.New(gscerr.ErrMalformed, "vectors: malformed line")
+	ErrMissingField = gscerr.New(gscerr.ErrMalformed, "vectors: missing field")
+	ErrInvalidValue = gscerr.New(gscerr.ErrMalformed, "vectors: invalid field value")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrSyntax:       "vectors: 格式错误的行",
+		ErrMissingField: "vectors: 缺少字段",
+		ErrInvalidValue: "vectors: 字段值无效",
+	})
+}
+
+// SyntaxError 记录解析失败的行号
+type SyntaxError struct {
+	Line int
+	Err  error
+}
+
+func (e *SyntaxError) Error() string {
+	return e.Err.Error() + " (line " + strconv.Itoa(e.Line) + ")"
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// Case 是一个测试用例
+type Case struct {
+	// Section 是用例所属的最后一个方括号行的内容，如"ENCRYPT"或"Taglen = 128"
+	Section string
+	// Params 是方括号行中"名称 = 值"形式的参数，如Keylen、PTlen
+	Params map[string]string
+	// Fields 是用例自身的字段；没有值的行（如CAVP解密文件中的FAIL）以空字符串记录
+	Fields map[string]string
+	// Line 是用例第一行的行号，从1开始
+	Line int
+}
+
+// Has 报告用例是否包含名为name的字段
+func (c *Case) Has(name string) bool {
+	_, ok := c.Fields[name]
+	return ok
+}
+
+// Get 返回字段name的值，字段不存在时返回ErrMissingField
+func (c *Case) Get(name string) (string, error) {
+	v, ok := c.Fields[name]
+	if !ok {
+		return "", c.errorf(ErrMissingField)
+	}
+	return v, nil
+}
+
+// Hex 将字段name按十六进制解码，忽略其中的空白；值为空时返回空切片
+func (c *Case) Hex(name string) ([]byte, error) {
+	v, err := c.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	b, err := hex.DecodeString(strings.Join(strings.Fields(v), ""))
+	if err != nil {
+		return nil, c.errorf(ErrInvalidValue)
+	}
+	return b, nil
+}
+
+// Int 将字段name按十进制整数解析
+func (c *Case) Int(name string) (int, error) {
+	v, err := c.Get(name)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, c.errorf(ErrInvalidValue)
+	}
+	return n, nil
+}
+
+// Param 将方括号参数name按十进制整数解析，如GCM文件中的Taglen
+func (c *Case) Param(name string) (int, error) {
+	v, ok := c.Params[name]
+	if !ok {
+		return 0, c.errorf(ErrMissingField)
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, c.errorf(ErrInvalidValue)
+	}
+	return n, nil
+}
+
+func (c *Case) errorf(err error) error {
+	return &SyntaxError{Line: c.Line, Err: err}
+}
+
+// ParseRSP 解析CAVP的.rsp文件
+func ParseRSP(r io.Reader) ([]*Case, error) {
+	return parse(r, false)
+}
+
+// ParseGBT 解析按GB/T运算示例整理的测试向量文件
+func ParseGBT(r io.Reader) ([]*Case, error) {
+	return parse(r, true)
+}
+
+// Load 读取并解析path，扩展名为.rsp时按CAVP格式解析，否则按GB/T格式解析
+func Load(path string) ([]*Case, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if strings.EqualFold(filepath.Ext(path), ".rsp") {
+		return ParseRSP(f)
+	}
+	return ParseGBT(f)
+}
+
+// Run 加载path中的全部用例，逐个作为子测试调用fn
+// 子测试以用例的行号命名，加载失败时测试立即失败
+func Run(t *testing.T, path string, fn func(t *testing.T, c *Case)) {
+	t.Helper()
+	cases, err := Load(path)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	if len(cases) == 0 {
+		t.Fatalf("%s: 没有测试用例", path)
+	}
+	for _, c := range cases {
+		t.Run(filepath.Base(path)+":"+strconv.Itoa(c.Line), func(t *testing.T) {
+			fn(t, c)
+		})
+	}
+}
+
+// parse 是两种格式共用的逐行解析器，gbt为true时接受冒号分隔和续行
+func parse(r io.Reader, gbt bool) ([]*Case, error) {
+	var (
+		cases   []*Case
+		cur     *Case
+		last    string // 最后一个字段名，用于续行
+		section string
+		params  = map[string]string{}
+		// inHeader 表示正处于连续的方括号行中，此时新的方括号行追加参数而不是替换
+		inHeader bool
+	)
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		raw := strings.TrimSuffix(sc.Text(), "\r")
+		line := strings.TrimSpace(raw)
+
+		switch {
+		case line == "" || line[0] == '#':
+			if line == "" {
+				cur, last = nil, ""
+			}
+			continue
+
+		case gbt && cur != nil && last != "" && raw[0] != line[0]:
+			// 以空白开头的续行追加到上一个字段
+			cur.Fields[last] += " " + line
+			continue
+
+		case line[0] == '[':
+			if !strings.HasSuffix(line, "]") {
+				return nil, &SyntaxError{Line: n, Err: ErrSyntax}
+			}
+			if !inHeader {
+				params = map[string]string{}
+			}
+			inHeader = true
+			cur, last = nil, ""
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if k, v, ok := splitField(section, false); ok {
+				params[k] = v
+			}
+			continue
+		}
+
+		inHeader = false
+		if cur == nil {
+			cur = &Case{Section: section, Params: params, Fields: map[string]string{}, Line: n}
+			cases = append(cases, cur)
+		}
+		k, v, ok := splitField(line, gbt)
+		if !ok {
+			// 没有值的行，如FAIL
+			k, v = line, ""
+		}
+		if k == "" {
+			return nil, &SyntaxError{Line: n, Err: ErrSyntax}
+		}
+		cur.Fields[k] = v
+		last = k
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return cases, nil
+}
+
+// splitField 按第一个分隔符拆分"名称 = 值"，gbt为true时也接受半角和全角冒号
+func splitField(line string, gbt bool) (key, value string, ok bool) {
+	seps := "="
+	if gbt {
+		seps = "=:："
+	}
+	i := strings.IndexAny(line, seps)
+	if i < 0 {
+		return "", "", false
+	}
+	_, size := utf8.DecodeRuneInString(line[i:])
+	return strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+size:]), true
+}
@@ -0,0 +1,209 @@
+package vectors
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/laenix/gsc/aes"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/modes"
+	"github.com/laenix/gsc/sm3"
+	"github.com/laenix/gsc/sm4"
+)
+
+// mustHex 读取十六进制字段，失败时测试立即失败
+func mustHex(t *testing.T, c *Case, name string) []byte {
+	t.Helper()
+	b, err := c.Hex(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestParseRSP(t *testing.T) {
+	const data = `# CAVS 11.1
+[Keylen = 128]
+[IVlen = 96]
+
+Count = 0
+Key = 00ff
+CT =
+
+[DECRYPT]
+
+COUNT = 1
+FAIL
+`
+	cases, err := ParseRSP(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) != 2 {
+		t.Fatalf("解析出%d个用例，期望2个", len(cases))
+	}
+	c := cases[0]
+	if c.Line != 5 || c.Section != "IVlen = 96" {
+		t.Errorf("第一个用例: 行号%d，分组%q", c.Line, c.Section)
+	}
+	if n, _ := c.Param("Keylen"); n != 128 {
+		t.Errorf("Keylen = %d，期望128", n)
+	}
+	if b, _ := c.Hex("Key"); !bytes.Equal(b, []byte{0x00, 0xff}) {
+		t.Errorf("Key = %x", b)
+	}
+	if b, err := c.Hex("CT"); err != nil || len(b) != 0 {
+		t.Errorf("空字段应解码为空切片: %x, %v", b, err)
+	}
+
+	c = cases[1]
+	if c.Section != "DECRYPT" || len(c.Params) != 0 {
+		t.Errorf("新的方括号组应替换参数: %q %v", c.Section, c.Params)
+	}
+	if !c.Has("FAIL") {
+		t.Error("没有值的行应作为字段记录")
+	}
+	if _, err := c.Hex("Key"); !errors.Is(err, ErrMissingField) {
+		t.Errorf("期望ErrMissingField，实际: %v", err)
+	}
+}
+
+func TestParseGBT(t *testing.T) {
+	const data = "[示例]\n明文：0123 4567\n      89ab\n次数: 3\n"
+	cases, err := ParseGBT(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) != 1 {
+		t.Fatalf("解析出%d个用例，期望1个", len(cases))
+	}
+	if b, _ := cases[0].Hex("明文"); !bytes.Equal(b, []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab}) {
+		t.Errorf("续行未正确拼接: %x", b)
+	}
+	if n, _ := cases[0].Int("次数"); n != 3 {
+		t.Errorf("次数 = %d，期望3", n)
+	}
+
+	// 格式错误的行返回带行号的SyntaxError
+	if _, err := ParseRSP(strings.NewReader("[ENCRYPT\n")); !errors.Is(err, gscerr.ErrMalformed) {
+		t.Errorf("期望ErrMalformed类别，实际: %v", err)
+	}
+	var se *SyntaxError
+	if _, err := ParseRSP(strings.NewReader("\n= 00\n")); !errors.As(err, &se) || se.Line != 2 {
+		t.Errorf("期望第2行的SyntaxError，实际: %v", err)
+	}
+}
+
+// AESVS ECBVarTxt128（节选）
+func TestAESECB(t *testing.T) {
+	Run(t, "testdata/ECBVarTxt128.rsp", func(t *testing.T, c *Case) {
+		block, err := aes.New(mustHex(t, c, "KEY"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pt, ct := mustHex(t, c, "PLAINTEXT"), mustHex(t, c, "CIPHERTEXT")
+		if c.Section == "DECRYPT" {
+			if got, _ := block.Decrypt(ct); !bytes.Equal(got, pt) {
+				t.Errorf("解密结果 %x，期望 %x", got, pt)
+			}
+			return
+		}
+		if got, _ := block.Encrypt(pt); !bytes.Equal(got, ct) {
+			t.Errorf("加密结果 %x，期望 %x", got, ct)
+		}
+	})
+}
+
+// newGCM 按用例的Taglen参数创建AES-GCM
+func newGCM(t *testing.T, c *Case) *modes.GCM {
+	t.Helper()
+	block, err := aes.New(mustHex(t, c, "Key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tagLen, err := c.Param("Taglen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := modes.NewGCMWithTagSize(block, tagLen/8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// GCMVS gcmEncryptExtIV128（节选）
+func TestGCMEncrypt(t *testing.T) {
+	Run(t, "testdata/gcmEncryptExtIV128.rsp", func(t *testing.T, c *Case) {
+		g := newGCM(t, c)
+		got, err := g.Seal(mustHex(t, c, "IV"), mustHex(t, c, "PT"), mustHex(t, c, "AAD"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := append(mustHex(t, c, "CT"), mustHex(t, c, "Tag")...)
+		if !bytes.Equal(got, want) {
+			t.Errorf("Seal结果 %x，期望 %x", got, want)
+		}
+	})
+}
+
+// GCMVS gcmDecrypt128（节选），FAIL用例必须认证失败
+func TestGCMDecrypt(t *testing.T) {
+	Run(t, "testdata/gcmDecrypt128.rsp", func(t *testing.T, c *Case) {
+		g := newGCM(t, c)
+		ciphertext := append(mustHex(t, c, "CT"), mustHex(t, c, "Tag")...)
+		got, err := g.Open(mustHex(t, c, "IV"), ciphertext, mustHex(t, c, "AAD"))
+		if c.Has("FAIL") {
+			if !errors.Is(err, gscerr.ErrAuthFailed) {
+				t.Errorf("期望认证失败，实际: %v", err)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := mustHex(t, c, "PT"); !bytes.Equal(got, want) {
+			t.Errorf("Open结果 %x，期望 %x", got, want)
+		}
+	})
+}
+
+// GB/T 32905-2016 附录A
+func TestSM3(t *testing.T) {
+	Run(t, "testdata/sm3.txt", func(t *testing.T, c *Case) {
+		h := sm3.New()
+		h.Write(mustHex(t, c, "消息"))
+		if got, want := h.Sum(nil), mustHex(t, c, "杂凑值"); !bytes.Equal(got, want) {
+			t.Errorf("杂凑值 %x，期望 %x", got, want)
+		}
+	})
+}
+
+// GB/T 32907-2016 附录A，示例2需要加密一百万次
+func TestSM4(t *testing.T) {
+	Run(t, "testdata/sm4.txt", func(t *testing.T, c *Case) {
+		rounds := 1
+		if c.Has("加密次数") {
+			if testing.Short() {
+				t.Skip("short模式下跳过")
+			}
+			var err error
+			if rounds, err = c.Int("加密次数"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		block, err := sm4.New(mustHex(t, c, "加密密钥"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := mustHex(t, c, "明文")
+		for range rounds {
+			block.EncryptBlocks(buf, buf)
+		}
+		if want := mustHex(t, c, "密文"); !bytes.Equal(buf, want) {
+			t.Errorf("密文 %x，期望 %x", buf, want)
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package x25519_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/laenix/gsc/kdf/hkdf"
+	"github.com/laenix/gsc/x25519"
+)
+
+func Example() {
+	alice, err := x25519.GenerateKey(nil)
+	if err != nil {
+		panic(err)
+	}
+	bob, err := x25519.GenerateKey(nil)
+	if err != nil {
+		panic(err)
+	}
+
+	// 双方各自用自己的私钥和对方的公钥计算相同的共享密钥
+	aliceShared, err := alice.ECDH(bob.PublicKey())
+	if err != nil {
+		panic(err)
+	}
+	bobShared, err := bob.ECDH(alice.PublicKey())
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(bytes.Equal(aliceShared, bobShared))
+
+	// 共享密钥经HKDF派生对称密钥，info中绑定双方公钥
+	info := append(alice.PublicKey().Bytes(), bob.PublicKey().Bytes()...)
+	key, err := hkdf.Key(sha256.New, aliceShared, nil, info, 32)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(len(key))
+	// Output:
+	// true
+	// 32
+}
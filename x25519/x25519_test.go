@@ -0,0 +1,195 @@
+package x25519
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+)
+
+func mustHex(t testing.TB, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// RFC 7748第5.2节的测试向量，第二组的u坐标最高位为1（应被忽略）
+func TestVectors(t *testing.T) {
+	for _, tc := range []struct{ scalar, point, out string }{
+		{
+			"a546e36bf0527c9d3b16154b82465edd62144c0ac1fc5a18506a2244ba449ac4",
+			"e6db6867583030db3594c1a424b15f7c726624ec26b3353b10a903a6d0ab1c4c",
+			"c3da55379de9c6908e94ea4df28d084f32eccf03491c71f754b4075577a28552",
+		},
+		{
+			"4b66e9d4d1b4673c5ad22691957d6af5c11b6421e0ea01d42ca4169e7918ba0d",
+			"e5210f12786811d3f4b7959d0538ae2c31dbe7106fc03c3efc4cd549c715a493",
+			"95cbde9476e8907d7aade45cb4b873f88b595a68799fa152e6f8f7647aac7957",
+		},
+	} {
+		got, err := X25519(mustHex(t, tc.scalar), mustHex(t, tc.point))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, mustHex(t, tc.out)) {
+			t.Fatalf("X25519结果错误: %x", got)
+		}
+	}
+}
+
+// RFC 7748第5.2节的迭代测试：k = X25519(k, u)，u取上一次的k
+func TestIterated(t *testing.T) {
+	k, u := Basepoint(), Basepoint()
+	for i := 1; i <= 1000; i++ {
+		out, err := X25519(k, u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k, u = out, k
+		switch i {
+		case 1:
+			if !bytes.Equal(k, mustHex(t, "422c8e7a6227d7bca1350b3e2bb7279f7897b87bb6854b783c60e80311ae3079")) {
+				t.Fatalf("1次迭代结果错误: %x", k)
+			}
+		case 1000:
+			if !bytes.Equal(k, mustHex(t, "684cf59ba83309552800ef566f2f4d3c1c3887c49360e3875f2eb94d99532c51")) {
+				t.Fatalf("1000次迭代结果错误: %x", k)
+			}
+		}
+	}
+}
+
+// RFC 7748第6.1节的Diffie-Hellman测试向量
+func TestECDH(t *testing.T) {
+	alice, err := NewPrivateKey(mustHex(t, "77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := NewPrivateKey(mustHex(t, "5dab087e624a8a4b79e17f8b83800ee66f3bb1292618b6fd1c2f8b27ff88e0eb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(alice.PublicKey().Bytes(), mustHex(t, "8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a")) {
+		t.Fatal("Alice的公钥错误")
+	}
+	if !bytes.Equal(bob.PublicKey().Bytes(), mustHex(t, "de9edb7d7b7dc1b4d35b61c2ece435373f8343c85b78674dadfc7e146f882b4f")) {
+		t.Fatal("Bob的公钥错误")
+	}
+	want := mustHex(t, "4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742")
+	for _, tc := range []struct {
+		priv *PrivateKey
+		pub  *PublicKey
+	}{
+		{alice, bob.PublicKey()},
+		{bob, alice.PublicKey()},
+	} {
+		shared, err := tc.priv.ECDH(tc.pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(shared, want) {
+			t.Fatalf("共享密钥错误: %x", shared)
+		}
+	}
+}
+
+// 与crypto/ecdh对比随机密钥的公钥和共享密钥
+func TestStdlibInterop(t *testing.T) {
+	for range 32 {
+		priv, err := GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdPriv, err := ecdh.X25519().NewPrivateKey(priv.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(priv.PublicKey().Bytes(), stdPriv.PublicKey().Bytes()) {
+			t.Fatal("公钥与crypto/ecdh不一致")
+		}
+
+		peer, err := ecdh.X25519().GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		peerPub, err := NewPublicKey(peer.PublicKey().Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		shared, err := priv.ECDH(peerPub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := peer.ECDH(stdPriv.PublicKey())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(shared, want) {
+			t.Fatal("共享密钥与crypto/ecdh不一致")
+		}
+	}
+}
+
+// 小阶点使共享密钥全为0，必须被拒绝（RFC 7748第6.1节）
+func TestLowOrderPoints(t *testing.T) {
+	priv, err := GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		"0100000000000000000000000000000000000000000000000000000000000000",
+		"e0eb7a7c3b41b8ae1656e3faf19fc46ada098deb9c32b1fd866205165f49b800",
+		"5f9c95bca3508c24b1d0b1559c83ef5b04445cc4581c8e86d8224eddd09f1157",
+		"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+		"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+	} {
+		pub, err := NewPublicKey(mustHex(t, s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := priv.ECDH(pub); err != ErrLowOrderPoint {
+			t.Fatalf("小阶点%s期望ErrLowOrderPoint，实际: %v", s, err)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := NewPrivateKey(make([]byte, 31)); err != ErrInvalidScalar {
+		t.Fatalf("私钥长度错误期望ErrInvalidScalar，实际: %v", err)
+	}
+	if _, err := NewPublicKey(make([]byte, 33)); err != ErrInvalidPoint {
+		t.Fatalf("公钥长度错误期望ErrInvalidPoint，实际: %v", err)
+	}
+	if _, err := X25519(make([]byte, 32), make([]byte, 31)); err != ErrInvalidPoint {
+		t.Fatalf("点长度错误期望ErrInvalidPoint，实际: %v", err)
+	}
+	if _, err := GenerateKey(bytes.NewReader(nil)); err == nil {
+		t.Fatal("随机数读取失败时应返回错误")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	priv, _ := GenerateKey(nil)
+	other, _ := GenerateKey(nil)
+	same, _ := NewPrivateKey(priv.Bytes())
+	if !priv.Equal(same) || !priv.PublicKey().Equal(same.Public()) {
+		t.Fatal("相同的密钥应相等")
+	}
+	if priv.Equal(other) || priv.PublicKey().Equal(other.PublicKey()) {
+		t.Fatal("不同的密钥不应相等")
+	}
+}
+
+func BenchmarkECDH(b *testing.B) {
+	priv, _ := GenerateKey(nil)
+	peer, _ := GenerateKey(nil)
+	pub := peer.PublicKey()
+	for b.Loop() {
+		priv.ECDH(pub)
+	}
+}
@@ -0,0 +1,195 @@
+// Package x448 实现RFC 7748的X448函数和基于它的Diffie-Hellman密钥交换
+//
+// 标量乘法使用常量时间的Montgomery阶梯，域运算与Ed448共用GF(2^448 - 2^224 - 1)的实现。
+// 密钥和共享密钥的格式与RFC 7748和OpenSSL相同。共享密钥是曲线上点的u坐标，
+// 不是均匀分布的字节串，应经过HKDF等密钥派生函数后再作为对称密钥使用
+package x448
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"io"
+
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+	"github.com/laenix/gsc/internal/edwards448/field"
+	"github.com/laenix/gsc/subtle"
+)
+
+// 错误定义
+var (
+	ErrInvalidScalar = gscerr.New(gscerr.ErrKeySize, "x448: invalid scalar length")
+	ErrInvalidPoint  = gscerr.New(gscerr.ErrKeySize, "x448: invalid point length")
+	ErrLowOrderPoint = gscerr.New(gscerr.ErrMalformed, "x448: low order point, shared secret is all zero")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrInvalidScalar: "x448: 标量长度无效",
+		ErrInvalidPoint:  "x448: 点编码长度无效",
+		ErrLowOrderPoint: "x448: 对方公钥是小阶点，共享密钥全为0",
+	})
+}
+
+// 标量和点编码的大小（字节）
+const (
+	// ScalarSize 是私钥（标量）的长度
+	ScalarSize = 56
+	// PointSize 是公钥和共享密钥（u坐标）的长度
+	PointSize = 56
+)
+
+// basepoint 是基点的u坐标5
+var basepoint = [PointSize]byte{5}
+
+// a24 是阶梯中使用的常数 (156326 - 2) / 4
+const a24 = 39081
+
+// scalarMult 以常量时间计算标量scalar与u坐标point的乘积（RFC 7748第5节）
+// 标量按规则修剪，不小于p的u坐标按模p约简
+func scalarMult(scalar, point []byte) []byte {
+	var k [ScalarSize]byte
+	copy(k[:], scalar)
+	k[0] &= 252
+	k[55] |= 128
+
+	var x1, x2, z2, x3, z3 field.Element
+	x1.SetBytes(point)
+	x2.One()
+	x3.Set(&x1)
+	z3.One()
+
+	var a, aa, b, bb, e, c, dd, da, cb field.Element
+	swap := 0
+	for t := 447; t >= 0; t-- {
+		kt := int(k[t/8] >> (t % 8) & 1)
+		swap ^= kt
+		x2.Swap(&x3, swap)
+		z2.Swap(&z3, swap)
+		swap = kt
+
+		a.Add(&x2, &z2)
+		aa.Square(&a)
+		b.Subtract(&x2, &z2)
+		bb.Square(&b)
+		e.Subtract(&aa, &bb)
+		c.Add(&x3, &z3)
+		dd.Subtract(&x3, &z3)
+		da.Multiply(&dd, &a)
+		cb.Multiply(&c, &b)
+
+		x3.Add(&da, &cb)
+		x3.Square(&x3)
+		z3.Subtract(&da, &cb)
+		z3.Square(&z3)
+		z3.Multiply(&z3, &x1)
+		x2.Multiply(&aa, &bb)
+		z2.Mult32(&e, a24)
+		z2.Add(&z2, &aa)
+		z2.Multiply(&z2, &e)
+	}
+	x2.Swap(&x3, swap)
+	z2.Swap(&z3, swap)
+
+	z2.Invert(&z2)
+	return x2.Multiply(&x2, &z2).Bytes()
+}
+
+// X448 计算RFC 7748的X448(scalar, point)，point为Basepoint时得到公钥
+// 结果全为0（point是小阶点）时返回ErrLowOrderPoint
+func X448(scalar, point []byte) ([]byte, error) {
+	if len(scalar) != ScalarSize {
+		return nil, ErrInvalidScalar
+	}
+	if len(point) != PointSize {
+		return nil, ErrInvalidPoint
+	}
+	out := scalarMult(scalar, point)
+	if subtle.ConstantTimeCompare(out, make([]byte, PointSize)) == 1 {
+		return nil, ErrLowOrderPoint
+	}
+	return out, nil
+}
+
+// Basepoint 返回基点的u坐标编码
+func Basepoint() []byte {
+	return bytes.Clone(basepoint[:])
+}
+
+// PublicKey 是X448公钥
+type PublicKey struct {
+	b []byte
+}
+
+// NewPublicKey 由56字节编码创建公钥，长度错误时返回ErrInvalidPoint
+func NewPublicKey(b []byte) (*PublicKey, error) {
+	if len(b) != PointSize {
+		return nil, ErrInvalidPoint
+	}
+	return &PublicKey{b: bytes.Clone(b)}, nil
+}
+
+// Bytes 返回公钥的编码
+func (k *PublicKey) Bytes() []byte {
+	return bytes.Clone(k.b)
+}
+
+// Equal 判断k和x是否是相同的公钥
+func (k *PublicKey) Equal(x crypto.PublicKey) bool {
+	xx, ok := x.(*PublicKey)
+	return ok && bytes.Equal(k.b, xx.b)
+}
+
+// PrivateKey 是X448私钥
+type PrivateKey struct {
+	b, pub []byte
+}
+
+// GenerateKey 生成私钥，random为nil时使用crypto/rand
+func GenerateKey(random io.Reader) (*PrivateKey, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	b := make([]byte, ScalarSize)
+	if _, err := io.ReadFull(random, b); err != nil {
+		return nil, err
+	}
+	return NewPrivateKey(b)
+}
+
+// NewPrivateKey 由56字节编码创建私钥，长度错误时返回ErrInvalidScalar
+func NewPrivateKey(b []byte) (*PrivateKey, error) {
+	if len(b) != ScalarSize {
+		return nil, ErrInvalidScalar
+	}
+	return &PrivateKey{b: bytes.Clone(b), pub: scalarMult(b, basepoint[:])}, nil
+}
+
+// Bytes 返回私钥的编码
+func (k *PrivateKey) Bytes() []byte {
+	return bytes.Clone(k.b)
+}
+
+// PublicKey 返回私钥对应的公钥
+func (k *PrivateKey) PublicKey() *PublicKey {
+	return &PublicKey{b: bytes.Clone(k.pub)}
+}
+
+// Public 返回私钥对应的公钥，与标准库私钥类型的Public方法一致
+func (k *PrivateKey) Public() crypto.PublicKey {
+	return k.PublicKey()
+}
+
+// Equal 判断k和x是否是相同的私钥
+func (k *PrivateKey) Equal(x crypto.PrivateKey) bool {
+	xx, ok := x.(*PrivateKey)
+	return ok && subtle.ConstantTimeCompare(k.b, xx.b) == 1
+}
+
+// ECDH 计算与对方公钥的共享密钥，对方公钥是小阶点时返回ErrLowOrderPoint
+// 返回值应输入HKDF等密钥派生函数，并在info中绑定双方公钥
+func (k *PrivateKey) ECDH(remote *PublicKey) ([]byte, error) {
+	return X448(k.b, remote.b)
+}
@@ -0,0 +1,177 @@
+package x448
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/laenix/gsc/vectors"
+)
+
+func mustHex(t testing.TB, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// RFC 7748第5.2节的测试向量
+func TestVectors(t *testing.T) {
+	got, err := X448(
+		mustHex(t, "3d262fddf9ec8e88495266fea19a34d28882acef045104d0d1aae121700a779c984c24f8cdd78fbff44943eba368f54b29259a4f1c600ad3"),
+		mustHex(t, "06fce640fa3487bfda5f6cf2d5263f8aad88334cbd07437f020f08f9814dc031ddbdc38c19c6da2583fa5429db94ada18aa7a7fb4ef8a086"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := mustHex(t, "ce3e4ff95a60dc6697da1db1d85e6afbdf79b50a2412d7546d5f239fe14fbaadeb445fc66a01b0779d98223961111e21766282f73dd96b6f")
+	if !bytes.Equal(got, want) {
+		t.Fatalf("X448结果错误: %x", got)
+	}
+}
+
+// RFC 7748第5.2节的迭代测试：k = X448(k, u)，u取上一次的k
+func TestIterated(t *testing.T) {
+	k, u := Basepoint(), Basepoint()
+	for i := 1; i <= 1000; i++ {
+		out, err := X448(k, u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k, u = out, k
+		switch i {
+		case 1:
+			if !bytes.Equal(k, mustHex(t, "3f482c8a9f19b01e6c46ee9711d9dc14fd4bf67af30765c2ae2b846a4d23a8cd0db897086239492caf350b51f833868b9bc2b3bca9cf4113")) {
+				t.Fatalf("1次迭代结果错误: %x", k)
+			}
+		case 1000:
+			if !bytes.Equal(k, mustHex(t, "aa3b4749d55b9daf1e5b00288826c467274ce3ebbdd5c17b975e09d4af6c67cf10d087202db88286e2b79fceea3ec353ef54faa26e219f38")) {
+				t.Fatalf("1000次迭代结果错误: %x", k)
+			}
+		}
+	}
+}
+
+// RFC 7748第6.2节的Diffie-Hellman测试向量
+func TestECDH(t *testing.T) {
+	alice, err := NewPrivateKey(mustHex(t, "9a8f4925d1519f5775cf46b04b5800d4ee9ee8bae8bc5565d498c28dd9c9baf574a9419744897391006382a6f127ab1d9ac2d8c0a598726b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := NewPrivateKey(mustHex(t, "1c306a7ac2a0e2e0990b294470cba339e6453772b075811d8fad0d1d6927c120bb5ee8972b0d3e21374c9c921b09d1b0366f10b65173992d"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(alice.PublicKey().Bytes(), mustHex(t, "9b08f7cc31b7e3e67d22d5aea121074a273bd2b83de09c63faa73d2c22c5d9bbc836647241d953d40c5b12da88120d53177f80e532c41fa0")) {
+		t.Fatal("Alice的公钥错误")
+	}
+	if !bytes.Equal(bob.PublicKey().Bytes(), mustHex(t, "3eb7a829b0cd20f5bcfc0b599b6feccf6da4627107bdb0d4f345b43027d8b972fc3e34fb4232a13ca706dcb57aec3dae07bdc1c67bf33609")) {
+		t.Fatal("Bob的公钥错误")
+	}
+	want := mustHex(t, "07fff4181ac6cc95ec1c16a94a0f74d12da232ce40a77552281d282bb60c0b56fd2464c335543936521c24403085d59a449a5037514a879d")
+	for _, tc := range []struct {
+		priv *PrivateKey
+		pub  *PublicKey
+	}{
+		{alice, bob.PublicKey()},
+		{bob, alice.PublicKey()},
+	} {
+		shared, err := tc.priv.ECDH(tc.pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(shared, want) {
+			t.Fatalf("共享密钥错误: %x", shared)
+		}
+	}
+}
+
+// 测试OpenSSL生成的共享密钥
+func TestOpenSSLVectors(t *testing.T) {
+	vectors.Run(t, "testdata/openssl.rsp", func(t *testing.T, c *vectors.Case) {
+		priv, err := c.Hex("Priv")
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub, err := c.Hex("Pub")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := c.Hex("Shared")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := X448(priv, pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("共享密钥不一致: %x", got)
+		}
+	})
+}
+
+// 小阶点使共享密钥全为0，必须被拒绝（RFC 7748第6.2节）
+func TestLowOrderPoints(t *testing.T) {
+	priv, err := GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pMinus1 := bytes.Repeat([]byte{0xff}, PointSize)
+	pMinus1[0] = 0xfe
+	pMinus1[28] = 0xfe
+	p := bytes.Clone(pMinus1)
+	p[0] = 0xff
+	for _, b := range [][]byte{
+		make([]byte, PointSize),
+		append([]byte{1}, make([]byte, PointSize-1)...),
+		pMinus1,
+		p,
+	} {
+		pub, err := NewPublicKey(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := priv.ECDH(pub); err != ErrLowOrderPoint {
+			t.Fatalf("小阶点%x期望ErrLowOrderPoint，实际: %v", b, err)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := NewPrivateKey(make([]byte, 32)); err != ErrInvalidScalar {
+		t.Fatalf("私钥长度错误期望ErrInvalidScalar，实际: %v", err)
+	}
+	if _, err := NewPublicKey(make([]byte, 57)); err != ErrInvalidPoint {
+		t.Fatalf("公钥长度错误期望ErrInvalidPoint，实际: %v", err)
+	}
+	if _, err := X448(make([]byte, 56), make([]byte, 32)); err != ErrInvalidPoint {
+		t.Fatalf("点长度错误期望ErrInvalidPoint，实际: %v", err)
+	}
+	if _, err := GenerateKey(bytes.NewReader(nil)); err == nil {
+		t.Fatal("随机数读取失败时应返回错误")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	priv, _ := GenerateKey(nil)
+	other, _ := GenerateKey(nil)
+	same, _ := NewPrivateKey(priv.Bytes())
+	if !priv.Equal(same) || !priv.PublicKey().Equal(same.Public()) {
+		t.Fatal("相同的密钥应相等")
+	}
+	if priv.Equal(other) || priv.PublicKey().Equal(other.PublicKey()) {
+		t.Fatal("不同的密钥不应相等")
+	}
+}
+
+func BenchmarkECDH(b *testing.B) {
+	priv, _ := GenerateKey(nil)
+	peer, _ := GenerateKey(nil)
+	pub := peer.PublicKey()
+	for b.Loop() {
+		priv.ECDH(pub)
+	}
+}
@@ -0,0 +1,439 @@
+package x509
+
+import (
+	"bytes"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/laenix/gsc/der"
+)
+
+// KeyUsage 是密钥用途扩展中的位，取值与crypto/x509相同
+type KeyUsage int
+
+// 密钥用途（RFC 5280第4.2.1.3节）
+const (
+	KeyUsageDigitalSignature KeyUsage = 1 << iota
+	KeyUsageContentCommitment
+	KeyUsageKeyEncipherment
+	KeyUsageDataEncipherment
+	KeyUsageKeyAgreement
+	KeyUsageCertSign
+	KeyUsageCRLSign
+	KeyUsageEncipherOnly
+	KeyUsageDecipherOnly
+)
+
+// ExtKeyUsage 是扩展密钥用途
+type ExtKeyUsage int
+
+// 扩展密钥用途（RFC 5280第4.2.1.12节）
+const (
+	ExtKeyUsageAny ExtKeyUsage = iota
+	ExtKeyUsageServerAuth
+	ExtKeyUsageClientAuth
+	ExtKeyUsageCodeSigning
+	ExtKeyUsageEmailProtection
+	ExtKeyUsageTimeStamping
+	ExtKeyUsageOCSPSigning
+)
+
+// extKeyUsageOIDs 是扩展密钥用途的OID
+var extKeyUsageOIDs = map[ExtKeyUsage]der.OID{
+	ExtKeyUsageAny:             {2, 5, 29, 37, 0},
+	ExtKeyUsageServerAuth:      {1, 3, 6, 1, 5, 5, 7, 3, 1},
+	ExtKeyUsageClientAuth:      {1, 3, 6, 1, 5, 5, 7, 3, 2},
+	ExtKeyUsageCodeSigning:     {1, 3, 6, 1, 5, 5, 7, 3, 3},
+	ExtKeyUsageEmailProtection: {1, 3, 6, 1, 5, 5, 7, 3, 4},
+	ExtKeyUsageTimeStamping:    {1, 3, 6, 1, 5, 5, 7, 3, 8},
+	ExtKeyUsageOCSPSigning:     {1, 3, 6, 1, 5, 5, 7, 3, 9},
+}
+
+// 证书扩展的OID
+var (
+	oidExtSubjectKeyID      = der.OID{2, 5, 29, 14}
+	oidExtKeyUsage          = der.OID{2, 5, 29, 15}
+	oidExtSubjectAltName    = der.OID{2, 5, 29, 17}
+	oidExtBasicConstraints  = der.OID{2, 5, 29, 19}
+	oidExtExtKeyUsage       = der.OID{2, 5, 29, 37}
+	oidExtAuthorityKeyID    = der.OID{2, 5, 29, 35}
+	oidExtCertificatePolicy = der.OID{2, 5, 29, 32}
+)
+
+// subjectAltName中GeneralName的上下文标签
+const (
+	nameTypeEmail = 1
+	nameTypeDNS   = 2
+	nameTypeIP    = 7
+)
+
+// Extension 是证书扩展
+type Extension struct {
+	ID       der.OID
+	Critical bool
+	Value    []byte
+}
+
+// Certificate 是解析后的X.509证书，也用作CreateCertificate的模板
+type Certificate struct {
+	Raw                     []byte // 完整的DER编码
+	RawTBSCertificate       []byte // 被签名的TBSCertificate
+	RawSubjectPublicKeyInfo []byte
+	RawSubject              []byte
+	RawIssuer               []byte
+
+	Signature          []byte
+	SignatureAlgorithm SignatureAlgorithm
+	PublicKey          any
+
+	Version      int
+	SerialNumber *big.Int
+	Issuer       pkix.Name
+	Subject      pkix.Name
+	NotBefore    time.Time
+	NotAfter     time.Time
+
+	KeyUsage    KeyUsage
+	ExtKeyUsage []ExtKeyUsage
+
+	// BasicConstraintsValid 表示存在基本约束扩展，此时IsCA和路径长度有效
+	BasicConstraintsValid bool
+	IsCA                  bool
+	// MaxPathLen 是CA下允许的中间CA数量，-1表示不限制；
+	// 作为模板时0表示不限制，除非MaxPathLenZero为true
+	MaxPathLen     int
+	MaxPathLenZero bool
+
+	SubjectKeyId   []byte
+	AuthorityKeyId []byte
+
+	DNSNames       []string
+	EmailAddresses []string
+	IPAddresses    []net.IP
+
+	// Extensions 是证书中的全部扩展，解析时填写
+	Extensions []Extension
+	// ExtraExtensions 在签发时原样写入证书，覆盖同OID的自动生成扩展
+	ExtraExtensions []Extension
+
+	// unhandledCritical 是本包不认识的关键扩展，验证证书链时拒绝
+	unhandledCritical []der.OID
+}
+
+// Equal 判断两个证书的编码是否相同
+func (c *Certificate) Equal(other *Certificate) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+	return bytes.Equal(c.Raw, other.Raw)
+}
+
+// CheckSignatureFrom 验证c的签名是由parent的私钥生成的，不检查parent是否为CA
+func (c *Certificate) CheckSignatureFrom(parent *Certificate) error {
+	return parent.CheckSignature(c.SignatureAlgorithm, c.RawTBSCertificate, c.Signature)
+}
+
+// CheckSignature 使用c的公钥验证signed的签名
+func (c *Certificate) CheckSignature(alg SignatureAlgorithm, signed, signature []byte) error {
+	return checkSignature(alg, c.PublicKey, signed, signature)
+}
+
+// ParseCertificate 解析一个DER编码的证书
+//
+//	Certificate ::= SEQUENCE {
+//	  tbsCertificate       TBSCertificate,
+//	  signatureAlgorithm   AlgorithmIdentifier,
+//	  signatureValue       BIT STRING }
+func ParseCertificate(data []byte) (*Certificate, error) {
+	p := der.NewParser(data)
+	raw, err := p.ReadRaw(der.TagSequence)
+	if err != nil || p.Finish() != nil {
+		return nil, ErrMalformed
+	}
+	c := &Certificate{Raw: raw}
+
+	seq, _ := der.NewParser(raw).ReadSequence()
+	if c.RawTBSCertificate, err = seq.ReadRaw(der.TagSequence); err != nil {
+		return nil, ErrMalformed
+	}
+	outerAlg, err := readSignatureAlgorithm(seq)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	if c.Signature, err = seq.ReadBitString(); err != nil || seq.Finish() != nil {
+		return nil, ErrMalformed
+	}
+	if err := c.parseTBS(); err != nil {
+		return nil, err
+	}
+	if c.SignatureAlgorithm != outerAlg {
+		return nil, ErrMalformed
+	}
+	return c, nil
+}
+
+// parseTBS 解析TBSCertificate
+//
+//	TBSCertificate ::= SEQUENCE {
+//	  version         [0]  EXPLICIT Version DEFAULT v1,
+//	  serialNumber         CertificateSerialNumber,
+//	  signature            AlgorithmIdentifier,
+//	  issuer               Name,
+//	  validity             Validity,
+//	  subject              Name,
+//	  subjectPublicKeyInfo SubjectPublicKeyInfo,
+//	  issuerUniqueID  [1]  IMPLICIT UniqueIdentifier OPTIONAL,
+//	  subjectUniqueID [2]  IMPLICIT UniqueIdentifier OPTIONAL,
+//	  extensions      [3]  EXPLICIT Extensions OPTIONAL }
+func (c *Certificate) parseTBS() error {
+	tbs, _ := der.NewParser(c.RawTBSCertificate).ReadSequence()
+
+	c.Version = 1
+	v, present, err := tbs.ReadExplicit(0)
+	if err != nil {
+		return ErrMalformed
+	}
+	if present {
+		n, err := v.ReadInt64()
+		if err != nil || v.Finish() != nil || n < 0 || n > 2 {
+			return ErrMalformed
+		}
+		c.Version = int(n) + 1
+	}
+
+	if c.SerialNumber, err = tbs.ReadInteger(); err != nil {
+		return ErrMalformed
+	}
+	if c.SignatureAlgorithm, err = readSignatureAlgorithm(tbs); err != nil {
+		return ErrMalformed
+	}
+	if c.RawIssuer, err = readName(tbs, &c.Issuer); err != nil {
+		return err
+	}
+	validity, err := tbs.ReadSequence()
+	if err != nil {
+		return ErrMalformed
+	}
+	if c.NotBefore, err = validity.ReadTime(); err != nil {
+		return ErrMalformed
+	}
+	if c.NotAfter, err = validity.ReadTime(); err != nil || validity.Finish() != nil {
+		return ErrMalformed
+	}
+	if c.RawSubject, err = readName(tbs, &c.Subject); err != nil {
+		return err
+	}
+	if c.RawSubjectPublicKeyInfo, err = tbs.ReadRaw(der.TagSequence); err != nil {
+		return ErrMalformed
+	}
+	if c.PublicKey, err = ParsePKIXPublicKey(c.RawSubjectPublicKeyInfo); err != nil {
+		return err
+	}
+	for _, tag := range []der.Tag{der.Context(1, false), der.Context(2, false)} {
+		if _, _, err := tbs.ReadOptional(tag); err != nil {
+			return ErrMalformed
+		}
+	}
+
+	c.MaxPathLen = -1
+	exts, present, err := tbs.ReadExplicit(3)
+	if err != nil || tbs.Finish() != nil {
+		return ErrMalformed
+	}
+	if present {
+		if c.Version != 3 {
+			return ErrMalformed
+		}
+		if err := c.parseExtensions(exts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readName 读取Name，返回其原始编码并填写name
+func readName(p *der.Parser, name *pkix.Name) ([]byte, error) {
+	raw, err := p.ReadRaw(der.TagSequence)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	var rdn pkix.RDNSequence
+	if rest, err := asn1.Unmarshal(raw, &rdn); err != nil || len(rest) > 0 {
+		return nil, ErrMalformed
+	}
+	name.FillFromRDNSequence(&rdn)
+	return raw, nil
+}
+
+// parseExtensions 解析扩展列表并填写已知扩展对应的字段
+func (c *Certificate) parseExtensions(p *der.Parser) error {
+	var err error
+	if c.Extensions, err = readExtensions(p); err != nil {
+		return err
+	}
+	for _, ext := range c.Extensions {
+		handled, err := c.parseExtension(ext)
+		if err != nil {
+			return err
+		}
+		if !handled && ext.Critical {
+			c.unhandledCritical = append(c.unhandledCritical, ext.ID)
+		}
+	}
+	return nil
+}
+
+// readExtensions 读取扩展列表，同一OID出现多次时返回ErrMalformed
+//
+//	Extensions ::= SEQUENCE SIZE (1..MAX) OF Extension
+//	Extension ::= SEQUENCE {
+//	  extnID      OBJECT IDENTIFIER,
+//	  critical    BOOLEAN DEFAULT FALSE,
+//	  extnValue   OCTET STRING }
+func readExtensions(p *der.Parser) ([]Extension, error) {
+	list, err := p.ReadSequence()
+	if err != nil || p.Finish() != nil {
+		return nil, ErrMalformed
+	}
+	var exts []Extension
+	for !list.Empty() {
+		seq, err := list.ReadSequence()
+		if err != nil {
+			return nil, ErrMalformed
+		}
+		var ext Extension
+		if ext.ID, err = seq.ReadOID(); err != nil {
+			return nil, ErrMalformed
+		}
+		if tag, _ := seq.PeekTag(); tag == der.TagBoolean {
+			if ext.Critical, err = seq.ReadBoolean(); err != nil {
+				return nil, ErrMalformed
+			}
+		}
+		if ext.Value, err = seq.ReadOctetString(); err != nil || seq.Finish() != nil {
+			return nil, ErrMalformed
+		}
+		for _, prev := range exts {
+			if prev.ID.Equal(ext.ID) {
+				return nil, ErrMalformed
+			}
+		}
+		exts = append(exts, ext)
+	}
+	return exts, nil
+}
+
+// parseExtension 解析一个已知扩展，不认识的扩展返回false
+func (c *Certificate) parseExtension(ext Extension) (bool, error) {
+	p := der.NewParser(ext.Value)
+	var err error
+	switch {
+	case ext.ID.Equal(oidExtKeyUsage):
+		var bits der.BitString
+		if bits, err = p.ReadBits(); err == nil {
+			for i := 0; i < bits.BitLength && i < 9; i++ {
+				if bits.Bytes[i/8]&(0x80>>(i%8)) != 0 {
+					c.KeyUsage |= 1 << i
+				}
+			}
+		}
+
+	case ext.ID.Equal(oidExtBasicConstraints):
+		err = c.parseBasicConstraints(p)
+
+	case ext.ID.Equal(oidExtSubjectAltName):
+		c.DNSNames, c.EmailAddresses, c.IPAddresses, err = parseSubjectAltName(p)
+
+	case ext.ID.Equal(oidExtExtKeyUsage):
+		var seq *der.Parser
+		if seq, err = p.ReadSequence(); err == nil {
+			for !seq.Empty() && err == nil {
+				var oid der.OID
+				if oid, err = seq.ReadOID(); err == nil {
+					for usage, known := range extKeyUsageOIDs {
+						if known.Equal(oid) {
+							c.ExtKeyUsage = append(c.ExtKeyUsage, usage)
+						}
+					}
+				}
+			}
+		}
+
+	case ext.ID.Equal(oidExtSubjectKeyID):
+		c.SubjectKeyId, err = p.ReadOctetString()
+
+	case ext.ID.Equal(oidExtAuthorityKeyID):
+		// AuthorityKeyIdentifier ::= SEQUENCE { keyIdentifier [0] IMPLICIT OCTET STRING OPTIONAL, ... }
+		var seq *der.Parser
+		if seq, err = p.ReadSequence(); err == nil {
+			c.AuthorityKeyId, _, err = seq.ReadOptional(der.Context(0, false))
+		}
+
+	case ext.ID.Equal(oidExtCertificatePolicy):
+		// 证书策略只做识别，不参与验证
+		_, err = p.ReadSequence()
+
+	default:
+		return false, nil
+	}
+	if err != nil || p.Finish() != nil {
+		return false, ErrMalformed
+	}
+	return true, nil
+}
+
+// parseBasicConstraints 解析基本约束扩展
+//
+//	BasicConstraints ::= SEQUENCE {
+//	  cA                BOOLEAN DEFAULT FALSE,
+//	  pathLenConstraint INTEGER (0..MAX) OPTIONAL }
+func (c *Certificate) parseBasicConstraints(p *der.Parser) error {
+	seq, err := p.ReadSequence()
+	if err != nil {
+		return err
+	}
+	c.BasicConstraintsValid = true
+	if tag, _ := seq.PeekTag(); tag == der.TagBoolean {
+		if c.IsCA, err = seq.ReadBoolean(); err != nil {
+			return err
+		}
+	}
+	if !seq.Empty() {
+		n, err := seq.ReadInt64()
+		if err != nil || n < 0 || n > 1<<20 {
+			return ErrMalformed
+		}
+		c.MaxPathLen = int(n)
+		c.MaxPathLenZero = n == 0
+	}
+	return seq.Finish()
+}
+
+// parseSubjectAltName 解析主体备用名称扩展中的DNS名称、电子邮件地址和IP地址，忽略其他名称类型
+func parseSubjectAltName(p *der.Parser) (dns, emails []string, ips []net.IP, err error) {
+	seq, err := p.ReadSequence()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for !seq.Empty() {
+		tag, content, _, err := seq.ReadElement()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		switch tag {
+		case der.Context(nameTypeDNS, false):
+			dns = append(dns, string(content))
+		case der.Context(nameTypeEmail, false):
+			emails = append(emails, string(content))
+		case der.Context(nameTypeIP, false):
+			if len(content) != net.IPv4len && len(content) != net.IPv6len {
+				return nil, nil, nil, ErrMalformed
+			}
+			ips = append(ips, net.IP(content))
+		}
+	}
+	return dns, emails, ips, nil
+}
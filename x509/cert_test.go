@@ -0,0 +1,339 @@
+package x509
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	stdx509 "crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/laenix/gsc/der"
+	gsced25519 "github.com/laenix/gsc/ed25519"
+	gscrsa "github.com/laenix/gsc/rsa"
+	"github.com/laenix/gsc/sm2"
+)
+
+var (
+	testNotBefore = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	testNotAfter  = time.Date(2034, 1, 1, 0, 0, 0, 0, time.UTC)
+	testNow       = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// newCA 签发自签名CA证书
+func newCA(t *testing.T, priv any, pub any, name string) *Certificate {
+	t.Helper()
+	template := &Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Country: []string{"CN"}, Organization: []string{"gsc"}, CommonName: name},
+		NotBefore:             testNotBefore,
+		NotAfter:              testNotAfter,
+		KeyUsage:              KeyUsageCertSign | KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	data, err := CreateCertificate(template, template, pub, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := ParseCertificate(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// newLeaf 由parent签发服务器证书
+func newLeaf(t *testing.T, parent *Certificate, parentPriv, pub any) *Certificate {
+	t.Helper()
+	template := &Certificate{
+		SerialNumber:   big.NewInt(2),
+		Subject:        pkix.Name{CommonName: "www.example.com"},
+		NotBefore:      testNotBefore,
+		NotAfter:       testNotAfter,
+		KeyUsage:       KeyUsageDigitalSignature | KeyUsageKeyAgreement,
+		ExtKeyUsage:    []ExtKeyUsage{ExtKeyUsageServerAuth},
+		DNSNames:       []string{"www.example.com", "*.api.example.com"},
+		EmailAddresses: []string{"admin@example.com"},
+		IPAddresses:    []net.IP{net.ParseIP("192.0.2.1"), net.ParseIP("2001:db8::1")},
+	}
+	data, err := CreateCertificate(template, parent, pub, parentPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := ParseCertificate(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestSM2Certificate(t *testing.T) {
+	caKey, _ := sm2.New().GenerateKey(nil)
+	leafKey, _ := sm2.New().GenerateKey(nil)
+	ca := newCA(t, caKey, &caKey.PublicKey, "gsc SM2 CA")
+	leaf := newLeaf(t, ca, caKey, &leafKey.PublicKey)
+
+	if ca.SignatureAlgorithm != SM2WithSM3 || ca.Version != 3 || ca.Subject.CommonName != "gsc SM2 CA" {
+		t.Errorf("CA: %v %d %v", ca.SignatureAlgorithm, ca.Version, ca.Subject)
+	}
+	if !ca.IsCA || ca.MaxPathLen != -1 || ca.KeyUsage != KeyUsageCertSign|KeyUsageCRLSign || len(ca.SubjectKeyId) != 20 {
+		t.Errorf("CA扩展: %+v", ca)
+	}
+	if pub := leaf.PublicKey.(*sm2.PublicKey); pub.X.Cmp(leafKey.X) != 0 {
+		t.Error("公钥不一致")
+	}
+	if !slices.Equal(leaf.AuthorityKeyId, ca.SubjectKeyId) || leaf.IsCA || leaf.BasicConstraintsValid {
+		t.Errorf("叶子证书扩展: %+v", leaf)
+	}
+	if !slices.Equal(leaf.DNSNames, []string{"www.example.com", "*.api.example.com"}) ||
+		leaf.EmailAddresses[0] != "admin@example.com" || len(leaf.IPAddresses) != 2 ||
+		!slices.Equal(leaf.ExtKeyUsage, []ExtKeyUsage{ExtKeyUsageServerAuth}) {
+		t.Errorf("叶子证书名称: %+v", leaf)
+	}
+	if err := leaf.CheckSignatureFrom(ca); err != nil {
+		t.Error(err)
+	}
+	if err := leaf.CheckSignatureFrom(leaf); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("错误的签发者: %v", err)
+	}
+
+	// 私钥与签发者证书不匹配
+	other, _ := sm2.New().GenerateKey(nil)
+	if _, err := CreateCertificate(&Certificate{SerialNumber: big.NewInt(3)}, ca, &leafKey.PublicKey, other); !errors.Is(err, ErrKeyMismatch) {
+		t.Errorf("私钥不匹配: %v", err)
+	}
+}
+
+// 测试解析并验证OpenSSL签发的SM2自签名证书（openssl req -x509 -sm3）
+func TestOpenSSLSM2Certificate(t *testing.T) {
+	data, err := os.ReadFile("testdata/sm2_ca.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(data)
+	c, err := ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.SignatureAlgorithm != SM2WithSM3 || c.Subject.CommonName != "gsc SM2 Root" || !c.IsCA {
+		t.Errorf("%v %v", c.SignatureAlgorithm, c.Subject)
+	}
+	if pub := c.PublicKey.(*sm2.PublicKey); pub.X.Text(16) != "cbefc94c6c798876f2303267e4ffcdfb9794b3b5373afc42e45c95e7959f9f2e" {
+		t.Errorf("公钥 %x", pub.X)
+	}
+	if err := c.CheckSignatureFrom(c); err != nil {
+		t.Errorf("OpenSSL的SM2签名验证失败: %v", err)
+	}
+
+	roots := NewCertPool()
+	if !roots.AppendCertsFromPEM(data) {
+		t.Fatal("AppendCertsFromPEM")
+	}
+	if _, err := c.Verify(VerifyOptions{Roots: roots}); err != nil {
+		t.Error(err)
+	}
+}
+
+// 测试RSA、ECDSA和Ed25519证书与crypto/x509互通
+func TestStandardInterop(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	edPub, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	for _, tt := range []struct {
+		priv, pub any
+		alg       SignatureAlgorithm
+	}{
+		{rsaKey, &rsaKey.PublicKey, SHA256WithRSA},
+		{p256, &p256.PublicKey, ECDSAWithSHA256},
+		{p384, &p384.PublicKey, ECDSAWithSHA384},
+		{edKey, edPub, PureEd25519},
+	} {
+		ca := newCA(t, tt.priv, tt.pub, "gsc CA")
+		leaf := newLeaf(t, ca, tt.priv, &p256.PublicKey)
+		if ca.SignatureAlgorithm != tt.alg {
+			t.Errorf("%T: 签名算法 %v", tt.priv, ca.SignatureAlgorithm)
+		}
+
+		// 本包签发的证书由crypto/x509解析和验证
+		stdCA, err := stdx509.ParseCertificate(ca.Raw)
+		if err != nil {
+			t.Fatalf("%v: %v", tt.alg, err)
+		}
+		stdLeaf, err := stdx509.ParseCertificate(leaf.Raw)
+		if err != nil {
+			t.Fatalf("%v: %v", tt.alg, err)
+		}
+		if err := stdLeaf.CheckSignatureFrom(stdCA); err != nil {
+			t.Errorf("%v: crypto/x509验证失败: %v", tt.alg, err)
+		}
+		if !slices.Equal(stdLeaf.DNSNames, leaf.DNSNames) || !stdCA.IsCA || stdLeaf.KeyUsage != stdx509.KeyUsage(leaf.KeyUsage) {
+			t.Errorf("%v: crypto/x509解析结果不同", tt.alg)
+		}
+
+		// crypto/x509签发的证书由本包解析和验证
+		template := &stdx509.Certificate{
+			SerialNumber: big.NewInt(9),
+			Subject:      pkix.Name{CommonName: "std"},
+			NotBefore:    testNotBefore,
+			NotAfter:     testNotAfter,
+			DNSNames:     []string{"std.example.com"},
+		}
+		data, err := stdx509.CreateCertificate(rand.Reader, template, stdCA, &p256.PublicKey, tt.priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := ParseCertificate(data)
+		if err != nil {
+			t.Fatalf("%v: %v", tt.alg, err)
+		}
+		if err := c.CheckSignatureFrom(ca); err != nil || c.DNSNames[0] != "std.example.com" {
+			t.Errorf("%v: %v", tt.alg, err)
+		}
+	}
+}
+
+// 测试gsc/rsa私钥签发证书以及RSASSA-PSS签名算法与crypto/x509互通
+func TestRSAPSSAndGSCKeys(t *testing.T) {
+	stdKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	key := gscrsa.FromStdPrivateKey(stdKey)
+
+	for _, alg := range []SignatureAlgorithm{SHA256WithRSA, SHA256WithRSAPSS, SHA384WithRSAPSS, SHA512WithRSAPSS} {
+		template := &Certificate{
+			SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: alg.String()},
+			NotBefore: testNotBefore, NotAfter: testNotAfter, SignatureAlgorithm: alg,
+		}
+		data, err := CreateCertificate(template, template, &key.PublicKey, key)
+		if err != nil {
+			t.Fatalf("%v: %v", alg, err)
+		}
+		c, err := ParseCertificate(data)
+		if err != nil {
+			t.Fatalf("%v: %v", alg, err)
+		}
+		if c.SignatureAlgorithm != alg || c.CheckSignatureFrom(c) != nil {
+			t.Errorf("%v: 解析得到%v", alg, c.SignatureAlgorithm)
+		}
+		std, err := stdx509.ParseCertificate(data)
+		if err != nil {
+			t.Fatalf("%v: %v", alg, err)
+		}
+		if err := std.CheckSignature(std.SignatureAlgorithm, std.RawTBSCertificate, std.Signature); err != nil || std.SignatureAlgorithm.String() != alg.String() || !stdKey.PublicKey.Equal(std.PublicKey) {
+			t.Errorf("%v: crypto/x509验证失败: %v", alg, err)
+		}
+	}
+
+	// crypto/x509签发的PSS证书
+	template := &stdx509.Certificate{
+		SerialNumber: big.NewInt(2), NotBefore: testNotBefore, NotAfter: testNotAfter,
+		SignatureAlgorithm: stdx509.SHA384WithRSAPSS,
+	}
+	data, err := stdx509.CreateCertificate(rand.Reader, template, template, &stdKey.PublicKey, stdKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := ParseCertificate(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.SignatureAlgorithm != SHA384WithRSAPSS || c.CheckSignatureFrom(c) != nil {
+		t.Errorf("crypto/x509的PSS证书: %v", c.SignatureAlgorithm)
+	}
+
+	// 盐长度与摘要长度不同的PSS参数不对应任何已知算法
+	pss, _ := gscrsa.MarshalPSSAlgorithm(gscrsa.SHA256, &gscrsa.PSSOptions{SaltLength: 20})
+	if alg, err := readSignatureAlgorithm(der.NewParser(pss)); err != nil || alg != UnknownSignatureAlgorithm {
+		t.Errorf("非标准PSS参数: %v, %v", alg, err)
+	}
+
+	// gsc/rsa的密钥与crypto/rsa的密钥编码相同
+	want, _ := MarshalPKCS8PrivateKey(stdKey)
+	if got, err := MarshalPKCS8PrivateKey(key); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("PKCS#8编码不同: %v", err)
+	}
+	want, _ = MarshalPKIXPublicKey(&stdKey.PublicKey)
+	if got, err := MarshalPKIXPublicKey(&key.PublicKey); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("SPKI编码不同: %v", err)
+	}
+}
+
+// 测试gsc/ed25519的密钥：签发的证书与crypto/ed25519密钥签发的逐字节相同，编码也相同
+func TestGSCEd25519(t *testing.T) {
+	edPub, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	key := gsced25519.PrivateKey(edKey)
+	pub := key.Public().(gsced25519.PublicKey)
+
+	template := &Certificate{
+		SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "Ed25519"},
+		NotBefore: testNotBefore, NotAfter: testNotAfter,
+	}
+	want, err := CreateCertificate(template, template, edPub, edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := CreateCertificate(template, template, pub, key)
+	if err != nil || !bytes.Equal(data, want) {
+		t.Fatalf("证书不同: %v", err)
+	}
+	c, _ := ParseCertificate(data)
+	if c.SignatureAlgorithm != PureEd25519 || c.CheckSignatureFrom(c) != nil || checkSignature(PureEd25519, pub, c.RawTBSCertificate, c.Signature) != nil {
+		t.Error("gsc/ed25519证书验证失败")
+	}
+
+	want, _ = MarshalPKCS8PrivateKey(edKey)
+	if got, err := MarshalPKCS8PrivateKey(key); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("PKCS#8编码不同: %v", err)
+	}
+	if _, _, err := signingParams(gsced25519.PrivateKey(edKey[:32]), 0); !errors.Is(err, ErrUnsupportedKeyType) {
+		t.Errorf("私钥长度错误: %v", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	key, _ := sm2.New().GenerateKey(nil)
+	ca := newCA(t, key, &key.PublicKey, "gsc")
+
+	for _, data := range [][]byte{nil, {0x30, 0x00}, ca.Raw[:len(ca.Raw)-1], append(ca.Raw[:len(ca.Raw):len(ca.Raw)], 0)} {
+		if _, err := ParseCertificate(data); !errors.Is(err, ErrMalformed) {
+			t.Errorf("%d字节: %v", len(data), err)
+		}
+	}
+
+	// 重复的扩展
+	dup := &Certificate{
+		SerialNumber: big.NewInt(1), NotBefore: testNotBefore, NotAfter: testNotAfter,
+		ExtraExtensions: []Extension{{ID: der.OID{1, 2, 3}, Value: []byte{5, 0}}},
+	}
+	dup.ExtraExtensions = append(dup.ExtraExtensions, Extension{ID: der.OID{1, 2, 3, 5}, Value: []byte{5, 0}})
+	data, err := CreateCertificate(dup, dup, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 把1.2.3.5改为1.2.3，解析不检查签名
+	data = bytes.Replace(data, []byte{6, 3, 0x2a, 3, 5}, []byte{6, 2, 0x2a, 3}, 1)
+	if _, err := ParseCertificate(data); !errors.Is(err, ErrMalformed) {
+		t.Errorf("重复扩展: %v", err)
+	}
+
+	if _, err := CreateCertificate(&Certificate{}, ca, &key.PublicKey, key); !errors.Is(err, ErrInvalidTemplate) {
+		t.Errorf("缺少序列号: %v", err)
+	}
+	if _, err := CreateCertificate(&Certificate{SerialNumber: big.NewInt(1), ExtKeyUsage: []ExtKeyUsage{99}}, ca, &key.PublicKey, key); !errors.Is(err, ErrInvalidTemplate) {
+		t.Errorf("未知扩展密钥用途: %v", err)
+	}
+	if _, err := CreateCertificate(&Certificate{SerialNumber: big.NewInt(1)}, ca, &key.PublicKey, "key"); !errors.Is(err, ErrUnsupportedKeyType) {
+		t.Errorf("未知私钥类型: %v", err)
+	}
+}
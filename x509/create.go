@@ -0,0 +1,229 @@
+package x509
+
+import (
+	"crypto/sha1"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"net"
+
+	"github.com/laenix/gsc/der"
+)
+
+// CreateCertificate 使用priv签发证书，返回DER编码
+//
+// template提供序列号、主体、有效期和扩展；parent是签发者证书，自签名时传入template本身。
+// pub是被签发的公钥，priv是签发者的私钥，签名算法由priv的类型决定（见SignatureAlgorithm）；
+// RSA私钥（crypto/rsa或gsc/rsa）可以用template.SignatureAlgorithm选择PKCS#1 v1.5或PSS。
+// 签名完成后会用parent的公钥（自签名时用priv对应的公钥）验证签名，以发现私钥与签发者不匹配的错误。
+//
+// 写入的扩展：基本约束（BasicConstraintsValid时，关键）、密钥用途（非零时，关键）、扩展密钥用途、
+// 主体备用名称、主体密钥标识（CA证书未指定时按RFC 5280方法1由公钥的SHA-1计算）、
+// 签发者密钥标识（parent有主体密钥标识且不是自签名时），以及template.ExtraExtensions
+func CreateCertificate(template, parent *Certificate, pub, priv any) ([]byte, error) {
+	if template == nil || parent == nil || template.SerialNumber == nil || template.SerialNumber.Sign() < 0 {
+		return nil, ErrInvalidTemplate
+	}
+	alg, signerPub, err := signingParams(priv, template.SignatureAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	spki, err := MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	subject, err := rawName(template.RawSubject, template.Subject)
+	if err != nil {
+		return nil, err
+	}
+	issuer, err := rawName(parent.RawSubject, parent.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	skid := template.SubjectKeyId
+	if len(skid) == 0 && template.IsCA {
+		// SPKI中的公钥位串
+		p, _ := der.NewParser(spki).ReadSequence()
+		p.ReadSequence()
+		key, _ := p.ReadBitString()
+		sum := sha1.Sum(key)
+		skid = sum[:]
+	}
+	var akid []byte
+	if string(issuer) != string(subject) {
+		akid = parent.SubjectKeyId
+	}
+	exts, err := buildExtensions(template, skid, akid)
+	if err != nil {
+		return nil, err
+	}
+
+	var b der.Builder
+	b.AddSequence(func(b *der.Builder) {
+		b.AddExplicit(0, func(b *der.Builder) { b.AddInt64(2) })
+		b.AddInteger(template.SerialNumber)
+		addSignatureAlgorithm(b, alg)
+		b.AddRaw(issuer)
+		b.AddSequence(func(b *der.Builder) {
+			b.AddTime(template.NotBefore)
+			b.AddTime(template.NotAfter)
+		})
+		b.AddRaw(subject)
+		b.AddRaw(spki)
+		if len(exts) > 0 {
+			b.AddExplicit(3, func(b *der.Builder) { addExtensions(b, exts) })
+		}
+	})
+	tbs, err := b.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := sign(alg, priv, tbs)
+	if err != nil {
+		return nil, err
+	}
+	issuerPub := parent.PublicKey
+	if parent == template || issuerPub == nil {
+		issuerPub = signerPub
+	}
+	if err := checkSignature(alg, issuerPub, tbs, signature); err != nil {
+		return nil, ErrKeyMismatch
+	}
+
+	b = der.Builder{}
+	b.AddSequence(func(b *der.Builder) {
+		b.AddRaw(tbs)
+		addSignatureAlgorithm(b, alg)
+		b.AddBitString(signature)
+	})
+	return b.Bytes()
+}
+
+// rawName 返回名称的DER编码，优先使用已有的原始编码
+func rawName(raw []byte, name pkix.Name) ([]byte, error) {
+	if len(raw) > 0 {
+		return raw, nil
+	}
+	data, err := asn1.Marshal(name.ToRDNSequence())
+	if err != nil {
+		return nil, ErrInvalidTemplate
+	}
+	return data, nil
+}
+
+// buildExtensions 根据模板生成扩展列表，ExtraExtensions覆盖同OID的自动生成扩展
+func buildExtensions(t *Certificate, skid, akid []byte) ([]Extension, error) {
+	var exts []Extension
+	var err error
+	add := func(id der.OID, critical bool, f func(*der.Builder)) {
+		var b der.Builder
+		f(&b)
+		value, e := b.Bytes()
+		if e != nil && err == nil {
+			err = ErrInvalidTemplate
+		}
+		exts = append(exts, Extension{ID: id, Critical: critical, Value: value})
+	}
+
+	if t.BasicConstraintsValid {
+		add(oidExtBasicConstraints, true, func(b *der.Builder) {
+			b.AddSequence(func(b *der.Builder) {
+				if t.IsCA {
+					b.AddBoolean(true)
+					// 与crypto/x509相同：MaxPathLen为0且MaxPathLenZero为false时不限制
+					if t.MaxPathLen > 0 || (t.MaxPathLen == 0 && t.MaxPathLenZero) {
+						b.AddInt64(int64(t.MaxPathLen))
+					}
+				}
+			})
+		})
+	}
+	if t.KeyUsage != 0 {
+		add(oidExtKeyUsage, true, func(b *der.Builder) {
+			var bits [2]byte
+			n := 0
+			for i := 0; i < 9; i++ {
+				if t.KeyUsage&(1<<i) != 0 {
+					bits[i/8] |= 0x80 >> (i % 8)
+					n = i + 1
+				}
+			}
+			// 命名位列表的DER编码去掉末尾的0位
+			b.AddBits(der.BitString{Bytes: bits[:], BitLength: n})
+		})
+	}
+	if len(t.ExtKeyUsage) > 0 {
+		add(oidExtExtKeyUsage, false, func(b *der.Builder) {
+			b.AddSequence(func(b *der.Builder) {
+				for _, u := range t.ExtKeyUsage {
+					b.AddOID(extKeyUsageOIDs[u])
+				}
+			})
+		})
+	}
+	if len(t.DNSNames) > 0 || len(t.EmailAddresses) > 0 || len(t.IPAddresses) > 0 {
+		add(oidExtSubjectAltName, false, func(b *der.Builder) {
+			addSubjectAltName(b, t.DNSNames, t.EmailAddresses, t.IPAddresses)
+		})
+	}
+	if len(skid) > 0 {
+		add(oidExtSubjectKeyID, false, func(b *der.Builder) { b.AddOctetString(skid) })
+	}
+	if len(akid) > 0 {
+		add(oidExtAuthorityKeyID, false, func(b *der.Builder) {
+			b.AddSequence(func(b *der.Builder) {
+				b.AddElement(der.Context(0, false), akid)
+			})
+		})
+	}
+
+	return mergeExtensions(exts, t.ExtraExtensions), err
+}
+
+// mergeExtensions 将extra追加到exts，覆盖同OID的扩展
+func mergeExtensions(exts, extra []Extension) []Extension {
+	for _, e := range extra {
+		for i := range exts {
+			if exts[i].ID.Equal(e.ID) {
+				exts = append(exts[:i], exts[i+1:]...)
+				break
+			}
+		}
+		exts = append(exts, e)
+	}
+	return exts
+}
+
+// addSubjectAltName 追加主体备用名称扩展的值
+func addSubjectAltName(b *der.Builder, dns, emails []string, ips []net.IP) {
+	b.AddSequence(func(b *der.Builder) {
+		for _, name := range dns {
+			b.AddElement(der.Context(nameTypeDNS, false), []byte(name))
+		}
+		for _, email := range emails {
+			b.AddElement(der.Context(nameTypeEmail, false), []byte(email))
+		}
+		for _, ip := range ips {
+			if v4 := ip.To4(); v4 != nil {
+				ip = v4
+			}
+			b.AddElement(der.Context(nameTypeIP, false), ip)
+		}
+	})
+}
+
+// addExtensions 追加扩展列表，critical为false时按DER省略默认值
+func addExtensions(b *der.Builder, exts []Extension) {
+	b.AddSequence(func(b *der.Builder) {
+		for _, ext := range exts {
+			b.AddSequence(func(b *der.Builder) {
+				b.AddOID(ext.ID)
+				if ext.Critical {
+					b.AddBoolean(true)
+				}
+				b.AddOctetString(ext.Value)
+			})
+		}
+	})
+}
@@ -0,0 +1,196 @@
+package x509
+
+import (
+	"crypto/x509/pkix"
+	"net"
+
+	"github.com/laenix/gsc/der"
+)
+
+// oidExtensionRequest 是PKCS#9的extensionRequest属性，携带申请者希望写入证书的扩展
+var oidExtensionRequest = der.OID{1, 2, 840, 113549, 1, 9, 14}
+
+// CertificateRequest 是解析后的PKCS#10证书签名请求（CSR），也用作CreateCertificateRequest的模板
+type CertificateRequest struct {
+	Raw                      []byte // 完整的DER编码
+	RawTBSCertificateRequest []byte // 被签名的CertificationRequestInfo
+	RawSubjectPublicKeyInfo  []byte
+	RawSubject               []byte
+
+	Signature          []byte
+	SignatureAlgorithm SignatureAlgorithm
+	PublicKey          any
+
+	Version int
+	Subject pkix.Name
+
+	DNSNames       []string
+	EmailAddresses []string
+	IPAddresses    []net.IP
+
+	// Extensions 是extensionRequest属性中的全部扩展，解析时填写
+	Extensions []Extension
+	// ExtraExtensions 在创建时写入extensionRequest属性，覆盖同OID的自动生成扩展
+	ExtraExtensions []Extension
+}
+
+// CreateCertificateRequest 使用priv创建并签名证书签名请求，返回DER编码
+// 公钥取自priv，签名算法的选择与CreateCertificate相同；DNSNames、EmailAddresses和IPAddresses
+// 写入extensionRequest属性中的主体备用名称扩展
+func CreateCertificateRequest(template *CertificateRequest, priv any) ([]byte, error) {
+	if template == nil {
+		return nil, ErrInvalidTemplate
+	}
+	alg, pub, err := signingParams(priv, template.SignatureAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	spki, err := MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	subject, err := rawName(template.RawSubject, template.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	var exts []Extension
+	if len(template.DNSNames) > 0 || len(template.EmailAddresses) > 0 || len(template.IPAddresses) > 0 {
+		var b der.Builder
+		addSubjectAltName(&b, template.DNSNames, template.EmailAddresses, template.IPAddresses)
+		value, err := b.Bytes()
+		if err != nil {
+			return nil, ErrInvalidTemplate
+		}
+		exts = append(exts, Extension{ID: oidExtSubjectAltName, Value: value})
+	}
+	exts = mergeExtensions(exts, template.ExtraExtensions)
+
+	// CertificationRequestInfo ::= SEQUENCE {
+	//   version       INTEGER { v1(0) },
+	//   subject       Name,
+	//   subjectPKInfo SubjectPublicKeyInfo,
+	//   attributes    [0] IMPLICIT SET OF Attribute }
+	var b der.Builder
+	b.AddSequence(func(b *der.Builder) {
+		b.AddInt64(0)
+		b.AddRaw(subject)
+		b.AddRaw(spki)
+		b.AddConstructed(der.Context(0, true), func(b *der.Builder) {
+			if len(exts) == 0 {
+				return
+			}
+			b.AddSequence(func(b *der.Builder) {
+				b.AddOID(oidExtensionRequest)
+				b.AddSet(func(b *der.Builder) { addExtensions(b, exts) })
+			})
+		})
+	})
+	tbs, err := b.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	signature, err := sign(alg, priv, tbs)
+	if err != nil {
+		return nil, err
+	}
+
+	b = der.Builder{}
+	b.AddSequence(func(b *der.Builder) {
+		b.AddRaw(tbs)
+		addSignatureAlgorithm(b, alg)
+		b.AddBitString(signature)
+	})
+	return b.Bytes()
+}
+
+// ParseCertificateRequest 解析一个DER编码的证书签名请求，不验证签名（见CheckSignature）
+func ParseCertificateRequest(data []byte) (*CertificateRequest, error) {
+	p := der.NewParser(data)
+	raw, err := p.ReadRaw(der.TagSequence)
+	if err != nil || p.Finish() != nil {
+		return nil, ErrMalformed
+	}
+	r := &CertificateRequest{Raw: raw}
+
+	seq, _ := der.NewParser(raw).ReadSequence()
+	if r.RawTBSCertificateRequest, err = seq.ReadRaw(der.TagSequence); err != nil {
+		return nil, ErrMalformed
+	}
+	if r.SignatureAlgorithm, err = readSignatureAlgorithm(seq); err != nil {
+		return nil, ErrMalformed
+	}
+	if r.Signature, err = seq.ReadBitString(); err != nil || seq.Finish() != nil {
+		return nil, ErrMalformed
+	}
+
+	info, _ := der.NewParser(r.RawTBSCertificateRequest).ReadSequence()
+	version, err := info.ReadInt64()
+	if err != nil || version != 0 {
+		return nil, ErrMalformed
+	}
+	r.Version = int(version)
+	if r.RawSubject, err = readName(info, &r.Subject); err != nil {
+		return nil, err
+	}
+	if r.RawSubjectPublicKeyInfo, err = info.ReadRaw(der.TagSequence); err != nil {
+		return nil, ErrMalformed
+	}
+	if r.PublicKey, err = ParsePKIXPublicKey(r.RawSubjectPublicKeyInfo); err != nil {
+		return nil, err
+	}
+	attrs, err := info.Read(der.Context(0, true))
+	if err != nil || info.Finish() != nil {
+		return nil, ErrMalformed
+	}
+	if err := r.parseAttributes(der.NewParser(attrs)); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// parseAttributes 解析属性列表，只处理extensionRequest，忽略其他属性（如challengePassword）
+//
+//	Attribute ::= SEQUENCE {
+//	  type   OBJECT IDENTIFIER,
+//	  values SET SIZE(1..MAX) OF AttributeValue }
+func (r *CertificateRequest) parseAttributes(p *der.Parser) error {
+	for !p.Empty() {
+		attr, err := p.ReadSequence()
+		if err != nil {
+			return ErrMalformed
+		}
+		oid, err := attr.ReadOID()
+		if err != nil {
+			return ErrMalformed
+		}
+		values, err := attr.ReadSet()
+		if err != nil || attr.Finish() != nil {
+			return ErrMalformed
+		}
+		if !oid.Equal(oidExtensionRequest) {
+			continue
+		}
+		if r.Extensions != nil {
+			return ErrMalformed
+		}
+		if r.Extensions, err = readExtensions(values); err != nil {
+			return err
+		}
+		for _, ext := range r.Extensions {
+			if !ext.ID.Equal(oidExtSubjectAltName) {
+				continue
+			}
+			v := der.NewParser(ext.Value)
+			if r.DNSNames, r.EmailAddresses, r.IPAddresses, err = parseSubjectAltName(v); err != nil || v.Finish() != nil {
+				return ErrMalformed
+			}
+		}
+	}
+	return nil
+}
+
+// CheckSignature 使用请求中的公钥验证签名，证明申请者持有对应的私钥
+func (r *CertificateRequest) CheckSignature() error {
+	return checkSignature(r.SignatureAlgorithm, r.PublicKey, r.RawTBSCertificateRequest, r.Signature)
+}
@@ -0,0 +1,185 @@
+package x509
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	stdx509 "crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/laenix/gsc/der"
+	"github.com/laenix/gsc/sm2"
+)
+
+func TestOpenSSLSM2CertificateRequest(t *testing.T) {
+	data, err := os.ReadFile("testdata/sm2_csr.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(data)
+	r, err := ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.SignatureAlgorithm != SM2WithSM3 || r.Subject.CommonName != "www.example.com" {
+		t.Errorf("%v %v", r.SignatureAlgorithm, r.Subject)
+	}
+	if !slices.Equal(r.DNSNames, []string{"www.example.com", "example.com"}) ||
+		!slices.Equal(r.EmailAddresses, []string{"admin@example.com"}) ||
+		len(r.IPAddresses) != 1 || !r.IPAddresses[0].Equal(net.ParseIP("192.0.2.1")) {
+		t.Errorf("备用名称 %v %v %v", r.DNSNames, r.EmailAddresses, r.IPAddresses)
+	}
+	if _, ok := r.PublicKey.(*sm2.PublicKey); !ok {
+		t.Errorf("公钥类型 %T", r.PublicKey)
+	}
+	if err := r.CheckSignature(); err != nil {
+		t.Errorf("OpenSSL的SM2签名验证失败: %v", err)
+	}
+}
+
+// 测试由CSR签发证书的完整流程
+func TestCertificateRequestEnroll(t *testing.T) {
+	caKey, _ := sm2.New().GenerateKey(nil)
+	ca := newCA(t, caKey, &caKey.PublicKey, "gsc SM2 CA")
+	key, _ := sm2.New().GenerateKey(nil)
+
+	data, err := CreateCertificateRequest(&CertificateRequest{
+		Subject:        pkix.Name{Country: []string{"CN"}, CommonName: "www.example.com"},
+		DNSNames:       []string{"www.example.com"},
+		EmailAddresses: []string{"admin@example.com"},
+		IPAddresses:    []net.IP{net.ParseIP("192.0.2.1"), net.ParseIP("2001:db8::1")},
+	}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := ParseCertificateRequest(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.CheckSignature(); err != nil {
+		t.Fatal(err)
+	}
+	if r.SignatureAlgorithm != SM2WithSM3 || len(r.Extensions) != 1 || len(r.IPAddresses) != 2 {
+		t.Errorf("%v %v", r.SignatureAlgorithm, r.Extensions)
+	}
+
+	der, err := CreateCertificate(&Certificate{
+		SerialNumber:   big.NewInt(7),
+		RawSubject:     r.RawSubject,
+		NotBefore:      testNotBefore,
+		NotAfter:       testNotAfter,
+		KeyUsage:       KeyUsageDigitalSignature,
+		DNSNames:       r.DNSNames,
+		EmailAddresses: r.EmailAddresses,
+		IPAddresses:    r.IPAddresses,
+	}, ca, r.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := NewCertPool()
+	roots.AddCert(ca)
+	if _, err := c.Verify(VerifyOptions{Roots: roots, CurrentTime: testNow, DNSName: "2001:db8::1"}); err != nil {
+		t.Error(err)
+	}
+	if c.Subject.CommonName != "www.example.com" || c.PublicKey.(*sm2.PublicKey).X.Cmp(key.X) != 0 {
+		t.Errorf("证书主体 %v", c.Subject)
+	}
+}
+
+// 测试RSA和ECDSA证书请求与crypto/x509互通
+func TestCertificateRequestInterop(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	for _, priv := range []any{rsaKey, p256} {
+		data, err := CreateCertificateRequest(&CertificateRequest{
+			Subject:  pkix.Name{CommonName: "gsc"},
+			DNSNames: []string{"gsc.example.com"},
+		}, priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		std, err := stdx509.ParseCertificateRequest(data)
+		if err != nil {
+			t.Fatalf("%T: %v", priv, err)
+		}
+		if err := std.CheckSignature(); err != nil || std.Subject.CommonName != "gsc" || !slices.Equal(std.DNSNames, []string{"gsc.example.com"}) {
+			t.Errorf("%T: crypto/x509解析结果不同: %v", priv, err)
+		}
+
+		data, err = stdx509.CreateCertificateRequest(rand.Reader, &stdx509.CertificateRequest{
+			Subject:        pkix.Name{CommonName: "std"},
+			EmailAddresses: []string{"std@example.com"},
+		}, priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := ParseCertificateRequest(data)
+		if err != nil {
+			t.Fatalf("%T: %v", priv, err)
+		}
+		if err := r.CheckSignature(); err != nil || !slices.Equal(r.EmailAddresses, []string{"std@example.com"}) {
+			t.Errorf("%T: %v", priv, err)
+		}
+	}
+}
+
+func TestCertificateRequestErrors(t *testing.T) {
+	key, _ := sm2.New().GenerateKey(nil)
+	data, err := CreateCertificateRequest(&CertificateRequest{Subject: pkix.Name{CommonName: "gsc"}}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := ParseCertificateRequest(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Extensions != nil {
+		t.Errorf("没有备用名称时不应写入extensionRequest: %v", r.Extensions)
+	}
+
+	// 篡改签名
+	bad := slices.Clone(data)
+	bad[len(bad)-1] ^= 1
+	if r, err := ParseCertificateRequest(bad); err != nil {
+		t.Fatal(err)
+	} else if err := r.CheckSignature(); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("篡改签名: %v", err)
+	}
+
+	if _, err := ParseCertificateRequest(append(slices.Clone(data), 0)); !errors.Is(err, ErrMalformed) {
+		t.Errorf("尾随数据: %v", err)
+	}
+	if _, err := CreateCertificateRequest(nil, key); !errors.Is(err, ErrInvalidTemplate) {
+		t.Errorf("空模板: %v", err)
+	}
+	if _, err := CreateCertificateRequest(&CertificateRequest{}, struct{}{}); !errors.Is(err, ErrUnsupportedKeyType) {
+		t.Errorf("不支持的私钥: %v", err)
+	}
+
+	// extensionRequest属性中的重复扩展
+	dup := Extension{ID: der.OID{1, 2, 3}, Value: []byte{5, 0}}
+	data, err = CreateCertificateRequest(&CertificateRequest{ExtraExtensions: []Extension{dup, {ID: der.OID{1, 2, 4}, Value: []byte{5, 0}}}}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 将OID 1.2.4改为1.2.3
+	i := bytes.Index(data, []byte{6, 2, 42, 4})
+	data[i+3] = 3
+	if _, err := ParseCertificateRequest(data); !errors.Is(err, ErrMalformed) {
+		t.Errorf("重复扩展: %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package x509
+
+import (
+	"crypto/ecdsa"
+	stded25519 "crypto/ed25519"
+	stdx509 "crypto/x509"
+
+	"github.com/laenix/gsc/der"
+	"github.com/laenix/gsc/ed25519"
+	"github.com/laenix/gsc/rsa"
+	"github.com/laenix/gsc/sm2"
+)
+
+// MarshalPKCS8PrivateKey 将私钥编码为PKCS#8（RFC 5208）的PrivateKeyInfo结构
+//
+// 支持*rsa.PrivateKey（crypto/rsa或gsc/rsa）、*ecdsa.PrivateKey、ed25519.PrivateKey（crypto/ed25519或gsc/ed25519）、*ecdh.PrivateKey和*sm2.PrivateKey。
+// SM2私钥的算法标识为id-ecPublicKey，曲线参数为OIDSM2，与OpenSSL的输出逐字节相同
+func MarshalPKCS8PrivateKey(key any) ([]byte, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		key = k.ToStd()
+	case ed25519.PrivateKey:
+		key = stded25519.PrivateKey(k)
+	}
+	var priv *sm2.PrivateKey
+	switch k := key.(type) {
+	case *sm2.PrivateKey:
+		priv = k
+	case *ecdsa.PrivateKey:
+		if k.Curve == sm2.P256() {
+			priv = fromECDSA(k)
+		}
+	}
+	if priv == nil {
+		data, err := stdx509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, ErrUnsupportedKeyType
+		}
+		return data, nil
+	}
+
+	ec, err := marshalSM2PrivateKey(priv, nil)
+	if err != nil {
+		return nil, err
+	}
+	var b der.Builder
+	b.AddSequence(func(b *der.Builder) {
+		b.AddInt64(0)
+		b.AddSequence(func(b *der.Builder) {
+			b.AddOID(oidECPublicKey)
+			b.AddOID(OIDSM2)
+		})
+		b.AddOctetString(ec)
+	})
+	return b.Bytes()
+}
+
+// ParsePKCS8PrivateKey 解析PKCS#8的PrivateKeyInfo结构（以及RFC 5958的OneAsymmetricKey）
+//
+// SM2私钥返回*sm2.PrivateKey，算法标识可以是id-ecPublicKey加SM2曲线参数（OpenSSL），
+// 也可以直接是OIDSM2（部分国密实现）；其他类型交给crypto/x509解析
+func ParsePKCS8PrivateKey(data []byte) (any, error) {
+	info, err := parsePKCS8(data)
+	if err != nil {
+		return nil, err
+	}
+	if !info.sm2 {
+		key, err := stdx509.ParsePKCS8PrivateKey(data)
+		if err != nil {
+			return nil, ErrInvalidKey
+		}
+		return key, nil
+	}
+	ec, err := parseECPrivateKey(info.privateKey)
+	if err != nil {
+		return nil, err
+	}
+	if ec.curve != nil && !ec.curve.Equal(OIDSM2) {
+		return nil, ErrInvalidKey
+	}
+	return ec.sm2(info.publicKey)
+}
+
+// pkcs8 是解析后的OneAsymmetricKey结构
+type pkcs8 struct {
+	sm2        bool
+	privateKey []byte
+	publicKey  []byte
+}
+
+// parsePKCS8 解析PKCS#8结构的外层并识别SM2算法标识
+//
+//	OneAsymmetricKey ::= SEQUENCE {
+//	  version                   INTEGER { v1(0), v2(1) },
+//	  privateKeyAlgorithm       AlgorithmIdentifier,
+//	  privateKey                OCTET STRING,
+//	  attributes            [0] IMPLICIT Attributes OPTIONAL,
+//	  publicKey             [1] IMPLICIT BIT STRING OPTIONAL }
+func parsePKCS8(data []byte) (*pkcs8, error) {
+	p := der.NewParser(data)
+	seq, err := p.ReadSequence()
+	if err != nil || p.Finish() != nil {
+		return nil, ErrInvalidKey
+	}
+	version, err := seq.ReadInt64()
+	if err != nil || (version != 0 && version != 1) {
+		return nil, ErrInvalidKey
+	}
+	algo, err := seq.ReadSequence()
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+
+	// 只有SM2由本包处理，其他算法和曲线交给crypto/x509
+	info := &pkcs8{sm2: isSM2Algorithm(algo)}
+	if !info.sm2 {
+		return info, nil
+	}
+
+	if info.privateKey, err = seq.ReadOctetString(); err != nil {
+		return nil, ErrInvalidKey
+	}
+	if _, _, err := seq.ReadOptional(der.Context(0, true)); err != nil {
+		return nil, ErrInvalidKey
+	}
+	bits, present, err := seq.ReadOptional(der.Context(1, false))
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	if present {
+		if version != 1 || len(bits) == 0 || bits[0] != 0 {
+			return nil, ErrInvalidKey
+		}
+		info.publicKey = bits[1:]
+	}
+	if seq.Finish() != nil {
+		return nil, ErrInvalidKey
+	}
+	return info, nil
+}
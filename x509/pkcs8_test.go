@@ -0,0 +1,190 @@
+package x509
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/laenix/gsc/der"
+	"github.com/laenix/gsc/sm2"
+)
+
+// OpenSSL 3 生成的SM2私钥（openssl genpkey -algorithm SM2），PKCS#8和SEC 1两种编码
+const (
+	opensslPKCS8 = "308187020100301306072a8648ce3d020106082a811ccf5501822d046d306b0201010420" +
+		"e3dc197d4783375711b68d7551de01f70066ccfe561db96f8d7c09a1db2aecbba14403420004" +
+		"cbefc94c6c798876f2303267e4ffcdfb9794b3b5373afc42e45c95e7959f9f2ebc880029c8b6" +
+		"92743296d0c457fe60a10dddc62066ced0df8936096a2daba92d"
+	opensslSEC1 = "30770201010420e3dc197d4783375711b68d7551de01f70066ccfe561db96f8d7c09a1db2aecbb" +
+		"a00a06082a811ccf5501822da14403420004cbefc94c6c798876f2303267e4ffcdfb9794b3b537" +
+		"3afc42e45c95e7959f9f2ebc880029c8b692743296d0c457fe60a10dddc62066ced0df8936096a" +
+		"2daba92d"
+)
+
+func TestOpenSSLSM2(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		data    string
+		parse   func([]byte) (any, error)
+		marshal func(any) ([]byte, error)
+	}{
+		{"PKCS#8", opensslPKCS8, ParsePKCS8PrivateKey, MarshalPKCS8PrivateKey},
+		{"SEC 1", opensslSEC1, ParseECPrivateKey, MarshalECPrivateKey},
+	} {
+		data, _ := hex.DecodeString(tt.data)
+		key, err := tt.parse(data)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		priv := key.(*sm2.PrivateKey)
+		if priv.D.Text(16) != "e3dc197d4783375711b68d7551de01f70066ccfe561db96f8d7c09a1db2aecbb" {
+			t.Errorf("%s: 私钥 %x", tt.name, priv.D)
+		}
+		// 重新编码得到与OpenSSL相同的字节
+		if out, err := tt.marshal(priv); err != nil || hex.EncodeToString(out) != tt.data {
+			t.Errorf("%s: 编码 %x, %v", tt.name, out, err)
+		}
+	}
+}
+
+func TestPKCS8RoundTrip(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	xKey, _ := ecdh.X25519().GenerateKey(rand.Reader)
+
+	for _, key := range []crypto.PrivateKey{rsaKey, ecKey, edKey, xKey} {
+		data, err := MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatalf("%T: %v", key, err)
+		}
+		got, err := ParsePKCS8PrivateKey(data)
+		if err != nil {
+			t.Fatalf("%T: %v", key, err)
+		}
+		if !key.(interface{ Equal(crypto.PrivateKey) bool }).Equal(got) {
+			t.Errorf("%T: 往返后不同", key)
+		}
+	}
+
+	sm2Key, _ := sm2.New().GenerateKey(nil)
+	data, err := MarshalPKCS8PrivateKey(sm2Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParsePKCS8PrivateKey(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k := got.(*sm2.PrivateKey); k.D.Cmp(sm2Key.D) != 0 || k.X.Cmp(sm2Key.X) != 0 {
+		t.Error("SM2私钥往返后不同")
+	}
+
+	// SM2曲线上的*ecdsa.PrivateKey按SM2私钥编码
+	ecSM2 := &ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: sm2.P256(), X: sm2Key.X, Y: sm2Key.Y}, D: sm2Key.D}
+	if again, err := MarshalPKCS8PrivateKey(ecSM2); err != nil || hex.EncodeToString(again) != hex.EncodeToString(data) {
+		t.Errorf("SM2曲线上的ECDSA私钥: %v", err)
+	}
+}
+
+// 测试SM2的几种算法标识：OpenSSL的id-ecPublicKey加曲线参数，以及直接使用SM2 OID
+func TestPKCS8SM2Identifiers(t *testing.T) {
+	priv, _ := sm2.New().GenerateKey(nil)
+	ec, _ := marshalSM2PrivateKey(priv, OIDSM2)
+	ecNoCurve, _ := marshalSM2PrivateKey(priv, nil)
+
+	build := func(version int64, algo func(*der.Builder), inner []byte, public []byte) []byte {
+		var b der.Builder
+		b.AddSequence(func(b *der.Builder) {
+			b.AddInt64(version)
+			b.AddSequence(algo)
+			b.AddOctetString(inner)
+			if public != nil {
+				b.AddElement(der.Context(1, false), append([]byte{0}, public...))
+			}
+		})
+		data, _ := b.Bytes()
+		return data
+	}
+	ecAlgo := func(b *der.Builder) { b.AddOID(oidECPublicKey); b.AddOID(OIDSM2) }
+	sm2Algo := func(b *der.Builder) { b.AddOID(OIDSM2) }
+	sm2NullAlgo := func(b *der.Builder) { b.AddOID(OIDSM2); b.AddNull() }
+	other, _ := sm2.New().GenerateKey(nil)
+
+	tests := []struct {
+		name string
+		data []byte
+		ok   bool
+	}{
+		{"OpenSSL", build(0, ecAlgo, ecNoCurve, nil), true},
+		{"内层含曲线", build(0, ecAlgo, ec, nil), true},
+		{"SM2 OID", build(0, sm2Algo, ec, nil), true},
+		{"SM2 OID加NULL", build(0, sm2NullAlgo, ecNoCurve, nil), true},
+		{"v2含公钥", build(1, ecAlgo, ecNoCurve, sm2Point(&priv.PublicKey)), true},
+		{"v2公钥不一致", build(1, ecAlgo, ecNoCurve, sm2Point(&other.PublicKey)), false},
+		{"v1不得含公钥", build(0, ecAlgo, ecNoCurve, sm2Point(&priv.PublicKey)), false},
+		{"版本错误", build(2, ecAlgo, ecNoCurve, nil), false},
+		{"内层不是ECPrivateKey", build(0, ecAlgo, []byte{1, 2, 3}, nil), false},
+	}
+	for _, tt := range tests {
+		key, err := ParsePKCS8PrivateKey(tt.data)
+		if tt.ok {
+			if err != nil || key.(*sm2.PrivateKey).D.Cmp(priv.D) != 0 {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+		} else if !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("%s: 期望ErrInvalidKey，实际%v", tt.name, err)
+		}
+	}
+}
+
+func TestECPrivateKey(t *testing.T) {
+	sm2Key, _ := sm2.New().GenerateKey(nil)
+	data, err := MarshalECPrivateKey(sm2Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParseECPrivateKey(data)
+	if err != nil || got.(*sm2.PrivateKey).D.Cmp(sm2Key.D) != 0 {
+		t.Errorf("SM2: %v", err)
+	}
+
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if data, err = MarshalECPrivateKey(ecKey); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ParseECPrivateKey(data); err != nil || !ecKey.Equal(got) {
+		t.Errorf("P-384: %v", err)
+	}
+
+	// 公钥与私钥不一致
+	other, _ := sm2.New().GenerateKey(nil)
+	data, _ = MarshalECPrivateKey(&sm2.PrivateKey{D: sm2Key.D, PublicKey: other.PublicKey})
+	if _, err := ParseECPrivateKey(data); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("公私钥不一致: %v", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := MarshalPKCS8PrivateKey("key"); !errors.Is(err, ErrUnsupportedKeyType) {
+		t.Errorf("PKCS#8未知类型: %v", err)
+	}
+	if _, err := MarshalECPrivateKey(ed25519.PrivateKey(make([]byte, 64))); !errors.Is(err, ErrUnsupportedKeyType) {
+		t.Errorf("SEC 1未知类型: %v", err)
+	}
+	if _, err := MarshalPKCS8PrivateKey(&sm2.PrivateKey{}); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("空SM2私钥: %v", err)
+	}
+	for _, data := range [][]byte{nil, {0x30, 0x00}, {0x30, 0x03, 0x02, 0x01, 0x00, 0x00}} {
+		if _, err := ParsePKCS8PrivateKey(data); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("%x: %v", data, err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package x509
+
+import (
+	"crypto/ecdsa"
+	stded25519 "crypto/ed25519"
+	stdx509 "crypto/x509"
+
+	"github.com/laenix/gsc/der"
+	"github.com/laenix/gsc/ed25519"
+	"github.com/laenix/gsc/rsa"
+	"github.com/laenix/gsc/sm2"
+)
+
+// MarshalPKIXPublicKey 将公钥编码为SubjectPublicKeyInfo结构
+//
+// 支持*rsa.PublicKey（crypto/rsa或gsc/rsa）、*ecdsa.PublicKey、ed25519.PublicKey（crypto/ed25519或gsc/ed25519）、*ecdh.PublicKey和*sm2.PublicKey。
+// SM2公钥的算法标识为id-ecPublicKey，曲线参数为OIDSM2
+func MarshalPKIXPublicKey(pub any) ([]byte, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		pub = k.ToStd()
+	case ed25519.PublicKey:
+		pub = stded25519.PublicKey(k)
+	}
+	var key *sm2.PublicKey
+	switch k := pub.(type) {
+	case *sm2.PublicKey:
+		key = k
+	case *ecdsa.PublicKey:
+		if k.Curve == sm2.P256() {
+			key = &sm2.PublicKey{X: k.X, Y: k.Y}
+		}
+	}
+	if key == nil {
+		data, err := stdx509.MarshalPKIXPublicKey(pub)
+		if err != nil {
+			return nil, ErrUnsupportedKeyType
+		}
+		return data, nil
+	}
+
+	if key.X == nil || key.Y == nil {
+		return nil, ErrInvalidKey
+	}
+	var b der.Builder
+	b.AddSequence(func(b *der.Builder) {
+		b.AddSequence(func(b *der.Builder) {
+			b.AddOID(oidECPublicKey)
+			b.AddOID(OIDSM2)
+		})
+		b.AddBitString(sm2Point(key))
+	})
+	return b.Bytes()
+}
+
+// ParsePKIXPublicKey 解析SubjectPublicKeyInfo结构
+// SM2公钥返回*sm2.PublicKey，算法标识的识别规则与ParsePKCS8PrivateKey相同；其他类型交给crypto/x509解析
+func ParsePKIXPublicKey(data []byte) (any, error) {
+	p := der.NewParser(data)
+	seq, err := p.ReadSequence()
+	if err != nil || p.Finish() != nil {
+		return nil, ErrInvalidKey
+	}
+	algo, err := seq.ReadSequence()
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	if !isSM2Algorithm(algo) {
+		key, err := stdx509.ParsePKIXPublicKey(data)
+		if err != nil {
+			return nil, ErrInvalidKey
+		}
+		return key, nil
+	}
+	point, err := seq.ReadBitString()
+	if err != nil || seq.Finish() != nil {
+		return nil, ErrInvalidKey
+	}
+	return parseSM2PublicKey(point)
+}
+
+// isSM2Algorithm 判断AlgorithmIdentifier是否表示SM2密钥：
+// id-ecPublicKey加SM2曲线参数（OpenSSL），或直接使用OIDSM2（部分国密实现）
+func isSM2Algorithm(algo *der.Parser) bool {
+	oid, err := algo.ReadOID()
+	if err != nil {
+		return false
+	}
+	if oid.Equal(OIDSM2) {
+		return true
+	}
+	if !oid.Equal(oidECPublicKey) {
+		return false
+	}
+	curve, err := algo.ReadOID()
+	return err == nil && curve.Equal(OIDSM2)
+}
@@ -0,0 +1,167 @@
+package x509
+
+import (
+	"crypto/ecdsa"
+	stdx509 "crypto/x509"
+	"math/big"
+
+	"github.com/laenix/gsc/der"
+	"github.com/laenix/gsc/sm2"
+)
+
+// ecPrivateKeyVersion 是SEC 1 ECPrivateKey结构的版本号
+const ecPrivateKeyVersion = 1
+
+// MarshalECPrivateKey 将ECDSA或SM2私钥编码为SEC 1（RFC 5915）的ECPrivateKey结构
+// SM2曲线上的*ecdsa.PrivateKey按SM2私钥处理
+func MarshalECPrivateKey(key any) ([]byte, error) {
+	switch k := key.(type) {
+	case *sm2.PrivateKey:
+		return marshalSM2PrivateKey(k, OIDSM2)
+	case *ecdsa.PrivateKey:
+		if k.Curve == sm2.P256() {
+			return marshalSM2PrivateKey(fromECDSA(k), OIDSM2)
+		}
+		return stdx509.MarshalECPrivateKey(k)
+	}
+	return nil, ErrUnsupportedKeyType
+}
+
+// ParseECPrivateKey 解析SEC 1的ECPrivateKey结构，SM2曲线返回*sm2.PrivateKey，其他曲线返回*ecdsa.PrivateKey
+func ParseECPrivateKey(data []byte) (any, error) {
+	ec, err := parseECPrivateKey(data)
+	if err != nil {
+		return nil, err
+	}
+	if ec.curve != nil && ec.curve.Equal(OIDSM2) {
+		return ec.sm2(nil)
+	}
+	key, err := stdx509.ParseECPrivateKey(data)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	return key, nil
+}
+
+// ecPrivateKey 是解析后的ECPrivateKey结构，可选字段不存在时为nil
+type ecPrivateKey struct {
+	d      []byte
+	curve  der.OID
+	public []byte
+}
+
+// parseECPrivateKey 解析ECPrivateKey结构
+//
+//	ECPrivateKey ::= SEQUENCE {
+//	  version        INTEGER { ecPrivkeyVer1(1) },
+//	  privateKey     OCTET STRING,
+//	  parameters [0] ECParameters OPTIONAL,
+//	  publicKey  [1] BIT STRING OPTIONAL }
+func parseECPrivateKey(data []byte) (*ecPrivateKey, error) {
+	p := der.NewParser(data)
+	seq, err := p.ReadSequence()
+	if err != nil || p.Finish() != nil {
+		return nil, ErrInvalidKey
+	}
+	ec := new(ecPrivateKey)
+	version, err := seq.ReadInt64()
+	if err != nil || version != ecPrivateKeyVersion {
+		return nil, ErrInvalidKey
+	}
+	if ec.d, err = seq.ReadOctetString(); err != nil {
+		return nil, ErrInvalidKey
+	}
+	params, present, err := seq.ReadExplicit(0)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	if present {
+		// 只支持命名曲线，不支持显式曲线参数
+		if ec.curve, err = params.ReadOID(); err != nil || params.Finish() != nil {
+			return nil, ErrInvalidKey
+		}
+	}
+	pub, present, err := seq.ReadExplicit(1)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	if present {
+		if ec.public, err = pub.ReadBitString(); err != nil || pub.Finish() != nil {
+			return nil, ErrInvalidKey
+		}
+	}
+	if seq.Finish() != nil {
+		return nil, ErrInvalidKey
+	}
+	return ec, nil
+}
+
+// sm2 将结构转换为SM2私钥，结构中的公钥或外层给出的公钥必须与私钥一致
+func (ec *ecPrivateKey) sm2(public []byte) (*sm2.PrivateKey, error) {
+	if len(ec.d) > sm2.PrivateKeySize {
+		return nil, ErrInvalidKey
+	}
+	priv, err := sm2.New().DecodePrivateKey(ec.d)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	for _, point := range [][]byte{ec.public, public} {
+		if point == nil {
+			continue
+		}
+		pub, err := parseSM2PublicKey(point)
+		if err != nil || pub.X.Cmp(priv.X) != 0 || pub.Y.Cmp(priv.Y) != 0 {
+			return nil, ErrInvalidKey
+		}
+	}
+	return priv, nil
+}
+
+// marshalSM2PrivateKey 将SM2私钥编码为ECPrivateKey结构，curve为nil时省略曲线参数（嵌入PKCS#8时）
+func marshalSM2PrivateKey(priv *sm2.PrivateKey, curve der.OID) ([]byte, error) {
+	if priv == nil || priv.D == nil || priv.X == nil || priv.Y == nil {
+		return nil, ErrInvalidKey
+	}
+	var b der.Builder
+	b.AddSequence(func(b *der.Builder) {
+		b.AddInt64(ecPrivateKeyVersion)
+		b.AddOctetString(priv.D.FillBytes(make([]byte, sm2.PrivateKeySize)))
+		if curve != nil {
+			b.AddExplicit(0, func(b *der.Builder) { b.AddOID(curve) })
+		}
+		b.AddExplicit(1, func(b *der.Builder) { b.AddBitString(sm2Point(&priv.PublicKey)) })
+	})
+	return b.Bytes()
+}
+
+// sm2Point 返回未压缩的公钥点 04 || X || Y
+func sm2Point(pub *sm2.PublicKey) []byte {
+	point := make([]byte, sm2.PublicKeySize)
+	point[0] = 4
+	pub.X.FillBytes(point[1:33])
+	pub.Y.FillBytes(point[33:])
+	return point
+}
+
+// parseSM2PublicKey 解析未压缩的SM2公钥点
+func parseSM2PublicKey(point []byte) (*sm2.PublicKey, error) {
+	if len(point) != sm2.PublicKeySize {
+		return nil, ErrInvalidKey
+	}
+	pub, err := sm2.New().DecodePublicKey(point)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	return pub, nil
+}
+
+// fromECDSA 将SM2曲线上的ECDSA私钥转换为SM2私钥，字段不完整时返回空私钥
+func fromECDSA(k *ecdsa.PrivateKey) *sm2.PrivateKey {
+	if k.D == nil || k.X == nil || k.Y == nil {
+		return &sm2.PrivateKey{}
+	}
+	return &sm2.PrivateKey{
+		D:         new(big.Int).Set(k.D),
+		PublicKey: sm2.PublicKey{X: new(big.Int).Set(k.X), Y: new(big.Int).Set(k.Y)},
+	}
+}
@@ -0,0 +1,369 @@
+package x509
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	stded25519 "crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	stdrsa "crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"math/big"
+
+	"github.com/laenix/gsc/der"
+	"github.com/laenix/gsc/ed25519"
+	"github.com/laenix/gsc/rsa"
+	"github.com/laenix/gsc/sm2"
+	"github.com/laenix/gsc/sm3"
+)
+
+// SignatureAlgorithm 是证书和CSR的签名算法
+type SignatureAlgorithm int
+
+// 支持的签名算法
+const (
+	UnknownSignatureAlgorithm SignatureAlgorithm = iota
+	SHA256WithRSA
+	SHA384WithRSA
+	SHA512WithRSA
+	ECDSAWithSHA256
+	ECDSAWithSHA384
+	ECDSAWithSHA512
+	PureEd25519
+	// SM2WithSM3 使用默认用户标识"1234567812345678"计算ZA（GM/T 0015），签名值为DER编码的 SEQUENCE { r, s }；
+	// 验证时也接受空用户标识的签名，以兼容OpenSSL 3.0签发的证书
+	SM2WithSM3
+	// SHA256WithRSAPSS等是RSASSA-PSS签名，MGF1使用相同的摘要算法，盐长度等于摘要长度（RFC 4055）
+	SHA256WithRSAPSS
+	SHA384WithRSAPSS
+	SHA512WithRSAPSS
+)
+
+// signatureAlgorithms 是签名算法的名称、OID和摘要函数，hash为0表示不预先计算摘要
+var signatureAlgorithms = map[SignatureAlgorithm]struct {
+	name string
+	oid  der.OID
+	hash crypto.Hash
+	// null 表示AlgorithmIdentifier带NULL参数（RSA，RFC 4055）
+	null bool
+	// pss 表示RSASSA-PSS，参数由rsa.MarshalPSSAlgorithm编码
+	pss bool
+}{
+	SHA256WithRSA:    {"SHA256-RSA", der.OID{1, 2, 840, 113549, 1, 1, 11}, crypto.SHA256, true, false},
+	SHA384WithRSA:    {"SHA384-RSA", der.OID{1, 2, 840, 113549, 1, 1, 12}, crypto.SHA384, true, false},
+	SHA512WithRSA:    {"SHA512-RSA", der.OID{1, 2, 840, 113549, 1, 1, 13}, crypto.SHA512, true, false},
+	ECDSAWithSHA256:  {"ECDSA-SHA256", der.OID{1, 2, 840, 10045, 4, 3, 2}, crypto.SHA256, false, false},
+	ECDSAWithSHA384:  {"ECDSA-SHA384", der.OID{1, 2, 840, 10045, 4, 3, 3}, crypto.SHA384, false, false},
+	ECDSAWithSHA512:  {"ECDSA-SHA512", der.OID{1, 2, 840, 10045, 4, 3, 4}, crypto.SHA512, false, false},
+	PureEd25519:      {"Ed25519", der.OID{1, 3, 101, 112}, 0, false, false},
+	SM2WithSM3:       {"SM2-SM3", OIDSM2WithSM3, 0, false, false},
+	SHA256WithRSAPSS: {"SHA256-RSAPSS", rsa.OIDRSASSAPSS, crypto.SHA256, false, true},
+	SHA384WithRSAPSS: {"SHA384-RSAPSS", rsa.OIDRSASSAPSS, crypto.SHA384, false, true},
+	SHA512WithRSAPSS: {"SHA512-RSAPSS", rsa.OIDRSASSAPSS, crypto.SHA512, false, true},
+}
+
+// String 返回算法名称
+func (alg SignatureAlgorithm) String() string {
+	if info, ok := signatureAlgorithms[alg]; ok {
+		return info.name
+	}
+	return "unknown"
+}
+
+// isRSA 判断是否是RSA签名算法（PKCS#1 v1.5或PSS）
+func (alg SignatureAlgorithm) isRSA() bool {
+	info := signatureAlgorithms[alg]
+	return info.null || info.pss
+}
+
+// addSignatureAlgorithm 追加签名算法的AlgorithmIdentifier
+func addSignatureAlgorithm(b *der.Builder, alg SignatureAlgorithm) {
+	info := signatureAlgorithms[alg]
+	if info.pss {
+		// 参数是确定的盐长度，编码不会失败
+		raw, _ := rsa.MarshalPSSAlgorithm(rsa.HashFor(info.hash), nil)
+		b.AddRaw(raw)
+		return
+	}
+	b.AddSequence(func(b *der.Builder) {
+		b.AddOID(info.oid)
+		if info.null {
+			b.AddNull()
+		}
+	})
+}
+
+// readSignatureAlgorithm 读取签名算法的AlgorithmIdentifier，不认识的算法返回UnknownSignatureAlgorithm
+// 参数只接受缺省或NULL，部分国密实现会为SM2WithSM3附带NULL参数；
+// RSASSA-PSS的参数必须与某个PSS算法的摘要、MGF1摘要和盐长度完全一致
+func readSignatureAlgorithm(p *der.Parser) (SignatureAlgorithm, error) {
+	raw, err := p.ReadRaw(der.TagSequence)
+	if err != nil {
+		return 0, err
+	}
+	seq, _ := der.NewParser(raw).ReadSequence()
+	oid, err := seq.ReadOID()
+	if err != nil {
+		return 0, err
+	}
+	if oid.Equal(rsa.OIDRSASSAPSS) {
+		return pssAlgorithm(raw), nil
+	}
+	if !seq.Empty() {
+		if err := seq.ReadNull(); err != nil || seq.Finish() != nil {
+			return UnknownSignatureAlgorithm, nil
+		}
+	}
+	for alg, info := range signatureAlgorithms {
+		if info.oid.Equal(oid) {
+			return alg, nil
+		}
+	}
+	return UnknownSignatureAlgorithm, nil
+}
+
+// pssAlgorithm 将id-RSASSA-PSS的AlgorithmIdentifier对应到PSS签名算法，参数不符时返回UnknownSignatureAlgorithm
+func pssAlgorithm(raw []byte) SignatureAlgorithm {
+	hash, opts, err := rsa.ParsePSSAlgorithm(raw)
+	if err != nil || opts.MGFHash != hash || opts.SaltLength != hash.Size() {
+		return UnknownSignatureAlgorithm
+	}
+	for _, alg := range []SignatureAlgorithm{SHA256WithRSAPSS, SHA384WithRSAPSS, SHA512WithRSAPSS} {
+		if rsa.HashFor(signatureAlgorithms[alg].hash) == hash {
+			return alg
+		}
+	}
+	return UnknownSignatureAlgorithm
+}
+
+// rsaPrivateKey 返回priv对应的gsc/rsa私钥，priv是crypto/rsa私钥时转换，不是RSA私钥时返回nil
+func rsaPrivateKey(priv any) *rsa.PrivateKey {
+	switch k := priv.(type) {
+	case *rsa.PrivateKey:
+		return k
+	case *stdrsa.PrivateKey:
+		return rsa.FromStdPrivateKey(k)
+	}
+	return nil
+}
+
+// rsaPublicKey 返回pub对应的gsc/rsa公钥，不是RSA公钥时返回nil
+func rsaPublicKey(pub any) *rsa.PublicKey {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return k
+	case *stdrsa.PublicKey:
+		return rsa.FromStdPublicKey(k)
+	}
+	return nil
+}
+
+// ed25519PrivateKey 返回priv对应的gsc/ed25519私钥，两个包的私钥格式相同；不是Ed25519私钥或长度错误时返回nil
+func ed25519PrivateKey(priv any) ed25519.PrivateKey {
+	var k ed25519.PrivateKey
+	switch p := priv.(type) {
+	case ed25519.PrivateKey:
+		k = p
+	case stded25519.PrivateKey:
+		k = ed25519.PrivateKey(p)
+	}
+	if len(k) != ed25519.PrivateKeySize {
+		return nil
+	}
+	return k
+}
+
+// ed25519PublicKey 返回pub对应的gsc/ed25519公钥，不是Ed25519公钥时返回nil
+func ed25519PublicKey(pub any) ed25519.PublicKey {
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		return k
+	case stded25519.PublicKey:
+		return ed25519.PublicKey(k)
+	}
+	return nil
+}
+
+// signingParams 根据私钥类型选择签名算法，返回算法和对应的公钥
+// RSA和Ed25519私钥可以是标准库或gsc的类型。RSA私钥默认使用SHA256WithRSA，requested是RSA签名算法时使用requested；
+// 其他私钥忽略requested。ECDSA按曲线选择摘要：P-256用SHA-256，P-384用SHA-384，P-521用SHA-512；
+// SM2曲线上的ECDSA私钥按SM2处理
+func signingParams(priv any, requested SignatureAlgorithm) (SignatureAlgorithm, any, error) {
+	alg := SHA256WithRSA
+	if requested.isRSA() {
+		alg = requested
+	}
+	switch k := priv.(type) {
+	case *stdrsa.PrivateKey:
+		return alg, &k.PublicKey, nil
+	case *rsa.PrivateKey:
+		return alg, &k.PublicKey, nil
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return ECDSAWithSHA256, &k.PublicKey, nil
+		case elliptic.P384():
+			return ECDSAWithSHA384, &k.PublicKey, nil
+		case elliptic.P521():
+			return ECDSAWithSHA512, &k.PublicKey, nil
+		case sm2.P256():
+			s := fromECDSA(k)
+			return SM2WithSM3, &s.PublicKey, nil
+		}
+	case stded25519.PrivateKey, ed25519.PrivateKey:
+		if k := ed25519PrivateKey(k); k != nil {
+			return PureEd25519, k.Public(), nil
+		}
+	case *sm2.PrivateKey:
+		return SM2WithSM3, &k.PublicKey, nil
+	}
+	return 0, nil, ErrUnsupportedKeyType
+}
+
+// sign 使用私钥签名signed，alg由signingParams根据同一私钥选出
+func sign(alg SignatureAlgorithm, priv any, signed []byte) ([]byte, error) {
+	if k, ok := priv.(*ecdsa.PrivateKey); ok && alg == SM2WithSM3 {
+		priv = fromECDSA(k)
+	}
+	if alg.isRSA() {
+		k := rsaPrivateKey(priv)
+		if k == nil {
+			return nil, ErrUnsupportedKeyType
+		}
+		info := signatureAlgorithms[alg]
+		hash := rsa.HashFor(info.hash)
+		if info.pss {
+			return rsa.SignPSS(nil, k, hash, hash.Sum(signed), nil)
+		}
+		return rsa.SignPKCS1v15(k, hash, hash.Sum(signed))
+	}
+	if alg == PureEd25519 {
+		k := ed25519PrivateKey(priv)
+		if k == nil {
+			return nil, ErrUnsupportedKeyType
+		}
+		return ed25519.Sign(k, signed)
+	}
+	if k, ok := priv.(*sm2.PrivateKey); ok {
+		raw, err := sm2.New().SignWithId(k, signed, nil)
+		if err != nil {
+			return nil, err
+		}
+		var b der.Builder
+		b.AddSequence(func(b *der.Builder) {
+			b.AddInteger(new(big.Int).SetBytes(raw[:32]))
+			b.AddInteger(new(big.Int).SetBytes(raw[32:]))
+		})
+		return b.Bytes()
+	}
+
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, ErrUnsupportedKeyType
+	}
+	h := signatureAlgorithms[alg].hash
+	if h == 0 {
+		return signer.Sign(rand.Reader, signed, crypto.Hash(0))
+	}
+	d := h.New()
+	d.Write(signed)
+	return signer.Sign(rand.Reader, d.Sum(nil), h)
+}
+
+// checkSignature 使用公钥验证signed的签名
+func checkSignature(alg SignatureAlgorithm, pub any, signed, signature []byte) error {
+	info, ok := signatureAlgorithms[alg]
+	if !ok {
+		return ErrUnsupportedAlgorithm
+	}
+	var digest []byte
+	if info.hash != 0 {
+		d := info.hash.New()
+		d.Write(signed)
+		digest = d.Sum(nil)
+	}
+
+	var valid bool
+	switch alg {
+	case SHA256WithRSA, SHA384WithRSA, SHA512WithRSA:
+		k := rsaPublicKey(pub)
+		if k == nil {
+			return ErrKeyMismatch
+		}
+		valid = rsa.VerifyPKCS1v15(k, rsa.HashFor(info.hash), digest, signature) == nil
+	case SHA256WithRSAPSS, SHA384WithRSAPSS, SHA512WithRSAPSS:
+		k := rsaPublicKey(pub)
+		if k == nil {
+			return ErrKeyMismatch
+		}
+		valid = rsa.VerifyPSS(k, rsa.HashFor(info.hash), digest, signature, nil) == nil
+	case ECDSAWithSHA256, ECDSAWithSHA384, ECDSAWithSHA512:
+		k, isECDSA := pub.(*ecdsa.PublicKey)
+		if !isECDSA {
+			return ErrKeyMismatch
+		}
+		valid = ecdsa.VerifyASN1(k, digest, signature)
+	case PureEd25519:
+		k := ed25519PublicKey(pub)
+		if k == nil {
+			return ErrKeyMismatch
+		}
+		valid = ed25519.Verify(k, signed, signature)
+	case SM2WithSM3:
+		k, isSM2 := pub.(*sm2.PublicKey)
+		if !isSM2 {
+			return ErrKeyMismatch
+		}
+		raw, err := sm2RawSignature(signature)
+		if err != nil {
+			return err
+		}
+		valid = sm2.New().VerifyWithId(k, signed, raw, nil) || sm2.New().Verify(k, sm2EmptyIDDigest(k, signed), raw)
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// sm2EmptyIDDigest 计算用户标识为空时的摘要 e = SM3(ZA || M)，ZA中ENTLA为0
+// OpenSSL 3.0签发和验证SM2证书时不设置用户标识，因此验证时在默认标识失败后再尝试空标识
+func sm2EmptyIDDigest(pub *sm2.PublicKey, msg []byte) []byte {
+	params := sm2.P256().Params()
+	a := new(big.Int).Sub(params.P, big.NewInt(3))
+	h := sm3.New()
+	h.Write([]byte{0, 0})
+	for _, v := range []*big.Int{a, params.B, params.Gx, params.Gy, pub.X, pub.Y} {
+		h.Write(v.FillBytes(make([]byte, 32)))
+	}
+	za := h.Sum(nil)
+	h.Reset()
+	h.Write(za)
+	h.Write(msg)
+	return h.Sum(nil)
+}
+
+// sm2RawSignature 将DER编码的SM2签名转换为sm2包使用的 r || s
+func sm2RawSignature(signature []byte) ([]byte, error) {
+	p := der.NewParser(signature)
+	seq, err := p.ReadSequence()
+	if err != nil || p.Finish() != nil {
+		return nil, ErrInvalidSignature
+	}
+	r, err := seq.ReadInteger()
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	s, err := seq.ReadInteger()
+	if err != nil || seq.Finish() != nil {
+		return nil, ErrInvalidSignature
+	}
+	if r.Sign() <= 0 || s.Sign() <= 0 || r.BitLen() > 256 || s.BitLen() > 256 {
+		return nil, ErrInvalidSignature
+	}
+	raw := make([]byte, sm2.SignatureSize)
+	r.FillBytes(raw[:32])
+	s.FillBytes(raw[32:])
+	return raw, nil
+}
@@ -0,0 +1,200 @@
+package x509
+
+import (
+	"encoding/pem"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxChainLength 是证书链的最大长度，防止构造链时出现过深的搜索
+const maxChainLength = 10
+
+// CertPool 是按主体名称索引的证书集合
+type CertPool struct {
+	bySubject map[string][]*Certificate
+}
+
+// NewCertPool 返回空的证书集合
+func NewCertPool() *CertPool {
+	return &CertPool{bySubject: make(map[string][]*Certificate)}
+}
+
+// AddCert 添加证书，重复添加同一证书无效果
+func (s *CertPool) AddCert(c *Certificate) {
+	if s.contains(c) {
+		return
+	}
+	s.bySubject[string(c.RawSubject)] = append(s.bySubject[string(c.RawSubject)], c)
+}
+
+// Clone 返回集合的副本，向副本添加证书不影响原集合；nil集合返回空集合
+func (s *CertPool) Clone() *CertPool {
+	c := NewCertPool()
+	if s != nil {
+		for k, v := range s.bySubject {
+			c.bySubject[k] = slices.Clone(v)
+		}
+	}
+	return c
+}
+
+// AppendCertsFromPEM 添加PEM数据中的全部"CERTIFICATE"块，返回是否至少添加了一个证书
+func (s *CertPool) AppendCertsFromPEM(data []byte) bool {
+	ok := false
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return ok
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		c, err := ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+		s.AddCert(c)
+		ok = true
+	}
+}
+
+// contains 判断集合中是否有编码相同的证书
+func (s *CertPool) contains(c *Certificate) bool {
+	if s == nil {
+		return false
+	}
+	return slices.ContainsFunc(s.bySubject[string(c.RawSubject)], c.Equal)
+}
+
+// issuers 返回主体名称与c的签发者名称相同的证书
+func (s *CertPool) issuers(c *Certificate) []*Certificate {
+	if s == nil {
+		return nil
+	}
+	return s.bySubject[string(c.RawIssuer)]
+}
+
+// VerifyOptions 是证书链验证的参数
+type VerifyOptions struct {
+	// Roots 是信任的根证书，不能为空
+	Roots *CertPool
+	// Intermediates 是可用于构造证书链的中间CA证书，它们本身不被信任
+	Intermediates *CertPool
+	// CurrentTime 是检查有效期使用的时间，零值表示当前时间
+	CurrentTime time.Time
+	// DNSName 非空时检查叶子证书对该主机名或IP地址有效
+	DNSName string
+	// KeyUsages 非空时要求叶子证书的扩展密钥用途包含其中之一；叶子证书没有扩展密钥用途时不限制
+	KeyUsages []ExtKeyUsage
+}
+
+// Verify 构造从c到可信根证书的证书链并验证，返回的链以c开始、以根证书结束
+//
+// 检查每个证书的有效期和关键扩展，签发者必须是CA（基本约束中cA为真，且密钥用途为空或包含证书签名），
+// 满足路径长度约束，并验证每一级的签名。有多条候选链时返回第一条验证通过的
+func (c *Certificate) Verify(opts VerifyOptions) ([]*Certificate, error) {
+	if opts.CurrentTime.IsZero() {
+		opts.CurrentTime = time.Now()
+	}
+	if opts.DNSName != "" {
+		if err := c.VerifyHostname(opts.DNSName); err != nil {
+			return nil, err
+		}
+	}
+	if len(opts.KeyUsages) > 0 && len(c.ExtKeyUsage) > 0 && !slices.Contains(c.ExtKeyUsage, ExtKeyUsageAny) &&
+		!slices.ContainsFunc(opts.KeyUsages, func(u ExtKeyUsage) bool { return slices.Contains(c.ExtKeyUsage, u) }) {
+		return nil, ErrIncompatibleUsage
+	}
+	return buildChain(opts, []*Certificate{c})
+}
+
+// buildChain 为链中最后一个证书寻找签发者，直到到达可信根证书
+func buildChain(opts VerifyOptions, chain []*Certificate) ([]*Certificate, error) {
+	c := chain[len(chain)-1]
+	if err := c.checkValidity(opts.CurrentTime); err != nil {
+		return nil, err
+	}
+	if opts.Roots.contains(c) {
+		return chain, nil
+	}
+	if len(chain) >= maxChainLength {
+		return nil, ErrUnknownAuthority
+	}
+
+	err := ErrUnknownAuthority
+	for _, pool := range []*CertPool{opts.Roots, opts.Intermediates} {
+		for _, parent := range pool.issuers(c) {
+			if slices.ContainsFunc(chain, parent.Equal) {
+				continue
+			}
+			if e := checkIssuer(parent, c, len(chain)-1); e != nil {
+				err = e
+				continue
+			}
+			full, e := buildChain(opts, append(slices.Clip(chain), parent))
+			if e == nil {
+				return full, nil
+			}
+			err = e
+		}
+	}
+	return nil, err
+}
+
+// checkIssuer 检查parent能否签发child，below是child下方（含child本身，不含叶子证书）的CA证书数量
+func checkIssuer(parent, child *Certificate, below int) error {
+	if !parent.BasicConstraintsValid || !parent.IsCA {
+		return ErrNotCA
+	}
+	if parent.KeyUsage != 0 && parent.KeyUsage&KeyUsageCertSign == 0 {
+		return ErrNotCA
+	}
+	if parent.MaxPathLen >= 0 && below > parent.MaxPathLen {
+		return ErrPathLength
+	}
+	return child.CheckSignatureFrom(parent)
+}
+
+// checkValidity 检查有效期和不认识的关键扩展
+func (c *Certificate) checkValidity(now time.Time) error {
+	if now.Before(c.NotBefore) || now.After(c.NotAfter) {
+		return ErrExpired
+	}
+	if len(c.unhandledCritical) > 0 {
+		return ErrUnhandledCriticalExtension
+	}
+	return nil
+}
+
+// VerifyHostname 检查证书对host有效：host为IP地址时与IPAddresses比较，
+// 否则与DNSNames比较（不区分大小写，通配符只匹配最左边的一个标签）。不使用主体的CommonName
+func (c *Certificate) VerifyHostname(host string) error {
+	if ip := net.ParseIP(strings.Trim(host, "[]")); ip != nil {
+		if slices.ContainsFunc(c.IPAddresses, ip.Equal) {
+			return nil
+		}
+		return ErrHostnameMismatch
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, name := range c.DNSNames {
+		if matchHostname(strings.ToLower(name), host) {
+			return nil
+		}
+	}
+	return ErrHostnameMismatch
+}
+
+// matchHostname 比较证书中的名称和主机名，名称可以以"*."开头
+func matchHostname(pattern, host string) bool {
+	if pattern == "" || host == "" {
+		return false
+	}
+	if rest, ok := strings.CutPrefix(pattern, "*."); ok {
+		_, hostRest, found := strings.Cut(host, ".")
+		return found && rest != "" && hostRest == rest
+	}
+	return pattern == host
+}
@@ -0,0 +1,144 @@
+package x509
+
+import (
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"testing"
+
+	"github.com/laenix/gsc/der"
+	"github.com/laenix/gsc/sm2"
+)
+
+// newIntermediate 由parent签发中间CA证书，maxPathLen为-1时不限制路径长度
+func newIntermediate(t *testing.T, parent *Certificate, parentPriv any, pub any, maxPathLen int) *Certificate {
+	t.Helper()
+	template := &Certificate{
+		SerialNumber:          big.NewInt(3),
+		Subject:               pkix.Name{CommonName: "gsc intermediate"},
+		NotBefore:             testNotBefore,
+		NotAfter:              testNotAfter,
+		KeyUsage:              KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLen:            max(maxPathLen, 0),
+		MaxPathLenZero:        maxPathLen == 0,
+	}
+	data, err := CreateCertificate(template, parent, pub, parentPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := ParseCertificate(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestVerifyChain(t *testing.T) {
+	rootKey, _ := sm2.New().GenerateKey(nil)
+	interKey, _ := sm2.New().GenerateKey(nil)
+	leafKey, _ := sm2.New().GenerateKey(nil)
+	root := newCA(t, rootKey, &rootKey.PublicKey, "gsc root")
+	inter := newIntermediate(t, root, rootKey, &interKey.PublicKey, 0)
+	leaf := newLeaf(t, inter, interKey, &leafKey.PublicKey)
+
+	roots, inters := NewCertPool(), NewCertPool()
+	roots.AddCert(root)
+	inters.AddCert(inter)
+	opts := VerifyOptions{Roots: roots, Intermediates: inters, CurrentTime: testNow, DNSName: "www.example.com"}
+
+	chain, err := leaf.Verify(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chain) != 3 || !chain[0].Equal(leaf) || !chain[1].Equal(inter) || !chain[2].Equal(root) {
+		t.Errorf("证书链长度 %d", len(chain))
+	}
+	if inter.MaxPathLen != 0 || !inter.MaxPathLenZero {
+		t.Errorf("路径长度 %d", inter.MaxPathLen)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*VerifyOptions)
+		want   error
+	}{
+		{"缺少中间证书", func(o *VerifyOptions) { o.Intermediates = nil }, ErrUnknownAuthority},
+		{"根证书不可信", func(o *VerifyOptions) { o.Roots = NewCertPool() }, ErrUnknownAuthority},
+		{"已过期", func(o *VerifyOptions) { o.CurrentTime = testNotAfter.AddDate(0, 0, 1) }, ErrExpired},
+		{"尚未生效", func(o *VerifyOptions) { o.CurrentTime = testNotBefore.AddDate(0, 0, -1) }, ErrExpired},
+		{"主机名", func(o *VerifyOptions) { o.DNSName = "example.com" }, ErrHostnameMismatch},
+		{"扩展密钥用途", func(o *VerifyOptions) { o.KeyUsages = []ExtKeyUsage{ExtKeyUsageCodeSigning} }, ErrIncompatibleUsage},
+		{"允许的用途", func(o *VerifyOptions) { o.KeyUsages = []ExtKeyUsage{ExtKeyUsageClientAuth, ExtKeyUsageServerAuth} }, nil},
+	}
+	for _, tt := range tests {
+		o := opts
+		tt.modify(&o)
+		if _, err := leaf.Verify(o); !errors.Is(err, tt.want) {
+			t.Errorf("%s: 期望%v，实际%v", tt.name, tt.want, err)
+		}
+	}
+
+	// 中间CA的路径长度为0，不能再签发CA
+	subKey, _ := sm2.New().GenerateKey(nil)
+	sub := newIntermediate(t, inter, interKey, &subKey.PublicKey, -1)
+	subLeaf := newLeaf(t, sub, subKey, &leafKey.PublicKey)
+	inters.AddCert(sub)
+	if _, err := subLeaf.Verify(opts); !errors.Is(err, ErrPathLength) {
+		t.Errorf("路径长度约束: %v", err)
+	}
+
+	// 叶子证书不是CA，不能签发证书
+	fake := newLeaf(t, leaf, leafKey, &subKey.PublicKey)
+	inters.AddCert(leaf)
+	if _, err := fake.Verify(VerifyOptions{Roots: roots, Intermediates: inters, CurrentTime: testNow}); !errors.Is(err, ErrNotCA) {
+		t.Errorf("非CA签发: %v", err)
+	}
+}
+
+func TestVerifyCriticalExtension(t *testing.T) {
+	key, _ := sm2.New().GenerateKey(nil)
+	template := &Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "critical"},
+		NotBefore:             testNotBefore,
+		NotAfter:              testNotAfter,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		ExtraExtensions:       []Extension{{ID: der.OID{1, 2, 3, 4}, Critical: true, Value: []byte{5, 0}}},
+	}
+	data, _ := CreateCertificate(template, template, &key.PublicKey, key)
+	c, err := ParseCertificate(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := NewCertPool()
+	roots.AddCert(c)
+	if _, err := c.Verify(VerifyOptions{Roots: roots, CurrentTime: testNow}); !errors.Is(err, ErrUnhandledCriticalExtension) {
+		t.Errorf("未知关键扩展: %v", err)
+	}
+}
+
+func TestVerifyHostname(t *testing.T) {
+	c := &Certificate{
+		DNSNames:    []string{"www.example.com", "*.api.example.com"},
+		IPAddresses: []net.IP{net.ParseIP("192.0.2.1"), net.ParseIP("2001:db8::1")},
+	}
+	for host, ok := range map[string]bool{
+		"www.example.com":      true,
+		"WWW.Example.COM.":     true,
+		"v1.api.example.com":   true,
+		"api.example.com":      false,
+		"a.v1.api.example.com": false,
+		"example.com":          false,
+		"192.0.2.1":            true,
+		"[2001:db8::1]":        true,
+		"192.0.2.2":            false,
+	} {
+		if err := c.VerifyHostname(host); (err == nil) != ok {
+			t.Errorf("%s: %v", host, err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Package x509 实现支持SM2的密钥和证书编码
+//
+// 标准库crypto/x509不识别SM2曲线，本包在其基础上补充国密算法：SM2密钥使用GM/T 0006中的OID，
+// 编码与OpenSSL和GmSSL一致；RSA、ECDSA和Ed25519密钥交给crypto/x509处理，解析得到的RSA密钥是crypto/rsa的类型，
+// 可用rsa.FromStdPublicKey等转换为gsc/rsa的类型。证书的解析、签发和证书链验证由本包实现，
+// 签名算法支持RSA（PKCS#1 v1.5和PSS，由gsc/rsa计算）、ECDSA、Ed25519（由gsc/ed25519计算）和SM2-with-SM3，
+// 签名和编码时也接受gsc/rsa和gsc/ed25519的密钥。
+// ASN.1结构使用der包构造和解析，名称使用crypto/x509/pkix中的类型
+package x509
+
+import (
+	"github.com/laenix/gsc/der"
+	"github.com/laenix/gsc/gscerr"
+	"github.com/laenix/gsc/i18n"
+)
+
+// 错误定义
+var (
+	ErrUnsupportedKeyType = gscerr.New(gscerr.ErrUnsupported, "x509: unsupported key type")
+	ErrInvalidKey         = gscerr.New(gscerr.ErrMalformed, "x509: invalid key encoding")
+	ErrMalformed          = gscerr.New(gscerr.ErrMalformed, "x509: malformed certificate")
+	ErrInvalidTemplate    = gscerr.New(gscerr.ErrParameter, "x509: invalid certificate template")
+
+	ErrUnsupportedAlgorithm       = gscerr.New(gscerr.ErrUnsupported, "x509: unsupported signature algorithm")
+	ErrKeyMismatch                = gscerr.New(gscerr.ErrParameter, "x509: public key type does not match the signature algorithm")
+	ErrInvalidSignature           = gscerr.New(gscerr.ErrVerification, "x509: invalid signature")
+	ErrExpired                    = gscerr.New(gscerr.ErrVerification, "x509: certificate has expired or is not yet valid")
+	ErrUnknownAuthority           = gscerr.New(gscerr.ErrVerification, "x509: certificate signed by unknown authority")
+	ErrNotCA                      = gscerr.New(gscerr.ErrVerification, "x509: issuer is not a CA")
+	ErrPathLength                 = gscerr.New(gscerr.ErrVerification, "x509: path length constraint exceeded")
+	ErrHostnameMismatch           = gscerr.New(gscerr.ErrVerification, "x509: certificate is not valid for the host name")
+	ErrIncompatibleUsage          = gscerr.New(gscerr.ErrVerification, "x509: certificate specifies an incompatible key usage")
+	ErrUnhandledCriticalExtension = gscerr.New(gscerr.ErrUnsupported, "x509: unhandled critical extension")
+)
+
+// 登记错误消息的中文译文
+func init() {
+	i18n.Register(map[error]string{
+		ErrUnsupportedKeyType:         "x509: 不支持的密钥类型",
+		ErrInvalidKey:                 "x509: 密钥编码无效",
+		ErrMalformed:                  "x509: 证书格式错误",
+		ErrInvalidTemplate:            "x509: 证书模板无效",
+		ErrUnsupportedAlgorithm:       "x509: 不支持的签名算法",
+		ErrKeyMismatch:                "x509: 公钥类型与签名算法不匹配",
+		ErrInvalidSignature:           "x509: 签名无效",
+		ErrExpired:                    "x509: 证书已过期或尚未生效",
+		ErrUnknownAuthority:           "x509: 证书由未知的CA签发",
+		ErrNotCA:                      "x509: 签发者不是CA",
+		ErrPathLength:                 "x509: 超出路径长度约束",
+		ErrHostnameMismatch:           "x509: 证书对该主机名无效",
+		ErrIncompatibleUsage:          "x509: 证书的密钥用途不符",
+		ErrUnhandledCriticalExtension: "x509: 存在无法处理的关键扩展",
+	})
+}
+
+// 国密算法OID（GM/T 0006-2012）
+var (
+	// OIDSM2 是SM2曲线（sm2p256v1），作为id-ecPublicKey的曲线参数；部分国密实现也用它作为算法标识
+	OIDSM2 = der.OID{1, 2, 156, 10197, 1, 301}
+	// OIDSM2Sign 是SM2签名算法（sm2-1）
+	OIDSM2Sign = der.OID{1, 2, 156, 10197, 1, 301, 1}
+	// OIDSM2Encrypt 是SM2加密算法（sm2-3）
+	OIDSM2Encrypt = der.OID{1, 2, 156, 10197, 1, 301, 3}
+	// OIDSM3 是SM3杂凑算法
+	OIDSM3 = der.OID{1, 2, 156, 10197, 1, 401}
+	// OIDSM2WithSM3 是SM3摘要的SM2签名
+	OIDSM2WithSM3 = der.OID{1, 2, 156, 10197, 1, 501}
+	// OIDSM4 是SM4分组密码
+	OIDSM4 = der.OID{1, 2, 156, 10197, 1, 104}
+)
+
+// oidECPublicKey 是RFC 5480中的椭圆曲线公钥算法标识
+var oidECPublicKey = der.OID{1, 2, 840, 10045, 2, 1}